@@ -79,11 +79,22 @@ func auditWrap(engine *core.UltraFastEngine, tool string, handler func(context.C
 			engine.Audit(startEntry)
 		}
 
+		// Track the call so a shutdown can wait for it and report it if it
+		// does not finish in time
+		tracked := *entry
+		if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+			tracked.Path, _ = args["path"].(string)
+		}
+		ctx, untrack := engine.TrackCall(ctx, tracked)
+		defer untrack()
+
 		// Call actual handler
 		res, err := handler(ctx, request)
+		elapsed := time.Since(start)
+		engine.RecordToolLatency(tool, elapsed)
 
 		// Complete audit entry
-		entry.DurationMs = time.Since(start).Milliseconds()
+		entry.DurationMs = elapsed.Milliseconds()
 		if err != nil {
 			entry.Status = "error"
 			entry.Error = err.Error()
@@ -98,6 +109,9 @@ func auditWrap(engine *core.UltraFastEngine, tool string, handler func(context.C
 		} else {
 			entry.Status = "ok"
 		}
+		if entry.Status == "error" {
+			engine.RecordToolError(tool)
+		}
 
 		// Extract path and summarize args for logging
 		if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
@@ -146,8 +160,12 @@ func auditWrap(engine *core.UltraFastEngine, tool string, handler func(context.C
 			entry.TokensSaved = entry.TokensBaseline - entry.TokensConsumed
 		}
 
-		// Log the audit entry
+		// Log the audit entry (and a warning line when the call was slow)
+		engine.ObserveSlowOperation(*entry, elapsed)
 		engine.Audit(*entry)
+		if tool != "server_info" || entry.Args["action"] != "history" {
+			engine.RecordHistory(*entry)
+		}
 
 		return res, err
 	}
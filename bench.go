@@ -0,0 +1,386 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mcp/filesystem-ultra/cache"
+	"github.com/mcp/filesystem-ultra/core"
+	localmcp "github.com/mcp/filesystem-ultra/mcp"
+)
+
+// benchOptions configures --bench
+type benchOptions struct {
+	Files        int     // files in the generated workspace
+	Sizes        []int64 // file sizes, assigned round-robin
+	Iterations   int     // repeats of the workspace-wide operations
+	CompareCache int64   // a second cache size to run, 0 = none
+	JSONPath     string  // where to write the JSON report, "-" = stdout, "" = none
+}
+
+// benchFilesPerDir is how many workspace files share a directory
+const benchFilesPerDir = 50
+
+// benchOpResult is the measurement of one operation type in one run
+type benchOpResult struct {
+	Name      string  `json:"name"`
+	Ops       int     `json:"ops"`
+	Bytes     int64   `json:"bytes"`
+	TotalMS   float64 `json:"total_ms"`
+	OpsPerSec float64 `json:"ops_per_sec"`
+	MBPerSec  float64 `json:"mb_per_sec,omitempty"`
+	P50MS     float64 `json:"p50_ms"`
+	P95MS     float64 `json:"p95_ms"`
+	P99MS     float64 `json:"p99_ms"`
+	MaxMS     float64 `json:"max_ms"`
+}
+
+// benchRun is every operation measured against one cache size
+type benchRun struct {
+	CacheSize int64           `json:"cache_size"`
+	Ops       []benchOpResult `json:"ops"`
+}
+
+// benchReport is the --bench-json output
+type benchReport struct {
+	Version         string     `json:"version"`
+	GoVersion       string     `json:"go_version"`
+	GoOS            string     `json:"goos"`
+	GoArch          string     `json:"goarch"`
+	CPUs            int        `json:"cpus"`
+	ParallelOps     int        `json:"parallel_ops"`
+	BinaryThreshold int64      `json:"binary_threshold"`
+	Files           int        `json:"files"`
+	Sizes           []int64    `json:"sizes"`
+	Iterations      int        `json:"iterations"`
+	StartedAt       time.Time  `json:"started_at"`
+	Runs            []benchRun `json:"runs"`
+}
+
+// benchSampler collects the latencies of one operation type
+type benchSampler struct {
+	name    string
+	samples []time.Duration
+	bytes   int64
+}
+
+func (s *benchSampler) time(bytes int64, fn func() error) error {
+	start := time.Now()
+	if err := fn(); err != nil {
+		return fmt.Errorf("%s: %w", s.name, err)
+	}
+	s.samples = append(s.samples, time.Since(start))
+	s.bytes += bytes
+	return nil
+}
+
+func (s *benchSampler) result() benchOpResult {
+	r := benchOpResult{Name: s.name, Ops: len(s.samples), Bytes: s.bytes}
+	if r.Ops == 0 {
+		return r
+	}
+	sorted := append([]time.Duration(nil), s.samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	ms := func(d time.Duration) float64 { return float64(d) / float64(time.Millisecond) }
+	pct := func(q float64) time.Duration { return sorted[int(q*float64(len(sorted)-1)+0.5)] }
+	r.TotalMS, r.P50MS, r.P95MS, r.P99MS, r.MaxMS = ms(total), ms(pct(0.50)), ms(pct(0.95)), ms(pct(0.99)), ms(sorted[len(sorted)-1])
+	if total > 0 {
+		r.OpsPerSec = float64(r.Ops) / total.Seconds()
+		if r.Bytes > 0 {
+			r.MBPerSec = float64(r.Bytes) / 1024 / 1024 / total.Seconds()
+		}
+	}
+	return r
+}
+
+// runBenchmark generates a temp workspace and measures each operation type
+// against the current configuration, once per cache size. It prints a table
+// to w and, with opts.JSONPath, writes the same numbers as JSON for CI.
+func runBenchmark(w io.Writer, config *Configuration, opts benchOptions) error {
+	if opts.Files <= 0 || len(opts.Sizes) == 0 || opts.Iterations <= 0 {
+		return fmt.Errorf("--bench-files, --bench-sizes and --bench-iterations must be positive")
+	}
+	report := benchReport{
+		Version:         serverVersion,
+		GoVersion:       runtime.Version(),
+		GoOS:            runtime.GOOS,
+		GoArch:          runtime.GOARCH,
+		CPUs:            runtime.NumCPU(),
+		ParallelOps:     config.ParallelOps,
+		BinaryThreshold: config.BinaryThreshold,
+		Files:           opts.Files,
+		Sizes:           opts.Sizes,
+		Iterations:      opts.Iterations,
+		StartedAt:       time.Now().UTC(),
+	}
+	cacheSizes := []int64{config.CacheSize}
+	if opts.CompareCache > 0 && opts.CompareCache != config.CacheSize {
+		cacheSizes = append(cacheSizes, opts.CompareCache)
+	}
+	for _, size := range cacheSizes {
+		run, err := benchOneCacheSize(config, opts, size)
+		if err != nil {
+			return fmt.Errorf("cache %s: %w", formatSize(size), err)
+		}
+		report.Runs = append(report.Runs, run)
+	}
+
+	writeBenchTable(w, &report)
+	if opts.JSONPath == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if opts.JSONPath == "-" {
+		_, err = fmt.Fprintf(w, "\n%s\n", data)
+		return err
+	}
+	return os.WriteFile(opts.JSONPath, append(data, '\n'), 0644)
+}
+
+// benchOneCacheSize runs every operation against a fresh workspace and engine
+func benchOneCacheSize(config *Configuration, opts benchOptions, cacheSize int64) (benchRun, error) {
+	run := benchRun{CacheSize: cacheSize}
+	tmp, err := os.MkdirTemp("", "mcp-bench-")
+	if err != nil {
+		return run, err
+	}
+	defer os.RemoveAll(tmp)
+	workspace := filepath.Join(tmp, "workspace")
+	files, dirs, err := generateBenchWorkspace(workspace, opts)
+	if err != nil {
+		return run, fmt.Errorf("generating workspace: %w", err)
+	}
+
+	cacheSystem, err := cache.NewIntelligentCache(cacheSize)
+	if err != nil {
+		return run, err
+	}
+	defer cacheSystem.Close()
+	// Backups go beside the workspace, so edits pay for them as in normal use
+	// without the backups showing up in listings and searches
+	engine, err := core.NewUltraFastEngine(&core.Config{
+		Cache:            cacheSystem,
+		ParallelOps:      config.ParallelOps,
+		AllowedPaths:     []string{workspace},
+		BinaryThreshold:  config.BinaryThreshold,
+		CompactMode:      true,
+		MaxResponseSize:  config.MaxResponseSize,
+		MaxSearchResults: config.MaxSearchResults,
+		MaxListItems:     config.MaxListItems,
+		BackupDir:        filepath.Join(tmp, "backups"),
+	})
+	if err != nil {
+		return run, err
+	}
+	defer engine.Close()
+	ctx := context.Background()
+
+	fileSize := func(i int) int64 { return opts.Sizes[i%len(opts.Sizes)] }
+	steps := []struct {
+		name string
+		fn   func(s *benchSampler) error
+	}{
+		{"read_cold", func(s *benchSampler) error {
+			cacheSystem.Clear()
+			for i, p := range files {
+				if err := s.time(fileSize(i), func() error { _, err := engine.ReadFileContent(ctx, p); return err }); err != nil {
+					return err
+				}
+			}
+			return nil
+		}},
+		{"read_warm", func(s *benchSampler) error {
+			for i, p := range files {
+				if err := s.time(fileSize(i), func() error { _, err := engine.ReadFileContent(ctx, p); return err }); err != nil {
+					return err
+				}
+			}
+			return nil
+		}},
+		{"write", func(s *benchSampler) error {
+			for i := range files {
+				content := benchContent(i, fileSize(i))
+				p := filepath.Join(workspace, "out", fmt.Sprintf("out%05d.txt", i))
+				if err := s.time(int64(len(content)), func() error { return engine.WriteFileAuto(ctx, p, content) }); err != nil {
+					return err
+				}
+			}
+			return nil
+		}},
+		{"edit", func(s *benchSampler) error {
+			for i, p := range files {
+				old, repl := fmt.Sprintf("EDIT_ME_%05d", i), fmt.Sprintf("EDITED__%05d", i)
+				if err := s.time(fileSize(i), func() error {
+					_, err := engine.EditFile(ctx, p, old, repl, false, false, false)
+					return err
+				}); err != nil {
+					return err
+				}
+			}
+			return nil
+		}},
+		{"multi_edit", func(s *benchSampler) error {
+			for i, p := range files {
+				edits := []core.MultiEditOperation{
+					{OldText: fmt.Sprintf("MULTI_A_%05d", i), NewText: fmt.Sprintf("MULTI_X_%05d", i)},
+					{OldText: fmt.Sprintf("MULTI_B_%05d", i), NewText: fmt.Sprintf("MULTI_Y_%05d", i)},
+				}
+				if err := s.time(fileSize(i), func() error {
+					_, err := engine.MultiEdit(ctx, p, edits, false, false, false, "")
+					return err
+				}); err != nil {
+					return err
+				}
+			}
+			return nil
+		}},
+		{"list_directory", func(s *benchSampler) error {
+			for it := 0; it < opts.Iterations; it++ {
+				for _, d := range dirs {
+					if err := s.time(0, func() error { _, err := engine.ListDirectoryContent(ctx, d); return err }); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		}},
+		{"smart_search", func(s *benchSampler) error {
+			req := localmcp.CallToolRequest{Arguments: map[string]interface{}{"path": workspace, "pattern": "NEEDLE_A", "include_content": true}}
+			for it := 0; it < opts.Iterations; it++ {
+				if err := s.time(0, func() error { return benchResponseError(engine.SmartSearch(ctx, req)) }); err != nil {
+					return err
+				}
+			}
+			return nil
+		}},
+		{"search_and_replace", func(s *benchSampler) error {
+			// Back and forth, so every iteration rewrites the same files
+			for it := 0; it < opts.Iterations; it++ {
+				from, to := "NEEDLE_A", "NEEDLE_B"
+				if it%2 == 1 {
+					from, to = to, from
+				}
+				if err := s.time(0, func() error {
+					return benchResponseError(engine.SearchAndReplace(ctx, workspace, from, to, true, false))
+				}); err != nil {
+					return err
+				}
+			}
+			return nil
+		}},
+	}
+	for _, step := range steps {
+		s := &benchSampler{name: step.name}
+		if err := step.fn(s); err != nil {
+			return run, err
+		}
+		run.Ops = append(run.Ops, s.result())
+	}
+	return run, nil
+}
+
+// benchResponseError turns an engine response that reports an error in its
+// text into an error
+func benchResponseError(resp *localmcp.CallToolResponse, err error) error {
+	if err != nil {
+		return err
+	}
+	if resp != nil && len(resp.Content) > 0 && strings.HasPrefix(resp.Content[0].Text, "❌") {
+		return fmt.Errorf("%s", resp.Content[0].Text)
+	}
+	return nil
+}
+
+// generateBenchWorkspace writes opts.Files files under root, benchFilesPerDir
+// to a directory, and returns their paths and the directories
+func generateBenchWorkspace(root string, opts benchOptions) (files, dirs []string, err error) {
+	for i := 0; i < opts.Files; i++ {
+		dir := filepath.Join(root, "src", fmt.Sprintf("d%03d", i/benchFilesPerDir))
+		if i%benchFilesPerDir == 0 {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return nil, nil, err
+			}
+			dirs = append(dirs, dir)
+		}
+		p := filepath.Join(dir, fmt.Sprintf("file%05d.txt", i))
+		if err := os.WriteFile(p, []byte(benchContent(i, opts.Sizes[i%len(opts.Sizes)])), 0644); err != nil {
+			return nil, nil, err
+		}
+		files = append(files, p)
+	}
+	return files, dirs, nil
+}
+
+// benchContent is the content of workspace file i: size bytes of numbered
+// lines holding the markers edit and multi_edit replace, and in every tenth
+// file the needle that search and search_and_replace look for
+func benchContent(i int, size int64) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "// bench file %d EDIT_ME_%05d MULTI_A_%05d\n", i, i, i)
+	if i%10 == 0 {
+		sb.WriteString("const marker = \"NEEDLE_A\"\n")
+	}
+	for line := 1; int64(sb.Len()) < size; line++ {
+		fmt.Fprintf(&sb, "line %d: the quick brown fox jumps over the lazy dog\n", line)
+	}
+	fmt.Fprintf(&sb, "// end MULTI_B_%05d\n", i)
+	return sb.String()
+}
+
+// writeBenchTable prints one table per run and, when two cache sizes ran, the
+// change in throughput between them
+func writeBenchTable(w io.Writer, r *benchReport) {
+	sizes := make([]string, len(r.Sizes))
+	for i, s := range r.Sizes {
+		sizes[i] = formatSize(s)
+	}
+	fmt.Fprintf(w, "Benchmark v%s: %d files (%s), %d iterations, parallel-ops %d, binary-threshold %s, %s/%s %d CPUs\n",
+		r.Version, r.Files, strings.Join(sizes, ", "), r.Iterations, r.ParallelOps, formatSize(r.BinaryThreshold), r.GoOS, r.GoArch, r.CPUs)
+
+	ms := func(v float64) string { return fmt.Sprintf("%.3f", v) }
+	for _, run := range r.Runs {
+		fmt.Fprintf(w, "\nCache %s\n", formatSize(run.CacheSize))
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintf(tw, "%-18s\tops\tops/s\tMB/s\tp50 ms\tp95 ms\tp99 ms\tmax ms\t\n", "operation")
+		for _, op := range run.Ops {
+			mbs := "-"
+			if op.MBPerSec > 0 {
+				mbs = fmt.Sprintf("%.1f", op.MBPerSec)
+			}
+			fmt.Fprintf(tw, "%-18s\t%d\t%.0f\t%s\t%s\t%s\t%s\t%s\t\n", op.Name, op.Ops, op.OpsPerSec, mbs, ms(op.P50MS), ms(op.P95MS), ms(op.P99MS), ms(op.MaxMS))
+		}
+		tw.Flush()
+	}
+
+	if len(r.Runs) != 2 {
+		return
+	}
+	a, b := r.Runs[0], r.Runs[1]
+	fmt.Fprintf(w, "\nCache %s vs %s\n", formatSize(b.CacheSize), formatSize(a.CacheSize))
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "%-18s\tops/s %s\tops/s %s\tchange\t\n", "operation", formatSize(a.CacheSize), formatSize(b.CacheSize))
+	for i, op := range a.Ops {
+		change := "-"
+		if op.OpsPerSec > 0 {
+			change = fmt.Sprintf("%+.1f%%", (b.Ops[i].OpsPerSec/op.OpsPerSec-1)*100)
+		}
+		fmt.Fprintf(tw, "%-18s\t%.0f\t%.0f\t%s\t\n", op.Name, op.OpsPerSec, b.Ops[i].OpsPerSec, change)
+	}
+	tw.Flush()
+}
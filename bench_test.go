@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunBenchmark_TableAndJSON(t *testing.T) {
+	config := DefaultConfiguration()
+	config.CacheSize = 4 * 1024 * 1024
+	jsonPath := filepath.Join(t.TempDir(), "bench.json")
+	var out strings.Builder
+	err := runBenchmark(&out, config, benchOptions{
+		Files:        12,
+		Sizes:        []int64{512, 4096},
+		Iterations:   2,
+		CompareCache: 64 * 1024,
+		JSONPath:     jsonPath,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report benchReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"read_cold", "read_warm", "write", "edit", "multi_edit", "list_directory", "smart_search", "search_and_replace"}
+	if len(report.Runs) != 2 || report.Runs[1].CacheSize != 64*1024 {
+		t.Fatalf("runs = %+v", report.Runs)
+	}
+	for _, run := range report.Runs {
+		if len(run.Ops) != len(want) {
+			t.Fatalf("ops = %+v", run.Ops)
+		}
+		for i, op := range run.Ops {
+			if op.Name != want[i] || op.Ops == 0 || op.P50MS > op.P99MS || op.P99MS > op.MaxMS {
+				t.Fatalf("op %d = %+v", i, op)
+			}
+		}
+		if run.Ops[0].Ops != 12 || run.Ops[0].Bytes != 6*512+6*4096 {
+			t.Fatalf("read_cold = %+v", run.Ops[0])
+		}
+	}
+
+	table := out.String()
+	for _, s := range append(want, "Cache 4.0 MB", "Cache 64.0 KB vs 4.0 MB", "p95 ms") {
+		if !strings.Contains(table, s) {
+			t.Fatalf("table lacks %q:\n%s", s, table)
+		}
+	}
+}
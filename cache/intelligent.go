@@ -1,9 +1,11 @@
 package cache
 
 import (
+	"encoding/binary"
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/allegro/bigcache/v3"
@@ -21,13 +23,40 @@ type IntelligentCache struct {
 	// Metadata cache (file info, stats, etc.)
 	metaCache *gocache.Cache
 
+	// Negative cache: recent "not found" results (see negative.go)
+	negCache    *gocache.Cache
+	negativeTTL time.Duration
+
 	// Cache statistics
 	stats *CacheStats
 
 	// Configuration
-	maxSize     int64
-	currentSize int64
-	mu          sync.RWMutex
+	maxSize int64
+	mu      sync.RWMutex
+
+	// currentSize is the exact number of file content bytes stored: added
+	// when an entry is stored, subtracted by the bigcache removal callback
+	currentSize atomic.Int64
+
+	// statValidation makes GetFile stat the file on every hit and drop the
+	// entry when its size or mtime no longer match the ones recorded by
+	// SetFile (external editors, git checkout, builds). Default: enabled.
+	statValidation bool
+
+	// Admission rules (see SetMaxFileSize, SetFileTTL, SetNeverCache).
+	// Reads of paths the rules keep out are counted as bypasses, not misses.
+	maxFileSize int64
+	fileTTL     time.Duration
+	neverCache  func(path string) bool
+	oversized   map[string]bool // paths SetFile rejected for size
+
+	// watched reports paths whose changes a file watcher reports; hits on
+	// them skip the stat check (the watcher invalidates them instead)
+	watched func(path string) bool
+
+	// Snapshot written on Close (EnablePersistence); empty = disabled
+	persistPath   string
+	persistBudget int64
 
 	// Prefetch tracking for predictive caching
 	accessPattern map[string]int64 // path -> access count
@@ -47,8 +76,28 @@ type CacheStats struct {
 	MetaHits   int64
 	MetaMisses int64
 
-	// Eviction counters
-	Evictions int64
+	// Bytes returned by file and directory hits, and file bytes read from
+	// disk on misses (NoteDiskRead): what the cache saved vs what it cost
+	FileBytesServed int64
+	DirBytesServed  int64
+	FileBytesRead   int64
+
+	// Eviction counters: Evictions counts directory/metadata entries that
+	// expired or were removed; FileEvictions counts file entries bigcache
+	// dropped because they expired or to make room.
+	Evictions     int64
+	FileEvictions int64
+
+	// File entries dropped on hit because the file changed on disk, or
+	// because they outlived the file TTL
+	StaleFiles   int64
+	ExpiredFiles int64
+
+	// Reads of never-cache or oversized paths (not counted as misses)
+	FileBypasses int64
+
+	// Lookups answered from the negative ("not found") cache
+	NegativeHits int64
 
 	// Timing stats
 	LastAccess    time.Time
@@ -69,6 +118,23 @@ func NewIntelligentCache(maxSize int64) (*IntelligentCache, error) {
 	}
 	// Approximate max size: MaxEntriesInWindow * MaxEntrySize ≈ maxSize / 2
 	bigConfig.MaxEntriesInWindow = int((maxSize / 2) / int64(bigConfig.MaxEntrySize))
+
+	// Track removals: every removed entry gives its bytes back and expiry or
+	// space evictions are counted (runs under a bigcache shard lock: only
+	// touch the counters here, never the cache itself)
+	stats := &CacheStats{}
+	var cache *IntelligentCache
+	bigConfig.OnRemoveWithReason = func(key string, entry []byte, reason bigcache.RemoveReason) {
+		if len(entry) >= fileStampSize {
+			cache.currentSize.Add(-int64(len(entry) - fileStampSize))
+		}
+		if reason != bigcache.Deleted {
+			stats.mu.Lock()
+			stats.FileEvictions++
+			stats.mu.Unlock()
+		}
+	}
+
 	fileCache, err := bigcache.NewBigCache(bigConfig)
 	if err != nil {
 		return nil, err
@@ -78,15 +144,19 @@ func NewIntelligentCache(maxSize int64) (*IntelligentCache, error) {
 	dirCache := gocache.New(3*time.Minute, 1*time.Minute)
 	metaCache := gocache.New(10*time.Minute, 2*time.Minute)
 
-	cache := &IntelligentCache{
-		fileCache:     fileCache,
-		dirCache:      dirCache,
-		metaCache:     metaCache,
-		stats:         &CacheStats{},
-		maxSize:       maxSize,
-		currentSize:   0,
-		accessPattern: make(map[string]int64),
-		prefetchQueue: make(chan string, 100), // Buffer for prefetch requests
+	cache = &IntelligentCache{
+		fileCache:      fileCache,
+		dirCache:       dirCache,
+		metaCache:      metaCache,
+		negCache:       newNegativeCache(),
+		negativeTTL:    DefaultNegativeTTL,
+		stats:          stats,
+		maxSize:        maxSize,
+		accessPattern:  make(map[string]int64),
+		prefetchQueue:  make(chan string, 100), // Buffer for prefetch requests
+		statValidation: true,
+		maxFileSize:    DefaultMaxFileSize,
+		oversized:      make(map[string]bool),
 	}
 
 	// Set up eviction callbacks (bigcache doesn't have direct OnEvicted, but we can track via stats)
@@ -99,35 +169,210 @@ func NewIntelligentCache(maxSize int64) (*IntelligentCache, error) {
 	return cache, nil
 }
 
-// GetFile retrieves a file from cache
+// fileStampSize is the header stored before each cached file's content:
+// the file size and mtime (UnixNano) observed when it was cached, and when it
+// was cached (UnixNano, for the TTL).
+const fileStampSize = 24
+
+// DefaultMaxFileSize is the largest file content SetFile caches by default
+const DefaultMaxFileSize = 2 * 1024 * 1024
+
+// maxOversizedTracked bounds the set of paths remembered as too large to cache
+const maxOversizedTracked = 1024
+
+// SetMaxFileSize sets the largest file content SetFile caches (<= 0 = no
+// limit). One huge file would otherwise evict everything else in its shard.
+func (c *IntelligentCache) SetMaxFileSize(n int64) {
+	c.mu.Lock()
+	c.maxFileSize = n
+	c.oversized = make(map[string]bool)
+	c.mu.Unlock()
+}
+
+// SetFileTTL makes GetFile treat file entries older than ttl as misses
+// (0 = no per-entry TTL; bigcache's LifeWindow still applies).
+func (c *IntelligentCache) SetFileTTL(ttl time.Duration) {
+	c.mu.Lock()
+	c.fileTTL = ttl
+	c.mu.Unlock()
+}
+
+// SetNeverCache installs a predicate for paths whose content must never be
+// cached (logs and other files that change constantly); nil caches all paths.
+func (c *IntelligentCache) SetNeverCache(match func(path string) bool) {
+	c.mu.Lock()
+	c.neverCache = match
+	c.mu.Unlock()
+}
+
+// SetWatched installs the predicate for paths covered by a file watcher
+// (nil = none). Hits on those paths are not stat-checked.
+func (c *IntelligentCache) SetWatched(watched func(path string) bool) {
+	c.mu.Lock()
+	c.watched = watched
+	c.mu.Unlock()
+}
+
+// bypassed reports whether the admission rules keep path out of the cache
+func (c *IntelligentCache) bypassed(path string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.oversized[path] || (c.neverCache != nil && c.neverCache(path))
+}
+
+// stampEntry prepends the stamp header to content
+func stampEntry(size, mtime int64, content []byte) []byte {
+	entry := make([]byte, fileStampSize+len(content))
+	binary.LittleEndian.PutUint64(entry[:8], uint64(size))
+	binary.LittleEndian.PutUint64(entry[8:16], uint64(mtime))
+	binary.LittleEndian.PutUint64(entry[16:fileStampSize], uint64(time.Now().UnixNano()))
+	copy(entry[fileStampSize:], content)
+	return entry
+}
+
+// readStamp decodes the stamp header of a stored entry
+func readStamp(entry []byte) (size, mtime int64, cachedAt time.Time) {
+	size = int64(binary.LittleEndian.Uint64(entry[:8]))
+	mtime = int64(binary.LittleEndian.Uint64(entry[8:16]))
+	cachedAt = time.Unix(0, int64(binary.LittleEndian.Uint64(entry[16:fileStampSize])))
+	return size, mtime, cachedAt
+}
+
+// storeFile writes a stamped entry, replacing any previous one for path.
+// The old entry is deleted first so the removal callback gives its bytes
+// back (bigcache overwrites silently). Callers hold c.mu.
+func (c *IntelligentCache) storeFile(path string, entry []byte) error {
+	_ = c.fileCache.Delete(path)
+	if err := c.fileCache.Set(path, entry); err != nil {
+		return err
+	}
+	c.currentSize.Add(int64(len(entry) - fileStampSize))
+	return nil
+}
+
+// SetStatValidation enables or disables the size/mtime check on GetFile.
+// Disabling it saves one stat per hit at the cost of possibly serving
+// content another process has since changed (until the entry expires).
+func (c *IntelligentCache) SetStatValidation(enabled bool) {
+	c.mu.Lock()
+	c.statValidation = enabled
+	c.mu.Unlock()
+}
+
+// StatValidation reports whether cache hits are checked against the file
+func (c *IntelligentCache) StatValidation() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.statValidation
+}
+
+// GetFile retrieves a file from cache. With stat validation enabled, a hit
+// whose file is gone or whose size/mtime changed since SetFile is dropped and
+// reported as a miss; so is an entry older than the file TTL. Paths the
+// admission rules keep out of the cache are counted as bypasses, so they do
+// not drag the hit rate down.
 func (c *IntelligentCache) GetFile(path string) ([]byte, bool) {
 	c.updateAccessStats()
+	return c.getFile(path, true)
+}
+
+// PeekFile is GetFile without the hit/miss accounting, for re-checks of a
+// lookup that was already counted (e.g. after waiting on a concurrent load).
+// Stale and expired entries are still dropped.
+func (c *IntelligentCache) PeekFile(path string) ([]byte, bool) {
+	return c.getFile(path, false)
+}
+
+func (c *IntelligentCache) getFile(path string, count bool) ([]byte, bool) {
+	if c.bypassed(path) {
+		if count {
+			c.stats.mu.Lock()
+			c.stats.FileBypasses++
+			c.stats.mu.Unlock()
+		}
+		return nil, false
+	}
 
 	item, err := c.fileCache.Get(path)
-	if err == nil {
+	if err == nil && len(item) >= fileStampSize {
+		size, mtime, cachedAt := readStamp(item)
+		c.mu.RLock()
+		ttl, validate, watched := c.fileTTL, c.statValidation, c.watched
+		c.mu.RUnlock()
+		if validate && watched != nil && watched(path) {
+			validate = false
+		}
+		switch {
+		case ttl > 0 && time.Since(cachedAt) > ttl:
+			c.fileCache.Delete(path)
+			c.stats.mu.Lock()
+			c.stats.ExpiredFiles++
+			c.stats.mu.Unlock()
+		case !validate || fileUnchanged(path, size, mtime):
+			if count {
+				c.stats.mu.Lock()
+				c.stats.FileHits++
+				c.stats.FileBytesServed += int64(len(item) - fileStampSize)
+				c.stats.mu.Unlock()
+			}
+			return item[fileStampSize:], true
+		default:
+			c.fileCache.Delete(path)
+			c.stats.mu.Lock()
+			c.stats.StaleFiles++
+			c.stats.mu.Unlock()
+		}
+	}
+
+	if count {
 		c.stats.mu.Lock()
-		c.stats.FileHits++
+		c.stats.FileMisses++
 		c.stats.mu.Unlock()
-		return item, true
 	}
-
-	c.stats.mu.Lock()
-	c.stats.FileMisses++
-	c.stats.mu.Unlock()
-
 	return nil, false
 }
 
-// SetFile stores a file in cache with intelligent size management
+// fileUnchanged reports whether path still has the recorded size and mtime
+func fileUnchanged(path string, size, mtime int64) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Size() == size && info.ModTime().UnixNano() == mtime
+}
+
+// SetFile stores a file in cache with intelligent size management, together
+// with the file's current size and mtime for stale detection. Content that no
+// longer matches the file's size (it changed right after being read) is not
+// cached, and neither are never-cache paths or content over the max file size.
 func (c *IntelligentCache) SetFile(path string, content []byte) {
+	c.mu.RLock()
+	maxFileSize, neverCache, validate := c.maxFileSize, c.neverCache, c.statValidation
+	c.mu.RUnlock()
+	if neverCache != nil && neverCache(path) {
+		return
+	}
+
+	var size, mtime int64
+	if validate {
+		info, err := os.Stat(path)
+		if err != nil || info.Size() != int64(len(content)) {
+			return
+		}
+		size, mtime = info.Size(), info.ModTime().UnixNano()
+	}
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	// Bigcache handles size and eviction automatically
-	err := c.fileCache.Set(path, content)
-	if err == nil {
-		c.currentSize += int64(len(content)) // Approximate tracking
+	if maxFileSize > 0 && int64(len(content)) > maxFileSize {
+		if len(c.oversized) < maxOversizedTracked {
+			c.oversized[path] = true
+		}
+		_ = c.fileCache.Delete(path)
+		return
 	}
+	delete(c.oversized, path)
+
+	// Bigcache handles size and eviction automatically
+	_ = c.storeFile(path, stampEntry(size, mtime, content))
 }
 
 // dirCacheEntry pairs a directory listing with the directory's mtime at cache time.
@@ -144,11 +389,12 @@ func (c *IntelligentCache) GetDirectory(path string) (string, time.Time, bool) {
 	c.updateAccessStats()
 
 	if item, found := c.dirCache.Get(path); found {
+		entry := item.(dirCacheEntry)
 		c.stats.mu.Lock()
 		c.stats.DirHits++
+		c.stats.DirBytesServed += int64(len(entry.Listing))
 		c.stats.mu.Unlock()
 
-		entry := item.(dirCacheEntry)
 		// Refresh TTL without changing the stored mtime
 		c.dirCache.Set(path, entry, gocache.DefaultExpiration)
 
@@ -192,16 +438,10 @@ func (c *IntelligentCache) SetMetadata(key string, value interface{}) {
 	c.metaCache.Set(key, value, gocache.DefaultExpiration)
 }
 
-// InvalidateFile removes a file from cache
+// InvalidateFile removes a file from cache (the removal callback gives its
+// bytes back)
 func (c *IntelligentCache) InvalidateFile(path string) {
-	err := c.fileCache.Delete(path)
-	if err == nil {
-		// Approximate size update
-		c.mu.Lock()
-		// Note: Without exact size, we might need to adjust tracking
-		c.currentSize -= 0 // Placeholder; bigcache doesn't provide evicted size
-		c.mu.Unlock()
-	}
+	_ = c.fileCache.Delete(path)
 }
 
 // InvalidateDirectory removes a directory listing from cache
@@ -224,6 +464,25 @@ func (c *IntelligentCache) updateAccessStats() {
 	c.stats.mu.Unlock()
 }
 
+// NoteDiskRead records n bytes of file content read from disk after a miss
+func (c *IntelligentCache) NoteDiskRead(n int) {
+	c.stats.mu.Lock()
+	c.stats.FileBytesRead += int64(n)
+	c.stats.mu.Unlock()
+}
+
+// TypeStats returns hit/miss and bytes-served counters per entry type (file,
+// dir, meta). Unlike Report it does not walk the entries, so it is cheap
+// enough for every stats call.
+func (c *IntelligentCache) TypeStats() []CacheTypeStats {
+	stats := c.GetStats()
+	return []CacheTypeStats{
+		{Type: "file", Hits: stats.FileHits, Misses: stats.FileMisses, Served: stats.FileBytesServed},
+		{Type: "dir", Hits: stats.DirHits, Misses: stats.DirMisses, Served: stats.DirBytesServed},
+		{Type: "meta", Hits: stats.MetaHits, Misses: stats.MetaMisses},
+	}
+}
+
 // GetHitRate calculates the overall cache hit rate
 func (c *IntelligentCache) GetHitRate() float64 {
 	c.stats.mu.RLock()
@@ -240,11 +499,23 @@ func (c *IntelligentCache) GetHitRate() float64 {
 	return float64(totalHits) / float64(total)
 }
 
-// GetMemoryUsage returns current memory usage in bytes (approximate for bigcache)
+// GetMemoryUsage returns the bytes of file content currently cached. Entries
+// removed for any reason (invalidation, TTL, stale, eviction) are subtracted;
+// the memory bigcache has allocated for its shards is Report().Allocated.
 func (c *IntelligentCache) GetMemoryUsage() int64 {
+	return c.currentSize.Load()
+}
+
+// MaxSize returns the memory limit the cache was created with
+func (c *IntelligentCache) MaxSize() int64 {
+	return c.maxSize
+}
+
+// MaxFileSize returns the largest file content cached (<= 0 = no limit)
+func (c *IntelligentCache) MaxFileSize() int64 {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return c.currentSize + int64(c.fileCache.Capacity()) // Use bigcache capacity as estimate
+	return c.maxFileSize
 }
 
 // GetStats returns detailed cache statistics (copy without mutex)
@@ -253,15 +524,23 @@ func (c *IntelligentCache) GetStats() CacheStats {
 	defer c.stats.mu.RUnlock()
 	// Return a copy without the mutex
 	return CacheStats{
-		FileHits:      c.stats.FileHits,
-		FileMisses:    c.stats.FileMisses,
-		DirHits:       c.stats.DirHits,
-		DirMisses:     c.stats.DirMisses,
-		MetaHits:      c.stats.MetaHits,
-		MetaMisses:    c.stats.MetaMisses,
-		Evictions:     c.stats.Evictions,
-		LastAccess:    c.stats.LastAccess,
-		TotalAccesses: c.stats.TotalAccesses,
+		FileHits:        c.stats.FileHits,
+		FileMisses:      c.stats.FileMisses,
+		DirHits:         c.stats.DirHits,
+		DirMisses:       c.stats.DirMisses,
+		MetaHits:        c.stats.MetaHits,
+		MetaMisses:      c.stats.MetaMisses,
+		FileBytesServed: c.stats.FileBytesServed,
+		DirBytesServed:  c.stats.DirBytesServed,
+		FileBytesRead:   c.stats.FileBytesRead,
+		Evictions:       c.stats.Evictions,
+		FileEvictions:   c.stats.FileEvictions,
+		StaleFiles:      c.stats.StaleFiles,
+		ExpiredFiles:    c.stats.ExpiredFiles,
+		FileBypasses:    c.stats.FileBypasses,
+		NegativeHits:    c.stats.NegativeHits,
+		LastAccess:      c.stats.LastAccess,
+		TotalAccesses:   c.stats.TotalAccesses,
 	}
 }
 
@@ -281,19 +560,33 @@ func (c *IntelligentCache) onMetaEvicted(key string, value interface{}) {
 	c.stats.mu.Unlock()
 }
 
-// Flush clears all caches
+// Flush clears all caches. File entries are deleted one by one rather than
+// with bigcache's Reset, which reallocates a MaxEntrySize buffer for every
+// shard (about 256MB with this configuration); the removal callback gives
+// their bytes back to currentSize.
 func (c *IntelligentCache) Flush() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.fileCache.Reset()
+	var keys []string
+	it := c.fileCache.Iterator()
+	for it.SetNext() {
+		if info, err := it.Value(); err == nil {
+			keys = append(keys, info.Key())
+		}
+	}
+	for _, key := range keys {
+		_ = c.fileCache.Delete(key)
+	}
 	c.dirCache.Flush()
 	c.metaCache.Flush()
-	c.currentSize = 0
+	c.negCache.Flush()
 }
 
-// Close gracefully shuts down the cache
+// Close gracefully shuts down the cache, writing the snapshot first when
+// persistence is enabled
 func (c *IntelligentCache) Close() error {
+	c.saveOnClose()
 	close(c.prefetchQueue) // Signal prefetch worker to stop
 	err := c.fileCache.Close()
 	c.Flush()
@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	gocache "github.com/patrickmn/go-cache"
+)
+
+// DefaultNegativeTTL is how long a "not found" result is remembered
+const DefaultNegativeTTL = 5 * time.Second
+
+// missingEntry holds the not-found error each operation (read, stat, ...)
+// returned for a path. Entries are replaced, never mutated in place.
+type missingEntry map[string]error
+
+// SetNegativeTTL sets how long not-found results are cached (0 disables
+// negative caching and drops the entries already cached).
+func (c *IntelligentCache) SetNegativeTTL(ttl time.Duration) {
+	c.mu.Lock()
+	c.negativeTTL = ttl
+	c.mu.Unlock()
+	if ttl <= 0 {
+		c.negCache.Flush()
+	}
+}
+
+// GetMissing returns the not-found error op returned for path within the
+// negative TTL, so a repeated probe of a missing path skips the disk.
+func (c *IntelligentCache) GetMissing(path, op string) (error, bool) {
+	item, found := c.negCache.Get(path)
+	if !found {
+		return nil, false
+	}
+	err, ok := item.(missingEntry)[op]
+	if !ok {
+		return nil, false
+	}
+	c.stats.mu.Lock()
+	c.stats.NegativeHits++
+	c.stats.mu.Unlock()
+	return err, true
+}
+
+// SetMissing remembers that op found no file at path, returning err
+func (c *IntelligentCache) SetMissing(path, op string, err error) {
+	c.mu.RLock()
+	ttl := c.negativeTTL
+	c.mu.RUnlock()
+	if ttl <= 0 || path == "" {
+		return
+	}
+	entry := missingEntry{op: err}
+	if item, found := c.negCache.Get(path); found {
+		for k, v := range item.(missingEntry) {
+			if k != op {
+				entry[k] = v
+			}
+		}
+	}
+	c.negCache.Set(path, entry, ttl)
+}
+
+// InvalidateMissing forgets the not-found results for path and for every
+// path below it (a created or moved-in directory brings its children along).
+func (c *IntelligentCache) InvalidateMissing(path string) {
+	if path == "" || c.negCache.ItemCount() == 0 {
+		return
+	}
+	c.negCache.Delete(path)
+	prefix := strings.TrimSuffix(path, string(os.PathSeparator)) + string(os.PathSeparator)
+	for key := range c.negCache.Items() {
+		if strings.HasPrefix(key, prefix) {
+			c.negCache.Delete(key)
+		}
+	}
+}
+
+// newNegativeCache creates the store behind GetMissing/SetMissing
+func newNegativeCache() *gocache.Cache {
+	return gocache.New(DefaultNegativeTTL, 30*time.Second)
+}
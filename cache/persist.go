@@ -0,0 +1,213 @@
+package cache
+
+import (
+	"encoding/gob"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// snapshotVersion is bumped whenever the snapshot layout changes; a snapshot
+// with another version is ignored (cold start).
+const snapshotVersion = 1
+
+// snapshotEntry is one cached file in a snapshot. Size and Mtime are the
+// stamp recorded when the file was cached; Content is nil for entries that
+// did not fit the content budget (index only).
+type snapshotEntry struct {
+	Path    string
+	Size    int64
+	Mtime   int64
+	Hash    uint64
+	Hits    int64
+	Content []byte
+}
+
+type cacheSnapshot struct {
+	Version int
+	SavedAt time.Time
+	Entries []snapshotEntry
+}
+
+// PersistStats summarizes a snapshot save or load
+type PersistStats struct {
+	Entries  int   // index entries written / read
+	Contents int   // entries whose content was written / restored
+	Bytes    int64 // content bytes written / restored
+	Skipped  int   // load only: entries whose file changed or content was damaged
+}
+
+// EnablePersistence loads the snapshot a previous run left at path and makes
+// Close write a fresh one there, with the hottest file contents up to budget
+// bytes. A missing file is a normal cold start; an unreadable or corrupt one
+// is reported through the error, but persistence stays enabled and the cache
+// is usable either way.
+func (c *IntelligentCache) EnablePersistence(path string, budget int64) (PersistStats, error) {
+	c.mu.Lock()
+	c.persistPath, c.persistBudget = path, budget
+	c.mu.Unlock()
+	return c.LoadSnapshot(path)
+}
+
+// SaveSnapshot writes the file cache index (path, size, mtime, hash) and the
+// contents of the most accessed files, up to budget bytes, to path. Entries
+// cached without a size/mtime stamp (stat validation off) cannot be checked
+// on load and are left out.
+func (c *IntelligentCache) SaveSnapshot(path string, budget int64) (PersistStats, error) {
+	access := c.GetAccessStats()
+	var entries []snapshotEntry
+	it := c.fileCache.Iterator()
+	for it.SetNext() {
+		info, err := it.Value()
+		if err != nil {
+			continue
+		}
+		item := info.Value()
+		if len(item) < fileStampSize {
+			continue
+		}
+		size, mtime, _ := readStamp(item)
+		if mtime == 0 {
+			continue
+		}
+		content := item[fileStampSize:]
+		entries = append(entries, snapshotEntry{
+			Path:    info.Key(),
+			Size:    size,
+			Mtime:   mtime,
+			Hash:    contentHash(content),
+			Hits:    access[info.Key()],
+			Content: content,
+		})
+	}
+
+	// Hottest first; among equally hot files prefer the small ones so more
+	// of them fit the budget
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Hits != entries[j].Hits {
+			return entries[i].Hits > entries[j].Hits
+		}
+		return entries[i].Size < entries[j].Size
+	})
+	var stats PersistStats
+	for i := range entries {
+		if stats.Bytes+entries[i].Size <= budget {
+			stats.Bytes += entries[i].Size
+			stats.Contents++
+		} else {
+			entries[i].Content = nil
+		}
+	}
+	stats.Entries = len(entries)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return stats, err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".cache-snapshot-*")
+	if err != nil {
+		return stats, err
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+	err = gob.NewEncoder(tmp).Encode(cacheSnapshot{Version: snapshotVersion, SavedAt: time.Now(), Entries: entries})
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0600)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	return stats, err
+}
+
+// LoadSnapshot restores the entries of a snapshot whose file still has the
+// recorded size and mtime. Restored contents must also match their hash and
+// pass the current admission rules (never-cache, max file size); index-only
+// entries are queued for background prefetch. Access counts are
+// restored so the next snapshot keeps the same files hot.
+func (c *IntelligentCache) LoadSnapshot(path string) (PersistStats, error) {
+	var stats PersistStats
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return stats, nil
+	}
+	if err != nil {
+		return stats, err
+	}
+	defer f.Close()
+
+	var snap cacheSnapshot
+	if err := gob.NewDecoder(f).Decode(&snap); err != nil {
+		return stats, fmt.Errorf("corrupt cache snapshot %s: %w", path, err)
+	}
+	if snap.Version != snapshotVersion {
+		return stats, fmt.Errorf("cache snapshot %s has version %d, want %d", path, snap.Version, snapshotVersion)
+	}
+
+	for _, e := range snap.Entries {
+		stats.Entries++
+		if !fileUnchanged(e.Path, e.Size, e.Mtime) {
+			stats.Skipped++
+			continue
+		}
+		if e.Hits > 0 {
+			c.patternMu.Lock()
+			c.accessPattern[e.Path] = e.Hits
+			c.patternMu.Unlock()
+		}
+		if e.Content == nil {
+			select {
+			case c.prefetchQueue <- e.Path:
+			default:
+			}
+			continue
+		}
+		c.mu.RLock()
+		maxFileSize := c.maxFileSize
+		c.mu.RUnlock()
+		if c.bypassed(e.Path) || (maxFileSize > 0 && e.Size > maxFileSize) {
+			continue // admission rules changed since the snapshot was written
+		}
+		if int64(len(e.Content)) != e.Size || contentHash(e.Content) != e.Hash {
+			stats.Skipped++
+			continue
+		}
+		c.mu.Lock()
+		err := c.storeFile(e.Path, stampEntry(e.Size, e.Mtime, e.Content))
+		c.mu.Unlock()
+		if err == nil {
+			stats.Contents++
+			stats.Bytes += e.Size
+		}
+	}
+	return stats, nil
+}
+
+// saveOnClose writes the snapshot when persistence is enabled; failures are
+// logged, never fatal (the next start is just cold).
+func (c *IntelligentCache) saveOnClose() {
+	c.mu.RLock()
+	path, budget := c.persistPath, c.persistBudget
+	c.mu.RUnlock()
+	if path == "" {
+		return
+	}
+	stats, err := c.SaveSnapshot(path, budget)
+	if err != nil {
+		slog.Warn("Failed to save cache snapshot", "path", path, "error", err)
+		return
+	}
+	slog.Info("Cache snapshot saved", "path", path, "entries", stats.Entries, "contents", stats.Contents, "bytes", stats.Bytes)
+}
+
+// contentHash is the integrity hash stored for each snapshot entry
+func contentHash(content []byte) uint64 {
+	h := fnv.New64a()
+	h.Write(content)
+	return h.Sum64()
+}
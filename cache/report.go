@@ -0,0 +1,175 @@
+package cache
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// CacheTypeStats is the per-type breakdown of a CacheReport
+type CacheTypeStats struct {
+	Type    string `json:"type"` // file, dir, meta
+	Entries int    `json:"entries"`
+	Bytes   int64  `json:"bytes"` // 0 for metadata (sizes unknown)
+	Hits    int64  `json:"hits"`
+	Misses  int64  `json:"misses"`
+	Served  int64  `json:"bytes_served"` // bytes returned by hits (0 for metadata)
+}
+
+// HitRate is Hits / (Hits + Misses), 0 before the first lookup
+func (t CacheTypeStats) HitRate() float64 {
+	if t.Hits+t.Misses == 0 {
+		return 0
+	}
+	return float64(t.Hits) / float64(t.Hits+t.Misses)
+}
+
+// CacheEntryInfo describes one cached entry
+type CacheEntryInfo struct {
+	Key  string `json:"key"`
+	Type string `json:"type"`
+	Size int64  `json:"size"`
+}
+
+// CacheReport is a snapshot of what the cache holds and how it performed
+type CacheReport struct {
+	Types          []CacheTypeStats `json:"types"`
+	BytesUsed      int64            `json:"bytes_used"`
+	Capacity       int64            `json:"capacity"`
+	Allocated      int64            `json:"allocated"` // bigcache shard memory currently allocated
+	HitRate        float64          `json:"hit_rate"`
+	BytesFromDisk  int64            `json:"bytes_from_disk"` // file bytes read on misses
+	FileEvictions  int64            `json:"file_evictions"`
+	OtherEvictions int64            `json:"other_evictions"`
+	StaleFiles     int64            `json:"stale_files"`
+	ExpiredFiles   int64            `json:"expired_files"` // outlived the file TTL
+	Bypasses       int64            `json:"bypasses"`      // reads of never-cache or oversized paths
+	NegativeItems  int              `json:"negative_entries"`
+	NegativeHits   int64            `json:"negative_hits"` // probes of missing paths answered without disk I/O
+	StatValidation bool             `json:"stat_validation"`
+	MaxFileSize    int64            `json:"max_file_size"` // 0 = no limit
+	FileTTL        time.Duration    `json:"file_ttl"`      // 0 = none
+	Largest        []CacheEntryInfo `json:"largest,omitempty"`
+}
+
+// Report returns a snapshot of the cache with the topN largest entries.
+// It walks every file entry (bigcache copies each value while iterating), so
+// it is meant for on-demand inspection, not hot paths.
+func (c *IntelligentCache) Report(topN int) CacheReport {
+	stats := c.GetStats()
+	types := c.TypeStats()
+	files, dirs, meta := types[0], types[1], types[2]
+	meta.Entries = c.metaCache.ItemCount()
+
+	var entries []CacheEntryInfo
+	it := c.fileCache.Iterator()
+	for it.SetNext() {
+		info, err := it.Value()
+		if err != nil {
+			continue // removed while iterating
+		}
+		size := int64(len(info.Value()) - fileStampSize)
+		if size < 0 {
+			size = 0
+		}
+		files.Entries++
+		files.Bytes += size
+		entries = append(entries, CacheEntryInfo{Key: info.Key(), Type: "file", Size: size})
+	}
+	for key, item := range c.dirCache.Items() {
+		size := int64(0)
+		if entry, ok := item.Object.(dirCacheEntry); ok {
+			size = int64(len(entry.Listing))
+		}
+		dirs.Entries++
+		dirs.Bytes += size
+		entries = append(entries, CacheEntryInfo{Key: key, Type: "dir", Size: size})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Size != entries[j].Size {
+			return entries[i].Size > entries[j].Size
+		}
+		return entries[i].Key < entries[j].Key
+	})
+	if topN < 0 {
+		topN = 0
+	}
+	if len(entries) > topN {
+		entries = entries[:topN]
+	}
+
+	c.mu.RLock()
+	maxFileSize, ttl := c.maxFileSize, c.fileTTL
+	c.mu.RUnlock()
+	return CacheReport{
+		Types:          []CacheTypeStats{files, dirs, meta},
+		BytesUsed:      files.Bytes + dirs.Bytes,
+		Capacity:       c.maxSize,
+		Allocated:      int64(c.fileCache.Capacity()),
+		HitRate:        c.GetHitRate(),
+		BytesFromDisk:  stats.FileBytesRead,
+		FileEvictions:  stats.FileEvictions,
+		OtherEvictions: stats.Evictions,
+		StaleFiles:     stats.StaleFiles,
+		ExpiredFiles:   stats.ExpiredFiles,
+		Bypasses:       stats.FileBypasses,
+		NegativeItems:  c.negCache.ItemCount(),
+		NegativeHits:   stats.NegativeHits,
+		StatValidation: c.StatValidation(),
+		MaxFileSize:    maxFileSize,
+		FileTTL:        ttl,
+		Largest:        entries,
+	}
+}
+
+// InvalidatePrefix removes every file, directory, metadata and negative entry whose
+// key starts with prefix and returns how many were removed. Keys are
+// normalized paths (directory JSON listings use "<dir>::json").
+func (c *IntelligentCache) InvalidatePrefix(prefix string) int {
+	if prefix == "" {
+		return 0
+	}
+	var keys []string
+	it := c.fileCache.Iterator()
+	for it.SetNext() {
+		if info, err := it.Value(); err == nil && strings.HasPrefix(info.Key(), prefix) {
+			keys = append(keys, info.Key())
+		}
+	}
+	removed := 0
+	for _, key := range keys {
+		if c.fileCache.Delete(key) == nil {
+			removed++
+		}
+	}
+	for key := range c.dirCache.Items() {
+		if strings.HasPrefix(key, prefix) {
+			c.dirCache.Delete(key)
+			removed++
+		}
+	}
+	for key := range c.metaCache.Items() {
+		if strings.HasPrefix(key, prefix) {
+			c.metaCache.Delete(key)
+			removed++
+		}
+	}
+	for key := range c.negCache.Items() {
+		if strings.HasPrefix(key, prefix) {
+			c.negCache.Delete(key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Clear drops every entry and returns how many were dropped; hit/miss
+// counters are kept. It is safe while reads are in flight: GetFile hands
+// out copies, and a read that completes after Clear only re-caches content
+// stamped with the size/mtime it was read at.
+func (c *IntelligentCache) Clear() int {
+	dropped := c.fileCache.Len() + c.dirCache.ItemCount() + c.metaCache.ItemCount() + c.negCache.ItemCount()
+	c.Flush()
+	return dropped
+}
@@ -0,0 +1,297 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Where an allowed path came from
+const (
+	AllowedPathStartup   = "startup"   // --allowed-paths or positional arguments
+	AllowedPathPersisted = "persisted" // AllowedPathsFile, survives restarts
+	AllowedPathRuntime   = "runtime"   // allowed_paths add, gone after restart
+)
+
+// allowedPathsFileState is the AllowedPathsFile format
+type allowedPathsFileState struct {
+	Paths []string `json:"paths"`
+}
+
+// AllowedPathInfo is one entry of allowed_paths list
+type AllowedPathInfo struct {
+	Path     string `json:"path"`
+	Resolved string `json:"resolved,omitempty"` // symlink-resolved form, when different
+	Source   string `json:"source"`
+	Exists   bool   `json:"exists"`
+}
+
+// AllowedPathsListing is the allowed_paths list result
+type AllowedPathsListing struct {
+	Paths             []AllowedPathInfo `json:"paths"` // empty = access control off
+	ManagementEnabled bool              `json:"management_enabled"`
+	PersistFile       string            `json:"persist_file,omitempty"`
+}
+
+// allowedPathKey is the form two allowed paths are compared in
+func allowedPathKey(path string) string {
+	abs, err := filepath.Abs(canonicalWSLForm(NormalizePath(path)))
+	if err != nil {
+		abs = path
+	}
+	abs = filepath.Clean(abs)
+	if os.PathSeparator == '\\' {
+		return strings.ToLower(abs)
+	}
+	return abs
+}
+
+// loadPersistedAllowedPaths appends the directories kept in AllowedPathsFile
+// to AllowedPaths. Runs during engine construction, before the engine is
+// shared. Entries that no longer exist are skipped with a warning.
+func (e *UltraFastEngine) loadPersistedAllowedPaths() {
+	paths, err := readAllowedPathsFile(e.config.AllowedPathsFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			slog.Warn("Persisted allowed paths unusable", "file", e.config.AllowedPathsFile, "error", err)
+		}
+		return
+	}
+	e.allowedSources = make(map[string]string)
+	for _, p := range paths {
+		if info, err := os.Stat(p); err != nil || !info.IsDir() {
+			slog.Warn("Skipping persisted allowed path: not a directory", "path", p)
+			continue
+		}
+		if slices.ContainsFunc(e.config.AllowedPaths, func(a string) bool { return allowedPathKey(a) == allowedPathKey(p) }) {
+			continue
+		}
+		e.config.AllowedPaths = append(e.config.AllowedPaths, p)
+		e.allowedSources[allowedPathKey(p)] = AllowedPathPersisted
+		slog.Info("Allowed path restored", "path", p, "file", e.config.AllowedPathsFile)
+	}
+}
+
+func readAllowedPathsFile(file string) ([]string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var state allowedPathsFileState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", file, err)
+	}
+	return state.Paths, nil
+}
+
+// writeAllowedPathsFile replaces the file atomically
+func writeAllowedPathsFile(file string, paths []string) error {
+	data, err := json.MarshalIndent(allowedPathsFileState{Paths: paths}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+// persistedAllowedPathsLocked returns the AllowedPaths entries that belong in
+// AllowedPathsFile (caller holds allowedMu)
+func (e *UltraFastEngine) persistedAllowedPathsLocked() []string {
+	var out []string
+	for _, p := range e.config.AllowedPaths {
+		if e.allowedSources[allowedPathKey(p)] == AllowedPathPersisted {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// afterAllowedPathsChange propagates a new AllowedPaths to auto-sync and
+// records the change. Newly added directories are not watched; cache hits
+// under them fall back to stat checks.
+func (e *UltraFastEngine) afterAllowedPathsChange(ctx context.Context, op, path string) {
+	if e.autoSyncManager != nil {
+		e.autoSyncManager.SetAllowedPaths(e.GetAllowedPaths())
+	}
+	e.recordOperation(ctx, OperationRecord{Operation: op, Path: path})
+}
+
+// AddAllowedPath grants access to an existing directory until restart, or
+// for good when persist is set (requires AllowedPathsFile). It returns the
+// absolute path that was added. Only available with AllowPathManagement and
+// while access control is active: in open-access mode every path is already
+// allowed.
+func (e *UltraFastEngine) AddAllowedPath(ctx context.Context, path string, persist bool) (string, error) {
+	if !e.config.AllowPathManagement {
+		return "", fmt.Errorf("path management is disabled (start the server with --allow-path-management)")
+	}
+	if persist && e.config.AllowedPathsFile == "" {
+		return "", fmt.Errorf("persist requires --allowed-paths-file")
+	}
+	path = NormalizePath(path)
+	if err := validatePathSecurity(path); err != nil {
+		return "", &PathError{Op: "add_allowed_path", Path: path, Err: err}
+	}
+	abs, err := filepath.Abs(canonicalWSLForm(path))
+	if err != nil {
+		return "", &PathError{Op: "add_allowed_path", Path: path, Err: err}
+	}
+	abs = filepath.Clean(abs)
+	info, err := os.Stat(abs)
+	if err != nil {
+		return "", &PathError{Op: "add_allowed_path", Path: abs, Err: err}
+	}
+	if !info.IsDir() {
+		return "", &PathError{Op: "add_allowed_path", Path: abs, Err: fmt.Errorf("not a directory")}
+	}
+	if filepath.Dir(abs) == abs {
+		return "", &PathError{Op: "add_allowed_path", Path: abs, Err: fmt.Errorf("refusing to allow a filesystem root")}
+	}
+	if _, restricted := e.allowedBases(); !restricted {
+		return "", fmt.Errorf("access control is not configured, every path is already allowed (start the server with --allowed-paths to restrict it)")
+	}
+	if e.IsPathAllowed(abs) {
+		return "", &PathError{Op: "add_allowed_path", Path: abs, Err: fmt.Errorf("already allowed%s", e.AllowedDirsSuffix())}
+	}
+
+	e.allowedMu.Lock()
+	if e.allowedSources == nil {
+		e.allowedSources = make(map[string]string)
+	}
+	source := AllowedPathRuntime
+	if persist {
+		source = AllowedPathPersisted
+		if err := writeAllowedPathsFile(e.config.AllowedPathsFile, append(e.persistedAllowedPathsLocked(), abs)); err != nil {
+			e.allowedMu.Unlock()
+			return "", fmt.Errorf("failed to persist allowed paths: %w", err)
+		}
+	}
+	e.config.AllowedPaths = append(slices.Clone(e.config.AllowedPaths), abs)
+	e.allowedSources[allowedPathKey(abs)] = source
+	e.resolveAllowedPaths()
+	e.allowedMu.Unlock()
+
+	slog.Info("Allowed path added", "path", abs, "source", source)
+	e.afterAllowedPathsChange(ctx, "allow_path_add", abs)
+	return abs, nil
+}
+
+// RemoveAllowedPath revokes access to one of the AllowedPaths entries (also
+// from AllowedPathsFile when it was persisted). The last entry cannot be
+// removed: without allowed paths the server falls back to open access.
+// Removing a startup path lasts until restart.
+func (e *UltraFastEngine) RemoveAllowedPath(ctx context.Context, path string) (string, error) {
+	if !e.config.AllowPathManagement {
+		return "", fmt.Errorf("path management is disabled (start the server with --allow-path-management)")
+	}
+	key := allowedPathKey(path)
+
+	e.allowedMu.Lock()
+	i := slices.IndexFunc(e.config.AllowedPaths, func(a string) bool { return allowedPathKey(a) == key })
+	if i < 0 {
+		e.allowedMu.Unlock()
+		return "", &PathError{Op: "remove_allowed_path", Path: path, Err: fmt.Errorf("not an allowed path (entries: %s)", strings.Join(e.config.AllowedPaths, "; "))}
+	}
+	if len(e.config.AllowedPaths) == 1 {
+		e.allowedMu.Unlock()
+		return "", &PathError{Op: "remove_allowed_path", Path: path, Err: fmt.Errorf("cannot remove the last allowed path: the server would fall back to full filesystem access")}
+	}
+	removed := e.config.AllowedPaths[i]
+	source := e.allowedSources[key]
+	if source == "" {
+		source = AllowedPathStartup
+	}
+	if source == AllowedPathPersisted {
+		remaining := slices.DeleteFunc(e.persistedAllowedPathsLocked(), func(a string) bool { return allowedPathKey(a) == key })
+		if err := writeAllowedPathsFile(e.config.AllowedPathsFile, remaining); err != nil {
+			e.allowedMu.Unlock()
+			return "", fmt.Errorf("failed to persist allowed paths: %w", err)
+		}
+	}
+	e.config.AllowedPaths = slices.Delete(slices.Clone(e.config.AllowedPaths), i, i+1)
+	delete(e.allowedSources, key)
+	e.resolveAllowedPaths()
+	e.allowedMu.Unlock()
+
+	slog.Info("Allowed path removed", "path", removed, "source", source)
+	e.afterAllowedPathsChange(ctx, "allow_path_remove", removed)
+	return removed, nil
+}
+
+// ListAllowedPaths describes the current AllowedPaths
+func (e *UltraFastEngine) ListAllowedPaths() AllowedPathsListing {
+	listing := AllowedPathsListing{ManagementEnabled: e.config.AllowPathManagement, PersistFile: e.config.AllowedPathsFile}
+	bases, _ := e.allowedBases()
+
+	e.allowedMu.RLock()
+	paths := e.config.AllowedPaths
+	sources := make([]string, len(paths))
+	for i, p := range paths {
+		if sources[i] = e.allowedSources[allowedPathKey(p)]; sources[i] == "" {
+			sources[i] = AllowedPathStartup
+		}
+	}
+	e.allowedMu.RUnlock()
+
+	for i, p := range paths {
+		info := AllowedPathInfo{Path: p, Source: sources[i]}
+		if st, err := os.Stat(p); err == nil && st.IsDir() {
+			info.Exists = true
+		}
+		if len(bases) == len(paths) && bases[i] != allowedPathKey(p) {
+			info.Resolved = bases[i]
+		}
+		listing.Paths = append(listing.Paths, info)
+	}
+	return listing
+}
+
+// FormatAllowedPaths renders allowed_paths list output
+func FormatAllowedPaths(l AllowedPathsListing, compact bool) string {
+	if len(l.Paths) == 0 {
+		return "Access control is off: every path is allowed (start the server with --allowed-paths to restrict it)"
+	}
+	var b strings.Builder
+	if compact {
+		for i, p := range l.Paths {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			b.WriteString(p.Path + " [" + p.Source + "]")
+			if !p.Exists {
+				b.WriteString(" (missing)")
+			}
+		}
+		return b.String()
+	}
+	b.WriteString(fmt.Sprintf("Allowed paths (%d):", len(l.Paths)))
+	for _, p := range l.Paths {
+		b.WriteString(fmt.Sprintf("\n  %s  [%s]", p.Path, p.Source))
+		if p.Resolved != "" {
+			b.WriteString(" -> " + p.Resolved)
+		}
+		if !p.Exists {
+			b.WriteString("  (missing)")
+		}
+	}
+	if l.ManagementEnabled {
+		b.WriteString("\nManagement: enabled (allowed_paths add, remove)")
+		if l.PersistFile != "" {
+			b.WriteString(", persisted in " + l.PersistFile)
+		}
+	} else {
+		b.WriteString("\nManagement: disabled (start the server with --allow-path-management)")
+	}
+	return b.String()
+}
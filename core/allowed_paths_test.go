@@ -0,0 +1,108 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mcp/filesystem-ultra/cache"
+)
+
+func newPathManagementEngine(t *testing.T, dir, file string, enabled bool) *UltraFastEngine {
+	t.Helper()
+	c, err := cache.NewIntelligentCache(1024 * 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine, err := NewUltraFastEngine(&Config{Cache: c, AllowedPaths: []string{dir}, ParallelOps: 2, BackupDir: t.TempDir(),
+		AllowPathManagement: enabled, AllowedPathsFile: file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { engine.Close(); c.Close() })
+	return engine
+}
+
+func TestAllowedPaths_AddRemoveAndPersist(t *testing.T) {
+	dir, other := t.TempDir(), t.TempDir()
+	file := filepath.Join(t.TempDir(), "allowed-paths.json")
+	engine := newPathManagementEngine(t, dir, file, true)
+	ctx := context.Background()
+	target := filepath.Join(other, "a.txt")
+
+	if engine.IsPathAllowed(target) {
+		t.Fatal("other dir allowed before AddAllowedPath")
+	}
+	added, err := engine.AddAllowedPath(ctx, other, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !engine.IsPathAllowed(target) {
+		t.Fatal("other dir not allowed after AddAllowedPath")
+	}
+	if _, err := engine.AddAllowedPath(ctx, filepath.Join(other), false); err == nil {
+		t.Error("adding an already allowed path succeeded")
+	}
+	if report := engine.GetOperationReport(10, time.Time{}); report.Total != 1 || report.Records[0].Operation != "allow_path_add" {
+		t.Errorf("change not recorded: %+v", report.Records)
+	}
+
+	// A restarted engine restores the persisted path
+	restarted := newPathManagementEngine(t, dir, file, false)
+	listing := restarted.ListAllowedPaths()
+	if len(listing.Paths) != 2 || listing.Paths[1].Path != added || listing.Paths[1].Source != AllowedPathPersisted {
+		t.Fatalf("restored listing = %+v", listing.Paths)
+	}
+	if !restarted.IsPathAllowed(target) {
+		t.Error("persisted path not allowed after restart")
+	}
+	if _, err := restarted.RemoveAllowedPath(ctx, other); err == nil || !strings.Contains(err.Error(), "--allow-path-management") {
+		t.Errorf("remove with management disabled: %v", err)
+	}
+
+	if _, err := engine.RemoveAllowedPath(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+	if engine.IsPathAllowed(target) {
+		t.Error("other dir still allowed after RemoveAllowedPath")
+	}
+	if data, _ := os.ReadFile(file); strings.Contains(string(data), filepath.Base(other)) {
+		t.Errorf("removed path still persisted: %s", data)
+	}
+	if _, err := engine.RemoveAllowedPath(ctx, dir); err == nil || !strings.Contains(err.Error(), "last allowed path") {
+		t.Errorf("removing the last allowed path: %v", err)
+	}
+}
+
+func TestAllowedPaths_AddRejections(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	disabled := newPathManagementEngine(t, dir, "", false)
+	if _, err := disabled.AddAllowedPath(ctx, t.TempDir(), false); err == nil {
+		t.Error("add succeeded with management disabled")
+	}
+
+	engine := newPathManagementEngine(t, dir, "", true)
+	file := filepath.Join(dir, "f.txt")
+	os.WriteFile(file, []byte("x"), 0644)
+	for name, tc := range map[string]struct {
+		path    string
+		persist bool
+	}{
+		"missing":              {filepath.Join(t.TempDir(), "nope"), false},
+		"file":                 {file, false},
+		"root":                 {string(filepath.Separator), false},
+		"persist without file": {t.TempDir(), true},
+	} {
+		if _, err := engine.AddAllowedPath(ctx, tc.path, tc.persist); err == nil {
+			t.Errorf("%s: add succeeded", name)
+		}
+	}
+	if got := len(engine.GetAllowedPaths()); got != 1 {
+		t.Errorf("allowed paths = %d after rejected adds, want 1", got)
+	}
+}
@@ -0,0 +1,422 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// archive tool formats
+const (
+	ArchiveZip   = "zip"
+	ArchiveTarGz = "tar.gz"
+)
+
+// archiveMaxBytes is the uncompressed total the archive tool refuses to pack
+// without force
+const archiveMaxBytes int64 = 2 << 30
+
+// ArchiveOptions tunes CreateArchive
+type ArchiveOptions struct {
+	Sources []string // files and directories to pack
+	Glob    string   // path glob ("/proj/out/**/*.txt"), with or instead of Sources
+	// BaseDir is what entry names are relative to. Default: the parent of
+	// the sources (their common one when several), or the base of Glob.
+	BaseDir string
+	Exclude []string // globs skipped on top of the default excludes
+	// CompressionLevel is 0 (store) to 9; -1 is the deflate default
+	CompressionLevel int
+	Overwrite        bool // replace an existing archive
+	Force            bool // pack more than archiveMaxBytes
+	// FollowSymlinks packs what symlinks point to (inside the allowed paths)
+	// instead of skipping them
+	FollowSymlinks bool
+}
+
+// ArchiveResult is the archive tool response for create
+type ArchiveResult struct {
+	Action          string `json:"action"`
+	Archive         string `json:"archive"`
+	Format          string `json:"format"`
+	BaseDir         string `json:"base_dir,omitempty"`
+	Files           int    `json:"files"`
+	Dirs            int    `json:"dirs,omitempty"`
+	Bytes           int64  `json:"bytes"`            // uncompressed
+	Compressed      int64  `json:"compressed_bytes"` // size of the archive
+	SkippedSymlinks int    `json:"skipped_symlinks,omitempty"`
+	Excluded        int    `json:"excluded,omitempty"` // entries left out by the excludes
+	DurationMs      int64  `json:"duration_ms"`
+}
+
+// archiveEntry is one file or directory to pack
+type archiveEntry struct {
+	src  string
+	name string // slash-separated, relative to the base dir; dirs end in "/"
+	info os.FileInfo
+}
+
+// archivePlan lists what CreateArchive packs, sorted by name
+type archivePlan struct {
+	base     string
+	entries  []archiveEntry
+	files    int
+	dirs     int
+	bytes    int64
+	symlinks int
+	excluded int
+}
+
+// archiveItem is one entry read from an archive
+type archiveItem struct {
+	name     string      // as stored: slash-separated, dirs may end in "/"
+	mode     fs.FileMode // type bits and permissions; 0 permissions when not recorded
+	size     int64       // uncompressed, as declared by the archive
+	modified time.Time
+	linkname string // symlink target, for symlink entries
+	// open streams the entry's content; valid during the walk callback only
+	open func() (io.ReadCloser, error)
+}
+
+// walkArchive calls fn for every entry of the archive at path
+func walkArchive(path, format string, fn func(item archiveItem) error) error {
+	if format == ArchiveTarGz {
+		return walkTarGz(path, fn)
+	}
+	return walkZip(path, fn)
+}
+
+// archiveFormat returns the format of an archive path, from its extension
+// unless format is given
+func archiveFormat(path, format string) (string, error) {
+	if format == "" {
+		lower := strings.ToLower(path)
+		switch {
+		case strings.HasSuffix(lower, ".zip"):
+			return ArchiveZip, nil
+		case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+			return ArchiveTarGz, nil
+		}
+		return "", fmt.Errorf("cannot tell the archive format of %s: use a .zip, .tar.gz or .tgz name or pass archive_format", path)
+	}
+	switch format {
+	case ArchiveZip, ArchiveTarGz:
+		return format, nil
+	case "tgz":
+		return ArchiveTarGz, nil
+	}
+	return "", fmt.Errorf("unsupported archive format %q: use zip or tar.gz", format)
+}
+
+// planArchive resolves the sources and glob of opts into the entries of an
+// archive. archivePath itself is never packed. Symlinks are skipped unless
+// opts.FollowSymlinks; followed ones pack their target under the link's name,
+// each real directory at most once.
+func (e *UltraFastEngine) planArchive(ctx context.Context, archivePath string, opts ArchiveOptions) (*archivePlan, error) {
+	sources := make([]string, 0, len(opts.Sources))
+	for _, s := range opts.Sources {
+		s = NormalizePath(s)
+		if !e.IsPathAllowed(s) {
+			return nil, e.AccessDeniedError("archive", s)
+		}
+		if _, err := os.Lstat(s); err != nil {
+			return nil, &PathError{Op: "archive", Path: s, Err: err}
+		}
+		sources = append(sources, s)
+	}
+	var globMatches []string
+	globBase := ""
+	if opts.Glob != "" {
+		var err error
+		if globMatches, err = expandPathGlob(opts.Glob, 0, e.IsPathAllowed); err != nil {
+			return nil, err
+		}
+		globBase, _ = splitGlobBase(NormalizePath(opts.Glob))
+	}
+	if len(sources) == 0 && len(globMatches) == 0 {
+		return nil, fmt.Errorf("nothing to archive: pass sources or glob")
+	}
+
+	plan := &archivePlan{base: NormalizePath(opts.BaseDir)}
+	if opts.BaseDir == "" {
+		parents := make([]string, 0, len(sources)+1)
+		for _, s := range sources {
+			parents = append(parents, filepath.Dir(s))
+		}
+		if globBase != "" {
+			parents = append(parents, globBase)
+		}
+		plan.base = commonDir(parents)
+	}
+	if abs, err := filepath.Abs(plan.base); err == nil {
+		plan.base = abs
+	}
+	selfAbs, _ := filepath.Abs(archivePath)
+	excludes := newSyncExcludes(opts.Exclude, nil)
+	seen := map[string]bool{}
+
+	// add records the file or directory read from src and named after p
+	add := func(src, p string, info os.FileInfo) error {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return err
+		}
+		if abs == selfAbs {
+			return nil
+		}
+		rel, err := filepath.Rel(plan.base, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("%s is outside base_dir %s", p, plan.base)
+		}
+		if rel == "." {
+			return nil // the base dir itself has no entry
+		}
+		name := filepath.ToSlash(rel)
+		if info.IsDir() {
+			name += "/"
+		}
+		if seen[name] {
+			return nil
+		}
+		seen[name] = true
+		plan.entries = append(plan.entries, archiveEntry{src: src, name: name, info: info})
+		if info.IsDir() {
+			plan.dirs++
+		} else {
+			plan.files++
+			plan.bytes += info.Size()
+		}
+		return nil
+	}
+
+	// walk adds the tree at real, named as if it sat at logical; top is the
+	// source the excludes are relative to
+	visited := map[string]bool{}
+	var walk func(top, real, logical string) error
+	walk = func(top, real, logical string) error {
+		return filepath.WalkDir(real, func(p string, d fs.DirEntry, err error) error {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", p, err)
+			}
+			name := logical
+			if rel, err := filepath.Rel(real, p); err == nil && rel != "." {
+				name = filepath.Join(logical, rel)
+			}
+			if name != top && excludes.excluded(top, name, d.IsDir()) {
+				plan.excluded++
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.Type()&fs.ModeSymlink != 0 {
+				target, ok := e.followableLink(p, opts.FollowSymlinks)
+				if !ok {
+					plan.symlinks++
+					return nil
+				}
+				info, err := os.Stat(target)
+				if err != nil {
+					return fmt.Errorf("failed to stat %s: %w", target, err)
+				}
+				if !info.IsDir() {
+					return add(target, name, info)
+				}
+				if visited[target] {
+					plan.symlinks++
+					return nil
+				}
+				visited[target] = true
+				return walk(top, target, name)
+			}
+			if !d.IsDir() && !d.Type().IsRegular() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return fmt.Errorf("failed to stat %s: %w", p, err)
+			}
+			if d.IsDir() {
+				if realDir, err := filepath.EvalSymlinks(p); err == nil {
+					if visited[realDir] {
+						return filepath.SkipDir
+					}
+					visited[realDir] = true
+				}
+			}
+			return add(p, name, info)
+		})
+	}
+	for _, src := range sources {
+		if err := walk(src, src, src); err != nil {
+			return nil, err
+		}
+	}
+	for _, p := range globMatches {
+		if excludes.excluded(globBase, p, false) {
+			plan.excluded++
+			continue
+		}
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, &PathError{Op: "archive", Path: p, Err: err}
+		}
+		if err := add(p, p, info); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(plan.entries, func(i, j int) bool { return plan.entries[i].name < plan.entries[j].name })
+	return plan, nil
+}
+
+// followableLink returns the resolved target of the symlink at p when
+// follow is set and the target exists inside the allowed paths
+func (e *UltraFastEngine) followableLink(p string, follow bool) (string, bool) {
+	if !follow {
+		return "", false
+	}
+	target, err := filepath.EvalSymlinks(p)
+	if err != nil || !e.IsPathAllowed(target) {
+		return "", false
+	}
+	info, err := os.Stat(target)
+	if err != nil || (!info.IsDir() && !info.Mode().IsRegular()) {
+		return "", false
+	}
+	return target, true
+}
+
+// commonDir returns the deepest directory holding every one of dirs
+func commonDir(dirs []string) string {
+	if len(dirs) == 0 {
+		return "."
+	}
+	common := filepath.Clean(dirs[0])
+	for _, d := range dirs[1:] {
+		d = filepath.Clean(d)
+		for common != d && !strings.HasPrefix(d, strings.TrimSuffix(common, string(filepath.Separator))+string(filepath.Separator)) {
+			parent := filepath.Dir(common)
+			if parent == common {
+				break
+			}
+			common = parent
+		}
+	}
+	return common
+}
+
+// CreateArchive packs opts' sources into a new archive at archivePath,
+// written to a temporary file and renamed into place. The archive must lie
+// in the allowed paths; more than archiveMaxBytes is refused unless
+// opts.Force.
+func (e *UltraFastEngine) CreateArchive(ctx context.Context, archivePath, format string, opts ArchiveOptions) (*ArchiveResult, error) {
+	archivePath = NormalizePath(archivePath)
+	format, err := archiveFormat(archivePath, format)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.acquireOperation(ctx, "copy"); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	defer e.releaseOperation("copy", start)
+
+	if !e.IsPathAllowed(archivePath) {
+		return nil, e.AccessDeniedError("archive", archivePath)
+	}
+	if err := e.CheckWritable("archive", archivePath); err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(archivePath); err == nil {
+		if info.IsDir() {
+			return nil, fmt.Errorf("archive path is a directory: %s", archivePath)
+		}
+		if !opts.Overwrite {
+			return nil, fmt.Errorf("archive already exists: %s (pass overwrite:true to replace it)", archivePath)
+		}
+	}
+	if opts.CompressionLevel < -1 || opts.CompressionLevel > 9 {
+		return nil, fmt.Errorf("compression_level must be 0-9, got %d", opts.CompressionLevel)
+	}
+
+	plan, err := e.planArchive(ctx, archivePath, opts)
+	if err != nil {
+		return nil, err
+	}
+	if plan.files == 0 {
+		return nil, fmt.Errorf("nothing to archive: the sources hold no regular files")
+	}
+	if plan.bytes > archiveMaxBytes && !opts.Force {
+		return nil, fmt.Errorf("sources total %s, over the %s archive limit (pass force:true to pack them anyway)",
+			formatSize(plan.bytes), formatSize(archiveMaxBytes))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(archivePath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+	tmpPath := archivePath + ".tmp." + secureRandomSuffix()
+	write := e.writeZip
+	if format == ArchiveTarGz {
+		write = e.writeTarGz
+	}
+	if err := write(ctx, tmpPath, plan, opts.CompressionLevel); err != nil {
+		os.Remove(tmpPath)
+		return nil, err
+	}
+	if err := os.Rename(tmpPath, archivePath); err != nil {
+		os.Remove(tmpPath)
+		return nil, fmt.Errorf("failed to rename temp file: %w", lockedError("write", archivePath, err))
+	}
+	e.invalidateMutatedPath(archivePath)
+
+	result := &ArchiveResult{
+		Action:          "create",
+		Archive:         archivePath,
+		Format:          format,
+		BaseDir:         plan.base,
+		Files:           plan.files,
+		Dirs:            plan.dirs,
+		Bytes:           plan.bytes,
+		SkippedSymlinks: plan.symlinks,
+		Excluded:        plan.excluded,
+		DurationMs:      time.Since(start).Milliseconds(),
+	}
+	if info, err := os.Stat(archivePath); err == nil {
+		result.Compressed = info.Size()
+	}
+	return result, nil
+}
+
+// FormatArchiveResult renders an ArchiveResult as text
+func FormatArchiveResult(r *ArchiveResult, compact bool) string {
+	ratio := ""
+	if r.Bytes > 0 {
+		ratio = fmt.Sprintf(" (%.0f%%)", float64(r.Compressed)*100/float64(r.Bytes))
+	}
+	if compact {
+		return fmt.Sprintf("OK: %s — %d files, %s → %s%s", r.Archive, r.Files, formatSize(r.Bytes), formatSize(r.Compressed), ratio)
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Created %s archive %s\n", r.Format, r.Archive))
+	sb.WriteString(fmt.Sprintf("  entries relative to: %s\n", r.BaseDir))
+	sb.WriteString(fmt.Sprintf("  %d files", r.Files))
+	if r.Dirs > 0 {
+		sb.WriteString(fmt.Sprintf(", %d directories", r.Dirs))
+	}
+	sb.WriteString(fmt.Sprintf("\n  %s uncompressed → %s%s in %dms\n", formatSize(r.Bytes), formatSize(r.Compressed), ratio, r.DurationMs))
+	if r.Excluded > 0 {
+		sb.WriteString(fmt.Sprintf("  %d entries left out by the excludes\n", r.Excluded))
+	}
+	if r.SkippedSymlinks > 0 {
+		sb.WriteString(fmt.Sprintf("  ⚠️  %d symlinks skipped\n", r.SkippedSymlinks))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,382 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ExtractOptions tunes ExtractArchive
+type ExtractOptions struct {
+	// Include extracts only the entries matching this glob ("*.csv",
+	// "docs/**"); a glob without "/" also matches entry base names
+	Include   string
+	Overwrite bool // replace existing files instead of skipping them
+	DryRun    bool // list what would happen, write nothing
+	Force     bool // extract more than archiveMaxBytes
+	// FollowSymlinks recreates symlink entries whose target stays inside the
+	// destination instead of skipping them
+	FollowSymlinks bool
+}
+
+// ExtractEntry is one entry of an extract dry run
+type ExtractEntry struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	Action string `json:"action"` // extract, overwrite, link, skip_exists, skip_symlink
+}
+
+// ExtractResult is the archive tool response for extract
+type ExtractResult struct {
+	Action      string         `json:"action"`
+	Archive     string         `json:"archive"`
+	Format      string         `json:"format"`
+	DestDir     string         `json:"dest_dir"`
+	DryRun      bool           `json:"dry_run,omitempty"`
+	Extracted   int            `json:"extracted"`       // files written (or, dry run, to write), overwritten ones included
+	Overwritten int            `json:"overwritten"`     // existing files replaced
+	Skipped     int            `json:"skipped"`         // existing files without overwrite, symlink entries
+	Links       int            `json:"links,omitempty"` // symlinks recreated (follow_symlinks)
+	Filtered    int            `json:"filtered,omitempty"`
+	Dirs        int            `json:"dirs,omitempty"`
+	Bytes       int64          `json:"bytes"`
+	Entries     []ExtractEntry `json:"entries,omitempty"` // dry run, capped at MaxSearchResults
+	Truncated   bool           `json:"truncated,omitempty"`
+	Warnings    []string       `json:"warnings,omitempty"`
+	DurationMs  int64          `json:"duration_ms"`
+}
+
+// safeEntryPath returns where entry name lands under dest, or an error when
+// the name is absolute or climbs out of dest (zip slip)
+func safeEntryPath(dest, name string) (string, error) {
+	slashed := strings.ReplaceAll(name, "\\", "/")
+	if slashed == "" || strings.HasPrefix(slashed, "/") || filepath.IsAbs(name) || filepath.VolumeName(name) != "" ||
+		(len(slashed) > 1 && slashed[1] == ':') {
+		return "", fmt.Errorf("unsafe archive entry %q: absolute path", name)
+	}
+	clean := path.Clean(slashed)
+	if clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("unsafe archive entry %q: escapes the destination", name)
+	}
+	if clean == "." {
+		return dest, nil
+	}
+	return filepath.Join(dest, filepath.FromSlash(clean)), nil
+}
+
+// includeEntry reports whether name matches an extract include glob
+func includeEntry(glob, name string) bool {
+	if glob == "" {
+		return true
+	}
+	name = strings.TrimSuffix(path.Clean(strings.ReplaceAll(name, "\\", "/")), "/")
+	if matchGlobSegments(strings.Split(glob, "/"), strings.Split(name, "/")) {
+		return true
+	}
+	if !strings.Contains(glob, "/") {
+		ok, _ := path.Match(glob, path.Base(name))
+		return ok
+	}
+	return false
+}
+
+// ExtractArchive unpacks the archive at archivePath into destDir. Every
+// entry is checked before anything is written: one absolute or escaping
+// entry, or one that would create or replace a write-protected path,
+// refuses the whole archive. Files keep their recorded modes and
+// times, are written to a temporary file and renamed into place, and are
+// skipped when they exist unless opts.Overwrite. Symlink entries are
+// skipped with a warning, or with opts.FollowSymlinks recreated when their
+// target stays inside destDir.
+func (e *UltraFastEngine) ExtractArchive(ctx context.Context, archivePath, destDir, format string, opts ExtractOptions) (*ExtractResult, error) {
+	archivePath, destDir = NormalizePath(archivePath), NormalizePath(destDir)
+	format, err := archiveFormat(archivePath, format)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.acquireOperation(ctx, "copy"); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	defer e.releaseOperation("copy", start)
+
+	if !e.IsPathAllowed(archivePath) {
+		return nil, e.AccessDeniedError("archive", archivePath)
+	}
+	if !e.IsPathAllowed(destDir) {
+		return nil, e.AccessDeniedError("archive", destDir)
+	}
+	if err := e.CheckWritable("archive", destDir); err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(destDir); err == nil && !info.IsDir() {
+		return nil, fmt.Errorf("dest_dir is not a directory: %s", destDir)
+	}
+	if abs, err := filepath.Abs(destDir); err == nil {
+		destDir = abs
+	}
+
+	// First pass: validate every name and total the declared sizes
+	var total int64
+	err = walkArchive(archivePath, format, func(item archiveItem) error {
+		target, err := safeEntryPath(destDir, item.name)
+		if err != nil {
+			return err
+		}
+		if !includeEntry(opts.Include, item.name) {
+			return nil
+		}
+		if item.mode.IsRegular() {
+			total += item.size
+		}
+		return e.checkEntryWritable(item, target, opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if total > archiveMaxBytes && !opts.Force {
+		return nil, fmt.Errorf("archive expands to %s, over the %s limit (pass force:true to extract it anyway)",
+			formatSize(total), formatSize(archiveMaxBytes))
+	}
+
+	res := &ExtractResult{Action: "extract", Archive: archivePath, Format: format, DestDir: destDir, DryRun: opts.DryRun}
+	var bufPtr *[]byte
+	if !opts.DryRun {
+		bufPtr = e.bufferPool.Get().(*[]byte)
+		defer e.bufferPool.Put(bufPtr)
+	}
+	listEntry := func(name string, size int64, action string) {
+		if !opts.DryRun {
+			return
+		}
+		if len(res.Entries) >= e.config.MaxSearchResults {
+			res.Truncated = true
+			return
+		}
+		res.Entries = append(res.Entries, ExtractEntry{Name: name, Size: size, Action: action})
+	}
+
+	err = walkArchive(archivePath, format, func(item archiveItem) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !includeEntry(opts.Include, item.name) {
+			res.Filtered++
+			return nil
+		}
+		target, _ := safeEntryPath(destDir, item.name)
+		switch {
+		case item.mode&os.ModeSymlink != 0:
+			reason := "pass follow_symlinks:true to recreate it"
+			if opts.FollowSymlinks {
+				if err := e.checkEntryWritable(item, target, opts); err != nil {
+					return err
+				}
+				reason = e.extractSymlink(item, destDir, target, opts)
+				if reason == "" {
+					res.Links++
+					listEntry(item.name, 0, "link")
+					return nil
+				}
+			}
+			res.Skipped++
+			res.Warnings = append(res.Warnings, fmt.Sprintf("symlink entry %s skipped: %s", item.name, reason))
+			listEntry(item.name, 0, "skip_symlink")
+			return nil
+		case item.mode.IsDir():
+			res.Dirs++
+			if opts.DryRun {
+				return nil
+			}
+			if !pathInside(destDir, target) {
+				return fmt.Errorf("unsafe archive entry %q: resolves outside the destination", item.name)
+			}
+			if err := e.checkEntryWritable(item, target, opts); err != nil {
+				return err
+			}
+			return os.MkdirAll(target, 0755)
+		case !item.mode.IsRegular():
+			res.Skipped++
+			res.Warnings = append(res.Warnings, fmt.Sprintf("special entry %s skipped", item.name))
+			return nil
+		}
+
+		existing, statErr := os.Lstat(target)
+		exists := statErr == nil
+		if exists && (existing.IsDir() || !opts.Overwrite) {
+			res.Skipped++
+			listEntry(item.name, item.size, "skip_exists")
+			return nil
+		}
+		action := "extract"
+		if exists {
+			action = "overwrite"
+			res.Overwritten++
+		}
+		res.Extracted++
+		res.Bytes += item.size
+		listEntry(item.name, item.size, action)
+		if opts.DryRun {
+			return nil
+		}
+		// A symlinked directory already in dest must not carry the write out
+		if !pathInside(destDir, target) {
+			return fmt.Errorf("unsafe archive entry %q: resolves outside the destination", item.name)
+		}
+		// Re-check now that earlier entries may have added symlinked directories
+		if err := e.checkEntryWritable(item, target, opts); err != nil {
+			return err
+		}
+		if err := e.extractFile(item, target, *bufPtr); err != nil {
+			return fmt.Errorf("failed to extract %s: %w", item.name, err)
+		}
+		e.invalidateMutatedPath(target)
+		return nil
+	})
+	if err != nil {
+		return res, err
+	}
+	e.invalidateDirListing(destDir)
+	res.DurationMs = time.Since(start).Milliseconds()
+	return res, nil
+}
+
+// checkEntryWritable fails when extracting item would create or replace a
+// write-protected path, either target itself or the path it reaches through
+// symlinked directories already under the destination. Entries that would be
+// skipped (existing paths without opts.Overwrite, existing directories,
+// symlinks without opts.FollowSymlinks, special files) are let through.
+func (e *UltraFastEngine) checkEntryWritable(item archiveItem, target string, opts ExtractOptions) error {
+	switch {
+	case item.mode&os.ModeSymlink != 0 && !opts.FollowSymlinks:
+		return nil
+	case !item.mode.IsRegular() && !item.mode.IsDir() && item.mode&os.ModeSymlink == 0:
+		return nil
+	}
+	if existing, err := os.Lstat(target); err == nil && (existing.IsDir() || !opts.Overwrite) {
+		return nil
+	}
+	if err := e.CheckWritable("archive", target); err != nil {
+		return err
+	}
+	if real := filepath.Join(resolveExisting(filepath.Dir(target)), filepath.Base(target)); real != target {
+		return e.CheckWritable("archive", real)
+	}
+	return nil
+}
+
+// extractSymlink recreates a symlink entry at target, or in a dry run only
+// checks it can be. It returns why the link was not made, or "" once it is:
+// absolute targets, targets leaving destDir and existing paths (unless
+// opts.Overwrite replaces a file) are refused.
+func (e *UltraFastEngine) extractSymlink(item archiveItem, destDir, target string, opts ExtractOptions) string {
+	link := filepath.FromSlash(item.linkname)
+	if link == "" || filepath.IsAbs(link) || strings.HasPrefix(item.linkname, "/") {
+		return fmt.Sprintf("absolute or empty target %q", item.linkname)
+	}
+	if !pathInside(destDir, filepath.Dir(target)) || !pathInside(destDir, filepath.Join(filepath.Dir(target), link)) {
+		return fmt.Sprintf("target %q leaves the destination", item.linkname)
+	}
+	if existing, err := os.Lstat(target); err == nil {
+		if existing.IsDir() || !opts.Overwrite {
+			return "the path already exists"
+		}
+		if !opts.DryRun {
+			if err := os.Remove(target); err != nil {
+				return err.Error()
+			}
+		}
+	}
+	if opts.DryRun {
+		return ""
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err.Error()
+	}
+	if err := os.Symlink(link, target); err != nil {
+		return err.Error()
+	}
+	e.invalidateMutatedPath(target)
+	return ""
+}
+
+// extractFile writes one archive entry to target through a temporary file,
+// then applies its mode and modification time. More content than the entry
+// declares is an error, so a forged size cannot slip past the size guard.
+func (e *UltraFastEngine) extractFile(item archiveItem, target string, buf []byte) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	r, err := item.open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	tmpPath := target + ".tmp." + secureRandomSuffix()
+	out, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	n, err := io.CopyBuffer(out, io.LimitReader(r, item.size+1), buf)
+	if err == nil && n > item.size {
+		err = fmt.Errorf("entry holds more than its declared %d bytes", item.size)
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if perm := item.mode.Perm(); perm != 0 {
+		_ = os.Chmod(tmpPath, perm)
+	}
+	if !item.modified.IsZero() {
+		_ = os.Chtimes(tmpPath, item.modified, item.modified)
+	}
+	if err := os.Rename(tmpPath, target); err != nil {
+		os.Remove(tmpPath)
+		return lockedError("write", target, err)
+	}
+	return nil
+}
+
+// FormatExtractResult renders an ExtractResult as text
+func FormatExtractResult(r *ExtractResult, compact bool) string {
+	verb := "Extracted"
+	if r.DryRun {
+		verb = "Would extract"
+	}
+	summary := fmt.Sprintf("%s %d files (%s) to %s", verb, r.Extracted, formatSize(r.Bytes), r.DestDir)
+	counts := fmt.Sprintf("%d overwritten, %d skipped", r.Overwritten, r.Skipped)
+	if r.Links > 0 {
+		counts += fmt.Sprintf(", %d symlinks", r.Links)
+	}
+	if r.Filtered > 0 {
+		counts += fmt.Sprintf(", %d not matching include_glob", r.Filtered)
+	}
+	if compact && !r.DryRun {
+		return fmt.Sprintf("OK: %s — %s", summary, counts)
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s\n  from %s (%s)\n  %s\n", summary, r.Archive, r.Format, counts))
+	if r.DryRun {
+		sb.WriteString("\nEntries:\n")
+		for _, en := range r.Entries {
+			sb.WriteString(fmt.Sprintf("  %-12s %10s  %s\n", en.Action, formatSize(en.Size), en.Name))
+		}
+		if r.Truncated {
+			sb.WriteString("  … more entries not listed\n")
+		}
+		sb.WriteString("\n(dry run: nothing written)\n")
+	}
+	for _, w := range r.Warnings {
+		sb.WriteString(fmt.Sprintf("⚠️  %s\n", w))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,228 @@
+package core
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// GzipOptions tunes GzipFile and GunzipFile
+type GzipOptions struct {
+	Dest         string // output path; default: path + ".gz", or path without ".gz"
+	Overwrite    bool   // replace an existing Dest
+	DeleteSource bool   // remove the input once the output is in place
+	Level        int    // gzip only: 0-9, -1 for the default
+	Force        bool   // gunzip only: expand past archiveMaxBytes
+}
+
+// GzipResult is the archive tool response for gzip and gunzip
+type GzipResult struct {
+	Action        string  `json:"action"`
+	Source        string  `json:"source"`
+	Dest          string  `json:"dest"`
+	BytesBefore   int64   `json:"bytes_before"`
+	BytesAfter    int64   `json:"bytes_after"`
+	Ratio         float64 `json:"ratio"` // compressed size / uncompressed size
+	SourceDeleted bool    `json:"source_deleted,omitempty"`
+	DurationMs    int64   `json:"duration_ms"`
+}
+
+// gunzipDest is where gunzip writes path when no dest is given
+func gunzipDest(path string) (string, error) {
+	lower := strings.ToLower(path)
+	switch {
+	case strings.HasSuffix(lower, ".tgz"):
+		return path[:len(path)-len(".tgz")] + ".tar", nil
+	case strings.HasSuffix(lower, ".gz"):
+		return path[:len(path)-len(".gz")], nil
+	}
+	return "", fmt.Errorf("cannot tell the output name of %s: it does not end in .gz; pass dest", path)
+}
+
+// GzipFile compresses the file at path to opts.Dest (default path + ".gz").
+// The gzip header records the file's name and modification time, and the
+// output keeps the source's mode and modification time.
+func (e *UltraFastEngine) GzipFile(ctx context.Context, path string, opts GzipOptions) (*GzipResult, error) {
+	path = NormalizePath(path)
+	dest := path + ".gz"
+	if opts.Dest != "" {
+		dest = NormalizePath(opts.Dest)
+	}
+	if opts.Level < -1 || opts.Level > 9 {
+		return nil, fmt.Errorf("compression_level must be 0-9, got %d", opts.Level)
+	}
+	return e.gzipStream(ctx, "gzip", path, dest, opts, func(in *os.File, info os.FileInfo, out io.Writer, buf []byte) (time.Time, error) {
+		zw, err := gzip.NewWriterLevel(out, opts.Level)
+		if err != nil {
+			return time.Time{}, err
+		}
+		zw.Name = filepath.Base(path)
+		zw.ModTime = info.ModTime()
+		if _, err := io.CopyBuffer(zw, in, buf); err != nil {
+			return time.Time{}, err
+		}
+		return info.ModTime(), zw.Close()
+	})
+}
+
+// GunzipFile decompresses the gzip file at path to opts.Dest (default: path
+// without ".gz", or ".tgz" turned into ".tar"). The output gets the
+// modification time recorded in the gzip header, else the source's. More than
+// archiveMaxBytes of output is refused unless opts.Force.
+func (e *UltraFastEngine) GunzipFile(ctx context.Context, path string, opts GzipOptions) (*GzipResult, error) {
+	path = NormalizePath(path)
+	dest := NormalizePath(opts.Dest)
+	if opts.Dest == "" {
+		var err error
+		if dest, err = gunzipDest(path); err != nil {
+			return nil, err
+		}
+	}
+	return e.gzipStream(ctx, "gunzip", path, dest, opts, func(in *os.File, info os.FileInfo, out io.Writer, buf []byte) (time.Time, error) {
+		zr, err := gzip.NewReader(in)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("not a gzip file: %w", err)
+		}
+		defer zr.Close()
+		modified := info.ModTime()
+		if !zr.ModTime.IsZero() {
+			modified = zr.ModTime
+		}
+		var src io.Reader = zr
+		if !opts.Force {
+			src = io.LimitReader(zr, archiveMaxBytes+1)
+		}
+		n, err := io.CopyBuffer(out, src, buf)
+		if err == nil && !opts.Force && n > archiveMaxBytes {
+			err = fmt.Errorf("%s expands past the %s limit (pass force:true to decompress it anyway)", path, formatSize(archiveMaxBytes))
+		}
+		return modified, err
+	})
+}
+
+// gzipStream runs the shared part of gzip and gunzip: access checks,
+// streaming path through transform into a temporary file next to dest with
+// the source's mode, stamping it with the modification time transform
+// returns, renaming into place and optionally deleting the source
+func (e *UltraFastEngine) gzipStream(ctx context.Context, action, path, dest string, opts GzipOptions,
+	transform func(in *os.File, info os.FileInfo, out io.Writer, buf []byte) (time.Time, error)) (*GzipResult, error) {
+	if err := e.acquireOperation(ctx, "write"); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	defer e.releaseOperation("write", start)
+
+	for _, p := range []string{path, dest} {
+		if !e.IsPathAllowed(p) {
+			return nil, e.AccessDeniedError("archive", p)
+		}
+	}
+	if err := e.CheckWritable("archive", dest); err != nil {
+		return nil, err
+	}
+	if opts.DeleteSource {
+		if err := e.CheckWritable("archive", path); err != nil {
+			return nil, err
+		}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, &PathError{Op: "archive", Path: path, Err: err}
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s is not a regular file", path)
+	}
+	if srcAbs, destAbs := absOrSelf(path), absOrSelf(dest); srcAbs == destAbs {
+		return nil, fmt.Errorf("dest is the source file: %s", dest)
+	}
+	if destInfo, err := os.Stat(dest); err == nil {
+		if destInfo.IsDir() {
+			return nil, fmt.Errorf("dest is a directory: %s", dest)
+		}
+		if !opts.Overwrite {
+			return nil, fmt.Errorf("dest already exists: %s (pass overwrite:true to replace it)", dest)
+		}
+	}
+
+	in, err := os.Open(path)
+	if err != nil {
+		return nil, &PathError{Op: "archive", Path: path, Err: err}
+	}
+	defer in.Close()
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+	tmpPath := dest + ".tmp." + secureRandomSuffix()
+	out, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	bufPtr := e.bufferPool.Get().(*[]byte)
+	modified, err := transform(in, info, out, *bufPtr)
+	e.bufferPool.Put(bufPtr)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return nil, fmt.Errorf("%s %s: %w", action, path, err)
+	}
+	_ = os.Chtimes(tmpPath, modified, modified)
+	if err := os.Rename(tmpPath, dest); err != nil {
+		os.Remove(tmpPath)
+		return nil, fmt.Errorf("failed to rename temp file: %w", lockedError("write", dest, err))
+	}
+	in.Close()
+	e.invalidateMutatedPath(dest)
+
+	res := &GzipResult{Action: action, Source: path, Dest: dest, BytesBefore: info.Size()}
+	if destInfo, err := os.Stat(dest); err == nil {
+		res.BytesAfter = destInfo.Size()
+	}
+	compressed, plain := res.BytesAfter, res.BytesBefore
+	if action == "gunzip" {
+		compressed, plain = plain, compressed
+	}
+	if plain > 0 {
+		res.Ratio = float64(compressed) / float64(plain)
+	}
+	if opts.DeleteSource {
+		if err := os.Remove(path); err != nil {
+			return res, fmt.Errorf("%s written, but the source was not deleted: %w", dest, lockedError("delete", path, err))
+		}
+		res.SourceDeleted = true
+		e.invalidateMutatedPath(path)
+	}
+	res.DurationMs = time.Since(start).Milliseconds()
+	return res, nil
+}
+
+// absOrSelf is filepath.Abs, or p itself when it fails
+func absOrSelf(p string) string {
+	if abs, err := filepath.Abs(p); err == nil {
+		return abs
+	}
+	return p
+}
+
+// FormatGzipResult renders a GzipResult as text
+func FormatGzipResult(r *GzipResult, compact bool) string {
+	sizes := fmt.Sprintf("%s → %s (%.0f%%)", formatSize(r.BytesBefore), formatSize(r.BytesAfter), r.Ratio*100)
+	deleted := ""
+	if r.SourceDeleted {
+		deleted = ", source deleted"
+	}
+	if compact {
+		return fmt.Sprintf("OK: %s — %s%s", r.Dest, sizes, deleted)
+	}
+	verb := "Compressed"
+	if r.Action == "gunzip" {
+		verb = "Decompressed"
+	}
+	return fmt.Sprintf("%s %s\n  → %s\n  %s in %dms%s", verb, r.Source, r.Dest, sizes, r.DurationMs, deleted)
+}
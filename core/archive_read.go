@@ -0,0 +1,299 @@
+package core
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ArchiveListEntry is one entry of an archive listing
+type ArchiveListEntry struct {
+	Name     string    `json:"name"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	Dir      bool      `json:"dir,omitempty"`
+	Symlink  bool      `json:"symlink,omitempty"`
+}
+
+// ArchiveListing is the archive tool response for list
+type ArchiveListing struct {
+	Action    string             `json:"action"`
+	Archive   string             `json:"archive"`
+	Format    string             `json:"format"`
+	Files     int                `json:"files"`
+	Dirs      int                `json:"dirs"`
+	Bytes     int64              `json:"bytes"`   // uncompressed total of the files
+	Entries   []ArchiveListEntry `json:"entries"` // capped at MaxSearchResults
+	Truncated bool               `json:"truncated,omitempty"`
+}
+
+// ArchiveEntryContent is the archive tool response for read
+type ArchiveEntryContent struct {
+	Action    string    `json:"action"`
+	Archive   string    `json:"archive"`
+	Entry     string    `json:"entry"`
+	Size      int64     `json:"size"` // the whole entry, uncompressed
+	Modified  time.Time `json:"modified"`
+	Returned  int64     `json:"returned_bytes"` // bytes of the entry in Content
+	Base64    bool      `json:"base64,omitempty"`
+	Truncated bool      `json:"truncated,omitempty"`
+	Content   string    `json:"content"`
+}
+
+// ArchiveEntryNotFoundError is returned by ReadArchiveEntry when the archive
+// holds no entry of that exact name
+type ArchiveEntryNotFoundError struct {
+	Archive     string
+	Entry       string
+	Suggestions []string // closest entry names, best first
+}
+
+func (e *ArchiveEntryNotFoundError) Error() string {
+	msg := fmt.Sprintf("no entry %q in %s", e.Entry, e.Archive)
+	if len(e.Suggestions) > 0 {
+		msg += fmt.Sprintf(" — did you mean %q?", e.Suggestions[0])
+		if len(e.Suggestions) > 1 {
+			msg += fmt.Sprintf(" (also: %s)", strings.Join(e.Suggestions[1:], ", "))
+		}
+	}
+	return msg
+}
+
+// ListArchive returns the entries of the archive at archivePath, in archive
+// order, without extracting anything
+func (e *UltraFastEngine) ListArchive(ctx context.Context, archivePath, format string) (*ArchiveListing, error) {
+	archivePath = NormalizePath(archivePath)
+	format, err := archiveFormat(archivePath, format)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.acquireOperation(ctx, "list"); err != nil {
+		return nil, err
+	}
+	defer e.releaseOperation("list", time.Now())
+	if !e.IsPathAllowed(archivePath) {
+		return nil, e.AccessDeniedError("archive", archivePath)
+	}
+
+	res := &ArchiveListing{Action: "list", Archive: archivePath, Format: format, Entries: []ArchiveListEntry{}}
+	err = walkArchive(archivePath, format, func(item archiveItem) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		entry := ArchiveListEntry{
+			Name:     item.name,
+			Modified: item.modified,
+			Dir:      item.mode.IsDir(),
+			Symlink:  item.mode&fs.ModeSymlink != 0,
+		}
+		if entry.Dir {
+			res.Dirs++
+		} else {
+			entry.Size = item.size
+			res.Files++
+			res.Bytes += item.size
+		}
+		if len(res.Entries) >= e.config.MaxSearchResults {
+			res.Truncated = true
+			return nil
+		}
+		res.Entries = append(res.Entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ReadArchiveEntry streams the entry of that exact name out of the archive,
+// without writing to disk. At most maxBytes are returned, and never more
+// than MaxResponseSize (counted after base64 encoding); a longer entry comes
+// back truncated. Binary content needs asBase64.
+func (e *UltraFastEngine) ReadArchiveEntry(ctx context.Context, archivePath, entry, format string, maxBytes int64, asBase64 bool) (*ArchiveEntryContent, error) {
+	archivePath = NormalizePath(archivePath)
+	format, err := archiveFormat(archivePath, format)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.acquireOperation(ctx, "read"); err != nil {
+		return nil, err
+	}
+	defer e.releaseOperation("read", time.Now())
+	if !e.IsPathAllowed(archivePath) {
+		return nil, e.AccessDeniedError("archive", archivePath)
+	}
+
+	limit := e.config.MaxResponseSize
+	if asBase64 {
+		limit = limit / 4 * 3
+	}
+	if maxBytes > 0 && (limit <= 0 || maxBytes < limit) {
+		limit = maxBytes
+	}
+
+	var res *ArchiveEntryContent
+	var names []string
+	err = walkArchive(archivePath, format, func(item archiveItem) error {
+		if item.name != entry {
+			names = append(names, item.name)
+			return nil
+		}
+		if !item.mode.IsRegular() {
+			return fmt.Errorf("entry %q is not a regular file", entry)
+		}
+		r, err := item.open()
+		if err != nil {
+			return fmt.Errorf("failed to open entry %s: %w", entry, err)
+		}
+		defer r.Close()
+		var src io.Reader = r
+		if limit > 0 {
+			src = io.LimitReader(r, limit)
+		}
+		data, err := io.ReadAll(src)
+		if err != nil {
+			return fmt.Errorf("failed to read entry %s: %w", entry, err)
+		}
+		res = &ArchiveEntryContent{
+			Action:    "read",
+			Archive:   archivePath,
+			Entry:     entry,
+			Size:      item.size,
+			Modified:  item.modified,
+			Returned:  int64(len(data)),
+			Base64:    asBase64,
+			Truncated: int64(len(data)) < item.size,
+		}
+		if asBase64 {
+			res.Content = base64.StdEncoding.EncodeToString(data)
+		} else {
+			if !DetectContentType(entry, data).Text() {
+				return fmt.Errorf("entry %q is binary: pass as_base64:true to read it", entry)
+			}
+			res.Content = string(data)
+		}
+		return errStopWalk
+	})
+	if err != nil && !errors.Is(err, errStopWalk) {
+		return nil, err
+	}
+	if res == nil {
+		return nil, &ArchiveEntryNotFoundError{Archive: archivePath, Entry: entry, Suggestions: closestEntryNames(entry, names, 3)}
+	}
+	return res, nil
+}
+
+// errStopWalk ends an archive walk early without an error
+var errStopWalk = errors.New("stop walk")
+
+// closestEntryNames returns up to n names resembling want: the same name up
+// to case or a trailing "/", the same base name, then the smallest edit
+// distances within half the length of want
+func closestEntryNames(want string, names []string, n int) []string {
+	type scored struct {
+		name  string
+		score int
+	}
+	wantLower := strings.ToLower(strings.TrimSuffix(want, "/"))
+	wantBase := path.Base(wantLower)
+	maxDist := len(wantLower)/2 + 1
+	var cands []scored
+	for _, name := range names {
+		lower := strings.ToLower(strings.TrimSuffix(name, "/"))
+		switch {
+		case lower == wantLower:
+			cands = append(cands, scored{name, -2})
+		case path.Base(lower) == wantBase:
+			cands = append(cands, scored{name, -1})
+		default:
+			if d := editDistance(wantLower, lower, maxDist); d <= maxDist {
+				cands = append(cands, scored{name, d})
+			}
+		}
+	}
+	sort.SliceStable(cands, func(i, j int) bool { return cands[i].score < cands[j].score })
+	out := make([]string, 0, n)
+	for _, c := range cands {
+		if len(out) == n {
+			break
+		}
+		out = append(out, c.name)
+	}
+	return out
+}
+
+// editDistance is the Levenshtein distance of a and b, or bound+1 as soon
+// as it is known to exceed bound
+func editDistance(a, b string, bound int) int {
+	if d := len(a) - len(b); d > bound || -d > bound {
+		return bound + 1
+	}
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			rowMin = min(rowMin, cur[j])
+		}
+		if rowMin > bound {
+			return bound + 1
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// FormatArchiveListing renders an ArchiveListing as text
+func FormatArchiveListing(r *ArchiveListing, compact bool) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s (%s): %d files, %d directories, %s uncompressed\n",
+		r.Archive, r.Format, r.Files, r.Dirs, formatSize(r.Bytes)))
+	for _, en := range r.Entries {
+		switch {
+		case compact:
+			sb.WriteString(en.Name + "\n")
+		case en.Dir:
+			sb.WriteString(fmt.Sprintf("  %10s  %s  %s\n", "<dir>", en.Modified.Format("2006-01-02 15:04"), en.Name))
+		default:
+			sb.WriteString(fmt.Sprintf("  %10s  %s  %s\n", formatSize(en.Size), en.Modified.Format("2006-01-02 15:04"), en.Name))
+		}
+	}
+	if r.Truncated {
+		sb.WriteString(fmt.Sprintf("… only the first %d entries listed\n", len(r.Entries)))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// FormatArchiveEntryContent renders an ArchiveEntryContent as text: the
+// content, under a header unless compact
+func FormatArchiveEntryContent(r *ArchiveEntryContent, compact bool) string {
+	note := ""
+	if r.Truncated {
+		note = fmt.Sprintf("\n[truncated: %d of %d bytes returned; raise max_bytes or extract the entry]", r.Returned, r.Size)
+	}
+	if compact {
+		return r.Content + note
+	}
+	enc := ""
+	if r.Base64 {
+		enc = ", base64"
+	}
+	return fmt.Sprintf("# %s in %s (%d bytes%s)\n%s%s", r.Entry, r.Archive, r.Size, enc, r.Content, note)
+}
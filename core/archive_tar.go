@@ -0,0 +1,102 @@
+package core
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// writeTarGz packs plan into a new gzip-compressed tarball at path,
+// streaming every file through a pooled buffer. Headers keep each file's
+// mode and modification time; owner names are left out.
+func (e *UltraFastEngine) writeTarGz(ctx context.Context, path string, plan *archivePlan, level int) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer f.Close()
+	zw, err := gzip.NewWriterLevel(f, level)
+	if err != nil {
+		return err
+	}
+	tw := tar.NewWriter(zw)
+	bufPtr := e.bufferPool.Get().(*[]byte)
+	defer e.bufferPool.Put(bufPtr)
+
+	for _, entry := range plan.entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(entry.info, "")
+		if err != nil {
+			return fmt.Errorf("failed to archive %s: %w", entry.src, err)
+		}
+		hdr.Name = entry.name
+		hdr.Uname, hdr.Gname = "", ""
+		if err := tw.WriteHeader(hdr); err != nil {
+			return fmt.Errorf("failed to archive %s: %w", entry.src, err)
+		}
+		if entry.info.IsDir() {
+			continue
+		}
+		if err := copyFileInto(tw, entry.src, *bufPtr); err != nil {
+			return fmt.Errorf("failed to archive %s: %w", entry.src, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("failed to sync archive: %w", err)
+	}
+	return f.Close()
+}
+
+// walkTarGz calls fn for every entry of the tarball at path, in archive
+// order. PAX global headers are not entries and are passed over.
+func walkTarGz(path string, fn func(item archiveItem) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open tarball %s: %w", path, err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("failed to open tarball %s: %w", path, err)
+	}
+	defer zr.Close()
+	tr := tar.NewReader(zr)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tarball %s: %w", path, err)
+		}
+		if hdr.Typeflag == tar.TypeXGlobalHeader {
+			continue
+		}
+		item := archiveItem{
+			name:     hdr.Name,
+			mode:     hdr.FileInfo().Mode(),
+			size:     hdr.Size,
+			modified: hdr.ModTime,
+			linkname: hdr.Linkname,
+			open:     func() (io.ReadCloser, error) { return io.NopCloser(tr), nil },
+		}
+		if hdr.Typeflag == tar.TypeLink {
+			item.mode = os.ModeIrregular // hard links are not extracted
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+}
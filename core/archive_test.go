@@ -0,0 +1,503 @@
+package core
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// archiveFixture lays out a small project: two files under out/, one in a
+// subdirectory, a node_modules tree and (where supported) a symlink
+func archiveFixture(t *testing.T, dir string) {
+	t.Helper()
+	write := func(rel, content string) {
+		p := filepath.Join(dir, filepath.FromSlash(rel))
+		os.MkdirAll(filepath.Dir(p), 0755)
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("out/report.txt", strings.Repeat("report line\n", 200))
+	write("out/data/a.csv", "a,b\n1,2\n")
+	write("out/node_modules/dep/index.js", "module.exports = 1\n")
+	write("out/debug.log", "noise\n")
+	if runtime.GOOS != "windows" {
+		os.Symlink(filepath.Join(dir, "out", "report.txt"), filepath.Join(dir, "out", "link.txt"))
+	}
+}
+
+func zipNames(t *testing.T, path string) []string {
+	t.Helper()
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestCreateArchive_Zip(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	ctx := context.Background()
+	archiveFixture(t, dir)
+	zipPath := filepath.Join(dir, "dist", "out.zip")
+
+	res, err := engine.CreateArchive(ctx, zipPath, "", ArchiveOptions{
+		Sources:          []string{filepath.Join(dir, "out")},
+		Exclude:          []string{"*.log"},
+		CompressionLevel: -1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "out/,out/data/,out/data/a.csv,out/report.txt"
+	if got := strings.Join(zipNames(t, zipPath), ","); got != want {
+		t.Errorf("entries = %s, want %s", got, want)
+	}
+	if res.Files != 2 || res.Bytes != 2400+8 || res.Compressed == 0 || res.Compressed >= res.Bytes || res.Excluded != 2 {
+		t.Errorf("result = %+v", res)
+	}
+	if runtime.GOOS != "windows" && res.SkippedSymlinks != 1 {
+		t.Errorf("symlinks skipped = %d", res.SkippedSymlinks)
+	}
+	if text := FormatArchiveResult(res, true); !strings.HasPrefix(text, "OK: "+zipPath+" — 2 files") {
+		t.Errorf("compact = %s", text)
+	}
+
+	// The archive is not replaced without overwrite
+	if _, err := engine.CreateArchive(ctx, zipPath, "", ArchiveOptions{Sources: []string{filepath.Join(dir, "out")}}); err == nil {
+		t.Error("existing archive replaced without overwrite")
+	}
+
+	// A glob, stored uncompressed, with names relative to base_dir
+	res, err = engine.CreateArchive(ctx, zipPath, "zip", ArchiveOptions{
+		Glob:      filepath.Join(dir, "out", "**", "*.csv"),
+		BaseDir:   filepath.Join(dir, "out"),
+		Overwrite: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(zipNames(t, zipPath), ","); got != "data/a.csv" || res.Compressed <= res.Bytes {
+		t.Errorf("glob entries = %s, result %+v", got, res)
+	}
+
+	// A source outside base_dir, an archive outside the allowed paths and
+	// an unknown format are refused
+	if _, err := engine.CreateArchive(ctx, zipPath, "", ArchiveOptions{
+		Sources: []string{filepath.Join(dir, "out")}, BaseDir: filepath.Join(dir, "out", "data"), Overwrite: true,
+	}); err == nil {
+		t.Error("source outside base_dir accepted")
+	}
+	if _, err := engine.CreateArchive(ctx, filepath.Join(t.TempDir(), "x.zip"), "", ArchiveOptions{Sources: []string{filepath.Join(dir, "out")}}); err == nil {
+		t.Error("archive outside the allowed paths written")
+	}
+	if _, err := engine.CreateArchive(ctx, filepath.Join(dir, "x.rar"), "", ArchiveOptions{Sources: []string{filepath.Join(dir, "out")}}); err == nil {
+		t.Error("unknown archive format accepted")
+	}
+	if tmp, _ := filepath.Glob(filepath.Join(dir, "dist", "*.tmp.*")); len(tmp) > 0 {
+		t.Errorf("temporary files left: %v", tmp)
+	}
+}
+
+func TestCommonDir(t *testing.T) {
+	sep := string(filepath.Separator)
+	a := filepath.Join(sep+"p", "src", "a")
+	b := filepath.Join(sep+"p", "src", "b", "c")
+	if got := commonDir([]string{a, b}); got != filepath.Join(sep+"p", "src") {
+		t.Errorf("commonDir = %s", got)
+	}
+	if got := commonDir([]string{a, a}); got != a {
+		t.Errorf("commonDir of one dir = %s", got)
+	}
+}
+
+// writeTestZip writes a zip whose entries are name → content; a name ending
+// in "/" is a directory
+func writeTestZip(t *testing.T, path string, entries map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(entries[name]))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+}
+
+func TestExtractArchive_Zip(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	ctx := context.Background()
+	archiveFixture(t, dir)
+	os.Chmod(filepath.Join(dir, "out", "data", "a.csv"), 0600)
+	zipPath := filepath.Join(dir, "out.zip")
+	if _, err := engine.CreateArchive(ctx, zipPath, "", ArchiveOptions{Sources: []string{filepath.Join(dir, "out")}}); err != nil {
+		t.Fatal(err)
+	}
+	dest := filepath.Join(dir, "unpacked")
+
+	// Dry run lists without writing
+	res, err := engine.ExtractArchive(ctx, zipPath, dest, "", ExtractOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Extracted != 3 || len(res.Entries) != 3 || res.Entries[0].Action != "extract" {
+		t.Errorf("dry run = %+v", res)
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Error("dry run wrote to dest_dir")
+	}
+
+	res, err = engine.ExtractArchive(ctx, zipPath, dest, "", ExtractOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(filepath.Join(dest, "out", "report.txt"))
+	if err != nil || len(got) != 2400 || res.Extracted != 3 || res.Dirs != 2 {
+		t.Fatalf("extract = %+v, %d bytes, %v", res, len(got), err)
+	}
+	if info, err := os.Stat(filepath.Join(dest, "out", "data", "a.csv")); err != nil || (runtime.GOOS != "windows" && info.Mode().Perm() != 0600) {
+		t.Errorf("mode not kept: %v %v", info.Mode(), err)
+	}
+
+	// Existing files are skipped unless overwrite; include_glob picks a subset
+	os.WriteFile(filepath.Join(dest, "out", "data", "a.csv"), []byte("changed"), 0644)
+	res, err = engine.ExtractArchive(ctx, zipPath, dest, "", ExtractOptions{Include: "*.csv"})
+	if err != nil || res.Extracted != 0 || res.Skipped != 1 || res.Filtered == 0 {
+		t.Errorf("no overwrite = %+v, %v", res, err)
+	}
+	res, err = engine.ExtractArchive(ctx, zipPath, dest, "", ExtractOptions{Include: "*.csv", Overwrite: true})
+	if err != nil || res.Extracted != 1 || res.Overwritten != 1 {
+		t.Errorf("overwrite = %+v, %v", res, err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dest, "out", "data", "a.csv")); string(got) != "a,b\n1,2\n" {
+		t.Errorf("overwritten content = %q", got)
+	}
+
+	// One escaping or absolute entry refuses the whole archive
+	for name, evil := range map[string]string{"slip.zip": "../evil.txt", "nested.zip": "a/../../evil.txt", "abs.zip": "/tmp/evil.txt", "drive.zip": "C:/evil.txt"} {
+		p := filepath.Join(dir, name)
+		writeTestZip(t, p, map[string]string{"good.txt": "ok", evil: "pwned"})
+		if _, err := engine.ExtractArchive(ctx, p, filepath.Join(dir, "slip"), "", ExtractOptions{}); err == nil || !strings.Contains(err.Error(), "unsafe archive entry") {
+			t.Errorf("%s: %s accepted: %v", name, evil, err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "slip", "good.txt")); err == nil {
+			t.Errorf("%s: entries written before the refusal", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "evil.txt")); err == nil {
+		t.Error("zip slip wrote outside dest_dir")
+	}
+
+	// dest_dir outside the allowed paths is refused
+	if _, err := engine.ExtractArchive(ctx, zipPath, t.TempDir(), "", ExtractOptions{}); err == nil {
+		t.Error("dest_dir outside the allowed paths accepted")
+	}
+}
+
+func TestListAndReadArchive_Zip(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	ctx := context.Background()
+	zipPath := filepath.Join(dir, "in.zip")
+	writeTestZip(t, zipPath, map[string]string{
+		"docs/":          "",
+		"docs/README.md": "# Title\n" + strings.Repeat("body\n", 100),
+		"img/logo.bin":   "\x89PNG\x00\x01\x02",
+	})
+
+	list, err := engine.ListArchive(ctx, zipPath, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Files != 2 || list.Dirs != 1 || len(list.Entries) != 3 || !list.Entries[0].Dir || list.Entries[1].Size != 508 {
+		t.Errorf("list = %+v", list)
+	}
+	if text := FormatArchiveListing(list, false); !strings.Contains(text, "docs/README.md") || !strings.Contains(text, "2 files, 1 directories") {
+		t.Errorf("list text:\n%s", text)
+	}
+
+	res, err := engine.ReadArchiveEntry(ctx, zipPath, "docs/README.md", "", 0, false)
+	if err != nil || res.Truncated || res.Returned != 508 || !strings.HasPrefix(res.Content, "# Title\n") {
+		t.Fatalf("read = %+v, %v", res, err)
+	}
+	res, err = engine.ReadArchiveEntry(ctx, zipPath, "docs/README.md", "", 7, false)
+	if err != nil || !res.Truncated || res.Content != "# Title" {
+		t.Errorf("max_bytes read = %+v, %v", res, err)
+	}
+	if text := FormatArchiveEntryContent(res, true); !strings.Contains(text, "[truncated: 7 of 508 bytes") {
+		t.Errorf("truncated text = %q", text)
+	}
+
+	// Binary entries need as_base64
+	if _, err := engine.ReadArchiveEntry(ctx, zipPath, "img/logo.bin", "", 0, false); err == nil || !strings.Contains(err.Error(), "as_base64") {
+		t.Errorf("binary read as text: %v", err)
+	}
+	res, err = engine.ReadArchiveEntry(ctx, zipPath, "img/logo.bin", "", 0, true)
+	if err != nil || res.Content != "iVBORwABAg==" {
+		t.Errorf("base64 read = %+v, %v", res, err)
+	}
+
+	// A miss suggests the closest names
+	_, err = engine.ReadArchiveEntry(ctx, zipPath, "docs/readme.md", "", 0, false)
+	var nf *ArchiveEntryNotFoundError
+	if !errors.As(err, &nf) || len(nf.Suggestions) == 0 || nf.Suggestions[0] != "docs/README.md" {
+		t.Errorf("case miss = %v", err)
+	}
+	_, err = engine.ReadArchiveEntry(ctx, zipPath, "README.md", "", 0, false)
+	if !errors.As(err, &nf) || len(nf.Suggestions) == 0 || nf.Suggestions[0] != "docs/README.md" || !strings.Contains(err.Error(), "did you mean") {
+		t.Errorf("base name miss = %v", err)
+	}
+	_, err = engine.ReadArchiveEntry(ctx, zipPath, "nothing/like/it.txt", "", 0, false)
+	if !errors.As(err, &nf) || len(nf.Suggestions) != 0 {
+		t.Errorf("far miss = %v", err)
+	}
+}
+
+func TestGzipAndGunzipFile(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	ctx := context.Background()
+	logPath := filepath.Join(dir, "app.log")
+	content := strings.Repeat("GET /index.html 200\n", 500)
+	os.WriteFile(logPath, []byte(content), 0640)
+	mtime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	os.Chtimes(logPath, mtime, mtime)
+
+	res, err := engine.GzipFile(ctx, logPath, GzipOptions{Level: -1, DeleteSource: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gzPath := logPath + ".gz"
+	if res.Dest != gzPath || res.BytesBefore != int64(len(content)) || res.BytesAfter >= res.BytesBefore || res.Ratio >= 0.5 || !res.SourceDeleted {
+		t.Errorf("gzip = %+v", res)
+	}
+	if _, err := os.Stat(logPath); !os.IsNotExist(err) {
+		t.Error("source not deleted")
+	}
+	if info, _ := os.Stat(gzPath); !info.ModTime().Equal(mtime) {
+		t.Errorf("gzip mtime = %v", info.ModTime())
+	}
+
+	res, err = engine.GunzipFile(ctx, gzPath, GzipOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(logPath)
+	info, _ := os.Stat(logPath)
+	if string(got) != content || res.Dest != logPath || res.Ratio != float64(res.BytesBefore)/float64(res.BytesAfter) || !info.ModTime().Equal(mtime) {
+		t.Errorf("gunzip = %+v, mtime %v", res, info.ModTime())
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0640 {
+		t.Errorf("gunzip mode = %v", info.Mode())
+	}
+
+	// An existing dest is refused unless overwrite
+	if _, err := engine.GunzipFile(ctx, gzPath, GzipOptions{}); err == nil || !strings.Contains(err.Error(), "overwrite") {
+		t.Errorf("existing dest replaced: %v", err)
+	}
+	if _, err := engine.GunzipFile(ctx, gzPath, GzipOptions{Overwrite: true}); err != nil {
+		t.Errorf("overwrite: %v", err)
+	}
+
+	// Not gzip, no derivable dest, dest outside the allowed paths
+	if _, err := engine.GunzipFile(ctx, logPath, GzipOptions{Dest: filepath.Join(dir, "x")}); err == nil {
+		t.Error("plain file gunzipped")
+	}
+	if _, err := engine.GunzipFile(ctx, logPath, GzipOptions{}); err == nil {
+		t.Error("gunzip without .gz or dest accepted")
+	}
+	if _, err := engine.GzipFile(ctx, logPath, GzipOptions{Level: -1, Dest: filepath.Join(t.TempDir(), "x.gz")}); err == nil {
+		t.Error("dest outside the allowed paths written")
+	}
+	if tmp, _ := filepath.Glob(filepath.Join(dir, "*.tmp.*")); len(tmp) > 0 {
+		t.Errorf("temporary files left: %v", tmp)
+	}
+}
+
+// writeTestTarGz writes a tarball from headers, with content for regular files
+func writeTestTarGz(t *testing.T, path string, hdrs []*tar.Header, contents map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := gzip.NewWriter(f)
+	tw := tar.NewWriter(zw)
+	for _, h := range hdrs {
+		if h.Typeflag == tar.TypeReg {
+			h.Size = int64(len(contents[h.Name]))
+		}
+		if err := tw.WriteHeader(h); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(contents[h.Name]))
+	}
+	tw.Close()
+	zw.Close()
+	f.Close()
+}
+
+func TestArchive_TarGz(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs Unix modes and symlinks")
+	}
+	engine, dir := setupProgressEngine(t)
+	ctx := context.Background()
+	archiveFixture(t, dir)
+	os.WriteFile(filepath.Join(dir, "out", "run.sh"), []byte("#!/bin/sh\n"), 0755)
+	tgz := filepath.Join(dir, "out.tgz")
+
+	res, err := engine.CreateArchive(ctx, tgz, "", ArchiveOptions{Sources: []string{filepath.Join(dir, "out")}, CompressionLevel: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Format != ArchiveTarGz || res.Files != 4 || res.SkippedSymlinks != 1 {
+		t.Errorf("create = %+v", res)
+	}
+	list, err := engine.ListArchive(ctx, tgz, "")
+	if err != nil || list.Files != 4 || list.Entries[0].Name != "out/" {
+		t.Fatalf("list = %+v, %v", list, err)
+	}
+	read, err := engine.ReadArchiveEntry(ctx, tgz, "out/data/a.csv", "", 0, false)
+	if err != nil || read.Content != "a,b\n1,2\n" {
+		t.Errorf("read = %+v, %v", read, err)
+	}
+
+	dest := filepath.Join(dir, "unpacked")
+	ext, err := engine.ExtractArchive(ctx, tgz, dest, "", ExtractOptions{})
+	if err != nil || ext.Extracted != 4 {
+		t.Fatalf("extract = %+v, %v", ext, err)
+	}
+	if info, err := os.Stat(filepath.Join(dest, "out", "run.sh")); err != nil || info.Mode().Perm() != 0755 {
+		t.Errorf("mode not kept: %v, %v", info, err)
+	}
+
+	// follow_symlinks packs the link's target under the link's name
+	res, err = engine.CreateArchive(ctx, tgz, "", ArchiveOptions{Sources: []string{filepath.Join(dir, "out")}, Overwrite: true, FollowSymlinks: true})
+	if err != nil || res.Files != 5 || res.SkippedSymlinks != 0 {
+		t.Errorf("follow create = %+v, %v", res, err)
+	}
+	if read, err := engine.ReadArchiveEntry(ctx, tgz, "out/link.txt", "", 10, false); err != nil || read.Content != "report lin" {
+		t.Errorf("followed link = %+v, %v", read, err)
+	}
+
+	// Traversal refuses the tarball; symlink entries are skipped unless
+	// follow_symlinks, and even then only links inside dest_dir are made
+	evil := filepath.Join(dir, "evil.tar.gz")
+	writeTestTarGz(t, evil, []*tar.Header{
+		{Name: "ok.txt", Typeflag: tar.TypeReg, Mode: 0644},
+		{Name: "../../evil.txt", Typeflag: tar.TypeReg, Mode: 0644},
+	}, map[string]string{"ok.txt": "ok", "../../evil.txt": "pwned"})
+	if _, err := engine.ExtractArchive(ctx, evil, filepath.Join(dir, "evil"), "", ExtractOptions{}); err == nil || !strings.Contains(err.Error(), "unsafe archive entry") {
+		t.Errorf("traversal accepted: %v", err)
+	}
+	links := filepath.Join(dir, "links.tar.gz")
+	writeTestTarGz(t, links, []*tar.Header{
+		{Name: "a.txt", Typeflag: tar.TypeReg, Mode: 0644},
+		{Name: "inside", Typeflag: tar.TypeSymlink, Linkname: "a.txt"},
+		{Name: "outside", Typeflag: tar.TypeSymlink, Linkname: "../../etc/passwd"},
+		{Name: "absolute", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"},
+	}, map[string]string{"a.txt": "a"})
+	ext, err = engine.ExtractArchive(ctx, links, filepath.Join(dir, "l1"), "", ExtractOptions{})
+	if err != nil || ext.Extracted != 1 || ext.Skipped != 3 || len(ext.Warnings) != 3 || ext.Links != 0 {
+		t.Errorf("links skipped = %+v, %v", ext, err)
+	}
+	ext, err = engine.ExtractArchive(ctx, links, filepath.Join(dir, "l2"), "", ExtractOptions{FollowSymlinks: true})
+	if err != nil || ext.Links != 1 || ext.Skipped != 2 {
+		t.Errorf("links followed = %+v, %v", ext, err)
+	}
+	if target, err := os.Readlink(filepath.Join(dir, "l2", "inside")); err != nil || target != "a.txt" {
+		t.Errorf("inside link = %q, %v", target, err)
+	}
+	if _, err := os.Lstat(filepath.Join(dir, "l2", "outside")); err == nil {
+		t.Error("link leaving dest_dir created")
+	}
+}
+
+func TestExtractArchive_ProtectedEntries(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	ctx := context.Background()
+	dest := filepath.Join(dir, "dest")
+	os.MkdirAll(filepath.Join(dest, "locked"), 0755)
+	keep := filepath.Join(dest, "keep.txt")
+	os.WriteFile(keep, []byte("original"), 0644)
+	engine.config.ProtectedPaths = []string{keep, filepath.Join(dest, "locked")}
+
+	// Overwriting a protected file refuses the archive before anything is written
+	zipPath := filepath.Join(dir, "over.zip")
+	writeTestZip(t, zipPath, map[string]string{"good.txt": "ok", "keep.txt": "replaced"})
+	if _, err := engine.ExtractArchive(ctx, zipPath, dest, "", ExtractOptions{Overwrite: true}); err == nil || !strings.Contains(err.Error(), "write-protected") {
+		t.Fatalf("protected file overwritten: %v", err)
+	}
+	if got, _ := os.ReadFile(keep); string(got) != "original" {
+		t.Errorf("protected file = %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "good.txt")); err == nil {
+		t.Error("entries written before the refusal")
+	}
+	// Without overwrite the protected file is only skipped
+	if res, err := engine.ExtractArchive(ctx, zipPath, dest, "", ExtractOptions{}); err != nil || res.Extracted != 1 || res.Skipped != 1 {
+		t.Errorf("no overwrite = %+v, %v", res, err)
+	}
+
+	// New files inside a protected directory are refused too
+	intoPath := filepath.Join(dir, "into.zip")
+	writeTestZip(t, intoPath, map[string]string{"locked/new.txt": "x"})
+	if _, err := engine.ExtractArchive(ctx, intoPath, dest, "", ExtractOptions{}); err == nil || !strings.Contains(err.Error(), "write-protected") {
+		t.Errorf("file created in a protected directory: %v", err)
+	}
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+	// A symlink entry cannot replace a protected file, and a file entry cannot
+	// reach a protected directory through a symlink an earlier entry made
+	linkPath := filepath.Join(dir, "link.tar.gz")
+	writeTestTarGz(t, linkPath, []*tar.Header{
+		{Name: "keep.txt", Typeflag: tar.TypeSymlink, Linkname: "good.txt"},
+	}, nil)
+	if _, err := engine.ExtractArchive(ctx, linkPath, dest, "", ExtractOptions{FollowSymlinks: true, Overwrite: true}); err == nil || !strings.Contains(err.Error(), "write-protected") {
+		t.Errorf("symlink replaced a protected file: %v", err)
+	}
+	doorPath := filepath.Join(dir, "door.tar.gz")
+	writeTestTarGz(t, doorPath, []*tar.Header{
+		{Name: "door", Typeflag: tar.TypeSymlink, Linkname: "locked"},
+		{Name: "door/planted.txt", Typeflag: tar.TypeReg, Mode: 0644},
+	}, map[string]string{"door/planted.txt": "x"})
+	if _, err := engine.ExtractArchive(ctx, doorPath, dest, "", ExtractOptions{FollowSymlinks: true}); err == nil || !strings.Contains(err.Error(), "write-protected") {
+		t.Errorf("file written through a symlink into a protected directory: %v", err)
+	}
+	if _, err := os.Lstat(filepath.Join(dest, "locked", "planted.txt")); err == nil {
+		t.Error("protected directory written through a symlink")
+	}
+	if info, err := os.Lstat(keep); err != nil || info.Mode()&os.ModeSymlink != 0 {
+		t.Errorf("protected file replaced: %v, %v", info, err)
+	}
+}
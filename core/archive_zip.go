@@ -0,0 +1,105 @@
+package core
+
+import (
+	"archive/zip"
+	"compress/flate"
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+// writeZip packs plan into a new zip file at path, streaming every file
+// through a pooled buffer
+func (e *UltraFastEngine) writeZip(ctx context.Context, path string, plan *archivePlan, level int) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	if level > 0 {
+		zw.RegisterCompressor(zip.Deflate, func(out io.Writer) (io.WriteCloser, error) {
+			return flate.NewWriter(out, level)
+		})
+	}
+	bufPtr := e.bufferPool.Get().(*[]byte)
+	defer e.bufferPool.Put(bufPtr)
+
+	for _, entry := range plan.entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		hdr, err := zip.FileInfoHeader(entry.info)
+		if err != nil {
+			return fmt.Errorf("failed to archive %s: %w", entry.src, err)
+		}
+		hdr.Name = entry.name
+		if entry.info.IsDir() {
+			hdr.Method = zip.Store
+		} else if level == 0 {
+			hdr.Method = zip.Store
+		} else {
+			hdr.Method = zip.Deflate
+		}
+		w, err := zw.CreateHeader(hdr)
+		if err != nil {
+			return fmt.Errorf("failed to archive %s: %w", entry.src, err)
+		}
+		if entry.info.IsDir() {
+			continue
+		}
+		if err := copyFileInto(w, entry.src, *bufPtr); err != nil {
+			return fmt.Errorf("failed to archive %s: %w", entry.src, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("failed to sync archive: %w", err)
+	}
+	return f.Close()
+}
+
+// copyFileInto streams the file at src into w through buf
+func copyFileInto(w io.Writer, src string, buf []byte) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	_, err = io.CopyBuffer(w, in, buf)
+	return err
+}
+
+// walkZip calls fn for every entry of the zip at path, in archive order
+func walkZip(path string, fn func(item archiveItem) error) error {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("failed to open zip %s: %w", path, err)
+	}
+	defer zr.Close()
+	for _, f := range zr.File {
+		f := f
+		item := archiveItem{
+			name:     f.Name,
+			mode:     f.Mode(),
+			size:     int64(f.UncompressedSize64),
+			modified: f.Modified,
+			open:     func() (io.ReadCloser, error) { return f.Open() },
+		}
+		if item.mode&os.ModeSymlink != 0 {
+			// A zip symlink stores its target as the entry content
+			if r, err := f.Open(); err == nil {
+				target, _ := io.ReadAll(io.LimitReader(r, 4096))
+				r.Close()
+				item.linkname = string(target)
+			}
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,380 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+)
+
+// server_info artifact keeps generated content in named slots between tool
+// calls, so it can be written to disk later without being sent again. The
+// slots share a byte budget; capturing past it evicts the least recently
+// used ones. With PersistArtifacts they also survive restarts
+// (artifacts_persist.go).
+
+// DefaultMaxArtifactBytes is the total size of the artifact slots when
+// Config.MaxArtifactBytes is 0
+const DefaultMaxArtifactBytes int64 = 64 << 20
+
+// ArtifactLast names the most recently captured slot wherever an artifact
+// name is taken; it cannot be captured into itself
+const ArtifactLast = "last"
+
+// artifactDefault is the slot a capture without a name goes to
+const artifactDefault = "default"
+
+// artifact is one named slot
+type artifact struct {
+	name     string
+	content  string
+	captured time.Time
+	used     time.Time
+	// chunks counts the parts of a chunked capture; pending is set until its
+	// final chunk arrives, and total is the announced count (0 = unknown)
+	chunks  int
+	total   int
+	pending bool
+}
+
+// ArtifactInfo describes one artifact slot
+type ArtifactInfo struct {
+	Name     string    `json:"name"`
+	Bytes    int       `json:"bytes"`
+	Lines    int       `json:"lines"`
+	Captured time.Time `json:"captured"`
+	Used     time.Time `json:"used"` // last capture or write
+	Last     bool      `json:"last,omitempty"`
+	Chunks   int       `json:"chunks,omitempty"`  // parts of a chunked capture so far
+	Total    int       `json:"total,omitempty"`   // announced part count
+	Pending  bool      `json:"pending,omitempty"` // chunked capture still waiting for final
+}
+
+// ArtifactList is the server_info artifact info/list response
+type ArtifactList struct {
+	Artifacts  []ArtifactInfo `json:"artifacts"` // most recently used first
+	TotalBytes int64          `json:"total_bytes"`
+	MaxBytes   int64          `json:"max_bytes"`
+	Dir        string         `json:"dir,omitempty"` // where the slots persist (PersistArtifacts)
+}
+
+// ArtifactCapture is the server_info artifact capture response
+type ArtifactCapture struct {
+	Artifact ArtifactInfo `json:"artifact"`
+	Replaced bool         `json:"replaced,omitempty"` // the slot held an earlier capture
+	Evicted  []string     `json:"evicted,omitempty"`  // slots dropped to stay under MaxBytes
+	Warning  string       `json:"warning,omitempty"`  // persisting failed; kept in memory only
+}
+
+// artifactMaxBytes is the configured artifact budget
+func (e *UltraFastEngine) artifactMaxBytes() int64 {
+	if e.config.MaxArtifactBytes > 0 {
+		return e.config.MaxArtifactBytes
+	}
+	return DefaultMaxArtifactBytes
+}
+
+// resolveArtifactName maps "" and "last" to the most recently captured
+// slot. Callers hold artifactMutex.
+func (e *UltraFastEngine) resolveArtifactName(name string) (string, error) {
+	if name != "" && name != ArtifactLast {
+		return name, nil
+	}
+	if e.artifactLast == "" {
+		return "", fmt.Errorf("no artifact captured")
+	}
+	return e.artifactLast, nil
+}
+
+// artifactInfo describes a; callers hold artifactMutex
+func (e *UltraFastEngine) artifactInfo(a *artifact) ArtifactInfo {
+	return ArtifactInfo{
+		Name:     a.name,
+		Bytes:    len(a.content),
+		Lines:    strings.Count(a.content, "\n") + 1,
+		Captured: a.captured,
+		Used:     a.used,
+		Last:     a.name == e.artifactLast,
+		Chunks:   a.chunks,
+		Total:    a.total,
+		Pending:  a.pending,
+	}
+}
+
+// CaptureArtifact stores content in the named slot ("" = "default"),
+// replacing what it held, and makes it the "last" artifact. Least recently
+// used slots are evicted to keep the total within MaxArtifactBytes; content
+// larger than that on its own is refused.
+func (e *UltraFastEngine) CaptureArtifact(ctx context.Context, name, content string) (*ArtifactCapture, error) {
+	if name == "" {
+		name = artifactDefault
+	}
+	if name == ArtifactLast {
+		return nil, fmt.Errorf("%q names the most recent artifact and cannot be captured into; pick another name", ArtifactLast)
+	}
+	limit := e.artifactMaxBytes()
+	if int64(len(content)) > limit {
+		return nil, fmt.Errorf("artifact is %s, over the %s artifact limit (--max-artifact-bytes)",
+			formatSize(int64(len(content))), formatSize(limit))
+	}
+
+	e.artifactMutex.Lock()
+	defer e.artifactMutex.Unlock()
+	e.prepareArtifacts()
+	res := &ArtifactCapture{Replaced: e.removeArtifact(name)}
+	now := time.Now()
+	for e.artifactBytes+int64(len(content)) > limit && e.artifactOrder.Len() > 0 {
+		oldest := e.artifactOrder.Back().Value.(*artifact)
+		e.removeArtifact(oldest.name)
+		e.unpersistArtifact(oldest.name)
+		res.Evicted = append(res.Evicted, oldest.name)
+	}
+	a := &artifact{name: name, content: content, captured: now, used: now}
+	e.artifacts[name] = e.artifactOrder.PushFront(a)
+	e.artifactBytes += int64(len(content))
+	e.artifactLast = name
+	res.Artifact = e.artifactInfo(a)
+	if err := e.persistArtifact(a); err != nil {
+		slog.Warn("Failed to persist artifact", "name", name, "error", err)
+		res.Warning = fmt.Sprintf("not persisted, lost on restart: %v", err)
+	}
+	return res, nil
+}
+
+// CaptureArtifactChunk appends one part of content too large for a single
+// call to the named slot ("" = "default"). Index 0 starts a new capture,
+// replacing the slot; every later index must follow the previous one, and
+// must stay below total when total is given. The slot cannot be written
+// until the chunk with final arrives (the total-th one, when announced).
+func (e *UltraFastEngine) CaptureArtifactChunk(ctx context.Context, name, chunk string, index, total int, final bool) (*ArtifactCapture, error) {
+	if name == "" {
+		name = artifactDefault
+	}
+	if name == ArtifactLast {
+		return nil, fmt.Errorf("%q names the most recent artifact and cannot be captured into; pick another name", ArtifactLast)
+	}
+	if index < 0 || total < 0 {
+		return nil, fmt.Errorf("index and total must not be negative")
+	}
+	if total > 0 && index >= total {
+		return nil, fmt.Errorf("chunk index %d is past total %d (indexes start at 0)", index, total)
+	}
+	if total > 0 && final != (index == total-1) {
+		return nil, fmt.Errorf("chunk %d of %d: final must be set on the last chunk (index %d) and only there", index, total, total-1)
+	}
+	limit := e.artifactMaxBytes()
+
+	e.artifactMutex.Lock()
+	defer e.artifactMutex.Unlock()
+	e.prepareArtifacts()
+	res := &ArtifactCapture{}
+	var a *artifact
+	if index == 0 {
+		res.Replaced = e.removeArtifact(name)
+		now := time.Now()
+		a = &artifact{name: name, captured: now, used: now, total: total, pending: true}
+		e.artifacts[name] = e.artifactOrder.PushFront(a)
+	} else {
+		el, ok := e.artifacts[name]
+		if !ok || !el.Value.(*artifact).pending {
+			return nil, fmt.Errorf("no chunked capture of %q in progress: start it with index 0", name)
+		}
+		a = el.Value.(*artifact)
+		if index != a.chunks {
+			return nil, fmt.Errorf("chunk index %d out of order: %d chunks of %q received, send index %d next (or index 0 to restart)",
+				index, a.chunks, name, a.chunks)
+		}
+		if total > 0 && a.total > 0 && total != a.total {
+			return nil, fmt.Errorf("total changed from %d to %d mid-capture; restart with index 0", a.total, total)
+		}
+		if total > 0 {
+			a.total = total
+		}
+		e.artifactOrder.MoveToFront(el)
+	}
+	if e.artifactBytes+int64(len(chunk)) > limit {
+		for e.artifactBytes+int64(len(chunk)) > limit && e.artifactOrder.Back().Value.(*artifact) != a {
+			oldest := e.artifactOrder.Back().Value.(*artifact)
+			e.removeArtifact(oldest.name)
+			e.unpersistArtifact(oldest.name)
+			res.Evicted = append(res.Evicted, oldest.name)
+		}
+		if e.artifactBytes+int64(len(chunk)) > limit {
+			return nil, fmt.Errorf("artifact %q would reach %s, over the %s artifact limit (--max-artifact-bytes)",
+				name, formatSize(int64(len(a.content)+len(chunk))), formatSize(limit))
+		}
+	}
+	a.content += chunk
+	a.chunks++
+	a.used = time.Now()
+	a.pending = !final
+	e.artifactBytes += int64(len(chunk))
+	e.artifactLast = name
+	res.Artifact = e.artifactInfo(a)
+	if err := e.persistArtifact(a); err != nil {
+		slog.Warn("Failed to persist artifact", "name", name, "error", err)
+		res.Warning = fmt.Sprintf("not persisted, lost on restart: %v", err)
+	}
+	return res, nil
+}
+
+// removeArtifact drops a slot; callers hold artifactMutex
+func (e *UltraFastEngine) removeArtifact(name string) bool {
+	el, ok := e.artifacts[name]
+	if !ok {
+		return false
+	}
+	e.artifactBytes -= int64(len(el.Value.(*artifact).content))
+	e.artifactOrder.Remove(el)
+	delete(e.artifacts, name)
+	if e.artifactLast == name {
+		e.artifactLast = ""
+		var latest *artifact
+		for _, el := range e.artifacts {
+			if a := el.Value.(*artifact); latest == nil || a.captured.After(latest.captured) {
+				latest = a
+			}
+		}
+		if latest != nil {
+			e.artifactLast = latest.name
+		}
+	}
+	return true
+}
+
+// getArtifact returns the content of a slot ("" or "last" for the most
+// recent) and marks it used
+func (e *UltraFastEngine) getArtifact(name string) (*artifact, error) {
+	e.artifactMutex.Lock()
+	defer e.artifactMutex.Unlock()
+	e.prepareArtifacts()
+	resolved, err := e.resolveArtifactName(name)
+	if err != nil {
+		return nil, err
+	}
+	el, ok := e.artifacts[resolved]
+	if !ok {
+		return nil, fmt.Errorf("no artifact named %q (server_info artifact info lists them)", resolved)
+	}
+	a := el.Value.(*artifact)
+	if a.pending {
+		return nil, fmt.Errorf("artifact %q is incomplete: %d chunks, %d bytes assembled so far; send the last chunk with final:true first",
+			resolved, a.chunks, len(a.content))
+	}
+	a.used = time.Now()
+	e.artifactOrder.MoveToFront(el)
+	e.persistArtifactIndex()
+	copied := *a
+	return &copied, nil
+}
+
+// WriteArtifact writes the named artifact ("" or "last" for the most
+// recent) to path. A non-empty expectedHash, the content_hash from
+// DiffArtifact, must still match the file.
+func (e *UltraFastEngine) WriteArtifact(ctx context.Context, name, path, expectedHash string) (*ArtifactInfo, error) {
+	a, err := e.getArtifact(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.checkArtifactTarget(path, expectedHash); err != nil {
+		return nil, err
+	}
+	if err := e.WriteFileContent(ctx, path, a.content); err != nil {
+		return nil, err
+	}
+	e.artifactMutex.Lock()
+	info := e.artifactInfo(a)
+	e.artifactMutex.Unlock()
+	return &info, nil
+}
+
+// DeleteArtifact drops the named slot ("last" for the most recent)
+func (e *UltraFastEngine) DeleteArtifact(name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("name is required to delete an artifact")
+	}
+	e.artifactMutex.Lock()
+	defer e.artifactMutex.Unlock()
+	e.prepareArtifacts()
+	resolved, err := e.resolveArtifactName(name)
+	if err != nil {
+		return "", err
+	}
+	if !e.removeArtifact(resolved) {
+		return "", fmt.Errorf("no artifact named %q", resolved)
+	}
+	e.unpersistArtifact(resolved)
+	e.persistArtifactIndex()
+	return resolved, nil
+}
+
+// ListArtifacts describes every slot, most recently used first
+func (e *UltraFastEngine) ListArtifacts() *ArtifactList {
+	e.artifactMutex.Lock()
+	defer e.artifactMutex.Unlock()
+	e.prepareArtifacts()
+	res := &ArtifactList{Artifacts: []ArtifactInfo{}, TotalBytes: e.artifactBytes, MaxBytes: e.artifactMaxBytes(), Dir: e.artifactDir}
+	for el := e.artifactOrder.Front(); el != nil; el = el.Next() {
+		res.Artifacts = append(res.Artifacts, e.artifactInfo(el.Value.(*artifact)))
+	}
+	return res
+}
+
+// FormatArtifactList renders an ArtifactList as text
+func FormatArtifactList(r *ArtifactList, compact bool) string {
+	if len(r.Artifacts) == 0 {
+		return "No artifact captured"
+	}
+	if compact {
+		names := make([]string, len(r.Artifacts))
+		for i, a := range r.Artifacts {
+			names[i] = fmt.Sprintf("%s (%s)", a.Name, formatSize(int64(a.Bytes)))
+		}
+		sort.Strings(names)
+		return fmt.Sprintf("%d artifacts, %s of %s: %s", len(r.Artifacts), formatSize(r.TotalBytes), formatSize(r.MaxBytes), strings.Join(names, ", "))
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Artifacts: %d, %s of %s (least recently used evicted first)\n", len(r.Artifacts), formatSize(r.TotalBytes), formatSize(r.MaxBytes)))
+	for _, a := range r.Artifacts {
+		last := ""
+		if a.Pending {
+			last = fmt.Sprintf("  (incomplete: %d chunks)", a.Chunks)
+		}
+		if a.Last {
+			last += "  ← last"
+		}
+		sb.WriteString(fmt.Sprintf("  %-20s %10s %6d lines  captured %s%s\n", a.Name, formatSize(int64(a.Bytes)), a.Lines, FormatAge(a.Captured), last))
+	}
+	if r.Dir != "" {
+		sb.WriteString(fmt.Sprintf("Persisted in %s\n", r.Dir))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// FormatArtifactCapture renders an ArtifactCapture as text
+func FormatArtifactCapture(r *ArtifactCapture) string {
+	verb := "Captured"
+	if r.Replaced {
+		verb = "Replaced"
+	}
+	text := fmt.Sprintf("%s artifact %q: %d bytes, %d lines", verb, r.Artifact.Name, r.Artifact.Bytes, r.Artifact.Lines)
+	if a := r.Artifact; a.Chunks > 0 {
+		switch {
+		case a.Pending && a.Total > 0:
+			text = fmt.Sprintf("Chunk %d of %d of artifact %q received: %d bytes assembled", a.Chunks, a.Total, a.Name, a.Bytes)
+		case a.Pending:
+			text = fmt.Sprintf("Chunk %d of artifact %q received: %d bytes assembled; send the last one with final:true", a.Chunks, a.Name, a.Bytes)
+		default:
+			text = fmt.Sprintf("Artifact %q complete: %d chunks, %d bytes, %d lines", a.Name, a.Chunks, a.Bytes, a.Lines)
+		}
+	}
+	if len(r.Evicted) > 0 {
+		text += fmt.Sprintf("\n⚠️  evicted to stay under the artifact limit: %s", strings.Join(r.Evicted, ", "))
+	}
+	if r.Warning != "" {
+		text += "\n⚠️  " + r.Warning
+	}
+	return text
+}
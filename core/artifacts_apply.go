@@ -0,0 +1,141 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Artifact apply modes
+const (
+	ArtifactOverwrite = "overwrite" // the artifact replaces the file
+	ArtifactPatch     = "patch"     // the artifact is a unified diff for the file
+	ArtifactAppend    = "append"    // the artifact is added at the end of the file
+)
+
+// ArtifactApply is the result of ApplyArtifact
+type ArtifactApply struct {
+	Artifact    ArtifactInfo `json:"artifact"`
+	Path        string       `json:"path"`
+	Mode        string       `json:"mode"`
+	Created     bool         `json:"created,omitempty"`
+	Added       int          `json:"added"`
+	Removed     int          `json:"removed"`
+	BytesBefore int          `json:"bytes_before"`
+	BytesAfter  int          `json:"bytes_after"`
+	BackupID    string       `json:"backup_id,omitempty"`
+	ContentHash string       `json:"content_hash"` // of the file as written
+}
+
+// ApplyArtifact writes the named artifact ("" or "last" for the most recent)
+// into path as an edit rather than a blind rewrite: overwrite replaces the
+// file, patch applies the artifact as a unified diff, append adds it at the
+// end. An existing file is backed up first, the write goes through the
+// same hooks, secret scan and atomic rename as write_file, and the result
+// reports the lines changed. A non-empty expectedHash, the
+// content_hash from DiffArtifact, must still match the file.
+func (e *UltraFastEngine) ApplyArtifact(ctx context.Context, name, path, mode, expectedHash string) (*ArtifactApply, error) {
+	if mode == "" {
+		mode = ArtifactOverwrite
+	}
+	if mode != ArtifactOverwrite && mode != ArtifactPatch && mode != ArtifactAppend {
+		return nil, fmt.Errorf("invalid mode %q: use overwrite, patch or append", mode)
+	}
+	a, err := e.getArtifact(name)
+	if err != nil {
+		return nil, err
+	}
+	path = NormalizePath(path)
+
+	if !e.IsPathAllowed(path) {
+		return nil, e.AccessDeniedError("apply_artifact", path)
+	}
+	if err := e.CheckWritable("apply_artifact", path); err != nil {
+		return nil, err
+	}
+	// Read and back up the file the write will land on, not a symlink to it
+	if path, err = e.ResolveAndAuthorize("apply_artifact", path); err != nil {
+		return nil, err
+	}
+	if err := e.checkArtifactTarget(path, expectedHash); err != nil {
+		return nil, err
+	}
+
+	res := &ArtifactApply{Path: path, Mode: mode}
+	old := ""
+	if info, err := os.Stat(path); err == nil {
+		if info.IsDir() {
+			return nil, fmt.Errorf("%s is a directory", path)
+		}
+		if err := e.validateEditableFile(path); err != nil {
+			return nil, fmt.Errorf("file validation failed: %w", err)
+		}
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, &PathError{Op: "apply_artifact", Path: path, Err: err}
+		}
+		old = string(raw)
+	} else if errors.Is(err, os.ErrNotExist) {
+		if mode == ArtifactPatch {
+			return nil, fmt.Errorf("%s does not exist: patch mode needs the file the diff was made against", path)
+		}
+		res.Created = true
+	} else {
+		return nil, &PathError{Op: "apply_artifact", Path: path, Err: err}
+	}
+
+	updated := a.content
+	switch mode {
+	case ArtifactPatch:
+		if updated, err = ApplyUnifiedDiff(old, a.content); err != nil {
+			return nil, fmt.Errorf("artifact %q does not apply to %s: %w", a.name, path, err)
+		}
+	case ArtifactAppend:
+		updated = old + a.content
+	}
+
+	if !res.Created && e.backupManager != nil {
+		e.backupChainMu.RLock()
+		previousBackupID := e.backupChain[path]
+		e.backupChainMu.RUnlock()
+		res.BackupID, err = e.backupManager.CreateBackupWithContextAndParent(path, "apply_artifact",
+			fmt.Sprintf("Apply artifact %q (%s)", a.name, mode), previousBackupID)
+		if err != nil {
+			return nil, fmt.Errorf("could not create backup: %w", err)
+		}
+		e.backupChainMu.Lock()
+		e.backupChain[path] = res.BackupID
+		e.backupChainMu.Unlock()
+		SetBackupID(ctx, res.BackupID, previousBackupID)
+	}
+	// The engine write runs the pre-write hooks and secret scan, keeps the
+	// file's EOL style and mode, and syncs the result like any write_file
+	if _, updated, err = e.writeFileContent(ctx, path, updated); err != nil {
+		return nil, err
+	}
+
+	res.Added, res.Removed = countChanges(splitLines(old), splitLines(updated))
+	res.BytesBefore, res.BytesAfter = len(old), len(updated)
+	res.ContentHash = contentHashFNV(updated)
+	e.artifactMutex.Lock()
+	res.Artifact = e.artifactInfo(a)
+	e.artifactMutex.Unlock()
+	e.recordOperation(ctx, OperationRecord{Operation: "apply_artifact", Path: path, Replacements: 1,
+		BytesWritten: int64(len(updated)), BackupID: res.BackupID})
+	return res, nil
+}
+
+// FormatArtifactApply renders an ArtifactApply as text
+func FormatArtifactApply(r *ArtifactApply) string {
+	verb := map[string]string{ArtifactOverwrite: "Wrote", ArtifactPatch: "Patched", ArtifactAppend: "Appended"}[r.Mode]
+	text := fmt.Sprintf("%s artifact %q into %s: +%d -%d lines, %s → %s",
+		verb, r.Artifact.Name, r.Path, r.Added, r.Removed, formatSize(int64(r.BytesBefore)), formatSize(int64(r.BytesAfter)))
+	if r.Created {
+		text += " (new file)"
+	}
+	if r.BackupID != "" {
+		text += fmt.Sprintf("\nBackup: %s", r.BackupID)
+	}
+	return text + fmt.Sprintf("\ncontent_hash: %s", r.ContentHash)
+}
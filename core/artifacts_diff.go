@@ -0,0 +1,105 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ArtifactDiff compares an artifact with the file it would overwrite
+type ArtifactDiff struct {
+	Artifact    ArtifactInfo `json:"artifact"`
+	Path        string       `json:"path"`
+	Exists      bool         `json:"exists"`
+	ContentHash string       `json:"content_hash,omitempty"` // of the file; pass as expected_hash to write
+	Identical   bool         `json:"identical"`
+	Added       int          `json:"added"`
+	Removed     int          `json:"removed"`
+	Diff        string       `json:"diff,omitempty"` // unified, file → artifact
+}
+
+// DiffArtifact diffs the named artifact ("" or "last" for the most recent)
+// against the current content of path, which may not exist yet. The file's
+// content_hash is returned so a following write can insist on the file
+// still being the one compared.
+func (e *UltraFastEngine) DiffArtifact(ctx context.Context, name, path string, contextLines int) (*ArtifactDiff, error) {
+	if contextLines < 0 {
+		contextLines = 3
+	}
+	a, err := e.getArtifact(name)
+	if err != nil {
+		return nil, err
+	}
+	path = NormalizePath(path)
+	if !e.IsPathAllowed(path) {
+		return nil, e.AccessDeniedError("read", path)
+	}
+	e.artifactMutex.Lock()
+	res := &ArtifactDiff{Artifact: e.artifactInfo(a), Path: path}
+	e.artifactMutex.Unlock()
+
+	current := ""
+	if info, err := os.Stat(path); err == nil {
+		if info.IsDir() {
+			return nil, fmt.Errorf("%s is a directory", path)
+		}
+		if current, err = e.ReadFileContent(ctx, path); err != nil {
+			return nil, err
+		}
+		if !DetectContentType(path, []byte(current)).Text() {
+			return nil, fmt.Errorf("%s is not a UTF-8 text file and cannot be diffed", path)
+		}
+		res.Exists = true
+		res.ContentHash = contentHashFNV(current)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, &PathError{Op: "read", Path: path, Err: err}
+	}
+
+	res.Identical = current == a.content
+	if !res.Identical {
+		res.Added, res.Removed = countChanges(splitLines(current), splitLines(a.content))
+		res.Diff = UnifiedDiffContext(current, a.content, path, contextLines)
+	}
+	return res, nil
+}
+
+// checkArtifactTarget refuses a write when expectedHash is set and path no
+// longer has that content_hash
+func (e *UltraFastEngine) checkArtifactTarget(path, expectedHash string) error {
+	if expectedHash == "" {
+		return nil
+	}
+	raw, err := os.ReadFile(NormalizePath(path))
+	if err != nil {
+		return fmt.Errorf("expected_hash given but %s cannot be read: %w", path, err)
+	}
+	if actual := contentHashFNV(string(raw)); actual != expectedHash {
+		return fmt.Errorf("stale write: %s changed since it was compared (expected hash: %s, actual: %s). Diff the artifact again first",
+			path, expectedHash, actual)
+	}
+	return nil
+}
+
+// FormatArtifactDiff renders an ArtifactDiff as text
+func FormatArtifactDiff(r *ArtifactDiff) string {
+	var sb strings.Builder
+	switch {
+	case r.Identical:
+		fmt.Fprintf(&sb, "Artifact %q is identical to %s", r.Artifact.Name, r.Path)
+	case !r.Exists:
+		fmt.Fprintf(&sb, "Artifact %q vs %s (does not exist yet): +%d lines", r.Artifact.Name, r.Path, r.Added)
+	default:
+		fmt.Fprintf(&sb, "Artifact %q vs %s: +%d -%d lines", r.Artifact.Name, r.Path, r.Added, r.Removed)
+	}
+	if r.ContentHash != "" {
+		fmt.Fprintf(&sb, " (content_hash: %s)", r.ContentHash)
+	}
+	sb.WriteString("\n")
+	if r.Diff != "" {
+		sb.WriteString("\n")
+		sb.WriteString(r.Diff)
+	}
+	return sb.String()
+}
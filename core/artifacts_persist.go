@@ -0,0 +1,187 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// With PersistArtifacts every artifact slot is mirrored to a file under
+// <backup-dir>/artifacts, listed in an index, so a capture survives the
+// frequent restarts of the server by its client. The files are read back on
+// the first artifact call after startup; slots older than ArtifactMaxAge are
+// dropped then and on later calls.
+
+// artifactDirName is the artifact directory under the backup dir
+const artifactDirName = "artifacts"
+
+// artifactIndexFile lists the persisted slots
+const artifactIndexFile = "index.json"
+
+// DefaultArtifactMaxAge is how long an artifact is kept when
+// Config.ArtifactMaxAge is 0
+const DefaultArtifactMaxAge = 7 * 24 * time.Hour
+
+// persistedArtifact is one slot in the artifact index
+type persistedArtifact struct {
+	Name     string    `json:"name"`
+	File     string    `json:"file"`
+	Bytes    int       `json:"bytes"`
+	Captured time.Time `json:"captured"`
+	Used     time.Time `json:"used"`
+	Chunks   int       `json:"chunks,omitempty"`
+	Total    int       `json:"total,omitempty"`
+	Pending  bool      `json:"pending,omitempty"`
+}
+
+// artifactIndex is the on-disk index of the artifact slots
+type artifactIndex struct {
+	Last      string              `json:"last,omitempty"`
+	Artifacts []persistedArtifact `json:"artifacts"` // most recently used first
+}
+
+// artifactFileName is the file holding the named slot: a hash, since names
+// are free-form
+func artifactFileName(name string) string {
+	sum := sha256.Sum256([]byte(name))
+	return hex.EncodeToString(sum[:8]) + ".artifact"
+}
+
+// artifactMaxAge is the configured artifact lifetime
+func (e *UltraFastEngine) artifactMaxAge() time.Duration {
+	if e.config.ArtifactMaxAge > 0 {
+		return e.config.ArtifactMaxAge
+	}
+	return DefaultArtifactMaxAge
+}
+
+// prepareArtifacts loads the persisted slots on first use and drops the
+// expired ones. Callers hold artifactMutex.
+func (e *UltraFastEngine) prepareArtifacts() {
+	if e.artifactDir != "" && !e.artifactsLoaded {
+		e.artifactsLoaded = true
+		if err := e.loadArtifacts(); err != nil {
+			slog.Warn("Persisted artifacts unusable, starting empty", "dir", e.artifactDir, "error", err)
+		}
+	}
+	cutoff := time.Now().Add(-e.artifactMaxAge())
+	expired := false
+	for name, el := range e.artifacts {
+		if el.Value.(*artifact).captured.Before(cutoff) {
+			e.removeArtifact(name)
+			e.unpersistArtifact(name)
+			expired = true
+		}
+	}
+	if expired {
+		e.persistArtifactIndex()
+	}
+}
+
+// loadArtifacts reads the index and slot files from artifactDir. Callers
+// hold artifactMutex.
+func (e *UltraFastEngine) loadArtifacts() error {
+	data, err := os.ReadFile(filepath.Join(e.artifactDir, artifactIndexFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var idx artifactIndex
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return fmt.Errorf("bad artifact index: %w", err)
+	}
+	limit := e.artifactMaxBytes()
+	for _, p := range idx.Artifacts {
+		content, err := os.ReadFile(filepath.Join(e.artifactDir, p.File))
+		if err != nil || len(content) != p.Bytes || e.artifactBytes+int64(len(content)) > limit {
+			slog.Warn("Persisted artifact dropped", "name", p.Name, "error", err)
+			os.Remove(filepath.Join(e.artifactDir, p.File))
+			continue
+		}
+		// The index is most recently used first: append at the back
+		a := &artifact{name: p.Name, content: string(content), captured: p.Captured, used: p.Used,
+			chunks: p.Chunks, total: p.Total, pending: p.Pending}
+		e.artifacts[p.Name] = e.artifactOrder.PushBack(a)
+		e.artifactBytes += int64(len(content))
+	}
+	if _, ok := e.artifacts[idx.Last]; ok {
+		e.artifactLast = idx.Last
+	} else if e.artifactOrder.Len() > 0 {
+		e.artifactLast = e.artifactOrder.Front().Value.(*artifact).name
+	}
+	return nil
+}
+
+// persistArtifact writes the slot's content next to the index. Failures are
+// returned for the capture to warn about. Callers hold artifactMutex.
+func (e *UltraFastEngine) persistArtifact(a *artifact) error {
+	if e.artifactDir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(e.artifactDir, 0700); err != nil {
+		return err
+	}
+	path := filepath.Join(e.artifactDir, artifactFileName(a.name))
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(a.content), 0600); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return e.persistArtifactIndex()
+}
+
+// unpersistArtifact removes the slot's file; the caller rewrites the index.
+// Callers hold artifactMutex.
+func (e *UltraFastEngine) unpersistArtifact(name string) {
+	if e.artifactDir == "" {
+		return
+	}
+	if err := os.Remove(filepath.Join(e.artifactDir, artifactFileName(name))); err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Warn("Failed to remove persisted artifact", "name", name, "error", err)
+	}
+}
+
+// persistArtifactIndex rewrites the index from the slots in memory.
+// Callers hold artifactMutex.
+func (e *UltraFastEngine) persistArtifactIndex() error {
+	if e.artifactDir == "" {
+		return nil
+	}
+	idx := artifactIndex{Last: e.artifactLast, Artifacts: []persistedArtifact{}}
+	for el := e.artifactOrder.Front(); el != nil; el = el.Next() {
+		a := el.Value.(*artifact)
+		idx.Artifacts = append(idx.Artifacts, persistedArtifact{
+			Name: a.name, File: artifactFileName(a.name), Bytes: len(a.content), Captured: a.captured, Used: a.used,
+			Chunks: a.chunks, Total: a.total, Pending: a.pending,
+		})
+	}
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(e.artifactDir, 0700); err != nil {
+		return err
+	}
+	path := filepath.Join(e.artifactDir, artifactIndexFile)
+	if err := os.WriteFile(path+".tmp", data, 0600); err != nil {
+		slog.Warn("Failed to persist artifact index", "error", err)
+		return err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		slog.Warn("Failed to persist artifact index", "error", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,320 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mcp/filesystem-ultra/cache"
+)
+
+func TestArtifactSlots(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	ctx := context.Background()
+
+	if _, err := engine.WriteArtifact(ctx, "", filepath.Join(dir, "x.txt"), ""); err == nil {
+		t.Error("write without a capture succeeded")
+	}
+
+	// Two captures in a row both survive; "last" is the second
+	if _, err := engine.CaptureArtifact(ctx, "a", "first\n"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := engine.CaptureArtifact(ctx, "b", "second\n"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := engine.WriteArtifact(ctx, "last", filepath.Join(dir, "last.txt"), ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := engine.WriteArtifact(ctx, "a", filepath.Join(dir, "a.txt"), ""); err != nil {
+		t.Fatal(err)
+	}
+	for file, want := range map[string]string{"last.txt": "second\n", "a.txt": "first\n"} {
+		if got, _ := os.ReadFile(filepath.Join(dir, file)); string(got) != want {
+			t.Errorf("%s = %q, want %q", file, got, want)
+		}
+	}
+
+	// A capture without a name goes to "default" and becomes last
+	res, err := engine.CaptureArtifact(ctx, "", "unnamed")
+	if err != nil || res.Artifact.Name != "default" || !res.Artifact.Last {
+		t.Errorf("unnamed capture = %+v, %v", res, err)
+	}
+	if _, err := engine.CaptureArtifact(ctx, "last", "x"); err == nil {
+		t.Error("capture into \"last\" accepted")
+	}
+	res, err = engine.CaptureArtifact(ctx, "a", "first again\n")
+	if err != nil || !res.Replaced {
+		t.Errorf("replace = %+v, %v", res, err)
+	}
+
+	list := engine.ListArtifacts()
+	if len(list.Artifacts) != 3 || list.Artifacts[0].Name != "a" || list.TotalBytes != int64(len("first again\nsecond\nunnamed")) {
+		t.Errorf("list = %+v", list)
+	}
+	if text := FormatArtifactList(list, false); !strings.Contains(text, "← last") {
+		t.Errorf("list text:\n%s", text)
+	}
+
+	// Deleting the last slot hands "last" to the next most recent capture
+	if name, err := engine.DeleteArtifact("last"); err != nil || name != "a" {
+		t.Errorf("delete last = %s, %v", name, err)
+	}
+	if a, err := engine.getArtifact("last"); err != nil || a.name != "default" {
+		t.Errorf("last after delete = %+v, %v", a, err)
+	}
+	if _, err := engine.DeleteArtifact("missing"); err == nil {
+		t.Error("missing artifact deleted")
+	}
+}
+
+func TestArtifactSlots_LRUEviction(t *testing.T) {
+	engine, _ := setupProgressEngine(t)
+	ctx := context.Background()
+	engine.config.MaxArtifactBytes = 10
+
+	engine.CaptureArtifact(ctx, "a", "aaaa")
+	engine.CaptureArtifact(ctx, "b", "bbbb")
+	engine.getArtifact("a") // a is now more recently used than b
+	res, err := engine.CaptureArtifact(ctx, "c", "cccc")
+	if err != nil || len(res.Evicted) != 1 || res.Evicted[0] != "b" {
+		t.Fatalf("capture = %+v, %v", res, err)
+	}
+	if list := engine.ListArtifacts(); len(list.Artifacts) != 2 || list.TotalBytes != 8 {
+		t.Errorf("after eviction = %+v", list)
+	}
+	if _, err := engine.CaptureArtifact(ctx, "big", strings.Repeat("x", 11)); err == nil {
+		t.Error("artifact over the whole limit accepted")
+	}
+}
+
+func TestArtifactSlots_PersistAcrossRestart(t *testing.T) {
+	dir, backupDir := t.TempDir(), t.TempDir()
+	newEngine := func(maxAge time.Duration) *UltraFastEngine {
+		c, err := cache.NewIntelligentCache(1024 * 1024)
+		if err != nil {
+			t.Fatal(err)
+		}
+		engine, err := NewUltraFastEngine(&Config{
+			Cache: c, AllowedPaths: []string{dir}, ParallelOps: 2,
+			BackupDir: backupDir, PersistArtifacts: true, ArtifactMaxAge: maxAge,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { engine.Close(); c.Close() })
+		return engine
+	}
+	ctx := context.Background()
+
+	first := newEngine(0)
+	first.CaptureArtifact(ctx, "a", "alpha\n")
+	res, err := first.CaptureArtifact(ctx, "b", "beta\n")
+	if err != nil || res.Warning != "" {
+		t.Fatalf("capture = %+v, %v", res, err)
+	}
+	first.DeleteArtifact("a")
+
+	// A restarted server finds b, and b is still "last"
+	second := newEngine(0)
+	list := second.ListArtifacts()
+	if len(list.Artifacts) != 1 || list.Artifacts[0].Name != "b" || !list.Artifacts[0].Last {
+		t.Fatalf("after restart = %+v", list)
+	}
+	if _, err := second.WriteArtifact(ctx, "", filepath.Join(dir, "b.txt"), ""); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, "b.txt")); string(got) != "beta\n" {
+		t.Errorf("written = %q", got)
+	}
+	files, _ := filepath.Glob(filepath.Join(backupDir, artifactDirName, "*.artifact"))
+	if len(files) != 1 {
+		t.Errorf("slot files = %v, want only b's", files)
+	}
+
+	// Expired slots are dropped on load, files included
+	time.Sleep(5 * time.Millisecond)
+	third := newEngine(time.Millisecond)
+	if list := third.ListArtifacts(); len(list.Artifacts) != 0 {
+		t.Errorf("expired slots kept: %+v", list)
+	}
+	if files, _ := filepath.Glob(filepath.Join(backupDir, artifactDirName, "*.artifact")); len(files) != 0 {
+		t.Errorf("expired slot files kept: %v", files)
+	}
+
+	// A persistence failure warns but keeps the capture
+	third.artifactDir = filepath.Join(dir, "b.txt", "not-a-dir")
+	res, err = third.CaptureArtifact(ctx, "c", "gamma")
+	if err != nil || res.Warning == "" {
+		t.Errorf("failed persist = %+v, %v", res, err)
+	}
+	if a, err := third.getArtifact("c"); err != nil || a.content != "gamma" {
+		t.Errorf("capture lost: %+v, %v", a, err)
+	}
+}
+
+func TestArtifactSlots_Chunked(t *testing.T) {
+	dir, backupDir := t.TempDir(), t.TempDir()
+	newEngine := func() *UltraFastEngine {
+		c, err := cache.NewIntelligentCache(1024 * 1024)
+		if err != nil {
+			t.Fatal(err)
+		}
+		engine, err := NewUltraFastEngine(&Config{
+			Cache: c, AllowedPaths: []string{dir}, ParallelOps: 2,
+			BackupDir: backupDir, PersistArtifacts: true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { engine.Close(); c.Close() })
+		return engine
+	}
+	ctx := context.Background()
+	out := filepath.Join(dir, "big.txt")
+
+	first := newEngine()
+	if _, err := first.CaptureArtifactChunk(ctx, "big", "one\n", 1, 3, false); err == nil {
+		t.Error("chunk 1 without chunk 0 accepted")
+	}
+	if _, err := first.CaptureArtifactChunk(ctx, "big", "one\n", 0, 3, true); err == nil {
+		t.Error("final before index total-1 accepted")
+	}
+	res, err := first.CaptureArtifactChunk(ctx, "big", "one\n", 0, 3, false)
+	if err != nil || !res.Artifact.Pending || res.Artifact.Chunks != 1 {
+		t.Fatalf("chunk 0 = %+v, %v", res, err)
+	}
+	if _, err := first.CaptureArtifactChunk(ctx, "big", "three\n", 2, 3, true); err == nil || !strings.Contains(err.Error(), "send index 1") {
+		t.Errorf("skipped chunk error = %v", err)
+	}
+	if _, err := first.WriteArtifact(ctx, "big", out, ""); err == nil || !strings.Contains(err.Error(), "1 chunks, 4 bytes") {
+		t.Errorf("write of an incomplete artifact = %v", err)
+	}
+
+	// A restart mid-capture resumes at the next index
+	second := newEngine()
+	if _, err := second.CaptureArtifactChunk(ctx, "big", "two\n", 1, 3, false); err != nil {
+		t.Fatal(err)
+	}
+	res, err = second.CaptureArtifactChunk(ctx, "big", "three\n", 2, 3, true)
+	if err != nil || res.Artifact.Pending || res.Artifact.Chunks != 3 {
+		t.Fatalf("final chunk = %+v, %v", res, err)
+	}
+	if _, err := second.WriteArtifact(ctx, "", out, ""); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(out); string(got) != "one\ntwo\nthree\n" {
+		t.Errorf("written = %q", got)
+	}
+	if _, err := second.CaptureArtifactChunk(ctx, "big", "more", 3, 0, false); err == nil {
+		t.Error("chunk after final accepted")
+	}
+}
+
+func TestArtifactDiff(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	ctx := context.Background()
+	target := filepath.Join(dir, "notes.txt")
+	os.WriteFile(target, []byte("one\ntwo\nthree\n"), 0644)
+	engine.CaptureArtifact(ctx, "notes", "one\n2\nthree\nfour\n")
+
+	res, err := engine.DiffArtifact(ctx, "notes", target, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Exists || res.Identical || res.Added != 2 || res.Removed != 1 || res.ContentHash == "" {
+		t.Errorf("diff = %+v", res)
+	}
+	if !strings.Contains(res.Diff, "-two") || !strings.Contains(res.Diff, "+four") {
+		t.Errorf("unified diff = %q", res.Diff)
+	}
+	missing, err := engine.DiffArtifact(ctx, "", filepath.Join(dir, "new.txt"), 3)
+	if err != nil || missing.Exists || missing.Added != 4 {
+		t.Errorf("diff against a missing file = %+v, %v", missing, err)
+	}
+
+	// The compared file changing blocks a guarded write
+	os.WriteFile(target, []byte("one\ntwo\nthree\nedited\n"), 0644)
+	if _, err := engine.WriteArtifact(ctx, "notes", target, res.ContentHash); err == nil || !strings.Contains(err.Error(), "stale write") {
+		t.Errorf("stale write = %v", err)
+	}
+	again, _ := engine.DiffArtifact(ctx, "notes", target, 3)
+	if _, err := engine.WriteArtifact(ctx, "notes", target, again.ContentHash); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(target); string(got) != "one\n2\nthree\nfour\n" {
+		t.Errorf("written = %q", got)
+	}
+}
+
+func TestApplyArtifact(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	ctx := context.Background()
+	target := filepath.Join(dir, "list.txt")
+	os.WriteFile(target, []byte("one\ntwo\nthree\n"), 0644)
+
+	engine.CaptureArtifact(ctx, "fix", "@@ -1,3 +1,3 @@\n one\n-two\n+2\n three\n")
+	res, err := engine.ApplyArtifact(ctx, "fix", target, ArtifactPatch, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Added != 1 || res.Removed != 1 || res.BackupID == "" {
+		t.Errorf("patch = %+v", res)
+	}
+	if got, _ := os.ReadFile(target); string(got) != "one\n2\nthree\n" {
+		t.Errorf("patched = %q", got)
+	}
+	if _, err := engine.ApplyArtifact(ctx, "fix", target, ArtifactPatch, ""); err == nil {
+		t.Error("patch applied twice")
+	}
+
+	engine.CaptureArtifact(ctx, "tail", "four\n")
+	if res, err = engine.ApplyArtifact(ctx, "tail", target, ArtifactAppend, ""); err != nil || res.Added != 1 || res.Removed != 0 {
+		t.Fatalf("append = %+v, %v", res, err)
+	}
+	engine.CaptureArtifact(ctx, "whole", "only\n")
+	if res, err = engine.ApplyArtifact(ctx, "whole", target, "", res.ContentHash); err != nil || res.Added != 1 || res.Removed != 4 {
+		t.Fatalf("overwrite = %+v, %v", res, err)
+	}
+	if got, _ := os.ReadFile(target); string(got) != "only\n" {
+		t.Errorf("overwritten = %q", got)
+	}
+
+	created, err := engine.ApplyArtifact(ctx, "whole", filepath.Join(dir, "new.txt"), ArtifactAppend, "")
+	if err != nil || !created.Created || created.BackupID != "" {
+		t.Errorf("append to a new file = %+v, %v", created, err)
+	}
+	if _, err := engine.ApplyArtifact(ctx, "fix", filepath.Join(dir, "missing.txt"), ArtifactPatch, ""); err == nil {
+		t.Error("patch of a missing file succeeded")
+	}
+}
+
+func TestApplyArtifact_UsesWritePath(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	ctx := context.Background()
+	engine.hookManager.SetSecretGuard(NewSecretGuard(nil))
+	target := filepath.Join(dir, "app.env")
+	os.WriteFile(target, []byte("MODE=dev\r\n"), 0644)
+
+	engine.CaptureArtifact(ctx, "leak", "AWS_KEY="+fakeAWSKey+"\n")
+	if _, err := engine.ApplyArtifact(ctx, "leak", target, ArtifactAppend, ""); err == nil || !strings.Contains(err.Error(), "aws-access-key-id") {
+		t.Fatalf("secret applied: %v", err)
+	}
+	if got, _ := os.ReadFile(target); string(got) != "MODE=dev\r\n" {
+		t.Errorf("denied apply changed the file: %q", got)
+	}
+
+	// The write keeps the file's CRLF line endings and hashes what landed on disk
+	engine.CaptureArtifact(ctx, "mode", "DEBUG=1\n")
+	res, err := engine.ApplyArtifact(ctx, "mode", target, ArtifactAppend, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(target)
+	if string(got) != "MODE=dev\r\nDEBUG=1\r\n" || res.ContentHash != contentHashFNV(string(got)) || res.BytesAfter != len(got) {
+		t.Errorf("applied %q, result %+v", got, res)
+	}
+}
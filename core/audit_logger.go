@@ -29,6 +29,14 @@ func SetFeedback(ctx context.Context, signal *FeedbackSignal) {
 	}
 }
 
+// AuditTool is the tool whose call ctx belongs to, or "" outside a tool call
+func AuditTool(ctx context.Context) string {
+	if entry, ok := ctx.Value(AuditEntryKey{}).(*AuditEntry); ok {
+		return entry.Tool
+	}
+	return ""
+}
+
 // SetDiffLines annotates the audit entry with the number of diff lines generated.
 func SetDiffLines(ctx context.Context, n int) {
 	if entry, ok := ctx.Value(AuditEntryKey{}).(*AuditEntry); ok {
@@ -78,6 +86,7 @@ func SetBackupID(ctx context.Context, backupID, previousBackupID string) {
 		entry.BackupID = backupID
 		entry.PreviousBackupID = previousBackupID
 	}
+	markInFlightBackup(ctx, backupID)
 }
 
 // SetSoftDeleteID annotates the audit entry with the soft-delete ID created by
@@ -92,6 +101,14 @@ func SetSoftDeleteID(ctx context.Context, sdID string) {
 	}
 }
 
+// SetConfirmToken records the confirmation token that authorized a
+// HIGH/CRITICAL operation (--confirm-tokens mode).
+func SetConfirmToken(ctx context.Context, token string) {
+	if entry, ok := ctx.Value(AuditEntryKey{}).(*AuditEntry); ok {
+		entry.ConfirmToken = token
+	}
+}
+
 // SetIntegrityStatus annotates the audit entry with file integrity verification result.
 func SetIntegrityStatus(ctx context.Context, status, warning string) {
 	if entry, ok := ctx.Value(AuditEntryKey{}).(*AuditEntry); ok {
@@ -142,7 +159,7 @@ type AuditEntry struct {
 	DurationMs     int64                  `json:"duration_ms"`
 	BytesIn        int64                  `json:"bytes_in,omitempty"`
 	BytesOut       int64                  `json:"bytes_out,omitempty"` // file bytes written/read — excludes diff text
-	Status         string                 `json:"status"`              // "ok", "warn", or "error"
+	Status         string                 `json:"status"`              // "ok", "warn", "error" or "interrupted" (shutdown)
 	Error          string                 `json:"error,omitempty"`
 	RiskLevel      string                 `json:"risk,omitempty"`
 	FileSize       int64                  `json:"file_size,omitempty"`
@@ -175,6 +192,16 @@ type AuditEntry struct {
 	// File integrity verification (for HIGH/CRITICAL edits)
 	IntegrityStatus string `json:"integrity_status,omitempty"` // "OK", "WARNING", "ERROR"
 	IntegrityWarn   string `json:"integrity_warn,omitempty"`   // warning message if verification had issues
+
+	// Two-step confirmation token that authorized this operation (--confirm-tokens)
+	ConfirmToken string `json:"confirm_token,omitempty"`
+
+	// Hook outcomes worth telling the caller: "gofmt modified content
+	// (+3/-1 lines)", "blocked by hook 'no-secrets'"
+	Hooks []string `json:"hooks,omitempty"`
+
+	// Mutating is set when the call changed files or server state (MarkMutating)
+	Mutating bool `json:"mutating,omitempty"`
 }
 
 // MetricsSnapshot is the periodic metrics dump written to metrics.json
@@ -182,6 +209,7 @@ type MetricsSnapshot struct {
 	UpdatedAt    time.Time          `json:"updated_at"`
 	OpsTotal     int64              `json:"ops_total"`
 	OpsPerSec    float64            `json:"ops_per_sec"`
+	OpsRate1m    float64            `json:"ops_rate_1m"`
 	CacheHitRate float64            `json:"cache_hit_rate"`
 	MemoryMB     float64            `json:"memory_mb"`
 	Reads        int64              `json:"reads"`
@@ -7,19 +7,33 @@
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// Delete modes for sync_on_delete
+const (
+	AutoSyncDeleteRemove = "remove" // delete the Windows copy
+	AutoSyncDeleteTrash  = "trash"  // move it into TrashDir/<timestamp>/
+)
+
+// defaultAutoSyncTrashDir is the trash folder name under the Windows home
+const defaultAutoSyncTrashDir = ".mcp-sync-trash"
+
 // AutoSyncConfig holds the configuration for automatic WSL<->Windows syncing
 type AutoSyncConfig struct {
-	Enabled         bool              `json:"enabled"`
-	SyncOnWrite     bool              `json:"sync_on_write"`
-	SyncOnEdit      bool              `json:"sync_on_edit"`
-	SyncOnDelete    bool              `json:"sync_on_delete"`
-	TargetMapping   map[string]string `json:"target_mapping,omitempty"`   // Custom path mappings
-	ExcludePatterns []string          `json:"exclude_patterns,omitempty"` // Patterns to exclude from auto-sync
-	Silent          bool              `json:"silent"`                     // If true, don't log sync operations
-	OnlySubdirs     []string          `json:"only_subdirs,omitempty"`     // Only sync files under these subdirectories
-	ConfigVersion   string            `json:"config_version"`             // Config file version
+	Enabled         bool                  `json:"enabled"`
+	SyncOnWrite     bool                  `json:"sync_on_write"`
+	SyncOnEdit      bool                  `json:"sync_on_edit"`
+	SyncOnDelete    bool                  `json:"sync_on_delete"`
+	DeleteMode      string                `json:"delete_mode,omitempty"`      // "remove" (default) or "trash"
+	TrashDir        string                `json:"trash_dir,omitempty"`        // Trash folder for delete_mode "trash" (default: <Windows home>/.mcp-sync-trash)
+	TargetMapping   map[string]string     `json:"target_mapping,omitempty"`   // Custom path mappings
+	RootMappings    []AutoSyncRootMapping `json:"root_mappings,omitempty"`    // When set, only files under an enabled mapping are synced
+	ExcludePatterns []string              `json:"exclude_patterns,omitempty"` // Extra patterns to exclude from auto-sync ("*.log", "dist/**"), on top of the defaults and .gitignore
+	Silent          bool                  `json:"silent"`                     // If true, don't log sync operations
+	OnlySubdirs     []string              `json:"only_subdirs,omitempty"`     // Only sync files under these subdirectories
+	ConfigVersion   string                `json:"config_version"`             // Config file version
 }
 
 // DefaultAutoSyncConfig returns the default auto-sync configuration
@@ -46,13 +60,24 @@ type AutoSyncManager struct {
 	enabled      bool
 	configPath   string
 	allowedPaths []string // Copied from engine for safety checks during sync
+
+	gitignores *gitignoreCache // parsed .gitignore files consulted by ShouldSyncPath
+
+	mappingCounts sync.Map // WSL root -> *atomic.Int64 files synced through that root mapping
+
+	queue  autoSyncQueue           // debounced copies from AfterWrite/AfterEdit
+	submit func(task func()) error // engine worker pool; nil runs copies on goroutines
+
+	deletesSynced atomic.Int64 // Windows copies removed or trashed by AfterDelete
+	deleteErrors  atomic.Int64
 }
 
 // NewAutoSyncManager creates a new AutoSyncManager
 func NewAutoSyncManager() *AutoSyncManager {
 	manager := &AutoSyncManager{
-		config:  DefaultAutoSyncConfig(),
-		enabled: false,
+		config:     DefaultAutoSyncConfig(),
+		enabled:    false,
+		gitignores: newGitignoreCache(),
 	}
 
 	// Detect environment
@@ -258,6 +283,11 @@ func (m *AutoSyncManager) ShouldSyncPath(path string) bool {
 	m.configMu.RLock()
 	defer m.configMu.RUnlock()
 
+	// With root mappings configured, only their trees are mirrored
+	if mapping, restricted := m.rootMappingFor(path); restricted && mapping == nil {
+		return false
+	}
+
 	// Check if path matches only_subdirs filter
 	if len(m.config.OnlySubdirs) > 0 {
 		matched := false
@@ -272,14 +302,10 @@ func (m *AutoSyncManager) ShouldSyncPath(path string) bool {
 		}
 	}
 
-	// Check exclude patterns
-	for _, pattern := range m.config.ExcludePatterns {
-		if matched, _ := filepath.Match(pattern, filepath.Base(path)); matched {
-			return false
-		}
-	}
-
-	return true
+	// Same exclusion rules as workspace sync: defaults, exclude_patterns
+	// and the .gitignore files of the enclosing repository
+	excludes := newSyncExcludes(m.config.ExcludePatterns, m.gitignores)
+	return !excludes.excluded(findRepoRoot(path), path, false)
 }
 
 // AfterWrite is called after a write operation to potentially auto-sync
@@ -308,9 +334,18 @@ func (m *AutoSyncManager) AfterEdit(path string) error {
 	return m.syncToWindows(path)
 }
 
-// AfterDelete is called after a delete operation to potentially auto-sync
+// AfterDelete is called after a file or directory is deleted in WSL (delete,
+// soft delete, or the source of a move) and removes the Windows copy, or
+// moves it into the trash folder when delete_mode is "trash", so the Windows
+// side does not keep ghost files. Failures are logged, never returned.
 func (m *AutoSyncManager) AfterDelete(path string) error {
-	if !m.IsEnabled() || !m.config.SyncOnDelete {
+	m.queue.drop(path)
+
+	m.configMu.RLock()
+	syncOnDelete, silent := m.config.SyncOnDelete, m.config.Silent
+	mode, trashDir := m.config.DeleteMode, m.config.TrashDir
+	m.configMu.RUnlock()
+	if !m.IsEnabled() || !syncOnDelete {
 		return nil
 	}
 
@@ -318,63 +353,94 @@ func (m *AutoSyncManager) AfterDelete(path string) error {
 		return nil
 	}
 
-	// Convert to Windows path and delete
-	winPath, err := WSLToWindows(path)
+	winPath, err := m.windowsTarget(path)
 	if err != nil {
 		return nil // Silent fail
 	}
 
 	if !m.isTargetAllowed(winPath) {
-		if !m.config.Silent {
+		if !silent {
 			fmt.Fprintf(os.Stderr, "[AutoSync] Blocked delete sync to path outside allowed directories: %s\n", winPath)
 		}
 		return nil
 	}
 
-	// Delete on Windows side (ignore errors)
-	os.Remove(winPath)
-	return nil
-}
+	// The Windows path is C:\... style; operate on its /mnt/c/... form
+	target := NormalizePath(winPath)
+	if filepath.Dir(target) == target {
+		return nil // never a drive root
+	}
+	if _, err := os.Lstat(target); err != nil {
+		return nil // nothing to remove on the Windows side
+	}
 
-// syncToWindows performs the actual sync operation
-func (m *AutoSyncManager) syncToWindows(wslPath string) error {
-	// Convert to Windows path
-	winPath, err := WSLToWindows(wslPath)
+	action := "Deleted"
+	if mode == AutoSyncDeleteTrash {
+		action = "Trashed"
+		err = moveToSyncTrash(target, trashDir)
+	} else {
+		err = os.RemoveAll(target)
+	}
 	if err != nil {
-		if !m.config.Silent {
-			fmt.Fprintf(os.Stderr, "[AutoSync] Failed to convert path %s: %v\n", wslPath, err)
+		m.deleteErrors.Add(1)
+		if !silent {
+			fmt.Fprintf(os.Stderr, "[AutoSync] Failed to sync delete of %s -> %s: %v\n", path, winPath, err)
 		}
-		return nil // Don't fail the operation
+		return nil
 	}
-
-	// Check for custom mapping
-	m.configMu.RLock()
-	if customTarget, exists := m.config.TargetMapping[wslPath]; exists {
-		winPath = customTarget
+	m.deletesSynced.Add(1)
+	if !silent {
+		fmt.Fprintf(os.Stderr, "[AutoSync] %s: %s\n", action, winPath)
 	}
-	m.configMu.RUnlock()
+	return nil
+}
 
-	if !m.isTargetAllowed(winPath) {
-		if !m.config.Silent {
-			fmt.Fprintf(os.Stderr, "[AutoSync] Blocked sync to path outside allowed directories: %s\n", winPath)
+// moveToSyncTrash moves target into trashDir/<timestamp>/, trashDir
+// defaulting to the Windows home's .mcp-sync-trash folder
+func moveToSyncTrash(target, trashDir string) error {
+	if trashDir == "" {
+		wslHome, _ := GetWindowsHome()
+		if wslHome == "" {
+			return fmt.Errorf("no trash_dir configured and the Windows home is unknown")
 		}
-		return nil
+		trashDir = filepath.Join(wslHome, defaultAutoSyncTrashDir)
 	}
-
-	// Perform copy asynchronously to not block the main operation
-	go func() {
-		if err := CopyFileWithConversion(wslPath, winPath, true); err != nil {
-			if !m.config.Silent {
-				fmt.Fprintf(os.Stderr, "[AutoSync] Failed to sync %s -> %s: %v\n", wslPath, winPath, err)
-			}
-		} else {
-			if !m.config.Silent {
-				fmt.Fprintf(os.Stderr, "[AutoSync] Synced: %s -> %s\n", wslPath, winPath)
-			}
+	dir := filepath.Join(NormalizePath(trashDir), time.Now().Format("20060102-150405"))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	dest := filepath.Join(dir, filepath.Base(target))
+	for i := 1; ; i++ {
+		if _, err := os.Lstat(dest); os.IsNotExist(err) {
+			break
 		}
-	}()
+		dest = filepath.Join(dir, fmt.Sprintf("%s.%d", filepath.Base(target), i))
+	}
+	return os.Rename(target, dest)
+}
 
-	return nil
+// windowsTarget returns the Windows path a WSL path syncs to: its custom
+// mapping, else its root mapping, else the WSLToWindows conversion
+func (m *AutoSyncManager) windowsTarget(wslPath string) (string, error) {
+	m.configMu.RLock()
+	customTarget, exists := m.config.TargetMapping[wslPath]
+	mapping, _ := m.rootMappingFor(wslPath)
+	m.configMu.RUnlock()
+	if exists {
+		return customTarget, nil
+	}
+	if mapping != nil {
+		return mapping.target(wslPath), nil
+	}
+	return WSLToWindows(wslPath)
+}
+
+// deleteMode returns the configured delete mode (caller holds configMu)
+func (m *AutoSyncManager) deleteMode() string {
+	if m.config.DeleteMode == AutoSyncDeleteTrash {
+		return AutoSyncDeleteTrash
+	}
+	return AutoSyncDeleteRemove
 }
 
 // GetStatus returns the current auto-sync status
@@ -382,17 +448,23 @@ func (m *AutoSyncManager) GetStatus() map[string]interface{} {
 	m.configMu.RLock()
 	defer m.configMu.RUnlock()
 
-	return map[string]interface{}{
+	status := map[string]interface{}{
 		"enabled":          m.enabled,
 		"is_wsl":           m.isWSL,
 		"windows_user":     m.winUser,
 		"sync_on_write":    m.config.SyncOnWrite,
 		"sync_on_edit":     m.config.SyncOnEdit,
 		"sync_on_delete":   m.config.SyncOnDelete,
+		"delete_mode":      m.deleteMode(),
+		"deletes_synced":   m.deletesSynced.Load(),
+		"delete_errors":    m.deleteErrors.Load(),
 		"config_path":      m.getConfigPath(),
 		"exclude_patterns": m.config.ExcludePatterns,
 		"only_subdirs":     m.config.OnlySubdirs,
 		"custom_mappings":  m.config.TargetMapping,
+		"root_mappings":    m.rootMappingStatus(),
 		"config_version":   m.config.ConfigVersion,
 	}
+	m.queueStatus(status)
+	return status
 }
@@ -0,0 +1,100 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// mirrorEngine enables auto-sync with sync_on_delete on the engine and maps
+// each name under dir to a copy under a separate "Windows" directory
+func mirrorEngine(t *testing.T, engine *UltraFastEngine, dir, mode string, names ...string) (string, map[string]string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("auto-sync only handles WSL (Unix) source paths")
+	}
+	winDir := t.TempDir()
+	cfg := DefaultAutoSyncConfig()
+	cfg.Enabled = true
+	cfg.SyncOnDelete = true
+	cfg.Silent = true
+	cfg.DeleteMode = mode
+	cfg.TrashDir = filepath.Join(winDir, ".trash")
+	cfg.TargetMapping = map[string]string{}
+	mirrors := map[string]string{}
+	for _, name := range names {
+		src, dst := filepath.Join(dir, name), filepath.Join(winDir, name)
+		os.WriteFile(src, []byte(name), 0644)
+		os.WriteFile(dst, []byte(name), 0644)
+		cfg.TargetMapping[src] = dst
+		mirrors[name] = dst
+	}
+	engine.autoSyncManager = &AutoSyncManager{config: cfg, enabled: true, isWSL: true}
+	return winDir, mirrors
+}
+
+func TestAutoSync_DeleteRemovesWindowsCopy(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	_, mirrors := mirrorEngine(t, engine, dir, "", "deleted.txt", "soft.txt", "moved.txt", "kept.txt")
+	ctx := context.Background()
+
+	if err := engine.DeleteFile(ctx, filepath.Join(dir, "deleted.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := engine.SoftDeleteFile(ctx, filepath.Join(dir, "soft.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.MoveFile(ctx, filepath.Join(dir, "moved.txt"), filepath.Join(dir, "sub", "moved.txt")); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"deleted.txt", "soft.txt", "moved.txt"} {
+		if _, err := os.Stat(mirrors[name]); !os.IsNotExist(err) {
+			t.Errorf("Windows copy of %s still exists (err %v)", name, err)
+		}
+	}
+	if _, err := os.Stat(mirrors["kept.txt"]); err != nil {
+		t.Errorf("untouched file's Windows copy removed: %v", err)
+	}
+
+	status := engine.autoSyncManager.GetStatus()
+	if status["deletes_synced"] != int64(3) || status["delete_mode"] != AutoSyncDeleteRemove {
+		t.Errorf("status = %v", status)
+	}
+}
+
+func TestAutoSync_DeleteModeTrash(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	winDir, mirrors := mirrorEngine(t, engine, dir, AutoSyncDeleteTrash, "a.txt")
+
+	if err := engine.DeleteFile(context.Background(), filepath.Join(dir, "a.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(mirrors["a.txt"]); !os.IsNotExist(err) {
+		t.Fatalf("Windows copy left in place (err %v)", err)
+	}
+	trashed, _ := filepath.Glob(filepath.Join(winDir, ".trash", "*", "a.txt"))
+	if len(trashed) != 1 {
+		t.Fatalf("trash contents = %v", trashed)
+	}
+	if data, _ := os.ReadFile(trashed[0]); string(data) != "a.txt" {
+		t.Errorf("trashed content = %q", data)
+	}
+}
+
+func TestAutoSync_DeleteDisabledKeepsWindowsCopy(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	_, mirrors := mirrorEngine(t, engine, dir, "", "a.txt")
+	engine.autoSyncManager.config.SyncOnDelete = false
+
+	if err := engine.DeleteFile(context.Background(), filepath.Join(dir, "a.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(mirrors["a.txt"]); err != nil {
+		t.Errorf("Windows copy removed with sync_on_delete off: %v", err)
+	}
+	if got := engine.autoSyncManager.GetStatus()["deletes_synced"]; got != int64(0) {
+		t.Errorf("deletes_synced = %v", got)
+	}
+}
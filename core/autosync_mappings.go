@@ -0,0 +1,122 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// AutoSyncRootMapping mirrors one WSL directory tree to a Windows directory.
+// Once any mapping is configured, auto-sync only handles files under an
+// enabled mapping's WSL root; everything else the server writes is ignored.
+type AutoSyncRootMapping struct {
+	WSLRoot     string `json:"wsl_root"`
+	WindowsRoot string `json:"windows_root"` // C:\... or /mnt/c/... form
+	Enabled     bool   `json:"enabled"`
+}
+
+// AutoSyncMappingStatus is a root mapping with the files auto-synced through
+// it since the server started
+type AutoSyncMappingStatus struct {
+	AutoSyncRootMapping
+	FilesSynced int64 `json:"files_synced"`
+}
+
+// within reports whether path is the mapping's WSL root or below it
+func (r AutoSyncRootMapping) within(path string) bool {
+	root := filepath.Clean(r.WSLRoot)
+	path = filepath.Clean(path)
+	return path == root || strings.HasPrefix(path, strings.TrimSuffix(root, "/")+"/")
+}
+
+// target returns the Windows path wslPath maps to (wslPath must be within r)
+func (r AutoSyncRootMapping) target(wslPath string) string {
+	rel, _ := filepath.Rel(filepath.Clean(r.WSLRoot), filepath.Clean(wslPath))
+	return filepath.Join(NormalizePath(r.WindowsRoot), rel)
+}
+
+// rootMappingFor returns the enabled mapping with the longest WSL root that
+// contains path. restricted is true when mappings are configured at all, in
+// which case a nil mapping means the path is not auto-synced. The caller
+// holds configMu.
+func (m *AutoSyncManager) rootMappingFor(path string) (mapping *AutoSyncRootMapping, restricted bool) {
+	for i := range m.config.RootMappings {
+		r := &m.config.RootMappings[i]
+		restricted = true
+		if !r.Enabled || !r.within(path) {
+			continue
+		}
+		if mapping == nil || len(r.WSLRoot) > len(mapping.WSLRoot) {
+			mapping = r
+		}
+	}
+	return mapping, restricted
+}
+
+// AddRootMapping adds a root mapping, replacing the one with the same WSL
+// root, and saves the configuration
+func (m *AutoSyncManager) AddRootMapping(mapping AutoSyncRootMapping) error {
+	if !filepath.IsAbs(mapping.WSLRoot) || !IsWSLPath(mapping.WSLRoot) {
+		return fmt.Errorf("wsl_root must be an absolute WSL path: %q", mapping.WSLRoot)
+	}
+	if mapping.WindowsRoot == "" {
+		return fmt.Errorf("windows_root is required")
+	}
+	if !m.isTargetAllowed(mapping.WindowsRoot) {
+		return fmt.Errorf("windows_root %s is outside allowed paths", mapping.WindowsRoot)
+	}
+	mapping.WSLRoot = filepath.Clean(mapping.WSLRoot)
+
+	m.configMu.Lock()
+	defer m.configMu.Unlock()
+	mappings := make([]AutoSyncRootMapping, 0, len(m.config.RootMappings)+1)
+	for _, r := range m.config.RootMappings {
+		if r.WSLRoot != mapping.WSLRoot {
+			mappings = append(mappings, r)
+		}
+	}
+	m.config.RootMappings = append(mappings, mapping)
+	return m.saveConfig()
+}
+
+// RemoveRootMapping removes the mapping for wslRoot and saves the configuration
+func (m *AutoSyncManager) RemoveRootMapping(wslRoot string) error {
+	wslRoot = filepath.Clean(wslRoot)
+
+	m.configMu.Lock()
+	defer m.configMu.Unlock()
+	mappings := make([]AutoSyncRootMapping, 0, len(m.config.RootMappings))
+	for _, r := range m.config.RootMappings {
+		if r.WSLRoot != wslRoot {
+			mappings = append(mappings, r)
+		}
+	}
+	if len(mappings) == len(m.config.RootMappings) {
+		return fmt.Errorf("no auto-sync mapping for %s", wslRoot)
+	}
+	m.config.RootMappings = mappings
+	return m.saveConfig()
+}
+
+// countMappingSync records a file synced through the mapping for wslRoot
+func (m *AutoSyncManager) countMappingSync(wslRoot string) {
+	counter, _ := m.mappingCounts.LoadOrStore(wslRoot, new(atomic.Int64))
+	counter.(*atomic.Int64).Add(1)
+}
+
+// rootMappingStatus lists the configured mappings by WSL root with their
+// file counts (caller holds configMu)
+func (m *AutoSyncManager) rootMappingStatus() []AutoSyncMappingStatus {
+	out := make([]AutoSyncMappingStatus, 0, len(m.config.RootMappings))
+	for _, r := range m.config.RootMappings {
+		s := AutoSyncMappingStatus{AutoSyncRootMapping: r}
+		if counter, ok := m.mappingCounts.Load(r.WSLRoot); ok {
+			s.FilesSynced = counter.(*atomic.Int64).Load()
+		}
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].WSLRoot < out[j].WSLRoot })
+	return out
+}
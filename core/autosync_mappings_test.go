@@ -0,0 +1,94 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// waitForFile polls for the asynchronous auto-sync copy
+func waitForFile(path string) bool {
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func TestAutoSync_RootMappingsLimitAndRouteWrites(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("auto-sync only handles WSL (Unix) source paths")
+	}
+	engine, dir := setupProgressEngine(t)
+	cfg := DefaultAutoSyncConfig()
+	cfg.Enabled = true
+	cfg.Silent = true
+	configPath := filepath.Join(t.TempDir(), "autosync.json")
+	engine.autoSyncManager = &AutoSyncManager{config: cfg, enabled: true, isWSL: true, configPath: configPath}
+
+	workspace, other := filepath.Join(dir, "workspace"), filepath.Join(dir, "other")
+	mirror, disabledMirror := t.TempDir(), t.TempDir()
+	os.MkdirAll(filepath.Join(workspace, "off"), 0755)
+	os.MkdirAll(other, 0755)
+	if err := engine.AddAutoSyncMapping(AutoSyncRootMapping{WSLRoot: workspace + "/", WindowsRoot: mirror, Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.AddAutoSyncMapping(AutoSyncRootMapping{WSLRoot: filepath.Join(workspace, "off"), WindowsRoot: disabledMirror}); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.AddAutoSyncMapping(AutoSyncRootMapping{WSLRoot: "relative/dir", WindowsRoot: mirror}); err == nil {
+		t.Error("relative wsl_root accepted")
+	}
+
+	ctx := context.Background()
+	engine.WriteFileContent(ctx, filepath.Join(workspace, "sub", "a.txt"), "mapped")
+	engine.WriteFileContent(ctx, filepath.Join(other, "b.txt"), "unmapped")
+	engine.WriteFileContent(ctx, filepath.Join(workspace, "off", "c.txt"), "disabled mapping")
+
+	if !waitForFile(filepath.Join(mirror, "sub", "a.txt")) {
+		t.Fatal("file under the mapping was not synced to its Windows root")
+	}
+	// The disabled, more specific mapping does not route c.txt; the enclosing
+	// enabled mapping does
+	if !waitForFile(filepath.Join(mirror, "off", "c.txt")) {
+		t.Error("file under a disabled nested mapping was not synced by its parent mapping")
+	}
+	if _, err := os.Stat(filepath.Join(disabledMirror, "c.txt")); err == nil {
+		t.Error("disabled mapping was used")
+	}
+	if engine.autoSyncManager.ShouldSyncPath(filepath.Join(other, "b.txt")) {
+		t.Error("path outside every mapping is auto-synced")
+	}
+
+	// Counted after the copy completes
+	var status []AutoSyncMappingStatus
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		status = engine.GetAutoSyncStatus()["root_mappings"].([]AutoSyncMappingStatus)
+		if len(status) == 2 && status[0].FilesSynced == 2 {
+			break
+		}
+	}
+	if len(status) != 2 || status[0].WSLRoot != workspace || status[0].FilesSynced != 2 {
+		t.Errorf("mapping status = %+v", status)
+	}
+
+	// Saved to the config file, and removable
+	saved, err := os.ReadFile(configPath)
+	if err != nil || !strings.Contains(string(saved), `"root_mappings"`) {
+		t.Errorf("config not saved (%v):\n%s", err, saved)
+	}
+	if err := engine.RemoveAutoSyncMapping(workspace); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.RemoveAutoSyncMapping(workspace); err == nil {
+		t.Error("removing a missing mapping succeeded")
+	}
+	if engine.autoSyncManager.ShouldSyncPath(filepath.Join(workspace, "sub", "a.txt")) {
+		t.Error("only the disabled mapping is left, yet the workspace still syncs")
+	}
+}
@@ -0,0 +1,216 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Auto-sync batching: copies are queued and flushed after autoSyncDebounce
+// without new writes, or as soon as autoSyncBatchSize paths are pending, so a
+// multi-file edit crosses the 9p boundary once, in parallel
+const (
+	autoSyncDebounce    = 500 * time.Millisecond
+	autoSyncBatchSize   = 50
+	autoSyncCopyWorkers = 8
+)
+
+// autoSyncQueue holds WSL paths waiting to be copied to Windows. The zero
+// value is ready to use.
+type autoSyncQueue struct {
+	mu       sync.Mutex
+	pending  map[string]bool
+	timer    *time.Timer
+	inflight sync.WaitGroup // batches being copied
+
+	deduped   atomic.Int64 // writes folded into an already pending copy
+	copied    atomic.Int64
+	failed    atomic.Int64
+	lastFlush atomic.Pointer[autoSyncFlush]
+}
+
+// autoSyncFlush describes the most recent batch
+type autoSyncFlush struct {
+	At       time.Time
+	Files    int
+	Failed   int
+	Duration time.Duration
+}
+
+// enqueue queues wslPath for copying. It returns a full batch to flush now,
+// or nil when the debounce timer will pick the path up.
+func (q *autoSyncQueue) enqueue(wslPath string, flush func()) []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.pending == nil {
+		q.pending = make(map[string]bool)
+	}
+	if q.pending[wslPath] {
+		q.deduped.Add(1)
+	}
+	q.pending[wslPath] = true
+	if len(q.pending) >= autoSyncBatchSize {
+		return q.takeLocked()
+	}
+	if q.timer == nil {
+		q.timer = time.AfterFunc(autoSyncDebounce, flush)
+	} else {
+		q.timer.Reset(autoSyncDebounce)
+	}
+	return nil
+}
+
+// take removes and returns every pending path, sorted
+func (q *autoSyncQueue) take() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.takeLocked()
+}
+
+func (q *autoSyncQueue) takeLocked() []string {
+	if q.timer != nil {
+		q.timer.Stop()
+	}
+	paths := make([]string, 0, len(q.pending))
+	for p := range q.pending {
+		paths = append(paths, p)
+	}
+	q.pending = nil
+	sort.Strings(paths)
+	if len(paths) > 0 {
+		q.inflight.Add(1) // released by the flush that copies them
+	}
+	return paths
+}
+
+// drop forgets pending copies of path and anything below it (the source
+// was deleted or moved away)
+func (q *autoSyncQueue) drop(path string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	prefix := strings.TrimSuffix(path, "/") + "/"
+	for p := range q.pending {
+		if p == path || strings.HasPrefix(p, prefix) {
+			delete(q.pending, p)
+		}
+	}
+}
+
+func (q *autoSyncQueue) depth() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending)
+}
+
+// syncToWindows queues wslPath for the next batch copy
+func (m *AutoSyncManager) syncToWindows(wslPath string) error {
+	if batch := m.queue.enqueue(wslPath, m.flushPending); batch != nil {
+		go m.copyBatch(batch)
+	}
+	return nil
+}
+
+// flushPending copies everything queued (debounce timer callback)
+func (m *AutoSyncManager) flushPending() {
+	if batch := m.queue.take(); len(batch) > 0 {
+		m.copyBatch(batch)
+	}
+}
+
+// Flush copies all pending paths and waits for batches already being
+// copied. The engine calls it on Close so no write is left unsynced.
+func (m *AutoSyncManager) Flush() {
+	m.flushPending()
+	m.queue.inflight.Wait()
+}
+
+// copyBatch copies paths concurrently, on the engine's worker pool when set
+func (m *AutoSyncManager) copyBatch(paths []string) {
+	defer m.queue.inflight.Done()
+	start := time.Now()
+	var failed atomic.Int64
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, autoSyncCopyWorkers)
+	for _, p := range paths {
+		sem <- struct{}{}
+		wg.Add(1)
+		task := func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if !m.copyToWindows(p) {
+				failed.Add(1)
+			}
+		}
+		if m.submit == nil || m.submit(task) != nil {
+			go task()
+		}
+	}
+	wg.Wait()
+	m.queue.lastFlush.Store(&autoSyncFlush{At: start, Files: len(paths), Failed: int(failed.Load()), Duration: time.Since(start)})
+}
+
+// copyToWindows copies one WSL file to its Windows target, reporting
+// whether it succeeded (skipped paths count as success)
+func (m *AutoSyncManager) copyToWindows(wslPath string) bool {
+	m.configMu.RLock()
+	silent := m.config.Silent
+	m.configMu.RUnlock()
+
+	if _, err := os.Stat(wslPath); os.IsNotExist(err) {
+		return true // deleted before the flush
+	}
+
+	// Convert to Windows path (custom mapping first)
+	winPath, err := m.windowsTarget(wslPath)
+	if err != nil {
+		if !silent {
+			fmt.Fprintf(os.Stderr, "[AutoSync] Failed to convert path %s: %v\n", wslPath, err)
+		}
+		return true // Don't count unconvertible paths as failures
+	}
+
+	if !m.isTargetAllowed(winPath) {
+		if !silent {
+			fmt.Fprintf(os.Stderr, "[AutoSync] Blocked sync to path outside allowed directories: %s\n", winPath)
+		}
+		return true
+	}
+
+	if err := CopyFileWithConversion(wslPath, winPath, true); err != nil {
+		m.queue.failed.Add(1)
+		if !silent {
+			fmt.Fprintf(os.Stderr, "[AutoSync] Failed to sync %s -> %s: %v\n", wslPath, winPath, err)
+		}
+		return false
+	}
+	m.queue.copied.Add(1)
+
+	m.configMu.RLock()
+	mapping, _ := m.rootMappingFor(wslPath)
+	m.configMu.RUnlock()
+	if mapping != nil {
+		m.countMappingSync(mapping.WSLRoot)
+	}
+	if !silent {
+		fmt.Fprintf(os.Stderr, "[AutoSync] Synced: %s -> %s\n", wslPath, winPath)
+	}
+	return true
+}
+
+// queueStatus adds the queue's counters to an autosync status map
+func (m *AutoSyncManager) queueStatus(status map[string]interface{}) {
+	status["pending_count"] = m.queue.depth()
+	status["files_synced"] = m.queue.copied.Load()
+	status["sync_errors"] = m.queue.failed.Load()
+	status["deduped_writes"] = m.queue.deduped.Load()
+	if last := m.queue.lastFlush.Load(); last != nil {
+		status["last_flush_at"] = last.At
+		status["last_flush_files"] = last.Files
+		status["last_flush_failed"] = last.Failed
+		status["last_flush_duration"] = last.Duration
+	}
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// queueManager returns an enabled manager mirroring a WSL temp dir to a
+// Windows temp dir through a root mapping
+func queueManager(t *testing.T) (*AutoSyncManager, string, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("auto-sync only handles WSL (Unix) source paths")
+	}
+	wslDir, winDir := t.TempDir(), t.TempDir()
+	cfg := DefaultAutoSyncConfig()
+	cfg.Enabled = true
+	cfg.Silent = true
+	cfg.RootMappings = []AutoSyncRootMapping{{WSLRoot: wslDir, WindowsRoot: winDir, Enabled: true}}
+	m := &AutoSyncManager{config: cfg, enabled: true, isWSL: true}
+	t.Cleanup(m.Flush)
+	return m, wslDir, winDir
+}
+
+func TestAutoSyncQueue_DebouncesAndDedupes(t *testing.T) {
+	m, wslDir, winDir := queueManager(t)
+	src := filepath.Join(wslDir, "a.txt")
+	for i := 1; i <= 3; i++ {
+		os.WriteFile(src, []byte(fmt.Sprintf("v%d", i)), 0644)
+		m.AfterWrite(src)
+	}
+	status := m.GetStatus()
+	if status["pending_count"] != 1 || status["deduped_writes"] != int64(2) {
+		t.Fatalf("pending %v, deduped %v", status["pending_count"], status["deduped_writes"])
+	}
+	if _, err := os.Stat(filepath.Join(winDir, "a.txt")); err == nil {
+		t.Error("copied before the debounce expired")
+	}
+
+	m.Flush()
+	if got := readString(filepath.Join(winDir, "a.txt")); got != "v3" {
+		t.Errorf("Windows copy = %q, want the last write", got)
+	}
+	status = m.GetStatus()
+	if status["pending_count"] != 0 || status["files_synced"] != int64(1) || status["last_flush_files"] != 1 {
+		t.Errorf("status after flush = %v", status)
+	}
+
+	// Left alone, the debounce timer flushes on its own
+	os.WriteFile(filepath.Join(wslDir, "b.txt"), []byte("b"), 0644)
+	m.AfterEdit(filepath.Join(wslDir, "b.txt"))
+	if !waitForFile(filepath.Join(winDir, "b.txt")) {
+		t.Error("debounced copy never ran")
+	}
+}
+
+func TestAutoSyncQueue_FullBatchFlushesImmediately(t *testing.T) {
+	m, wslDir, winDir := queueManager(t)
+	start := time.Now()
+	for i := 0; i < autoSyncBatchSize; i++ {
+		src := filepath.Join(wslDir, fmt.Sprintf("f%02d.txt", i))
+		os.WriteFile(src, []byte("x"), 0644)
+		m.AfterWrite(src)
+	}
+	last := filepath.Join(winDir, fmt.Sprintf("f%02d.txt", autoSyncBatchSize-1))
+	for !fileExists(last) && time.Since(start) < autoSyncDebounce {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !fileExists(last) {
+		t.Fatal("a full batch waited for the debounce")
+	}
+	m.Flush()
+	if synced := m.GetStatus()["files_synced"]; synced != int64(autoSyncBatchSize) {
+		t.Errorf("files_synced = %v", synced)
+	}
+}
+
+func TestAutoSyncQueue_DeleteDropsPendingCopies(t *testing.T) {
+	m, wslDir, winDir := queueManager(t)
+	dir := filepath.Join(wslDir, "build")
+	os.MkdirAll(dir, 0755)
+	for _, name := range []string{"build/x.txt", "build/y.txt", "keep.txt"} {
+		src := filepath.Join(wslDir, name)
+		os.WriteFile(src, []byte(name), 0644)
+		m.AfterWrite(src)
+	}
+	os.RemoveAll(dir)
+	m.AfterDelete(dir)
+	if depth := m.GetStatus()["pending_count"]; depth != 1 {
+		t.Errorf("pending_count = %v after deleting build/", depth)
+	}
+	m.Flush()
+	if !fileExists(filepath.Join(winDir, "keep.txt")) || fileExists(filepath.Join(winDir, "build")) {
+		t.Error("deleted paths were copied or the kept file was not")
+	}
+	if errs := m.GetStatus()["sync_errors"]; errs != int64(0) {
+		t.Errorf("sync_errors = %v", errs)
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
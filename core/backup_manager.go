@@ -54,17 +54,22 @@ type RestoreResult struct {
 	PreRestoreID  string              `json:"pre_restore_id,omitempty"`
 }
 
-// SoftDeleteInfo contains information about a soft-deleted file in the trash.
-// Files live at <BackupDir>/filesdelete/<sd-id>/<basename> with a metadata.json
-// sidecar describing the original location and contents.
+// SoftDeleteInfo contains information about a soft-deleted file or directory
+// in the trash. Entries live at <trash root>/<timestamp>-<shortid>/<rel>
+// with a metadata.json sidecar describing the original location and contents.
 type SoftDeleteInfo struct {
-	SDID         string    `json:"sd_id"`         // soft-delete ID (e.g. "sd-20260611-150455-a1b2c3d4")
-	OriginalPath string    `json:"original_path"` // where the file used to live
-	DestPath     string    `json:"dest_path"`     // full path inside the trash
-	Size         int64     `json:"size"`          // bytes
-	Hash         string    `json:"hash"`          // SHA-256 hex of the moved file
-	Timestamp    time.Time `json:"timestamp"`     // when the soft-delete happened
-	Kind         string    `json:"kind"`          // always "soft_delete" (parallels BackupInfo.Operation)
+	SDID         string    `json:"sd_id"`              // soft-delete ID (e.g. "sd-20260611-150455-a1b2c3d4")
+	OriginalPath string    `json:"original_path"`      // where the file used to live
+	DestPath     string    `json:"dest_path"`          // full path inside the trash
+	RelPath      string    `json:"rel_path,omitempty"` // DestPath inside the entry: the path below its allowed root
+	Size         int64     `json:"size"`               // bytes (of every file, for a directory)
+	IsDir        bool      `json:"is_dir,omitempty"`
+	Files        int       `json:"files,omitempty"`        // regular files in a deleted directory
+	Hash         string    `json:"hash"`                   // SHA-256 hex of the moved file; empty for directories
+	Timestamp    time.Time `json:"timestamp"`              // when the soft-delete happened
+	Kind         string    `json:"kind"`                   // always "soft_delete" (parallels BackupInfo.Operation)
+	Source       string    `json:"source,omitempty"`       // tool or operation that deleted it, e.g. "delete_file"
+	CrossVolume  bool      `json:"cross_volume,omitempty"` // copied + deleted: no trash root shared its volume
 }
 
 // BackupManager gestiona todos los backups del sistema
@@ -75,6 +80,12 @@ type BackupManager struct {
 	mutex         sync.RWMutex
 	metadataCache map[string]*BackupInfo
 	cacheLastScan time.Time
+
+	// Trash locations, wired by the engine through configureTrash
+	trashDir         string                                        // --trash-dir
+	rootTrashDirs    func() []string                               // the .mcp-trash of every allowed root
+	crossVolumeMove  func(src, dst string, info os.FileInfo) error // copy + verify + delete
+	authorizeRestore func(path string) (string, error)             // access check of a restore target; returns the resolved path
 }
 
 // NewBackupManager crea un nuevo BackupManager
@@ -194,16 +205,23 @@ func (bm *BackupManager) CreateBatchBackup(paths []string, operation string, use
 
 	var files []BackupMetadata
 	var totalSize int64
+	usedNames := make(map[string]bool, len(paths))
 
 	// Copiar cada archivo
-	for _, path := range paths {
+	for i, path := range paths {
 		fileInfo, err := os.Stat(path)
 		if err != nil {
 			slog.Warn("Skipping file in backup", "path", path, "error", err)
 			continue
 		}
 
+		// Files from different directories can share a base name; keep each
+		// copy distinct (restore goes through BackupPath in the metadata)
 		fileName := filepath.Base(path)
+		if usedNames[fileName] {
+			fileName = fmt.Sprintf("%d-%s", i, fileName)
+		}
+		usedNames[fileName] = true
 		backupFilePath := filepath.Join(backupFilesDir, fileName)
 
 		hash, err := copyFileWithHash(path, backupFilePath)
@@ -631,6 +649,17 @@ func (bm *BackupManager) GetBackupLimits() (maxCount int, maxAgeDays int) {
 	return bm.maxBackups, bm.maxAgeDays
 }
 
+// Totals returns the number of backups and their combined size from the
+// metadata cache (no directory scan)
+func (bm *BackupManager) Totals() (count int, bytes int64) {
+	bm.mutex.RLock()
+	defer bm.mutex.RUnlock()
+	for _, info := range bm.metadataCache {
+		bytes += info.TotalSize
+	}
+	return len(bm.metadataCache), bytes
+}
+
 func (bm *BackupManager) GetBackupPath(backupID string) string {
 	// Sanitize backup ID - return empty string on invalid ID
 	if err := sanitizeBackupID(backupID); err != nil {
@@ -899,20 +928,186 @@ func FormatAge(t time.Time) string {
 // Soft-delete (trash) subsystem
 // ============================================================================
 //
-// Soft-deletes are stored at <BackupDir>/filesdelete/<sd-id>/<basename> with a
-// metadata.json sidecar. They are NOT added to BackupManager.metadataCache so
-// the maxBackups cap and cleanupIfNeeded do not touch them. Use PurgeTrash for
-// manual cleanup, or restore via RestoreTrash.
+// Soft-deletes are stored at <trash root>/<timestamp>-<shortid>/<rel>,
+// where <rel> is the deleted path relative to the nearest allowed root, with
+// a metadata.json sidecar. The trash root is the first of --trash-dir,
+// <BackupDir>/trash and the allowed root's own .mcp-trash that sits on the
+// deleted file's volume, so the move stays a rename; when none does, the
+// file is copied and deleted instead. Two files with the same name never collide, and a
+// deleted directory keeps its whole subtree. They are NOT added to
+// BackupManager.metadataCache so the maxBackups cap and cleanupIfNeeded do
+// not touch them. Use PurgeTrash for manual cleanup, or restore via
+// RestoreTrash.
 //
 // This subsystem was added in v4.5.2+ to fix a real bug (2026-06-11) where
 // soft-deletes went to a parallel filesdelete/ folder with no discoverability
-// and no way to restore via MCP tools.
+// and no way to restore via MCP tools. Entries of the earlier flat layout,
+// <BackupDir>/filesdelete/<sd-id>/<basename>, are still listed, restored and
+// purged.
 
 // softDeleteTrashSubdir is the trash subdirectory name under BackupDir.
-const softDeleteTrashSubdir = "filesdelete"
+const softDeleteTrashSubdir = "trash"
+
+// legacyTrashSubdir held the flat <sd-id>/<basename> entries before the
+// structure-preserving layout
+const legacyTrashSubdir = "filesdelete"
+
+// RootTrashDirName is the per-root trash directory created at the top of an
+// allowed root when neither --trash-dir nor the backup directory shares its
+// volume
+const RootTrashDirName = ".mcp-trash"
+
+// trashEntry is one soft-delete: its directory and its metadata
+type trashEntry struct {
+	dir  string
+	info SoftDeleteInfo
+}
+
+// configureTrash sets the --trash-dir, the source of the per-root trash
+// directories, the cross-volume fallback used when no trash root shares
+// the deleted file's volume and the access check of restore targets
+func (bm *BackupManager) configureTrash(trashDir string, rootTrashDirs func() []string, move func(src, dst string, info os.FileInfo) error,
+	authorizeRestore func(path string) (string, error)) {
+	bm.mutex.Lock()
+	defer bm.mutex.Unlock()
+	if trashDir != "" {
+		trashDir = filepath.Clean(trashDir)
+	}
+	bm.trashDir, bm.rootTrashDirs, bm.crossVolumeMove = trashDir, rootTrashDirs, move
+	bm.authorizeRestore = authorizeRestore
+}
+
+// defaultTrashRoot is <BackupDir>/trash
+func (bm *BackupManager) defaultTrashRoot() string {
+	return filepath.Join(bm.backupDir, softDeleteTrashSubdir)
+}
+
+// trashCandidates lists where a soft-delete below allowedRoot may go, most
+// preferred first: --trash-dir, <BackupDir>/trash, <allowedRoot>/.mcp-trash
+func (bm *BackupManager) trashCandidates(allowedRoot string) []string {
+	var candidates []string
+	if bm.trashDir != "" {
+		candidates = append(candidates, bm.trashDir)
+	}
+	candidates = append(candidates, bm.defaultTrashRoot())
+	if allowedRoot != "" {
+		candidates = append(candidates, filepath.Join(allowedRoot, RootTrashDirName))
+	}
+	return candidates
+}
+
+// chooseTrashRoot picks the first candidate on path's volume. sameVolume is
+// false when none is, and the first candidate is returned.
+func (bm *BackupManager) chooseTrashRoot(path, allowedRoot string) (root string, sameVolume bool) {
+	candidates := bm.trashCandidates(allowedRoot)
+	vol := pathVolume(path)
+	for _, c := range candidates {
+		if pathVolume(c) == vol {
+			return c, true
+		}
+	}
+	return candidates[0], false
+}
 
-// SoftDeleteFile moves a file to the trash under <BackupDir>/filesdelete/<sd-id>/<basename>
-// and writes a metadata.json sidecar with the original path, size, and hash.
+// trashRoots lists every directory that may hold current-layout entries,
+// without duplicates. Callers hold bm.mutex.
+func (bm *BackupManager) trashRoots() []string {
+	roots := []string{}
+	if bm.trashDir != "" {
+		roots = append(roots, bm.trashDir)
+	}
+	roots = append(roots, bm.defaultTrashRoot())
+	if bm.rootTrashDirs != nil {
+		roots = append(roots, bm.rootTrashDirs()...)
+	}
+	seen := make(map[string]bool, len(roots))
+	unique := roots[:0]
+	for _, r := range roots {
+		if r = filepath.Clean(r); !seen[r] {
+			seen[r] = true
+			unique = append(unique, r)
+		}
+	}
+	return unique
+}
+
+// findTrashEntry locates sdID under every trash root, then in the legacy
+// layout. Callers hold bm.mutex.
+func (bm *BackupManager) findTrashEntry(sdID string) (*trashEntry, error) {
+	var dirs []string
+	for _, root := range bm.trashRoots() {
+		dirs = append(dirs, filepath.Join(root, strings.TrimPrefix(sdID, "sd-")))
+	}
+	for _, dir := range append(dirs, filepath.Join(bm.backupDir, legacyTrashSubdir, sdID)) {
+		data, err := os.ReadFile(filepath.Join(dir, "metadata.json"))
+		if err != nil {
+			continue
+		}
+		entry := &trashEntry{dir: dir}
+		if err := json.Unmarshal(data, &entry.info); err != nil {
+			return nil, fmt.Errorf("invalid trash metadata: %w", err)
+		}
+		return entry, nil
+	}
+	return nil, fmt.Errorf("trash entry not found: %s", sdID)
+}
+
+// trashEntries reads every entry of every trash root and of the legacy
+// layout, skipping (and logging) those whose metadata is unreadable.
+// Callers hold bm.mutex.
+func (bm *BackupManager) trashEntries() ([]trashEntry, error) {
+	var result []trashEntry
+	for _, root := range append(bm.trashRoots(), filepath.Join(bm.backupDir, legacyTrashSubdir)) {
+		dirs, err := os.ReadDir(root)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read trash directory %s: %w", root, err)
+		}
+		for _, d := range dirs {
+			if !d.IsDir() {
+				continue
+			}
+			entry := trashEntry{dir: filepath.Join(root, d.Name())}
+			data, err := os.ReadFile(filepath.Join(entry.dir, "metadata.json"))
+			if err != nil {
+				slog.Warn("Skipping trash entry with unreadable metadata", "entry", d.Name(), "error", err)
+				continue
+			}
+			if err := json.Unmarshal(data, &entry.info); err != nil {
+				slog.Warn("Skipping trash entry with invalid metadata", "entry", d.Name(), "error", err)
+				continue
+			}
+			result = append(result, entry)
+		}
+	}
+	return result, nil
+}
+
+// contentPath is where the entry's file or directory sits: <dir>/<rel_path>,
+// or <dir>/<basename> for legacy entries. Only the recorded relative part is
+// trusted, so tampered metadata cannot point outside the entry.
+func (t *trashEntry) contentPath() (string, error) {
+	rel := t.info.RelPath
+	if rel == "" {
+		rel = filepath.Base(t.info.DestPath)
+	}
+	rel = filepath.Clean(rel)
+	if filepath.IsAbs(rel) || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("trash metadata points outside its entry: %s", t.info.RelPath)
+	}
+	return filepath.Join(t.dir, rel), nil
+}
+
+// SoftDeleteFile moves a file or a whole directory to the trash under
+// <trash root>/<timestamp>-<shortid>/<relPath> and writes a metadata.json
+// sidecar with the original path, size, hash (files only) and source, the
+// tool or operation that deleted it. relPath is the path below allowedRoot,
+// the nearest allowed root ("" when there is none; relPath is then the base
+// name when empty). The trash root is chosen on the file's volume; when no
+// candidate shares it, the move is a copy + verify + delete and the result
+// is marked CrossVolume.
 // Returns a SoftDeleteInfo that the caller (engine) can use to format a
 // response with a restore command.
 //
@@ -924,7 +1119,7 @@ func FormatAge(t time.Time) string {
 // On error after the move, the file may be left in the trash with no metadata.
 // Callers should treat SoftDeleteFile failures as "delete did not happen" and
 // surface the error to the user.
-func (bm *BackupManager) SoftDeleteFile(path string) (*SoftDeleteInfo, error) {
+func (bm *BackupManager) SoftDeleteFile(path, relPath, allowedRoot, source string) (*SoftDeleteInfo, error) {
 	bm.mutex.Lock()
 	defer bm.mutex.Unlock()
 
@@ -938,60 +1133,83 @@ func (bm *BackupManager) SoftDeleteFile(path string) (*SoftDeleteInfo, error) {
 	if strings.Contains(cleanPath, "..") {
 		return nil, fmt.Errorf("invalid path: contains '..'")
 	}
+	if relPath == "" {
+		relPath = filepath.Base(cleanPath)
+	}
+	relPath = filepath.Clean(relPath)
+	if filepath.IsAbs(relPath) || relPath == "." || strings.Contains(relPath, "..") {
+		return nil, fmt.Errorf("invalid trash path: %s", relPath)
+	}
+	for _, root := range bm.trashRoots() {
+		if pathInside(root, cleanPath) {
+			return nil, fmt.Errorf("%s is already in the trash (%s); use backup(action:\"purge_trash\") to remove it", cleanPath, root)
+		}
+	}
 
 	// Stat the source so we can record size + mtime.
 	fileInfo, err := os.Stat(cleanPath)
 	if err != nil {
 		return nil, fmt.Errorf("source file not found: %w", err)
 	}
+	size, files := fileInfo.Size(), 0
 	if fileInfo.IsDir() {
-		return nil, fmt.Errorf("cannot soft-delete a directory: %s", cleanPath)
+		size = 0
+		filepath.WalkDir(cleanPath, func(_ string, d os.DirEntry, err error) error {
+			if err == nil && d.Type().IsRegular() {
+				if fi, err := d.Info(); err == nil {
+					size += fi.Size()
+					files++
+				}
+			}
+			return nil
+		})
 	}
 
 	// Generate SD-ID and prepare the per-deletion directory.
+	trashRoot, sameVolume := bm.chooseTrashRoot(cleanPath, allowedRoot)
 	sdID := generateSoftDeleteID()
-	trashRoot := filepath.Join(bm.backupDir, softDeleteTrashSubdir)
-	sdDir := filepath.Join(trashRoot, sdID)
-	if err := os.MkdirAll(sdDir, 0700); err != nil {
+	sdDir := filepath.Join(trashRoot, strings.TrimPrefix(sdID, "sd-"))
+	destPath := filepath.Join(sdDir, relPath)
+	if err := os.MkdirAll(filepath.Dir(destPath), 0700); err != nil {
 		return nil, fmt.Errorf("failed to create trash directory: %w", err)
 	}
 
-	destPath := filepath.Join(sdDir, filepath.Base(cleanPath))
-
-	// If destPath already exists (extremely rare — same basename in same second
-	// from two soft-deletes), suffix the basename with a short hash of the source
-	// path. Avoids collision without needing a retry loop.
-	if _, err := os.Stat(destPath); err == nil {
-		ext := filepath.Ext(destPath)
-		name := destPath[:len(destPath)-len(ext)]
-		shortHash := fmt.Sprintf("%x", sha256.Sum256([]byte(cleanPath+sdID)))[:8]
-		destPath = fmt.Sprintf("%s_%s%s", name, shortHash, ext)
-	}
-
-	// Atomic move (same-volume); fails on cross-volume (EXDEV) — caller can
-	// fall back to copy+remove in a follow-up.
-	if err := os.Rename(cleanPath, destPath); err != nil {
-		// Clean up the empty trash subdir we just created.
-		_ = os.Remove(sdDir)
-		return nil, fmt.Errorf("failed to move file to trash: %w", err)
+	// Atomic move when the trash root shares the volume; otherwise (or when
+	// the volume check could not tell) copy + verify + delete.
+	crossVolume := false
+	err = renamePath(cleanPath, destPath)
+	if err != nil && isCrossDeviceError(err) && bm.crossVolumeMove != nil {
+		slog.Warn("No trash root on the deleted file's volume; copying it to the trash instead of renaming",
+			"path", cleanPath, "trash", trashRoot, "same_volume_check", sameVolume)
+		crossVolume = true
+		err = bm.crossVolumeMove(cleanPath, destPath, fileInfo)
 	}
-
-	// Compute hash of the moved file for integrity verification on restore.
-	hash, err := hashFile(destPath)
 	if err != nil {
-		// Move succeeded but hash failed — file is in trash, metadata is partial.
-		// Log loudly; the metadata write below will still record what we have.
-		slog.Warn("Failed to hash soft-deleted file", "path", destPath, "error", err)
+		// Clean up the empty trash subdir we just created.
+		_ = os.RemoveAll(sdDir)
+		return nil, fmt.Errorf("failed to move file to trash: %w", lockedError("delete", cleanPath, err))
 	}
 
 	info := &SoftDeleteInfo{
 		SDID:         sdID,
 		OriginalPath: cleanPath,
 		DestPath:     destPath,
-		Size:         fileInfo.Size(),
-		Hash:         hash,
+		RelPath:      relPath,
+		Size:         size,
+		IsDir:        fileInfo.IsDir(),
+		Files:        files,
 		Timestamp:    time.Now(),
 		Kind:         "soft_delete",
+		Source:       source,
+		CrossVolume:  crossVolume,
+	}
+	if !info.IsDir {
+		// Hash the moved file for integrity verification on restore.
+		if info.Hash, err = hashFile(destPath); err != nil {
+			// Move succeeded but hash failed — file is in trash, metadata is
+			// partial. Log loudly; the metadata write below still records it.
+			slog.Warn("Failed to hash soft-deleted file", "path", destPath, "error", err)
+		}
 	}
 
 	// Write metadata sidecar. If this fails, the file is in the trash but
@@ -1014,13 +1232,9 @@ func (bm *BackupManager) ListTrash(limit int, filterPath string, olderThanDays i
 		return nil, nil
 	}
 
-	trashRoot := filepath.Join(bm.backupDir, softDeleteTrashSubdir)
-	entries, err := os.ReadDir(trashRoot)
+	entries, err := bm.trashEntries()
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("failed to read trash directory: %w", err)
+		return nil, err
 	}
 
 	cutoff := time.Time{}
@@ -1030,21 +1244,7 @@ func (bm *BackupManager) ListTrash(limit int, filterPath string, olderThanDays i
 
 	results := make([]SoftDeleteInfo, 0, len(entries))
 	for _, entry := range entries {
-		if !entry.IsDir() {
-			continue
-		}
-		sdDir := filepath.Join(trashRoot, entry.Name())
-		metaPath := filepath.Join(sdDir, "metadata.json")
-		data, err := os.ReadFile(metaPath)
-		if err != nil {
-			slog.Warn("Skipping trash entry with unreadable metadata", "sd_id", entry.Name(), "error", err)
-			continue
-		}
-		var info SoftDeleteInfo
-		if err := json.Unmarshal(data, &info); err != nil {
-			slog.Warn("Skipping trash entry with invalid metadata", "sd_id", entry.Name(), "error", err)
-			continue
-		}
+		info := entry.info
 
 		// Apply filters
 		if filterPath != "" {
@@ -1073,11 +1273,12 @@ func (bm *BackupManager) ListTrash(limit int, filterPath string, olderThanDays i
 	return results, nil
 }
 
-// RestoreTrash moves a soft-deleted file back to its original path. The
-// original directory is created if missing. Returns the restored original path.
+// RestoreTrash moves a soft-deleted file or directory back to its original
+// path. The original directory is created if missing. Returns the restored
+// original path.
 //
-// Validates the SD-ID against path traversal and confirms the recorded
-// DestPath is actually inside our known trash root (defense against metadata
+// Validates the SD-ID against path traversal and only trusts the path the
+// metadata records relative to its own entry (defense against metadata
 // tampering).
 func (bm *BackupManager) RestoreTrash(sdID string) (string, error) {
 	if err := sanitizeSoftDeleteID(sdID); err != nil {
@@ -1091,56 +1292,58 @@ func (bm *BackupManager) RestoreTrash(sdID string) (string, error) {
 		return "", fmt.Errorf("backup directory not configured; cannot restore from trash")
 	}
 
-	trashRoot := filepath.Join(bm.backupDir, softDeleteTrashSubdir)
-	sdDir := filepath.Join(trashRoot, sdID)
-	metaPath := filepath.Join(sdDir, "metadata.json")
-	data, err := os.ReadFile(metaPath)
+	entry, err := bm.findTrashEntry(sdID)
 	if err != nil {
-		return "", fmt.Errorf("trash entry not found: %s", sdID)
-	}
-
-	var info SoftDeleteInfo
-	if err := json.Unmarshal(data, &info); err != nil {
-		return "", fmt.Errorf("invalid trash metadata: %w", err)
+		return "", err
 	}
-
-	// Defense: confirm DestPath is inside trashRoot (defense against metadata
-	// tampering or copy-paste of an SD-ID pointing elsewhere).
-	absTrashRoot, err := filepath.Abs(trashRoot)
+	info := entry.info
+	src, err := entry.contentPath()
 	if err != nil {
-		return "", fmt.Errorf("failed to resolve trash root: %w", err)
+		return "", err
 	}
-	absDest, err := filepath.Abs(info.DestPath)
-	if err != nil {
-		return "", fmt.Errorf("failed to resolve dest path: %w", err)
+
+	// metadata.json is not trusted for the destination either: a forged
+	// entry must not move a file outside the allowed, writable paths
+	if !filepath.IsAbs(info.OriginalPath) {
+		return "", fmt.Errorf("trash metadata has no absolute original path: %q", info.OriginalPath)
 	}
-	if !strings.HasPrefix(strings.ToLower(absDest), strings.ToLower(absTrashRoot)+string(os.PathSeparator)) {
-		return "", fmt.Errorf("trash metadata points outside trash root: %s", info.DestPath)
+	dest := filepath.Clean(info.OriginalPath)
+	if bm.authorizeRestore != nil {
+		if dest, err = bm.authorizeRestore(dest); err != nil {
+			return "", fmt.Errorf("cannot restore to %s: %w", info.OriginalPath, err)
+		}
 	}
 
 	// Confirm the file still exists in the trash
-	if _, err := os.Stat(info.DestPath); err != nil {
+	srcInfo, err := os.Stat(src)
+	if err != nil {
 		return "", fmt.Errorf("trash file missing: %w", err)
 	}
 
 	// Ensure the original directory exists (it may have been removed since)
-	if err := os.MkdirAll(filepath.Dir(info.OriginalPath), 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
 		return "", fmt.Errorf("failed to recreate original directory: %w", err)
 	}
 
 	// If the original path now has a file (user re-created it), refuse — do
 	// not silently overwrite.
-	if _, err := os.Stat(info.OriginalPath); err == nil {
-		return "", fmt.Errorf("original path already exists; cannot overwrite: %s", info.OriginalPath)
+	if _, err := os.Stat(dest); err == nil {
+		return "", fmt.Errorf("original path already exists; cannot overwrite: %s", dest)
 	}
 
-	if err := os.Rename(info.DestPath, info.OriginalPath); err != nil {
+	err = renamePath(src, dest)
+	if err != nil && isCrossDeviceError(err) && bm.crossVolumeMove != nil {
+		err = bm.crossVolumeMove(src, dest, srcInfo)
+	}
+	if err != nil {
 		return "", fmt.Errorf("failed to move file back: %w", err)
 	}
 
-	// Remove the now-empty sd-id subdir + metadata.json (best-effort).
-	_ = os.Remove(metaPath)
-	_ = os.Remove(sdDir)
+	// Remove the now-empty entry: metadata.json and the directories that
+	// held the relative path (best-effort).
+	if err := os.RemoveAll(entry.dir); err != nil {
+		slog.Warn("Failed to remove restored trash entry", "sd_id", sdID, "error", err)
+	}
 
 	return info.OriginalPath, nil
 }
@@ -1149,57 +1352,121 @@ func (bm *BackupManager) RestoreTrash(sdID string) (string, error) {
 // Returns (deletedCount, freedBytes, error). If dryRun is true, no files are
 // removed; the returned counts reflect what WOULD be removed.
 func (bm *BackupManager) PurgeTrash(olderThanDays int, dryRun bool) (int, int64, error) {
+	res, err := bm.PurgeTrashOlderThan(time.Duration(olderThanDays)*24*time.Hour, dryRun)
+	if err != nil {
+		return 0, 0, err
+	}
+	return len(res.Entries), res.FreedBytes, nil
+}
+
+// TrashPurgeResult lists the trash entries a purge removed, or would remove
+// on a dry run
+type TrashPurgeResult struct {
+	Entries    []SoftDeleteInfo `json:"entries"` // oldest first
+	FreedBytes int64            `json:"freed_bytes"`
+	DryRun     bool             `json:"dry_run"`
+}
+
+// PurgeTrashOlderThan permanently removes trash entries soft-deleted more
+// than maxAge ago. It holds the backup lock for the whole pass, so an entry
+// restored meanwhile is either gone before the pass or restored after it,
+// never both. An entry whose file is no longer in the trash (restored, with
+// only its metadata left behind) is cleaned up but frees nothing.
+func (bm *BackupManager) PurgeTrashOlderThan(maxAge time.Duration, dryRun bool) (*TrashPurgeResult, error) {
 	bm.mutex.Lock()
 	defer bm.mutex.Unlock()
 
+	res := &TrashPurgeResult{Entries: []SoftDeleteInfo{}, DryRun: dryRun}
 	if bm.backupDir == "" {
-		return 0, 0, nil
+		return res, nil
 	}
 
-	trashRoot := filepath.Join(bm.backupDir, softDeleteTrashSubdir)
-	entries, err := os.ReadDir(trashRoot)
+	entries, err := bm.trashEntries()
 	if err != nil {
-		if os.IsNotExist(err) {
-			return 0, 0, nil
-		}
-		return 0, 0, fmt.Errorf("failed to read trash directory: %w", err)
+		return nil, err
 	}
 
-	cutoff := time.Now().AddDate(0, 0, -olderThanDays)
-	deletedCount := 0
-	var freedBytes int64
+	cutoff := time.Now().Add(-maxAge)
 
 	for _, entry := range entries {
-		if !entry.IsDir() {
-			continue
-		}
-		sdDir := filepath.Join(trashRoot, entry.Name())
-		metaPath := filepath.Join(sdDir, "metadata.json")
-		data, err := os.ReadFile(metaPath)
-		if err != nil {
-			continue
-		}
-		var info SoftDeleteInfo
-		if err := json.Unmarshal(data, &info); err != nil {
-			continue
-		}
+		info := entry.info
 		if !info.Timestamp.Before(cutoff) {
 			continue
 		}
-
-		freedBytes += info.Size
-		deletedCount++
+		if src, err := entry.contentPath(); err != nil {
+			info.Size = 0
+		} else if _, err := os.Stat(src); err != nil {
+			info.Size = 0
+		}
 
 		if !dryRun {
-			if err := os.RemoveAll(sdDir); err != nil {
-				slog.Warn("Failed to purge trash entry", "sd_id", entry.Name(), "error", err)
-				deletedCount--
-				freedBytes -= info.Size
+			if err := os.RemoveAll(entry.dir); err != nil {
+				slog.Warn("Failed to purge trash entry", "sd_id", info.SDID, "error", err)
+				continue
 			}
 		}
+		res.Entries = append(res.Entries, info)
+		res.FreedBytes += info.Size
 	}
 
-	return deletedCount, freedBytes, nil
+	sort.Slice(res.Entries, func(i, j int) bool {
+		return res.Entries[i].Timestamp.Before(res.Entries[j].Timestamp)
+	})
+	return res, nil
+}
+
+// purgeExpiredTrash is the startup retention pass of --trash-max-age,
+// logging every entry it removes
+func purgeExpiredTrash(bm *BackupManager, maxAge time.Duration) {
+	res, err := bm.PurgeTrashOlderThan(maxAge, false)
+	if err != nil {
+		slog.Warn("Trash retention pass failed", "error", err)
+		return
+	}
+	for _, info := range res.Entries {
+		slog.Info("Purged expired trash entry", "sd_id", info.SDID, "original_path", info.OriginalPath,
+			"size", info.Size, "deleted_at", info.Timestamp.Format(time.RFC3339))
+	}
+	if len(res.Entries) > 0 {
+		slog.Info("Trash retention pass", "purged", len(res.Entries), "freed", FormatSize(res.FreedBytes), "max_age", maxAge)
+	}
+}
+
+// TrashRootUsage is what one trash directory holds
+type TrashRootUsage struct {
+	Dir     string `json:"dir"`
+	Entries int    `json:"entries"`
+	Bytes   int64  `json:"bytes"`
+}
+
+// TrashUsage counts the entries and bytes of every trash root, and of the
+// legacy layout while it still holds entries
+func (bm *BackupManager) TrashUsage() ([]TrashRootUsage, error) {
+	bm.mutex.RLock()
+	defer bm.mutex.RUnlock()
+
+	entries, err := bm.trashEntries()
+	if err != nil {
+		return nil, err
+	}
+	roots := bm.trashRoots()
+	usage := make([]TrashRootUsage, len(roots), len(roots)+1)
+	index := make(map[string]int, len(roots)+1)
+	for i, r := range roots {
+		usage[i].Dir, index[r] = r, i
+	}
+	for _, entry := range entries {
+		dir := filepath.Dir(entry.dir)
+		i, ok := index[dir]
+		if !ok {
+			usage = append(usage, TrashRootUsage{Dir: dir})
+			i = len(usage) - 1
+			index[dir] = i
+		}
+		usage[i].Entries++
+		usage[i].Bytes += entry.info.Size
+	}
+	return usage, nil
 }
 
 // saveSoftDeleteMetadata writes a SoftDeleteInfo as JSON to <sdDir>/metadata.json
@@ -1213,11 +1480,17 @@ func (bm *BackupManager) saveSoftDeleteMetadata(sdDir string, info *SoftDeleteIn
 }
 
 // generateSoftDeleteID returns a unique soft-delete ID in the form
-// "sd-<YYYYMMDD-HHMMSS>-<16 hex chars>". Reuses the algorithm from
-// generateBackupID but with a "sd-" prefix for grep-ability and namespace
-// separation.
+// "sd-<YYYYMMDD-HHMMSS>-<8 hex chars>"; the entry directory is the ID
+// without its "sd-" prefix, which is kept for grep-ability and namespace
+// separation from backup IDs.
 func generateSoftDeleteID() string {
-	return "sd-" + generateBackupID()
+	timestamp := time.Now().Format("20060102-150405")
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		// Fallback if crypto/rand fails (should never happen)
+		return fmt.Sprintf("sd-%s-%08x", timestamp, uint32(time.Now().UnixNano()))
+	}
+	return "sd-" + timestamp + "-" + hex.EncodeToString(b)
 }
 
 // hashFile computes the SHA-256 hex digest of a single file's contents.
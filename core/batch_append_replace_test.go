@@ -0,0 +1,75 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBatchAppendAndReplaceNth(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	mgr := NewBatchOperationManager(t.TempDir(), 10)
+	mgr.SetEngine(engine)
+
+	log := filepath.Join(dir, "log.txt")
+	code := filepath.Join(dir, "main.go")
+	os.WriteFile(log, []byte("first\n"), 0644)
+	os.WriteFile(code, []byte("// TODO a\n// TODO b\n// TODO c\n"), 0644)
+
+	res := mgr.ExecuteBatch(BatchRequest{Operations: []FileOperation{
+		{Type: "append", Path: log, Content: "second\n"},
+		{Type: "append", Path: filepath.Join(dir, "fresh.txt"), Content: "created\n"},
+		{Type: "replace_nth", Path: code, OldText: "TODO", NewText: "DONE", Occurrence: -1},
+		{Type: "replace_nth", Path: code, OldText: "TODO", NewText: "DONE", Occurrence: 1},
+	}})
+	if !res.Success {
+		t.Fatalf("batch failed: %v %+v", res.Errors, res.Results)
+	}
+	if got, _ := os.ReadFile(log); string(got) != "first\nsecond\n" {
+		t.Errorf("append result = %q", got)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, "fresh.txt")); string(got) != "created\n" {
+		t.Errorf("append to missing file = %q", got)
+	}
+	if got, _ := os.ReadFile(code); string(got) != "// DONE a\n// TODO b\n// DONE c\n" {
+		t.Errorf("replace_nth result = %q", got)
+	}
+}
+
+func TestBatchAppendAndReplaceNth_ValidationAndRollback(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	mgr := NewBatchOperationManager(t.TempDir(), 10)
+	mgr.SetEngine(engine)
+
+	res := mgr.ExecuteBatch(BatchRequest{Operations: []FileOperation{
+		{Type: "append", Path: filepath.Join(dir, "a.txt")},
+		{Type: "replace_nth", Path: filepath.Join(dir, "missing.go"), OldText: "x"},
+	}})
+	joined := strings.Join(res.Errors, "\n")
+	for _, want := range []string{"content is required for append", "occurrence is required", "file does not exist"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("validation errors missing %q: %v", want, res.Errors)
+		}
+	}
+
+	// An out-of-range occurrence fails mid-batch; atomic rollback undoes the append
+	log := filepath.Join(dir, "log.txt")
+	code := filepath.Join(dir, "main.go")
+	os.WriteFile(log, []byte("first\n"), 0644)
+	os.WriteFile(code, []byte("TODO\n"), 0644)
+	res = mgr.ExecuteBatch(BatchRequest{
+		Atomic:       true,
+		CreateBackup: true,
+		Operations: []FileOperation{
+			{Type: "append", Path: log, Content: "second\n"},
+			{Type: "replace_nth", Path: code, OldText: "TODO", NewText: "DONE", Occurrence: 3},
+		},
+	})
+	if res.Success || !res.RollbackDone {
+		t.Fatalf("expected rollback, got %+v", res)
+	}
+	if got, _ := os.ReadFile(log); string(got) != "first\n" {
+		t.Errorf("append not rolled back: %q", got)
+	}
+}
@@ -0,0 +1,93 @@
+package core
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBatchRequest_CreateBackupDefaultsToTrue(t *testing.T) {
+	var req BatchRequest
+	if err := json.Unmarshal([]byte(`{"operations":[{"type":"delete","path":"x"}]}`), &req); err != nil {
+		t.Fatal(err)
+	}
+	if !req.CreateBackup {
+		t.Error("create_backup should default to true when omitted")
+	}
+	if err := json.Unmarshal([]byte(`{"operations":[],"create_backup":false}`), &req); err != nil {
+		t.Fatal(err)
+	}
+	if req.CreateBackup {
+		t.Error("explicit create_backup:false must be honored")
+	}
+}
+
+// TestBatchBackup_ListedAndUsedForAtomicRollback verifies the batch backup
+// goes through BackupManager (listed, restorable by ID) and that an atomic
+// failure mid-way restores from it, including same-named files in different
+// directories.
+func TestBatchBackup_ListedAndUsedForAtomicRollback(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a", "config.txt")
+	b := filepath.Join(dir, "b", "config.txt")
+	gone := filepath.Join(dir, "gone.txt")
+	created := filepath.Join(dir, "new.txt")
+	for path, content := range map[string]string{a: "alpha\n", b: "beta\n", gone: "keep me\n"} {
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bm, err := NewBackupManager(t.TempDir(), 10, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mgr := NewBatchOperationManager(t.TempDir(), 10)
+	mgr.SetBackupManager(bm)
+
+	res := mgr.ExecuteBatch(BatchRequest{
+		Atomic:       true,
+		CreateBackup: true,
+		Operations: []FileOperation{
+			{Type: "write", Path: a, Content: "overwritten\n"},
+			{Type: "write", Path: b, Content: "overwritten\n"},
+			{Type: "delete", Path: gone},
+			{Type: "write", Path: created, Content: "new\n"},
+			{Type: "edit", Path: a, OldText: "not present", NewText: "x"},
+		},
+	})
+	if res.Success || !res.RollbackDone {
+		t.Fatalf("expected atomic failure with rollback, got %+v", res)
+	}
+	if res.BackupID == "" {
+		t.Fatal("result has no backup_id")
+	}
+
+	for path, want := range map[string]string{a: "alpha\n", b: "beta\n", gone: "keep me\n"} {
+		if got, err := os.ReadFile(path); err != nil || string(got) != want {
+			t.Errorf("%s after rollback = %q (%v), want %q", path, got, err, want)
+		}
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Errorf("file created by the batch survived rollback: %v", err)
+	}
+
+	backups, err := bm.ListBackups(10, "batch_operations", "", 0)
+	if err != nil || len(backups) != 1 || backups[0].BackupID != res.BackupID || len(backups[0].Files) != 3 {
+		t.Fatalf("ListBackups = %+v (%v), want the batch backup with 3 files", backups, err)
+	}
+}
+
+func TestBatchBackup_SkippedWhenNothingToBackUp(t *testing.T) {
+	dir := t.TempDir()
+	mgr := NewBatchOperationManager(t.TempDir(), 10)
+	res := mgr.ExecuteBatch(BatchRequest{
+		CreateBackup: true,
+		Operations:   []FileOperation{{Type: "create_dir", Path: filepath.Join(dir, "sub")}},
+	})
+	if !res.Success || res.BackupID != "" {
+		t.Errorf("create-only batch: success=%v backup_id=%q, want success and no backup", res.Success, res.BackupID)
+	}
+}
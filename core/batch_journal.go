@@ -0,0 +1,268 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// batchJournalFile is stored next to metadata.json in the backup of a
+// continue_on_error batch.
+const batchJournalFile = "batch_journal.json"
+
+// Batch journal operation statuses
+const (
+	BatchOpApplied = "applied"
+	BatchOpFailed  = "failed"
+	BatchOpSkipped = "skipped" // not run: an earlier operation of its group failed
+)
+
+// BatchOpJournal is the rollback bookkeeping of one batch operation: what a
+// targeted rollback must restore from the batch backup, remove, or move back.
+type BatchOpJournal struct {
+	Index     int      `json:"index"`
+	Group     string   `json:"group"`
+	Type      string   `json:"type"`
+	Status    string   `json:"status"`
+	Restore   []string `json:"restore,omitempty"`    // files to restore from the backup
+	Remove    []string `json:"remove,omitempty"`     // files/dirs the operation created
+	MovedFrom string   `json:"moved_from,omitempty"` // move: original location
+	MovedTo   string   `json:"moved_to,omitempty"`   // move: new location
+}
+
+// BatchJournal is persisted with the batch backup so rollback_batch can
+// revert only the failed groups after the batch call has returned.
+type BatchJournal struct {
+	BackupID     string           `json:"backup_id"`
+	FailedGroups []string         `json:"failed_groups"`
+	Operations   []BatchOpJournal `json:"operations"`
+	RolledBackAt *time.Time       `json:"rolled_back_at,omitempty"`
+}
+
+// BatchRollbackResult reports what RollbackBatch did (or would do)
+type BatchRollbackResult struct {
+	BackupID  string   `json:"backup_id"`
+	Groups    []string `json:"groups"`
+	DryRun    bool     `json:"dry_run"`
+	Restored  []string `json:"restored,omitempty"`
+	Removed   []string `json:"removed,omitempty"`
+	MovedBack []string `json:"moved_back,omitempty"`
+	Kept      []string `json:"kept,omitempty"` // also touched by a successful group; left as is
+	Errors    []string `json:"errors,omitempty"`
+}
+
+// journalEntry converts the rollback data captured before an operation into
+// its persistent journal form.
+func (rb rollbackData) journalEntry() BatchOpJournal {
+	entry := BatchOpJournal{Type: rb.operationType}
+	track := func(path string, created bool) {
+		if path == "" {
+			return
+		}
+		if created {
+			entry.Remove = append(entry.Remove, path)
+		} else {
+			entry.Restore = append(entry.Restore, path)
+		}
+	}
+	switch rb.operationType {
+	case "move":
+		entry.MovedFrom, entry.MovedTo = rb.originalPath, rb.backupPath
+	case "extract":
+		track(rb.originalPath, false)
+		track(rb.secondPath, rb.secondWasCreated)
+	default:
+		track(rb.originalPath, rb.wasCreated)
+	}
+	return entry
+}
+
+// CreateJournalBackup creates a backup entry without files. It holds the
+// journal of a continue_on_error batch that modifies no existing file, so
+// rollback_batch can still remove what the failed groups created.
+func (bm *BackupManager) CreateJournalBackup(operation string, userContext string) (string, error) {
+	bm.mutex.Lock()
+	defer bm.mutex.Unlock()
+
+	backupID := generateBackupID()
+	backupBaseDir := filepath.Join(bm.backupDir, backupID)
+	if err := os.MkdirAll(backupBaseDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	info := BackupInfo{
+		BackupID:    backupID,
+		Timestamp:   time.Now(),
+		Operation:   operation,
+		UserContext: userContext,
+		Files:       []BackupMetadata{},
+	}
+	if err := bm.saveBackupMetadata(backupBaseDir, &info); err != nil {
+		os.RemoveAll(backupBaseDir)
+		return "", fmt.Errorf("failed to save metadata: %w", err)
+	}
+	bm.metadataCache[backupID] = &info
+	return backupID, nil
+}
+
+// SaveBatchJournal stores the journal in its backup directory
+func (bm *BackupManager) SaveBatchJournal(journal *BatchJournal) error {
+	if err := sanitizeBackupID(journal.BackupID); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(journal, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(bm.backupDir, journal.BackupID, batchJournalFile), data, 0600)
+}
+
+// LoadBatchJournal reads the journal of a continue_on_error batch backup
+func (bm *BackupManager) LoadBatchJournal(backupID string) (*BatchJournal, error) {
+	if err := sanitizeBackupID(backupID); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(bm.backupDir, backupID, batchJournalFile))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("backup %s has no batch journal (only batches run with continue_on_error can be rolled back by group; use backup(action:\"restore\") for a full restore)", backupID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var journal BatchJournal
+	if err := json.Unmarshal(data, &journal); err != nil {
+		return nil, fmt.Errorf("invalid batch journal for %s: %w", backupID, err)
+	}
+	return &journal, nil
+}
+
+// RollbackBatch reverts the operations of the failed groups of a
+// continue_on_error batch, newest first: moves are undone, created files are
+// removed and modified files are restored from the batch backup. Paths also
+// touched by a group that succeeded are kept (reported in Kept) so the
+// rollback never undoes successful work. A journal can be rolled back once.
+func (m *BatchOperationManager) RollbackBatch(backupID string, dryRun bool) (*BatchRollbackResult, error) {
+	bm := m.getBackupManager()
+	if bm == nil {
+		return nil, fmt.Errorf("backup manager unavailable (backup dir: %s)", m.backupDir)
+	}
+	journal, err := bm.LoadBatchJournal(backupID)
+	if err != nil {
+		return nil, err
+	}
+	if journal.RolledBackAt != nil {
+		return nil, fmt.Errorf("batch %s was already rolled back at %s", backupID, journal.RolledBackAt.Format(time.RFC3339))
+	}
+
+	result := &BatchRollbackResult{BackupID: backupID, Groups: journal.FailedGroups, DryRun: dryRun}
+	if len(journal.FailedGroups) == 0 {
+		return result, nil
+	}
+	failed := make(map[string]bool, len(journal.FailedGroups))
+	for _, g := range journal.FailedGroups {
+		failed[g] = true
+	}
+
+	// Paths the successful groups depend on
+	protected := make(map[string]bool)
+	for _, op := range journal.Operations {
+		if failed[op.Group] || op.Status != BatchOpApplied {
+			continue
+		}
+		for _, p := range append(append(append([]string{}, op.Restore...), op.Remove...), op.MovedFrom, op.MovedTo) {
+			if p != "" {
+				protected[p] = true
+			}
+		}
+	}
+	keep := func(path string) bool {
+		if protected[path] {
+			result.Kept = append(result.Kept, path)
+			return true
+		}
+		return false
+	}
+
+	var restore []string
+	for i := len(journal.Operations) - 1; i >= 0; i-- {
+		op := journal.Operations[i]
+		if !failed[op.Group] || op.Status == BatchOpSkipped {
+			continue
+		}
+		if op.MovedTo != "" && !keep(op.MovedTo) && !keep(op.MovedFrom) {
+			if _, statErr := os.Stat(op.MovedTo); statErr == nil {
+				if !dryRun {
+					if err := os.Rename(op.MovedTo, op.MovedFrom); err != nil {
+						result.Errors = append(result.Errors, fmt.Sprintf("move back %s: %v", op.MovedTo, err))
+						continue
+					}
+					m.invalidateRestored(op.MovedTo)
+					m.invalidateRestored(op.MovedFrom)
+				}
+				result.MovedBack = append(result.MovedBack, op.MovedTo+" -> "+op.MovedFrom)
+			}
+		}
+		for _, p := range op.Remove {
+			if keep(p) {
+				continue
+			}
+			if _, statErr := os.Stat(p); statErr != nil {
+				continue
+			}
+			if !dryRun {
+				if err := os.Remove(p); err != nil {
+					result.Errors = append(result.Errors, fmt.Sprintf("remove %s: %v", p, err))
+					continue
+				}
+				m.invalidateRestored(p)
+			}
+			result.Removed = append(result.Removed, p)
+		}
+		for _, p := range op.Restore {
+			if !keep(p) {
+				restore = append(restore, p)
+			}
+		}
+	}
+
+	seen := make(map[string]bool, len(restore))
+	for _, p := range restore {
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		if !dryRun {
+			if _, _, err := bm.RestoreBackup(backupID, p, false); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("restore %s: %v", p, err))
+				continue
+			}
+			m.invalidateRestored(p)
+			refreshKnownHashPath(p)
+		}
+		result.Restored = append(result.Restored, p)
+	}
+	sort.Strings(result.Kept)
+	result.Kept = dedupSorted(result.Kept)
+
+	if !dryRun {
+		now := time.Now()
+		journal.RolledBackAt = &now
+		if err := bm.SaveBatchJournal(journal); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("failed to mark journal rolled back: %v", err))
+		}
+	}
+	return result, nil
+}
+
+// dedupSorted removes adjacent duplicates from a sorted slice
+func dedupSorted(items []string) []string {
+	out := items[:0]
+	for i, s := range items {
+		if i == 0 || s != items[i-1] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestBatchContinueOnError_RollbackFailedGroups runs independent groups past a
+// failure and checks backup(action:"rollback_batch") reverts only the failed group.
+func TestBatchContinueOnError_RollbackFailedGroups(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	mgr := NewBatchOperationManager(t.TempDir(), 10)
+	mgr.SetBackupManager(engine.GetBackupManager())
+	mgr.SetEngine(engine)
+
+	api := filepath.Join(dir, "api.go")
+	docs := filepath.Join(dir, "docs.md")
+	created := filepath.Join(dir, "api_v2.go")
+	os.WriteFile(api, []byte("func Old() {}\n"), 0644)
+	os.WriteFile(docs, []byte("Old docs\n"), 0644)
+
+	res := mgr.ExecuteBatch(BatchRequest{
+		ContinueOnError: true,
+		Operations: []FileOperation{
+			{Type: "edit", Path: api, OldText: "Old", NewText: "New", Group: "api"},
+			{Type: "write", Path: created, Content: "package api\n", Group: "api"},
+			{Type: "edit", Path: api, OldText: "not present", NewText: "x", Group: "api"},
+			{Type: "edit", Path: api, OldText: "New", NewText: "Newer", Group: "api"},
+			{Type: "edit", Path: docs, OldText: "Old", NewText: "New"},
+		},
+	})
+	if res.Success || res.FailedOps != 1 || res.SkippedOps != 1 || res.CompletedOps != 3 {
+		t.Fatalf("result = %+v", res)
+	}
+	if len(res.FailedGroups) != 1 || res.FailedGroups[0] != "api" || !res.Results[3].Skipped {
+		t.Fatalf("failed groups = %v, op 3 = %+v", res.FailedGroups, res.Results[3])
+	}
+	if got, _ := os.ReadFile(docs); string(got) != "New docs\n" {
+		t.Fatalf("independent op did not run: %q", got)
+	}
+
+	preview, err := mgr.RollbackBatch(res.BackupID, true)
+	if err != nil || len(preview.Restored) != 1 || len(preview.Removed) != 1 {
+		t.Fatalf("dry run = %+v (%v)", preview, err)
+	}
+	if _, err := os.Stat(created); err != nil {
+		t.Fatal("dry run removed a file")
+	}
+
+	rb, err := mgr.RollbackBatch(res.BackupID, false)
+	if err != nil || len(rb.Errors) > 0 {
+		t.Fatalf("rollback = %+v (%v)", rb, err)
+	}
+	if got, _ := os.ReadFile(api); string(got) != "func Old() {}\n" {
+		t.Errorf("failed group not restored: %q", got)
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Errorf("file created by the failed group survived: %v", err)
+	}
+	if got, _ := os.ReadFile(docs); string(got) != "New docs\n" {
+		t.Errorf("successful group was reverted: %q", got)
+	}
+
+	if _, err := mgr.RollbackBatch(res.BackupID, false); err == nil || !strings.Contains(err.Error(), "already rolled back") {
+		t.Errorf("second rollback: %v", err)
+	}
+}
+
+func TestBatchContinueOnError_GuardsAndSharedPaths(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	mgr := NewBatchOperationManager(t.TempDir(), 10)
+	mgr.SetBackupManager(engine.GetBackupManager())
+	mgr.SetEngine(engine)
+
+	shared := filepath.Join(dir, "shared.txt")
+	os.WriteFile(shared, []byte("v1\n"), 0644)
+
+	res := mgr.ExecuteBatch(BatchRequest{
+		Atomic:          true,
+		ContinueOnError: true,
+		Operations:      []FileOperation{{Type: "write", Path: shared, Content: "x"}},
+	})
+	if res.Success || !strings.Contains(strings.Join(res.Errors, " "), "mutually exclusive") {
+		t.Errorf("atomic + continue_on_error accepted: %+v", res)
+	}
+
+	// Group "a" fails after touching shared.txt, which group "b" also
+	// changed successfully: the rollback keeps b's version.
+	res = mgr.ExecuteBatch(BatchRequest{
+		ContinueOnError: true,
+		CreateBackup:    false,
+		Operations: []FileOperation{
+			{Type: "edit", Path: shared, OldText: "v1", NewText: "v2", Group: "a"},
+			{Type: "append", Path: shared, Content: "b\n", Group: "b"},
+			{Type: "create_dir", Path: filepath.Join(dir, "out"), Group: "a"},
+			{Type: "edit", Path: shared, OldText: "missing", NewText: "x", Group: "a"},
+		},
+	})
+	if res.BackupID == "" || len(res.Journal) != 4 {
+		t.Fatalf("continue_on_error needs a backup and a journal: %+v", res)
+	}
+	rb, err := mgr.RollbackBatch(res.BackupID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rb.Kept) != 1 || rb.Kept[0] != shared || len(rb.Removed) != 1 {
+		t.Errorf("rollback = %+v, want shared.txt kept and out/ removed", rb)
+	}
+	if got, _ := os.ReadFile(shared); string(got) != "v2\nb\n" {
+		t.Errorf("shared file = %q", got)
+	}
+
+	if _, err := mgr.RollbackBatch("20260101-000000-deadbeef", false); err == nil {
+		t.Error("rollback of an unknown backup succeeded")
+	}
+}
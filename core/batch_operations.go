@@ -4,6 +4,7 @@
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
@@ -13,16 +14,18 @@
 
 // FileOperation representa una operación individual en un batch
 type FileOperation struct {
-	Type        string                 `json:"type"`        // write, edit, search_and_replace, move, delete, create_dir, copy, extract
+	Type        string                 `json:"type"`        // write, append, edit, search_and_replace, replace_nth, move, delete, create_dir, copy, extract
 	Path        string                 `json:"path"`        // Ruta principal
 	Source      string                 `json:"source"`      // Para move/copy/extract (archivo origen)
 	Destination string                 `json:"destination"` // Para move/copy/extract (archivo destino)
-	Content     string                 `json:"content"`     // Para write
-	OldText     string                 `json:"old_text"`    // Para edit
-	NewText     string                 `json:"new_text"`    // Para edit
+	Content     string                 `json:"content"`     // Para write/append
+	OldText     string                 `json:"old_text"`    // Para edit (pattern en replace_nth)
+	NewText     string                 `json:"new_text"`    // Para edit (replacement en replace_nth)
+	Occurrence  int                    `json:"occurrence"`  // Para replace_nth: 1=primera, 2=segunda, -1=última, -2=penúltima
 	StartLine   int                    `json:"start_line"`  // Para extract: primera línea (1-based, inclusive)
 	EndLine     int                    `json:"end_line"`    // Para extract: última línea (1-based, inclusive)
 	Append      bool                   `json:"append"`      // Para extract: añadir al destino en vez de sobrescribir
+	Group       string                 `json:"group"`       // Para continue_on_error: ops dependientes comparten grupo
 	Options     map[string]interface{} `json:"options"`     // Opciones adicionales
 }
 
@@ -67,9 +70,26 @@ func (op *FileOperation) UnmarshalJSON(data []byte) error {
 type BatchRequest struct {
 	Operations   []FileOperation `json:"operations"`
 	Atomic       bool            `json:"atomic"`        // Si es true, se hace rollback en caso de error
-	CreateBackup bool            `json:"create_backup"` // Crear backup antes de ejecutar
+	CreateBackup bool            `json:"create_backup"` // Crear backup antes de ejecutar (JSON default: true)
 	ValidateOnly bool            `json:"validate_only"` // Solo validar, no ejecutar
 	Force        bool            `json:"force"`         // Bypass risk validation warnings
+	MaxMatches   int             `json:"max_matches"`   // Máximo de archivos por glob en path/source (default DefaultBatchGlobMaxMatches)
+	// Si es true, los grupos independientes siguen ejecutándose tras un fallo;
+	// rollback_batch(backup_id) revierte después solo los grupos fallidos
+	ContinueOnError bool `json:"continue_on_error"`
+}
+
+// UnmarshalJSON defaults create_backup to true when the field is absent, so
+// destructive batches sent as JSON are recoverable via backup(action:"restore")
+// unless the caller opts out explicitly.
+func (r *BatchRequest) UnmarshalJSON(data []byte) error {
+	type plain BatchRequest
+	aux := plain{CreateBackup: true}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	*r = BatchRequest(aux)
+	return nil
 }
 
 // BatchResult representa el resultado de ejecutar un batch
@@ -79,14 +99,26 @@ type BatchResult struct {
 	CompletedOps   int               `json:"completed_operations"`
 	FailedOps      int               `json:"failed_operations"`
 	Results        []OperationResult `json:"results"`
-	BackupPath     string            `json:"backup_path,omitempty"`
-	BackupID       string            `json:"backup_id,omitempty"` // New: ID from BackupManager
+	BackupID       string            `json:"backup_id,omitempty"` // BackupManager ID (list_backups / backup restore)
 	RollbackDone   bool              `json:"rollback_done"`
 	ExecutionTime  string            `json:"execution_time"`
 	ValidationOnly bool              `json:"validation_only"`
 	Errors         []string          `json:"errors,omitempty"`
 	RiskLevel      string            `json:"risk_level,omitempty"`   // New: Batch risk assessment
 	RiskWarning    string            `json:"risk_warning,omitempty"` // New: Risk warning message
+	GlobExpansions []GlobExpansion   `json:"glob_expansions,omitempty"`
+	SkippedOps     int               `json:"skipped_operations,omitempty"` // continue_on_error: ops no ejecutadas por fallo de su grupo
+	FailedGroups   []string          `json:"failed_groups,omitempty"`      // continue_on_error: grupos con alguna op fallida
+	Journal        []BatchOpJournal  `json:"journal,omitempty"`            // continue_on_error: bookkeeping por op para rollback_batch
+}
+
+// GlobExpansion records how a glob in an operation's path/source expanded.
+// Results (and their indexes) refer to the expanded operation list.
+type GlobExpansion struct {
+	Index   int      `json:"index"`   // Index of the operation in the request
+	Field   string   `json:"field"`   // "path" or "source"
+	Pattern string   `json:"pattern"` // Original glob
+	Matches []string `json:"matches"` // Files it expanded to, in order
 }
 
 // OperationResult representa el resultado de una operación individual
@@ -94,10 +126,14 @@ type OperationResult struct {
 	Index         int    `json:"index"`
 	Type          string `json:"type"`
 	Path          string `json:"path"`
+	Group         string `json:"group,omitempty"`
 	Success       bool   `json:"success"`
 	Error         string `json:"error,omitempty"`
 	Skipped       bool   `json:"skipped,omitempty"`
 	BytesAffected int64  `json:"bytes_affected,omitempty"`
+	// validate_only: lo que haría la op según la simulación del batch
+	Findings             []string `json:"findings,omitempty"`
+	ExpectedReplacements int      `json:"expected_replacements,omitempty"`
 }
 
 // BatchOperationManager maneja operaciones en batch con soporte para rollback
@@ -106,7 +142,6 @@ type BatchOperationManager struct {
 	backupManager *BackupManager // Shared backup manager (统一backup系统)
 	maxBackups    int
 	mutex         sync.Mutex
-	currentBackup string
 	engine        *UltraFastEngine // Reference to engine for intelligent edit (Bug #18)
 }
 
@@ -148,8 +183,15 @@ func (m *BatchOperationManager) allowedDirsSuffix() string {
 // This ensures hooks are respected even when using the batch manager's low-level execution path.
 // Returns error only if a hook denied the operation.
 func (m *BatchOperationManager) executeHooksForOperation(ctx context.Context, event HookEvent, op FileOperation) error {
-	if m.engine == nil || m.engine.hookManager == nil || !m.engine.hookManager.IsEnabled() {
-		return nil
+	_, err := m.hookResultForOperation(ctx, event, op)
+	return err
+}
+
+// hookResultForOperation is executeHooksForOperation returning the aggregated
+// hook result too (ModifiedContent from content hooks); nil when hooks are off.
+func (m *BatchOperationManager) hookResultForOperation(ctx context.Context, event HookEvent, op FileOperation) (*HookResult, error) {
+	if m.engine == nil || m.engine.hookManager == nil || !m.engine.hookManager.IsActive() {
+		return nil, nil
 	}
 
 	workingDir, _ := os.Getwd()
@@ -161,12 +203,12 @@ func (m *BatchOperationManager) executeHooksForOperation(ctx context.Context, ev
 		SourcePath: op.Source,
 		DestPath:   op.Destination,
 		Content:    op.Content,
+		NewContent: op.NewText,
 		Timestamp:  time.Now(),
 		WorkingDir: workingDir,
 	}
 
-	_, err := m.engine.hookManager.ExecuteHooks(ctx, event, hookCtx)
-	return err
+	return m.engine.hookManager.ExecuteHooks(ctx, event, hookCtx)
 }
 
 // ExecuteBatch ejecuta un batch de operaciones
@@ -179,101 +221,256 @@ func (m *BatchOperationManager) ExecuteBatch(request BatchRequest) BatchResult {
 		ValidationOnly: request.ValidateOnly,
 	}
 
-	// Paso 1: Validar todas las operaciones
-	validationErrors := m.validateOperations(request.Operations)
-	if len(validationErrors) > 0 {
+	// Paso 0: Expandir globs en path/source (antes de validar, para que cada
+	// archivo resultante pase por la validación normal)
+	operations, expansions, globErrors := m.expandGlobs(request.Operations, request.MaxMatches)
+	result.GlobExpansions = expansions
+	if len(globErrors) > 0 {
 		result.Success = false
-		result.Errors = validationErrors
+		result.Errors = globErrors
 		result.ExecutionTime = time.Since(startTime).String()
 		return result
 	}
+	request.Operations = operations
+	result.TotalOps = len(operations)
 
-	// Si solo es validación, retornar aquí
+	// Paso 1: Validar todas las operaciones
+	validationErrors, opErrors := m.validateOperationsByIndex(request.Operations)
+	if request.Atomic && request.ContinueOnError {
+		validationErrors = append(validationErrors, "atomic and continue_on_error are mutually exclusive: atomic rolls everything back on the first failure, continue_on_error keeps going and lets rollback_batch revert the failed groups")
+	}
+
+	// Si solo es validación, simular el batch sin escribir (recuentos de
+	// reemplazos, sobrescrituras, conflictos) y retornar aquí
 	if request.ValidateOnly {
-		result.Success = true
-		result.ExecutionTime = time.Since(startTime).String()
-		for i, op := range request.Operations {
-			result.Results = append(result.Results, OperationResult{
-				Index:   i,
-				Type:    op.Type,
-				Path:    op.Path,
-				Success: true,
-			})
+		results, problems := m.previewOperations(request.Operations, opErrors)
+		result.Results = results
+		if errs := append(validationErrors, problems...); len(errs) > 0 {
+			result.Errors = errs
 		}
+		result.Success = len(result.Errors) == 0
+		result.ExecutionTime = time.Since(startTime).String()
+		return result
+	}
+
+	if len(validationErrors) > 0 {
+		result.Success = false
+		result.Errors = validationErrors
+		result.ExecutionTime = time.Since(startTime).String()
 		return result
 	}
 
-	// Paso 2: Crear backup si se solicita
-	if request.CreateBackup {
-		backupPath, err := m.createBackup(request.Operations)
+	// Paso 2: Crear backup si se solicita. continue_on_error siempre lo
+	// necesita: el journal para rollback_batch se guarda junto al backup.
+	if request.CreateBackup || request.ContinueOnError {
+		backupID, err := m.createBackup(request.Operations)
+		if err == nil && backupID == "" && request.ContinueOnError {
+			if bm := m.getBackupManager(); bm != nil {
+				backupID, err = bm.CreateJournalBackup("batch_operations",
+					fmt.Sprintf("Batch: %d operations (journal only)", len(request.Operations)))
+			}
+		}
 		if err != nil {
 			result.Success = false
 			result.Errors = []string{fmt.Sprintf("Failed to create backup: %v", err)}
 			result.ExecutionTime = time.Since(startTime).String()
 			return result
 		}
-		result.BackupPath = backupPath
-		m.currentBackup = backupPath
+		result.BackupID = backupID
 	}
 
-	// Paso 3: Ejecutar operaciones
+	// Paso 3: Ejecutar operaciones. Las independientes se ejecutan en paralelo
+	// por oleadas (hasta ParallelOps); las que comparten rutas o grupo
+	// mantienen el orden del batch.
+	outcomes := make([]batchOpOutcome, len(request.Operations))
 	rollbackInfo := make([]rollbackData, 0, len(request.Operations))
-
-	for i, op := range request.Operations {
-		opResult := OperationResult{
-			Index: i,
-			Type:  op.Type,
-			Path:  op.Path,
+	failedGroups := make(map[string]bool)
+	failedAt := -1
+
+	for _, wave := range m.planBatchWaves(request) {
+		// El servidor se está apagando: no se lanzan más oleadas. La primera
+		// op pendiente cuenta como fallida para que el batch atómico haga
+		// rollback y continue_on_error marque su grupo para rollback_batch.
+		if m.engine != nil && m.engine.ShuttingDown() {
+			i := wave[0]
+			op := request.Operations[i]
+			out := batchOpOutcome{ran: true, err: ErrShuttingDown,
+				result: OperationResult{Index: i, Type: op.Type, Path: op.Path, Error: "not run: " + ErrShuttingDown.Error()}}
+			if request.ContinueOnError {
+				out.result.Group = batchOpGroup(op, i)
+				out.entry = BatchOpJournal{Index: i, Group: out.result.Group, Type: op.Type, Status: BatchOpSkipped}
+			}
+			outcomes[i] = out
+			if failedAt < 0 {
+				failedAt = i
+			}
+			break
 		}
 
-		// Guardar información para rollback
-		var rbData rollbackData
-		if request.Atomic {
-			rbData = m.prepareRollback(op)
+		m.runBatchWave(wave, func(i int) {
+			outcomes[i] = m.runBatchOperation(i, request.Operations[i], request, result.BackupID, failedGroups)
+		})
+
+		// La oleada ya terminó: no queda ninguna op en curso
+		for _, i := range wave {
+			out := outcomes[i]
+			if out.err != nil {
+				failedGroups[out.result.Group] = true
+				if failedAt < 0 {
+					failedAt = i
+				}
+			} else if request.Atomic {
+				rollbackInfo = append(rollbackInfo, out.rb)
+			}
 		}
+		// Si es atómico y falla, no se lanzan más oleadas
+		if request.Atomic && failedAt >= 0 {
+			break
+		}
+	}
 
-		// Ejecutar operación
-		err := m.executeOperation(op, &opResult)
-
-		if err != nil {
-			opResult.Success = false
-			opResult.Error = err.Error()
+	// Resultados en el orden original del batch
+	var applied []FileOperation
+	reportedGroups := make(map[string]bool)
+	for i, out := range outcomes {
+		if !out.ran {
+			continue
+		}
+		switch {
+		case out.result.Skipped:
+			result.SkippedOps++
+		case out.err != nil:
 			result.FailedOps++
-
-			// Si es atómico y falla, hacer rollback
-			if request.Atomic {
-				result.RollbackDone = true
-				m.rollback(rollbackInfo)
-				result.Success = false
-				result.Errors = []string{fmt.Sprintf("Operation %d failed, rollback completed: %v", i, err)}
-				result.Results = append(result.Results, opResult)
-				result.ExecutionTime = time.Since(startTime).String()
-				return result
+			if request.ContinueOnError && !reportedGroups[out.result.Group] {
+				reportedGroups[out.result.Group] = true
+				result.FailedGroups = append(result.FailedGroups, out.result.Group)
 			}
-		} else {
-			opResult.Success = true
+		default:
 			result.CompletedOps++
-			if request.Atomic {
-				rollbackInfo = append(rollbackInfo, rbData)
-			}
+			applied = append(applied, request.Operations[i])
+		}
+		if request.ContinueOnError {
+			result.Journal = append(result.Journal, out.entry)
 		}
+		result.Results = append(result.Results, out.result)
+	}
 
-		result.Results = append(result.Results, opResult)
+	// Rollback atómico, una vez asentadas todas las operaciones
+	if request.Atomic && failedAt >= 0 {
+		result.RollbackDone = true
+		m.rollback(rollbackInfo, result.BackupID)
+		for i, out := range outcomes {
+			if out.ran {
+				m.invalidateOperationPaths(request.Operations[i])
+			}
+		}
+		result.Success = false
+		result.Errors = []string{fmt.Sprintf("Operation %d failed, rollback completed: %v", failedAt, outcomes[failedAt].err)}
+		result.ExecutionTime = time.Since(startTime).String()
+		return result
 	}
 
 	result.Success = result.FailedOps == 0
 	result.ExecutionTime = time.Since(startTime).String()
+	m.logAppliedOperations(applied, result.BackupID, request.Force, startTime)
 
 	// New point 4 fix: refresh the auto-OCC baseline for files this batch wrote,
 	// so a later edit_file isn't falsely flagged as an external change (the batch
 	// is the session's own modification).
 	if result.Success {
 		m.refreshKnownHashes(request.Operations)
+	} else if request.ContinueOnError {
+		m.refreshKnownHashes(applied)
+	}
+
+	// Paso 4: Persistir el journal para rollback_batch
+	if request.ContinueOnError && result.BackupID != "" {
+		journal := &BatchJournal{
+			BackupID:     result.BackupID,
+			FailedGroups: result.FailedGroups,
+			Operations:   result.Journal,
+		}
+		if err := m.getBackupManager().SaveBatchJournal(journal); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("failed to save batch journal, rollback_batch unavailable: %v", err))
+		}
 	}
 
 	return result
 }
 
+// expandGlobs replaces operations whose path (delete, edit,
+// search_and_replace, replace_nth, append) or source (copy, move) is a glob
+// with one operation per matching file. copy/move globs need a destination
+// directory; each file keeps its base name there. Paths that exist literally
+// are never expanded, and matches are confined to the allowed paths.
+func (m *BatchOperationManager) expandGlobs(operations []FileOperation, maxMatches int) ([]FileOperation, []GlobExpansion, []string) {
+	if maxMatches <= 0 {
+		maxMatches = DefaultBatchGlobMaxMatches
+	}
+	var allowed func(string) bool
+	if m.engine != nil && len(m.engine.GetAllowedPaths()) > 0 {
+		allowed = m.engine.IsPathAllowed
+	}
+	isGlob := func(p string) bool {
+		if !isGlobPattern(p) {
+			return false
+		}
+		_, err := os.Stat(p)
+		return err != nil
+	}
+
+	expanded := make([]FileOperation, 0, len(operations))
+	var expansions []GlobExpansion
+	var errors []string
+	for i, op := range operations {
+		field, pattern := "", ""
+		switch op.Type {
+		case "delete", "edit", "search_and_replace", "replace_nth", "append":
+			if isGlob(op.Path) {
+				field, pattern = "path", op.Path
+			}
+		case "copy", "move":
+			if isGlob(op.Source) {
+				field, pattern = "source", op.Source
+			}
+		default:
+			for _, p := range m.collectPaths(op) {
+				if isGlob(p) {
+					errors = append(errors, fmt.Sprintf("Op %d: glob patterns are not supported for %s (only path of delete/edit/search_and_replace/replace_nth/append and source of copy/move): %s", i, op.Type, p))
+				}
+			}
+		}
+		if field == "" {
+			expanded = append(expanded, op)
+			continue
+		}
+
+		if field == "source" {
+			if info, err := os.Stat(op.Destination); err != nil || !info.IsDir() {
+				errors = append(errors, fmt.Sprintf("Op %d: destination must be an existing directory when source is a glob: %s", i, op.Destination))
+				continue
+			}
+		}
+		matches, err := expandPathGlob(pattern, maxMatches, allowed)
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("Op %d: %v", i, err))
+			continue
+		}
+		expansions = append(expansions, GlobExpansion{Index: i, Field: field, Pattern: pattern, Matches: matches})
+		for _, match := range matches {
+			clone := op
+			if field == "path" {
+				clone.Path = match
+			} else {
+				clone.Source = match
+				clone.Destination = filepath.Join(op.Destination, filepath.Base(match))
+			}
+			expanded = append(expanded, clone)
+		}
+	}
+	return expanded, expansions, errors
+}
+
 // refreshKnownHashes updates the auto-OCC baseline for files modified by a batch.
 // Existing files get their new on-disk hash recorded; removed/moved-away files
 // have their baseline cleared. Without this, a file the session had read and
@@ -281,7 +478,7 @@ func (m *BatchOperationManager) ExecuteBatch(request BatchRequest) BatchResult {
 func (m *BatchOperationManager) refreshKnownHashes(ops []FileOperation) {
 	for _, op := range ops {
 		switch op.Type {
-		case "write", "edit", "search_and_replace":
+		case "write", "append", "edit", "search_and_replace", "replace_nth":
 			refreshKnownHashPath(op.Path)
 		case "extract":
 			refreshKnownHashPath(op.Source)
@@ -299,19 +496,56 @@ func (m *BatchOperationManager) refreshKnownHashes(ops []FileOperation) {
 
 // validateOperations valida que todas las operaciones sean ejecutables
 func (m *BatchOperationManager) validateOperations(operations []FileOperation) []string {
+	errors, _ := m.validateOperationsByIndex(operations)
+	return errors
+}
+
+// validateOperationsByIndex valida el batch y devuelve además los errores
+// agrupados por operación (validate_only los muestra junto a cada op).
+func (m *BatchOperationManager) validateOperationsByIndex(operations []FileOperation) ([]string, map[int][]string) {
 	errors := make([]string, 0)
+	byOp := make(map[int][]string)
+	fail := func(i int, format string, args ...interface{}) {
+		msg := fmt.Sprintf(format, args...)
+		errors = append(errors, fmt.Sprintf("Op %d: %s", i, msg))
+		byOp[i] = append(byOp[i], msg)
+	}
 
 	// Track paths that will be created/available by earlier ops in this batch
 	// so that write→edit, create_dir→write, copy→edit chains validate correctly.
 	pendingPaths := make(map[string]bool)
+	// Rutas que una op anterior elimina o mueve (ruta -> índice de esa op):
+	// detecta conflictos como "op 3 borra el archivo que edita op 5".
+	removedBy := make(map[string]int)
 
 	for i, op := range operations {
+		var input string
+		switch op.Type {
+		case "edit", "search_and_replace", "replace_nth", "delete":
+			input = op.Path
+		case "move", "copy", "extract":
+			input = op.Source
+		}
+		if j, ok := removedBy[input]; ok && input != "" {
+			fail(i, "%s is deleted or moved away by op %d earlier in this batch", input, j)
+		}
+		switch op.Type {
+		case "delete":
+			removedBy[op.Path] = i
+		case "move":
+			removedBy[op.Source] = i
+			delete(removedBy, op.Destination)
+		case "copy", "extract":
+			delete(removedBy, op.Destination)
+		default:
+			delete(removedBy, op.Path)
+		}
 		// Security: enforce allowed-paths on every path in the operation.
 		// Without this check, batch operations bypass --allowed-paths access control.
-		if m.engine != nil && len(m.engine.config.AllowedPaths) > 0 {
+		if m.engine != nil && len(m.engine.GetAllowedPaths()) > 0 {
 			for _, p := range m.collectPaths(op) {
 				if p != "" && !m.engine.IsPathAllowed(p) {
-					errors = append(errors, fmt.Sprintf("Op %d: access denied — path '%s' is not in allowed paths%s", i, p, m.allowedDirsSuffix()))
+					fail(i, "access denied — path '%s' is not in allowed paths%s", p, m.allowedDirsSuffix())
 				}
 			}
 			// Prevent destructive operations on allowed-path roots
@@ -321,107 +555,140 @@ func (m *BatchOperationManager) validateOperations(operations []FileOperation) [
 					target = op.Source
 				}
 				if target != "" && m.engine.IsAllowedPathRoot(target) {
-					errors = append(errors, fmt.Sprintf("Op %d: access denied — cannot %s allowed-path root '%s'%s", i, op.Type, target, m.allowedDirsSuffix()))
+					fail(i, "access denied — cannot %s allowed-path root '%s'%s", op.Type, target, m.allowedDirsSuffix())
 				}
 			}
 		}
+		if m.engine != nil {
+			if err := m.checkProtected(op); err != nil {
+				fail(i, "%v", err)
+			}
+		}
 
 		switch op.Type {
 		case "write":
 			if op.Path == "" {
-				errors = append(errors, fmt.Sprintf("Op %d: path is required for write", i))
+				fail(i, "path is required for write")
 			}
 			// Validar que el directorio padre existe o será creado por una op anterior del batch
 			dir := filepath.Dir(op.Path)
 			if _, err := os.Stat(dir); os.IsNotExist(err) && !pendingPaths[dir] {
-				errors = append(errors, fmt.Sprintf("Op %d: parent directory does not exist: %s", i, dir))
+				fail(i, "parent directory does not exist: %s", dir)
 			}
 			pendingPaths[op.Path] = true // este archivo existirá tras la op
 
+		case "append":
+			if op.Path == "" {
+				fail(i, "path is required for append")
+			}
+			if op.Content == "" {
+				fail(i, "content is required for append")
+			}
+			// Como write: el archivo se crea si no existe, el directorio padre no
+			dir := filepath.Dir(op.Path)
+			if _, err := os.Stat(dir); os.IsNotExist(err) && !pendingPaths[dir] {
+				fail(i, "parent directory does not exist: %s", dir)
+			}
+			pendingPaths[op.Path] = true
+
+		case "replace_nth":
+			if op.Path == "" {
+				fail(i, "path is required for replace_nth")
+			}
+			if op.OldText == "" {
+				fail(i, "pattern is required for replace_nth")
+			}
+			if op.Occurrence == 0 {
+				fail(i, "occurrence is required for replace_nth (1=first, -1=last)")
+			}
+			if _, err := os.Stat(op.Path); os.IsNotExist(err) && !pendingPaths[op.Path] {
+				fail(i, "file does not exist: %s", op.Path)
+			}
+
 		case "edit":
 			if op.Path == "" {
-				errors = append(errors, fmt.Sprintf("Op %d: path is required for edit", i))
+				fail(i, "path is required for edit")
 			}
 			if op.OldText == "" && op.NewText == "" {
-				errors = append(errors, fmt.Sprintf("Op %d: old_text or new_text required for edit", i))
+				fail(i, "old_text or new_text required for edit")
 			}
 			// Permitir si una op anterior del batch crea el archivo
 			if _, err := os.Stat(op.Path); os.IsNotExist(err) && !pendingPaths[op.Path] {
-				errors = append(errors, fmt.Sprintf("Op %d: file does not exist: %s", i, op.Path))
+				fail(i, "file does not exist: %s", op.Path)
 			}
 
 		case "search_and_replace":
 			if op.Path == "" {
-				errors = append(errors, fmt.Sprintf("Op %d: path is required for search_and_replace", i))
+				fail(i, "path is required for search_and_replace")
 			}
 			if op.OldText == "" {
-				errors = append(errors, fmt.Sprintf("Op %d: old_text (pattern) is required for search_and_replace", i))
+				fail(i, "old_text (pattern) is required for search_and_replace")
 			}
 			if _, err := os.Stat(op.Path); os.IsNotExist(err) && !pendingPaths[op.Path] {
-				errors = append(errors, fmt.Sprintf("Op %d: path does not exist: %s", i, op.Path))
+				fail(i, "path does not exist: %s", op.Path)
 			}
 
 		case "move":
 			if op.Source == "" || op.Destination == "" {
-				errors = append(errors, fmt.Sprintf("Op %d: source and destination required for move", i))
+				fail(i, "source and destination required for move")
 			}
 			if _, err := os.Stat(op.Source); os.IsNotExist(err) && !pendingPaths[op.Source] {
-				errors = append(errors, fmt.Sprintf("Op %d: source does not exist: %s", i, op.Source))
+				fail(i, "source does not exist: %s", op.Source)
 			}
 			// Validar que el destino no existe ni será creado por una op anterior
 			if _, err := os.Stat(op.Destination); err == nil && !pendingPaths[op.Destination] {
-				errors = append(errors, fmt.Sprintf("Op %d: destination already exists: %s", i, op.Destination))
+				fail(i, "destination already exists: %s", op.Destination)
 			}
 			pendingPaths[op.Destination] = true // el archivo existirá en el destino
 			delete(pendingPaths, op.Source)     // ya no estará en el origen
 
 		case "copy":
 			if op.Source == "" || op.Destination == "" {
-				errors = append(errors, fmt.Sprintf("Op %d: source and destination required for copy", i))
+				fail(i, "source and destination required for copy")
 			}
 			if _, err := os.Stat(op.Source); os.IsNotExist(err) && !pendingPaths[op.Source] {
-				errors = append(errors, fmt.Sprintf("Op %d: source does not exist: %s", i, op.Source))
+				fail(i, "source does not exist: %s", op.Source)
 			}
 			pendingPaths[op.Destination] = true // la copia existirá
 
 		case "delete":
 			if op.Path == "" {
-				errors = append(errors, fmt.Sprintf("Op %d: path is required for delete", i))
+				fail(i, "path is required for delete")
 			}
 			if _, err := os.Stat(op.Path); os.IsNotExist(err) && !pendingPaths[op.Path] {
-				errors = append(errors, fmt.Sprintf("Op %d: file does not exist: %s", i, op.Path))
+				fail(i, "file does not exist: %s", op.Path)
 			}
 			delete(pendingPaths, op.Path) // ya no estará disponible
 
 		case "create_dir":
 			if op.Path == "" {
-				errors = append(errors, fmt.Sprintf("Op %d: path is required for create_dir", i))
+				fail(i, "path is required for create_dir")
 			}
 			// Validar que el directorio no existe ya (en disco ni pendiente)
 			if _, err := os.Stat(op.Path); err == nil {
-				errors = append(errors, fmt.Sprintf("Op %d: directory already exists: %s", i, op.Path))
+				fail(i, "directory already exists: %s", op.Path)
 			}
 			pendingPaths[op.Path] = true // el directorio existirá tras la op
 
 		case "extract":
 			// extract: move lines [start_line, end_line] from source to destination (point 4)
 			if op.Source == "" || op.Destination == "" {
-				errors = append(errors, fmt.Sprintf("Op %d: source and destination required for extract", i))
+				fail(i, "source and destination required for extract")
 			}
 			if op.StartLine < 1 || op.EndLine < op.StartLine {
-				errors = append(errors, fmt.Sprintf("Op %d: extract requires start_line>=1 and end_line>=start_line (got %d..%d)", i, op.StartLine, op.EndLine))
+				fail(i, "extract requires start_line>=1 and end_line>=start_line (got %d..%d)", op.StartLine, op.EndLine)
 			}
 			if _, err := os.Stat(op.Source); os.IsNotExist(err) && !pendingPaths[op.Source] {
-				errors = append(errors, fmt.Sprintf("Op %d: source does not exist: %s", i, op.Source))
+				fail(i, "source does not exist: %s", op.Source)
 			}
 			pendingPaths[op.Destination] = true // el destino existirá tras la op
 
 		default:
-			errors = append(errors, fmt.Sprintf("Op %d: unknown operation type: %s", i, op.Type))
+			fail(i, "unknown operation type: %s", op.Type)
 		}
 	}
 
-	return errors
+	return errors, byOp
 }
 
 // rollbackData contiene información necesaria para revertir una operación
@@ -439,27 +706,36 @@ type rollbackData struct {
 }
 
 // prepareRollback prepara la información necesaria para revertir una operación
-func (m *BatchOperationManager) prepareRollback(op FileOperation) rollbackData {
+func (m *BatchOperationManager) prepareRollback(op FileOperation, keepContent bool) rollbackData {
 	rb := rollbackData{
 		operationType: op.Type,
 	}
 
+	// readContent captures a file's content for in-memory rollback. With a
+	// batch backup the content is restored from the backup instead.
+	readContent := func(path string) ([]byte, bool) {
+		if _, err := os.Stat(path); err != nil {
+			return nil, false
+		}
+		if !keepContent {
+			return nil, true
+		}
+		content, err := os.ReadFile(path)
+		return content, err == nil
+	}
+
 	switch op.Type {
-	case "write":
+	case "write", "append":
 		rb.originalPath = op.Path
 		// Guardar contenido original si el archivo existe
-		if content, err := os.ReadFile(op.Path); err == nil {
-			rb.content = content
-		} else {
-			rb.wasCreated = true
-		}
+		var existed bool
+		rb.content, existed = readContent(op.Path)
+		rb.wasCreated = !existed
 
-	case "edit", "search_and_replace":
+	case "edit", "search_and_replace", "replace_nth":
 		rb.originalPath = op.Path
 		// Guardar contenido original
-		if content, err := os.ReadFile(op.Path); err == nil {
-			rb.content = content
-		}
+		rb.content, _ = readContent(op.Path)
 
 	case "move":
 		rb.originalPath = op.Source
@@ -467,14 +743,15 @@ func (m *BatchOperationManager) prepareRollback(op FileOperation) rollbackData {
 
 	case "copy":
 		rb.originalPath = op.Destination
-		rb.wasCreated = true
+		// Una copia sobre un archivo existente lo sobrescribe
+		var existed bool
+		rb.content, existed = readContent(op.Destination)
+		rb.wasCreated = !existed
 
 	case "delete":
 		rb.originalPath = op.Path
 		// Guardar contenido antes de eliminar
-		if content, err := os.ReadFile(op.Path); err == nil {
-			rb.content = content
-		}
+		rb.content, _ = readContent(op.Path)
 
 	case "create_dir":
 		rb.originalPath = op.Path
@@ -483,34 +760,42 @@ func (m *BatchOperationManager) prepareRollback(op FileOperation) rollbackData {
 	case "extract":
 		// Capture both files so an extract can be fully reverted (point 4).
 		rb.originalPath = op.Source
-		if content, err := os.ReadFile(op.Source); err == nil {
-			rb.content = content
-		}
+		rb.content, _ = readContent(op.Source)
 		rb.secondPath = op.Destination
-		if content, err := os.ReadFile(op.Destination); err == nil {
-			rb.secondContent = content
-		} else {
-			rb.secondWasCreated = true
-		}
+		var existed bool
+		rb.secondContent, existed = readContent(op.Destination)
+		rb.secondWasCreated = !existed
 	}
 
 	return rb
 }
 
-// rollback revierte las operaciones ejecutadas
-func (m *BatchOperationManager) rollback(rollbackInfo []rollbackData) {
+// rollback revierte las operaciones ejecutadas. Con backupID, el contenido de
+// los archivos modificados o eliminados se restaura desde el backup del batch
+// (que refleja el estado anterior a todo el batch) una vez deshechos los
+// cambios estructurales (archivos creados, moves).
+func (m *BatchOperationManager) rollback(rollbackInfo []rollbackData, backupID string) {
+	var restore []string
+	restoreContent := func(path string, content []byte) {
+		if backupID != "" {
+			restore = append(restore, path)
+			return
+		}
+		os.WriteFile(path, content, 0644)
+	}
+
 	// Revertir en orden inverso
 	for i := len(rollbackInfo) - 1; i >= 0; i-- {
 		rb := rollbackInfo[i]
 
 		switch rb.operationType {
-		case "write", "edit", "search_and_replace":
+		case "write", "append", "edit", "search_and_replace", "replace_nth":
 			if rb.wasCreated {
 				// El archivo fue creado, eliminarlo
 				os.Remove(rb.originalPath)
 			} else {
 				// Restaurar contenido original
-				os.WriteFile(rb.originalPath, rb.content, 0644)
+				restoreContent(rb.originalPath, rb.content)
 			}
 
 		case "move":
@@ -518,12 +803,16 @@ func (m *BatchOperationManager) rollback(rollbackInfo []rollbackData) {
 			os.Rename(rb.backupPath, rb.originalPath)
 
 		case "copy":
-			// Eliminar la copia
-			os.Remove(rb.originalPath)
+			// Eliminar la copia, o restaurar el destino sobrescrito
+			if rb.wasCreated {
+				os.Remove(rb.originalPath)
+			} else {
+				restoreContent(rb.originalPath, rb.content)
+			}
 
 		case "delete":
 			// Restaurar archivo eliminado
-			os.WriteFile(rb.originalPath, rb.content, 0644)
+			restoreContent(rb.originalPath, rb.content)
 
 		case "create_dir":
 			// Eliminar directorio creado
@@ -531,116 +820,153 @@ func (m *BatchOperationManager) rollback(rollbackInfo []rollbackData) {
 
 		case "extract":
 			// Restaurar origen y revertir el destino (point 4).
-			os.WriteFile(rb.originalPath, rb.content, 0644)
+			restoreContent(rb.originalPath, rb.content)
 			if rb.secondWasCreated {
 				os.Remove(rb.secondPath)
 			} else {
-				os.WriteFile(rb.secondPath, rb.secondContent, 0644)
+				restoreContent(rb.secondPath, rb.secondContent)
 			}
 		}
 	}
+
+	if len(restore) == 0 {
+		return
+	}
+	bm := m.getBackupManager()
+	seen := make(map[string]bool, len(restore))
+	for _, path := range restore {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		// Files created earlier in this batch are not in the backup; they
+		// were removed above, so "not found in backup" is expected here.
+		if _, _, err := bm.RestoreBackup(backupID, path, false); err != nil {
+			slog.Warn("Batch rollback: restore from backup failed", "backup_id", backupID, "path", path, "error", err)
+		}
+		m.invalidateRestored(path)
+	}
+}
+
+// invalidateOperationPaths drops cached content and directory listings for
+// every path an operation touched: batch operations write through os.*
+// directly, so without this list_directory/read_file could serve stale
+// entries. A deleted or moved directory's own listing is dropped as well.
+func (m *BatchOperationManager) invalidateOperationPaths(op FileOperation) {
+	if m.engine == nil {
+		return
+	}
+	for _, p := range m.collectPaths(op) {
+		if p == "" {
+			continue
+		}
+		m.engine.invalidateMutatedPath(p)
+		m.engine.invalidateDirListing(NormalizePath(p))
+	}
 }
 
-// getBackupDir returns the effective backup directory
-func (m *BatchOperationManager) getBackupDir() string {
-	if m.backupManager != nil {
-		return m.backupManager.backupDir
+// invalidateRestored drops cached reads of a file rolled back from backup
+func (m *BatchOperationManager) invalidateRestored(path string) {
+	if m.engine != nil {
+		m.engine.invalidateMutatedPath(path)
 	}
-	return m.backupDir
 }
 
-// createBackup crea un backup de todos los archivos afectados
-func (m *BatchOperationManager) createBackup(operations []FileOperation) (string, error) {
+// getBackupManager returns the shared BackupManager, creating a private one
+// on backupDir for standalone managers (tests, no engine wiring).
+func (m *BatchOperationManager) getBackupManager() *BackupManager {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
-
-	// Crear directorio de backup con timestamp
-	timestamp := time.Now().Format("20060102-150405")
-	backupID := fmt.Sprintf("batch-%s", timestamp)
-	backupPath := filepath.Join(m.getBackupDir(), backupID)
-
-	if err := os.MkdirAll(backupPath, 0755); err != nil {
-		return "", err
+	if m.backupManager == nil {
+		bm, err := NewBackupManager(m.backupDir, m.maxBackups, 7)
+		if err != nil {
+			return nil
+		}
+		m.backupManager = bm
 	}
+	return m.backupManager
+}
 
-	// Preparar metadatos compatibles con BackupInfo
-	filesBackupDir := filepath.Join(backupPath, "files")
-	if err := os.MkdirAll(filesBackupDir, 0755); err != nil {
-		return "", err
+// backupTargets lists the existing files the batch will overwrite, edit,
+// delete or move, once each and in operation order.
+func backupTargets(operations []FileOperation) []string {
+	var targets []string
+	seen := make(map[string]bool)
+	add := func(path string) {
+		if path == "" || seen[path] {
+			return
+		}
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			return
+		}
+		seen[path] = true
+		targets = append(targets, path)
 	}
-
-	var backupMetadatas []BackupMetadata
-	var totalSize int64
-
-	// Hacer backup de archivos afectados
-	for i, op := range operations {
-		var sourceFile string
-
+	for _, op := range operations {
 		switch op.Type {
-		case "write", "edit", "search_and_replace", "delete":
-			sourceFile = op.Path
-		case "move", "extract":
-			sourceFile = op.Source
-		}
-
-		if sourceFile != "" {
-			if fileInfo, err := os.Stat(sourceFile); err == nil {
-				// El archivo existe, hacer backup
-				backupFileName := fmt.Sprintf("op-%d-%s", i, filepath.Base(sourceFile))
-				backupFilePath := filepath.Join(filesBackupDir, backupFileName)
-				hash, err := copyFileWithHash(sourceFile, backupFilePath)
-				if err != nil {
-					return "", fmt.Errorf("failed to backup %s: %w", sourceFile, err)
-				}
-				backupMetadatas = append(backupMetadatas, BackupMetadata{
-					OriginalPath: sourceFile,
-					BackupPath:   filepath.Join("files", backupFileName),
-					Size:         fileInfo.Size(),
-					Hash:         hash,
-					ModifiedTime: fileInfo.ModTime(),
-				})
-				totalSize += fileInfo.Size()
-			}
+		case "write", "append", "edit", "search_and_replace", "replace_nth", "delete":
+			add(op.Path)
+		case "move":
+			add(op.Source)
+		case "copy":
+			add(op.Destination) // overwritten if it exists
+		case "extract":
+			add(op.Source)
+			add(op.Destination)
 		}
 	}
+	return targets
+}
 
-	// Guardar metadatos en formato compatible con BackupManager
-	info := BackupInfo{
-		BackupID:  backupID,
-		Timestamp: time.Now(),
-		Operation: "batch_operation",
-		Files:     backupMetadatas,
-		TotalSize: totalSize,
-	}
-
-	metadataJSON, err := json.MarshalIndent(info, "", "  ")
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal backup metadata: %w", err)
+// createBackup backs up every file the batch will modify through the
+// BackupManager, so the batch shows up in backup(action:"list") and can be
+// restored by ID. Returns "" when the batch touches no existing file.
+func (m *BatchOperationManager) createBackup(operations []FileOperation) (string, error) {
+	targets := backupTargets(operations)
+	if len(targets) == 0 {
+		return "", nil
 	}
-	if err := os.WriteFile(filepath.Join(backupPath, "metadata.json"), metadataJSON, 0644); err != nil {
-		return "", fmt.Errorf("failed to write metadata: %w", err)
+	bm := m.getBackupManager()
+	if bm == nil {
+		return "", fmt.Errorf("backup manager unavailable (backup dir: %s)", m.backupDir)
 	}
+	return bm.CreateBatchBackup(targets, "batch_operations",
+		fmt.Sprintf("Batch: %d operations, %d files", len(operations), len(targets)))
+}
 
-	// Registrar en el cache del backup manager si está disponible
-	if m.backupManager != nil {
-		m.backupManager.metadataCache[backupID] = &info
+// executeOperation ejecuta una operación individual
+// logAppliedOperations records the batch's applied operations in the
+// mutation audit log (batch ops bypass the engine's per-operation recording)
+func (m *BatchOperationManager) logAppliedOperations(applied []FileOperation, backupID string, forced bool, started time.Time) {
+	if m.engine == nil || m.engine.mutationLog == nil {
+		return
+	}
+	now := time.Now()
+	for _, op := range applied {
+		rec := OperationRecord{Timestamp: now, Tool: "batch_operations", Operation: "batch_" + op.Type,
+			Path: op.Path, BackupID: backupID, Forced: forced}
+		if op.Source != "" {
+			rec.Path, rec.Dest = op.Source, op.Destination
+		}
+		if op.Type == "write" || op.Type == "append" {
+			rec.BytesWritten = int64(len(op.Content))
+		}
+		m.engine.logMutation(rec, started)
 	}
-
-	// Limpiar backups antiguos
-	m.cleanOldBackups()
-
-	return backupPath, nil
 }
 
-// executeOperation ejecuta una operación individual
 func (m *BatchOperationManager) executeOperation(op FileOperation, result *OperationResult) error {
 	switch op.Type {
 	case "write":
 		return m.executeWrite(op, result)
+	case "append":
+		return m.executeAppend(op, result)
 	case "edit":
 		return m.executeEdit(op, result)
 	case "search_and_replace":
 		return m.executeSearchAndReplace(op, result)
+	case "replace_nth":
+		return m.executeReplaceNth(op, result)
 	case "move":
 		return m.executeMove(op, result)
 	case "copy":
@@ -659,10 +985,15 @@ func (m *BatchOperationManager) executeOperation(op FileOperation, result *Opera
 func (m *BatchOperationManager) executeWrite(op FileOperation, result *OperationResult) error {
 	ctx := context.Background()
 
-	// Pre-write hook (respects user hooks even in batch mode)
-	if err := m.executeHooksForOperation(ctx, HookPreWrite, op); err != nil {
+	// Pre-write hook (respects user hooks even in batch mode); a formatter
+	// hook's output replaces the content
+	hookResult, err := m.hookResultForOperation(ctx, HookPreWrite, op)
+	if err != nil {
 		return fmt.Errorf("pre-write hook denied batch write: %w", err)
 	}
+	if hookResult != nil && hookResult.ModifiedContent != "" {
+		op.Content = hookResult.ModifiedContent
+	}
 
 	// Point 6a: atomic write (temp file + rename) instead of a direct
 	// os.WriteFile, so a batch interrupted mid-write never leaves a partial
@@ -684,6 +1015,36 @@ func (m *BatchOperationManager) executeWrite(op FileOperation, result *Operation
 	return nil
 }
 
+// executeAppend appends Content to Path, creating the file if needed. Like
+// write it goes through atomicWriteFile, so a failed append never leaves a
+// half-written file.
+func (m *BatchOperationManager) executeAppend(op FileOperation, result *OperationResult) error {
+	ctx := context.Background()
+
+	if err := m.executeHooksForOperation(ctx, HookPreWrite, op); err != nil {
+		return fmt.Errorf("pre-write hook denied batch append: %w", err)
+	}
+
+	fileMode := os.FileMode(0644)
+	var existing []byte
+	if info, statErr := os.Stat(op.Path); statErr == nil {
+		fileMode = info.Mode()
+		content, err := os.ReadFile(op.Path)
+		if err != nil {
+			return err
+		}
+		existing = content
+	}
+	if err := atomicWriteFile(op.Path, append(existing, op.Content...), fileMode); err != nil {
+		return err
+	}
+
+	result.BytesAffected = int64(len(op.Content))
+
+	_ = m.executeHooksForOperation(ctx, HookPostWrite, op)
+	return nil
+}
+
 // executeExtract moves lines [StartLine, EndLine] from Source to Destination
 // atomically (point 4). The same computed slice is written to the destination
 // and removed from the source, so the bytes written == the bytes deleted by
@@ -833,6 +1194,33 @@ func (m *BatchOperationManager) executeSearchAndReplace(op FileOperation, result
 	return nil
 }
 
+// executeReplaceNth replaces only the Occurrence-th match of OldText (regex,
+// literal if it does not compile) through the engine's ReplaceNthOccurrence,
+// which also runs the post-edit hooks.
+func (m *BatchOperationManager) executeReplaceNth(op FileOperation, result *OperationResult) error {
+	ctx := context.Background()
+
+	if err := m.executeHooksForOperation(ctx, HookPreEdit, op); err != nil {
+		return fmt.Errorf("pre-edit hook denied batch replace_nth: %w", err)
+	}
+
+	if m.engine == nil {
+		return fmt.Errorf("replace_nth requires engine (not available in standalone batch mode)")
+	}
+	var sizeBefore int64
+	if info, statErr := os.Stat(op.Path); statErr == nil {
+		sizeBefore = info.Size()
+	}
+	if _, err := m.engine.ReplaceNthOccurrence(ctx, op.Path, op.OldText, op.NewText, op.Occurrence, false); err != nil {
+		return err
+	}
+	if info, statErr := os.Stat(op.Path); statErr == nil {
+		result.BytesAffected = info.Size() - sizeBefore
+	}
+
+	return nil
+}
+
 func (m *BatchOperationManager) executeMove(op FileOperation, result *OperationResult) error {
 	ctx := context.Background()
 
@@ -847,7 +1235,7 @@ func (m *BatchOperationManager) executeMove(op FileOperation, result *OperationR
 
 	err = os.Rename(op.Source, op.Destination)
 	if err != nil {
-		return err
+		return lockedError("move", op.Source, err)
 	}
 
 	result.BytesAffected = info.Size()
@@ -931,21 +1319,21 @@ func (m *BatchOperationManager) collectPaths(op FileOperation) []string {
 	}
 }
 
-// cleanOldBackups limpia backups antiguos manteniendo solo los últimos N
-func (m *BatchOperationManager) cleanOldBackups() {
-	entries, err := os.ReadDir(m.getBackupDir())
-	if err != nil {
-		return
-	}
-
-	if len(entries) <= m.maxBackups {
-		return
-	}
-
-	// Ordenar por fecha de modificación y eliminar los más antiguos
-	backupDir := m.getBackupDir()
-	for i := 0; i < len(entries)-m.maxBackups; i++ {
-		path := filepath.Join(backupDir, entries[i].Name())
-		os.RemoveAll(path)
+// checkProtected refuses an operation that would change a write-protected
+// path: the destination of copy/extract, both ends of a move, the target of
+// everything else.
+func (m *BatchOperationManager) checkProtected(op FileOperation) error {
+	switch op.Type {
+	case "copy", "extract":
+		return m.engine.CheckWritable(op.Type, op.Destination)
+	case "move":
+		if err := m.engine.CheckRemovable(op.Type, op.Source); err != nil {
+			return err
+		}
+		return m.engine.CheckWritable(op.Type, op.Destination)
+	case "delete":
+		return m.engine.CheckRemovable(op.Type, op.Path)
+	default:
+		return m.engine.CheckWritable(op.Type, op.Path)
 	}
 }
@@ -130,7 +130,7 @@ func (e *UltraFastEngine) BatchRenameFiles(ctx context.Context, request BatchRen
 		return nil, fmt.Errorf("cannot proceed: %d naming conflicts detected. Use preview mode to review", len(conflicts))
 	}
 
-	e.executeRenameOperations(operations, result)
+	e.executeRenameOperations(ctx, operations, result)
 
 	result.ExecutionTime = time.Since(start).String()
 	return result, nil
@@ -141,7 +141,13 @@ func (e *UltraFastEngine) validateBatchRenameRequest(req *BatchRenameRequest) er
 	if req.Path == "" {
 		return fmt.Errorf("path is required")
 	}
+	return validateBatchRenameMode(req)
+}
 
+// validateBatchRenameMode checks the mode and its mode-specific fields
+// (everything except path). Shared with the pipeline batch_rename action,
+// whose file list comes from input_from instead of path.
+func validateBatchRenameMode(req *BatchRenameRequest) error {
 	validModes := []string{
 		"find_replace", "add_prefix", "add_suffix", "number_files",
 		"regex_rename", "change_extension", "to_lowercase", "to_uppercase",
@@ -198,6 +204,23 @@ func (e *UltraFastEngine) validateBatchRenameRequest(req *BatchRenameRequest) er
 	return nil
 }
 
+// planBatchRenameList plans renames for an explicit file list (pipeline
+// batch_rename). Every file must be inside the allowed paths.
+func (e *UltraFastEngine) planBatchRenameList(files []string, req BatchRenameRequest) ([]RenameOperation, []string, error) {
+	if err := validateBatchRenameMode(&req); err != nil {
+		return nil, nil, err
+	}
+	valid := make([]string, 0, len(files))
+	for _, f := range files {
+		validPath, err := e.validatePath(f)
+		if err != nil {
+			return nil, nil, err
+		}
+		valid = append(valid, validPath)
+	}
+	return e.planRenameOperations(valid, &req)
+}
+
 // collectFilesForRename collects all files to be renamed
 func (e *UltraFastEngine) collectFilesForRename(path string, isDir bool, recursive bool, filePattern string) ([]string, error) {
 	var files []string
@@ -329,6 +352,29 @@ func (e *UltraFastEngine) planRenameOperations(files []string, req *BatchRenameR
 
 		newPath := filepath.Join(dir, newName)
 
+		if err := e.CheckRemovable("batch_rename", oldPath); err != nil {
+			operations[i] = RenameOperation{
+				Index:   i,
+				OldPath: oldPath,
+				OldName: oldName,
+				NewName: newName,
+				Error:   err.Error(),
+				Skipped: true,
+			}
+			continue
+		}
+		if err := e.CheckWritable("batch_rename", newPath); err != nil {
+			operations[i] = RenameOperation{
+				Index:   i,
+				OldPath: oldPath,
+				OldName: oldName,
+				NewName: newName,
+				Error:   err.Error(),
+				Skipped: true,
+			}
+			continue
+		}
+
 		// Check for conflicts
 		if existingOldPath, exists := newNames[newPath]; exists {
 			conflict := fmt.Sprintf("Conflict: '%s' and '%s' both rename to '%s'", oldName, filepath.Base(existingOldPath), newName)
@@ -429,10 +475,13 @@ func (e *UltraFastEngine) generateNewName(oldName string, index int, req *BatchR
 	}
 }
 
-// executeRenameOperations executes the rename operations with parallel processing
-func (e *UltraFastEngine) executeRenameOperations(operations []RenameOperation, result *BatchRenameResult) {
+// executeRenameOperations executes the rename operations with parallel
+// processing. Each rename runs the pre/post-move hooks; a denied rename is
+// reported as that file's error and leaves it in place.
+func (e *UltraFastEngine) executeRenameOperations(ctx context.Context, operations []RenameOperation, result *BatchRenameResult) {
 	var wg sync.WaitGroup
 	var mu sync.Mutex
+	workingDir, _ := os.Getwd()
 
 	// Process operations in parallel using worker pool
 	for i := range operations {
@@ -449,7 +498,23 @@ func (e *UltraFastEngine) executeRenameOperations(operations []RenameOperation,
 		e.workerPool.Submit(func() {
 			defer wg.Done()
 
-			err := os.Rename(op.OldPath, op.NewPath)
+			hookCtx := &HookContext{
+				Event:      HookPreMove,
+				ToolName:   "batch_rename",
+				FilePath:   op.OldPath,
+				Operation:  "rename",
+				SourcePath: op.OldPath,
+				DestPath:   op.NewPath,
+				Timestamp:  time.Now(),
+				WorkingDir: workingDir,
+			}
+			_, err := e.hookManager.ExecuteHooks(ctx, HookPreMove, hookCtx)
+			if err != nil {
+				err = fmt.Errorf("pre-move hook denied rename: %w", err)
+			} else if err = os.Rename(op.OldPath, op.NewPath); err == nil {
+				hookCtx.Event = HookPostMove
+				_, _ = e.hookManager.ExecuteHooks(ctx, HookPostMove, hookCtx)
+			}
 
 			mu.Lock()
 			defer mu.Unlock()
@@ -462,10 +527,13 @@ func (e *UltraFastEngine) executeRenameOperations(operations []RenameOperation,
 			} else {
 				op.Success = true
 				result.RenamedCount++
+				e.recordOperation(ctx, OperationRecord{Operation: "rename", Path: op.OldPath, Dest: op.NewPath})
 
 				// Invalidate cache for old and new paths
 				e.invalidateFileReadCache(op.OldPath)
 				e.invalidateFileReadCache(op.NewPath)
+				e.invalidateDirListing(filepath.Dir(op.OldPath))
+				e.invalidateDirListing(filepath.Dir(op.NewPath))
 			}
 		})
 	}
@@ -0,0 +1,191 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// batchOpOutcome is what running one batch operation produced. Workers fill
+// their own slot; ExecuteBatch merges the outcomes in index order.
+type batchOpOutcome struct {
+	ran    bool // false: never started (atomic batch stopped earlier)
+	result OperationResult
+	rb     rollbackData
+	entry  BatchOpJournal
+	err    error
+}
+
+// batchOpGroup returns the continue_on_error group of an operation; an
+// ungrouped operation is its own group.
+func batchOpGroup(op FileOperation, index int) string {
+	if op.Group != "" {
+		return op.Group
+	}
+	return fmt.Sprintf("op-%d", index)
+}
+
+// batchOpPaths splits the paths an operation touches into the ones it only
+// reads and the ones it creates, modifies or removes.
+func batchOpPaths(op FileOperation) (reads, writes []string) {
+	clean := func(p string) string {
+		if p == "" {
+			return ""
+		}
+		p = filepath.Clean(NormalizePath(p))
+		if os.PathSeparator == '\\' {
+			return strings.ToLower(p) // C:\Foo and c:\foo are the same file
+		}
+		return p
+	}
+	switch op.Type {
+	case "copy":
+		return []string{clean(op.Source)}, []string{clean(op.Destination)}
+	case "move", "extract":
+		return nil, []string{clean(op.Source), clean(op.Destination)}
+	default:
+		return nil, []string{clean(op.Path)}
+	}
+}
+
+// pathsOverlap reports whether a and b are the same path or one contains the other
+func pathsOverlap(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	if a == b {
+		return true
+	}
+	sep := string(os.PathSeparator)
+	return strings.HasPrefix(b, strings.TrimSuffix(a, sep)+sep) || strings.HasPrefix(a, strings.TrimSuffix(b, sep)+sep)
+}
+
+// batchOpsConflict reports whether two operations must keep their relative
+// order: one writes a path the other reads or writes (including paths inside
+// a directory the other creates, moves or deletes), or they share an explicit
+// continue_on_error group.
+func batchOpsConflict(a, b FileOperation, continueOnError bool) bool {
+	if continueOnError && a.Group != "" && a.Group == b.Group {
+		return true
+	}
+	aReads, aWrites := batchOpPaths(a)
+	bReads, bWrites := batchOpPaths(b)
+	for _, w := range aWrites {
+		for _, p := range append(append([]string{}, bReads...), bWrites...) {
+			if pathsOverlap(w, p) {
+				return true
+			}
+		}
+	}
+	for _, w := range bWrites {
+		for _, r := range aReads {
+			if pathsOverlap(w, r) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// planBatchWaves splits the operations into waves that may run concurrently.
+// An operation that conflicts with an earlier one lands in a later wave, so
+// conflicting operations run in submission order; each wave lists indexes in
+// ascending order. Without an engine worker pool (or with ParallelOps <= 1)
+// every operation is its own wave, i.e. the batch runs serially.
+func (m *BatchOperationManager) planBatchWaves(request BatchRequest) [][]int {
+	ops := request.Operations
+	if m.engine == nil || m.engine.workerPool == nil || m.engine.config.ParallelOps <= 1 {
+		waves := make([][]int, len(ops))
+		for i := range ops {
+			waves[i] = []int{i}
+		}
+		return waves
+	}
+
+	level := make([]int, len(ops))
+	var waves [][]int
+	for i := range ops {
+		for j := 0; j < i; j++ {
+			if level[j] >= level[i] && batchOpsConflict(ops[j], ops[i], request.ContinueOnError) {
+				level[i] = level[j] + 1
+			}
+		}
+		if level[i] == len(waves) {
+			waves = append(waves, nil)
+		}
+		waves[level[i]] = append(waves[level[i]], i)
+	}
+	return waves
+}
+
+// runBatchWave runs one wave on the engine worker pool (at most ParallelOps
+// at a time) and returns once every operation in it has settled.
+func (m *BatchOperationManager) runBatchWave(wave []int, run func(index int)) {
+	if len(wave) == 1 || m.engine == nil || m.engine.workerPool == nil {
+		for _, i := range wave {
+			run(i)
+		}
+		return
+	}
+	var wg sync.WaitGroup
+	for _, i := range wave {
+		i := i
+		wg.Add(1)
+		if err := m.engine.workerPool.Submit(func() {
+			defer wg.Done()
+			run(i)
+		}); err != nil {
+			// Pool closed or overloaded: run inline rather than drop the op
+			run(i)
+			wg.Done()
+		}
+	}
+	wg.Wait()
+}
+
+// runBatchOperation executes a single operation and captures its rollback
+// and journal bookkeeping. failedGroups is only read here; ExecuteBatch
+// updates it between waves, after every worker has finished.
+func (m *BatchOperationManager) runBatchOperation(index int, op FileOperation, request BatchRequest, backupID string, failedGroups map[string]bool) batchOpOutcome {
+	out := batchOpOutcome{
+		ran:    true,
+		result: OperationResult{Index: index, Type: op.Type, Path: op.Path},
+	}
+
+	// continue_on_error: las ops de un grupo que ya falló no se ejecutan
+	group := ""
+	if request.ContinueOnError {
+		group = batchOpGroup(op, index)
+		out.result.Group = group
+		if failedGroups[group] {
+			out.result.Skipped = true
+			out.result.Error = fmt.Sprintf("skipped: an earlier operation of group %q failed", group)
+			out.entry = BatchOpJournal{Index: index, Group: group, Type: op.Type, Status: BatchOpSkipped}
+			return out
+		}
+	}
+
+	// Guardar información para rollback (el contenido solo se copia en
+	// memoria cuando no hay backup que lo cubra)
+	if request.Atomic || request.ContinueOnError {
+		out.rb = m.prepareRollback(op, backupID == "")
+	}
+
+	out.err = m.executeOperation(op, &out.result)
+	m.invalidateOperationPaths(op)
+	out.result.Success = out.err == nil
+	if out.err != nil {
+		out.result.Error = out.err.Error()
+	}
+
+	if request.ContinueOnError {
+		out.entry = out.rb.journalEntry()
+		out.entry.Index, out.entry.Group, out.entry.Status = index, group, BatchOpApplied
+		if out.err != nil {
+			out.entry.Status = BatchOpFailed
+		}
+	}
+	return out
+}
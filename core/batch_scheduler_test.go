@@ -0,0 +1,110 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPlanBatchWaves_ConflictsKeepOrder(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	mgr := NewBatchOperationManager(t.TempDir(), 10)
+	mgr.SetEngine(engine)
+
+	a := filepath.Join(dir, "a.txt")
+	sub := filepath.Join(dir, "sub")
+	waves := mgr.planBatchWaves(BatchRequest{Operations: []FileOperation{
+		{Type: "write", Path: a},                                            // 0
+		{Type: "write", Path: filepath.Join(dir, "b.txt")},                  // 1
+		{Type: "edit", Path: a},                                             // 2: same path as 0
+		{Type: "create_dir", Path: sub},                                     // 3
+		{Type: "write", Path: filepath.Join(sub, "c.txt")},                  // 4: inside 3
+		{Type: "copy", Source: a, Destination: filepath.Join(dir, "d.txt")}, // 5: reads what 2 writes
+		{Type: "copy", Source: a, Destination: filepath.Join(dir, "e.txt")}, // 6: only reads a, like 5
+		{Type: "delete", Path: sub},                                         // 7: deletes 4's parent
+	}})
+	want := [][]int{{0, 1, 3}, {2, 4}, {5, 6, 7}}
+	if fmt.Sprint(waves) != fmt.Sprint(want) {
+		t.Errorf("waves = %v, want %v", waves, want)
+	}
+
+	serial := NewBatchOperationManager(t.TempDir(), 10)
+	if got := serial.planBatchWaves(BatchRequest{Operations: make([]FileOperation, 3)}); fmt.Sprint(got) != "[[0] [1] [2]]" {
+		t.Errorf("without an engine the batch must run serially, got %v", got)
+	}
+}
+
+func TestBatchConcurrent_ResultsInOrderAndAtomicRollback(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	mgr := NewBatchOperationManager(t.TempDir(), 10)
+	mgr.SetBackupManager(engine.GetBackupManager())
+	mgr.SetEngine(engine)
+
+	var ops []FileOperation
+	for i := 0; i < 40; i++ {
+		ops = append(ops, FileOperation{Type: "write", Path: filepath.Join(dir, fmt.Sprintf("f%02d.txt", i)), Content: fmt.Sprint(i)})
+	}
+	chained := filepath.Join(dir, "f00.txt")
+	ops = append(ops, FileOperation{Type: "edit", Path: chained, OldText: "0", NewText: "zero"})
+
+	res := mgr.ExecuteBatch(BatchRequest{Operations: ops})
+	if !res.Success || res.CompletedOps != len(ops) {
+		t.Fatalf("batch = %+v", res.Errors)
+	}
+	for i, r := range res.Results {
+		if r.Index != i {
+			t.Fatalf("result %d has index %d, want original order", i, r.Index)
+		}
+	}
+	if got, _ := os.ReadFile(chained); string(got) != "zero" {
+		t.Errorf("conflicting edit ran before its write: %q", got)
+	}
+
+	// A failure in the first wave rolls back every operation of that wave
+	// once all of them have settled, and no later wave starts.
+	keep := filepath.Join(dir, "keep.txt")
+	os.WriteFile(keep, []byte("original"), 0644)
+	ops = []FileOperation{
+		{Type: "write", Path: keep, Content: "changed"},
+		{Type: "edit", Path: chained, OldText: "missing", NewText: "x"},
+		{Type: "write", Path: filepath.Join(dir, "new.txt"), Content: "new"},
+		{Type: "write", Path: filepath.Join(dir, "later.txt"), Content: "x"},
+		{Type: "edit", Path: keep, OldText: "changed", NewText: "again"},
+	}
+	res = mgr.ExecuteBatch(BatchRequest{Atomic: true, CreateBackup: true, Operations: ops})
+	if res.Success || !res.RollbackDone {
+		t.Fatalf("expected atomic rollback: %+v", res)
+	}
+	if got, _ := os.ReadFile(keep); string(got) != "original" {
+		t.Errorf("keep.txt after rollback = %q", got)
+	}
+	for _, name := range []string{"new.txt", "later.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s survived the atomic rollback", name)
+		}
+	}
+	for i := 1; i < len(res.Results); i++ {
+		if res.Results[i].Index <= res.Results[i-1].Index {
+			t.Fatalf("results out of order: %+v", res.Results)
+		}
+	}
+	if last := res.Results[len(res.Results)-1]; last.Index == 4 {
+		t.Error("operation of a later wave ran after an atomic failure")
+	}
+}
+
+func TestBatchOpsConflict_WindowsPathsIgnoreCase(t *testing.T) {
+	if os.PathSeparator != '\\' {
+		t.Skip("case-insensitive paths are a Windows rule")
+	}
+	a := FileOperation{Type: "write", Path: `C:\Foo\a.txt`}
+	b := FileOperation{Type: "edit", Path: `c:\foo\A.TXT`}
+	if !batchOpsConflict(a, b, false) {
+		t.Error("same file in different case was scheduled as independent")
+	}
+	dir := FileOperation{Type: "delete", Path: `c:\FOO`}
+	if !batchOpsConflict(a, dir, false) {
+		t.Error("write inside a directory deleted in different case was scheduled as independent")
+	}
+}
@@ -0,0 +1,274 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// batchSimFile is the simulated state of a path after the operations
+// previewed so far. known=false means an earlier op changed the file in a way
+// the preview does not model; its content is then only checked at execution.
+type batchSimFile struct {
+	exists  bool
+	isDir   bool
+	known   bool
+	content string
+	by      int // op that last changed the path, -1 = on disk
+}
+
+// batchSimulator replays a batch read-only so validate_only can report what
+// each operation would do against the state left by the earlier ones.
+type batchSimulator struct {
+	files map[string]*batchSimFile
+}
+
+// state returns the simulated state of path, loading it from disk on first use
+func (s *batchSimulator) state(path string) *batchSimFile {
+	if f, ok := s.files[path]; ok {
+		return f
+	}
+	f := &batchSimFile{by: -1}
+	if info, err := os.Stat(path); err == nil {
+		f.exists, f.isDir = true, info.IsDir()
+		if !f.isDir {
+			if data, err := os.ReadFile(path); err == nil {
+				f.known, f.content = true, string(data)
+			}
+		}
+	}
+	s.files[path] = f
+	return f
+}
+
+// set records the content path holds after op index
+func (s *batchSimulator) set(path string, index int, known bool, content string) {
+	s.files[path] = &batchSimFile{exists: true, known: known, content: content, by: index}
+}
+
+// describe names where a file's current state comes from
+func (f *batchSimFile) describe() string {
+	switch {
+	case f.by >= 0:
+		return fmt.Sprintf("file written by op %d", f.by)
+	case f.known:
+		return fmt.Sprintf("existing file, %s", FormatSize(int64(len(f.content))))
+	default:
+		return "existing file"
+	}
+}
+
+// previewOperations runs the deep validate_only checks. opErrors are the
+// per-operation validation errors; the returned results carry them plus
+// findings (expected replacements, overwrites, what each op will touch), and
+// problems is the list of additional blocking errors found by the replay
+// ("Op N: ..." like validateOperations).
+func (m *BatchOperationManager) previewOperations(operations []FileOperation, opErrors map[int][]string) ([]OperationResult, []string) {
+	sim := &batchSimulator{files: make(map[string]*batchSimFile)}
+	results := make([]OperationResult, 0, len(operations))
+	var problems []string
+
+	for i, op := range operations {
+		res := OperationResult{Index: i, Type: op.Type, Path: op.Path}
+		if res.Path == "" && op.Source != "" {
+			res.Path = op.Source + " -> " + op.Destination
+		}
+		errs := append([]string{}, opErrors[i]...)
+		fail := func(format string, args ...interface{}) {
+			msg := fmt.Sprintf(format, args...)
+			errs = append(errs, msg)
+			problems = append(problems, fmt.Sprintf("Op %d: %s", i, msg))
+		}
+		note := func(format string, args ...interface{}) {
+			res.Findings = append(res.Findings, fmt.Sprintf(format, args...))
+		}
+		// unknown reports an input whose content the replay cannot predict
+		unknown := func(f *batchSimFile) bool {
+			if f.exists && !f.known && !f.isDir {
+				if f.by >= 0 {
+					note("content after op %d is not simulated; matches are checked at execution", f.by)
+				} else {
+					note("file could not be read; matches are checked at execution")
+				}
+				return true
+			}
+			return false
+		}
+
+		// Skip the replay for ops that already failed validation; their
+		// findings would be noise.
+		if len(errs) > 0 {
+			res.Error = strings.Join(errs, "; ")
+			results = append(results, res)
+			continue
+		}
+
+		switch op.Type {
+		case "write":
+			if f := sim.state(op.Path); f.exists {
+				note("overwrites %s", f.describe())
+			} else {
+				note("creates file")
+			}
+			sim.set(op.Path, i, true, op.Content)
+
+		case "append":
+			f := sim.state(op.Path)
+			switch {
+			case !f.exists:
+				note("creates file (%s)", FormatSize(int64(len(op.Content))))
+				sim.set(op.Path, i, true, op.Content)
+			default:
+				note("appends %s to %s", FormatSize(int64(len(op.Content))), f.describe())
+				sim.set(op.Path, i, f.known, f.content+op.Content)
+			}
+
+		case "edit":
+			f := sim.state(op.Path)
+			if unknown(f) {
+				sim.set(op.Path, i, false, "")
+				break
+			}
+			count, modified := 0, f.content
+			if m.engine != nil {
+				if editResult, err := m.engine.performIntelligentEdit(f.content, op.OldText, op.NewText, false); err == nil {
+					count, modified = editResult.ReplacementCount, editResult.ModifiedContent
+					if editResult.MatchConfidence != "" && count > 0 {
+						note("match confidence: %s", editResult.MatchConfidence)
+					}
+				}
+			} else if strings.Contains(f.content, op.OldText) {
+				count, modified = 1, strings.Replace(f.content, op.OldText, op.NewText, 1)
+			}
+			if count == 0 {
+				fail("old_text not found in %s", f.describe())
+				break
+			}
+			res.ExpectedReplacements = count
+			note("expected replacements: %d", count)
+			sim.set(op.Path, i, true, modified)
+
+		case "search_and_replace":
+			f := sim.state(op.Path)
+			if m.engine == nil {
+				fail("search_and_replace requires engine (not available in standalone batch mode)")
+				break
+			}
+			if unknown(f) {
+				sim.set(op.Path, i, false, "")
+				break
+			}
+			// Mirrors searchAndReplaceInFile: literal, case-sensitive,
+			// binary and >10MB files are skipped (zero matches).
+			count := 0
+			if len(f.content) <= searchMaxFileSize && detectStringContentType(op.Path, f.content).Text() {
+				count = strings.Count(f.content, op.OldText)
+			}
+			if count == 0 {
+				fail("pattern '%s' not found in %s", op.OldText, f.describe())
+				break
+			}
+			res.ExpectedReplacements = count
+			note("expected replacements: %d", count)
+			sim.set(op.Path, i, true, strings.ReplaceAll(f.content, op.OldText, op.NewText))
+
+		case "replace_nth":
+			f := sim.state(op.Path)
+			if m.engine == nil {
+				fail("replace_nth requires engine (not available in standalone batch mode)")
+				break
+			}
+			if !unknown(f) {
+				total := countLineMatches(f.content, op.OldText)
+				target := op.Occurrence
+				if target < 0 {
+					target = total + op.Occurrence + 1
+				}
+				if total == 0 {
+					fail("pattern not found: '%s'", op.OldText)
+					break
+				}
+				if target < 1 || target > total {
+					fail("occurrence %d out of range (only %d matches found)", op.Occurrence, total)
+					break
+				}
+				res.ExpectedReplacements = 1
+				note("replaces match %d of %d", target, total)
+			}
+			sim.set(op.Path, i, false, "")
+
+		case "move":
+			f := sim.state(op.Source)
+			note("moves %s to %s", f.describe(), op.Destination)
+			sim.files[op.Destination] = &batchSimFile{exists: true, isDir: f.isDir, known: f.known, content: f.content, by: i}
+			sim.files[op.Source] = &batchSimFile{by: i}
+
+		case "copy":
+			f := sim.state(op.Source)
+			if dst := sim.state(op.Destination); dst.exists {
+				note("destination exists and will be overwritten (%s)", dst.describe())
+			}
+			sim.set(op.Destination, i, f.known, f.content)
+
+		case "delete":
+			f := sim.state(op.Path)
+			if f.isDir {
+				if entries, err := os.ReadDir(op.Path); err == nil && len(entries) > 0 {
+					fail("directory is not empty (%d entries); batch delete only removes empty directories", len(entries))
+					break
+				}
+				note("deletes empty directory")
+			} else {
+				note("deletes %s", f.describe())
+			}
+			sim.files[op.Path] = &batchSimFile{by: i}
+
+		case "create_dir":
+			note("creates directory")
+			sim.files[op.Path] = &batchSimFile{exists: true, isDir: true, known: true, by: i}
+
+		case "extract":
+			src := sim.state(op.Source)
+			dst := sim.state(op.Destination)
+			if unknown(src) {
+				sim.set(op.Source, i, false, "")
+				sim.set(op.Destination, i, false, "")
+				break
+			}
+			removed, remaining, err := ComputeLineRangeDeletion(src.content, op.StartLine, op.EndLine)
+			if err != nil {
+				fail("%v", err)
+				break
+			}
+			note("moves lines %d-%d (%s) to %s", op.StartLine, op.EndLine, FormatSize(int64(len(removed))), op.Destination)
+			destContent := removed
+			if dst.exists && op.Append {
+				destContent = dst.content + removed
+			} else if dst.exists {
+				note("destination exists and will be overwritten (%s)", dst.describe())
+			}
+			sim.set(op.Source, i, true, remaining)
+			sim.set(op.Destination, i, dst.known || !dst.exists, destContent)
+		}
+
+		res.Success = len(errs) == 0
+		res.Error = strings.Join(errs, "; ")
+		results = append(results, res)
+	}
+	return results, problems
+}
+
+// countLineMatches counts matches of pattern line by line, compiling it like
+// ReplaceNthOccurrence does (regex, or literal when it does not compile).
+func countLineMatches(content, pattern string) int {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		re = regexp.MustCompile(regexp.QuoteMeta(pattern))
+	}
+	total := 0
+	for _, line := range strings.Split(normalizeLineEndings(content), "\n") {
+		total += len(re.FindAllStringIndex(line, -1))
+	}
+	return total
+}
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBatchValidateOnly_PredictsExecution(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	mgr := NewBatchOperationManager(t.TempDir(), 10)
+	mgr.SetEngine(engine)
+
+	code := filepath.Join(dir, "main.go")
+	cfg := filepath.Join(dir, "config.txt")
+	os.WriteFile(code, []byte("old()\nold()\nold()\n"), 0644)
+	os.WriteFile(cfg, []byte("v1\n"), 0644)
+
+	// Valid batch: counts come from the state left by earlier ops
+	res := mgr.ExecuteBatch(BatchRequest{ValidateOnly: true, Operations: []FileOperation{
+		{Type: "search_and_replace", Path: code, OldText: "old", NewText: "fresh"},
+		{Type: "edit", Path: code, OldText: "fresh()\nfresh()", NewText: "both()"},
+		{Type: "copy", Source: code, Destination: cfg},
+		{Type: "write", Path: filepath.Join(dir, "new.txt"), Content: "a b a"},
+		{Type: "replace_nth", Path: filepath.Join(dir, "new.txt"), OldText: "a", NewText: "x", Occurrence: -1},
+	}})
+	if !res.Success || len(res.Results) != 5 {
+		t.Fatalf("validate_only = %+v", res)
+	}
+	if got := res.Results[0].ExpectedReplacements; got != 3 {
+		t.Errorf("search_and_replace expected replacements = %d, want 3", got)
+	}
+	if got := res.Results[1].ExpectedReplacements; got != 1 {
+		t.Errorf("edit on simulated content: expected replacements = %d, want 1 (%v)", got, res.Results[1].Findings)
+	}
+	if !strings.Contains(strings.Join(res.Results[2].Findings, " "), "will be overwritten") {
+		t.Errorf("copy overwrite not reported: %v", res.Results[2].Findings)
+	}
+	if !strings.Contains(strings.Join(res.Results[4].Findings, " "), "match 2 of 2") {
+		t.Errorf("replace_nth finding = %v", res.Results[4].Findings)
+	}
+	if got, _ := os.ReadFile(code); string(got) != "old()\nold()\nold()\n" {
+		t.Fatal("validate_only modified a file")
+	}
+
+	// Failing batch: every problem is reported on its operation
+	res = mgr.ExecuteBatch(BatchRequest{ValidateOnly: true, Operations: []FileOperation{
+		{Type: "edit", Path: code, OldText: "missing", NewText: "x"},
+		{Type: "delete", Path: cfg},
+		{Type: "edit", Path: cfg, OldText: "v1", NewText: "v2"},
+		{Type: "replace_nth", Path: code, OldText: "old", NewText: "x", Occurrence: 5},
+		{Type: "copy", Source: filepath.Join(dir, "nope.txt"), Destination: filepath.Join(dir, "x.txt")},
+	}})
+	if res.Success {
+		t.Fatalf("validate_only accepted a failing batch: %+v", res)
+	}
+	wants := []string{"old_text not found", "", "deleted or moved away by op 1", "out of range (only 3 matches", "source does not exist"}
+	for i, want := range wants {
+		r := res.Results[i]
+		if want == "" {
+			if !r.Success {
+				t.Errorf("op %d should pass: %s", i, r.Error)
+			}
+			continue
+		}
+		if r.Success || !strings.Contains(r.Error, want) {
+			t.Errorf("op %d error = %q, want %q", i, r.Error, want)
+		}
+	}
+
+	// The intra-batch conflict also blocks real execution up front
+	res = mgr.ExecuteBatch(BatchRequest{Operations: []FileOperation{
+		{Type: "delete", Path: cfg},
+		{Type: "edit", Path: cfg, OldText: "v1", NewText: "v2"},
+	}})
+	if res.Success || res.CompletedOps != 0 {
+		t.Errorf("conflicting batch executed: %+v", res)
+	}
+	if _, err := os.Stat(cfg); err != nil {
+		t.Error("conflicting batch deleted the file")
+	}
+}
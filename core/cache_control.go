@@ -0,0 +1,160 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/mcp/filesystem-ultra/cache"
+)
+
+// GetCacheReport returns a snapshot of the engine cache for cache(action:"stats")
+func (e *UltraFastEngine) GetCacheReport(topN int) cache.CacheReport {
+	if e.cache == nil {
+		return cache.CacheReport{}
+	}
+	return e.cache.Report(topN)
+}
+
+// ClearCache drops every cached file, listing and metadata entry and returns
+// how many were dropped. Reads in flight finish normally and may re-cache
+// what they read (stamped with its size/mtime, so it is never stale).
+func (e *UltraFastEngine) ClearCache() int {
+	if e.cache == nil {
+		return 0
+	}
+	return e.cache.Clear()
+}
+
+// InvalidateCachePath drops everything cached for path: file content,
+// metadata, its own listings when it is a directory, and the parent's
+// listings. Returns the normalized path the entries were keyed by.
+func (e *UltraFastEngine) InvalidateCachePath(path string) string {
+	path = NormalizePath(path)
+	e.invalidateMutatedPath(path)
+	e.invalidateDirListing(path)
+	return path
+}
+
+// InvalidateCachePrefix drops every cache entry whose key starts with the
+// normalized prefix and returns how many were removed, plus the prefix used.
+func (e *UltraFastEngine) InvalidateCachePrefix(prefix string) (int, string) {
+	prefix = NormalizePath(prefix)
+	if e.cache == nil {
+		return 0, prefix
+	}
+	return e.cache.InvalidatePrefix(prefix), prefix
+}
+
+// cachedMissing returns the not-found error op returned for path within the
+// negative cache TTL. Mutations invalidate it through invalidateFileReadCache,
+// the watcher through its event handler.
+func (e *UltraFastEngine) cachedMissing(path, op string) (error, bool) {
+	if e.cache == nil {
+		return nil, false
+	}
+	return e.cache.GetMissing(path, op)
+}
+
+// rememberMissing caches op's not-found error for path
+func (e *UltraFastEngine) rememberMissing(path, op string, err error) {
+	if e.cache != nil {
+		e.cache.SetMissing(path, op, err)
+	}
+}
+
+// neverCacheMatcher returns the cache admission predicate for the
+// never-cache patterns ("*.log", "logs/**", "tmp/*.json"; see
+// matchPathPattern), or nil when there are none.
+func neverCacheMatcher(patterns []string) func(string) bool {
+	var clean []string
+	for _, p := range patterns {
+		if p = strings.TrimSpace(p); p != "" {
+			clean = append(clean, p)
+		}
+	}
+	if len(clean) == 0 {
+		return nil
+	}
+	return func(path string) bool {
+		segments := strings.Split(filepath.ToSlash(filepath.Clean(path)), "/")
+		for _, pattern := range clean {
+			if matchPathPattern(segments, pattern) != "" {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// FormatCacheReport renders a cache report as text (compact or verbose).
+func FormatCacheReport(report cache.CacheReport, compact bool) string {
+	var sb strings.Builder
+	entries := 0
+	for _, t := range report.Types {
+		entries += t.Entries
+	}
+	sb.WriteString(fmt.Sprintf("%d entries, %s used of %s (allocated %s), hit %.1f%%\n",
+		entries, formatSize(report.BytesUsed), formatSize(report.Capacity), formatSize(report.Allocated), report.HitRate*100))
+
+	for _, t := range report.Types {
+		if compact {
+			sb.WriteString(fmt.Sprintf("%s:%d/%s h:%d m:%d ", t.Type, t.Entries, formatSize(t.Bytes), t.Hits, t.Misses))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("  %-5s %6d entries  %10s  hits %-8d misses %-8d hit %5.1f%%", t.Type, t.Entries, formatSize(t.Bytes), t.Hits, t.Misses, t.HitRate()*100))
+		if t.Served > 0 {
+			sb.WriteString("  served " + formatSize(t.Served))
+		}
+		sb.WriteString("\n")
+	}
+	if compact {
+		sb.WriteString("\n")
+	}
+	var served int64
+	for _, t := range report.Types {
+		served += t.Served
+	}
+	if served+report.BytesFromDisk > 0 {
+		sb.WriteString(fmt.Sprintf("bytes: %s served from cache, %s read from disk\n", formatSize(served), formatSize(report.BytesFromDisk)))
+	}
+
+	sb.WriteString(fmt.Sprintf("evictions: file %d (expired or no space), dir/meta %d (expired or invalidated); stale files dropped: %d",
+		report.FileEvictions, report.OtherEvictions, report.StaleFiles))
+	if report.ExpiredFiles > 0 {
+		sb.WriteString(fmt.Sprintf("; past TTL: %d", report.ExpiredFiles))
+	}
+	if !report.StatValidation {
+		sb.WriteString(" [stat check off]")
+	}
+	sb.WriteString("\n")
+	if report.NegativeItems > 0 || report.NegativeHits > 0 {
+		sb.WriteString(fmt.Sprintf("not found: %d cached, %d repeated lookups answered\n", report.NegativeItems, report.NegativeHits))
+	}
+
+	var rules []string
+	if report.MaxFileSize > 0 {
+		rules = append(rules, "max file "+formatSize(report.MaxFileSize))
+	}
+	if report.FileTTL > 0 {
+		rules = append(rules, "ttl "+report.FileTTL.String())
+	}
+	if report.Bypasses > 0 {
+		rules = append(rules, fmt.Sprintf("%d uncacheable reads bypassed", report.Bypasses))
+	}
+	if len(rules) > 0 && !compact {
+		sb.WriteString("rules: " + strings.Join(rules, ", ") + "\n")
+	}
+
+	if len(report.Largest) > 0 {
+		sb.WriteString("Largest entries:\n")
+		for _, entry := range report.Largest {
+			key := entry.Key
+			if compact {
+				key = filepath.Base(strings.TrimSuffix(key, "::json"))
+			}
+			sb.WriteString(fmt.Sprintf("  %10s  %-4s %s\n", formatSize(entry.Size), entry.Type, key))
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
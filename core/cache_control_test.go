@@ -0,0 +1,138 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCacheReportAndInvalidation(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	ctx := context.Background()
+
+	sub := filepath.Join(dir, "sub")
+	os.Mkdir(sub, 0755)
+	small := filepath.Join(dir, "small.txt")
+	big := filepath.Join(sub, "big.txt")
+	os.WriteFile(small, []byte("tiny"), 0644)
+	os.WriteFile(big, []byte(strings.Repeat("x", 1000)), 0644)
+	for _, p := range []string{small, big} {
+		if _, err := engine.ReadFileContent(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := engine.ListDirectoryContent(ctx, sub); err != nil {
+		t.Fatal(err)
+	}
+	engine.ReadFileContent(ctx, small) // one file hit
+
+	report := engine.GetCacheReport(1)
+	files := report.Types[0]
+	if files.Type != "file" || files.Entries != 2 || files.Bytes != 1004 || files.Hits == 0 {
+		t.Errorf("file stats = %+v", files)
+	}
+	if report.Types[1].Entries != 1 {
+		t.Errorf("dir entries = %d, want 1", report.Types[1].Entries)
+	}
+	if len(report.Largest) != 1 || report.Largest[0].Key != big {
+		t.Errorf("largest = %+v, want %s", report.Largest, big)
+	}
+	if text := FormatCacheReport(report, false); !strings.Contains(text, "Largest entries") {
+		t.Errorf("report text = %q", text)
+	}
+
+	// invalidate_prefix drops everything under sub/ and nothing else
+	if removed, _ := engine.InvalidateCachePrefix(sub); removed != 2 {
+		t.Errorf("prefix removed %d entries, want 2 (file + listing)", removed)
+	}
+	if _, hit := engine.cache.GetFile(big); hit {
+		t.Error("file under the prefix is still cached")
+	}
+	if _, hit := engine.cache.GetFile(small); !hit {
+		t.Error("file outside the prefix was dropped")
+	}
+
+	engine.InvalidateCachePath(small)
+	if _, hit := engine.cache.GetFile(small); hit {
+		t.Error("invalidate_path left the file cached")
+	}
+}
+
+func TestClearCache_SafeDuringReads(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	ctx := context.Background()
+
+	var paths []string
+	for i := 0; i < 8; i++ {
+		p := filepath.Join(dir, fmt.Sprintf("f%d.txt", i))
+		os.WriteFile(p, []byte(strings.Repeat(fmt.Sprint(i), 512)), 0644)
+		paths = append(paths, p)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan string, 64)
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < 200; n++ {
+				i := n % len(paths)
+				got, err := engine.ReadFileContent(ctx, paths[i])
+				if err != nil || got != strings.Repeat(fmt.Sprint(i), 512) {
+					errs <- fmt.Sprintf("read %d: %v (len %d)", i, err, len(got))
+					return
+				}
+			}
+		}()
+	}
+	for n := 0; n < 5; n++ {
+		engine.ClearCache()
+	}
+	wg.Wait()
+	close(errs)
+	for e := range errs {
+		t.Error(e)
+	}
+}
+
+func TestPerformanceStats_PerTypeHitRatesAndBytes(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	ctx := context.Background()
+
+	path := filepath.Join(dir, "a.txt")
+	os.WriteFile(path, []byte(strings.Repeat("a", 100)), 0644)
+	for i := 0; i < 4; i++ {
+		if _, err := engine.ReadFileContent(ctx, path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	engine.ListDirectoryContent(ctx, dir)
+	engine.ListDirectoryContent(ctx, dir)
+
+	// One cold read is exactly one miss: the dedup re-checks are not counted
+	types := engine.cache.TypeStats()
+	if files := types[0]; files.Hits != 3 || files.Misses != 1 || files.Served != 300 {
+		t.Errorf("file stats = %+v, want 3 hits, 1 miss, 300 bytes served", files)
+	}
+	if dirs := types[1]; dirs.Hits != 1 || dirs.Misses != 1 || dirs.Served == 0 {
+		t.Errorf("dir stats = %+v", dirs)
+	}
+	if got := engine.cache.GetStats().FileBytesRead; got != 100 {
+		t.Errorf("bytes read from disk = %d, want 100", got)
+	}
+
+	stats := engine.GetPerformanceStats()
+	for _, want := range []string{"file: 75.00% (3 hits, 1 misses), 300 B served", "dir:  50.00%", "Cache Bytes: "} {
+		if !strings.Contains(stats, want) {
+			t.Errorf("performance stats missing %q:\n%s", want, stats)
+		}
+	}
+	engine.config.CompactMode = true
+	if compact := engine.GetPerformanceStats(); !strings.Contains(compact, "file:75% dir:50%") || !strings.Contains(compact, "disk:100 B") {
+		t.Errorf("compact stats = %q", compact)
+	}
+}
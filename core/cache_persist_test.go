@@ -0,0 +1,116 @@
+package core
+
+// With --cache-persist the cache is written to a snapshot on Close and the
+// next process warms up from it: only files whose size and mtime still match
+// are restored, and a damaged snapshot means a cold start, not a failure.
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mcp/filesystem-ultra/cache"
+)
+
+func TestCacheSnapshot_RestoresUnchangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	snapshot := filepath.Join(t.TempDir(), "cache-snapshot.gob")
+	hot := filepath.Join(dir, "hot.go")
+	edited := filepath.Join(dir, "edited.go")
+	cold := filepath.Join(dir, "cold.go")
+	for _, p := range []string{hot, edited, cold} {
+		if err := os.WriteFile(p, []byte("package "+filepath.Base(p)+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	first, err := cache.NewIntelligentCache(1024 * 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := first.EnablePersistence(snapshot, 40); err != nil {
+		t.Fatalf("missing snapshot must be a cold start: %v", err)
+	}
+	for _, p := range []string{hot, edited, cold} {
+		data, _ := os.ReadFile(p)
+		first.SetFile(p, data)
+	}
+	first.TrackAccess(hot)
+	first.TrackAccess(hot)
+	first.TrackAccess(edited)
+	if err := first.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Changed on disk while the server was down
+	os.WriteFile(edited, []byte("package edited // changed\n"), 0644)
+	later := time.Now().Add(time.Hour)
+	os.Chtimes(edited, later, later)
+
+	second, err := cache.NewIntelligentCache(1024 * 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Close()
+	stats, err := second.EnablePersistence(snapshot, 40)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	// Budget 40 bytes: hot.go (15) and edited.go (18) carry content, cold.go
+	// (15) is index only
+	if stats.Entries != 3 || stats.Contents != 1 || stats.Skipped != 1 {
+		t.Errorf("load stats = %+v, want 3 entries, 1 restored, 1 skipped", stats)
+	}
+	if got, hit := second.GetFile(hot); !hit || string(got) != "package hot.go\n" {
+		t.Errorf("hot file not restored: hit=%v %q", hit, got)
+	}
+	if _, hit := second.GetFile(edited); hit {
+		t.Error("file changed on disk was restored from the snapshot")
+	}
+	if second.GetAccessStats()[hot] != 2 {
+		t.Errorf("access count not restored: %v", second.GetAccessStats())
+	}
+}
+
+func TestCacheSnapshot_CorruptFileStartsCold(t *testing.T) {
+	snapshot := filepath.Join(t.TempDir(), "cache-snapshot.gob")
+	os.WriteFile(snapshot, []byte("not a snapshot"), 0600)
+
+	c, err := cache.NewIntelligentCache(1024 * 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.EnablePersistence(snapshot, 1024); err == nil {
+		t.Error("corrupt snapshot was not reported")
+	}
+	file := filepath.Join(t.TempDir(), "a.txt")
+	os.WriteFile(file, []byte("a"), 0644)
+	c.SetFile(file, []byte("a"))
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Close replaced the damaged file with a valid snapshot
+	again, _ := cache.NewIntelligentCache(1024 * 1024)
+	defer again.Close()
+	if stats, err := again.LoadSnapshot(snapshot); err != nil || stats.Contents != 1 {
+		t.Errorf("snapshot after recovery: %+v, %v", stats, err)
+	}
+
+	// The engine treats it as non-fatal too
+	os.WriteFile(snapshot, []byte{0xff, 0x00, 0x13}, 0600)
+	engineCache, _ := cache.NewIntelligentCache(1024 * 1024)
+	defer engineCache.Close()
+	engine, err := NewUltraFastEngine(&Config{
+		Cache:        engineCache,
+		AllowedPaths: []string{filepath.Dir(file)},
+		ParallelOps:  2,
+		BackupDir:    filepath.Dir(snapshot),
+		PersistCache: true,
+	})
+	if err != nil {
+		t.Fatalf("engine failed on a corrupt snapshot: %v", err)
+	}
+	engine.Close()
+}
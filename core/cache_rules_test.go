@@ -0,0 +1,124 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mcp/filesystem-ultra/cache"
+)
+
+func TestCacheRules_SizeCapNeverCacheAndTTL(t *testing.T) {
+	dir := t.TempDir()
+	c, err := cache.NewIntelligentCache(1024 * 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine, err := NewUltraFastEngine(&Config{
+		Cache:              c,
+		AllowedPaths:       []string{dir},
+		ParallelOps:        2,
+		CacheMaxFileSize:   100,
+		NeverCachePatterns: []string{"*.log", "scratch/**"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { engine.Close(); c.Close() })
+	ctx := context.Background()
+
+	write := func(name, content string) string {
+		t.Helper()
+		p := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(p), 0755)
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	readTwice := func(p string) {
+		t.Helper()
+		for i := 0; i < 2; i++ {
+			if _, err := engine.ReadFileContent(ctx, p); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	big := write("big.txt", strings.Repeat("x", 200))
+	logFile := write("server.log", "line\n")
+	tmpFile := write("scratch/notes.txt", "scratch")
+	small := write("small.txt", "ok")
+	for _, p := range []string{big, logFile, tmpFile, small} {
+		readTwice(p)
+	}
+
+	for _, p := range []string{big, logFile, tmpFile} {
+		if _, hit := c.GetFile(p); hit {
+			t.Errorf("%s was cached", filepath.Base(p))
+		}
+	}
+	if got := c.GetMemoryUsage(); got != 2 {
+		t.Errorf("memory usage = %d, want 2 (only small.txt)", got)
+	}
+	// Uncacheable reads are bypasses: only the first read of each file is a miss
+	stats := c.GetStats()
+	if stats.FileBypasses == 0 {
+		t.Error("bypassed reads were not counted")
+	}
+	if stats.FileHits != 1 {
+		t.Errorf("file hits = %d, want 1 (second read of small.txt)", stats.FileHits)
+	}
+
+	// A file that shrinks under the cap becomes cacheable again
+	write("big.txt", "short")
+	readTwice(big)
+	if _, hit := c.GetFile(big); !hit {
+		t.Error("file under the cap was not cached after shrinking")
+	}
+
+	// Per-entry TTL
+	c.SetFileTTL(20 * time.Millisecond)
+	defer c.SetFileTTL(0)
+	if _, hit := c.GetFile(small); !hit {
+		t.Fatal("fresh entry missed")
+	}
+	time.Sleep(40 * time.Millisecond)
+	if _, hit := c.GetFile(small); hit {
+		t.Error("entry older than the TTL was served")
+	}
+	if c.GetStats().ExpiredFiles != 1 {
+		t.Errorf("expired files = %d, want 1", c.GetStats().ExpiredFiles)
+	}
+}
+
+func TestCacheMemoryUsage_TracksRemovals(t *testing.T) {
+	dir := t.TempDir()
+	c, err := cache.NewIntelligentCache(1024 * 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	a := filepath.Join(dir, "a.txt")
+	b := filepath.Join(dir, "b.txt")
+	os.WriteFile(a, []byte("aaaa"), 0644)
+	os.WriteFile(b, []byte("bbbbbbbb"), 0644)
+	c.SetFile(a, []byte("aaaa"))
+	c.SetFile(b, []byte("bbbbbbbb"))
+	c.SetFile(a, []byte("aaaa")) // overwrite must not double count
+	if got := c.GetMemoryUsage(); got != 12 {
+		t.Fatalf("usage = %d, want 12", got)
+	}
+	c.InvalidateFile(b)
+	if got := c.GetMemoryUsage(); got != 4 {
+		t.Errorf("usage after invalidate = %d, want 4", got)
+	}
+	c.Clear()
+	if got := c.GetMemoryUsage(); got != 0 {
+		t.Errorf("usage after clear = %d, want 0", got)
+	}
+}
@@ -0,0 +1,71 @@
+package core
+
+// A file cached by read_file and then rewritten by another process (editor,
+// git checkout, build) must not be served from cache: the cache records the
+// file's size and mtime and drops the entry on the next hit when they differ.
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReadFileContent_ExternalRewriteInvalidatesCache(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	path := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(path, []byte("version one\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	read := func() string {
+		t.Helper()
+		got, err := engine.ReadFileContent(context.Background(), path)
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		return got
+	}
+	if got := read(); got != "version one\n" {
+		t.Fatalf("first read = %q", got)
+	}
+	if _, hit := engine.cache.GetFile(path); !hit {
+		t.Fatal("first read did not populate the cache")
+	}
+
+	// Same size, newer mtime: only the mtime tells the versions apart
+	rewrite := func(content string, age time.Duration) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := time.Now().Add(age)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rewrite("version two\n", time.Hour)
+	if got := read(); got != "version two\n" {
+		t.Errorf("read after external rewrite = %q, want fresh content", got)
+	}
+	if engine.cache.GetStats().StaleFiles == 0 {
+		t.Error("stale entry was not counted")
+	}
+
+	// A different size is detected as well
+	rewrite("version three, longer\n", 2*time.Hour)
+	if got := read(); got != "version three, longer\n" {
+		t.Errorf("read after resize = %q", got)
+	}
+
+	// With the check disabled the cached bytes are served as-is
+	engine.cache.SetStatValidation(false)
+	defer engine.cache.SetStatValidation(true)
+	engine.cache.InvalidateFile(path)
+	read()
+	rewrite("version four, longer\n", 3*time.Hour)
+	if got := read(); got != "version three, longer\n" {
+		t.Errorf("stat check disabled: read = %q, want the cached content", got)
+	}
+}
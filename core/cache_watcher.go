@@ -0,0 +1,281 @@
+package core
+
+import (
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultMaxWatches bounds the directories the cache watcher registers. On
+// Linux it is further capped at half of fs.inotify.max_user_watches so other
+// programs (editors, build tools) keep watches to spare.
+const DefaultMaxWatches = 8192
+
+// selfWriteWindow is how long after an engine mutation a watcher event for
+// the same path is attributed to the engine rather than to another process
+const selfWriteWindow = 2 * time.Second
+
+// selfWriteGrace delays that attribution: the engine invalidates right after
+// writing, so its event can arrive before the write is noted
+const selfWriteGrace = 200 * time.Millisecond
+
+// watchSkipDirs are never watched: they are large and churn constantly.
+// Files under them keep the per-hit stat check.
+var watchSkipDirs = map[string]bool{".git": true, "node_modules": true, RootTrashDirName: true}
+
+// cacheWatcher invalidates cache entries when files under the allowed paths
+// change on disk (fsnotify). Cache hits in a watched directory skip the
+// size/mtime stat; directories it could not watch (limit reached, skipped,
+// errors) keep that check, so a partial watch degrades to polling, never to
+// stale reads.
+type cacheWatcher struct {
+	engine *UltraFastEngine
+	fsw    *fsnotify.Watcher
+	limit  int
+
+	mu   sync.RWMutex
+	dirs map[string]bool
+	full bool // limit or inotify quota reached
+
+	external atomic.Int64 // change events not caused by the engine itself
+
+	selfMu     sync.Mutex
+	selfWrites map[string]time.Time
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// startCacheWatcher watches every root recursively (in the background) and
+// starts processing events. It fails only when no watcher can be created at
+// all; the caller then keeps relying on stat checks.
+func startCacheWatcher(e *UltraFastEngine, roots []string, limit int) (*cacheWatcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = DefaultMaxWatches
+	}
+	if quota := inotifyWatchQuota(); quota > 0 && quota/2 < limit {
+		limit = quota / 2
+	}
+
+	w := &cacheWatcher{
+		engine:     e,
+		fsw:        fsw,
+		limit:      limit,
+		dirs:       make(map[string]bool),
+		selfWrites: make(map[string]time.Time),
+		done:       make(chan struct{}),
+	}
+	w.wg.Add(2)
+	go func() {
+		defer w.wg.Done()
+		for _, root := range roots {
+			w.addTree(root)
+		}
+		w.mu.RLock()
+		slog.Info("Cache watcher ready", "directories", len(w.dirs), "limit", w.limit, "partial", w.full)
+		w.mu.RUnlock()
+	}()
+	go w.run()
+	return w, nil
+}
+
+// inotifyWatchQuota returns fs.inotify.max_user_watches on Linux, 0 elsewhere
+func inotifyWatchQuota() int {
+	if runtime.GOOS != "linux" {
+		return 0
+	}
+	data, err := os.ReadFile("/proc/sys/fs/inotify/max_user_watches")
+	if err != nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+	return n
+}
+
+// addTree watches root and its subdirectories until the limit is reached
+func (w *cacheWatcher) addTree(root string) {
+	_ = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		select {
+		case <-w.done:
+			return filepath.SkipAll
+		default:
+		}
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if p != root && watchSkipDirs[d.Name()] {
+			return filepath.SkipDir
+		}
+		if !w.add(p) {
+			return filepath.SkipAll
+		}
+		return nil
+	})
+}
+
+// add watches one directory; false means no more watches can be added
+func (w *cacheWatcher) add(dir string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.full {
+		return false
+	}
+	if w.dirs[dir] {
+		return true
+	}
+	if len(w.dirs) >= w.limit {
+		w.full = true
+		slog.Warn("Cache watcher limit reached; remaining directories use stat checks", "limit", w.limit)
+		return false
+	}
+	if err := w.fsw.Add(dir); err != nil {
+		if errors.Is(err, syscall.ENOSPC) || errors.Is(err, syscall.EMFILE) {
+			w.full = true
+			slog.Warn("Out of inotify watches; remaining directories use stat checks",
+				"watched", len(w.dirs), "error", err)
+			return false
+		}
+		return true // unreadable directory: skip it, keep going
+	}
+	w.dirs[dir] = true
+	return true
+}
+
+// watched reports whether changes in path's directory are being reported
+func (w *cacheWatcher) watched(path string) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.dirs[filepath.Dir(path)]
+}
+
+// run processes events until close
+func (w *cacheWatcher) run() {
+	defer w.wg.Done()
+	for {
+		select {
+		case ev, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+			w.handle(ev)
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return
+			}
+			if errors.Is(err, fsnotify.ErrEventOverflow) {
+				// Events were lost: nothing cached can be trusted any more
+				slog.Warn("Cache watcher event queue overflowed; clearing the cache")
+				w.engine.ClearCache()
+				continue
+			}
+			slog.Debug("Cache watcher error", "error", err)
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// handle invalidates everything an event can make stale and counts changes
+// made by other processes
+func (w *cacheWatcher) handle(ev fsnotify.Event) {
+	if ev.Op == fsnotify.Chmod {
+		return // permissions/atime only; content and listings are unchanged
+	}
+	path := NormalizePath(ev.Name)
+
+	// Not invalidateFileReadCache: that notes a self write
+	e := w.engine
+	forgetReadFlight(path)
+	if e.cache != nil {
+		e.cache.InvalidateFile(path)
+		e.cache.InvalidateMetadata(path)
+		e.cache.InvalidateMetadata(lineIndexKey(path))
+		e.cache.InvalidateMissing(path)
+	}
+	e.invalidateDirListing(filepath.Dir(path))
+	e.invalidateDirListing(path)
+
+	if ev.Has(fsnotify.Remove) || ev.Has(fsnotify.Rename) {
+		w.mu.Lock()
+		delete(w.dirs, path) // fsnotify drops the watch itself
+		w.mu.Unlock()
+	}
+	if ev.Has(fsnotify.Create) {
+		if info, err := os.Stat(path); err == nil && info.IsDir() && !watchSkipDirs[info.Name()] {
+			w.addTree(path)
+		}
+	}
+
+	// Atomic writes go through "<path>.tmp.<suffix>": attribute those events
+	// to the file being written
+	owner := path
+	if i := strings.LastIndex(path, ".tmp."); i > 0 {
+		owner = path[:i]
+	}
+	time.AfterFunc(selfWriteGrace, func() {
+		if !w.isSelfWrite(owner) {
+			w.external.Add(1)
+		}
+	})
+}
+
+// noteSelfWrite records that the engine itself is about to change path
+func (w *cacheWatcher) noteSelfWrite(path string) {
+	if w == nil {
+		return
+	}
+	now := time.Now()
+	w.selfMu.Lock()
+	defer w.selfMu.Unlock()
+	if len(w.selfWrites) > 1024 {
+		for p, t := range w.selfWrites {
+			if now.Sub(t) > selfWriteWindow {
+				delete(w.selfWrites, p)
+			}
+		}
+	}
+	w.selfWrites[path] = now
+}
+
+// isSelfWrite reports whether path was changed by the engine moments ago
+func (w *cacheWatcher) isSelfWrite(path string) bool {
+	w.selfMu.Lock()
+	defer w.selfMu.Unlock()
+	t, ok := w.selfWrites[path]
+	return ok && time.Since(t) <= selfWriteWindow
+}
+
+// close stops the watcher and waits for its goroutines
+func (w *cacheWatcher) close() {
+	close(w.done)
+	w.fsw.Close()
+	w.wg.Wait()
+}
+
+// WatcherStats reports the cache watcher state for performance stats:
+// whether it runs, how many directories it watches, whether the watch is
+// partial, and how many external modifications it has seen.
+func (e *UltraFastEngine) WatcherStats() (active bool, dirs int, partial bool, external int64) {
+	w := e.watcher
+	if w == nil {
+		return false, 0, false, 0
+	}
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return true, len(w.dirs), w.full, w.external.Load()
+}
@@ -0,0 +1,134 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mcp/filesystem-ultra/cache"
+)
+
+// setupWatchEngine returns an engine watching dir, or skips when the platform
+// cannot watch (the engine then runs on stat checks alone).
+func setupWatchEngine(t *testing.T) (*UltraFastEngine, string) {
+	t.Helper()
+	dir := t.TempDir()
+	c, err := cache.NewIntelligentCache(1024 * 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine, err := NewUltraFastEngine(&Config{Cache: c, AllowedPaths: []string{dir}, ParallelOps: 2, Watch: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { engine.Close(); c.Close() })
+	if engine.watcher == nil {
+		t.Skip("file watching unavailable on this platform")
+	}
+	// Wait for the initial walk
+	waitFor(t, "root watched", func() bool { return engine.watcher.watched(filepath.Join(dir, "x")) })
+	return engine, dir
+}
+
+// waitFor polls cond for up to two seconds
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestCacheWatcher_InvalidatesExternalChanges(t *testing.T) {
+	engine, dir := setupWatchEngine(t)
+	ctx := context.Background()
+
+	path := filepath.Join(dir, "main.go")
+	os.WriteFile(path, []byte("package one\n"), 0644)
+	if _, err := engine.ReadFileContent(ctx, path); err != nil {
+		t.Fatal(err)
+	}
+	if _, hit := engine.cache.GetFile(path); !hit {
+		t.Fatal("read did not populate the cache")
+	}
+	listing, _ := engine.ListDirectoryContent(ctx, dir)
+
+	// Another program rewrites the file and adds one next to it
+	os.WriteFile(path, []byte("package two\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "added.go"), []byte("package two\n"), 0644)
+	waitFor(t, "file entry invalidated", func() bool {
+		_, hit := engine.cache.GetFile(path)
+		return !hit
+	})
+	if got, _ := engine.ReadFileContent(ctx, path); got != "package two\n" {
+		t.Errorf("read after external change = %q", got)
+	}
+	waitFor(t, "listing refreshed", func() bool {
+		fresh, _ := engine.ListDirectoryContent(ctx, dir)
+		return fresh != listing && strings.Contains(fresh, "added.go")
+	})
+	waitFor(t, "external modifications counted", func() bool {
+		_, _, _, external := engine.WatcherStats()
+		return external > 0
+	})
+
+	// New subdirectories are watched too
+	sub := filepath.Join(dir, "pkg")
+	os.Mkdir(sub, 0755)
+	waitFor(t, "new directory watched", func() bool { return engine.watcher.watched(filepath.Join(sub, "a.go")) })
+
+	// The engine's own writes are not external modifications
+	time.Sleep(3 * selfWriteGrace)
+	_, _, _, before := engine.WatcherStats()
+	if err := engine.WriteFileContent(ctx, path, "package three\n"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(3 * selfWriteGrace)
+	if _, _, _, after := engine.WatcherStats(); after != before {
+		t.Errorf("engine write counted as external: %d -> %d", before, after)
+	}
+	if stats := engine.GetPerformanceStats(); !strings.Contains(stats, "External Modifications") {
+		t.Errorf("performance stats missing watcher lines:\n%s", stats)
+	}
+}
+
+func TestCacheWatcher_LimitFallsBackToStatChecks(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{"a", "b", "c"} {
+		os.Mkdir(filepath.Join(dir, sub), 0755)
+	}
+	c, _ := cache.NewIntelligentCache(1024 * 1024)
+	engine, err := NewUltraFastEngine(&Config{Cache: c, AllowedPaths: []string{dir}, ParallelOps: 2, Watch: true, MaxWatches: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { engine.Close(); c.Close() }()
+	if engine.watcher == nil {
+		t.Skip("file watching unavailable on this platform")
+	}
+	waitFor(t, "limit reached", func() bool {
+		_, dirs, partial, _ := engine.WatcherStats()
+		return partial && dirs == 2
+	})
+
+	// A file in an unwatched directory is still checked on every hit
+	var unwatched string
+	for _, sub := range []string{"a", "b", "c"} {
+		if p := filepath.Join(dir, sub, "f.txt"); !engine.watcher.watched(p) {
+			unwatched = p
+			break
+		}
+	}
+	os.WriteFile(unwatched, []byte("one"), 0644)
+	engine.ReadFileContent(context.Background(), unwatched)
+	os.WriteFile(unwatched, []byte("two!"), 0644)
+	if got, _ := engine.ReadFileContent(context.Background(), unwatched); got != "two!" {
+		t.Errorf("unwatched file served stale content %q", got)
+	}
+}
@@ -112,7 +112,7 @@ func (o *ClaudeDesktopOptimizer) IntelligentWrite(ctx context.Context, path, con
 	size := int64(len(content))
 
 	// Auto-select strategy sin logging excesivo
-	if size <= o.config.MaxDirectFileSize {
+	if size <= o.config.MaxDirectFileSize && !o.engine.OverBinaryThreshold(size) {
 		AppendSubOp(ctx, "direct_write")
 		return o.engine.WriteFileContent(ctx, path, content)
 	} else {
@@ -138,7 +138,16 @@ func (o *ClaudeDesktopOptimizer) IntelligentRead(ctx context.Context, path strin
 		slog.Info("Intelligent read", "path", path, "size", formatSize(size))
 	}
 
-	// Auto-select strategy
+	// Auto-select strategy. Over --binary-threshold the file is summarized
+	// from its start and end rather than read whole.
+	if o.engine.OverBinaryThreshold(size) {
+		AppendSubOp(ctx, "large_view")
+		view, err := o.engine.ReadLargeFileView(ctx, path, LargeViewHeadLines, LargeViewTailLines)
+		if err != nil {
+			return "", err
+		}
+		return FormatLargeFileView(view), nil
+	}
 	if size <= o.config.MaxDirectFileSize {
 		AppendSubOp(ctx, "direct_read")
 		return o.engine.ReadFileContent(ctx, path)
@@ -0,0 +1,343 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// analyze_directory compare check modes, cheapest first
+const (
+	CompareByName  = "name"  // presence only
+	CompareBySize  = "size"  // presence and size (default)
+	CompareByMtime = "mtime" // presence, size and modification time
+	CompareByHash  = "hash"  // presence, size and SHA-256 of same-size files
+)
+
+// compareMtimeSlack absorbs timestamp precision differences between
+// filesystems (FAT keeps 2 s, NTFS 100 ns, ext4 1 ns)
+const compareMtimeSlack = 2 * time.Second
+
+// DirDiffEntry is a path present on both sides that differs
+type DirDiffEntry struct {
+	Path    string `json:"path"`
+	Differs string `json:"differs"` // "type", "size", "mtime" or "content"
+	SizeA   int64  `json:"size_a"`
+	SizeB   int64  `json:"size_b"`
+	ModA    string `json:"modified_a,omitempty"` // RFC3339, with check:"mtime"
+	ModB    string `json:"modified_b,omitempty"`
+}
+
+// DirComparison is the analyze_directory compare response. Paths are relative to
+// both roots; a directory present on one side only is listed once ("dir/")
+// instead of file by file. Each list is capped at MaxSearchResults; the
+// counts cover everything.
+type DirComparison struct {
+	PathA          string         `json:"path_a"`
+	PathB          string         `json:"path_b"`
+	Check          string         `json:"check"`
+	FilesA         int            `json:"files_a"`
+	FilesB         int            `json:"files_b"`
+	OnlyInA        []string       `json:"only_in_a"`
+	OnlyInB        []string       `json:"only_in_b"`
+	Differing      []DirDiffEntry `json:"differing"`
+	OnlyInACount   int            `json:"only_in_a_count"`
+	OnlyInBCount   int            `json:"only_in_b_count"`
+	DifferingCount int            `json:"differing_count"`
+	Identical      int            `json:"identical"`
+	Truncated      bool           `json:"truncated,omitempty"`
+	Skipped        int            `json:"skipped,omitempty"` // unreadable entries
+	DurationMs     int64          `json:"duration_ms"`
+}
+
+// treeEntry is one walked entry of a compared tree
+type treeEntry struct {
+	dir     bool
+	regular bool
+	size    int64
+	mod     time.Time
+}
+
+// treeSnapshot walks root, skipping excludes; symlinks are not followed
+func treeSnapshot(ctx context.Context, root string, excludes *syncExcludes) (map[string]treeEntry, int, error) {
+	entries := make(map[string]treeEntry)
+	skipped := 0
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			skipped++
+			if d != nil && d.IsDir() && p != root {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if p == root {
+			return nil
+		}
+		if excludes.excluded(root, p, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			skipped++
+			return nil
+		}
+		rel, _ := filepath.Rel(root, p)
+		entries[filepath.ToSlash(rel)] = treeEntry{dir: d.IsDir(), regular: d.Type().IsRegular(), size: info.Size(), mod: info.ModTime()}
+		return nil
+	})
+	return entries, skipped, err
+}
+
+// CompareDirectories reports how the trees at pathA and pathB differ: paths
+// only in A, only in B, and files on both sides that differ under check
+// (name, size, mtime or hash; "" = size). Both trees are walked concurrently;
+// hash mode hashes only files whose sizes match. The default excludes (.git,
+// node_modules, ...) and exclude globs are skipped on both sides.
+func (e *UltraFastEngine) CompareDirectories(ctx context.Context, pathA, pathB, check string, exclude []string) (*DirComparison, error) {
+	switch check {
+	case "":
+		check = CompareBySize
+	case CompareByName, CompareBySize, CompareByMtime, CompareByHash:
+	default:
+		return nil, fmt.Errorf("invalid check %q: use name, size, mtime or hash", check)
+	}
+	pathA, pathB = NormalizePath(pathA), NormalizePath(pathB)
+	if err := e.acquireOperation(ctx, "search"); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	defer e.releaseOperation("search", start)
+
+	roots := [2]string{pathA, pathB}
+	for i, p := range roots {
+		if !e.IsPathAllowed(p) {
+			return nil, e.AccessDeniedError("compare_directories", p)
+		}
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, &PathError{Op: "compare_directories", Path: p, Err: err}
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("not a directory: %s (compare takes two directories or two files)", p)
+		}
+		if abs, err := filepath.Abs(p); err == nil {
+			roots[i] = abs
+		}
+	}
+
+	excludes := newSyncExcludes(exclude, nil)
+	var (
+		trees   [2]map[string]treeEntry
+		skipped [2]int
+		errs    [2]error
+		wg      sync.WaitGroup
+	)
+	for i := range roots {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			trees[i], skipped[i], errs[i] = treeSnapshot(ctx, roots[i], excludes)
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res := &DirComparison{PathA: roots[0], PathB: roots[1], Check: check, Skipped: skipped[0] + skipped[1],
+		OnlyInA: []string{}, OnlyInB: []string{}, Differing: []DirDiffEntry{}}
+	onlyIn := func(tree, other map[string]treeEntry) (files int, only []string) {
+		only = []string{}
+		for rel, entry := range tree {
+			if !entry.dir {
+				files++
+			}
+			if _, ok := other[rel]; ok || parentMissing(rel, other) {
+				continue
+			}
+			if entry.dir {
+				rel += "/"
+			}
+			only = append(only, rel)
+		}
+		sort.Strings(only)
+		return files, only
+	}
+	res.FilesA, res.OnlyInA = onlyIn(trees[0], trees[1])
+	res.FilesB, res.OnlyInB = onlyIn(trees[1], trees[0])
+
+	// Paths on both sides: cheap checks first, hashes only for equal sizes
+	var toHash []string
+	var diffs []DirDiffEntry
+	for rel, a := range trees[0] {
+		b, ok := trees[1][rel]
+		if !ok || (a.dir && b.dir) {
+			continue
+		}
+		diff := DirDiffEntry{Path: rel, SizeA: a.size, SizeB: b.size}
+		switch {
+		case a.dir != b.dir:
+			diff.Differs = "type"
+			diff.SizeA, diff.SizeB = 0, 0
+		case check == CompareByName:
+		case a.size != b.size:
+			diff.Differs = "size"
+		case check == CompareByMtime && absDuration(a.mod.Sub(b.mod)) > compareMtimeSlack:
+			diff.Differs = "mtime"
+			diff.ModA = a.mod.UTC().Format(time.RFC3339)
+			diff.ModB = b.mod.UTC().Format(time.RFC3339)
+		case check == CompareByHash && a.regular && b.regular:
+			toHash = append(toHash, rel)
+			continue
+		}
+		if diff.Differs != "" {
+			diffs = append(diffs, diff)
+		} else {
+			res.Identical++
+		}
+	}
+	if len(toHash) > 0 {
+		contentDiffs, hashErrs := e.compareHashes(ctx, roots, toHash)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		res.Skipped += hashErrs
+		res.Identical += len(toHash) - len(contentDiffs) - hashErrs
+		for _, rel := range contentDiffs {
+			size := trees[0][rel].size
+			diffs = append(diffs, DirDiffEntry{Path: rel, Differs: "content", SizeA: size, SizeB: size})
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Path < diffs[j].Path })
+	res.Differing = append(res.Differing, diffs...)
+	res.OnlyInACount, res.OnlyInBCount, res.DifferingCount = len(res.OnlyInA), len(res.OnlyInB), len(res.Differing)
+	if limit := e.config.MaxSearchResults; limit > 0 {
+		if len(res.OnlyInA) > limit || len(res.OnlyInB) > limit || len(res.Differing) > limit {
+			res.Truncated = true
+		}
+		res.OnlyInA = res.OnlyInA[:min(limit, len(res.OnlyInA))]
+		res.OnlyInB = res.OnlyInB[:min(limit, len(res.OnlyInB))]
+		res.Differing = res.Differing[:min(limit, len(res.Differing))]
+	}
+	res.DurationMs = time.Since(start).Milliseconds()
+	return res, nil
+}
+
+// parentMissing reports whether a parent directory of rel is absent from
+// other, in which case rel is covered by that directory's own entry
+func parentMissing(rel string, other map[string]treeEntry) bool {
+	if i := strings.LastIndex(rel, "/"); i >= 0 {
+		_, ok := other[rel[:i]]
+		return !ok
+	}
+	return false
+}
+
+// compareHashes hashes each rel under both roots on the worker pool and
+// returns the paths whose contents differ and how many could not be read
+func (e *UltraFastEngine) compareHashes(ctx context.Context, roots [2]string, rels []string) ([]string, int) {
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		differ []string
+		failed int
+	)
+	for _, rel := range rels {
+		rel := rel
+		task := func() {
+			defer wg.Done()
+			if ctx.Err() != nil {
+				return
+			}
+			a, errA := e.hashFileBuffered(filepath.Join(roots[0], filepath.FromSlash(rel)))
+			b, errB := e.hashFileBuffered(filepath.Join(roots[1], filepath.FromSlash(rel)))
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case errA != nil || errB != nil:
+				failed++
+			case a != b:
+				differ = append(differ, rel)
+			}
+		}
+		wg.Add(1)
+		if e.workerPool == nil || e.workerPool.Submit(task) != nil {
+			task()
+		}
+	}
+	wg.Wait()
+	return differ, failed
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// FormatDirComparison renders a DirComparison as text
+func FormatDirComparison(r *DirComparison, compact bool) string {
+	summary := fmt.Sprintf("%d only in A, %d only in B, %d differ, %d identical",
+		r.OnlyInACount, r.OnlyInBCount, r.DifferingCount, r.Identical)
+	if compact {
+		out := fmt.Sprintf("%s ↔ %s (%s): %s", r.PathA, r.PathB, r.Check, summary)
+		if r.Skipped > 0 {
+			out += fmt.Sprintf(", %d skipped", r.Skipped)
+		}
+		return out
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("A: %s (%d files)\nB: %s (%d files)\ncheck: %s — %s\n", r.PathA, r.FilesA, r.PathB, r.FilesB, r.Check, summary))
+	if r.OnlyInACount+r.OnlyInBCount+r.DifferingCount == 0 {
+		sb.WriteString("\n✅ The trees match\n")
+	}
+	section := func(title string, count int, items []string, mark string) {
+		if count == 0 {
+			return
+		}
+		sb.WriteString(fmt.Sprintf("\n%s (%d):\n", title, count))
+		for _, item := range items {
+			sb.WriteString(fmt.Sprintf("  %s %s\n", mark, item))
+		}
+		if count > len(items) {
+			sb.WriteString(fmt.Sprintf("  … %d more\n", count-len(items)))
+		}
+	}
+	section("Only in A", r.OnlyInACount, r.OnlyInA, "-")
+	section("Only in B", r.OnlyInBCount, r.OnlyInB, "+")
+	diffs := make([]string, 0, len(r.Differing))
+	for _, d := range r.Differing {
+		switch d.Differs {
+		case "size":
+			diffs = append(diffs, fmt.Sprintf("%s  size %s → %s", d.Path, formatSize(d.SizeA), formatSize(d.SizeB)))
+		case "mtime":
+			diffs = append(diffs, fmt.Sprintf("%s  modified %s → %s", d.Path, d.ModA, d.ModB))
+		case "content":
+			diffs = append(diffs, fmt.Sprintf("%s  content (same size, %s)", d.Path, formatSize(d.SizeA)))
+		default:
+			diffs = append(diffs, fmt.Sprintf("%s  file on one side, directory on the other", d.Path))
+		}
+	}
+	section("Differ", r.DifferingCount, diffs, "~")
+	if r.Skipped > 0 {
+		sb.WriteString(fmt.Sprintf("\n⚠️  %d unreadable entries skipped\n", r.Skipped))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
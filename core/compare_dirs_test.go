@@ -0,0 +1,76 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompareDirectories_Checks(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	old := time.Now().Add(-time.Hour)
+	write := func(root, rel, content string, mod time.Time) {
+		p := filepath.Join(root, rel)
+		os.MkdirAll(filepath.Dir(p), 0755)
+		os.WriteFile(p, []byte(content), 0644)
+		os.Chtimes(p, mod, mod)
+	}
+	write(a, "same.txt", "same", old)
+	write(b, "same.txt", "same", old)
+	write(a, "grown.txt", "short", old)
+	write(b, "grown.txt", "much longer", old)
+	write(a, "edited.txt", "abcd", old)
+	write(b, "edited.txt", "abce", old)
+	write(a, "touched.txt", "t", old)
+	write(b, "touched.txt", "t", time.Now())
+	write(a, "gone/x.txt", "x", old)
+	write(a, "gone/deep/y.txt", "y", old)
+	write(b, "new.txt", "n", old)
+	write(a, "node_modules/m.js", "m", old)
+	write(a, "kind", "file", old)
+	os.MkdirAll(filepath.Join(b, "kind"), 0755)
+
+	ctx := context.Background()
+	res, err := engine.CompareDirectories(ctx, a, b, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(res.OnlyInA, ",") != "gone/" || strings.Join(res.OnlyInB, ",") != "new.txt" {
+		t.Errorf("only in: A=%v B=%v", res.OnlyInA, res.OnlyInB)
+	}
+	differs := func(r *DirComparison) string {
+		var out []string
+		for _, d := range r.Differing {
+			out = append(out, d.Path+":"+d.Differs)
+		}
+		return strings.Join(out, ",")
+	}
+	if got := differs(res); got != "grown.txt:size,kind:type" || res.Identical != 3 {
+		t.Errorf("size check: %s, identical %d", got, res.Identical)
+	}
+
+	res, _ = engine.CompareDirectories(ctx, a, b, CompareByMtime, nil)
+	if got := differs(res); got != "grown.txt:size,kind:type,touched.txt:mtime" {
+		t.Errorf("mtime check: %s", got)
+	}
+	res, _ = engine.CompareDirectories(ctx, a, b, CompareByHash, []string{"grown.txt"})
+	if got := differs(res); got != "edited.txt:content,kind:type" || res.Identical != 2 {
+		t.Errorf("hash check: %s, identical %d", got, res.Identical)
+	}
+	if text := FormatDirComparison(res, false); !strings.Contains(text, "~ edited.txt  content (same size, 4 B)") || !strings.Contains(text, "- gone/") {
+		t.Errorf("text:\n%s", text)
+	}
+
+	engine.config.MaxSearchResults = 1
+	res, _ = engine.CompareDirectories(ctx, a, b, CompareByMtime, nil)
+	if !res.Truncated || res.DifferingCount != 3 || len(res.Differing) != 1 {
+		t.Errorf("capped: %+v", res)
+	}
+	if _, err := engine.CompareDirectories(ctx, a, b, "bytes", nil); err == nil {
+		t.Error("invalid check accepted")
+	}
+}
@@ -0,0 +1,209 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// analyze_directory compare modes for two files
+const (
+	CompareFileHash  = "hash"  // streamed SHA-256 of both (default)
+	CompareFileDiff  = "diff"  // unified diff
+	CompareFileStats = "stats" // lines added and removed only
+)
+
+// compareDiffMaxBytes caps each file read for a diff or stats comparison;
+// larger files are compared by hash
+const compareDiffMaxBytes = 4 * 1024 * 1024
+
+// compareDiffMaxCells caps the LCS table of the changed region (lines of A
+// times lines of B after the common start and end), about 32 MB of memory
+const compareDiffMaxCells = 4 << 20
+
+// FileComparison is the analyze_directory compare response for two files
+type FileComparison struct {
+	PathA     string `json:"path_a"`
+	PathB     string `json:"path_b"`
+	Mode      string `json:"mode"` // the mode used: hash when diff or stats fell back
+	Identical bool   `json:"identical"`
+	SizeA     int64  `json:"size_a"`
+	SizeB     int64  `json:"size_b"`
+	HashA     string `json:"sha256_a,omitempty"`
+	HashB     string `json:"sha256_b,omitempty"`
+	LinesA    int    `json:"lines_a,omitempty"`
+	LinesB    int    `json:"lines_b,omitempty"`
+	Added     int    `json:"lines_added,omitempty"`
+	Removed   int    `json:"lines_removed,omitempty"`
+	Diff      string `json:"diff,omitempty"`
+	Note      string `json:"note,omitempty"` // why diff or stats fell back to hash
+}
+
+// ComparesFiles reports whether analyze_directory compare with pathA is a
+// file comparison: pathA is an allowed regular file. Anything else goes to
+// CompareDirectories, which reports the error.
+func (e *UltraFastEngine) ComparesFiles(pathA string) bool {
+	pathA = NormalizePath(pathA)
+	if !e.IsPathAllowed(pathA) {
+		return false
+	}
+	info, err := os.Stat(pathA)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// CompareFileContents compares two regular files. hash streams both through
+// SHA-256; diff returns a unified diff and stats only the added and removed
+// line counts. Binary files, and files too large to diff, are compared by
+// hash instead, with Note saying why.
+func (e *UltraFastEngine) CompareFileContents(ctx context.Context, pathA, pathB, mode string) (*FileComparison, error) {
+	switch mode {
+	case "":
+		mode = CompareFileHash
+	case CompareFileHash, CompareFileDiff, CompareFileStats:
+	default:
+		return nil, fmt.Errorf("invalid mode %q: use hash, diff or stats", mode)
+	}
+	pathA, pathB = NormalizePath(pathA), NormalizePath(pathB)
+	if err := e.acquireOperation(ctx, "search"); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	defer e.releaseOperation("search", start)
+
+	r := &FileComparison{PathA: pathA, PathB: pathB, Mode: mode}
+	var types [2]ContentType
+	for i, p := range []string{pathA, pathB} {
+		if !e.IsPathAllowed(p) {
+			return nil, e.AccessDeniedError("compare_files", p)
+		}
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, &PathError{Op: "compare_files", Path: p, Err: err}
+		}
+		if !info.Mode().IsRegular() {
+			return nil, fmt.Errorf("not a regular file: %s", p)
+		}
+		if i == 0 {
+			r.SizeA = info.Size()
+		} else {
+			r.SizeB = info.Size()
+		}
+		if types[i], err = DetectFileContentType(p); err != nil {
+			return nil, &PathError{Op: "compare_files", Path: p, Err: err}
+		}
+	}
+
+	if mode != CompareFileHash {
+		switch {
+		case !types[0].Text() || !types[1].Text():
+			r.Note = "binary file: compared by hash"
+		case r.SizeA > compareDiffMaxBytes || r.SizeB > compareDiffMaxBytes:
+			r.Note = fmt.Sprintf("larger than %s: compared by hash", formatSize(compareDiffMaxBytes))
+		default:
+			return r, e.compareLines(r)
+		}
+		r.Mode = CompareFileHash
+	}
+	return r, e.compareHashed(r)
+}
+
+// compareHashed fills the hashes and verdict of r
+func (e *UltraFastEngine) compareHashed(r *FileComparison) error {
+	var err error
+	if r.HashA, err = e.hashFileBuffered(r.PathA); err != nil {
+		return &PathError{Op: "compare_files", Path: r.PathA, Err: err}
+	}
+	if r.HashB, err = e.hashFileBuffered(r.PathB); err != nil {
+		return &PathError{Op: "compare_files", Path: r.PathB, Err: err}
+	}
+	r.Identical = r.HashA == r.HashB
+	return nil
+}
+
+// compareLines fills the line counts, and for diff mode the diff, of two
+// text files, falling back to compareHashed when the changed region is too
+// large for the LCS table
+func (e *UltraFastEngine) compareLines(r *FileComparison) error {
+	a, err := os.ReadFile(r.PathA)
+	if err != nil {
+		return &PathError{Op: "compare_files", Path: r.PathA, Err: err}
+	}
+	b, err := os.ReadFile(r.PathB)
+	if err != nil {
+		return &PathError{Op: "compare_files", Path: r.PathB, Err: err}
+	}
+	oldLines, newLines := splitLines(string(a)), splitLines(string(b))
+	r.LinesA, r.LinesB = len(oldLines), len(newLines)
+	if string(a) == string(b) {
+		r.Identical = true
+		return nil
+	}
+	if diffCells(oldLines, newLines) > compareDiffMaxCells {
+		r.Note = "too many changed lines to diff: compared by hash"
+		r.Mode = CompareFileHash
+		return e.compareHashed(r)
+	}
+	hunks := computeHunks(oldLines, newLines, 3)
+	for _, h := range hunks {
+		for _, l := range h.lines {
+			switch {
+			case strings.HasPrefix(l, "+"):
+				r.Added++
+			case strings.HasPrefix(l, "-"):
+				r.Removed++
+			}
+		}
+	}
+	if r.Mode == CompareFileDiff {
+		r.Diff = formatHunksNamed(hunks, filepath.ToSlash(r.PathA), filepath.ToSlash(r.PathB))
+	}
+	return nil
+}
+
+// FormatFileComparison renders a FileComparison as text
+func FormatFileComparison(r *FileComparison, compact bool) string {
+	verdict := "identical"
+	if !r.Identical {
+		verdict = "different"
+		if r.Mode != CompareFileHash {
+			verdict += fmt.Sprintf(" (+%d -%d)", r.Added, r.Removed)
+		}
+	}
+	note := ""
+	if r.Note != "" {
+		note = " — " + r.Note
+	}
+	if compact {
+		out := fmt.Sprintf("%s ↔ %s (%s): %s%s", r.PathA, r.PathB, r.Mode, verdict, note)
+		if r.Diff != "" {
+			out += "\n" + r.Diff
+		}
+		return strings.TrimRight(out, "\n")
+	}
+
+	var sb strings.Builder
+	side := func(label, path string, size int64, lines int, hash string) {
+		sb.WriteString(fmt.Sprintf("%s: %s (%s", label, path, formatSize(size)))
+		if lines > 0 {
+			sb.WriteString(fmt.Sprintf(", %d lines", lines))
+		}
+		sb.WriteString(")\n")
+		if hash != "" {
+			sb.WriteString(fmt.Sprintf("   sha256 %s\n", hash))
+		}
+	}
+	side("A", r.PathA, r.SizeA, r.LinesA, r.HashA)
+	side("B", r.PathB, r.SizeB, r.LinesB, r.HashB)
+	mark := "✅"
+	if !r.Identical {
+		mark = "≠"
+	}
+	sb.WriteString(fmt.Sprintf("%s %s by %s%s\n", mark, verdict, r.Mode, note))
+	if r.Diff != "" {
+		sb.WriteString("\n" + r.Diff)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
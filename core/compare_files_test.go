@@ -0,0 +1,78 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompareFileContents_Modes(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	ctx := context.Background()
+	write := func(name, content string) string {
+		p := filepath.Join(dir, name)
+		os.WriteFile(p, []byte(content), 0644)
+		return p
+	}
+	a := write("a.txt", "one\ntwo\nthree\nfour\n")
+	same := write("same.txt", "one\ntwo\nthree\nfour\n")
+	b := write("b.txt", "one\n2\nthree\nfour\nfive\n")
+
+	res, err := engine.CompareFileContents(ctx, a, same, "")
+	if err != nil || !res.Identical || res.Mode != CompareFileHash || res.HashA == "" || res.HashA != res.HashB {
+		t.Fatalf("hash identical = %+v, %v", res, err)
+	}
+	res, err = engine.CompareFileContents(ctx, a, b, "hash")
+	if err != nil || res.Identical || res.HashA == res.HashB {
+		t.Errorf("hash different = %+v, %v", res, err)
+	}
+
+	res, err = engine.CompareFileContents(ctx, a, b, "stats")
+	if err != nil || res.Identical || res.Added != 2 || res.Removed != 1 || res.Diff != "" || res.LinesB != 5 {
+		t.Errorf("stats = %+v, %v", res, err)
+	}
+
+	res, err = engine.CompareFileContents(ctx, a, b, "diff")
+	if err != nil || !strings.Contains(res.Diff, "-two\n+2\n") || !strings.Contains(res.Diff, "+five") {
+		t.Fatalf("diff = %+v, %v", res, err)
+	}
+	if text := FormatFileComparison(res, false); !strings.Contains(text, "≠ different (+2 -1) by diff") || !strings.Contains(text, "+++ "+filepath.ToSlash(b)) {
+		t.Errorf("diff text:\n%s", text)
+	}
+
+	// Binary files short-circuit to a hash comparison
+	bin := write("a.bin", "x\x00y")
+	res, err = engine.CompareFileContents(ctx, bin, a, "diff")
+	if err != nil || res.Mode != CompareFileHash || res.Note == "" || res.HashA == "" || res.Diff != "" {
+		t.Errorf("binary diff = %+v, %v", res, err)
+	}
+
+	if _, err := engine.CompareFileContents(ctx, a, b, "bytes"); err == nil {
+		t.Error("invalid mode accepted")
+	}
+	if _, err := engine.CompareFileContents(ctx, a, dir, ""); err == nil {
+		t.Error("a directory was compared as a file")
+	}
+	if !engine.ComparesFiles(a) || engine.ComparesFiles(dir) {
+		t.Error("ComparesFiles misroutes")
+	}
+}
+
+func TestDiff_LargeFileSmallChange(t *testing.T) {
+	// A one-line change in a large file must not build a full LCS table
+	lines := make([]string, 20000)
+	for i := range lines {
+		lines[i] = strings.Repeat("x", i%40)
+	}
+	changed := append([]string(nil), lines...)
+	changed[10000] = "changed"
+	if cells := diffCells(lines, changed); cells != 4 {
+		t.Errorf("diffCells = %d, want 4", cells)
+	}
+	added, removed := countChanges(lines, changed)
+	if added != 1 || removed != 1 {
+		t.Errorf("changes = +%d -%d", added, removed)
+	}
+}
@@ -46,6 +46,15 @@
 	MaxPipelineSteps = 20  // Maximum number of steps per pipeline
 	MaxPipelineFiles = 100 // Maximum number of files affected by a pipeline
 
+	// Upper bound for timeout_seconds / max_duration_seconds (1 hour)
+	MaxPipelineDurationSeconds = 3600
+
+	// Largest per-file diff snippet kept in verbose pipeline results
+	MaxPipelineDiffSnippet = 4 * 1024
+
+	// Files a single batch path/source glob may expand to (max_matches default)
+	DefaultBatchGlobMaxMatches = 100
+
 	// Pipeline risk assessment thresholds (based on number of files)
 	PipelineRiskMedium   = 30 // 30+ files = MEDIUM risk
 	PipelineRiskHigh     = 50 // 50+ files = HIGH risk
@@ -69,4 +78,15 @@
 	// Prevents accidental multi-MB output that wastes tokens (~500KB ≈ 125k tokens).
 	// Operators can override via Config.MaxSearchOutputBytes; 0 = use default.
 	DefaultMaxSearchOutputBytes = 500 * 1024
+
+	// OperationReportSize is the ring buffer capacity behind server_info report
+	// (most recent mutating operations kept in memory).
+	OperationReportSize = 500
+
+	// DefaultCachePersistBudget caps the file contents written to the cache
+	// snapshot (--cache-persist); the index of every cached file is always kept.
+	DefaultCachePersistBudget = 32 * 1024 * 1024
 )
+
+// cacheSnapshotFile is the cache snapshot written under the backup dir
+const cacheSnapshotFile = "cache-snapshot.gob"
@@ -0,0 +1,170 @@
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultConfirmTokenTTL is how long a confirmation token stays valid when
+// --confirm-token-ttl is not set.
+const DefaultConfirmTokenTTL = 10 * time.Minute
+
+// ConfirmTokenKey is the context key carrying the caller's confirm_token
+// argument from the tool handler down to the engine gate.
+type ConfirmTokenKey struct{}
+
+// WithConfirmToken returns ctx annotated with a confirm_token. Empty tokens
+// leave ctx unchanged.
+func WithConfirmToken(ctx context.Context, token string) context.Context {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, ConfirmTokenKey{}, token)
+}
+
+func confirmTokenFrom(ctx context.Context) string {
+	token, _ := ctx.Value(ConfirmTokenKey{}).(string)
+	return token
+}
+
+// ConfirmationRequiredError is returned instead of executing a HIGH/CRITICAL
+// operation when --confirm-tokens is enabled. It carries a one-time token and
+// the impact summary; re-issuing the identical operation with confirm_token
+// executes it.
+type ConfirmationRequiredError struct {
+	Token     string
+	Tool      string
+	Path      string
+	Impact    *ChangeImpact
+	ExpiresAt time.Time
+	Reason    string // why a previous token was rejected (empty on first request)
+}
+
+func (e *ConfirmationRequiredError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("CONFIRMATION REQUIRED: %s on %s is %s risk — no changes written\n",
+		e.Tool, e.Path, strings.ToUpper(e.Impact.RiskLevel)))
+	if e.Reason != "" {
+		sb.WriteString(fmt.Sprintf("Previous token rejected: %s\n", e.Reason))
+	}
+	sb.WriteString(fmt.Sprintf("Impact: %d replacement(s), ~%d bytes, %.1f%% of file (%d lines)\n",
+		e.Impact.Occurrences, e.Impact.CharactersChanged, e.Impact.ChangePercentage, e.Impact.TotalLines))
+	for _, factor := range e.Impact.RiskFactors {
+		sb.WriteString(fmt.Sprintf("  %s\n", factor))
+	}
+	sb.WriteString(fmt.Sprintf("confirm_token: %s (single use, expires %s)\n",
+		e.Token, e.ExpiresAt.Format(time.RFC3339)))
+	sb.WriteString("To proceed, re-issue the SAME call with confirm_token set. The token is invalidated if the file changes first. force:true does not bypass this gate.")
+	return sb.String()
+}
+
+// pendingConfirmation binds a token to one exact operation on one file state.
+type pendingConfirmation struct {
+	tool      string
+	path      string
+	opKey     string // fingerprint of the operation arguments
+	fileHash  string // content hash when the token was issued
+	expiresAt time.Time
+}
+
+// confirmationStore tracks outstanding confirmation tokens. Tokens are single
+// use: redeeming (successfully or not) removes them.
+type confirmationStore struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	pending map[string]*pendingConfirmation
+}
+
+func newConfirmationStore(ttl time.Duration) *confirmationStore {
+	if ttl <= 0 {
+		ttl = DefaultConfirmTokenTTL
+	}
+	return &confirmationStore{ttl: ttl, pending: make(map[string]*pendingConfirmation)}
+}
+
+func (s *confirmationStore) issue(tool, path, opKey, fileHash string) (string, time.Time) {
+	buf := make([]byte, 8)
+	rand.Read(buf)
+	token := "ct_" + hex.EncodeToString(buf)
+	expiresAt := time.Now().Add(s.ttl)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for t, p := range s.pending {
+		if now.After(p.expiresAt) {
+			delete(s.pending, t)
+		}
+	}
+	s.pending[token] = &pendingConfirmation{
+		tool: tool, path: path, opKey: opKey, fileHash: fileHash, expiresAt: expiresAt,
+	}
+	return token, expiresAt
+}
+
+// redeem consumes token and reports why it does not authorize the operation,
+// or "" when it does.
+func (s *confirmationStore) redeem(token, tool, path, opKey, fileHash string) string {
+	s.mu.Lock()
+	p, ok := s.pending[token]
+	delete(s.pending, token)
+	s.mu.Unlock()
+
+	switch {
+	case !ok:
+		return "unknown or already used token"
+	case time.Now().After(p.expiresAt):
+		return "token expired"
+	case p.tool != tool || p.path != path || p.opKey != opKey:
+		return "token was issued for a different operation"
+	case p.fileHash != fileHash:
+		return "file changed since the token was issued"
+	}
+	return ""
+}
+
+// requireConfirmation gates HIGH/CRITICAL mutations when --confirm-tokens is
+// enabled. Without a valid confirm_token in ctx it issues a fresh token and
+// returns a *ConfirmationRequiredError; with one it consumes the token and
+// records it on the audit entry. No-op when the mode is off.
+func (e *UltraFastEngine) requireConfirmation(ctx context.Context, tool, path, opKey, content string, impact *ChangeImpact) error {
+	if e.confirmTokens == nil || (impact.RiskLevel != "high" && impact.RiskLevel != "critical") {
+		return nil
+	}
+	fileHash := contentHashFNV(content)
+
+	reason := ""
+	if token := confirmTokenFrom(ctx); token != "" {
+		if reason = e.confirmTokens.redeem(token, tool, path, opKey, fileHash); reason == "" {
+			SetConfirmToken(ctx, token)
+			return nil
+		}
+	}
+
+	token, expiresAt := e.confirmTokens.issue(tool, path, opKey, fileHash)
+	return &ConfirmationRequiredError{
+		Token:     token,
+		Tool:      tool,
+		Path:      path,
+		Impact:    impact,
+		ExpiresAt: expiresAt,
+		Reason:    reason,
+	}
+}
+
+// ConfirmTokensEnabled reports whether --confirm-tokens mode is active.
+func (e *UltraFastEngine) ConfirmTokensEnabled() bool {
+	return e.confirmTokens != nil
+}
+
+// editOpKey fingerprints the arguments of an edit so a token only confirms the
+// exact operation it was issued for.
+func editOpKey(parts ...string) string {
+	return contentHashFNV(strings.Join(parts, "\x00"))
+}
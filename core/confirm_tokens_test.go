@@ -0,0 +1,107 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mcp/filesystem-ultra/cache"
+)
+
+func setupConfirmEngine(t *testing.T) (*UltraFastEngine, string) {
+	t.Helper()
+	dir := t.TempDir()
+	c, err := cache.NewIntelligentCache(1024 * 1024)
+	if err != nil {
+		t.Fatalf("cache: %v", err)
+	}
+	engine, err := NewUltraFastEngine(&Config{
+		Cache:         c,
+		AllowedPaths:  []string{dir},
+		ParallelOps:   2,
+		BackupDir:     t.TempDir(),
+		ConfirmTokens: true,
+	})
+	if err != nil {
+		t.Fatalf("engine: %v", err)
+	}
+	t.Cleanup(func() { engine.Close(); c.Close() })
+	return engine, dir
+}
+
+func TestConfirmTokens_BlockThenConfirm(t *testing.T) {
+	engine, dir := setupConfirmEngine(t)
+	original := strings.Repeat("old line\n", 20)
+	path := createTestFile(t, dir, "big.txt", original)
+	ctx := context.Background()
+
+	// CRITICAL rewrite without a token: blocked, nothing written
+	_, err := engine.EditFile(ctx, path, original, "new\n", true, false, false)
+	var confirmErr *ConfirmationRequiredError
+	if !errors.As(err, &confirmErr) {
+		t.Fatalf("expected ConfirmationRequiredError (force must not bypass), got %v", err)
+	}
+	if !strings.HasPrefix(confirmErr.Token, "ct_") || !strings.Contains(err.Error(), confirmErr.Token) {
+		t.Errorf("token missing from error: %q", err.Error())
+	}
+	if got, _ := os.ReadFile(path); string(got) != original {
+		t.Fatalf("file modified while blocked")
+	}
+
+	// Token for a different operation is rejected and a new token issued
+	_, err = engine.EditFile(WithConfirmToken(ctx, confirmErr.Token), path, original, "other\n", false, false, false)
+	var retryErr *ConfirmationRequiredError
+	if !errors.As(err, &retryErr) || !strings.Contains(retryErr.Reason, "different operation") {
+		t.Fatalf("expected rejection for different operation, got %v", err)
+	}
+
+	// The previous token was single-use; confirm with the fresh one for the same edit
+	_, err = engine.EditFile(ctx, path, original, "new\n", false, false, false)
+	if !errors.As(err, &confirmErr) {
+		t.Fatalf("expected new token, got %v", err)
+	}
+	if _, err := engine.EditFile(WithConfirmToken(ctx, confirmErr.Token), path, original, "new\n", false, false, false); err != nil {
+		t.Fatalf("confirmed edit failed: %v", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "new\n" {
+		t.Errorf("content = %q, want %q", got, "new\n")
+	}
+}
+
+func TestConfirmTokens_FileChangeInvalidatesToken(t *testing.T) {
+	engine, dir := setupConfirmEngine(t)
+	original := strings.Repeat("line\n", 20)
+	path := createTestFile(t, dir, "changed.txt", original)
+	ctx := context.Background()
+
+	_, err := engine.EditFile(ctx, path, "line\n", "LINE\n", false, false, false)
+	var confirmErr *ConfirmationRequiredError
+	if !errors.As(err, &confirmErr) {
+		t.Fatalf("expected ConfirmationRequiredError, got %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte(original+"line\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = engine.EditFile(WithConfirmToken(ctx, confirmErr.Token), path, "line\n", "LINE\n", false, false, false)
+	var retryErr *ConfirmationRequiredError
+	if !errors.As(err, &retryErr) || !strings.Contains(retryErr.Reason, "file changed") {
+		t.Fatalf("expected file-changed rejection, got %v", err)
+	}
+}
+
+func TestConfirmTokens_LowRiskAndDryRunUnaffected(t *testing.T) {
+	engine, dir := setupConfirmEngine(t)
+	path := createTestFile(t, dir, "small.txt", strings.Repeat("keep this line intact\n", 20)+"target\n")
+	ctx := context.Background()
+
+	if _, err := engine.EditFile(ctx, path, "target", "TARGET", false, false, false); err != nil {
+		t.Fatalf("low-risk edit should not need a token: %v", err)
+	}
+	content, _ := os.ReadFile(path)
+	if _, err := engine.EditFile(ctx, path, string(content), "x", false, true, false); err != nil {
+		t.Fatalf("dry_run should not need a token: %v", err)
+	}
+}
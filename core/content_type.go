@@ -0,0 +1,107 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// sniffLen is how much of a file content-type detection looks at, the same
+// window http.DetectContentType considers
+const sniffLen = 512
+
+// ContentType is the detected type of a file's content
+type ContentType struct {
+	MIME   string `json:"mime"`
+	Binary bool   `json:"is_binary"`
+}
+
+// Text reports whether the content can be searched and edited as UTF-8
+// text: not binary, and not UTF-16/UTF-32 (matching lines in those would
+// come out garbled)
+func (c ContentType) Text() bool {
+	return !c.Binary && !strings.Contains(c.MIME, "charset=utf-16") && !strings.Contains(c.MIME, "charset=utf-32")
+}
+
+// extensionMIME refines text content whose sniffed type is generic. The
+// sniffer only knows a handful of text formats (HTML, XML, plain), so a Go
+// file and a JSON file both come back as text/plain.
+var extensionMIME = map[string]string{
+	".txt": "text/plain", ".md": "text/markdown", ".rst": "text/x-rst",
+	".go": "text/x-go", ".mod": "text/plain", ".sum": "text/plain",
+	".js": "text/javascript", ".mjs": "text/javascript", ".cjs": "text/javascript", ".jsx": "text/jsx",
+	".ts": "application/typescript", ".tsx": "application/typescript",
+	".py": "text/x-python", ".java": "text/x-java", ".kt": "text/x-kotlin", ".scala": "text/x-scala",
+	".c": "text/x-c", ".h": "text/x-c", ".cpp": "text/x-c++", ".cc": "text/x-c++", ".hpp": "text/x-c++",
+	".cs": "text/x-csharp", ".rs": "text/x-rust", ".rb": "text/x-ruby", ".php": "application/x-php",
+	".swift": "text/x-swift", ".lua": "text/x-lua", ".pl": "text/x-perl",
+	".sh": "application/x-sh", ".bash": "application/x-sh", ".ps1": "text/x-powershell", ".bat": "application/x-bat",
+	".html": "text/html", ".htm": "text/html", ".css": "text/css", ".scss": "text/x-scss",
+	".json": "application/json", ".jsonc": "application/json", ".xml": "application/xml",
+	".yaml": "application/yaml", ".yml": "application/yaml", ".toml": "application/toml",
+	".ini": "text/plain", ".csv": "text/csv", ".tsv": "text/tab-separated-values",
+	".sql": "application/sql", ".svg": "image/svg+xml",
+}
+
+// DetectContentType classifies content by its first bytes (only the first
+// 512 are looked at) and, for text, the extension of name. Content decides
+// binary versus text; the extension only names the text format.
+func DetectContentType(name string, head []byte) ContentType {
+	if len(head) > sniffLen {
+		head = head[:sniffLen]
+	}
+	// http.DetectContentType knows the UTF-16 BOMs but not the UTF-32 ones,
+	// and would call these binary for their NUL bytes
+	switch {
+	case bytes.HasPrefix(head, []byte{0xFF, 0xFE, 0x00, 0x00}):
+		return ContentType{MIME: "text/plain; charset=utf-32le"}
+	case bytes.HasPrefix(head, []byte{0x00, 0x00, 0xFE, 0xFF}):
+		return ContentType{MIME: "text/plain; charset=utf-32be"}
+	}
+
+	sniffed := http.DetectContentType(head)
+	if strings.Contains(sniffed, "charset=utf-16") {
+		return ContentType{MIME: sniffed}
+	}
+	if bytes.IndexByte(head, 0) >= 0 || !textualMIME(sniffed) {
+		return ContentType{MIME: sniffed, Binary: true}
+	}
+	if m, ok := extensionMIME[strings.ToLower(filepath.Ext(name))]; ok {
+		return ContentType{MIME: m}
+	}
+	return ContentType{MIME: sniffed}
+}
+
+// detectStringContentType is DetectContentType for content already in memory
+// as a string, without copying more than the sniffed prefix
+func detectStringContentType(name, content string) ContentType {
+	if len(content) > sniffLen {
+		content = content[:sniffLen]
+	}
+	return DetectContentType(name, []byte(content))
+}
+
+// DetectFileContentType reads the first 512 bytes of path and classifies them
+func DetectFileContentType(path string) (ContentType, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return ContentType{}, err
+	}
+	defer f.Close()
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(f, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return ContentType{}, err
+	}
+	return DetectContentType(path, head[:n]), nil
+}
+
+// textualMIME reports whether a sniffed type is a text format
+func textualMIME(m string) bool {
+	return strings.HasPrefix(m, "text/") || strings.Contains(m, "json") ||
+		strings.Contains(m, "xml") || strings.Contains(m, "javascript")
+}
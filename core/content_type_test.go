@@ -0,0 +1,87 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectContentType(t *testing.T) {
+	cases := []struct {
+		name   string
+		head   []byte
+		mime   string
+		binary bool
+		text   bool
+	}{
+		{"main.go", []byte("package main\n"), "text/x-go", false, true},
+		{"data.json", []byte(`{"a": 1}`), "application/json", false, true},
+		{"notes", []byte("plain words\n"), "text/plain; charset=utf-8", false, true},
+		{"empty.txt", nil, "text/plain", false, true},
+		{"page.md", []byte("<html><body>hi</body></html>"), "text/markdown", false, true},
+		{"logo.png", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), "image/png", true, false},
+		{"blob.txt", []byte("abc\x00def"), "application/octet-stream", true, false},
+		{"doc.pdf", []byte("%PDF-1.7\n"), "application/pdf", true, false},
+		{"wide.cs", []byte{0xFF, 0xFE, 'a', 0x00}, "text/plain; charset=utf-16le", false, false},
+		{"wider.cs", []byte{0xFF, 0xFE, 0x00, 0x00, 'a', 0x00, 0x00, 0x00}, "text/plain; charset=utf-32le", false, false},
+	}
+	for _, c := range cases {
+		got := DetectContentType(c.name, c.head)
+		if got.MIME != c.mime || got.Binary != c.binary || got.Text() != c.text {
+			t.Errorf("%s: got %+v (text=%v), want mime %q binary=%v text=%v", c.name, got, got.Text(), c.mime, c.binary, c.text)
+		}
+	}
+}
+
+func TestContentType_Surfaces(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	ctx := context.Background()
+	src := filepath.Join(dir, "main.go")
+	bin := filepath.Join(dir, "main.bin")
+	os.WriteFile(src, []byte("package main\n"), 0644)
+	os.WriteFile(bin, []byte("mainmain\x00\x01\x02"), 0644)
+
+	info, err := engine.FileInfo(ctx, bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.MIME != "application/octet-stream" || !info.IsBinary {
+		t.Errorf("binary file info = %+v", info)
+	}
+	if text := FormatFileInfo(info, false); !strings.Contains(text, "🧾 Content: application/octet-stream (binary)") {
+		t.Errorf("prose misses the content type:\n%s", text)
+	}
+
+	analysis, err := engine.GetFileAnalysis(ctx, src)
+	if err != nil || !strings.Contains(analysis, "Type: Text file, text/x-go (editable)") {
+		t.Errorf("analysis = %q, %v", analysis, err)
+	}
+
+	out, err := engine.performSmartSearch(ctx, dir, "main", false, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resp FileSearchResponse
+	if err := json.Unmarshal([]byte(out), &resp); err != nil {
+		t.Fatalf("search JSON: %v\n%s", err, out)
+	}
+	kinds := map[string]bool{}
+	for _, f := range resp.Files {
+		kinds[filepath.Base(f.Path)] = f.Binary
+	}
+	if len(kinds) != 2 || kinds["main.go"] || !kinds["main.bin"] {
+		t.Errorf("search files = %+v", resp.Files)
+	}
+	if !strings.Contains(out, `"is_binary": true`) || !strings.Contains(out, `"mime": "text/x-go"`) {
+		t.Errorf("search JSON misses mime/is_binary:\n%s", out)
+	}
+
+	// search_and_replace skips what the detector calls binary
+	n, err := engine.searchAndReplaceInFile(bin, "main", "MAIN", true, false)
+	if err != nil || n != 0 {
+		t.Errorf("replace in binary = %d, %v", n, err)
+	}
+}
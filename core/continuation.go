@@ -0,0 +1,198 @@
+package core
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultContinuationTTL is how long a chunked response stays fetchable after
+// it was produced or last fetched.
+const DefaultContinuationTTL = 5 * time.Minute
+
+// maxContinuationBytes bounds the text held for continuations; the oldest
+// responses are dropped first.
+const maxContinuationBytes = 64 << 20
+
+// ContinuationChunk is one piece of a response larger than the configured
+// MaxResponseSize. Pass ContinuationToken to fetch_continuation for the next
+// piece; it is empty on the last one.
+type ContinuationChunk struct {
+	Content           string `json:"content"`
+	Kind              string `json:"kind"`   // tool that produced the response
+	Offset            int    `json:"offset"` // byte offset of Content in the full response
+	TotalSize         int    `json:"total_size"`
+	ContinuationToken string `json:"continuation_token,omitempty"`
+}
+
+// Remaining is how many bytes follow this chunk
+func (c ContinuationChunk) Remaining() int {
+	return c.TotalSize - c.Offset - len(c.Content)
+}
+
+type continuationEntry struct {
+	kind      string
+	text      string
+	expiresAt time.Time
+}
+
+// continuationStore keeps oversized responses for fetch_continuation. A token
+// names a response and an offset, so fetching the same token twice returns
+// the same chunk (a retried call does not skip data).
+type continuationStore struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]*continuationEntry
+	order   []string // ids, oldest first
+	bytes   int
+}
+
+func newContinuationStore(ttl time.Duration) *continuationStore {
+	if ttl <= 0 {
+		ttl = DefaultContinuationTTL
+	}
+	return &continuationStore{ttl: ttl, entries: make(map[string]*continuationEntry)}
+}
+
+// expireLocked drops expired responses, then the oldest ones until the store
+// fits maxContinuationBytes (caller holds mu)
+func (s *continuationStore) expireLocked(now time.Time) {
+	kept := s.order[:0]
+	for _, id := range s.order {
+		ent := s.entries[id]
+		if now.After(ent.expiresAt) {
+			s.bytes -= len(ent.text)
+			delete(s.entries, id)
+			continue
+		}
+		kept = append(kept, id)
+	}
+	s.order = kept
+	for s.bytes > maxContinuationBytes && len(s.order) > 1 {
+		s.bytes -= len(s.entries[s.order[0]].text)
+		delete(s.entries, s.order[0])
+		s.order = s.order[1:]
+	}
+}
+
+func (s *continuationStore) put(kind, text string) string {
+	buf := make([]byte, 8)
+	rand.Read(buf)
+	id := "cont_" + hex.EncodeToString(buf)
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ent := &continuationEntry{kind: kind, text: text, expiresAt: now.Add(s.ttl)}
+	s.entries[id] = ent
+	s.order = append(s.order, id)
+	s.bytes += len(text)
+	s.expireLocked(now)
+	return id
+}
+
+// get returns the response behind id and extends its lifetime
+func (s *continuationStore) get(id string) (*continuationEntry, bool) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expireLocked(now)
+	ent, ok := s.entries[id]
+	if ok {
+		ent.expiresAt = now.Add(s.ttl)
+	}
+	return ent, ok
+}
+
+func continuationToken(id string, offset int) string {
+	return id + ":" + strconv.Itoa(offset)
+}
+
+func parseContinuationToken(token string) (string, int, error) {
+	id, off, ok := strings.Cut(strings.TrimSpace(token), ":")
+	offset, err := strconv.Atoi(off)
+	if !ok || !strings.HasPrefix(id, "cont_") || err != nil || offset < 0 {
+		return "", 0, fmt.Errorf("malformed continuation token %q", token)
+	}
+	return id, offset, nil
+}
+
+// chunkEnd picks where a chunk of at most maxBytes starting at offset ends:
+// after the last newline in the second half of the window when there is one,
+// otherwise on a UTF-8 boundary
+func chunkEnd(text string, offset, maxBytes int) int {
+	end := offset + maxBytes
+	if end >= len(text) {
+		return len(text)
+	}
+	if nl := strings.LastIndexByte(text[offset:end], '\n'); nl >= maxBytes/2 {
+		return offset + nl + 1
+	}
+	for end > offset+1 && !utf8.RuneStart(text[end]) {
+		end--
+	}
+	return end
+}
+
+// ChunkResponse returns text whole when it fits in limit bytes (or limit is
+// 0), otherwise its first chunk with a continuation token for the rest.
+func (e *UltraFastEngine) ChunkResponse(kind, text string, limit int) ContinuationChunk {
+	if limit <= 0 || len(text) <= limit || e.continuations == nil {
+		return ContinuationChunk{Content: text, Kind: kind, TotalSize: len(text)}
+	}
+	id := e.continuations.put(kind, text)
+	end := chunkEnd(text, 0, limit)
+	return ContinuationChunk{
+		Content:           text[:end],
+		Kind:              kind,
+		TotalSize:         len(text),
+		ContinuationToken: continuationToken(id, end),
+	}
+}
+
+// FetchContinuation returns the chunk a continuation token points at, at most
+// maxBytes long (0 = MaxResponseSize).
+func (e *UltraFastEngine) FetchContinuation(token string, maxBytes int) (ContinuationChunk, error) {
+	id, offset, err := parseContinuationToken(token)
+	if err != nil {
+		return ContinuationChunk{}, err
+	}
+	ent, ok := e.continuations.get(id)
+	if !ok {
+		return ContinuationChunk{}, fmt.Errorf("continuation token expired or unknown (responses are kept %s): repeat the original call", e.continuations.ttl)
+	}
+	if offset > len(ent.text) {
+		return ContinuationChunk{}, fmt.Errorf("continuation offset %d is past the end of the response (%d bytes)", offset, len(ent.text))
+	}
+	if maxBytes <= 0 {
+		maxBytes = int(e.config.MaxResponseSize)
+	}
+	if maxBytes <= 0 {
+		maxBytes = len(ent.text)
+	}
+	end := chunkEnd(ent.text, offset, maxBytes)
+	chunk := ContinuationChunk{
+		Content:   ent.text[offset:end],
+		Kind:      ent.kind,
+		Offset:    offset,
+		TotalSize: len(ent.text),
+	}
+	if end < len(ent.text) {
+		chunk.ContinuationToken = continuationToken(id, end)
+	}
+	return chunk, nil
+}
+
+// ContinuationFooter is the line appended to a chunk that has more to come
+func ContinuationFooter(c ContinuationChunk) string {
+	if c.ContinuationToken == "" {
+		return ""
+	}
+	return fmt.Sprintf("\n\n[Partial response: bytes %d-%d of %d, %d remaining. Next chunk: fetch_continuation(token:%q)]",
+		c.Offset, c.Offset+len(c.Content), c.TotalSize, c.Remaining(), c.ContinuationToken)
+}
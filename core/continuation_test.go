@@ -0,0 +1,62 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContinuation_ChunksReassemble(t *testing.T) {
+	engine, _ := setupProgressEngine(t)
+	var b strings.Builder
+	for i := 0; i < 500; i++ {
+		fmt.Fprintf(&b, "line %03d ñ\n", i)
+	}
+	text := b.String()
+
+	if c := engine.ChunkResponse("read_file", "small", 100); c.ContinuationToken != "" || c.Content != "small" {
+		t.Fatalf("small response chunked: %+v", c)
+	}
+
+	c := engine.ChunkResponse("read_file", text, 1000)
+	if c.ContinuationToken == "" || len(c.Content) > 1000 || !strings.HasSuffix(c.Content, "\n") {
+		t.Fatalf("first chunk: %d bytes, token %q", len(c.Content), c.ContinuationToken)
+	}
+	got := c.Content
+	for c.ContinuationToken != "" {
+		token := c.ContinuationToken
+		next, err := engine.FetchContinuation(token, 700)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// fetching the same token again returns the same chunk
+		if again, err := engine.FetchContinuation(token, 700); err != nil || again.Content != next.Content {
+			t.Fatalf("refetch of %s differs: %v", token, err)
+		}
+		if next.Offset != len(got) || next.TotalSize != len(text) || next.Kind != "read_file" {
+			t.Fatalf("chunk at %d: %+v", len(got), next)
+		}
+		got += next.Content
+		c = next
+	}
+	if got != text {
+		t.Fatal("reassembled response differs from the original")
+	}
+}
+
+func TestContinuation_ExpiredAndMalformedTokens(t *testing.T) {
+	engine, _ := setupProgressEngine(t)
+	engine.continuations = newContinuationStore(20 * time.Millisecond)
+
+	c := engine.ChunkResponse("list_directory", strings.Repeat("x\n", 100), 50)
+	time.Sleep(40 * time.Millisecond)
+	if _, err := engine.FetchContinuation(c.ContinuationToken, 0); err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Errorf("expired token: err = %v", err)
+	}
+	for _, token := range []string{"", "cont_abc", "nope:10", "cont_abc:-1"} {
+		if _, err := engine.FetchContinuation(token, 0); err == nil || !strings.Contains(err.Error(), "malformed") {
+			t.Errorf("token %q: err = %v", token, err)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// copyVerifySample is how many files a copy hash-checks when full
+// verification is off
+const copyVerifySample = 16
+
+// copyProgressEvery is how many files pass between two progress reports
+const copyProgressEvery = 100
+
+// CopyOptions tunes CopyPath. The zero value copies everything and
+// hash-checks a sample of the copied files.
+type CopyOptions struct {
+	// SkipDefaultExcludes leaves out the default exclude dirs
+	// (.git, node_modules, ...) when copying a directory
+	SkipDefaultExcludes bool
+	// Verify hash-checks every copied file instead of a sample
+	Verify bool
+	// OnProgress, when set, is called as files are copied
+	OnProgress func(CopyProgress)
+}
+
+// CopyProgress is one progress report of a copy
+type CopyProgress struct {
+	FilesDone   int
+	FilesTotal  int
+	BytesDone   int64
+	BytesTotal  int64
+	CurrentPath string
+}
+
+// CopyResult is the copy_file response
+type CopyResult struct {
+	Source          string `json:"source"`
+	Dest            string `json:"dest"`
+	Dir             bool   `json:"dir"`
+	Files           int    `json:"files"`
+	Dirs            int    `json:"dirs,omitempty"`
+	Bytes           int64  `json:"bytes"`
+	SkippedSymlinks int    `json:"skipped_symlinks,omitempty"`
+	Excluded        int    `json:"excluded,omitempty"` // entries left out by the default excludes
+	Verified        int    `json:"verified"`           // files whose hashes were compared
+	VerifyMode      string `json:"verify_mode"`        // "sample" or "full"
+	DurationMs      int64  `json:"duration_ms"`
+}
+
+// copyPlan lists what a copy creates, parents before children
+type copyPlan struct {
+	dirs     []copyItem
+	files    []copyItem
+	bytes    int64
+	symlinks int
+	excluded int
+}
+
+type copyItem struct {
+	src, dst string
+	info     os.FileInfo
+}
+
+// planFileCopy is the plan of a single-file copy
+func planFileCopy(src, dst string, info os.FileInfo) *copyPlan {
+	return &copyPlan{files: []copyItem{{src, dst, info}}, bytes: info.Size()}
+}
+
+// planTreeCopy walks src and lists the dirs and regular files to copy.
+// Symlinks are skipped, as are the default excludes when asked.
+func planTreeCopy(ctx context.Context, src, dst string, opts CopyOptions) (*copyPlan, error) {
+	plan := &copyPlan{}
+	var excludes *syncExcludes
+	if opts.SkipDefaultExcludes {
+		excludes = newSyncExcludes(nil, nil)
+	}
+	err := filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", p, err)
+		}
+		if excludes != nil && excludes.excluded(src, p, d.IsDir()) {
+			plan.excluded++
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			plan.symlinks++
+			return nil
+		}
+		if !d.IsDir() && !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %w", p, err)
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		item := copyItem{p, filepath.Join(dst, rel), info}
+		if d.IsDir() {
+			plan.dirs = append(plan.dirs, item)
+		} else {
+			plan.files = append(plan.files, item)
+			plan.bytes += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// copyTree creates the dirs of plan, copies its files, then applies the
+// source dir modes and mtimes deepest-first so file writes don't bump them
+func (e *UltraFastEngine) copyTree(ctx context.Context, plan *copyPlan, opts CopyOptions) (*CopyResult, error) {
+	result := &CopyResult{Dirs: len(plan.dirs), SkippedSymlinks: plan.symlinks, Excluded: plan.excluded}
+	for _, dir := range plan.dirs {
+		if err := os.MkdirAll(dir.dst, 0755); err != nil {
+			return result, fmt.Errorf("failed to create directory %s: %w", dir.dst, err)
+		}
+	}
+
+	report := func(current string) {
+		if opts.OnProgress != nil {
+			opts.OnProgress(CopyProgress{FilesDone: result.Files, FilesTotal: len(plan.files),
+				BytesDone: result.Bytes, BytesTotal: plan.bytes, CurrentPath: current})
+		}
+	}
+	for _, file := range plan.files {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		if err := e.copyFile(file.src, file.dst); err != nil {
+			return result, fmt.Errorf("failed to copy %s: %w", file.src, err)
+		}
+		result.Files++
+		result.Bytes += file.info.Size()
+		if result.Files%copyProgressEvery == 0 {
+			report(file.src)
+		}
+	}
+	if result.Files%copyProgressEvery != 0 || result.Files == 0 {
+		report("")
+	}
+
+	for i := len(plan.dirs) - 1; i >= 0; i-- {
+		dir := plan.dirs[i]
+		_ = os.Chmod(dir.dst, dir.info.Mode().Perm())
+		_ = os.Chtimes(dir.dst, dir.info.ModTime(), dir.info.ModTime())
+	}
+	return result, nil
+}
+
+// verifyCopy compares the SHA-256 of source and copy for every file of plan
+// (full) or for an evenly spaced sample of copyVerifySample files
+func (e *UltraFastEngine) verifyCopy(plan *copyPlan, full bool, result *CopyResult) error {
+	files := plan.files
+	result.VerifyMode = "full"
+	if !full && len(files) > copyVerifySample {
+		result.VerifyMode = "sample"
+		sample := make([]copyItem, 0, copyVerifySample)
+		for i := 0; i < copyVerifySample; i++ {
+			sample = append(sample, files[i*len(files)/copyVerifySample])
+		}
+		files = sample
+	}
+	for _, file := range files {
+		want, err := e.hashFileBuffered(file.src)
+		if err != nil {
+			return fmt.Errorf("verify: %w", err)
+		}
+		got, err := e.hashFileBuffered(file.dst)
+		if err != nil {
+			return fmt.Errorf("verify: %w", err)
+		}
+		if got != want {
+			return fmt.Errorf("verify: %s does not match its source %s", file.dst, file.src)
+		}
+		result.Verified++
+	}
+	return nil
+}
+
+// pathInside reports whether p is dir or lies below it. Both sides are made
+// absolute and, where they exist, symlink-resolved; for a p that does not
+// exist yet its nearest existing parent is resolved.
+func pathInside(dir, p string) bool {
+	rel, err := filepath.Rel(resolveExisting(dir), resolveExisting(p))
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// resolveExisting makes path absolute and resolves the symlinks in its
+// longest existing prefix; the rest is joined on unchanged
+func resolveExisting(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	rest := ""
+	for cur := abs; ; cur = filepath.Dir(cur) {
+		if real, err := filepath.EvalSymlinks(cur); err == nil {
+			return filepath.Join(real, rest)
+		}
+		if filepath.Dir(cur) == cur {
+			return abs
+		}
+		rest = filepath.Join(filepath.Base(cur), rest)
+	}
+}
+
+// FormatCopyResult renders a directory CopyResult as text
+func FormatCopyResult(r *CopyResult, compact bool) string {
+	verified := fmt.Sprintf("%d files hash-verified (%s)", r.Verified, r.VerifyMode)
+	if compact {
+		return fmt.Sprintf("OK: copied to %s | %d files, %d dirs, %s | %s", r.Dest, r.Files, r.Dirs, formatSize(r.Bytes), verified)
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Successfully copied '%s' to '%s'\n", r.Source, r.Dest))
+	sb.WriteString(fmt.Sprintf("%d files, %d dirs, %s in %s\n", r.Files, r.Dirs, formatSize(r.Bytes), time.Duration(r.DurationMs)*time.Millisecond))
+	sb.WriteString(verified)
+	if r.Excluded > 0 {
+		sb.WriteString(fmt.Sprintf("\n%d entries left out by the default excludes", r.Excluded))
+	}
+	if r.SkippedSymlinks > 0 {
+		sb.WriteString(fmt.Sprintf("\n%d symlinks skipped", r.SkippedSymlinks))
+	}
+	return sb.String()
+}
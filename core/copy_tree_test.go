@@ -0,0 +1,85 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCopyPath_Directory(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	src := filepath.Join(dir, "template")
+	for i := 0; i < 40; i++ {
+		full := filepath.Join(src, fmt.Sprintf("pkg%d", i%4), fmt.Sprintf("f%02d.txt", i))
+		os.MkdirAll(filepath.Dir(full), 0755)
+		os.WriteFile(full, []byte(strings.Repeat("x", i+1)), 0644)
+	}
+	os.MkdirAll(filepath.Join(src, "node_modules", "dep"), 0755)
+	os.WriteFile(filepath.Join(src, "node_modules", "dep", "index.js"), []byte("module.exports = 1"), 0644)
+	script := filepath.Join(src, "run.sh")
+	os.WriteFile(script, []byte("#!/bin/sh\n"), 0750)
+	old := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	os.Chtimes(script, old, old)
+	os.Chtimes(filepath.Join(src, "pkg1"), old, old)
+
+	var reports []CopyProgress
+	dst := filepath.Join(dir, "out", "app")
+	result, err := engine.CopyPath(context.Background(), src, dst, CopyOptions{
+		SkipDefaultExcludes: true,
+		OnProgress:          func(p CopyProgress) { reports = append(reports, p) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Dir || result.Files != 41 || result.Excluded != 1 || result.Verified != copyVerifySample || result.VerifyMode != "sample" {
+		t.Errorf("result = %+v", result)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "node_modules")); !os.IsNotExist(err) {
+		t.Errorf("node_modules copied: %v", err)
+	}
+	if last := reports[len(reports)-1]; last.FilesDone != 41 || last.BytesDone != result.Bytes || last.BytesTotal != result.Bytes {
+		t.Errorf("last progress = %+v", last)
+	}
+	info, err := os.Stat(filepath.Join(dst, "run.sh"))
+	if err != nil || info.Mode().Perm() != 0750 || !info.ModTime().Equal(old) {
+		t.Errorf("run.sh = %v %v, %v", info.Mode(), info.ModTime(), err)
+	}
+	if info, err := os.Stat(filepath.Join(dst, "pkg1")); err != nil || !info.ModTime().Equal(old) {
+		t.Errorf("pkg1 mtime not kept: %v", err)
+	}
+	if text := FormatCopyResult(result, false); !strings.Contains(text, "41 files, 5 dirs") {
+		t.Errorf("text:\n%s", text)
+	}
+
+	result, err = engine.CopyPath(context.Background(), src, filepath.Join(dir, "full"), CopyOptions{Verify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Files != 42 || result.Verified != 42 || result.VerifyMode != "full" {
+		t.Errorf("full verify result = %+v", result)
+	}
+}
+
+func TestCopyPath_RejectsDestInsideSource(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	src := filepath.Join(dir, "src")
+	os.MkdirAll(src, 0755)
+	os.WriteFile(filepath.Join(src, "a.txt"), []byte("a"), 0644)
+
+	for _, dst := range []string{filepath.Join(src, "backup"), filepath.Join(src, "nested", "deeper")} {
+		_, err := engine.CopyPath(context.Background(), src, dst, CopyOptions{})
+		if err == nil || !strings.Contains(err.Error(), "inside the source directory") {
+			t.Errorf("copy to %s: err = %v", dst, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(src, "backup")); !os.IsNotExist(err) {
+		t.Errorf("backup created: %v", err)
+	}
+	if err := engine.CopyFile(context.Background(), src, filepath.Join(dir, "src-copy")); err != nil {
+		t.Errorf("sibling copy: %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package core
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestMatchCriticalFile(t *testing.T) {
+	cases := []struct {
+		path  string
+		extra []string
+		want  string
+	}{
+		{"/repo/go.mod", nil, "go.mod"},
+		{"/repo/go.sum", nil, "go.sum"},
+		{"/repo/web/package-lock.json", nil, "package-lock.json"},
+		{"/repo/Cargo.toml", nil, "Cargo.toml"},
+		{"/repo/Dockerfile", nil, "Dockerfile"},
+		{"/repo/Dockerfile.prod", nil, "Dockerfile.prod"},
+		{"/repo/.git/config", nil, ".git/**"},
+		{"/repo/.git/refs/heads/main", nil, ".git/**"},
+		{"/repo/db/migrations/0001_init.sql", nil, "migrations/0001_init.sql"},
+		{"/repo/db/queries/select.sql", nil, ""},
+		{"/repo/main.go", nil, ""},
+		{"/repo/gitignore.md", nil, ""},
+		{"/repo/prisma/schema.prisma", []string{"schema.prisma"}, "schema.prisma"},
+		{"/repo/deploy/k8s/app.yaml", []string{"deploy/**"}, "deploy/**"},
+		{"", nil, ""},
+	}
+	for _, tc := range cases {
+		got := MatchCriticalFile(filepath.FromSlash(tc.path), tc.extra)
+		if got != tc.want {
+			t.Errorf("MatchCriticalFile(%q, %v) = %q, want %q", tc.path, tc.extra, got, tc.want)
+		}
+	}
+}
+
+func TestCalculateChangeImpact_CriticalFileBumpsRisk(t *testing.T) {
+	content := "module example.com/app\n\ngo 1.22\n\nrequire example.com/dep v1.0.0\n"
+	thresholds := DefaultRiskThresholds()
+
+	plain := CalculateChangeImpact(content, "v1.0.0", "v1.1.0", thresholds, "/repo/notes.txt")
+	if plain.RiskLevel != "low" {
+		t.Fatalf("baseline risk = %q, want low", plain.RiskLevel)
+	}
+
+	critical := CalculateChangeImpact(content, "v1.0.0", "v1.1.0", thresholds, "/repo/go.mod")
+	if critical.RiskLevel != "medium" || !critical.IsRisky {
+		t.Errorf("go.mod risk = %q (risky=%v), want medium/risky", critical.RiskLevel, critical.IsRisky)
+	}
+	if !slices.Contains(critical.RiskFactors, "critical file: go.mod") {
+		t.Errorf("risk factors %v missing \"critical file: go.mod\"", critical.RiskFactors)
+	}
+
+	// Without a path the analysis is unchanged (backward compatible)
+	noPath := CalculateChangeImpact(content, "v1.0.0", "v1.1.0", thresholds)
+	if noPath.RiskLevel != "low" {
+		t.Errorf("risk without path = %q, want low", noPath.RiskLevel)
+	}
+
+	// Levels only go up to critical
+	whole := CalculateChangeImpact(content, content, "x", thresholds, "/repo/go.mod")
+	if whole.RiskLevel != "critical" {
+		t.Errorf("full rewrite of go.mod = %q, want critical", whole.RiskLevel)
+	}
+}
+
+func TestAnalyzeEditChange_CriticalFile(t *testing.T) {
+	engine, cleanup := setupTestEngine(t)
+	defer cleanup()
+
+	dir := engine.config.AllowedPaths[0]
+	path := createTestFile(t, dir, "package.json", "{\n  \"name\": \"app\",\n  \"version\": \"1.0.0\"\n}\n")
+
+	analysis, err := engine.AnalyzeEditChange(context.Background(), path, "1.0.0", "1.0.1")
+	if err != nil {
+		t.Fatalf("AnalyzeEditChange: %v", err)
+	}
+	if analysis.RiskLevel == "low" {
+		t.Errorf("package.json edit risk = low, want bumped level")
+	}
+	if !slices.Contains(analysis.RiskFactors, "critical file: package.json") {
+		t.Errorf("risk factors %v missing critical file entry", analysis.RiskFactors)
+	}
+}
@@ -86,9 +86,14 @@ func RenderDiff(oldContent, newContent, filePath, format string) string {
 
 // formatHunksFull renders hunks as a standard unified diff.
 func formatHunksFull(hunks []hunk, filePath string) string {
+	return formatHunksNamed(hunks, "a/"+filePath, "b/"+filePath)
+}
+
+// formatHunksNamed is formatHunksFull for two different files
+func formatHunksNamed(hunks []hunk, nameA, nameB string) string {
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("--- a/%s\n", filePath))
-	sb.WriteString(fmt.Sprintf("+++ b/%s\n", filePath))
+	sb.WriteString(fmt.Sprintf("--- %s\n", nameA))
+	sb.WriteString(fmt.Sprintf("+++ %s\n", nameB))
 	for _, h := range hunks {
 		sb.WriteString(h.header())
 		for _, line := range h.lines {
@@ -172,9 +177,45 @@ type edit struct {
 	text string
 }
 
-// diff computes the edit script between oldLines and newLines using Myers algorithm (simplified).
-// For simplicity we use the standard DP LCS approach — adequate for file sizes we handle.
+// diff computes the edit script between oldLines and newLines. Common
+// leading and trailing lines are matched directly, so only the changed middle
+// goes through the LCS table and a small change in a large file stays cheap.
 func diff(oldLines, newLines []string) []edit {
+	pre, suf := commonEnds(oldLines, newLines)
+	edits := make([]edit, 0, len(oldLines)+len(newLines)-pre-suf)
+	for _, l := range oldLines[:pre] {
+		edits = append(edits, edit{editEqual, l})
+	}
+	edits = append(edits, lcsDiff(oldLines[pre:len(oldLines)-suf], newLines[pre:len(newLines)-suf])...)
+	for _, l := range oldLines[len(oldLines)-suf:] {
+		edits = append(edits, edit{editEqual, l})
+	}
+	return edits
+}
+
+// commonEnds returns how many lines oldLines and newLines share at the start
+// and, past those, at the end
+func commonEnds(oldLines, newLines []string) (pre, suf int) {
+	for pre < len(oldLines) && pre < len(newLines) && oldLines[pre] == newLines[pre] {
+		pre++
+	}
+	for suf < len(oldLines)-pre && suf < len(newLines)-pre &&
+		oldLines[len(oldLines)-1-suf] == newLines[len(newLines)-1-suf] {
+		suf++
+	}
+	return pre, suf
+}
+
+// diffCells is the size of the LCS table diff builds for oldLines and
+// newLines, for callers that must bound its memory
+func diffCells(oldLines, newLines []string) int64 {
+	pre, suf := commonEnds(oldLines, newLines)
+	return int64(len(oldLines)-pre-suf+1) * int64(len(newLines)-pre-suf+1)
+}
+
+// lcsDiff is the standard DP LCS edit script — adequate for the changed
+// region of the file sizes we handle.
+func lcsDiff(oldLines, newLines []string) []edit {
 	m := len(oldLines)
 	n := len(newLines)
 
@@ -0,0 +1,71 @@
+package core
+
+// list_directory results are cached per directory. Any operation that adds,
+// removes or renames an entry must drop the parent's listing (text and JSON),
+// otherwise a file that was just written is missing from the next listing.
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestListDirectory_SeesEntriesAfterMutation(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	ctx := context.Background()
+	os.WriteFile(filepath.Join(dir, "existing.txt"), []byte("x"), 0644)
+
+	listings := func() (string, string) {
+		t.Helper()
+		text, err := engine.ListDirectoryContent(ctx, dir)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		js, err := engine.ListDirectoryJSON(ctx, dir)
+		if err != nil {
+			t.Fatalf("list json: %v", err)
+		}
+		return text, js
+	}
+	expect := func(step, name string, present bool) {
+		t.Helper()
+		text, js := listings()
+		if strings.Contains(text, name) != present || strings.Contains(js, name) != present {
+			t.Errorf("%s: %s present=%v wanted %v\ntext: %s\njson: %s", step, name, !present, present, text, js)
+		}
+	}
+
+	// Warm both listing caches first
+	expect("initial", "existing.txt", true)
+
+	if err := engine.WriteFileContent(ctx, filepath.Join(dir, "written.txt"), "hello"); err != nil {
+		t.Fatal(err)
+	}
+	expect("after write_file", "written.txt", true)
+
+	if err := engine.RenameFile(ctx, filepath.Join(dir, "written.txt"), filepath.Join(dir, "renamed.txt")); err != nil {
+		t.Fatal(err)
+	}
+	expect("after rename (old name)", "written.txt", false)
+	expect("after rename (new name)", "renamed.txt", true)
+
+	if err := engine.DeleteFile(ctx, filepath.Join(dir, "renamed.txt")); err != nil {
+		t.Fatal(err)
+	}
+	expect("after delete", "renamed.txt", false)
+
+	// Batch operations write through os.* and must invalidate as well
+	mgr := NewBatchOperationManager(t.TempDir(), 10)
+	mgr.SetEngine(engine)
+	res := mgr.ExecuteBatch(BatchRequest{Operations: []FileOperation{
+		{Type: "write", Path: filepath.Join(dir, "batch.txt"), Content: "b"},
+		{Type: "copy", Source: filepath.Join(dir, "existing.txt"), Destination: filepath.Join(dir, "copied.txt")},
+	}})
+	if !res.Success {
+		t.Fatalf("batch failed: %v", res.Errors)
+	}
+	expect("after batch write", "batch.txt", true)
+	expect("after batch copy", "copied.txt", true)
+}
@@ -0,0 +1,273 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// analyze_directory size defaults and limits
+const (
+	DefaultSizeTopN = 10
+	MaxSizeTopN     = 100
+	maxSkippedShown = 10
+)
+
+// SizeEntry is one directory or file in a DirectorySize ranking; Path is
+// relative to the measured directory
+type SizeEntry struct {
+	Path  string `json:"path"`
+	Bytes int64  `json:"bytes"`
+	Files int    `json:"files,omitempty"` // directories only
+}
+
+// DirectorySize is the analyze_directory size response
+type DirectorySize struct {
+	Path  string `json:"path"`
+	Depth int    `json:"depth"`
+	Bytes int64  `json:"bytes"`
+	Files int    `json:"files"`
+	Dirs  int    `json:"dirs"`
+	// LargestDirs ranks the subdirectories up to Depth levels below Path,
+	// LargestFiles the files anywhere below it
+	LargestDirs  []SizeEntry `json:"largest_dirs"`
+	LargestFiles []SizeEntry `json:"largest_files"`
+	// Skipped counts entries that could not be read (permission denied,
+	// removed during the walk); SkippedPaths shows the first few
+	Skipped      int      `json:"skipped"`
+	SkippedPaths []string `json:"skipped_paths,omitempty"`
+	DurationMs   int64    `json:"duration_ms"`
+}
+
+// sizeWalk accumulates one subtree; each worker owns one
+type sizeWalk struct {
+	bytes   int64
+	files   int
+	dirs    int
+	perDir  map[string]*SizeEntry
+	largest []SizeEntry
+	skipped []string
+}
+
+// DirectorySize measures path like du: total bytes and regular files below
+// it, the topN largest subdirectories up to depth levels down and the topN
+// largest files. Each immediate subdirectory is walked on the worker pool.
+// Unreadable entries are counted in Skipped instead of failing the call;
+// symlinks are not followed. Nothing is cached, since sizes change constantly.
+func (e *UltraFastEngine) DirectorySize(ctx context.Context, path string, depth, topN int) (*DirectorySize, error) {
+	path = NormalizePath(path)
+	if err := e.acquireOperation(ctx, "size"); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	defer e.releaseOperation("size", start)
+
+	if !e.IsPathAllowed(path) {
+		return nil, e.AccessDeniedError("directory_size", path)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, &PathError{Op: "directory_size", Path: path, Err: err}
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("not a directory: %s (%s; use get_file_info for files)", path, formatSize(info.Size()))
+	}
+	if depth <= 0 {
+		depth = 1
+	}
+	if topN <= 0 {
+		topN = DefaultSizeTopN
+	}
+	if topN > MaxSizeTopN {
+		topN = MaxSizeTopN
+	}
+	root, err := filepath.Abs(path)
+	if err != nil {
+		root = path
+	}
+
+	// The root's own files are measured here; each subdirectory is a task
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, &PathError{Op: "directory_size", Path: root, Err: err}
+	}
+	top := &sizeWalk{perDir: make(map[string]*SizeEntry)}
+	var subdirs []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			subdirs = append(subdirs, filepath.Join(root, entry.Name()))
+			continue
+		}
+		top.addFile(root, filepath.Join(root, entry.Name()), entry, depth, topN)
+	}
+
+	walks := make([]*sizeWalk, len(subdirs))
+	var wg sync.WaitGroup
+	for i, dir := range subdirs {
+		w := &sizeWalk{perDir: make(map[string]*SizeEntry)}
+		walks[i] = w
+		dir := dir
+		task := func() {
+			defer wg.Done()
+			w.walk(ctx, root, dir, depth, topN)
+		}
+		wg.Add(1)
+		if e.workerPool == nil || e.workerPool.Submit(task) != nil {
+			task()
+		}
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	res := &DirectorySize{Path: root, Depth: depth, LargestDirs: []SizeEntry{}, LargestFiles: []SizeEntry{}}
+	for _, w := range append(walks, top) {
+		res.Bytes += w.bytes
+		res.Files += w.files
+		res.Dirs += w.dirs
+		for _, d := range w.perDir {
+			res.LargestDirs = append(res.LargestDirs, *d)
+		}
+		res.LargestFiles = append(res.LargestFiles, w.largest...)
+		res.Skipped += len(w.skipped)
+		res.SkippedPaths = append(res.SkippedPaths, w.skipped...)
+	}
+	res.LargestDirs = largestEntries(res.LargestDirs, topN)
+	res.LargestFiles = largestEntries(res.LargestFiles, topN)
+	sort.Strings(res.SkippedPaths)
+	if len(res.SkippedPaths) > maxSkippedShown {
+		res.SkippedPaths = res.SkippedPaths[:maxSkippedShown]
+	}
+	res.DurationMs = time.Since(start).Milliseconds()
+	return res, nil
+}
+
+// walk measures the subtree at dir
+func (w *sizeWalk) walk(ctx context.Context, root, dir string, depth, topN int) {
+	filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			w.skip(root, p)
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			w.dirs++
+			if rel, _ := filepath.Rel(root, p); strings.Count(filepath.ToSlash(rel), "/") < depth {
+				w.perDir[filepath.ToSlash(rel)] = &SizeEntry{Path: filepath.ToSlash(rel)}
+			}
+			return nil
+		}
+		w.addFile(root, p, d, depth, topN)
+		return nil
+	})
+}
+
+// addFile counts a regular file in its ancestors up to depth levels below root
+func (w *sizeWalk) addFile(root, p string, d fs.DirEntry, depth, topN int) {
+	if !d.Type().IsRegular() {
+		return
+	}
+	info, err := d.Info()
+	if err != nil {
+		w.skip(root, p)
+		return
+	}
+	size := info.Size()
+	w.bytes += size
+	w.files++
+
+	rel, _ := filepath.Rel(root, p)
+	rel = filepath.ToSlash(rel)
+	segments := strings.Split(rel, "/")
+	for i := 1; i < len(segments) && i <= depth; i++ {
+		if dir := w.perDir[strings.Join(segments[:i], "/")]; dir != nil {
+			dir.Bytes += size
+			dir.Files++
+		}
+	}
+
+	w.largest = append(w.largest, SizeEntry{Path: rel, Bytes: size})
+	if len(w.largest) > 4*topN {
+		w.largest = largestEntries(w.largest, topN)
+	}
+}
+
+func (w *sizeWalk) skip(root, p string) {
+	if rel, err := filepath.Rel(root, p); err == nil {
+		p = filepath.ToSlash(rel)
+	}
+	w.skipped = append(w.skipped, p)
+}
+
+// largestEntries sorts by size (then path) and keeps the first n
+func largestEntries(entries []SizeEntry, n int) []SizeEntry {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Bytes != entries[j].Bytes {
+			return entries[i].Bytes > entries[j].Bytes
+		}
+		return entries[i].Path < entries[j].Path
+	})
+	if len(entries) > n {
+		entries = entries[:n]
+	}
+	return entries
+}
+
+// FormatDirectorySize renders a DirectorySize as text
+func FormatDirectorySize(r *DirectorySize, compact bool) string {
+	share := func(b int64) string {
+		if r.Bytes == 0 {
+			return "0%"
+		}
+		return fmt.Sprintf("%.0f%%", float64(b)*100/float64(r.Bytes))
+	}
+	if compact {
+		parts := make([]string, 0, len(r.LargestDirs))
+		for _, d := range r.LargestDirs {
+			parts = append(parts, fmt.Sprintf("%s %s", d.Path, formatSize(d.Bytes)))
+		}
+		out := fmt.Sprintf("%s: %s, %d files, %d dirs", r.Path, formatSize(r.Bytes), r.Files, r.Dirs)
+		if len(parts) > 0 {
+			out += " | " + strings.Join(parts, ", ")
+		}
+		if r.Skipped > 0 {
+			out += fmt.Sprintf(" | %d skipped", r.Skipped)
+		}
+		return out
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s in %d files, %d dirs (%dms)\n", r.Path, formatSize(r.Bytes), r.Files, r.Dirs, r.DurationMs))
+	if len(r.LargestDirs) > 0 {
+		sb.WriteString(fmt.Sprintf("\nLargest directories (depth %d):\n", r.Depth))
+		for _, d := range r.LargestDirs {
+			sb.WriteString(fmt.Sprintf("  %10s  %4s  %s/ (%d files)\n", formatSize(d.Bytes), share(d.Bytes), d.Path, d.Files))
+		}
+	}
+	if len(r.LargestFiles) > 0 {
+		sb.WriteString("\nLargest files:\n")
+		for _, f := range r.LargestFiles {
+			sb.WriteString(fmt.Sprintf("  %10s  %4s  %s\n", formatSize(f.Bytes), share(f.Bytes), f.Path))
+		}
+	}
+	if r.Skipped > 0 {
+		sb.WriteString(fmt.Sprintf("\n⚠️  %d entries skipped (unreadable): %s", r.Skipped, strings.Join(r.SkippedPaths, ", ")))
+		if r.Skipped > len(r.SkippedPaths) {
+			sb.WriteString(", ...")
+		}
+		sb.WriteString("\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
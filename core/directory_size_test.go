@@ -0,0 +1,77 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDirectorySize_TotalsAndRankings(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	for path, size := range map[string]int{
+		"top.txt":                 5,
+		"node_modules/a/index.js": 400,
+		"node_modules/b.js":       100,
+		"src/main.go":             50,
+		"src/pkg/util.go":         20,
+	} {
+		full := filepath.Join(dir, path)
+		os.MkdirAll(filepath.Dir(full), 0755)
+		os.WriteFile(full, []byte(strings.Repeat("x", size)), 0644)
+	}
+
+	res, err := engine.DirectorySize(context.Background(), dir, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Bytes != 575 || res.Files != 5 || res.Dirs != 4 || res.Skipped != 0 {
+		t.Errorf("totals = %+v", res)
+	}
+	if len(res.LargestDirs) != 2 || res.LargestDirs[0] != (SizeEntry{Path: "node_modules", Bytes: 500, Files: 2}) ||
+		res.LargestDirs[1] != (SizeEntry{Path: "src", Bytes: 70, Files: 2}) {
+		t.Errorf("largest dirs = %+v", res.LargestDirs)
+	}
+	if len(res.LargestFiles) != 2 || res.LargestFiles[0].Path != "node_modules/a/index.js" || res.LargestFiles[1].Bytes != 100 {
+		t.Errorf("largest files = %+v", res.LargestFiles)
+	}
+
+	res, err = engine.DirectorySize(context.Background(), dir, 2, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, d := range res.LargestDirs {
+		found = found || d == (SizeEntry{Path: "src/pkg", Bytes: 20, Files: 1})
+	}
+	if !found || len(res.LargestDirs) != 4 {
+		t.Errorf("depth 2 dirs = %+v", res.LargestDirs)
+	}
+	if text := FormatDirectorySize(res, false); !strings.Contains(text, "Largest directories (depth 2)") || !strings.Contains(text, "node_modules/ (2 files)") {
+		t.Errorf("text:\n%s", text)
+	}
+}
+
+func TestDirectorySize_SkipsUnreadable(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root reads every directory")
+	}
+	engine, dir := setupProgressEngine(t)
+	locked := filepath.Join(dir, "locked")
+	os.MkdirAll(filepath.Join(locked, "inner"), 0755)
+	os.WriteFile(filepath.Join(dir, "ok.txt"), []byte("abc"), 0644)
+	os.Chmod(locked, 0)
+	defer os.Chmod(locked, 0755)
+
+	res, err := engine.DirectorySize(context.Background(), dir, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Bytes != 3 || res.Skipped != 1 || res.SkippedPaths[0] != "locked" {
+		t.Errorf("result = %+v", res)
+	}
+	if text := FormatDirectorySize(res, false); !strings.Contains(text, "1 entries skipped") {
+		t.Errorf("text:\n%s", text)
+	}
+}
@@ -0,0 +1,313 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultTreeDepth is the analyze_directory tree max_depth default
+const DefaultTreeDepth = 3
+
+// TreeOptions controls DirectoryTree
+type TreeOptions struct {
+	MaxDepth      int      // levels listed below the root (<= 0 = DefaultTreeDepth)
+	IncludeSizes  bool     // cumulative size and file count on directories
+	IncludeHidden bool     // list dot files and directories
+	Exclude       []string // globs on top of the default excludes (.git, node_modules, ...)
+	Git           bool     // annotate entries with their git status (M, A, ??, ...)
+}
+
+// TreeNode is one entry of a DirectoryTree
+type TreeNode struct {
+	Name string `json:"name"`
+	Type string `json:"type"` // "dir", "file" or "symlink"
+	// Size is the file size; for directories (IncludeSizes) the cumulative
+	// size of the files below, at any depth, and Files their count
+	Size      int64       `json:"size,omitempty"`
+	Files     int         `json:"files,omitempty"`
+	Children  []*TreeNode `json:"children,omitempty"`
+	More      int         `json:"more,omitempty"`      // entries left out past MaxListItems
+	Collapsed bool        `json:"collapsed,omitempty"` // directory at max_depth: children not listed
+	Git       string      `json:"git,omitempty"`       // include_git: M, A, R, C, U, ?? or !!; "" = clean
+}
+
+// DirectoryTree is the analyze_directory tree response
+type DirectoryTree struct {
+	Path     string    `json:"path"`
+	MaxDepth int       `json:"max_depth"`
+	Root     *TreeNode `json:"root"`
+	Dirs     int       `json:"dirs"`  // directories listed
+	Files    int       `json:"files"` // files listed
+	// Truncated explains why the tree is incomplete (response size limit)
+	Truncated string `json:"truncated,omitempty"`
+}
+
+// treeWalker builds a DirectoryTree
+type treeWalker struct {
+	ctx      context.Context
+	root     string
+	opts     TreeOptions
+	maxItems int
+	excludes *syncExcludes
+	tree     *DirectoryTree
+}
+
+// DirectoryTree walks path up to opts.MaxDepth levels deep. Each directory
+// lists at most MaxListItems entries (directories first, then files, by
+// name) and counts the rest in More. Directories at max_depth are collapsed;
+// with IncludeSizes the walk still descends into them (and into entries past
+// MaxListItems) so every directory carries its cumulative size and file count.
+// Default excludes, opts.Exclude and, unless IncludeHidden, dot entries are
+// skipped entirely. Symlinks are listed but never followed.
+func (e *UltraFastEngine) DirectoryTree(ctx context.Context, path string, opts TreeOptions) (*DirectoryTree, error) {
+	path = NormalizePath(path)
+	if err := e.acquireOperation(ctx, "tree"); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	defer e.releaseOperation("tree", start)
+
+	if !e.IsPathAllowed(path) {
+		return nil, e.AccessDeniedError("directory_tree", path)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, &PathError{Op: "directory_tree", Path: path, Err: err}
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("not a directory: %s (use get_file_info or read_file)", path)
+	}
+	if opts.MaxDepth <= 0 {
+		opts.MaxDepth = DefaultTreeDepth
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+	w := &treeWalker{
+		ctx:      ctx,
+		root:     abs,
+		opts:     opts,
+		maxItems: e.config.MaxListItems,
+		excludes: newSyncExcludes(opts.Exclude, nil),
+		tree:     &DirectoryTree{Path: abs, MaxDepth: opts.MaxDepth},
+	}
+	w.tree.Root = &TreeNode{Name: filepath.Base(abs), Type: "dir"}
+	if err := w.walk(abs, w.tree.Root, 0, true); err != nil {
+		return nil, err
+	}
+	if opts.Git {
+		if ix, err := LoadGitStatus(ctx, abs); err == nil {
+			annotateTreeGit(ix, abs, w.tree.Root)
+		}
+	}
+	return w.tree, nil
+}
+
+// annotateTreeGit sets the git status of n (at path) and the nodes below it
+func annotateTreeGit(ix *GitStatusIndex, path string, n *TreeNode) {
+	n.Git = ix.Status(path, n.Type == "dir")
+	for _, c := range n.Children {
+		annotateTreeGit(ix, filepath.Join(path, c.Name), c)
+	}
+}
+
+// walk fills node from dir (depth levels below the root). list is false
+// below max_depth and past MaxListItems, where only sizes are collected.
+func (w *treeWalker) walk(dir string, node *TreeNode, depth int, list bool) error {
+	if err := w.ctx.Err(); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil // unreadable directory: listed without children
+	}
+
+	var dirs, files []fs.DirEntry
+	for _, entry := range entries {
+		name := entry.Name()
+		if !w.opts.IncludeHidden && strings.HasPrefix(name, ".") {
+			continue
+		}
+		isDir := entry.IsDir()
+		if w.excludes.excluded(w.root, filepath.Join(dir, name), isDir) {
+			continue
+		}
+		if isDir {
+			dirs = append(dirs, entry)
+		} else {
+			files = append(files, entry)
+		}
+	}
+	ordered := append(dirs, files...) // os.ReadDir sorts by name
+
+	for i, entry := range ordered {
+		listed := list && (w.maxItems <= 0 || i < w.maxItems)
+		if list && !listed && node.More == 0 {
+			node.More = len(ordered) - i
+			if !w.opts.IncludeSizes {
+				break
+			}
+		}
+		child := &TreeNode{Name: entry.Name(), Type: "file"}
+		childPath := filepath.Join(dir, entry.Name())
+		switch {
+		case entry.IsDir():
+			child.Type = "dir"
+			expand := listed && depth+1 < w.opts.MaxDepth
+			if listed && !expand {
+				child.Collapsed = true
+			}
+			if expand || w.opts.IncludeSizes {
+				if err := w.walk(childPath, child, depth+1, expand); err != nil {
+					return err
+				}
+			}
+			if listed {
+				w.tree.Dirs++
+			}
+		default:
+			if entry.Type()&fs.ModeSymlink != 0 {
+				child.Type = "symlink"
+			}
+			if fi, err := entry.Info(); err == nil && child.Type == "file" {
+				child.Size = fi.Size()
+			}
+			if listed {
+				w.tree.Files++
+			}
+		}
+		if w.opts.IncludeSizes {
+			node.Size += child.Size
+			node.Files += child.Files
+			if child.Type == "file" {
+				node.Files++
+			}
+		}
+		if listed {
+			node.Children = append(node.Children, child)
+		}
+	}
+	return nil
+}
+
+// prunedTree returns a copy of n with directories deeper than depth collapsed
+func prunedTree(n *TreeNode, depth int) *TreeNode {
+	c := *n
+	if n.Type != "dir" || len(n.Children) == 0 {
+		return &c
+	}
+	if depth <= 0 {
+		c.Children, c.More, c.Collapsed = nil, 0, true
+		return &c
+	}
+	c.Children = make([]*TreeNode, len(n.Children))
+	for i, child := range n.Children {
+		c.Children[i] = prunedTree(child, depth-1)
+	}
+	return &c
+}
+
+// DirectoryTreeJSON renders t as indented JSON within maxBytes (<= 0 = no
+// limit), collapsing the deepest level until it fits
+func DirectoryTreeJSON(t *DirectoryTree, maxBytes int) string {
+	out := *t
+	for depth := t.MaxDepth - 1; ; depth-- {
+		data, _ := json.MarshalIndent(out, "", "  ")
+		if maxBytes <= 0 || len(data) <= maxBytes || depth < 0 {
+			return string(data)
+		}
+		out.Root = prunedTree(t.Root, depth)
+		out.Truncated = fmt.Sprintf("collapsed below depth %d to stay under the %s response limit; narrow path or lower max_depth", depth, formatSize(int64(maxBytes)))
+	}
+}
+
+// FormatDirectoryTree renders t as an indented tree within maxBytes (<= 0 =
+// no limit). Compact mode indents with spaces instead of box-drawing glyphs.
+func FormatDirectoryTree(t *DirectoryTree, compact bool, maxBytes int) string {
+	annotate := func(n *TreeNode) string {
+		var note string
+		switch {
+		case n.Type == "dir" && (n.Files > 0 || n.Size > 0):
+			note = fmt.Sprintf(" (%d files, %s)", n.Files, formatSize(n.Size))
+		case n.Type == "file" && n.Size > 0:
+			note = fmt.Sprintf(" (%s)", formatSize(n.Size))
+		case n.Type == "symlink":
+			note = " (symlink)"
+		}
+		if n.Git != "" {
+			note += " [" + n.Git + "]"
+		}
+		return note
+	}
+	name := func(n *TreeNode) string {
+		if n.Type == "dir" {
+			return n.Name + "/"
+		}
+		return n.Name
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%s/ — %d dirs, %d files", t.Path, t.Dirs, t.Files))
+	if t.Root.Files > 0 || t.Root.Size > 0 {
+		b.WriteString(fmt.Sprintf(" listed; %d files, %s in total", t.Root.Files, formatSize(t.Root.Size)))
+	}
+	b.WriteString("\n")
+
+	truncated := false
+	var render func(n *TreeNode, prefix string)
+	render = func(n *TreeNode, prefix string) {
+		for i, child := range n.Children {
+			if truncated {
+				return
+			}
+			last := i == len(n.Children)-1 && n.More == 0
+			branch, indent := "├── ", "│   "
+			if last {
+				branch, indent = "└── ", "    "
+			}
+			if compact {
+				branch, indent = "", "  "
+			}
+			line := prefix + branch + name(child) + annotate(child) + "\n"
+			if maxBytes > 0 && b.Len()+len(line) > maxBytes {
+				truncated = true
+				return
+			}
+			b.WriteString(line)
+			render(child, prefix+indent)
+		}
+		if n.More > 0 && !truncated {
+			branch := "└── "
+			if compact {
+				branch = ""
+			}
+			b.WriteString(fmt.Sprintf("%s%s… %d more (max list items)\n", prefix, branch, n.More))
+		}
+	}
+	render(t.Root, "")
+
+	if truncated {
+		b.WriteString(fmt.Sprintf("… truncated at the %s response limit: narrow path, lower max_depth or add exclude\n", formatSize(int64(maxBytes))))
+	} else if hasCollapsed(t.Root) {
+		b.WriteString(fmt.Sprintf("(directories at depth %d are not expanded: raise max_depth or call analyze_directory tree on them)\n", t.MaxDepth))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// hasCollapsed reports whether any directory under n is collapsed
+func hasCollapsed(n *TreeNode) bool {
+	for _, c := range n.Children {
+		if c.Collapsed || hasCollapsed(c) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTreeFixture(t *testing.T, dir string) {
+	t.Helper()
+	for path, content := range map[string]string{
+		"README.md":               "hello",
+		"src/main.go":             "package main",
+		"src/pkg/util.go":         "package pkg\n",
+		"src/pkg/deep/x/y.go":     "package y",
+		"node_modules/m/index.js": "x",
+		".git/HEAD":               "ref",
+		".env":                    "SECRET=1",
+		"build.log":               "log",
+	} {
+		full := filepath.Join(dir, path)
+		os.MkdirAll(filepath.Dir(full), 0755)
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDirectoryTree_DepthExcludesAndSizes(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	writeTreeFixture(t, dir)
+
+	tree, err := engine.DirectoryTree(context.Background(), dir, TreeOptions{MaxDepth: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := FormatDirectoryTree(tree, false, 0)
+	for _, want := range []string{"├── src/", "│   ├── pkg/", "main.go", "build.log", "not expanded"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("tree missing %q:\n%s", want, text)
+		}
+	}
+	for _, banned := range []string{"node_modules", ".git", ".env", "util.go"} {
+		if strings.Contains(text, banned) {
+			t.Errorf("tree lists %q:\n%s", banned, text)
+		}
+	}
+
+	tree, err = engine.DirectoryTree(context.Background(), dir, TreeOptions{
+		MaxDepth: 1, IncludeSizes: true, IncludeHidden: true, Exclude: []string{"*.log"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := tree.Root.Children[0]
+	if src.Name != "src" || !src.Collapsed || src.Files != 3 || src.Size != int64(len("package main")+len("package pkg\n")+len("package y")) {
+		t.Errorf("src = %+v", src)
+	}
+	names := []string{}
+	for _, c := range tree.Root.Children {
+		names = append(names, c.Name)
+	}
+	if got := strings.Join(names, ","); got != "src,.env,README.md" {
+		t.Errorf("root entries = %s", got)
+	}
+}
+
+func TestDirectoryTree_MoreMarkersAndTruncation(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	engine.config.MaxListItems = 3
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		os.WriteFile(filepath.Join(dir, name+".txt"), []byte(strings.Repeat("x", 10)), 0644)
+	}
+
+	tree, err := engine.DirectoryTree(context.Background(), dir, TreeOptions{IncludeSizes: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tree.Root.Children) != 3 || tree.Root.More != 2 || tree.Root.Files != 5 || tree.Root.Size != 50 {
+		t.Errorf("root = %+v", tree.Root)
+	}
+	if text := FormatDirectoryTree(tree, true, 0); !strings.Contains(text, "… 2 more") {
+		t.Errorf("no more marker:\n%s", text)
+	}
+
+	text := FormatDirectoryTree(tree, false, len(tree.Path)+60)
+	if !strings.Contains(text, "truncated at the") || strings.Contains(text, "c.txt") {
+		t.Errorf("not truncated:\n%s", text)
+	}
+
+	var out DirectoryTree
+	if err := json.Unmarshal([]byte(DirectoryTreeJSON(tree, 0)), &out); err != nil || out.Root.More != 2 {
+		t.Errorf("json = %+v, %v", out.Root, err)
+	}
+
+	if _, err := engine.DirectoryTree(context.Background(), filepath.Join(dir, "a.txt"), TreeOptions{}); err == nil {
+		t.Error("file accepted as tree root")
+	}
+}
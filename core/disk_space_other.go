@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package core
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// diskSpace is not implemented on this platform
+func diskSpace(path string) (total, free, available int64, err error) {
+	return 0, 0, 0, fmt.Errorf("disk space is not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,16 @@
+//go:build linux || darwin || freebsd
+
+package core
+
+import "syscall"
+
+// diskSpace reports the size of the filesystem holding path, its free bytes
+// and the bytes available to unprivileged users (statfs)
+func diskSpace(path string) (total, free, available int64, err error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, 0, 0, err
+	}
+	bsize := int64(st.Bsize)
+	return int64(st.Blocks) * bsize, int64(st.Bfree) * bsize, int64(st.Bavail) * bsize, nil
+}
@@ -0,0 +1,26 @@
+//go:build windows
+
+package core
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var procGetDiskFreeSpaceExW = syscall.NewLazyDLL("kernel32.dll").NewProc("GetDiskFreeSpaceExW")
+
+// diskSpace reports the size of the volume holding path, its free bytes and
+// the bytes available to the calling user (GetDiskFreeSpaceEx)
+func diskSpace(path string) (total, free, available int64, err error) {
+	p, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	var avail, tot, totalFree uint64
+	r, _, callErr := procGetDiskFreeSpaceExW.Call(uintptr(unsafe.Pointer(p)),
+		uintptr(unsafe.Pointer(&avail)), uintptr(unsafe.Pointer(&tot)), uintptr(unsafe.Pointer(&totalFree)))
+	if r == 0 {
+		return 0, 0, 0, callErr
+	}
+	return int64(tot), int64(totalFree), int64(avail), nil
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DiskSpace describes the filesystem (volume) holding a path
+type DiskSpace struct {
+	Total int64 `json:"total"`
+	Free  int64 `json:"free"` // available to this process (excludes blocks reserved for root)
+	Used  int64 `json:"used"`
+	// UsedPercent is Used over Total, 0-100
+	UsedPercent float64 `json:"used_percent"`
+}
+
+// DiskSpaceOf returns the disk space of the filesystem holding path. path
+// must exist; access control is the caller's business.
+func DiskSpaceOf(path string) (DiskSpace, error) {
+	total, free, available, err := diskSpace(path)
+	if err != nil {
+		return DiskSpace{}, err
+	}
+	d := DiskSpace{Total: total, Free: available, Used: total - free}
+	if total > 0 {
+		d.UsedPercent = float64(d.Used) * 100 / float64(total)
+	}
+	return d, nil
+}
+
+// BackupUsage is what the backup directory holds
+type BackupUsage struct {
+	Dir   string `json:"dir"`
+	Count int    `json:"count"`
+	Bytes int64  `json:"bytes"`
+	// Disk is set when the backup directory is on another filesystem than
+	// the path asked about
+	Disk *DiskSpace `json:"disk,omitempty"`
+}
+
+// DiskUsageResponse is the analyze_directory disk_usage response
+type DiskUsageResponse struct {
+	Path    string       `json:"path"`
+	Disk    DiskSpace    `json:"disk"`
+	Backups *BackupUsage `json:"backups,omitempty"`
+}
+
+// DiskUsage reports total, free and used bytes of the filesystem holding
+// path, and how much the backup directory consumes
+func (e *UltraFastEngine) DiskUsage(ctx context.Context, path string) (*DiskUsageResponse, error) {
+	path = NormalizePath(path)
+	if err := e.acquireOperation(ctx, "fileinfo"); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	defer e.releaseOperation("fileinfo", start)
+
+	if !e.IsPathAllowed(path) {
+		return nil, e.AccessDeniedError("disk_usage", path)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil, &PathError{Op: "disk_usage", Path: path, Err: err}
+	}
+	disk, err := DiskSpaceOf(path)
+	if err != nil {
+		return nil, &PathError{Op: "disk_usage", Path: path, Err: err}
+	}
+	out := &DiskUsageResponse{Path: path, Disk: disk}
+	if abs, err := filepath.Abs(path); err == nil {
+		out.Path = abs
+	}
+
+	if bm := e.backupManager; bm != nil {
+		count, bytes := bm.Totals()
+		out.Backups = &BackupUsage{Dir: bm.GetBackupDir(), Count: count, Bytes: bytes}
+		if backupDisk, err := DiskSpaceOf(bm.GetBackupDir()); err == nil && backupDisk.Total != disk.Total {
+			out.Backups.Disk = &backupDisk
+		}
+	}
+	return out, nil
+}
+
+// FormatDiskUsage renders a DiskUsageResponse as text
+func FormatDiskUsage(r *DiskUsageResponse, compact bool) string {
+	d := r.Disk
+	if compact {
+		line := fmt.Sprintf("%s: %s free of %s (%.0f%% used)", r.Path, formatSize(d.Free), formatSize(d.Total), d.UsedPercent)
+		if b := r.Backups; b != nil {
+			line += fmt.Sprintf(" | backups %s in %d", formatSize(b.Bytes), b.Count)
+		}
+		return line
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Disk usage for %s\n", r.Path))
+	sb.WriteString(fmt.Sprintf("  Total: %s\n  Used:  %s (%.1f%%)\n  Free:  %s\n", formatSize(d.Total), formatSize(d.Used), d.UsedPercent, formatSize(d.Free)))
+	if b := r.Backups; b != nil {
+		sb.WriteString(fmt.Sprintf("Backups: %d using %s in %s", b.Count, formatSize(b.Bytes), b.Dir))
+		if b.Disk != nil {
+			sb.WriteString(fmt.Sprintf(" (another filesystem: %s free of %s)", formatSize(b.Disk.Free), formatSize(b.Disk.Total)))
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
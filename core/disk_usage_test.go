@@ -0,0 +1,29 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestDiskUsage_ReportsFilesystemAndBackups(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	res, err := engine.DiskUsage(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := res.Disk
+	if d.Total <= 0 || d.Free <= 0 || d.Free > d.Total || d.Used < 0 || d.UsedPercent < 0 || d.UsedPercent > 100 {
+		t.Errorf("disk = %+v", d)
+	}
+	if res.Backups == nil || res.Backups.Dir == "" {
+		t.Errorf("backups = %+v", res.Backups)
+	}
+	if text := FormatDiskUsage(res, false); !strings.Contains(text, "Free:") || !strings.Contains(text, "Backups:") {
+		t.Errorf("text:\n%s", text)
+	}
+
+	if _, err := engine.DiskUsage(context.Background(), t.TempDir()); err == nil || !strings.Contains(err.Error(), "access denied") {
+		t.Errorf("outside allowed paths: err = %v", err)
+	}
+}
@@ -0,0 +1,235 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDuplicateMinSize is the analyze_directory duplicates min_size default:
+// smaller files reclaim too little to be worth hashing
+const DefaultDuplicateMinSize = 1024
+
+// DuplicateGroup is a set of byte-identical files
+type DuplicateGroup struct {
+	Hash  string   `json:"sha256"`
+	Size  int64    `json:"size"`
+	Paths []string `json:"paths"`
+	// Reclaimable is what deleting all copies but one would free
+	Reclaimable int64 `json:"reclaimable"`
+}
+
+// DuplicateReport is the analyze_directory duplicates response
+type DuplicateReport struct {
+	Path    string           `json:"path"`
+	MinSize int64            `json:"min_size"`
+	Groups  []DuplicateGroup `json:"groups"`
+	// GroupsFound counts every group; Groups stops at MaxSearchResults
+	GroupsFound  int   `json:"groups_found"`
+	Truncated    bool  `json:"truncated,omitempty"`
+	Reclaimable  int64 `json:"reclaimable"` // over all groups found
+	FilesScanned int   `json:"files_scanned"`
+	FilesHashed  int   `json:"files_hashed"`
+	Skipped      int   `json:"skipped,omitempty"` // unreadable entries
+	DurationMs   int64 `json:"duration_ms"`
+}
+
+// FindDuplicateFiles reports byte-identical files under path. Files of at
+// least minSize bytes are grouped by size first; only sizes shared by two or
+// more files are hashed (SHA-256, streamed through the buffer pool on the
+// worker pool). Groups are sorted by reclaimable bytes and capped at
+// MaxSearchResults. The default excludes (.git, node_modules, ...) and
+// exclude globs are skipped; symlinks are not followed. Nothing is deleted.
+func (e *UltraFastEngine) FindDuplicateFiles(ctx context.Context, path string, minSize int64, exclude []string) (*DuplicateReport, error) {
+	path = NormalizePath(path)
+	if err := e.acquireOperation(ctx, "search"); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	defer e.releaseOperation("search", start)
+
+	if !e.IsPathAllowed(path) {
+		return nil, e.AccessDeniedError("find_duplicate_files", path)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, &PathError{Op: "find_duplicate_files", Path: path, Err: err}
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("not a directory: %s", path)
+	}
+	if minSize <= 0 {
+		minSize = DefaultDuplicateMinSize
+	}
+	root, err := filepath.Abs(path)
+	if err != nil {
+		root = path
+	}
+
+	report := &DuplicateReport{Path: root, MinSize: minSize, Groups: []DuplicateGroup{}}
+	excludes := newSyncExcludes(exclude, nil)
+	bySize := make(map[int64][]string)
+	walkErr := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			report.Skipped++
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if excludes.excluded(root, p, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			report.Skipped++
+			return nil
+		}
+		report.FilesScanned++
+		if fi.Size() >= minSize {
+			bySize[fi.Size()] = append(bySize[fi.Size()], p)
+		}
+		return nil
+	})
+	if walkErr != nil {
+		return nil, walkErr
+	}
+
+	// Hash only the files that share a size with another file
+	type hashed struct {
+		path string
+		size int64
+		sum  string
+	}
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results []hashed
+	)
+	for size, paths := range bySize {
+		if len(paths) < 2 {
+			continue
+		}
+		for _, p := range paths {
+			p, size := p, size
+			task := func() {
+				defer wg.Done()
+				if ctx.Err() != nil {
+					return
+				}
+				sum, err := e.hashFileBuffered(p)
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					report.Skipped++
+					return
+				}
+				results = append(results, hashed{p, size, sum})
+			}
+			wg.Add(1)
+			if e.workerPool == nil || e.workerPool.Submit(task) != nil {
+				task()
+			}
+		}
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	report.FilesHashed = len(results)
+
+	byHash := make(map[string]*DuplicateGroup)
+	for _, r := range results {
+		g := byHash[r.sum]
+		if g == nil {
+			g = &DuplicateGroup{Hash: r.sum, Size: r.size}
+			byHash[r.sum] = g
+		}
+		g.Paths = append(g.Paths, r.path)
+	}
+	for _, g := range byHash {
+		if len(g.Paths) < 2 {
+			continue
+		}
+		sort.Strings(g.Paths)
+		g.Reclaimable = g.Size * int64(len(g.Paths)-1)
+		report.Reclaimable += g.Reclaimable
+		report.Groups = append(report.Groups, *g)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		a, b := report.Groups[i], report.Groups[j]
+		if a.Reclaimable != b.Reclaimable {
+			return a.Reclaimable > b.Reclaimable
+		}
+		return a.Paths[0] < b.Paths[0]
+	})
+	report.GroupsFound = len(report.Groups)
+	if max := e.config.MaxSearchResults; max > 0 && len(report.Groups) > max {
+		report.Groups = report.Groups[:max]
+		report.Truncated = true
+	}
+	report.DurationMs = time.Since(start).Milliseconds()
+	return report, nil
+}
+
+// hashFileBuffered is hashFile with a pooled read buffer
+func (e *UltraFastEngine) hashFileBuffered(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	bufPtr := e.bufferPool.Get().(*[]byte)
+	defer e.bufferPool.Put(bufPtr)
+	hash := sha256.New()
+	if _, err := io.CopyBuffer(hash, f, *bufPtr); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// FormatDuplicateReport renders a DuplicateReport as text
+func FormatDuplicateReport(r *DuplicateReport, compact bool) string {
+	if compact {
+		return fmt.Sprintf("%d duplicate groups, %s reclaimable (%d files scanned, %d hashed)",
+			r.GroupsFound, formatSize(r.Reclaimable), r.FilesScanned, r.FilesHashed)
+	}
+	var sb strings.Builder
+	if r.GroupsFound == 0 {
+		sb.WriteString(fmt.Sprintf("No duplicate files of %s or more under %s (%d files scanned)", formatSize(r.MinSize), r.Path, r.FilesScanned))
+	} else {
+		sb.WriteString(fmt.Sprintf("%d duplicate groups under %s: %s reclaimable (%d files scanned, %d hashed, %dms)\n",
+			r.GroupsFound, r.Path, formatSize(r.Reclaimable), r.FilesScanned, r.FilesHashed, r.DurationMs))
+		for i, g := range r.Groups {
+			sb.WriteString(fmt.Sprintf("\n%d. %d copies × %s — %s reclaimable (sha256 %s)\n", i+1, len(g.Paths), formatSize(g.Size), formatSize(g.Reclaimable), g.Hash[:12]))
+			for _, p := range g.Paths {
+				sb.WriteString("   " + p + "\n")
+			}
+		}
+		if r.Truncated {
+			sb.WriteString(fmt.Sprintf("\n… %d more groups (max search results); raise min_size or narrow path\n", r.GroupsFound-len(r.Groups)))
+		}
+	}
+	if r.Skipped > 0 {
+		sb.WriteString(fmt.Sprintf("\n⚠️  %d unreadable entries skipped\n", r.Skipped))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
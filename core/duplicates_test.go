@@ -0,0 +1,61 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindDuplicateFiles_GroupsByContent(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	big := strings.Repeat("a", 4096)
+	small := strings.Repeat("b", 2048)
+	for path, content := range map[string]string{
+		"img/logo.png":          big,
+		"img/copy/logo.png":     big,
+		"backup/logo.png":       big,
+		"docs/a.txt":            small,
+		"docs/b.txt":            small,
+		"docs/same-size.txt":    strings.Repeat("c", 2048), // same size, different bytes
+		"tiny1.txt":             "x",
+		"tiny2.txt":             "x",
+		"node_modules/logo.png": big,
+		"dist/logo.png":         big,
+	} {
+		full := filepath.Join(dir, path)
+		os.MkdirAll(filepath.Dir(full), 0755)
+		os.WriteFile(full, []byte(content), 0644)
+	}
+
+	report, err := engine.FindDuplicateFiles(context.Background(), dir, 0, []string{"dist/**"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.GroupsFound != 2 || report.Reclaimable != 2*4096+2048 || report.FilesHashed != 6 {
+		t.Fatalf("report = %+v", report)
+	}
+	first := report.Groups[0]
+	if first.Size != 4096 || first.Reclaimable != 8192 || len(first.Paths) != 3 || first.Paths[0] != filepath.Join(dir, "backup/logo.png") {
+		t.Errorf("first group = %+v", first)
+	}
+	if got := report.Groups[1].Paths; len(got) != 2 || strings.Contains(strings.Join(got, ","), "same-size") {
+		t.Errorf("second group = %v", got)
+	}
+	if text := FormatDuplicateReport(report, false); !strings.Contains(text, "3 copies × 4.0 KB") {
+		t.Errorf("text:\n%s", text)
+	}
+
+	engine.config.MaxSearchResults = 1
+	report, err = engine.FindDuplicateFiles(context.Background(), dir, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Truncated || len(report.Groups) != 1 || report.GroupsFound != 2 || len(report.Groups[0].Paths) != 4 {
+		t.Errorf("capped report = %+v", report)
+	}
+	if text := FormatDuplicateReport(report, false); !strings.Contains(text, "… 1 more groups") {
+		t.Errorf("text:\n%s", text)
+	}
+}
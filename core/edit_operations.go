@@ -6,11 +6,13 @@
 	"encoding/json"
 	"fmt"
 	"hash/fnv"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/mcp/filesystem-ultra/mcp"
@@ -108,6 +110,9 @@ func (e *UltraFastEngine) EditFile(ctx context.Context, path, oldText, newText s
 	if !e.IsPathAllowed(path) {
 		return nil, e.AccessDeniedError("edit", path)
 	}
+	if err := e.CheckWritable("edit", path); err != nil {
+		return nil, err
+	}
 
 	// Validate file
 	if err := e.validateEditableFile(path); err != nil {
@@ -133,7 +138,15 @@ func (e *UltraFastEngine) EditFile(ctx context.Context, path, oldText, newText s
 	_, contextWarning := e.validateEditContext(string(content), oldText)
 
 	// Calculate change impact for risk assessment
-	impact := CalculateChangeImpact(string(content), oldText, newText, e.riskThresholds)
+	impact := CalculateChangeImpact(string(content), oldText, newText, e.riskThresholds, path)
+
+	// Two-step confirmation gate (--confirm-tokens): HIGH/CRITICAL edits need
+	// a confirm_token issued for this exact edit and file state.
+	if !dryRun {
+		if err := e.requireConfirmation(ctx, "edit_file", path, editOpKey(oldText, newText), string(content), impact); err != nil {
+			return nil, err
+		}
+	}
 
 	// Create persistent backup BEFORE blocking decision (Bug #16)
 	// Ensures backup exists even for blocked CRITICAL operations
@@ -178,6 +191,7 @@ func (e *UltraFastEngine) EditFile(ctx context.Context, path, oldText, newText s
 			FilePath:   path,
 			Operation:  "edit",
 			OldContent: string(content),
+			NewContent: newText,
 			Timestamp:  time.Now(),
 			WorkingDir: workingDir,
 			Metadata: map[string]interface{}{
@@ -235,7 +249,7 @@ func (e *UltraFastEngine) EditFile(ctx context.Context, path, oldText, newText s
 	// Atomic rename
 	if err := os.Rename(tmpPath, path); err != nil {
 		os.Remove(tmpPath)
-		return nil, fmt.Errorf("error finalizing edit: %w", err)
+		return nil, fmt.Errorf("error finalizing edit: %w", lockedError("edit", path, err))
 	}
 
 	// Invalidate cache
@@ -310,6 +324,16 @@ func (e *UltraFastEngine) EditFile(ctx context.Context, path, oldText, newText s
 	// next edit's expected_hash without a re-read.
 	result.NewHash = contentHashFNV(finalContent)
 
+	e.recordOperation(ctx, OperationRecord{
+		Operation:    "edit",
+		Path:         path,
+		RiskLevel:    impact.RiskLevel,
+		Replacements: result.ReplacementCount,
+		BytesWritten: int64(len(finalContent)),
+		BackupID:     backupID,
+		Forced:       force,
+	})
+
 	return result, nil
 }
 
@@ -343,13 +367,18 @@ func (e *UltraFastEngine) SearchAndReplace(ctx context.Context, path, pattern, r
 	if err != nil {
 		return nil, fmt.Errorf("error accessing path: %w", err)
 	}
+	if !info.IsDir() {
+		if err := e.CheckWritable("search_replace", validPath); err != nil {
+			return nil, err
+		}
+	}
 
 	var results []string
 	var totalReplacements int
 
 	if info.IsDir() {
 		// Search and replace in directory
-		err = e.searchAndReplaceInDirectory(validPath, pattern, replacement, caseSensitive, dryRun, &results, &totalReplacements)
+		err = e.searchAndReplaceInDirectory(ctx, validPath, pattern, replacement, caseSensitive, dryRun, &results, &totalReplacements)
 	} else {
 		// Search and replace in single file
 		replacements, err := e.searchAndReplaceInFile(validPath, pattern, replacement, caseSensitive, dryRun)
@@ -780,40 +809,88 @@ func (e *UltraFastEngine) performIntelligentEdit(content, oldText, newText strin
 	}, fmt.Errorf("no matches found for text: %q", oldText)
 }
 
-// searchAndReplaceInDirectory performs search and replace in all files in a directory.
+// searchAndReplaceInDirectory performs search and replace in all files under
+// dirPath. The files are collected first, then processed on the worker pool,
+// at most ParallelOps at a time. Per-file results come back over a channel and are reported in walk
+// order, so the output does not depend on scheduling.
 // When dryRun is true, replacement counts are computed but no files are modified.
-func (e *UltraFastEngine) searchAndReplaceInDirectory(dirPath, pattern, replacement string, caseSensitive bool, dryRun bool, results *[]string, totalReplacements *int) error {
-	entries, err := os.ReadDir(dirPath)
+func (e *UltraFastEngine) searchAndReplaceInDirectory(ctx context.Context, dirPath, pattern, replacement string, caseSensitive bool, dryRun bool, results *[]string, totalReplacements *int) error {
+	files, err := collectReplaceCandidates(dirPath)
 	if err != nil {
 		return err
 	}
 
-	for _, entry := range entries {
-		fullPath := dirPath + "/" + entry.Name()
-
-		if entry.IsDir() {
-			// Recursively search subdirectories
-			err := e.searchAndReplaceInDirectory(fullPath, pattern, replacement, caseSensitive, dryRun, results, totalReplacements)
-			if err != nil {
-				continue // Continue with other directories
+	type fileOutcome struct {
+		index        int
+		replacements int
+	}
+	outcomes := make(chan fileOutcome, len(files))
+	var wg sync.WaitGroup
+	for i, f := range files {
+		i, f := i, f
+		task := func() {
+			defer wg.Done()
+			if ctx.Err() != nil {
+				return
 			}
-		} else {
-			// Process file
-			replacements, err := e.searchAndReplaceInFile(fullPath, pattern, replacement, caseSensitive, dryRun)
+			replacements, err := e.searchAndReplaceInFile(f, pattern, replacement, caseSensitive, dryRun)
 			if err == nil && replacements > 0 {
-				*results = append(*results, fmt.Sprintf("📄 %s: %d replacements", fullPath, replacements))
-				*totalReplacements += replacements
+				outcomes <- fileOutcome{i, replacements}
 			}
 		}
+		wg.Add(1)
+		if e.workerPool == nil || e.workerPool.Submit(task) != nil {
+			task()
+		}
+	}
+	wg.Wait()
+	close(outcomes)
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("operation cancelled: %w", err)
 	}
 
+	var done []fileOutcome
+	for o := range outcomes {
+		done = append(done, o)
+	}
+	sort.Slice(done, func(a, b int) bool { return done[a].index < done[b].index })
+	for _, o := range done {
+		*results = append(*results, fmt.Sprintf("📄 %s: %d replacements", files[o.index], o.replacements))
+		*totalReplacements += o.replacements
+	}
 	return nil
 }
 
+// collectReplaceCandidates lists every non-directory entry under root in walk
+// order, symlinks included, skipping only unreadable subdirectories. Like the
+// sequential walk it replaced, it does not prune dependency or build
+// directories: search_replace on a directory reaches every file below it.
+func collectReplaceCandidates(root string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			if p == root {
+				return walkErr
+			}
+			return nil // Continue with other directories
+		}
+		if !d.IsDir() {
+			files = append(files, p)
+		}
+		return nil
+	})
+	return files, err
+}
+
 // searchAndReplaceInFile performs search and replace in a single file.
 // When dryRun is true, the would-be replacement count is returned but the file
 // is not modified.
 func (e *UltraFastEngine) searchAndReplaceInFile(filePath, pattern, replacement string, caseSensitive bool, dryRun bool) (int, error) {
+	// Directory runs skip write-protected files
+	if err := e.CheckWritable("search_replace", filePath); err != nil {
+		return 0, err
+	}
+
 	// Check if file is text and not too large
 	info, err := os.Stat(filePath)
 	if err != nil {
@@ -832,8 +909,7 @@ func (e *UltraFastEngine) searchAndReplaceInFile(filePath, pattern, replacement
 
 	contentStr := string(content)
 
-	// Check if it's a text file (basic check)
-	if !isTextContent(contentStr) {
+	if !detectStringContentType(filePath, contentStr).Text() {
 		return 0, nil // Skip binary files
 	}
 
@@ -878,7 +954,7 @@ func (e *UltraFastEngine) searchAndReplaceInFile(filePath, pattern, replacement
 
 	if err := os.Rename(tmpPath, filePath); err != nil {
 		os.Remove(tmpPath)
-		return 0, err
+		return 0, lockedError("edit", filePath, err)
 	}
 
 	// Invalidate cache
@@ -1106,12 +1182,6 @@ func calculateLinesWithText(content, text string) int {
 	return count
 }
 
-func isTextContent(content string) bool {
-	// Simple heuristic: if content has too many null bytes, it's likely binary
-	nullCount := strings.Count(content, "\x00")
-	return float64(nullCount)/float64(len(content)) < 0.01
-}
-
 // MultiEditOperation represents a single edit in a batch.
 // Supports old_text/new_text, old_str/new_str (Claude Desktop alias) and
 // old_string/new_string (Claude Code / filesystem-ultra-rust convention).
@@ -1236,6 +1306,9 @@ func (e *UltraFastEngine) MultiEdit(ctx context.Context, path string, edits []Mu
 	if !e.IsPathAllowed(path) {
 		return nil, e.AccessDeniedError("multi_edit", path)
 	}
+	if err := e.CheckWritable("multi_edit", path); err != nil {
+		return nil, err
+	}
 
 	// Validate file
 	if err := e.validateEditableFile(path); err != nil {
@@ -1323,6 +1396,20 @@ func (e *UltraFastEngine) MultiEdit(ctx context.Context, path string, edits []Mu
 		}
 	}
 	aggregateImpact := calculateMultiEditImpact(originalContent, simContent, edits, e.riskThresholds)
+	if originalContent != simContent {
+		applyCriticalFileRisk(aggregateImpact, path, e.riskThresholds.CriticalFiles)
+	}
+
+	// Two-step confirmation gate (--confirm-tokens)
+	if !dryRun {
+		keyParts := make([]string, 0, len(edits)*2)
+		for _, edit := range edits {
+			keyParts = append(keyParts, edit.OldText, edit.NewText)
+		}
+		if err := e.requireConfirmation(ctx, "multi_edit", path, editOpKey(keyParts...), originalContent, aggregateImpact); err != nil {
+			return nil, err
+		}
+	}
 
 	// Create persistent backup BEFORE blocking decision (Bug #16)
 	// Skip backup creation in dry_run mode (Bug #32)
@@ -1355,12 +1442,17 @@ func (e *UltraFastEngine) MultiEdit(ctx context.Context, path string, edits []Mu
 	var hookCtx *HookContext
 	if !dryRun {
 		workingDir, _ := os.Getwd()
+		newTexts := make([]string, len(edits))
+		for i, edit := range edits {
+			newTexts[i] = edit.NewText
+		}
 		hookCtx = &HookContext{
 			Event:      HookPreEdit,
 			ToolName:   "multi_edit",
 			FilePath:   path,
 			Operation:  "multi_edit",
 			OldContent: originalContent,
+			NewContent: strings.Join(newTexts, "\n"),
 			Timestamp:  time.Now(),
 			WorkingDir: workingDir,
 			Metadata: map[string]interface{}{
@@ -1596,7 +1688,7 @@ func (e *UltraFastEngine) MultiEdit(ctx context.Context, path string, edits []Mu
 	// Atomic rename
 	if err := os.Rename(tmpPath, path); err != nil {
 		os.Remove(tmpPath)
-		return nil, fmt.Errorf("error finalizing edit: %w", err)
+		return nil, fmt.Errorf("error finalizing edit: %w", lockedError("edit", path, err))
 	}
 
 	// Invalidate cache
@@ -1658,6 +1750,16 @@ func (e *UltraFastEngine) MultiEdit(ctx context.Context, path string, edits []Mu
 	// the aggregate diff without re-reading the file.
 	result.FinalContent = finalContent
 
+	e.recordOperation(ctx, OperationRecord{
+		Operation:    "multi_edit",
+		Path:         path,
+		RiskLevel:    aggregateImpact.RiskLevel,
+		Replacements: int(totalReplacements),
+		BytesWritten: int64(len(finalContent)),
+		BackupID:     backupID,
+		Forced:       force,
+	})
+
 	return result, nil
 }
 
@@ -1780,6 +1882,9 @@ func (e *UltraFastEngine) ReplaceNthOccurrence(ctx context.Context, path, patter
 	if err != nil {
 		return nil, fmt.Errorf("path validation error: %w", err)
 	}
+	if err := e.CheckWritable("replace_nth", validPath); err != nil {
+		return nil, err
+	}
 
 	// Check if file exists
 	info, err := os.Stat(validPath)
@@ -1907,7 +2012,7 @@ type matchInfo struct {
 	// Atomic rename
 	if err := os.Rename(tmpPath, validPath); err != nil {
 		os.Remove(tmpPath)
-		return nil, fmt.Errorf("error finalizing edit: %w", err)
+		return nil, fmt.Errorf("error finalizing edit: %w", lockedError("edit", validPath, err))
 	}
 
 	// Invalidate cache
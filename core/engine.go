@@ -1,21 +1,27 @@
 package core
 
 import (
+	"container/list"
 	"context"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"hash/crc32"
 	"io"
+	"io/fs"
 	"log/slog"
+	"math"
+	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/mcp/filesystem-ultra/cache"
@@ -31,22 +37,135 @@ type Config struct {
 	AllowedPaths     []string
 	BinaryThreshold  int64
 	CompactMode      bool   // Enable compact responses
+	JSONResponses    bool   // Structured JSON tool text by default (see response_types.go)
 	MaxResponseSize  int64  // Max response size
 	MaxSearchResults int    // Max search results
 	MaxListItems     int    // Max list items
 	HooksConfigPath  string // Path to hooks configuration file
 	HooksEnabled     bool   // Enable hooks system
 
+	// SecretScan denies writes and edits that would put credentials on disk
+	// (built-in pre-write/pre-edit hook, independent of HooksEnabled), except
+	// under SecretScanAllow patterns (same syntax as CriticalFiles).
+	SecretScan      bool
+	SecretScanAllow []string
+
+	// AllowPathManagement enables allowed_paths add / remove.
+	// Paths added with persistence are kept in AllowedPathsFile (JSON) and
+	// appended to AllowedPaths at startup.
+	AllowPathManagement bool
+	AllowedPathsFile    string
+
+	// ProtectedPaths are write-protection rules inside AllowedPaths: matching
+	// paths stay readable but every mutating operation refuses them (absolute
+	// paths/globs cover everything below; otherwise CriticalFiles syntax).
+	ProtectedPaths []string
+
+	// ResourceDepth is how many directory levels below each allowed path
+	// are listed as MCP resources (0 = no resources). ResourceExcludes adds
+	// globs to the default excludes and .gitignore rules.
+	ResourceDepth    int
+	ResourceExcludes []string
+
+	// EnableTools / DisableTools select the tools registered at startup by
+	// name or glob ("wsl_*"): with EnableTools set only matching tools are
+	// registered, and DisableTools removes tools from that set.
+	EnableTools  []string
+	DisableTools []string
+
 	// Backup configuration
 	BackupDir      string // Directory for backup storage
 	BackupMaxAge   int    // Max age of backups in days
 	BackupMaxCount int    // Max number of backups to keep
 
+	// TrashMaxAge, when set, purges soft-deleted files older than this at
+	// startup (0 = keep them until backup purge_trash)
+	TrashMaxAge time.Duration
+
+	// TrashDir, when set, is the preferred trash root for soft-deletes on its
+	// volume; elsewhere <BackupDir>/trash or the root's .mcp-trash is used
+	TrashDir string
+
 	// Risk thresholds
-	RiskThresholdMedium   float64 // % change for medium risk
-	RiskThresholdHigh     float64 // % change for high risk
-	RiskOccurrencesMedium int     // Occurrences for medium risk
-	RiskOccurrencesHigh   int     // Occurrences for high risk
+	RiskThresholdMedium   float64  // % change for medium risk
+	RiskThresholdHigh     float64  // % change for high risk
+	RiskOccurrencesMedium int      // Occurrences for medium risk
+	RiskOccurrencesHigh   int      // Occurrences for high risk
+	CriticalFiles         []string // Extra critical-file patterns (extend the built-in list)
+
+	// Two-step confirmation: HIGH/CRITICAL edits return a one-time token
+	// instead of executing; force:true no longer bypasses the gate.
+	ConfirmTokens   bool
+	ConfirmTokenTTL time.Duration // 0 = DefaultConfirmTokenTTL
+
+	// WriteSessionTTL is how long a chunked write_file session may sit idle
+	// before its temp file is removed (0 = DefaultWriteSessionTTL)
+	WriteSessionTTL time.Duration
+
+	// PersistOperationReport mirrors the server_info report ring buffer to
+	// <backup-dir>/operation-report.jsonl so it survives restarts.
+	PersistOperationReport bool
+
+	// MutationLogDir enables the append-only, hash-chained mutation audit log
+	// (server_info audit_log) in that directory, rotated at MutationLogMaxSize bytes
+	// (0 = DefaultMutationLogMaxSize).
+	MutationLogDir     string
+	MutationLogMaxSize int64
+
+	// OperationHistorySize is how many tool calls server_info history
+	// keeps in memory (0 = DefaultOperationHistorySize).
+	OperationHistorySize int
+
+	// SlowOpThreshold logs every MCP tool call that takes at least this long
+	// as one warning line and counts it in SlowOperations (0 = off).
+	SlowOpThreshold time.Duration
+
+	// MetricsAddr, when set (e.g. ":9090"), serves engine metrics at
+	// /metrics in the Prometheus text format. Off by default.
+	MetricsAddr string
+
+	// PersistCache loads <backup-dir>/cache-snapshot.gob at startup and makes
+	// the cache write it on Close, with hot file contents up to
+	// CachePersistBudget bytes (0 = DefaultCachePersistBudget).
+	PersistCache       bool
+	CachePersistBudget int64
+
+	// PersistMetrics keeps the operation counters and edit telemetry in
+	// <backup-dir>/metrics-state.json, saved every minute and on Close, so
+	// they accumulate across restarts until server_info reset_telemetry.
+	PersistMetrics bool
+
+	// Cache admission rules: largest file content cached (0 = the cache
+	// default, negative = no limit), per-entry TTL (0 = none) and path
+	// patterns that are never cached (same syntax as CriticalFiles).
+	CacheMaxFileSize   int64
+	CacheFileTTL       time.Duration
+	NeverCachePatterns []string
+
+	// NegativeCacheTTL is how long a "not found" from read_file/get_file_info
+	// is remembered for repeated probes of the same path (0 = off). Writes,
+	// creates and moves onto the path invalidate it.
+	NegativeCacheTTL time.Duration
+
+	// Watch invalidates cache entries from file system events under
+	// AllowedPaths (fsnotify); hits in watched directories skip the stat
+	// check. MaxWatches bounds the watched directories (0 = DefaultMaxWatches).
+	Watch      bool
+	MaxWatches int
+
+	// MaxWatchSnapshots bounds the directories analyze_directory watch keeps a
+	// snapshot for, least recently used first out (0 = DefaultMaxWatchSnapshots)
+	MaxWatchSnapshots int
+
+	// MaxArtifactBytes bounds the total size of the server_info artifact
+	// slots, least recently used evicted first (0 = DefaultMaxArtifactBytes)
+	MaxArtifactBytes int64
+
+	// PersistArtifacts mirrors the artifact slots to <backup-dir>/artifacts
+	// so they survive restarts; slots older than ArtifactMaxAge are dropped
+	// (0 = DefaultArtifactMaxAge).
+	PersistArtifacts bool
+	ArtifactMaxAge   time.Duration
 
 	// Logging
 	LogDir string // Directory for audit logs and metrics snapshots (empty = disabled)
@@ -66,15 +185,25 @@ type UltraFastEngine struct {
 	cache  *cache.IntelligentCache
 
 	// Performance monitoring
-	metrics *PerformanceMetrics
+	metrics        *PerformanceMetrics
+	metricsPersist *metricsPersistence // nil unless PersistMetrics
+	latency        latencyTracker      // per-operation and per-tool percentiles
 
 	// Parallel operation management
 	semaphore  chan struct{}
 	workerPool *ants.Pool
 
-	// Artifact buffer
-	lastArtifact  string
-	artifactMutex sync.RWMutex
+	// Artifact slots (artifacts.go): name → element of artifactOrder, most
+	// recently used first; artifactLast is the most recently captured name
+	artifacts     map[string]*list.Element
+	artifactOrder *list.List
+	artifactBytes int64
+	artifactLast  string
+	artifactMutex sync.Mutex
+	// artifactDir persists the slots ("" = memory only); they are read back
+	// on the first artifact call, which sets artifactsLoaded
+	artifactDir     string
+	artifactsLoaded bool
 
 	// Claude Desktop optimizer
 	optimizer *ClaudeDesktopOptimizer
@@ -98,6 +227,41 @@ type UltraFastEngine struct {
 	// Risk thresholds for impact analysis
 	riskThresholds RiskThresholds
 
+	// Two-step confirmation for HIGH/CRITICAL edits (nil unless --confirm-tokens)
+	confirmTokens *confirmationStore
+
+	// Oversized responses awaiting fetch_continuation
+	continuations *continuationStore
+
+	// Chunked write_file sessions (write_session.go)
+	writeSessions *writeSessionStore
+
+	// Resource provider state (see resources.go)
+	resourceIgnores *gitignoreCache
+	changeListener  atomic.Pointer[func(path string)]
+
+	// Tool calls in flight, drained on shutdown (see shutdown.go)
+	calls callTracker
+
+	// Cache invalidation watcher (nil unless Config.Watch and it could start)
+	watcher *cacheWatcher
+
+	// Recent mutating operations for server_info report
+	opReport    *operationReport
+	opHistory   *operationHistory
+	mutationLog *mutationLog // nil unless MutationLogDir is set
+
+	metricsServer *http.Server // nil unless MetricsAddr is set
+
+	// Running and recently finished pipelines for pipeline(action:"status")
+	pipelineRuns *pipelineRunRegistry
+
+	// Running and recently finished workspace syncs for wsl sync_status
+	syncRuns *syncRunRegistry
+
+	// Latest snapshot per analyze_directory watch path
+	watchSnapshots *watchSnapshotStore
+
 	// Environment detection cache (WSL/Windows detection)
 	// Caches the result of DetectEnvironment() to avoid repeated /proc/version reads
 	envCache struct {
@@ -116,7 +280,11 @@ type UltraFastEngine struct {
 
 	// Pre-resolved allowed paths (resolved once at startup via Abs + EvalSymlinks + norm)
 	// Only base allowed paths are cached; target paths are still resolved per-call for security.
+	// allowedMu guards them and config.AllowedPaths, which allowed_paths add /
+	// remove replace at runtime (never modified in place).
 	resolvedAllowedPaths []string
+	allowedMu            sync.RWMutex
+	allowedSources       map[string]string // allowedPathKey -> AllowedPathPersisted/Runtime; missing = startup
 
 	// Audit logger for operation tracking (nil if --log-dir not set)
 	auditLogger *AuditLogger
@@ -143,9 +311,9 @@ type UltraFastEngine struct {
 type PerformanceMetrics struct {
 	mu                  sync.RWMutex
 	OperationsTotal     int64
-	OperationsPerSecond float64
+	OperationsPerSecond float64 // over the last metrics interval (5s)
+	OperationsRate1m    float64 // exponentially weighted, 1-minute time constant
 	CacheHitRate        float64
-	AverageResponseTime time.Duration
 	MemoryUsage         int64
 	LastUpdateTime      time.Time
 
@@ -154,6 +322,7 @@ type PerformanceMetrics struct {
 	WriteOperations  int64
 	ListOperations   int64
 	SearchOperations int64
+	SlowOperations   int64 // tool calls over Config.SlowOpThreshold
 
 	// Telemetry: Track edit operations
 	// Used to detect full-file rewrites vs targeted edits
@@ -163,6 +332,11 @@ type PerformanceMetrics struct {
 	LastEditOperation   string  // Description of last edit
 	LastEditBytesSent   int64   // Bytes sent in last edit operation
 	AverageBytesPerEdit float64 // Running average of bytes per edit
+
+	// TrackingSince is when the counters started (engine start, the
+	// persisted window with PersistMetrics, or the last ResetTelemetry)
+	TrackingSince   time.Time
+	opsAtLastUpdate int64 // OperationsTotal at the last updateMetrics, for ops/s
 }
 
 // LogOperationMetric records detailed operation metrics for analysis
@@ -192,7 +366,7 @@ func NewUltraFastEngine(config *Config) (*UltraFastEngine, error) {
 	engine := &UltraFastEngine{
 		config:      config,
 		cache:       config.Cache,
-		metrics:     &PerformanceMetrics{},
+		metrics:     &PerformanceMetrics{TrackingSince: time.Now(), LastUpdateTime: time.Now()},
 		semaphore:   make(chan struct{}, config.ParallelOps),
 		backupChain: make(map[string]string),
 	}
@@ -212,6 +386,9 @@ func NewUltraFastEngine(config *Config) (*UltraFastEngine, error) {
 
 	// Log if allowed paths are configured
 	if len(config.AllowedPaths) > 0 {
+		if config.AllowedPathsFile != "" {
+			engine.loadPersistedAllowedPaths()
+		}
 		slog.Info("Access control enabled", "allowed_paths_count", len(config.AllowedPaths))
 		engine.resolveAllowedPaths()
 	} else {
@@ -251,10 +428,15 @@ func NewUltraFastEngine(config *Config) (*UltraFastEngine, error) {
 			slog.Info("Hook system initialized")
 		}
 	}
+	if config.SecretScan {
+		engine.hookManager.SetSecretGuard(NewSecretGuard(config.SecretScanAllow))
+		slog.Info("Secret scan enabled", "allow", config.SecretScanAllow)
+	}
 
 	// Initialize auto-sync manager
 	engine.autoSyncManager = NewAutoSyncManager()
 	engine.autoSyncManager.SetAllowedPaths(config.AllowedPaths)
+	engine.autoSyncManager.submit = engine.workerPool.Submit
 
 	if engine.autoSyncManager.IsEnabled() {
 		slog.Info("WSL auto-sync enabled")
@@ -282,6 +464,14 @@ func NewUltraFastEngine(config *Config) (*UltraFastEngine, error) {
 		engine.backupManager = backupManager
 		slog.Info("Backup manager initialized", "backup_dir", backupManager.backupDir,
 			"max_age_days", backupMaxAge, "max_count", backupMaxCount)
+		backupManager.configureTrash(NormalizePath(config.TrashDir), engine.rootTrashDirs,
+			func(src, dst string, info os.FileInfo) error {
+				_, err := engine.moveAcrossDevices(context.Background(), src, dst, info)
+				return err
+			}, engine.authorizeTrashRestore)
+		if config.TrashMaxAge > 0 {
+			go purgeExpiredTrash(backupManager, config.TrashMaxAge)
+		}
 	}
 
 	// Initialize risk thresholds
@@ -305,6 +495,101 @@ func NewUltraFastEngine(config *Config) (*UltraFastEngine, error) {
 	} else {
 		engine.riskThresholds.HighOccurrences = 100
 	}
+	engine.riskThresholds.CriticalFiles = config.CriticalFiles
+
+	// Operation report ring buffer (optionally persisted under the backup dir)
+	reportDir := ""
+	if config.PersistOperationReport && engine.backupManager != nil {
+		reportDir = engine.backupManager.backupDir
+	}
+	engine.opReport = newOperationReport(OperationReportSize, reportDir)
+	engine.opHistory = newOperationHistory(config.OperationHistorySize)
+
+	// Mutation audit log: failing to open it must not stop the server
+	if config.MutationLogDir != "" {
+		if ml, err := newMutationLog(config.MutationLogDir, config.MutationLogMaxSize); err != nil {
+			slog.Warn("Mutation audit log disabled", "dir", config.MutationLogDir, "error", err)
+		} else {
+			engine.mutationLog = ml
+			slog.Info("Mutation audit log enabled", "dir", config.MutationLogDir)
+		}
+	}
+
+	if config.PersistArtifacts && engine.backupManager != nil {
+		engine.artifactDir = filepath.Join(engine.backupManager.backupDir, artifactDirName)
+	}
+
+	// Cumulative counters (optionally persisted under the backup dir)
+	if config.PersistMetrics && engine.backupManager != nil {
+		engine.enableMetricsPersistence(engine.backupManager.backupDir)
+	}
+
+	// Metrics endpoint: a port that cannot be bound must not stop the server
+	if config.MetricsAddr != "" {
+		if err := engine.startMetricsServer(config.MetricsAddr); err != nil {
+			slog.Warn("Metrics endpoint disabled", "addr", config.MetricsAddr, "error", err)
+		}
+	}
+
+	// Cache admission rules, applied before the snapshot is loaded
+	if engine.cache != nil {
+		if config.CacheMaxFileSize != 0 {
+			engine.cache.SetMaxFileSize(config.CacheMaxFileSize)
+		}
+		engine.cache.SetFileTTL(config.CacheFileTTL)
+		engine.cache.SetNeverCache(neverCacheMatcher(config.NeverCachePatterns))
+		engine.cache.SetNegativeTTL(config.NegativeCacheTTL)
+	}
+
+	// File watcher (optional): invalidate on external changes. Without it, or
+	// where watching fails, the per-hit stat check keeps the cache correct.
+	if config.Watch && engine.cache != nil {
+		var roots []string
+		for _, allowed := range config.AllowedPaths {
+			if abs, err := filepath.Abs(allowed); err == nil {
+				if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+					abs = resolved
+				}
+				roots = append(roots, NormalizePath(abs))
+			}
+		}
+		if len(roots) > 0 {
+			if w, err := startCacheWatcher(engine, roots, config.MaxWatches); err != nil {
+				slog.Warn("File watcher unavailable, using stat checks", "error", err)
+			} else {
+				engine.watcher = w
+				engine.cache.SetWatched(w.watched)
+			}
+		}
+	}
+
+	// Cache snapshot (optional): warm the cache from the previous run
+	if config.PersistCache && engine.backupManager != nil && engine.cache != nil {
+		budget := config.CachePersistBudget
+		if budget <= 0 {
+			budget = DefaultCachePersistBudget
+		}
+		snapshotPath := filepath.Join(engine.backupManager.backupDir, cacheSnapshotFile)
+		if stats, err := engine.cache.EnablePersistence(snapshotPath, budget); err != nil {
+			slog.Warn("Cache snapshot unusable, starting cold", "error", err)
+		} else if stats.Entries > 0 {
+			slog.Info("Cache warmed from snapshot", "entries", stats.Entries, "restored", stats.Contents,
+				"bytes", stats.Bytes, "skipped", stats.Skipped)
+		}
+	}
+	engine.pipelineRuns = newPipelineRunRegistry()
+	engine.syncRuns = newSyncRunRegistry()
+	engine.watchSnapshots = newWatchSnapshotStore(config.MaxWatchSnapshots)
+	engine.artifacts = make(map[string]*list.Element)
+	engine.artifactOrder = list.New()
+	engine.continuations = newContinuationStore(0)
+	engine.writeSessions = newWriteSessionStore(config.WriteSessionTTL)
+	engine.resourceIgnores = newGitignoreCache()
+
+	if config.ConfirmTokens {
+		engine.confirmTokens = newConfirmationStore(config.ConfirmTokenTTL)
+		slog.Info("Confirmation tokens enabled", "ttl", engine.confirmTokens.ttl)
+	}
 
 	// Initialize audit logger if log directory is configured
 	if config.LogDir != "" {
@@ -333,7 +618,8 @@ func NewUltraFastEngine(config *Config) (*UltraFastEngine, error) {
 }
 
 // resolveAllowedPaths pre-resolves all AllowedPaths using Abs + EvalSymlinks + normalization.
-// Called once at engine initialization. Avoids repeated EvalSymlinks syscalls in isPathAllowed().
+// Called at engine initialization and whenever AllowedPaths changes (caller holds allowedMu
+// or runs before the engine is shared). Avoids repeated EvalSymlinks syscalls in isPathAllowed().
 func (e *UltraFastEngine) resolveAllowedPaths() {
 	norm := func(p string) string {
 		p = filepath.Clean(p)
@@ -343,9 +629,9 @@ func (e *UltraFastEngine) resolveAllowedPaths() {
 		return p
 	}
 
-	e.resolvedAllowedPaths = make([]string, 0, len(e.config.AllowedPaths))
+	resolved := make([]string, 0, len(e.config.AllowedPaths))
 	for _, allowed := range e.config.AllowedPaths {
-		baseAbs, err := filepath.Abs(allowed)
+		baseAbs, err := filepath.Abs(canonicalWSLForm(allowed))
 		if err != nil {
 			slog.Warn("Failed to resolve allowed path", "path", allowed, "error", err)
 			continue
@@ -353,18 +639,56 @@ func (e *UltraFastEngine) resolveAllowedPaths() {
 		if baseResolved, err := filepath.EvalSymlinks(baseAbs); err == nil {
 			baseAbs = baseResolved
 		}
-		e.resolvedAllowedPaths = append(e.resolvedAllowedPaths, norm(baseAbs))
+		resolved = append(resolved, norm(baseAbs))
+	}
+	e.resolvedAllowedPaths = resolved
+}
+
+// allowedBases returns the resolved allowed paths and whether access control
+// is active, re-resolving when AllowedPaths changed at runtime (e.g., tests
+// append paths after init)
+func (e *UltraFastEngine) allowedBases() ([]string, bool) {
+	e.allowedMu.RLock()
+	configured, bases := len(e.config.AllowedPaths), e.resolvedAllowedPaths
+	e.allowedMu.RUnlock()
+	if configured == 0 {
+		return nil, false
+	}
+	if len(bases) != configured {
+		e.allowedMu.Lock()
+		e.resolveAllowedPaths()
+		bases = e.resolvedAllowedPaths
+		e.allowedMu.Unlock()
 	}
+	return bases, true
 }
 
 // Close gracefully shuts down the engine
 func (e *UltraFastEngine) Close() error {
+	e.closeMetricsServer()
+	if e.watcher != nil {
+		e.cache.SetWatched(nil)
+		e.watcher.close()
+	}
+	if e.autoSyncManager != nil {
+		e.autoSyncManager.Flush() // before the worker pool it copies on goes away
+	}
+	if e.writeSessions != nil {
+		e.writeSessions.closeAll()
+	}
 	if e.workerPool != nil {
 		e.workerPool.Release()
 	}
 	if e.auditLogger != nil {
 		e.auditLogger.Close()
 	}
+	if e.opReport != nil {
+		e.opReport.close()
+	}
+	if e.mutationLog != nil {
+		e.mutationLog.close()
+	}
+	e.closeMetricsPersistence()
 	return nil
 }
 
@@ -415,6 +739,7 @@ func (e *UltraFastEngine) metricsSnapshotLoop(logDir string) {
 			UpdatedAt:    time.Now(),
 			OpsTotal:     e.metrics.OperationsTotal,
 			OpsPerSec:    e.metrics.OperationsPerSecond,
+			OpsRate1m:    e.metrics.OperationsRate1m,
 			CacheHitRate: e.metrics.CacheHitRate,
 			MemoryMB:     float64(memStats.Alloc) / (1024 * 1024),
 			Reads:        e.metrics.ReadOperations,
@@ -512,16 +837,29 @@ func (e *UltraFastEngine) StartMonitoring(ctx context.Context) {
 
 // updateMetrics calculates and updates performance metrics
 func (e *UltraFastEngine) updateMetrics() {
+	e.updateMetricsAt(time.Now())
+}
+
+// opsRateWindow is the time constant of OperationsRate1m
+const opsRateWindow = time.Minute
+
+// updateMetricsAt recomputes the metrics for a tick at now. The rates count
+// only the operations since the previous tick, so they reflect current load
+// rather than the lifetime total.
+func (e *UltraFastEngine) updateMetricsAt(now time.Time) {
 	e.metrics.mu.Lock()
 	defer e.metrics.mu.Unlock()
 
-	now := time.Now()
 	if !e.metrics.LastUpdateTime.IsZero() {
 		duration := now.Sub(e.metrics.LastUpdateTime).Seconds()
 		if duration > 0 {
-			e.metrics.OperationsPerSecond = float64(e.metrics.OperationsTotal) / duration
+			rate := float64(e.metrics.OperationsTotal-e.metrics.opsAtLastUpdate) / duration
+			e.metrics.OperationsPerSecond = rate
+			alpha := 1 - math.Exp(-duration/opsRateWindow.Seconds())
+			e.metrics.OperationsRate1m += alpha * (rate - e.metrics.OperationsRate1m)
 		}
 	}
+	e.metrics.opsAtLastUpdate = e.metrics.OperationsTotal
 
 	// Update cache hit rate
 	if e.cache != nil {
@@ -548,8 +886,6 @@ func (e *UltraFastEngine) releaseOperation(opType string, start time.Time) {
 	// Update metrics
 	e.metrics.mu.Lock()
 	e.metrics.OperationsTotal++
-	duration := time.Since(start)
-	e.metrics.AverageResponseTime = (e.metrics.AverageResponseTime + duration) / 2
 
 	// Update operation-specific counters
 	switch opType {
@@ -563,6 +899,7 @@ func (e *UltraFastEngine) releaseOperation(opType string, start time.Time) {
 		e.metrics.SearchOperations++
 	}
 	e.metrics.mu.Unlock()
+	e.latency.record(LatencyKindOp, opType, time.Since(start))
 
 	// Release semaphore slot
 	<-e.semaphore
@@ -591,11 +928,20 @@ func (e *UltraFastEngine) ReadFileContent(ctx context.Context, path string) (str
 		return "", e.AccessDeniedError("read", path)
 	}
 
+	// A path found missing moments ago is answered without touching the disk
+	requested := path
+	if err, hit := e.cachedMissing(requested, "read"); hit {
+		return "", err
+	}
+
 	// TOCTOU defense: re-resolve symlinks and re-authorize the canonical target
 	// immediately before any disk I/O. Operate on the resolved path so the read
 	// cannot be redirected outside the sandbox by a symlink swapped in after the
 	// IsPathAllowed check above.
 	if resolved, err := e.ResolveAndAuthorize("read", path); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			e.rememberMissing(requested, "read", err)
+		}
 		return "", err
 	} else {
 		path = resolved
@@ -635,6 +981,9 @@ func (e *UltraFastEngine) ReadFileContent(ctx context.Context, path string) (str
 	// Load from disk with singleflight dedup on concurrent cache misses.
 	content, err := e.readFileBytesDeduped(ctx, path)
 	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			e.rememberMissing(requested, "read", err)
+		}
 		return "", err
 	}
 
@@ -651,9 +1000,21 @@ func (e *UltraFastEngine) ReadFileContent(ctx context.Context, path string) (str
 
 // WriteFileContent implements atomic file writing
 func (e *UltraFastEngine) WriteFileContent(ctx context.Context, path, content string) error {
+	path, written, err := e.writeFileContent(ctx, path, content)
+	if err != nil {
+		return err
+	}
+	e.recordOperation(ctx, OperationRecord{Operation: "write", Path: path, BytesWritten: int64(len(written))})
+	return nil
+}
+
+// writeFileContent is WriteFileContent without the operation record, for
+// callers that record the write as their own operation. It returns the
+// resolved path and the content as written, after hooks and EOL conversion.
+func (e *UltraFastEngine) writeFileContent(ctx context.Context, path, content string) (string, string, error) {
 	// Check context before starting
 	if err := ctx.Err(); err != nil {
-		return &ContextError{Op: "write_file", Details: "operation cancelled before start"}
+		return "", "", &ContextError{Op: "write_file", Details: "operation cancelled before start"}
 	}
 
 	// Normalize path (handles WSL ↔ Windows conversion)
@@ -661,7 +1022,7 @@ func (e *UltraFastEngine) WriteFileContent(ctx context.Context, path, content st
 
 	// Acquire semaphore
 	if err := e.acquireOperation(ctx, "write"); err != nil {
-		return err
+		return "", "", err
 	}
 
 	start := time.Now()
@@ -669,7 +1030,10 @@ func (e *UltraFastEngine) WriteFileContent(ctx context.Context, path, content st
 
 	// Check if path is allowed (security + access control)
 	if !e.IsPathAllowed(path) {
-		return e.AccessDeniedError("write", path)
+		return "", "", e.AccessDeniedError("write", path)
+	}
+	if err := e.CheckWritable("write", path); err != nil {
+		return "", "", err
 	}
 
 	// TOCTOU defense: re-resolve symlinks and re-authorize the canonical target
@@ -678,14 +1042,14 @@ func (e *UltraFastEngine) WriteFileContent(ctx context.Context, path, content st
 	// in after the IsPathAllowed check above. For new files this resolves the
 	// (existing) parent directory and re-validates it.
 	if resolved, err := e.ResolveAndAuthorize("write", path); err != nil {
-		return err
+		return "", "", err
 	} else {
 		path = resolved
 	}
 
 	// Check context before proceeding with write
 	if err := ctx.Err(); err != nil {
-		return &ContextError{Op: "write_file", Details: "operation cancelled before write"}
+		return "", "", &ContextError{Op: "write_file", Details: "operation cancelled before write"}
 	}
 
 	// Execute pre-write hooks
@@ -702,7 +1066,7 @@ func (e *UltraFastEngine) WriteFileContent(ctx context.Context, path, content st
 
 	hookResult, err := e.hookManager.ExecuteHooks(ctx, HookPreWrite, hookCtx)
 	if err != nil {
-		return fmt.Errorf("pre-write hook denied operation: %w", err)
+		return "", "", fmt.Errorf("pre-write hook denied operation: %w", err)
 	}
 
 	// Use modified content if hook provided it (e.g., formatted code)
@@ -728,7 +1092,7 @@ func (e *UltraFastEngine) WriteFileContent(ctx context.Context, path, content st
 	// Ensure directory exists
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
+		return "", "", fmt.Errorf("failed to create directory: %w", err)
 	}
 
 	// Atomic write using temp file with secure random name
@@ -742,13 +1106,13 @@ func (e *UltraFastEngine) WriteFileContent(ctx context.Context, path, content st
 
 	// Write to temporary file
 	if err := os.WriteFile(tmpPath, []byte(finalContent), fileMode); err != nil {
-		return fmt.Errorf("failed to write temp file: %w", err)
+		return "", "", fmt.Errorf("failed to write temp file: %w", err)
 	}
 
 	// Atomic rename
 	if err := os.Rename(tmpPath, path); err != nil {
 		os.Remove(tmpPath) // Clean up temp file
-		return fmt.Errorf("failed to rename temp file: %w", err)
+		return "", "", fmt.Errorf("failed to rename temp file: %w", lockedError("write", path, err))
 	}
 
 	// Invalidate cache
@@ -764,7 +1128,7 @@ func (e *UltraFastEngine) WriteFileContent(ctx context.Context, path, content st
 		_ = e.autoSyncManager.AfterWrite(path)
 	}
 
-	return nil
+	return path, finalContent, nil
 }
 
 // WriteFileBytes writes raw bytes to a file atomically.
@@ -791,6 +1155,9 @@ func (e *UltraFastEngine) WriteFileBytes(ctx context.Context, path string, data
 	if !e.IsPathAllowed(path) {
 		return e.AccessDeniedError("write_bytes", path)
 	}
+	if err := e.CheckWritable("write_bytes", path); err != nil {
+		return err
+	}
 
 	// Check context before proceeding with write
 	if err := ctx.Err(); err != nil {
@@ -820,7 +1187,7 @@ func (e *UltraFastEngine) WriteFileBytes(ctx context.Context, path string, data
 	// Atomic rename
 	if err := os.Rename(tmpPath, path); err != nil {
 		os.Remove(tmpPath) // Clean up temp file
-		return fmt.Errorf("failed to rename temp file: %w", err)
+		return fmt.Errorf("failed to rename temp file: %w", lockedError("write", path, err))
 	}
 
 	// Invalidate cache
@@ -831,6 +1198,8 @@ func (e *UltraFastEngine) WriteFileBytes(ctx context.Context, path string, data
 		_ = e.autoSyncManager.AfterWrite(path)
 	}
 
+	e.recordOperation(ctx, OperationRecord{Operation: "write_bytes", Path: path, BytesWritten: int64(len(data))})
+
 	return nil
 }
 
@@ -853,7 +1222,7 @@ func atomicWriteFile(path string, data []byte, mode os.FileMode) error {
 	}
 	if err := os.Rename(tmpPath, path); err != nil {
 		os.Remove(tmpPath) // best-effort cleanup
-		return fmt.Errorf("failed to rename temp file: %w", err)
+		return fmt.Errorf("failed to rename temp file: %w", lockedError("write", path, err))
 	}
 	return nil
 }
@@ -1044,8 +1413,7 @@ func (e *UltraFastEngine) ListDirectoryContent(ctx context.Context, path string)
 	return responseText, nil
 }
 
-// ListDirectoryJSON returns a structured JSON listing of a directory:
-// {"path":..., "total":N, "entries":[{"name","type","size","modified"},...]}
+// ListDirectoryJSON returns a DirectoryListing of a directory as JSON.
 // Cached under a synthetic key so it doesn't collide with the text listing.
 func (e *UltraFastEngine) ListDirectoryJSON(ctx context.Context, path string) (string, error) {
 	path = NormalizePath(path)
@@ -1063,7 +1431,7 @@ func (e *UltraFastEngine) ListDirectoryJSON(ctx context.Context, path string) (s
 	dirInfo, statErr := os.Stat(path)
 
 	// Separate cache key: same directory, different rendering.
-	cacheKey := path + "::json"
+	cacheKey := dirListingJSONKey(path)
 	if cached, cachedMtime, hit := e.cache.GetDirectory(cacheKey); hit {
 		if statErr == nil && !dirInfo.ModTime().After(cachedMtime) {
 			return cached, nil
@@ -1079,19 +1447,7 @@ func (e *UltraFastEngine) ListDirectoryJSON(ctx context.Context, path string) (s
 		return "", fmt.Errorf("failed to read directory: %w", err)
 	}
 
-	type dirEntry struct {
-		Name     string `json:"name"`
-		Type     string `json:"type"`
-		Size     int64  `json:"size,omitempty"`
-		Modified string `json:"modified,omitempty"`
-	}
-
-	out := struct {
-		Path      string     `json:"path"`
-		Total     int        `json:"total"`
-		Truncated bool       `json:"truncated,omitempty"`
-		Entries   []dirEntry `json:"entries"`
-	}{Path: path, Total: len(entries), Entries: make([]dirEntry, 0, len(entries))}
+	out := DirectoryListing{Path: path, Total: len(entries), Entries: make([]DirectoryEntry, 0, len(entries))}
 
 	maxItems := e.config.MaxListItems
 	for i, entry := range entries {
@@ -1099,7 +1455,7 @@ type dirEntry struct {
 			out.Truncated = true
 			break
 		}
-		de := dirEntry{Name: entry.Name(), Type: "file"}
+		de := DirectoryEntry{Name: entry.Name(), Type: "file"}
 		if entry.IsDir() {
 			de.Type = "dir"
 		}
@@ -1126,38 +1482,7 @@ type dirEntry struct {
 
 // GetPerformanceStats returns performance statistics
 func (e *UltraFastEngine) GetPerformanceStats() string {
-	e.metrics.mu.RLock()
-	defer e.metrics.mu.RUnlock()
-
-	if e.config.CompactMode {
-		// Compact format: key metrics only
-		return fmt.Sprintf("ops/s:%.1f hit:%.1f%% mem:%s ops:%d",
-			e.metrics.OperationsPerSecond,
-			e.metrics.CacheHitRate*100,
-			formatSize(e.metrics.MemoryUsage),
-			e.metrics.OperationsTotal)
-	}
-
-	// Verbose format
-	return fmt.Sprintf(`Performance Statistics:
-Operations Total: %d
-Operations/Second: %.2f
-Cache Hit Rate: %.2f%%
-Average Response Time: %v
-Memory Usage: %s
-Read Operations: %d
-Write Operations: %d
-List Operations: %d
-Search Operations: %d`,
-		e.metrics.OperationsTotal,
-		e.metrics.OperationsPerSecond,
-		e.metrics.CacheHitRate*100,
-		e.metrics.AverageResponseTime,
-		formatSize(e.metrics.MemoryUsage),
-		e.metrics.ReadOperations,
-		e.metrics.WriteOperations,
-		e.metrics.ListOperations,
-		e.metrics.SearchOperations)
+	return FormatPerformanceStats(e.PerformanceStats(), e.config.CompactMode)
 }
 
 // AllowedDirsSuffix returns a human-readable suffix listing the effective
@@ -1168,10 +1493,11 @@ func (e *UltraFastEngine) GetPerformanceStats() string {
 // self-diagnosing (issue: Go/Rust variant parity — the error must name the
 // allowed directories so sandbox mismatches are visible without logs).
 func (e *UltraFastEngine) AllowedDirsSuffix() string {
-	if len(e.config.AllowedPaths) == 0 {
+	allowed := e.GetAllowedPaths()
+	if len(allowed) == 0 {
 		return " (rejected by the always-on path security policy; no allowed directories are configured)"
 	}
-	return fmt.Sprintf(" — outside allowed directories: %s", strings.Join(e.config.AllowedPaths, "; "))
+	return fmt.Sprintf(" — outside allowed directories: %s", strings.Join(allowed, "; "))
 }
 
 // AccessDeniedError builds a PathError whose message lists the effective
@@ -1197,17 +1523,14 @@ func (e *UltraFastEngine) IsPathAllowed(path string) bool {
 
 	// 2. When AllowedPaths is not configured, open-access mode — security checks above
 	//    still apply, but containment is not enforced.
-	if len(e.config.AllowedPaths) == 0 {
+	bases, restricted := e.allowedBases()
+	if !restricted {
 		return true
 	}
 
-	// 3. Re-resolve if AllowedPaths changed at runtime (e.g., tests append paths after init)
-	if len(e.resolvedAllowedPaths) != len(e.config.AllowedPaths) {
-		e.resolveAllowedPaths()
-	}
-
-	// Resolve to absolute, cleaned paths to prevent traversal and casing issues
-	targetAbs, err := filepath.Abs(path)
+	// Resolve to absolute, cleaned paths to prevent traversal and casing issues.
+	// The UNC and in-distro forms of a WSL file compare equal.
+	targetAbs, err := filepath.Abs(canonicalWSLForm(path))
 	if err != nil {
 		return false
 	}
@@ -1253,7 +1576,7 @@ func (e *UltraFastEngine) IsPathAllowed(path string) bool {
 
 	targetAbs = norm(targetAbs)
 
-	for _, baseAbs := range e.resolvedAllowedPaths {
+	for _, baseAbs := range bases {
 		// Quick equality check
 		if targetAbs == baseAbs {
 			return true
@@ -1278,11 +1601,12 @@ func (e *UltraFastEngine) IsPathAllowed(path string) bool {
 // configured --allowed-paths roots. Destructive operations (delete, move)
 // must reject these paths to prevent wiping out an entire allowed tree.
 func (e *UltraFastEngine) IsAllowedPathRoot(path string) bool {
-	if len(e.resolvedAllowedPaths) == 0 {
+	bases, _ := e.allowedBases()
+	if len(bases) == 0 {
 		return false
 	}
 
-	targetAbs, err := filepath.Abs(path)
+	targetAbs, err := filepath.Abs(canonicalWSLForm(path))
 	if err != nil {
 		return false
 	}
@@ -1299,7 +1623,7 @@ func (e *UltraFastEngine) IsAllowedPathRoot(path string) bool {
 	}
 	targetAbs = norm(targetAbs)
 
-	for _, baseAbs := range e.resolvedAllowedPaths {
+	for _, baseAbs := range bases {
 		if targetAbs == baseAbs {
 			return true
 		}
@@ -1336,43 +1660,50 @@ func (e *UltraFastEngine) ResolveAndAuthorize(op, path string) (string, error) {
 //   - /mnt/c/Users/... → C:\Users\... (when running on Windows)
 //   - C:\Users\... → /mnt/c/Users/... (when running on WSL/Linux)
 //   - /mnt/d/Projects/... → D:\Projects\...
+//   - \\wsl$\Ubuntu\home\... → /home/... (inside WSL), \\wsl.localhost\Ubuntu\home\... (on Windows)
 func NormalizePath(path string) string {
 	if path == "" {
 		return path
 	}
 
-	// Detect WSL path format: /mnt/<drive>/<rest of path>
-	if strings.HasPrefix(path, "/mnt/") && len(path) > 6 {
-		// Extract drive letter (e.g., /mnt/c/ -> c)
-		parts := strings.SplitN(path[5:], "/", 2)
-		if len(parts) >= 1 && len(parts[0]) == 1 {
-			driveLetter := strings.ToUpper(parts[0])
-
-			// If running on Windows, convert to Windows path
-			if os.PathSeparator == '\\' {
-				remainder := ""
-				if len(parts) > 1 {
-					remainder = parts[1]
-					// Convert forward slashes to backslashes
-					remainder = filepath.FromSlash(remainder)
-				}
-				return driveLetter + ":\\" + remainder
-			}
-			// If running on Linux/WSL, keep as is
-			return filepath.Clean(path)
+	// WSL UNC paths: \\wsl.localhost\<distro>\... and \\wsl$\<distro>\...
+	if distro, inner, ok := ParseWSLUNCPath(path); ok {
+		if os.PathSeparator == '\\' {
+			return wslUNCPath(distro, inner)
+		}
+		// Inside WSL the file is at the in-distro path, unless it belongs to
+		// another distro, which is not reachable from here
+		if current := os.Getenv("WSL_DISTRO_NAME"); current != "" && !strings.EqualFold(current, distro) {
+			return path
+		}
+		return inner
+	}
+
+	// Detect WSL drive path format: /mnt/<drive>/<rest of path>, under the
+	// wsl.conf automount root or a --path-mappings mount point
+	if driveLetter, remainder, ok := splitWSLDrivePath(path); ok {
+		// If running on Windows, convert to Windows path
+		if os.PathSeparator == '\\' {
+			return driveLetter + ":\\" + filepath.FromSlash(remainder)
 		}
+		// If running on Linux/WSL, keep as is
+		return filepath.Clean(path)
 	}
 
 	// Detect Windows absolute path format: C:\... or C:/...
 	if len(path) >= 3 && path[1] == ':' && (path[2] == '\\' || path[2] == '/') {
-		driveLetter := strings.ToLower(string(path[0]))
-
 		// If running on Linux/WSL, convert to WSL path
 		if os.PathSeparator == '/' {
-			remainder := path[3:]
+			mount, err := driveMountChecked(path[0])
+			if err != nil {
+				// An unmapped drive has no WSL path: keep the original so the
+				// operation fails on it instead of on a made-up /mnt/<x>
+				logUnmappedDrive(path, err)
+				return path
+			}
 			// Convert backslashes to forward slashes
-			remainder = filepath.ToSlash(remainder)
-			return "/mnt/" + driveLetter + "/" + remainder
+			remainder := strings.ReplaceAll(path[3:], "\\", "/")
+			return mount + "/" + remainder
 		}
 		// If running on Windows, normalize separators
 		return filepath.Clean(path)
@@ -1420,41 +1751,6 @@ func formatSize(bytes int64) string {
 	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
 }
 
-// CaptureLastArtifact stores the most recent artifact
-func (e *UltraFastEngine) CaptureLastArtifact(ctx context.Context, content string) error {
-	e.artifactMutex.Lock()
-	defer e.artifactMutex.Unlock()
-
-	e.lastArtifact = content
-	return nil
-}
-
-// WriteLastArtifact writes the last captured artifact to specified path
-func (e *UltraFastEngine) WriteLastArtifact(ctx context.Context, path string) error {
-	e.artifactMutex.RLock()
-	content := e.lastArtifact
-	e.artifactMutex.RUnlock()
-
-	if content == "" {
-		return fmt.Errorf("no artifact captured")
-	}
-
-	return e.WriteFileContent(ctx, path, content)
-}
-
-// GetLastArtifactInfo returns info about the last captured artifact
-func (e *UltraFastEngine) GetLastArtifactInfo() string {
-	e.artifactMutex.RLock()
-	defer e.artifactMutex.RUnlock()
-
-	if e.lastArtifact == "" {
-		return "No artifact captured"
-	}
-
-	lines := strings.Count(e.lastArtifact, "\n") + 1
-	return fmt.Sprintf("Last artifact: %d bytes, %d lines", len(e.lastArtifact), lines)
-}
-
 // IsCompactMode returns whether compact mode is enabled
 func (e *UltraFastEngine) IsCompactMode() bool {
 	return e.config.CompactMode
@@ -1465,8 +1761,11 @@ func (e *UltraFastEngine) GetHookManager() *HookManager {
 	return e.hookManager
 }
 
-// GetAllowedPaths returns the configured allowed paths
+// GetAllowedPaths returns the configured allowed paths. The slice is shared;
+// callers must not modify it.
 func (e *UltraFastEngine) GetAllowedPaths() []string {
+	e.allowedMu.RLock()
+	defer e.allowedMu.RUnlock()
 	return e.config.AllowedPaths
 }
 
@@ -1680,7 +1979,8 @@ func (e *UltraFastEngine) GetEditTelemetrySummary() map[string]interface{} {
 	totalEdits := e.metrics.EditOperations
 	if totalEdits == 0 {
 		return map[string]interface{}{
-			"message": "No edit operations recorded yet",
+			"message":        "No edit operations recorded yet",
+			"tracking_since": e.metrics.TrackingSince.Format(time.RFC3339),
 		}
 	}
 
@@ -1695,6 +1995,7 @@ func (e *UltraFastEngine) GetEditTelemetrySummary() map[string]interface{} {
 		"full_rewrite_percent":   fmt.Sprintf("%.1f%%", rewritePercent),
 		"average_bytes_per_edit": fmt.Sprintf("%.0f", e.metrics.AverageBytesPerEdit),
 		"last_operation":         e.metrics.LastEditOperation,
+		"tracking_since":         e.metrics.TrackingSince.Format(time.RFC3339),
 		"recommendation": map[string]string{
 			"if_high_rewrites": "Consider using search_files + read_file + edit_file for surgical edits",
 			"if_low_targeted":  "Good! Edits are efficient",
@@ -1719,6 +2020,22 @@ func (e *UltraFastEngine) SetAutoSyncConfig(config AutoSyncConfig) error {
 	return e.autoSyncManager.UpdateConfig(&config)
 }
 
+// AddAutoSyncMapping adds or replaces an auto-sync root mapping
+func (e *UltraFastEngine) AddAutoSyncMapping(mapping AutoSyncRootMapping) error {
+	if e.autoSyncManager == nil {
+		return fmt.Errorf("auto-sync manager not initialized")
+	}
+	return e.autoSyncManager.AddRootMapping(mapping)
+}
+
+// RemoveAutoSyncMapping removes the auto-sync root mapping for wslRoot
+func (e *UltraFastEngine) RemoveAutoSyncMapping(wslRoot string) error {
+	if e.autoSyncManager == nil {
+		return fmt.Errorf("auto-sync manager not initialized")
+	}
+	return e.autoSyncManager.RemoveRootMapping(wslRoot)
+}
+
 // GetAutoSyncStatus returns the current auto-sync status
 func (e *UltraFastEngine) GetAutoSyncStatus() map[string]interface{} {
 	if e.autoSyncManager == nil {
@@ -1735,7 +2052,8 @@ func (e *UltraFastEngine) GetBackupManager() *BackupManager {
 	return e.backupManager
 }
 
-// InvalidateCache drops any cached file content for the given path.
+// InvalidateCache drops any cached file content for the given path, and the
+// parent directory listing (the write may have created the file).
 // Safe to call when no cache is configured (no-op). Exposed so external
 // callers (e.g., tool handlers) can keep the cache consistent after
 // writing a file outside the engine's standard write APIs.
@@ -1743,8 +2061,7 @@ func (e *UltraFastEngine) InvalidateCache(path string) {
 	if e == nil || path == "" {
 		return
 	}
-	path = NormalizePath(path)
-	e.invalidateFileReadCache(path)
+	e.invalidateMutatedPath(path)
 }
 
 // SecureRandomSuffix exposes the internal helper for callers that need
@@ -103,6 +103,24 @@ func BenchmarkReadFileRange(b *testing.B) {
 	}
 }
 
+// BenchmarkReadFileRange_Indexed reads deep into a file above
+// LargeFileThreshold, which seeks through the cached line index.
+func BenchmarkReadFileRange_Indexed(b *testing.B) {
+	engine, dir := setupBenchEngine(b, 32*1024*1024)
+	path := makeBenchFile(b, dir, "indexed.txt", 64*1024*1024) // 64 MB, ~1.2M lines
+	ctx := context.Background()
+	if _, err := engine.ReadFileRange(ctx, path, 1, 1); err != nil { // builds the index
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := engine.ReadFileRange(ctx, path, 1_100_000, 1_100_100); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 // ─── Write benchmarks ─────────────────────────────────────────────────────────
 
 func BenchmarkWriteFile_Small(b *testing.B) { benchWriteFile(b, 4*1024) }
@@ -179,3 +197,28 @@ func BenchmarkParallelReads(b *testing.B) {
 		}
 	})
 }
+
+// BenchmarkSearchAndReplace_Directory replaces back and forth across 400
+// files, which the worker pool processes ParallelOps at a time
+func BenchmarkSearchAndReplace_Directory(b *testing.B) {
+	engine, dir := setupBenchEngine(b, 16*1024*1024)
+	for i := 0; i < 400; i++ {
+		sub := filepath.Join(dir, fmt.Sprintf("d%02d", i/50))
+		os.MkdirAll(sub, 0755)
+		path := makeBenchFile(b, sub, fmt.Sprintf("f%03d.txt", i), 16*1024)
+		f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+		f.WriteString("needle_a\n")
+		f.Close()
+	}
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		from, to := "needle_a", "needle_b"
+		if i%2 == 1 {
+			from, to = to, from
+		}
+		if _, err := engine.SearchAndReplace(ctx, dir, from, to, true, false); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
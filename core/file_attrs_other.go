@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package core
+
+import "os"
+
+// fileOwnership is not implemented on this platform
+func fileOwnership(info os.FileInfo, r *FileInfoResponse) {}
+
+// fileAttributes is not implemented on this platform
+func fileAttributes(info os.FileInfo) []string {
+	return nil
+}
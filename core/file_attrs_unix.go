@@ -0,0 +1,34 @@
+//go:build linux || darwin || freebsd
+
+package core
+
+import (
+	"os"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// fileOwnership fills the owner and group of info, by name when the
+// account database knows the IDs and as numeric IDs otherwise
+func fileOwnership(info os.FileInfo, r *FileInfoResponse) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return
+	}
+	uid := strconv.FormatUint(uint64(st.Uid), 10)
+	gid := strconv.FormatUint(uint64(st.Gid), 10)
+	r.Owner, r.Group = uid, gid
+	if u, err := user.LookupId(uid); err == nil {
+		r.Owner = u.Username
+	}
+	if g, err := user.LookupGroupId(gid); err == nil {
+		r.Group = g.Name
+	}
+}
+
+// fileAttributes reports platform file attributes; Unix has none beyond the
+// mode bits
+func fileAttributes(info os.FileInfo) []string {
+	return nil
+}
@@ -0,0 +1,33 @@
+//go:build windows
+
+package core
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileOwnership is not reported on Windows: owners are SIDs behind ACLs
+func fileOwnership(info os.FileInfo, r *FileInfoResponse) {}
+
+// fileAttributes lists the hidden, readonly and system attributes of info
+func fileAttributes(info os.FileInfo) []string {
+	data, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	if !ok {
+		return nil
+	}
+	var attrs []string
+	for _, a := range []struct {
+		flag uint32
+		name string
+	}{
+		{syscall.FILE_ATTRIBUTE_HIDDEN, "hidden"},
+		{syscall.FILE_ATTRIBUTE_READONLY, "readonly"},
+		{syscall.FILE_ATTRIBUTE_SYSTEM, "system"},
+	} {
+		if data.FileAttributes&a.flag != 0 {
+			attrs = append(attrs, a.name)
+		}
+	}
+	return attrs
+}
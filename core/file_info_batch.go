@@ -0,0 +1,96 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// FilesInfo is get_file_info for several paths, in the order given. The
+// stats run on the worker pool; a path that is missing or denied gets an
+// entry with Exists false and Error set instead of failing the batch.
+// opts adds the SHA-256 and line count of regular files.
+func (e *UltraFastEngine) FilesInfo(ctx context.Context, paths []string, opts FileInfoOptions) *FileInfoBatchResponse {
+	batch := &FileInfoBatchResponse{Files: make([]FileInfoResponse, len(paths)), Count: len(paths)}
+	if opts.Git {
+		opts.gitMemo = &gitStatusMemo{}
+	}
+	var wg sync.WaitGroup
+	for i, p := range paths {
+		i, p := i, NormalizePath(p)
+		task := func() {
+			defer wg.Done()
+			info, err := e.FileInfoWith(ctx, p, opts)
+			if err != nil {
+				batch.Files[i] = FileInfoResponse{Path: p, Error: err.Error()}
+				return
+			}
+			batch.Files[i] = info
+		}
+		wg.Add(1)
+		if e.workerPool == nil || e.workerPool.Submit(task) != nil {
+			task()
+		}
+	}
+	wg.Wait()
+	for _, f := range batch.Files {
+		if f.Exists {
+			batch.Found++
+		}
+	}
+	return batch
+}
+
+// FileInfoBatchJSON encodes b within maxBytes (<= 0 = no limit), dropping
+// trailing entries, counted in Omitted, when the whole batch does not fit
+func FileInfoBatchJSON(b *FileInfoBatchResponse, maxBytes int) string {
+	data, _ := json.MarshalIndent(b, "", "  ")
+	if maxBytes <= 0 || len(data) <= maxBytes {
+		return string(data)
+	}
+	out := *b
+	// Entry sizes as they appear in the indented array, plus room for the
+	// envelope and the omitted count
+	budget := maxBytes - 256
+	kept := 0
+	for _, f := range b.Files {
+		entry, _ := json.MarshalIndent(f, "    ", "  ")
+		if budget -= len(entry) + 6; budget < 0 {
+			break
+		}
+		kept++
+	}
+	out.Files = b.Files[:kept]
+	out.Omitted = len(b.Files) - kept
+	data, _ = json.MarshalIndent(out, "", "  ")
+	return string(data)
+}
+
+// FormatFileInfoBatch renders b as get_file_info text blocks within maxBytes
+// (<= 0 = no limit); failed paths show their error inline
+func FormatFileInfoBatch(b *FileInfoBatchResponse, compact bool, maxBytes int) string {
+	var sb strings.Builder
+	for i, f := range b.Files {
+		var block string
+		if !f.Exists {
+			block = fmt.Sprintf("=== %s ===\nERROR: %s\n", f.Path, f.Error)
+		} else {
+			block = FormatFileInfo(f, compact)
+			if f.Error != "" {
+				block += fmt.Sprintf("⚠️  %s\n", f.Error)
+			}
+		}
+		if i > 0 {
+			block = "\n" + block
+		}
+		if maxBytes > 0 && sb.Len()+len(block) > maxBytes-128 {
+			sb.WriteString(fmt.Sprintf("\n… %d more paths omitted (response size limit); pass fewer paths\n", len(b.Files)-i))
+			break
+		}
+		sb.WriteString(block)
+	}
+	sb.WriteString(fmt.Sprintf("\n%d of %d paths found\n", b.Found, b.Count))
+	return sb.String()
+}
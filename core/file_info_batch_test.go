@@ -0,0 +1,67 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFilesInfo_InlineErrorsAndHash(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("hello"), 0644)
+	os.MkdirAll(filepath.Join(dir, "sub"), 0755)
+	outside := t.TempDir()
+
+	paths := []string{filepath.Join(dir, "a.txt"), filepath.Join(dir, "missing.txt"), filepath.Join(dir, "sub"), outside}
+	batch := engine.FilesInfo(context.Background(), paths, FileInfoOptions{Hash: true})
+	if batch.Count != 4 || batch.Found != 2 {
+		t.Fatalf("batch = %+v", batch)
+	}
+	a, missing, sub, denied := batch.Files[0], batch.Files[1], batch.Files[2], batch.Files[3]
+	if !a.Exists || a.Size != 5 || a.SHA256 != "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" {
+		t.Errorf("a.txt = %+v", a)
+	}
+	if missing.Exists || !strings.Contains(missing.Error, "does not exist") {
+		t.Errorf("missing = %+v", missing)
+	}
+	if !sub.Exists || sub.Type != "dir" || sub.SHA256 != "" {
+		t.Errorf("sub = %+v", sub)
+	}
+	if denied.Exists || !strings.Contains(denied.Error, "access denied") {
+		t.Errorf("outside = %+v", denied)
+	}
+	if text := FormatFileInfoBatch(batch, false, 0); !strings.Contains(text, "ERROR: file or directory does not exist") || !strings.Contains(text, "2 of 4 paths found") {
+		t.Errorf("text:\n%s", text)
+	}
+}
+
+func TestFileInfoBatch_StaysUnderLimit(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	var paths []string
+	for i := 0; i < 200; i++ {
+		p := filepath.Join(dir, fmt.Sprintf("file-%03d.txt", i))
+		os.WriteFile(p, []byte("x"), 0644)
+		paths = append(paths, p)
+	}
+	batch := engine.FilesInfo(context.Background(), paths, FileInfoOptions{})
+	if batch.Found != 200 || batch.Files[199].Name != "file-199.txt" {
+		t.Fatalf("batch found %d, last %+v", batch.Found, batch.Files[199])
+	}
+
+	data := FileInfoBatchJSON(batch, 8*1024)
+	var got FileInfoBatchResponse
+	if err := json.Unmarshal([]byte(data), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(data) > 8*1024 || got.Omitted == 0 || len(got.Files)+got.Omitted != 200 || got.Count != 200 {
+		t.Errorf("json: %d bytes, %d files, %d omitted", len(data), len(got.Files), got.Omitted)
+	}
+	text := FormatFileInfoBatch(batch, true, 4*1024)
+	if len(text) > 4*1024 || !strings.Contains(text, "more paths omitted") {
+		t.Errorf("text: %d bytes\n%s", len(text), text)
+	}
+}
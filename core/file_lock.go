@@ -0,0 +1,78 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"runtime"
+	"strings"
+	"syscall"
+)
+
+// FileLockedError is a write, rename, move or delete that failed because
+// another process holds the file open: Visual Studio, an indexer or an
+// antivirus scan on Windows. Holders names the processes when the Restart
+// Manager could tell.
+type FileLockedError struct {
+	Op      string   // Operation (e.g., "write", "edit", "move", "delete")
+	Path    string   // The locked file
+	Holders []string // "devenv.exe (pid 4242)"; empty when unknown
+	Err     error    // The underlying sharing or lock violation
+}
+
+func (e *FileLockedError) Error() string {
+	msg := fmt.Sprintf("%s %s: file is locked by another process", e.Op, e.Path)
+	if len(e.Holders) > 0 {
+		msg += " (" + strings.Join(e.Holders, ", ") + ")"
+	}
+	return msg + "; close it there or retry"
+}
+
+func (e *FileLockedError) Unwrap() error {
+	return e.Err
+}
+
+// IsFileLocked reports whether err is a sharing or lock violation
+func IsFileLocked(err error) bool {
+	if err == nil {
+		return false
+	}
+	var locked *FileLockedError
+	if errors.As(err, &locked) {
+		return true
+	}
+	var errno syscall.Errno
+	if runtime.GOOS == "windows" && errors.As(err, &errno) {
+		for _, e := range windowsTransientErrnos {
+			if errno == e {
+				return true
+			}
+		}
+	}
+	msg := strings.ToLower(err.Error())
+	for _, m := range []string{"being used by another process", "sharing violation", "lock violation", "locked a portion of the file"} {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// lockedError returns err as a *FileLockedError when it is a sharing or
+// lock violation, and unchanged otherwise. Windows also reports a rename over
+// a file held open as plain access denied; that case counts as locked only
+// when the Restart Manager names a holder.
+func lockedError(op, path string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if IsFileLocked(err) {
+		return &FileLockedError{Op: op, Path: path, Holders: lockHolders(path), Err: err}
+	}
+	if runtime.GOOS == "windows" && errors.Is(err, fs.ErrPermission) {
+		if holders := lockHolders(path); len(holders) > 0 {
+			return &FileLockedError{Op: op, Path: path, Holders: holders, Err: err}
+		}
+	}
+	return err
+}
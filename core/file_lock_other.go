@@ -0,0 +1,8 @@
+//go:build !windows
+
+package core
+
+// lockHolders is Windows-only: elsewhere a lock never blocks a rename
+func lockHolders(path string) []string {
+	return nil
+}
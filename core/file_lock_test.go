@@ -0,0 +1,56 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestLockedError(t *testing.T) {
+	// The message form is what a formatted error carries on every platform
+	sharing := &os.LinkError{Op: "rename", Old: "a.tmp", New: "a.cs",
+		Err: errors.New("The process cannot access the file because it is being used by another process.")}
+	err := fmt.Errorf("error finalizing edit: %w", lockedError("edit", "a.cs", sharing))
+
+	var locked *FileLockedError
+	if !errors.As(err, &locked) || locked.Op != "edit" || locked.Path != "a.cs" {
+		t.Fatalf("sharing violation not reported as locked: %v", err)
+	}
+	if !strings.Contains(err.Error(), "edit a.cs: file is locked by another process; close it there or retry") {
+		t.Errorf("message = %s", err)
+	}
+	if !errors.Is(err, sharing.Err) || !IsFileLocked(err) || !IsTransientError(err) {
+		t.Error("locked error lost its cause or is not retryable")
+	}
+
+	locked.Holders = []string{"devenv.exe (pid 4242)"}
+	if !strings.Contains(locked.Error(), "locked by another process (devenv.exe (pid 4242))") {
+		t.Errorf("holders missing: %s", locked.Error())
+	}
+
+	if runtime.GOOS == "windows" {
+		if !IsFileLocked(&os.PathError{Op: "open", Path: "a.cs", Err: syscall.Errno(32)}) {
+			t.Error("ERROR_SHARING_VIOLATION not detected")
+		}
+	}
+
+	// Other failures pass through untouched
+	for _, plain := range []error{fs.ErrNotExist, errors.New("disk full")} {
+		if got := lockedError("write", "a.cs", plain); got != plain {
+			t.Errorf("lockedError(%v) = %v", plain, got)
+		}
+	}
+	if runtime.GOOS != "windows" {
+		if got := lockedError("write", "a.cs", fs.ErrPermission); got != fs.ErrPermission {
+			t.Errorf("access denied became %v", got)
+		}
+	}
+	if lockedError("write", "a.cs", nil) != nil {
+		t.Error("nil error wrapped")
+	}
+}
@@ -0,0 +1,84 @@
+//go:build windows
+
+package core
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	rstrtmgr                = syscall.NewLazyDLL("rstrtmgr.dll")
+	procRmStartSession      = rstrtmgr.NewProc("RmStartSession")
+	procRmRegisterResources = rstrtmgr.NewProc("RmRegisterResources")
+	procRmGetList           = rstrtmgr.NewProc("RmGetList")
+	procRmEndSession        = rstrtmgr.NewProc("RmEndSession")
+)
+
+const (
+	rmSessionKeyLen  = 32 // CCH_RM_SESSION_KEY
+	rmErrorMoreData  = 234
+	rmAppNameLen     = 256 // CCH_RM_MAX_APP_NAME
+	rmServiceNameLen = 64  // CCH_RM_MAX_SVC_NAME
+)
+
+// rmProcessInfo is RM_PROCESS_INFO
+type rmProcessInfo struct {
+	ProcessID        uint32
+	ProcessStartTime syscall.Filetime
+	AppName          [rmAppNameLen]uint16
+	ServiceShortName [rmServiceNameLen]uint16
+	ApplicationType  uint32
+	AppStatus        uint32
+	TSSessionID      uint32
+	Restartable      int32
+}
+
+// lockHolders asks the Restart Manager which processes have path open, as
+// "name (pid N)". Any failure yields nil: the hint is best-effort.
+func lockHolders(path string) []string {
+	if rstrtmgr.Load() != nil {
+		return nil
+	}
+	p, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return nil
+	}
+	var session uint32
+	var key [rmSessionKeyLen + 1]uint16
+	if r, _, _ := procRmStartSession.Call(uintptr(unsafe.Pointer(&session)), 0, uintptr(unsafe.Pointer(&key[0]))); r != 0 {
+		return nil
+	}
+	defer procRmEndSession.Call(uintptr(session))
+
+	files := []*uint16{p}
+	if r, _, _ := procRmRegisterResources.Call(uintptr(session), 1, uintptr(unsafe.Pointer(&files[0])), 0, 0, 0, 0); r != 0 {
+		return nil
+	}
+	// The first call sizes the list; holders can come and go in between,
+	// so retry a couple of times on ERROR_MORE_DATA
+	var needed, reason, count uint32
+	infos := make([]rmProcessInfo, 4)
+	for attempt := 0; ; attempt++ {
+		count = uint32(len(infos))
+		r, _, _ := procRmGetList.Call(uintptr(session), uintptr(unsafe.Pointer(&needed)), uintptr(unsafe.Pointer(&count)),
+			uintptr(unsafe.Pointer(&infos[0])), uintptr(unsafe.Pointer(&reason)))
+		if r == 0 {
+			break
+		}
+		if r != rmErrorMoreData || attempt == 2 {
+			return nil
+		}
+		infos = make([]rmProcessInfo, needed+2)
+	}
+	var holders []string
+	for _, info := range infos[:count] {
+		name := syscall.UTF16ToString(info.AppName[:])
+		if name == "" {
+			name = syscall.UTF16ToString(info.ServiceShortName[:])
+		}
+		holders = append(holders, fmt.Sprintf("%s (pid %d)", name, info.ProcessID))
+	}
+	return holders
+}
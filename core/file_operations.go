@@ -1,7 +1,6 @@
 package core
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	"io"
@@ -32,6 +31,12 @@ func (e *UltraFastEngine) RenameFile(ctx context.Context, oldPath, newPath strin
 	if !e.IsPathAllowed(newPath) {
 		return fmt.Errorf("access denied: destination path '%s' is not in allowed paths%s", newPath, e.AllowedDirsSuffix())
 	}
+	if err := e.CheckRemovable("rename", oldPath); err != nil {
+		return err
+	}
+	if err := e.CheckWritable("rename", newPath); err != nil {
+		return err
+	}
 
 	// Check if source exists
 	if _, err := os.Stat(oldPath); os.IsNotExist(err) {
@@ -43,6 +48,22 @@ func (e *UltraFastEngine) RenameFile(ctx context.Context, oldPath, newPath strin
 		return fmt.Errorf("destination file already exists: %s", newPath)
 	}
 
+	// Execute pre-move hook (a rename is a move within the tree)
+	workingDir, _ := os.Getwd()
+	hookCtx := &HookContext{
+		Event:      HookPreMove,
+		ToolName:   "rename_file",
+		FilePath:   oldPath,
+		Operation:  "rename",
+		SourcePath: oldPath,
+		DestPath:   newPath,
+		Timestamp:  time.Now(),
+		WorkingDir: workingDir,
+	}
+	if _, err := e.hookManager.ExecuteHooks(ctx, HookPreMove, hookCtx); err != nil {
+		return fmt.Errorf("pre-move hook denied operation: %w", err)
+	}
+
 	// Ensure destination directory exists
 	destDir := filepath.Dir(newPath)
 	if err := os.MkdirAll(destDir, 0755); err != nil {
@@ -62,7 +83,7 @@ func (e *UltraFastEngine) RenameFile(ctx context.Context, oldPath, newPath strin
 
 	// Perform the rename
 	if err := os.Rename(oldPath, newPath); err != nil {
-		return fmt.Errorf("failed to rename file: %w", err)
+		return fmt.Errorf("failed to rename file: %w", lockedError("move", oldPath, err))
 	}
 
 	// Invalidate cache entries for both paths
@@ -70,8 +91,19 @@ func (e *UltraFastEngine) RenameFile(ctx context.Context, oldPath, newPath strin
 	e.invalidateFileReadCache(newPath)
 
 	// Also invalidate parent directories
-	e.cache.InvalidateDirectory(filepath.Dir(oldPath))
-	e.cache.InvalidateDirectory(filepath.Dir(newPath))
+	e.invalidateDirListing(filepath.Dir(oldPath))
+	e.invalidateDirListing(filepath.Dir(newPath))
+
+	// Execute post-move hook (best-effort)
+	hookCtx.Event = HookPostMove
+	_, _ = e.hookManager.ExecuteHooks(ctx, HookPostMove, hookCtx)
+
+	// Auto-sync: drop the Windows copy of the removed path
+	if e.autoSyncManager != nil {
+		_ = e.autoSyncManager.AfterDelete(oldPath)
+	}
+
+	e.recordOperation(ctx, OperationRecord{Operation: "rename", Path: oldPath, Dest: newPath})
 
 	return nil
 }
@@ -80,11 +112,14 @@ func (e *UltraFastEngine) RenameFile(ctx context.Context, oldPath, newPath strin
 // caller (tool handler) can format a response with the SD-ID, dest path, and a
 // restore command.
 //
-// When --backup-dir is configured AND a BackupManager is available, the file
-// goes to <BackupDir>/filesdelete/<sd-id>/<basename> with a metadata.json
-// sidecar (the discoverable path; AI can restore via backup(action:"restore_trash")).
+// When --backup-dir or --trash-dir is configured AND a BackupManager is
+// available, the file or directory goes to <trash root>/<timestamp>-<shortid>/<rel>,
+// <rel> being its path below the nearest allowed root, with a metadata.json
+// sidecar (the discoverable path; AI can restore via
+// backup(action:"restore_trash")). The trash root is picked on the file's
+// volume; see trashLocation.
 //
-// When --backup-dir is NOT configured, the legacy walk-up behavior is used
+// When neither is configured, the legacy walk-up behavior is used
 // (with a deprecation warning) so users who don't pass --backup-dir are not
 // broken. Legacy entries are NOT discoverable via backup(action:"list_trash").
 func (e *UltraFastEngine) SoftDeleteFile(ctx context.Context, path string) (*SoftDeleteInfo, error) {
@@ -103,9 +138,12 @@ func (e *UltraFastEngine) SoftDeleteFile(ctx context.Context, path string) (*Sof
 		return nil, fmt.Errorf("access denied: path '%s' is not in allowed paths%s", path, e.AllowedDirsSuffix())
 	}
 	// Prevent soft-deletion of allowed-path roots (would move entire tree to trash)
-	if len(e.config.AllowedPaths) > 0 && e.IsAllowedPathRoot(path) {
+	if len(e.GetAllowedPaths()) > 0 && e.IsAllowedPathRoot(path) {
 		return nil, fmt.Errorf("access denied: cannot delete allowed-path root '%s'%s", path, e.AllowedDirsSuffix())
 	}
+	if err := e.CheckRemovable("soft_delete", path); err != nil {
+		return nil, err
+	}
 
 	// Check if source exists
 	if _, err := os.Stat(path); os.IsNotExist(err) {
@@ -128,9 +166,10 @@ func (e *UltraFastEngine) SoftDeleteFile(ctx context.Context, path string) (*Sof
 
 	var info *SoftDeleteInfo
 	var err error
-	if e.config.BackupDir != "" && e.backupManager != nil {
+	if e.trashEnabled() {
 		// Preferred path: delegate to BackupManager for the discoverable trash layout.
-		info, err = e.backupManager.SoftDeleteFile(path)
+		root, rel := e.trashLocation(path)
+		info, err = e.backupManager.SoftDeleteFile(path, rel, root, AuditTool(ctx))
 	} else {
 		// Legacy fallback: walk-up heuristic. Kept so users without --backup-dir
 		// are not broken by the upgrade. Emits a deprecation warning.
@@ -142,7 +181,8 @@ func (e *UltraFastEngine) SoftDeleteFile(ctx context.Context, path string) (*Sof
 
 	// Invalidate cache entries (the source path is gone now)
 	e.invalidateFileReadCache(path)
-	e.cache.InvalidateDirectory(filepath.Dir(path))
+	e.invalidateDirListing(path)
+	e.invalidateDirListing(filepath.Dir(path))
 
 	// Execute post-delete hook (best-effort) — include sd_id and dest_path in
 	// metadata so custom hooks can match on them.
@@ -153,6 +193,13 @@ func (e *UltraFastEngine) SoftDeleteFile(ctx context.Context, path string) (*Sof
 	}
 	_, _ = e.hookManager.ExecuteHooks(ctx, HookPostDelete, hookCtx)
 
+	// Auto-sync: drop the Windows copy of the removed path
+	if e.autoSyncManager != nil {
+		_ = e.autoSyncManager.AfterDelete(path)
+	}
+
+	e.recordOperation(ctx, OperationRecord{Operation: "soft_delete", Path: path, Dest: info.DestPath})
+
 	return info, nil
 }
 
@@ -171,8 +218,8 @@ func (e *UltraFastEngine) softDeleteLegacy(ctx context.Context, path string) (*S
 
 	// Determine the root directory (where to create filesdelete folder)
 	var rootDir string
-	if len(e.config.AllowedPaths) > 0 {
-		rootDir = e.config.AllowedPaths[0]
+	if allowed := e.GetAllowedPaths(); len(allowed) > 0 {
+		rootDir = allowed[0]
 	} else {
 		// Find a reasonable root directory - go up until we find a directory that
 		// looks like a project root. Note: this is known to misbehave for
@@ -239,7 +286,7 @@ func (e *UltraFastEngine) softDeleteLegacy(ctx context.Context, path string) (*S
 
 	// Move the file
 	if err := os.Rename(path, destPath); err != nil {
-		return nil, fmt.Errorf("failed to move file to filesdelete: %w", err)
+		return nil, fmt.Errorf("failed to move file to filesdelete: %w", lockedError("delete", path, err))
 	}
 
 	// Synthesize a SoftDeleteInfo. SDID is empty — legacy entries are not
@@ -288,6 +335,9 @@ func (e *UltraFastEngine) CreateDirectory(ctx context.Context, path string) erro
 	if !e.IsPathAllowed(path) {
 		return fmt.Errorf("access denied: path '%s' is not in allowed paths%s", path, e.AllowedDirsSuffix())
 	}
+	if err := e.CheckWritable("create_directory", path); err != nil {
+		return err
+	}
 
 	// Check if directory already exists
 	if info, err := os.Stat(path); err == nil {
@@ -316,13 +366,14 @@ func (e *UltraFastEngine) CreateDirectory(ctx context.Context, path string) erro
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
 
-	// Invalidate parent directory cache
-	e.cache.InvalidateDirectory(filepath.Dir(path))
+	// Invalidate parent directory cache (and any cached "not found")
+	e.invalidateMutatedPath(path)
 
 	// Execute post-create hook (best-effort)
 	hookCtx.Event = HookPostCreate
 	_, _ = e.hookManager.ExecuteHooks(ctx, HookPostCreate, hookCtx)
 
+	MarkMutating(ctx)
 	return nil
 }
 
@@ -343,9 +394,12 @@ func (e *UltraFastEngine) DeleteFile(ctx context.Context, path string) error {
 		return fmt.Errorf("access denied: path '%s' is not in allowed paths%s", path, e.AllowedDirsSuffix())
 	}
 	// Prevent deletion of allowed-path roots (would wipe entire tree via os.RemoveAll)
-	if len(e.config.AllowedPaths) > 0 && e.IsAllowedPathRoot(path) {
+	if len(e.GetAllowedPaths()) > 0 && e.IsAllowedPathRoot(path) {
 		return fmt.Errorf("access denied: cannot delete allowed-path root '%s'%s", path, e.AllowedDirsSuffix())
 	}
+	if err := e.CheckRemovable("delete", path); err != nil {
+		return err
+	}
 
 	// Check if file/directory exists
 	info, err := os.Stat(path)
@@ -379,29 +433,44 @@ func (e *UltraFastEngine) DeleteFile(ctx context.Context, path string) error {
 	}
 
 	if err != nil {
-		return fmt.Errorf("failed to delete: %w", err)
+		return fmt.Errorf("failed to delete: %w", lockedError("delete", path, err))
 	}
 
 	// Invalidate cache entries
 	e.invalidateFileReadCache(path)
-	e.cache.InvalidateDirectory(path)
-	e.cache.InvalidateDirectory(filepath.Dir(path))
+	e.invalidateDirListing(path)
+	e.invalidateDirListing(filepath.Dir(path))
 
 	// Execute post-delete hook (best-effort)
 	hookCtx.Event = HookPostDelete
 	_, _ = e.hookManager.ExecuteHooks(ctx, HookPostDelete, hookCtx)
 
+	// Auto-sync: drop the Windows copy of the removed path
+	if e.autoSyncManager != nil {
+		_ = e.autoSyncManager.AfterDelete(path)
+	}
+
+	e.recordOperation(ctx, OperationRecord{Operation: "delete", Path: path})
+
 	return nil
 }
 
 // MoveFile moves a file or directory to a new location
 func (e *UltraFastEngine) MoveFile(ctx context.Context, sourcePath, destPath string) error {
+	_, err := e.MovePath(ctx, sourcePath, destPath)
+	return err
+}
+
+// MovePath moves a file or directory to destPath, which must not exist.
+// When the rename crosses devices the move falls back to copy, full hash
+// verification and removal of the source (see moveAcrossDevices).
+func (e *UltraFastEngine) MovePath(ctx context.Context, sourcePath, destPath string) (*MoveResult, error) {
 	// Normalize path (handles WSL ↔ Windows conversion)
 	sourcePath = NormalizePath(sourcePath)
 	destPath = NormalizePath(destPath)
 	// Acquire semaphore
 	if err := e.acquireOperation(ctx, "move"); err != nil {
-		return err
+		return nil, err
 	}
 
 	start := time.Now()
@@ -409,40 +478,37 @@ func (e *UltraFastEngine) MoveFile(ctx context.Context, sourcePath, destPath str
 
 	// Check if both paths are allowed (security + access control)
 	if !e.IsPathAllowed(sourcePath) {
-		return fmt.Errorf("access denied: source path '%s' is not in allowed paths%s", sourcePath, e.AllowedDirsSuffix())
+		return nil, fmt.Errorf("access denied: source path '%s' is not in allowed paths%s", sourcePath, e.AllowedDirsSuffix())
 	}
 	if !e.IsPathAllowed(destPath) {
-		return fmt.Errorf("access denied: destination path '%s' is not in allowed paths%s", destPath, e.AllowedDirsSuffix())
+		return nil, fmt.Errorf("access denied: destination path '%s' is not in allowed paths%s", destPath, e.AllowedDirsSuffix())
 	}
 	// Prevent moving an allowed-path root (would remove the entire tree from its location)
-	if len(e.config.AllowedPaths) > 0 && e.IsAllowedPathRoot(sourcePath) {
-		return fmt.Errorf("access denied: cannot move allowed-path root '%s'%s", sourcePath, e.AllowedDirsSuffix())
+	if len(e.GetAllowedPaths()) > 0 && e.IsAllowedPathRoot(sourcePath) {
+		return nil, fmt.Errorf("access denied: cannot move allowed-path root '%s'%s", sourcePath, e.AllowedDirsSuffix())
+	}
+	if err := e.CheckRemovable("move", sourcePath); err != nil {
+		return nil, err
+	}
+	if err := e.CheckWritable("move", destPath); err != nil {
+		return nil, err
 	}
 
 	// Check if source exists
 	sourceInfo, err := os.Stat(sourcePath)
 	if os.IsNotExist(err) {
-		return fmt.Errorf("source does not exist: %s", sourcePath)
+		return nil, fmt.Errorf("source does not exist: %s", sourcePath)
 	}
 	if err != nil {
-		return fmt.Errorf("failed to stat source: %w", err)
+		return nil, fmt.Errorf("failed to stat source: %w", err)
 	}
 
 	// Check if destination already exists
 	if _, err := os.Stat(destPath); err == nil {
-		return fmt.Errorf("destination already exists: %s", destPath)
+		return nil, fmt.Errorf("destination already exists: %s", destPath)
 	}
 
-	// Ensure destination directory exists
-	destDir := filepath.Dir(destPath)
-	if !sourceInfo.IsDir() {
-		// For files, create parent directory
-		if err := os.MkdirAll(destDir, 0755); err != nil {
-			return fmt.Errorf("failed to create destination directory: %w", err)
-		}
-	}
-
-	// Execute pre-move hook
+	// Execute pre-move hook (before any filesystem change)
 	workingDir, _ := os.Getwd()
 	hookCtx := &HookContext{
 		Event:      HookPreMove,
@@ -455,7 +521,16 @@ func (e *UltraFastEngine) MoveFile(ctx context.Context, sourcePath, destPath str
 		WorkingDir: workingDir,
 	}
 	if _, err := e.hookManager.ExecuteHooks(ctx, HookPreMove, hookCtx); err != nil {
-		return fmt.Errorf("pre-move hook denied operation: %w", err)
+		return nil, fmt.Errorf("pre-move hook denied operation: %w", err)
+	}
+
+	// Ensure destination directory exists
+	destDir := filepath.Dir(destPath)
+	if !sourceInfo.IsDir() {
+		// For files, create parent directory
+		if err := os.MkdirAll(destDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create destination directory: %w", err)
+		}
 	}
 
 	// TOCTOU defense: re-resolve symlinks immediately before move.
@@ -463,40 +538,64 @@ func (e *UltraFastEngine) MoveFile(ctx context.Context, sourcePath, destPath str
 	// the earlier IsPathAllowed check and now.
 	sourceResolved, wasSymlink, err := ResolveSymlinks(sourcePath)
 	if err != nil {
-		return fmt.Errorf("failed to resolve source path: %w", err)
+		return nil, fmt.Errorf("failed to resolve source path: %w", err)
 	}
 	if wasSymlink {
-		return fmt.Errorf("security: source path resolved to symlink %q", sourceResolved)
+		return nil, fmt.Errorf("security: source path resolved to symlink %q", sourceResolved)
 	}
 
 	// Perform the move
-	if err := os.Rename(sourcePath, destPath); err != nil {
-		return fmt.Errorf("failed to move: %w", err)
+	result := &MoveResult{Source: sourcePath, Dest: destPath, Dir: sourceInfo.IsDir()}
+	if err := renamePath(sourcePath, destPath); err != nil {
+		if !isCrossDeviceError(err) {
+			return nil, fmt.Errorf("failed to move: %w", lockedError("move", sourcePath, err))
+		}
+		result.CrossDevice = true
+		if result.Copy, err = e.moveAcrossDevices(ctx, sourcePath, destPath, sourceInfo); err != nil {
+			e.invalidateMutatedPath(destPath)
+			e.invalidateDirListing(filepath.Dir(destPath))
+			return nil, err
+		}
 	}
 
 	// Invalidate cache entries
 	e.invalidateFileReadCache(sourcePath)
 	e.invalidateFileReadCache(destPath)
-	e.cache.InvalidateDirectory(sourcePath)
-	e.cache.InvalidateDirectory(destPath)
-	e.cache.InvalidateDirectory(filepath.Dir(sourcePath))
-	e.cache.InvalidateDirectory(filepath.Dir(destPath))
+	e.invalidateDirListing(sourcePath)
+	e.invalidateDirListing(destPath)
+	e.invalidateDirListing(filepath.Dir(sourcePath))
+	e.invalidateDirListing(filepath.Dir(destPath))
 
 	// Execute post-move hook (best-effort)
 	hookCtx.Event = HookPostMove
 	_, _ = e.hookManager.ExecuteHooks(ctx, HookPostMove, hookCtx)
 
-	return nil
+	// Auto-sync: drop the Windows copy of the removed path
+	if e.autoSyncManager != nil {
+		_ = e.autoSyncManager.AfterDelete(sourcePath)
+	}
+
+	e.recordOperation(ctx, OperationRecord{Operation: "move", Path: sourcePath, Dest: destPath})
+
+	return result, nil
 }
 
 // CopyFile copies a file or directory to a new location
 func (e *UltraFastEngine) CopyFile(ctx context.Context, sourcePath, destPath string) error {
+	_, err := e.CopyPath(ctx, sourcePath, destPath, CopyOptions{})
+	return err
+}
+
+// CopyPath copies a file or directory tree to destPath, which must not
+// exist, and reports what was copied. See CopyOptions for excludes,
+// verification and progress.
+func (e *UltraFastEngine) CopyPath(ctx context.Context, sourcePath, destPath string, opts CopyOptions) (*CopyResult, error) {
 	// Normalize path (handles WSL ↔ Windows conversion)
 	sourcePath = NormalizePath(sourcePath)
 	destPath = NormalizePath(destPath)
 	// Acquire semaphore
 	if err := e.acquireOperation(ctx, "copy"); err != nil {
-		return err
+		return nil, err
 	}
 
 	start := time.Now()
@@ -504,24 +603,27 @@ func (e *UltraFastEngine) CopyFile(ctx context.Context, sourcePath, destPath str
 
 	// Check if both paths are allowed (security + access control)
 	if !e.IsPathAllowed(sourcePath) {
-		return fmt.Errorf("access denied: source path '%s' is not in allowed paths%s", sourcePath, e.AllowedDirsSuffix())
+		return nil, fmt.Errorf("access denied: source path '%s' is not in allowed paths%s", sourcePath, e.AllowedDirsSuffix())
 	}
 	if !e.IsPathAllowed(destPath) {
-		return fmt.Errorf("access denied: destination path '%s' is not in allowed paths%s", destPath, e.AllowedDirsSuffix())
+		return nil, fmt.Errorf("access denied: destination path '%s' is not in allowed paths%s", destPath, e.AllowedDirsSuffix())
+	}
+	if err := e.CheckWritable("copy", destPath); err != nil {
+		return nil, err
 	}
 
 	// Check if source exists
 	sourceInfo, err := os.Stat(sourcePath)
 	if os.IsNotExist(err) {
-		return fmt.Errorf("source does not exist: %s", sourcePath)
+		return nil, fmt.Errorf("source does not exist: %s", sourcePath)
 	}
 	if err != nil {
-		return fmt.Errorf("failed to stat source: %w", err)
+		return nil, fmt.Errorf("failed to stat source: %w", err)
 	}
 
 	// Check if destination already exists
 	if _, err := os.Stat(destPath); err == nil {
-		return fmt.Errorf("destination already exists: %s", destPath)
+		return nil, fmt.Errorf("destination already exists: %s", destPath)
 	}
 
 	// TOCTOU defense: re-resolve symlinks immediately before copy.
@@ -529,10 +631,15 @@ func (e *UltraFastEngine) CopyFile(ctx context.Context, sourcePath, destPath str
 	// the earlier IsPathAllowed check and now.
 	sourceResolved, wasSymlink, err := ResolveSymlinks(sourcePath)
 	if err != nil {
-		return fmt.Errorf("failed to resolve source path: %w", err)
+		return nil, fmt.Errorf("failed to resolve source path: %w", err)
 	}
 	if wasSymlink {
-		return fmt.Errorf("security: source path resolved to symlink %q", sourceResolved)
+		return nil, fmt.Errorf("security: source path resolved to symlink %q", sourceResolved)
+	}
+
+	// A directory copied into itself would recurse without end
+	if sourceInfo.IsDir() && pathInside(sourceResolved, destPath) {
+		return nil, fmt.Errorf("destination %s is inside the source directory %s", destPath, sourcePath)
 	}
 
 	// Execute pre-copy hook
@@ -549,28 +656,42 @@ func (e *UltraFastEngine) CopyFile(ctx context.Context, sourcePath, destPath str
 		Metadata:   map[string]interface{}{"is_dir": sourceInfo.IsDir()},
 	}
 	if _, err := e.hookManager.ExecuteHooks(ctx, HookPreCopy, hookCtx); err != nil {
-		return fmt.Errorf("pre-copy hook denied operation: %w", err)
+		return nil, fmt.Errorf("pre-copy hook denied operation: %w", err)
 	}
 
 	// Copy based on type
-	var copyErr error
+	plan := planFileCopy(sourcePath, destPath, sourceInfo)
 	if sourceInfo.IsDir() {
-		copyErr = e.copyDirectory(sourcePath, destPath)
-	} else {
-		copyErr = e.copyFile(sourcePath, destPath)
+		plan, err = planTreeCopy(ctx, sourcePath, destPath, opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+	result, copyErr := e.copyTree(ctx, plan, opts)
+	result.Source, result.Dest, result.Dir = sourcePath, destPath, sourceInfo.IsDir()
+	if copyErr == nil {
+		copyErr = e.verifyCopy(plan, opts.Verify, result)
 	}
+	result.DurationMs = time.Since(start).Milliseconds()
+
+	// Invalidate cache entries for the destination, complete or not
+	e.invalidateMutatedPath(destPath)
+	e.invalidateDirListing(destPath)
 	if copyErr != nil {
-		return copyErr
+		return result, copyErr
 	}
 
 	// Execute post-copy hook (best-effort)
 	hookCtx.Event = HookPostCopy
 	_, _ = e.hookManager.ExecuteHooks(ctx, HookPostCopy, hookCtx)
 
-	return nil
+	e.recordOperation(ctx, OperationRecord{Operation: "copy", Path: sourcePath, Dest: destPath})
+
+	return result, nil
 }
 
-// copyFile copies a single file using io.Copy for memory efficiency
+// copyFile copies a single file through the buffer pool, keeping its mode
+// and modification time
 func (e *UltraFastEngine) copyFile(src, dst string) error {
 	// TOCTOU defense: re-resolve symlinks immediately before copy.
 	// An attacker could have replaced the file with a symlink between
@@ -622,59 +743,14 @@ func (e *UltraFastEngine) copyFile(src, dst string) error {
 	if err := dstFile.Sync(); err != nil {
 		return fmt.Errorf("failed to sync destination file: %w", err)
 	}
-
-	// Invalidate cache for destination
-	e.invalidateFileReadCache(dst)
-	e.cache.InvalidateDirectory(filepath.Dir(dst))
-
-	return nil
-}
-
-// copyDirectory recursively copies a directory
-func (e *UltraFastEngine) copyDirectory(src, dst string) error {
-	// Get source directory info
-	sourceInfo, err := os.Stat(src)
-	if err != nil {
-		return fmt.Errorf("failed to stat source directory: %w", err)
-	}
-
-	// Create destination directory
-	if err := os.MkdirAll(dst, sourceInfo.Mode()); err != nil {
-		return fmt.Errorf("failed to create destination directory: %w", err)
-	}
-
-	// Read directory contents
-	entries, err := os.ReadDir(src)
-	if err != nil {
-		return fmt.Errorf("failed to read directory: %w", err)
-	}
-
-	// Copy each entry, skipping symlinks to prevent sandbox escape and infinite loops
-	for _, entry := range entries {
-		sourcePath := filepath.Join(src, entry.Name())
-		destPath := filepath.Join(dst, entry.Name())
-
-		// Skip symlinks to prevent following links outside allowed paths
-		// and to avoid infinite loops from circular symlinks
-		if entry.Type()&os.ModeSymlink != 0 {
-			continue
-		}
-
-		if entry.IsDir() {
-			// Recursively copy subdirectory
-			if err := e.copyDirectory(sourcePath, destPath); err != nil {
-				return err
-			}
-		} else {
-			// Copy file
-			if err := e.copyFile(sourcePath, destPath); err != nil {
-				return err
-			}
-		}
+	if err := dstFile.Close(); err != nil {
+		return fmt.Errorf("failed to close destination file: %w", err)
 	}
+	_ = os.Chtimes(dst, sourceInfo.ModTime(), sourceInfo.ModTime())
 
 	// Invalidate cache for destination
-	e.cache.InvalidateDirectory(dst)
+	e.invalidateFileReadCache(dst)
+	e.invalidateDirListing(filepath.Dir(dst))
 
 	return nil
 }
@@ -717,8 +793,9 @@ func (e *UltraFastEngine) ReadFileRange(ctx context.Context, path string, startL
 		return "", fmt.Errorf("end_line (%d) must be >= start_line (%d)", endLine, startLine)
 	}
 
-	// Fast path: serve range from cache or deduped full read (files ≤ LargeFileThreshold).
-	if info.Size() <= LargeFileThreshold {
+	// Fast path: serve range from cache or deduped full read (files ≤ LargeFileThreshold
+	// and not over --binary-threshold).
+	if info.Size() <= LargeFileThreshold && !e.OverBinaryThreshold(info.Size()) {
 		if cached, hit := e.cache.GetFile(path); hit {
 			return extractLineRangeFromBytes(cached, path, startLine, endLine)
 		}
@@ -729,62 +806,17 @@ func (e *UltraFastEngine) ReadFileRange(ctx context.Context, path string, startL
 		return extractLineRangeFromBytes(content, path, startLine, endLine)
 	}
 
-	// Open file for efficient line-by-line reading
-	file, err := os.Open(path)
+	// Large file: seek through the cached line index rather than counting
+	// newlines from the start on every call. The index also supplies the
+	// REAL total for the footer; earlier versions stopped scanning at endLine
+	// and reported "[Lines 15-50 of 51 total lines]" for a 685-line file,
+	// which readers took for a truncated file.
+	content, totalLines, err := e.readIndexedRange(ctx, path, info, startLine, endLine)
 	if err != nil {
-		return "", fmt.Errorf("failed to open file: %w", err)
+		return "", err
 	}
-	defer file.Close()
-
 	var result strings.Builder
-	scanner := bufio.NewScanner(file)
-
-	// Use a larger buffer for better performance with long lines
-	const maxCapacity = 1024 * 1024 // 1MB buffer for very long lines
-	buf := make([]byte, 0, 64*1024)
-	scanner.Buffer(buf, maxCapacity)
-
-	lineNum := 0
-	foundLines := 0
-
-	// Read file line by line.
-	//
-	// IMPORTANT: we keep iterating past endLine WITHOUT collecting content,
-	// so the footer can report the REAL total line count of the file.
-	//
-	// Earlier versions broke out of this loop as soon as lineNum > endLine,
-	// which left totalLines = endLine + 1 and produced a footer like
-	//   "[Lines 15-50 of 51 total lines in foo.go]"
-	// for a file that actually had 685 lines. Both humans and AI agents
-	// reading that footer concluded the file had been truncated to 51 lines
-	// — a silent reporting bug that mimicked catastrophic data loss.
-	//
-	// The cost of finishing the scan without buffering is negligible
-	// (just newline detection per line), and it eliminates the lying footer.
-	for scanner.Scan() {
-		lineNum++
-
-		// Before the requested range: skip cheaply.
-		if lineNum < startLine {
-			continue
-		}
-
-		// Inside the requested range: collect the line.
-		if lineNum <= endLine {
-			if result.Len() > 0 {
-				result.WriteString("\n")
-			}
-			result.WriteString(scanner.Text())
-			foundLines++
-		}
-		// After the range: keep iterating to get the real totalLines, but
-		// do not call scanner.Text() (avoids buffer copies for unused lines).
-	}
-	totalLines := lineNum
-
-	if err := scanner.Err(); err != nil {
-		return "", fmt.Errorf("error reading file: %w", err)
-	}
+	result.WriteString(content)
 
 	// If endLine was beyond the actual file length, clamp for display.
 	actualEndLine := endLine
@@ -819,84 +851,9 @@ func (e *UltraFastEngine) ReadFileRange(ctx context.Context, path string, startL
 
 // GetFileInfo returns detailed information about a file or directory
 func (e *UltraFastEngine) GetFileInfo(ctx context.Context, path string) (string, error) {
-	// Normalize path (handles WSL ↔ Windows conversion)
-	path = NormalizePath(path)
-	// Acquire semaphore
-	if err := e.acquireOperation(ctx, "fileinfo"); err != nil {
-		return "", err
-	}
-
-	start := time.Now()
-	defer e.releaseOperation("fileinfo", start)
-
-	// Check if path is allowed (security + access control)
-	if !e.IsPathAllowed(path) {
-		return "", fmt.Errorf("access denied: path '%s' is not in allowed paths%s", path, e.AllowedDirsSuffix())
-	}
-
-	// Get file info
-	info, err := os.Stat(path)
-	if os.IsNotExist(err) {
-		return "", fmt.Errorf("file or directory does not exist: %s", path)
-	}
+	info, err := e.FileInfo(ctx, path)
 	if err != nil {
-		return "", fmt.Errorf("failed to stat file: %w", err)
-	}
-
-	// Build detailed info string
-	var result strings.Builder
-
-	if e.config.CompactMode {
-		// Compact mode: minimal info
-		fileType := "file"
-		if info.IsDir() {
-			fileType = "dir"
-		}
-		result.WriteString(fmt.Sprintf("%s: %s | %s | %s\n",
-			fileType,
-			info.Name(),
-			formatSize(info.Size()),
-			info.ModTime().Format("2006-01-02 15:04:05")))
-	} else {
-		// Verbose mode: detailed info
-		result.WriteString(fmt.Sprintf("📄 File Information\n"))
-		result.WriteString(fmt.Sprintf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n"))
-		result.WriteString(fmt.Sprintf("📁 Name: %s\n", info.Name()))
-		result.WriteString(fmt.Sprintf("📍 Full Path: %s\n", path))
-
-		if info.IsDir() {
-			result.WriteString(fmt.Sprintf("📂 Type: Directory\n"))
-
-			// Count items in directory if it's a directory
-			entries, err := os.ReadDir(path)
-			if err == nil {
-				fileCount := 0
-				dirCount := 0
-				for _, entry := range entries {
-					if entry.IsDir() {
-						dirCount++
-					} else {
-						fileCount++
-					}
-				}
-				result.WriteString(fmt.Sprintf("📊 Contents: %d files, %d directories\n", fileCount, dirCount))
-			}
-		} else {
-			result.WriteString(fmt.Sprintf("📄 Type: File\n"))
-			result.WriteString(fmt.Sprintf("💾 Size: %s (%d bytes)\n", formatSize(info.Size()), info.Size()))
-		}
-
-		result.WriteString(fmt.Sprintf("🔐 Permissions: %s\n", info.Mode().String()))
-		result.WriteString(fmt.Sprintf("🕐 Modified: %s\n", info.ModTime().Format("2006-01-02 15:04:05")))
-
-		// Get absolute path
-		absPath, err := filepath.Abs(path)
-		if err == nil && absPath != path {
-			result.WriteString(fmt.Sprintf("🔗 Absolute Path: %s\n", absPath))
-		}
-
-		result.WriteString(fmt.Sprintf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n"))
+		return "", err
 	}
-
-	return result.String(), nil
+	return FormatFileInfo(info, e.config.CompactMode), nil
 }
@@ -0,0 +1,251 @@
+package core
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// statsMaxFiles caps the per-file rows of a directory's stats; the totals
+// still cover every file
+const statsMaxFiles = 1000
+
+// FileStats is the wc-style statistics of a text file, or the totals over a
+// directory's text files
+type FileStats struct {
+	Path          string         `json:"path,omitempty"` // per-file rows of a directory: relative to it
+	Lines         int            `json:"lines"`
+	Words         int            `json:"words"`
+	Bytes         int64          `json:"bytes"`
+	MaxLineLength int            `json:"max_line_length"` // characters, line ending excluded
+	BlankLines    int            `json:"blank_lines"`     // empty or whitespace only
+	Patterns      map[string]int `json:"patterns,omitempty"`
+	Files         int            `json:"files,omitempty"`   // totals: text files counted
+	Skipped       int            `json:"skipped,omitempty"` // totals: binary or unreadable files left out
+	Omitted       int            `json:"omitted,omitempty"` // totals: rows past statsMaxFiles
+}
+
+// statsPatterns compiles get_file_info patterns: regular expressions, taken
+// literally when they do not compile
+func (e *UltraFastEngine) statsPatterns(patterns []string) []*regexp.Regexp {
+	res := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := e.CompileRegex(p)
+		if err != nil {
+			re, _ = e.CompileRegex(regexp.QuoteMeta(p))
+		}
+		res = append(res, re)
+	}
+	return res
+}
+
+// fileStats streams path once and counts it like wc, plus blank lines, the
+// longest line and pattern matches per line. Memory stays at the read buffer
+// unless patterns are given, which need the current line. text is false,
+// with nothing counted, for content DetectContentType does not call UTF-8
+// text.
+func fileStats(path string, patterns []string, res []*regexp.Regexp) (st FileStats, text bool, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return st, false, err
+	}
+	defer f.Close()
+	r := bufio.NewReaderSize(f, 64*1024)
+	if head, err := r.Peek(sniffLen); err != nil && err != io.EOF {
+		return st, false, err
+	} else if !DetectContentType(path, head).Text() {
+		return st, false, nil
+	}
+	if len(res) > 0 {
+		st.Patterns = make(map[string]int, len(res))
+		for _, p := range patterns {
+			st.Patterns[p] = 0
+		}
+	}
+
+	var line []byte // the current line, kept only for pattern matching
+	lineLen, pending, blank, inWord := 0, false, true, false
+	endLine := func() {
+		st.Lines++
+		if blank {
+			st.BlankLines++
+		}
+		if lineLen > st.MaxLineLength {
+			st.MaxLineLength = lineLen
+		}
+		for i, re := range res {
+			st.Patterns[patterns[i]] += len(re.FindAllIndex(line, -1))
+		}
+		line, lineLen, pending, blank, inWord = line[:0], 0, false, true, false
+	}
+	for {
+		frag, err := r.ReadSlice('\n')
+		if len(frag) > 0 {
+			st.Bytes += int64(len(frag))
+			body := frag
+			if body[len(body)-1] == '\n' {
+				body = body[:len(body)-1]
+			}
+			for _, b := range body {
+				switch {
+				case b == ' ' || b == '\t' || b == '\r' || b == '\v' || b == '\f':
+					inWord = false
+				default:
+					blank = false
+					if !inWord {
+						st.Words++
+						inWord = true
+					}
+				}
+				// Count characters, not bytes: skip UTF-8 continuation
+				// bytes, and the CR of a CRLF ending
+				if b&0xC0 != 0x80 && b != '\r' {
+					lineLen++
+				}
+			}
+			if len(body) > 0 {
+				pending = true
+				if len(res) > 0 {
+					line = append(line, body...)
+				}
+			}
+			if len(body) < len(frag) {
+				endLine()
+			}
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return st, false, err
+		}
+	}
+	if pending {
+		endLine()
+	}
+	return st, true, nil
+}
+
+// dirStats runs fileStats over the regular files under root, skipping the
+// directories search skips, and returns the totals plus one row per file
+// (sorted by path, at most statsMaxFiles)
+func (e *UltraFastEngine) dirStats(ctx context.Context, root string, patterns []string) (FileStats, []FileStats, error) {
+	res := e.statsPatterns(patterns)
+	total := FileStats{}
+	if len(res) > 0 {
+		total.Patterns = make(map[string]int, len(res))
+		for _, p := range patterns {
+			total.Patterns[p] = 0
+		}
+	}
+	var rows []FileStats
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			if d != nil && d.IsDir() && path != root {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if path != root && searchSkipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		st, text, err := fileStats(path, patterns, res)
+		if err != nil || !text {
+			total.Skipped++
+			return nil
+		}
+		total.Files++
+		total.Lines += st.Lines
+		total.Words += st.Words
+		total.Bytes += st.Bytes
+		total.BlankLines += st.BlankLines
+		if st.MaxLineLength > total.MaxLineLength {
+			total.MaxLineLength = st.MaxLineLength
+		}
+		for p, n := range st.Patterns {
+			total.Patterns[p] += n
+		}
+		if len(rows) >= statsMaxFiles {
+			total.Omitted++
+			return nil
+		}
+		if rel, err := filepath.Rel(root, path); err == nil {
+			st.Path = filepath.ToSlash(rel)
+		}
+		rows = append(rows, st)
+		return nil
+	})
+	if err != nil {
+		return total, rows, err
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Path < rows[j].Path })
+	return total, rows, nil
+}
+
+// formatStatsTable renders a directory's per-file stats and totals as an
+// aligned table, one column per pattern after the fixed ones
+func formatStatsTable(total FileStats, rows []FileStats, patterns []string) string {
+	var b strings.Builder
+	header := fmt.Sprintf("%8s %8s %10s %6s %6s", "lines", "words", "bytes", "max", "blank")
+	for _, p := range patterns {
+		header += fmt.Sprintf(" %8s", truncateLabel(p, 8))
+	}
+	b.WriteString(header + "  path\n")
+	row := func(st FileStats, label string) {
+		b.WriteString(fmt.Sprintf("%8d %8d %10d %6d %6d", st.Lines, st.Words, st.Bytes, st.MaxLineLength, st.BlankLines))
+		for _, p := range patterns {
+			b.WriteString(fmt.Sprintf(" %8d", st.Patterns[p]))
+		}
+		b.WriteString("  " + label + "\n")
+	}
+	for _, st := range rows {
+		row(st, st.Path)
+	}
+	label := fmt.Sprintf("total (%d files", total.Files)
+	if total.Skipped > 0 {
+		label += fmt.Sprintf(", %d binary or unreadable skipped", total.Skipped)
+	}
+	if total.Omitted > 0 {
+		label += fmt.Sprintf(", %d not listed", total.Omitted)
+	}
+	row(total, label+")")
+	return b.String()
+}
+
+// sortedPatternNames returns the patterns of a stats count map in order
+func sortedPatternNames(counts map[string]int) []string {
+	names := make([]string, 0, len(counts))
+	for p := range counts {
+		names = append(names, p)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// truncateLabel shortens s to n characters for a table header
+func truncateLabel(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
@@ -0,0 +1,85 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileStats_Counts(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.go")
+	// 4 lines (the last unterminated), 1 blank, CRLF on one, a 5-character
+	// non-ASCII line
+	os.WriteFile(path, []byte("func a() {\r\n\t\n// TODO: x TODO\nhéllo"), 0644)
+
+	engine := newTestEngine(dir)
+	patterns := []string{"TODO", "func [a-z]", "("}
+	st, text, err := fileStats(path, patterns, engine.statsPatterns(patterns))
+	if err != nil || !text {
+		t.Fatalf("fileStats = %v, text=%v", err, text)
+	}
+	want := FileStats{Lines: 4, Words: 8, Bytes: 36, MaxLineLength: 15, BlankLines: 1}
+	if st.Lines != want.Lines || st.Words != want.Words || st.Bytes != want.Bytes ||
+		st.MaxLineLength != want.MaxLineLength || st.BlankLines != want.BlankLines {
+		t.Errorf("stats = %+v, want %+v", st, want)
+	}
+	if st.Patterns["TODO"] != 2 || st.Patterns["func [a-z]"] != 1 || st.Patterns["("] != 1 {
+		t.Errorf("pattern counts = %v", st.Patterns)
+	}
+
+	// Lines longer than the read buffer are still one line
+	long := filepath.Join(dir, "long.txt")
+	os.WriteFile(long, append(bytes.Repeat([]byte("ab "), 50000), '\n'), 0644)
+	st, _, err = fileStats(long, nil, nil)
+	if err != nil || st.Lines != 1 || st.Words != 50000 || st.MaxLineLength != 150000 {
+		t.Errorf("long line stats = %+v, %v", st, err)
+	}
+
+	bin := filepath.Join(dir, "b.bin")
+	os.WriteFile(bin, []byte("x\x00y"), 0644)
+	if _, text, _ := fileStats(bin, nil, nil); text {
+		t.Error("binary file was counted")
+	}
+}
+
+func TestFileStats_FileInfoAndDirectory(t *testing.T) {
+	dir := t.TempDir()
+	engine := newTestEngine(dir)
+	ctx := context.Background()
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("one TODO\ntwo\n"), 0644)
+	os.Mkdir(filepath.Join(dir, "sub"), 0755)
+	os.WriteFile(filepath.Join(dir, "sub", "b.txt"), []byte("TODO three four\n\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "c.bin"), []byte{0, 1, 2}, 0644)
+	os.Mkdir(filepath.Join(dir, "node_modules"), 0755)
+	os.WriteFile(filepath.Join(dir, "node_modules", "x.js"), []byte("skipped\n"), 0644)
+
+	opts := FileInfoOptions{Stats: true, Patterns: []string{"TODO"}}
+	info, err := engine.FileInfoWith(ctx, filepath.Join(dir, "a.txt"), opts)
+	if err != nil || info.Stats == nil || info.Stats.Words != 3 || info.Stats.Patterns["TODO"] != 1 || *info.Lines != 2 {
+		t.Fatalf("file stats = %+v, %v", info.Stats, err)
+	}
+	text := FormatFileInfo(info, false)
+	if !strings.Contains(text, "📏 Lines: 2 (0 blank) | Words: 3 | Longest line: 8") || !strings.Contains(text, "🔎 Matches: TODO: 1") {
+		t.Errorf("file prose:\n%s", text)
+	}
+
+	info, err = engine.FileInfoWith(ctx, dir, opts)
+	if err != nil || info.Stats == nil {
+		t.Fatalf("dir stats: %v", err)
+	}
+	total := *info.Stats
+	if total.Files != 2 || total.Skipped != 1 || total.Lines != 4 || total.Words != 6 || total.BlankLines != 1 || total.Patterns["TODO"] != 2 {
+		t.Errorf("totals = %+v", total)
+	}
+	if len(info.StatsFiles) != 2 || info.StatsFiles[0].Path != "a.txt" || info.StatsFiles[1].Path != "sub/b.txt" {
+		t.Errorf("rows = %+v", info.StatsFiles)
+	}
+	table := FormatFileInfo(info, true)
+	if !strings.Contains(table, "sub/b.txt") || !strings.Contains(table, "total (2 files, 1 binary or unreadable skipped)") {
+		t.Errorf("directory table:\n%s", table)
+	}
+}
@@ -0,0 +1,250 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Git awareness for get_file_info, list_directory and analyze_directory
+// tree (include_git) and git(action:"changed"). Everything here is read-only:
+// one `git status` per repository and call, run with --no-optional-locks so
+// it never rewrites the index, and silently absent when the path is not in a
+// repository or git is not installed.
+
+// Working-tree status codes, as in `git status --short`
+const (
+	GitModified  = "M"
+	GitAdded     = "A"
+	GitDeleted   = "D"
+	GitRenamed   = "R"
+	GitCopied    = "C"
+	GitConflict  = "U"
+	GitUntracked = "??"
+	GitIgnored   = "!!"
+	GitClean     = "clean" // get_file_info only: tracked, no changes
+)
+
+// GitFileStatus is one changed path of a repository
+type GitFileStatus struct {
+	Path     string `json:"path"`                // relative to the repository root, slash-separated
+	Status   string `json:"status"`              // M, A, D, R, C, U or ??
+	Staged   string `json:"staged,omitempty"`    // index column of git status --short
+	Unstaged string `json:"unstaged,omitempty"`  // working-tree column
+	OrigPath string `json:"orig_path,omitempty"` // renames and copies: the old path
+}
+
+// GitChanges is git(action:"changed")
+type GitChanges struct {
+	Root  string          `json:"root"`
+	Files []GitFileStatus `json:"files"`
+}
+
+// GitStatusIndex is a repository's `git status`, for looking up paths
+type GitStatusIndex struct {
+	Root    string
+	entries map[string]GitFileStatus // changed, untracked and ignored paths
+	dirs    map[string]string        // untracked or ignored directories ("dir" without slash) → code
+}
+
+// LoadGitStatus runs `git status` for the repository holding path
+func LoadGitStatus(ctx context.Context, path string) (*GitStatusIndex, error) {
+	root, err := FindGitRoot(path)
+	if err != nil {
+		return nil, err
+	}
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	cmd := exec.CommandContext(ctx, "git", "--no-optional-locks", "status", "--porcelain=v1", "-z", "--untracked-files=normal", "--ignored")
+	cmd.Dir = root
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("git status: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	ix := &GitStatusIndex{Root: root, entries: map[string]GitFileStatus{}, dirs: map[string]string{}}
+	for _, st := range parseGitPorcelainZ(stdout.Bytes()) {
+		if strings.HasSuffix(st.Path, "/") && (st.Status == GitUntracked || st.Status == GitIgnored) {
+			ix.dirs[strings.TrimSuffix(st.Path, "/")] = st.Status
+			continue
+		}
+		ix.entries[st.Path] = st
+	}
+	return ix, nil
+}
+
+// parseGitPorcelainZ parses `git status --porcelain=v1 -z`: "XY path\0",
+// with renames and copies followed by "orig\0"
+func parseGitPorcelainZ(out []byte) []GitFileStatus {
+	fields := strings.Split(string(out), "\x00")
+	var res []GitFileStatus
+	for i := 0; i < len(fields); i++ {
+		f := fields[i]
+		if len(f) < 4 {
+			continue
+		}
+		x, y := f[0], f[1]
+		st := GitFileStatus{Path: f[3:], Status: gitStatusCode(x, y)}
+		if x != ' ' && x != '?' && x != '!' {
+			st.Staged = string(x)
+		}
+		if y != ' ' && y != '?' && y != '!' {
+			st.Unstaged = string(y)
+		}
+		if (x == 'R' || x == 'C') && i+1 < len(fields) {
+			i++
+			st.OrigPath = fields[i]
+		}
+		res = append(res, st)
+	}
+	return res
+}
+
+// gitStatusCode folds the two status columns into one code
+func gitStatusCode(x, y byte) string {
+	switch {
+	case x == '?':
+		return GitUntracked
+	case x == '!':
+		return GitIgnored
+	case x == 'U' || y == 'U' || (x == 'A' && y == 'A') || (x == 'D' && y == 'D'):
+		return GitConflict
+	case x != ' ':
+		return string(x)
+	}
+	return string(y)
+}
+
+// rel returns path relative to the repository root, slash-separated, and
+// false when it lies outside the repository or inside .git
+func (ix *GitStatusIndex) rel(path string) (string, bool) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(ix.Root, abs)
+	if err != nil {
+		return "", false
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == ".." || strings.HasPrefix(rel, "../") || rel == ".git" || strings.HasPrefix(rel, ".git/") {
+		return "", false
+	}
+	return rel, true
+}
+
+// Status returns the listing annotation of path: its code for a changed,
+// untracked or ignored file, GitModified for a directory with changes below
+// it, "" when clean or outside the repository
+func (ix *GitStatusIndex) Status(path string, isDir bool) string {
+	rel, ok := ix.rel(path)
+	if !ok {
+		return ""
+	}
+	if st, ok := ix.entries[rel]; ok {
+		return st.Status
+	}
+	for dir := rel; dir != "." && dir != ""; dir = pathDir(dir) {
+		if code, ok := ix.dirs[dir]; ok {
+			return code
+		}
+	}
+	if isDir {
+		prefix := rel + "/"
+		if rel == "." {
+			prefix = ""
+		}
+		for p, st := range ix.entries {
+			if st.Status != GitIgnored && strings.HasPrefix(p, prefix) {
+				return GitModified
+			}
+		}
+	}
+	return ""
+}
+
+// FileStatus is Status for get_file_info, where a tracked file without
+// changes is GitClean rather than unannotated
+func (ix *GitStatusIndex) FileStatus(path string, isDir bool) string {
+	if _, ok := ix.rel(path); !ok {
+		return ""
+	}
+	if code := ix.Status(path, isDir); code != "" {
+		return code
+	}
+	return GitClean
+}
+
+// Changes lists the changed and untracked paths, sorted, without ignored ones
+func (ix *GitStatusIndex) Changes() *GitChanges {
+	out := &GitChanges{Root: ix.Root, Files: []GitFileStatus{}}
+	for _, st := range ix.entries {
+		if st.Status != GitIgnored {
+			out.Files = append(out.Files, st)
+		}
+	}
+	for dir, code := range ix.dirs {
+		if code == GitUntracked {
+			out.Files = append(out.Files, GitFileStatus{Path: dir + "/", Status: code})
+		}
+	}
+	sort.Slice(out.Files, func(i, j int) bool { return out.Files[i].Path < out.Files[j].Path })
+	return out
+}
+
+// FormatGitChanges renders git(action:"changed") like `git status --short`
+func FormatGitChanges(c *GitChanges) string {
+	if len(c.Files) == 0 {
+		return fmt.Sprintf("%s: no working-tree changes", c.Root)
+	}
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%s: %d changed\n", c.Root, len(c.Files)))
+	for _, f := range c.Files {
+		if f.OrigPath != "" {
+			b.WriteString(fmt.Sprintf("%-2s %s -> %s\n", f.Status, f.OrigPath, f.Path))
+		} else {
+			b.WriteString(fmt.Sprintf("%-2s %s\n", f.Status, f.Path))
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// pathDir is path.Dir for the slash-separated relative paths above
+func pathDir(p string) string {
+	if i := strings.LastIndexByte(p, '/'); i >= 0 {
+		return p[:i]
+	}
+	return "."
+}
+
+// gitStatusMemo shares one GitStatusIndex per repository across the paths
+// of a get_file_info batch
+type gitStatusMemo struct {
+	mu    sync.Mutex
+	byDir map[string]*GitStatusIndex // FindGitRoot result → index; nil = no repository
+}
+
+// load returns the index for path's repository, running git once per root
+func (m *gitStatusMemo) load(ctx context.Context, path string) *GitStatusIndex {
+	root, err := FindGitRoot(path)
+	if err != nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if ix, ok := m.byDir[root]; ok {
+		return ix
+	}
+	ix, _ := LoadGitStatus(ctx, root)
+	if m.byDir == nil {
+		m.byDir = map[string]*GitStatusIndex{}
+	}
+	m.byDir[root] = ix
+	return ix
+}
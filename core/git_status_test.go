@@ -0,0 +1,127 @@
+package core
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// gitRepoForStatus builds a repository with one clean, one modified, one
+// staged, one untracked and one ignored file
+func gitRepoForStatus(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	git("config", "user.email", "t@example.com")
+	git("config", "user.name", "t")
+	os.MkdirAll(filepath.Join(dir, "src"), 0755)
+	os.WriteFile(filepath.Join(dir, "clean.txt"), []byte("a\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "src", "mod.go"), []byte("package src\n"), 0644)
+	os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("*.log\n"), 0644)
+	git("add", ".")
+	git("commit", "-q", "-m", "init")
+	os.WriteFile(filepath.Join(dir, "src", "mod.go"), []byte("package src // changed\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "staged.txt"), []byte("new\n"), 0644)
+	git("add", "staged.txt")
+	os.MkdirAll(filepath.Join(dir, "fresh"), 0755)
+	os.WriteFile(filepath.Join(dir, "fresh", "u.txt"), []byte("u\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "run.log"), []byte("log\n"), 0644)
+	return dir
+}
+
+func TestGitStatus_Lookups(t *testing.T) {
+	dir := gitRepoForStatus(t)
+	ix, err := LoadGitStatus(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		rel   string
+		isDir bool
+		want  string
+	}{
+		{"clean.txt", false, ""},
+		{"src/mod.go", false, GitModified},
+		{"src", true, GitModified},
+		{"staged.txt", false, GitAdded},
+		{"fresh", true, GitUntracked},
+		{"fresh/u.txt", false, GitUntracked},
+		{"run.log", false, GitIgnored},
+	}
+	for _, c := range cases {
+		if got := ix.Status(filepath.Join(dir, c.rel), c.isDir); got != c.want {
+			t.Errorf("Status(%s) = %q, want %q", c.rel, got, c.want)
+		}
+	}
+	if got := ix.FileStatus(filepath.Join(dir, "clean.txt"), false); got != GitClean {
+		t.Errorf("FileStatus(clean.txt) = %q", got)
+	}
+
+	changes := ix.Changes()
+	var paths []string
+	for _, f := range changes.Files {
+		paths = append(paths, f.Status+" "+f.Path)
+	}
+	if got := strings.Join(paths, ","); got != "?? fresh/,M src/mod.go,A staged.txt" {
+		t.Errorf("changes = %s", got)
+	}
+	if _, err := LoadGitStatus(context.Background(), t.TempDir()); err == nil {
+		t.Error("a directory outside any repository loaded a git status")
+	}
+}
+
+func TestGitStatus_Surfaces(t *testing.T) {
+	dir := gitRepoForStatus(t)
+	engine := newTestEngine(dir)
+	ctx := context.Background()
+
+	info, err := engine.FileInfoWith(ctx, filepath.Join(dir, "src", "mod.go"), FileInfoOptions{Git: true})
+	if err != nil || info.GitStatus != GitModified {
+		t.Errorf("file info git_status = %q, %v", info.GitStatus, err)
+	}
+	batch := engine.FilesInfo(ctx, []string{filepath.Join(dir, "clean.txt"), filepath.Join(dir, "run.log")}, FileInfoOptions{Git: true})
+	if batch.Files[0].GitStatus != GitClean || batch.Files[1].GitStatus != GitIgnored {
+		t.Errorf("batch git_status = %q, %q", batch.Files[0].GitStatus, batch.Files[1].GitStatus)
+	}
+	outside := t.TempDir()
+	os.WriteFile(filepath.Join(outside, "x.txt"), []byte("x"), 0644)
+	if info, _ := newTestEngine(outside).FileInfoWith(ctx, filepath.Join(outside, "x.txt"), FileInfoOptions{Git: true}); info.GitStatus != "" {
+		t.Errorf("git_status outside a repository = %q", info.GitStatus)
+	}
+
+	listing, err := engine.ListDirectoryView(ctx, dir, ListOptions{Git: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	marks := map[string]string{}
+	for _, e := range listing.Entries {
+		marks[e.Name] = e.Git
+	}
+	if marks["src"] != GitModified || marks["fresh"] != GitUntracked || marks["clean.txt"] != "" || !listing.View.Git {
+		t.Errorf("listing marks = %v", marks)
+	}
+	if text := FormatListView(listing, true); !strings.Contains(text, "src/[M]") {
+		t.Errorf("compact listing: %s", text)
+	}
+
+	tree, err := engine.DirectoryTree(ctx, dir, TreeOptions{Git: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := FormatDirectoryTree(tree, true, 0); !strings.Contains(text, "mod.go (23 B) [M]") || !strings.Contains(text, "staged.txt (4 B) [A]") {
+		t.Errorf("tree:\n%s", text)
+	}
+}
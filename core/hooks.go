@@ -7,6 +7,7 @@
 	"log/slog"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"runtime"
 	"strings"
@@ -58,12 +59,27 @@
 type HookType string
 
 const (
-	HookTypeCommand HookType = "command" // Shell command
+	HookTypeCommand HookType = "command" // Shell command, or a program when Args is set
 	HookTypeScript  HookType = "script"  // Script file
+	HookTypeWebhook HookType = "webhook" // HTTP request to URL
 )
 
+// What a hook receives on stdin
+const (
+	HookInputContext = "context" // the HookContext as JSON (default)
+	HookInputContent = "content" // the candidate file content; stdout replaces it
+)
+
+// defaultHookTimeout applies when a hook sets neither timeout nor timeout_ms
+const defaultHookTimeout = 60 * time.Second
+
+// hookWaitDelay bounds how long a timed-out hook may keep its pipes open
+// (e.g. a grandchild of sh -c) before Wait gives up on it
+const hookWaitDelay = time.Second
+
 // Hook represents a single hook configuration
 type Hook struct {
+	Name        string   `json:"name,omitempty"`    // Short name for responses and stats (default: description, else command)
 	Type        HookType `json:"type"`              // Type of hook (command or script)
 	Command     string   `json:"command"`           // Command to execute
 	Script      string   `json:"script,omitempty"`  // Script path (if type is script)
@@ -71,12 +87,136 @@ type Hook struct {
 	FailOnError bool     `json:"failOnError"`       // If true, operation fails if hook fails
 	Description string   `json:"description"`       // Human-readable description
 	Enabled     bool     `json:"enabled"`           // Whether this hook is enabled
+
+	// Args runs Command as a program with these arguments instead of through
+	// the shell; "{file_path}" in an argument expands to the file's path.
+	Args []string `json:"args,omitempty"`
+	// Input selects stdin: HookInputContext (default) or HookInputContent.
+	// A content hook is a formatter: it runs only when the event carries
+	// content (pre-write), exit 0 means allow with stdout as the new content,
+	// and any other exit (or the timeout) denies with stderr as the reason.
+	// Content hooks run one after another, each formatting the previous output.
+	Input      string            `json:"input,omitempty"`
+	TimeoutMs  int               `json:"timeout_ms,omitempty"`  // Overrides Timeout
+	WorkingDir string            `json:"working_dir,omitempty"` // Default: the server's working directory
+	Env        map[string]string `json:"env,omitempty"`         // Added to the server's environment
+
+	// Webhook hooks send the HookContext as JSON to URL. Content is left out
+	// unless MaxContentKB is set (then capped at that size). Secret ("${VAR}"
+	// is expanded) signs the body: X-Hook-Signature-256: sha256=<hex HMAC>.
+	// A failure is logged and the operation goes on, except on pre-* events
+	// with Blocking set, where a non-2xx response or an unreachable URL denies.
+	URL          string `json:"url,omitempty"`
+	Method       string `json:"method,omitempty"` // Default POST
+	Secret       string `json:"secret,omitempty"`
+	Blocking     bool   `json:"blocking,omitempty"`
+	MaxContentKB int    `json:"max_content_kb,omitempty"`
+}
+
+// timeout returns the hook's execution limit
+func (h *Hook) timeout() time.Duration {
+	switch {
+	case h.TimeoutMs > 0:
+		return time.Duration(h.TimeoutMs) * time.Millisecond
+	case h.Timeout > 0:
+		return time.Duration(h.Timeout) * time.Second
+	case h.Type == HookTypeWebhook:
+		return defaultWebhookTimeout
+	default:
+		return defaultHookTimeout
+	}
+}
+
+// label names the hook in logs, responses and stats: its name, else its
+// description, else what it runs
+func (h *Hook) label() string {
+	if h.Name != "" {
+		return h.Name
+	}
+	if h.Description != "" {
+		return h.Description
+	}
+	return h.target()
+}
+
+// target is what the hook runs: the script, the command, or the webhook's
+// URL without its path (webhook paths often carry the token)
+func (h *Hook) target() string {
+	switch h.Type {
+	case HookTypeScript:
+		return h.Script
+	case HookTypeWebhook:
+		return redactWebhookURL(h.URL)
+	}
+	return h.Command
 }
 
 // HookMatcher represents a matcher for specific tools/operations
 type HookMatcher struct {
 	Pattern string  `json:"pattern"` // Pattern to match (exact, regex, or wildcard)
 	Hooks   []*Hook `json:"hooks"`   // Hooks to execute for this pattern
+
+	// Path filters, evaluated against HookContext.FilePath after Pattern:
+	// the path must match one Include pattern (when any are set), none of
+	// the Exclude patterns, and have one of the Extensions ("go" or ".go",
+	// case-insensitive). Patterns use the never-cache syntax: "*.md" against
+	// the file name, "vendor/**" for a directory at any depth, "cmd/*.go"
+	// against the trailing segments. Backslash separators are accepted.
+	Include    []string `json:"include,omitempty"`
+	Exclude    []string `json:"exclude,omitempty"`
+	Extensions []string `json:"extensions,omitempty"`
+}
+
+// hasPathFilters reports whether the matcher restricts paths at all
+func (m *HookMatcher) hasPathFilters() bool {
+	return len(m.Include) > 0 || len(m.Exclude) > 0 || len(m.Extensions) > 0
+}
+
+// matchesPath applies the matcher's path filters to path
+func (m *HookMatcher) matchesPath(path string) bool {
+	if !m.hasPathFilters() {
+		return true
+	}
+	slashed := strings.ReplaceAll(path, "\\", "/")
+	var segments []string
+	for _, seg := range strings.Split(slashed, "/") {
+		if seg != "" {
+			segments = append(segments, seg)
+		}
+	}
+	matchAny := func(patterns []string) bool {
+		for _, p := range patterns {
+			if matchPathPattern(segments, strings.ReplaceAll(p, "\\", "/")) != "" {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(m.Extensions) > 0 {
+		ext := ""
+		if len(segments) > 0 {
+			ext = strings.ToLower(filepath.Ext(segments[len(segments)-1]))
+		}
+		found := false
+		for _, want := range m.Extensions {
+			want = strings.ToLower(strings.TrimSpace(want))
+			if want != "" && !strings.HasPrefix(want, ".") {
+				want = "." + want
+			}
+			if ext != "" && ext == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(m.Include) > 0 && !matchAny(m.Include) {
+		return false
+	}
+	return !matchAny(m.Exclude)
 }
 
 // HookConfig represents the complete hook configuration
@@ -98,10 +238,15 @@ type HookContext struct {
 	Timestamp  time.Time              `json:"timestamp"`             // Timestamp of the operation
 	WorkingDir string                 `json:"working_dir"`           // Current working directory
 	Metadata   map[string]interface{} `json:"metadata,omitempty"`    // Additional metadata
+
+	// ContentPath is a file holding the content to write when it is not in
+	// Content (write sessions); the secret scan reads it in chunks
+	ContentPath string `json:"-"`
 }
 
 // HookResult represents the result of a hook execution
 type HookResult struct {
+	Name              string                 `json:"name,omitempty"`               // Hook that produced it (description, else command)
 	Decision          HookDecision           `json:"decision"`                     // Decision (allow/deny/continue)
 	Reason            string                 `json:"reason,omitempty"`             // Reason for the decision
 	ModifiedContent   string                 `json:"modified_content,omitempty"`   // Modified content (e.g., formatted)
@@ -116,9 +261,14 @@ type HookResult struct {
 // HookManager manages hook execution
 type HookManager struct {
 	config      *HookConfig
+	configPath  string // file LoadConfig/Reload read; "" when configured in code
 	configMutex sync.RWMutex
 	enabled     bool
 	debugMode   bool
+	secretGuard *SecretGuard // built-in pre-write/pre-edit scan; nil = off
+
+	statsMu   sync.Mutex
+	execStats map[string]*HookExecStats // by hook name
 }
 
 // NewHookManager creates a new hook manager
@@ -149,7 +299,17 @@ func (hm *HookManager) LoadConfig(configPath string) error {
 		return fmt.Errorf("failed to parse hook config: %w", err)
 	}
 
+	if config.Hooks == nil {
+		config.Hooks = make(map[HookEvent][]*HookMatcher)
+	}
+	// Startup stays lenient (a typo must not take every hook down); Reload
+	// refuses a config with problems
+	for _, problem := range ValidateHookConfig(&config) {
+		slog.Warn("Hook config problem", "path", configPath, "problem", problem)
+	}
+
 	hm.config = &config
+	hm.configPath = configPath
 	hm.enabled = true
 
 	slog.Info("Hook configuration loaded", "path", configPath)
@@ -177,8 +337,40 @@ func (hm *HookManager) SetDebugMode(debug bool) {
 	hm.debugMode = debug
 }
 
+// SetSecretGuard installs the built-in secret scan (nil removes it). It runs
+// for pre-write and pre-edit whether or not configured hooks are enabled.
+func (hm *HookManager) SetSecretGuard(guard *SecretGuard) {
+	hm.configMutex.Lock()
+	defer hm.configMutex.Unlock()
+	hm.secretGuard = guard
+}
+
+// SecretGuard returns the built-in secret scan, nil when it is off
+func (hm *HookManager) SecretGuard() *SecretGuard {
+	hm.configMutex.RLock()
+	defer hm.configMutex.RUnlock()
+	return hm.secretGuard
+}
+
+// IsActive reports whether ExecuteHooks can do anything: configured hooks
+// are enabled or the built-in secret scan is on. Callers that skip building
+// a HookContext when hooks are off should check this rather than IsEnabled.
+func (hm *HookManager) IsActive() bool {
+	hm.configMutex.RLock()
+	defer hm.configMutex.RUnlock()
+	return hm.enabled || hm.secretGuard != nil
+}
+
 // ExecuteHooks executes all matching hooks for an event
 func (hm *HookManager) ExecuteHooks(ctx context.Context, event HookEvent, hookCtx *HookContext) (*HookResult, error) {
+	if event == HookPreWrite || event == HookPreEdit {
+		if guard := hm.SecretGuard(); guard != nil {
+			if denied := guard.check(event, hookCtx); denied != nil {
+				noteHook(ctx, fmt.Sprintf("blocked by hook '%s'", denied.Name))
+				return denied, fmt.Errorf("hook denied operation: %s", denied.Reason)
+			}
+		}
+	}
 	if !hm.IsEnabled() {
 		// Hooks disabled, allow operation
 		return &HookResult{Decision: HookAllow}, nil
@@ -193,33 +385,90 @@ func (hm *HookManager) ExecuteHooks(ctx context.Context, event HookEvent, hookCt
 		// No hooks configured for this event
 		return &HookResult{Decision: HookAllow}, nil
 	}
+	if hookCtx.Event != event {
+		// Webhooks decide between blocking and fire-and-forget on the event
+		withEvent := *hookCtx
+		withEvent.Event = event
+		hookCtx = &withEvent
+	}
 
-	// Find matching hooks
-	var matchedHooks []*Hook
+	// Find matching hooks; content hooks are kept apart (they chain)
+	var matchedHooks, contentHooks []*Hook
 	for _, matcher := range matchers {
-		if hm.matchesPattern(matcher.Pattern, hookCtx.ToolName) {
+		if hm.matchesPattern(matcher.Pattern, hookCtx.ToolName) && matcher.matchesPath(hookCtx.FilePath) {
 			for _, hook := range matcher.Hooks {
-				if hook.Enabled {
-					matchedHooks = append(matchedHooks, hook)
+				if !hook.Enabled {
+					continue
+				}
+				if hook.Input == HookInputContent && hook.Type != HookTypeWebhook {
+					if hookCtx.Content != "" {
+						contentHooks = append(contentHooks, hook)
+					}
+					continue
 				}
+				matchedHooks = append(matchedHooks, hook)
 			}
 		}
 	}
 
-	if len(matchedHooks) == 0 {
+	if len(matchedHooks) == 0 && len(contentHooks) == 0 {
 		// No matching hooks
 		return &HookResult{Decision: HookAllow}, nil
 	}
 
 	if debugMode {
-		slog.Debug("Executing hooks", "count", len(matchedHooks), "event", event, "path", hookCtx.FilePath)
+		var names []string
+		for _, h := range contentHooks {
+			names = append(names, h.label())
+		}
+		for _, h := range matchedHooks {
+			names = append(names, h.label())
+		}
+		slog.Debug("Executing hooks", "event", event, "tool", hookCtx.ToolName, "path", hookCtx.FilePath, "matched", names)
 	}
 
-	// Execute hooks in parallel (with deduplication)
+	// Formatters first, in order, each on the previous one's output
+	content := hookCtx.Content
+	for _, hook := range contentHooks {
+		result := hm.executeContentHook(ctx, hook, hookCtx, content)
+		modified := result.Decision != HookDeny && result.ModifiedContent != "" && result.ModifiedContent != content
+		hm.recordRun(result, modified)
+		if result.Decision == HookDeny {
+			noteHook(ctx, fmt.Sprintf("blocked by hook '%s'", result.Name))
+			return result, fmt.Errorf("hook denied operation: %s", result.Reason)
+		}
+		if modified {
+			noteHook(ctx, modifiedNote(result.Name, content, result.ModifiedContent))
+		}
+		if result.ModifiedContent != "" {
+			content = result.ModifiedContent
+		}
+	}
+	if content != hookCtx.Content {
+		formatted := *hookCtx
+		formatted.Content = content
+		hookCtx = &formatted
+	}
+
+	// Execute the other hooks in parallel (with deduplication)
 	results := hm.executeHooksParallel(ctx, matchedHooks, hookCtx)
+	for _, result := range results {
+		modified := result.ModifiedContent != "" && result.ModifiedContent != hookCtx.Content
+		hm.recordRun(result, modified)
+		if modified && result.Decision != HookDeny {
+			noteHook(ctx, modifiedNote(result.Name, hookCtx.Content, result.ModifiedContent))
+		}
+	}
 
 	// Aggregate results
-	return hm.aggregateResults(results)
+	aggregated, err := hm.aggregateResults(results)
+	if err != nil && strings.HasPrefix(string(event), "pre-") {
+		noteHook(ctx, fmt.Sprintf("blocked by hook '%s'", aggregated.Name))
+	}
+	if err == nil && aggregated.ModifiedContent == "" && len(contentHooks) > 0 {
+		aggregated.ModifiedContent = content
+	}
+	return aggregated, err
 }
 
 // matchesPattern checks if a tool name matches a pattern.
@@ -314,8 +563,11 @@ func (hm *HookManager) executeHooksParallel(ctx context.Context, hooks []*Hook,
 	uniqueHooks := make(map[string]*Hook)
 	for _, hook := range hooks {
 		key := hook.Command
-		if hook.Type == HookTypeScript {
+		switch hook.Type {
+		case HookTypeScript:
 			key = hook.Script
+		case HookTypeWebhook:
+			key = hook.Method + " " + hook.URL
 		}
 		if _, exists := uniqueHooks[key]; !exists {
 			uniqueHooks[key] = hook
@@ -352,41 +604,21 @@ func (hm *HookManager) executeHooksParallel(ctx context.Context, hooks []*Hook,
 
 // executeHook executes a single hook
 func (hm *HookManager) executeHook(ctx context.Context, hook *Hook, hookCtx *HookContext) *HookResult {
-	startTime := time.Now()
-
-	// Determine timeout
-	timeout := 60 * time.Second
-	if hook.Timeout > 0 {
-		timeout = time.Duration(hook.Timeout) * time.Second
+	if hook.Type == HookTypeWebhook {
+		return hm.executeWebhook(ctx, hook, hookCtx)
 	}
+	startTime := time.Now()
 
 	// Create context with timeout
-	execCtx, cancel := context.WithTimeout(ctx, timeout)
+	execCtx, cancel := context.WithTimeout(ctx, hook.timeout())
 	defer cancel()
-
-	// Prepare command — cross-platform shell dispatch
-	var cmd *exec.Cmd
-	if hook.Type == HookTypeScript {
-		// Execute script file directly
-		cmd = exec.CommandContext(execCtx, hook.Script)
-	} else {
-		// Execute shell command: use cmd.exe on Windows, sh on Linux/macOS
-		if runtime.GOOS == "windows" {
-			cmd = exec.CommandContext(execCtx, "cmd", "/C", hook.Command)
-		} else {
-			cmd = exec.CommandContext(execCtx, "sh", "-c", hook.Command)
-		}
-	}
-
-	// Set working directory
-	if hookCtx.WorkingDir != "" {
-		cmd.Dir = hookCtx.WorkingDir
-	}
+	cmd := hm.buildCommand(execCtx, hook, hookCtx)
 
 	// Prepare hook context as JSON input
 	jsonInput, err := json.Marshal(hookCtx)
 	if err != nil {
 		return &HookResult{
+			Name:     hook.label(),
 			Decision: HookContinue,
 			Reason:   fmt.Sprintf("Failed to marshal hook context: %v", err),
 			ExitCode: -1,
@@ -418,6 +650,7 @@ func (hm *HookManager) executeHook(ctx context.Context, hook *Hook, hookCtx *Hoo
 
 	// Parse result
 	result := hm.parseHookOutput(stdout.String(), stderr.String(), exitCode)
+	result.Name = hook.label()
 	result.Duration = duration
 
 	// Handle FailOnError
@@ -431,6 +664,84 @@ func (hm *HookManager) executeHook(ctx context.Context, hook *Hook, hookCtx *Hoo
 	return result
 }
 
+// buildCommand prepares a hook's process: the script, the program and its
+// Args, or a shell command (cmd.exe on Windows, sh elsewhere)
+func (hm *HookManager) buildCommand(ctx context.Context, hook *Hook, hookCtx *HookContext) *exec.Cmd {
+	var cmd *exec.Cmd
+	switch {
+	case hook.Type == HookTypeScript:
+		cmd = exec.CommandContext(ctx, hook.Script)
+	case len(hook.Args) > 0 || hook.Input == HookInputContent:
+		args := make([]string, len(hook.Args))
+		for i, arg := range hook.Args {
+			args[i] = strings.ReplaceAll(arg, "{file_path}", hookCtx.FilePath)
+		}
+		cmd = exec.CommandContext(ctx, hook.Command, args...)
+	case runtime.GOOS == "windows":
+		cmd = exec.CommandContext(ctx, "cmd", "/C", hook.Command)
+	default:
+		cmd = exec.CommandContext(ctx, "sh", "-c", hook.Command)
+	}
+	cmd.WaitDelay = hookWaitDelay
+
+	cmd.Dir = hookCtx.WorkingDir
+	if hook.WorkingDir != "" {
+		cmd.Dir = hook.WorkingDir
+	}
+	if len(hook.Env) > 0 {
+		cmd.Env = os.Environ()
+		for k, v := range hook.Env {
+			cmd.Env = append(cmd.Env, k+"="+v)
+		}
+	}
+	return cmd
+}
+
+// executeContentHook runs a formatter hook: content on stdin, the formatted
+// content on stdout. Exit 0 allows; a non-zero exit, a failure to start or
+// the timeout denies, with stderr as the reason.
+func (hm *HookManager) executeContentHook(ctx context.Context, hook *Hook, hookCtx *HookContext, content string) *HookResult {
+	startTime := time.Now()
+	timeout := hook.timeout()
+	execCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := hm.buildCommand(execCtx, hook, hookCtx)
+	cmd.Stdin = strings.NewReader(content)
+	var stdout, stderr strings.Builder
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	result := &HookResult{
+		Name:     hook.label(),
+		Decision: HookAllow,
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		Duration: time.Since(startTime),
+	}
+	reason := strings.TrimSpace(stderr.String())
+	switch {
+	case execCtx.Err() == context.DeadlineExceeded:
+		result.Decision = HookDeny
+		result.ExitCode = -1
+		result.Reason = fmt.Sprintf("%s timed out after %v", hook.Command, timeout)
+	case err != nil:
+		result.Decision = HookDeny
+		result.ExitCode = -1
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			result.ExitCode = exitErr.ExitCode()
+		}
+		if reason == "" {
+			reason = err.Error()
+		}
+		result.Reason = fmt.Sprintf("%s failed: %s", hook.Command, reason)
+	default:
+		result.ModifiedContent = stdout.String()
+	}
+	return result
+}
+
 // parseHookOutput parses the output from a hook command
 func (hm *HookManager) parseHookOutput(stdout, stderr string, exitCode int) *HookResult {
 	result := &HookResult{
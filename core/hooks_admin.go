@@ -0,0 +1,479 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// knownHookEvents lists every event the engine fires, in display order
+var knownHookEvents = []HookEvent{
+	HookPreWrite, HookPostWrite, HookPreEdit, HookPostEdit,
+	HookPreDelete, HookPostDelete, HookPreCreate, HookPostCreate,
+	HookPreMove, HookPostMove, HookPreCopy, HookPostCopy,
+	HookPreRead, HookPostRead, HookPreSearch, HookPostSearch,
+}
+
+// defaultHookTool is the tool name hooks(action:"test") matches patterns against when
+// the caller does not name one
+var defaultHookTool = map[HookEvent]string{
+	HookPreWrite: "write_file", HookPostWrite: "write_file",
+	HookPreEdit: "edit_file", HookPostEdit: "edit_file",
+	HookPreDelete: "delete_file", HookPostDelete: "delete_file",
+	HookPreCreate: "create_directory", HookPostCreate: "create_directory",
+	HookPreMove: "move_file", HookPostMove: "move_file",
+	HookPreCopy: "copy_file", HookPostCopy: "copy_file",
+	HookPreRead: "read_file", HookPostRead: "read_file",
+	HookPreSearch: "search_files", HookPostSearch: "search_files",
+}
+
+// ValidateHookConfig returns every problem in config: unknown events, empty
+// patterns, invalid regexes or file globs, hooks with nothing to run.
+func ValidateHookConfig(config *HookConfig) []string {
+	var problems []string
+	known := make(map[HookEvent]bool, len(knownHookEvents))
+	for _, ev := range knownHookEvents {
+		known[ev] = true
+	}
+	for event, matchers := range config.Hooks {
+		if !known[event] {
+			problems = append(problems, fmt.Sprintf("unknown event %q", event))
+			continue
+		}
+		for i, m := range matchers {
+			where := fmt.Sprintf("%s[%d]", event, i)
+			if m == nil {
+				problems = append(problems, where+": empty matcher")
+				continue
+			}
+			if m.Pattern == "" {
+				problems = append(problems, where+": pattern is empty")
+			} else if expr, ok := strings.CutPrefix(m.Pattern, regexPatternPrefix); ok {
+				if _, err := regexp.Compile(expr); err != nil {
+					problems = append(problems, fmt.Sprintf("%s: invalid regex %q: %v", where, expr, err))
+				}
+			}
+			for _, p := range append(append([]string{}, m.Include...), m.Exclude...) {
+				if _, err := path.Match(strings.ReplaceAll(p, "\\", "/"), ""); err != nil || strings.TrimSpace(p) == "" {
+					problems = append(problems, fmt.Sprintf("%s: invalid include/exclude pattern %q", where, p))
+				}
+			}
+			for _, ext := range m.Extensions {
+				if strings.Trim(strings.TrimSpace(ext), ".") == "" {
+					problems = append(problems, fmt.Sprintf("%s: empty extension", where))
+				}
+			}
+			for j, h := range m.Hooks {
+				problems = append(problems, validateHook(fmt.Sprintf("%s.hooks[%d]", where, j), h)...)
+			}
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+func validateHook(where string, h *Hook) []string {
+	if h == nil {
+		return []string{where + ": empty hook"}
+	}
+	var problems []string
+	switch h.Type {
+	case HookTypeCommand, "":
+		if h.Command == "" {
+			problems = append(problems, where+": command is empty")
+		}
+	case HookTypeScript:
+		if h.Script == "" {
+			problems = append(problems, where+": script is empty")
+		}
+	case HookTypeWebhook:
+		if u, err := url.Parse(h.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problems = append(problems, where+": webhook url must be an http(s) URL")
+		}
+		if h.Input == HookInputContent {
+			problems = append(problems, where+": a webhook cannot be a content hook")
+		}
+		if h.MaxContentKB < 0 {
+			problems = append(problems, where+": max_content_kb must not be negative")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("%s: unknown type %q (command, script or webhook)", where, h.Type))
+	}
+	if h.Input != "" && h.Input != HookInputContext && h.Input != HookInputContent {
+		problems = append(problems, fmt.Sprintf("%s: unknown input %q (context or content)", where, h.Input))
+	}
+	if h.Timeout < 0 || h.TimeoutMs < 0 {
+		problems = append(problems, where+": timeout must not be negative")
+	}
+	return problems
+}
+
+// ConfigPath returns the file the hook configuration was loaded from
+func (hm *HookManager) ConfigPath() string {
+	hm.configMutex.RLock()
+	defer hm.configMutex.RUnlock()
+	return hm.configPath
+}
+
+// Reload re-reads the configuration file and swaps it in only when it parses
+// and validates; otherwise the active configuration is kept and the error
+// lists the problems. Returns the number of hooks loaded.
+func (hm *HookManager) Reload(configPath string) (int, error) {
+	if configPath == "" {
+		configPath = hm.ConfigPath()
+	}
+	if configPath == "" {
+		return 0, fmt.Errorf("no hooks config path: start the server with --hooks-config")
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read hook config: %w", err)
+	}
+	var config HookConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return 0, fmt.Errorf("failed to parse hook config: %w", err)
+	}
+	if problems := ValidateHookConfig(&config); len(problems) > 0 {
+		return 0, fmt.Errorf("invalid hook config (%d problems; previous config kept):\n  %s", len(problems), strings.Join(problems, "\n  "))
+	}
+	if config.Hooks == nil {
+		config.Hooks = make(map[HookEvent][]*HookMatcher)
+	}
+
+	hm.configMutex.Lock()
+	hm.config = &config
+	hm.configPath = configPath
+	hm.configMutex.Unlock()
+	// Cached regexes may belong to patterns that are gone
+	compiledRegexCache.Clear()
+
+	count := 0
+	for _, matchers := range config.Hooks {
+		for _, m := range matchers {
+			count += len(m.Hooks)
+		}
+	}
+	return count, nil
+}
+
+// HookEventStatus is one event's matchers as reported by hooks(action:"status")
+type HookEventStatus struct {
+	Event    HookEvent      `json:"event"`
+	Matchers []*HookMatcher `json:"matchers"`
+}
+
+// HooksStatus describes the active hook configuration
+type HooksStatus struct {
+	Enabled    bool              `json:"enabled"`
+	ConfigPath string            `json:"config_path,omitempty"`
+	Events     []HookEventStatus `json:"events"`
+	SecretScan *SecretScanStatus `json:"secret_scan,omitempty"` // nil = off
+}
+
+// SecretScanStatus describes the built-in secret scan
+type SecretScanStatus struct {
+	Allow []string `json:"allow,omitempty"`
+	SecretGuardStats
+}
+
+// Status returns the active configuration, events in firing order
+func (hm *HookManager) Status() HooksStatus {
+	hm.configMutex.RLock()
+	defer hm.configMutex.RUnlock()
+	status := HooksStatus{Enabled: hm.enabled, ConfigPath: hm.configPath}
+	if hm.secretGuard != nil {
+		status.SecretScan = &SecretScanStatus{Allow: hm.secretGuard.AllowPatterns(), SecretGuardStats: hm.secretGuard.Stats()}
+	}
+	for _, ev := range knownHookEvents {
+		if matchers := hm.config.Hooks[ev]; len(matchers) > 0 {
+			status.Events = append(status.Events, HookEventStatus{Event: ev, Matchers: redactMatchers(matchers)})
+		}
+	}
+	return status
+}
+
+// redactMatchers copies matchers for display, hiding webhook secrets and
+// the token-bearing part of webhook URLs
+func redactMatchers(matchers []*HookMatcher) []*HookMatcher {
+	out := make([]*HookMatcher, len(matchers))
+	for i, m := range matchers {
+		if m == nil {
+			continue
+		}
+		copied := *m
+		copied.Hooks = make([]*Hook, len(m.Hooks))
+		for j, h := range m.Hooks {
+			if h == nil {
+				continue
+			}
+			hook := *h
+			if hook.Type == HookTypeWebhook {
+				hook.URL = redactWebhookURL(hook.URL)
+			}
+			if hook.Secret != "" {
+				hook.Secret = "***"
+			}
+			copied.Hooks[j] = &hook
+		}
+		out[i] = &copied
+	}
+	return out
+}
+
+// HookTestResult is what one hook did when run by hooks(action:"test")
+type HookTestResult struct {
+	Pattern     string        `json:"pattern"`
+	Description string        `json:"description,omitempty"`
+	Command     string        `json:"command"`
+	Outcome     string        `json:"outcome"` // allow, deny, modify, continue, skipped
+	Reason      string        `json:"reason,omitempty"`
+	ExitCode    int           `json:"exit_code"`
+	Duration    time.Duration `json:"duration"`
+	Content     string        `json:"modified_content,omitempty"`
+}
+
+// DryRun runs every hook whose matcher accepts hookCtx.ToolName, one at a
+// time and regardless of the enabled flag, and reports each outcome. No
+// file operation follows; hooks see metadata dry_run=true. Content hooks
+// chain as they do for real operations.
+func (hm *HookManager) DryRun(ctx context.Context, event HookEvent, hookCtx *HookContext) []HookTestResult {
+	hm.configMutex.RLock()
+	matchers := hm.config.Hooks[event]
+	hm.configMutex.RUnlock()
+
+	if hookCtx.Metadata == nil {
+		hookCtx.Metadata = map[string]interface{}{}
+	}
+	hookCtx.Metadata["dry_run"] = true
+
+	var results []HookTestResult
+	if guard := hm.SecretGuard(); guard != nil && (event == HookPreWrite || event == HookPreEdit) {
+		r := HookTestResult{Pattern: "*", Command: "secret-scan (built-in)", Outcome: "allow"}
+		denied, scanned := guard.inspect(event, hookCtx)
+		switch {
+		case denied != nil:
+			r.Outcome, r.Reason = "deny", denied.Reason
+		case !scanned:
+			r.Outcome, r.Reason = "skipped", "no content or allowlisted path"
+		}
+		results = append(results, r)
+	}
+	content := hookCtx.Content
+	for _, matcher := range matchers {
+		if !hm.matchesPattern(matcher.Pattern, hookCtx.ToolName) {
+			continue
+		}
+		pathOK := matcher.matchesPath(hookCtx.FilePath)
+		for _, hook := range matcher.Hooks {
+			r := HookTestResult{Pattern: matcher.Pattern, Description: hook.Description, Command: hook.target()}
+			switch {
+			case !pathOK:
+				r.Outcome, r.Reason = "skipped", "path excluded by "+matcher.filterSummary()
+			case !hook.Enabled:
+				r.Outcome, r.Reason = "skipped", "hook disabled"
+			case hook.Input == HookInputContent && hook.Type != HookTypeWebhook && content == "":
+				r.Outcome, r.Reason = "skipped", "content hook and no sample content"
+			case hook.Input == HookInputContent && hook.Type != HookTypeWebhook:
+				res := hm.executeContentHook(ctx, hook, hookCtx, content)
+				r.fill(res, content)
+				if res.Decision != HookDeny && res.ModifiedContent != "" {
+					content = res.ModifiedContent
+				}
+			default:
+				sample := *hookCtx
+				sample.Content = content
+				r.fill(hm.executeHook(ctx, hook, &sample), content)
+			}
+			results = append(results, r)
+		}
+	}
+	return results
+}
+
+// fill records a hook result; content is what the hook was given
+func (r *HookTestResult) fill(res *HookResult, content string) {
+	r.ExitCode, r.Duration, r.Reason = res.ExitCode, res.Duration, res.Reason
+	switch {
+	case res.Decision == HookDeny:
+		r.Outcome = "deny"
+	case res.ModifiedContent != "" && res.ModifiedContent != content:
+		r.Outcome, r.Content = "modify", res.ModifiedContent
+	case res.Decision == HookContinue:
+		r.Outcome = "continue"
+	default:
+		r.Outcome = "allow"
+	}
+}
+
+// DefaultHookTool returns the tool name an event is normally fired for
+func DefaultHookTool(event HookEvent) (string, bool) {
+	tool, ok := defaultHookTool[event]
+	return tool, ok
+}
+
+// FormatHooksStatus renders hooks(action:"status") output
+func FormatHooksStatus(status HooksStatus) string {
+	var sb strings.Builder
+	state := "disabled"
+	if status.Enabled {
+		state = "enabled"
+	}
+	path := status.ConfigPath
+	if path == "" {
+		path = "(none)"
+	}
+	sb.WriteString(fmt.Sprintf("Hooks %s, config: %s\n", state, path))
+	if scan := status.SecretScan; scan != nil {
+		allow := "none"
+		if len(scan.Allow) > 0 {
+			allow = strings.Join(scan.Allow, ",")
+		}
+		sb.WriteString(fmt.Sprintf("Secret scan: on for pre-write/pre-edit (allowlist: %s), %s\n", allow, scan.SecretGuardStats))
+	}
+	if len(status.Events) == 0 {
+		sb.WriteString("No hooks registered")
+		return sb.String()
+	}
+	for _, ev := range status.Events {
+		sb.WriteString(fmt.Sprintf("%s:\n", ev.Event))
+		for _, m := range ev.Matchers {
+			if m.hasPathFilters() {
+				sb.WriteString(fmt.Sprintf("  %s paths: %s\n", m.Pattern, m.filterSummary()))
+			}
+			for _, h := range m.Hooks {
+				mark := "on "
+				if !h.Enabled {
+					mark = "off"
+				}
+				cmd := h.target()
+				if h.Type == HookTypeWebhook && h.Method != "" {
+					cmd = strings.ToUpper(h.Method) + " " + cmd
+				}
+				if len(h.Args) > 0 {
+					cmd += " " + strings.Join(h.Args, " ")
+				}
+				var extra []string
+				if h.Input == HookInputContent {
+					extra = append(extra, "formatter")
+				}
+				if h.FailOnError {
+					extra = append(extra, "failOnError")
+				}
+				if h.Blocking {
+					extra = append(extra, "blocking")
+				}
+				if h.Secret != "" {
+					extra = append(extra, "signed")
+				}
+				extra = append(extra, "timeout "+h.timeout().String())
+				sb.WriteString(fmt.Sprintf("  [%s] %-16s %s (%s)", mark, m.Pattern, cmd, strings.Join(extra, ", ")))
+				if h.Description != "" {
+					sb.WriteString(" — " + h.Description)
+				}
+				sb.WriteString("\n")
+			}
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// FormatHookTestResults renders hooks(action:"test") output
+func FormatHookTestResults(event HookEvent, tool string, results []HookTestResult) string {
+	if len(results) == 0 {
+		return fmt.Sprintf("No hooks match %s for tool %s", event, tool)
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s (%s): %d hook(s), dry run\n", event, tool, len(results)))
+	for _, r := range results {
+		sb.WriteString(fmt.Sprintf("  %-8s %s  [%s]", strings.ToUpper(r.Outcome), r.Command, r.Pattern))
+		if r.Outcome != "skipped" {
+			sb.WriteString(fmt.Sprintf(" exit %d, %v", r.ExitCode, r.Duration.Round(time.Millisecond)))
+		}
+		if r.Reason != "" {
+			sb.WriteString(" — " + strings.TrimSpace(r.Reason))
+		}
+		sb.WriteString("\n")
+		if r.Content != "" {
+			sb.WriteString("    modified content:\n")
+			for _, line := range strings.Split(strings.TrimRight(r.Content, "\n"), "\n") {
+				sb.WriteString("    | " + line + "\n")
+			}
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// HooksStatus reports the active hook configuration for hooks(action:"status")
+func (e *UltraFastEngine) HooksStatus() HooksStatus {
+	return e.hookManager.Status()
+}
+
+// ReloadHooks re-reads the hooks config file for hooks(action:"reload"). A config that
+// does not parse or validate leaves the active one in place. A successful
+// reload enables hooks when the server was started with them enabled, so a
+// config that failed at startup can be fixed without a restart.
+func (e *UltraFastEngine) ReloadHooks() (int, error) {
+	count, err := e.hookManager.Reload(e.config.HooksConfigPath)
+	if err != nil {
+		return 0, err
+	}
+	if e.config.HooksEnabled {
+		e.hookManager.SetEnabled(true)
+	}
+	return count, nil
+}
+
+// TestHooks runs the hooks matching event for tool (default: the event's
+// usual tool) against path and sample content without performing any file
+// operation, for hooks(action:"test")
+func (e *UltraFastEngine) TestHooks(ctx context.Context, event HookEvent, tool, path, content string) (string, []HookTestResult, error) {
+	defaultTool, ok := DefaultHookTool(event)
+	if !ok {
+		names := make([]string, len(knownHookEvents))
+		for i, ev := range knownHookEvents {
+			names[i] = string(ev)
+		}
+		return "", nil, fmt.Errorf("unknown event %q. Valid: %s", event, strings.Join(names, ", "))
+	}
+	if tool == "" {
+		tool = defaultTool
+	}
+	path = NormalizePath(path)
+	_, operation, _ := strings.Cut(string(event), "-") // "pre-write" -> "write"
+	workingDir, _ := os.Getwd()
+	hookCtx := &HookContext{
+		Event:      event,
+		ToolName:   tool,
+		FilePath:   path,
+		Operation:  operation,
+		Content:    content,
+		Timestamp:  time.Now(),
+		WorkingDir: workingDir,
+	}
+	if event == HookPreEdit {
+		hookCtx.NewContent = content // the sample is the edit's new text
+	}
+	return tool, e.hookManager.DryRun(ctx, event, hookCtx), nil
+}
+
+// filterSummary describes the matcher's path filters
+func (m *HookMatcher) filterSummary() string {
+	var parts []string
+	if len(m.Extensions) > 0 {
+		parts = append(parts, "extensions "+strings.Join(m.Extensions, ","))
+	}
+	if len(m.Include) > 0 {
+		parts = append(parts, "include "+strings.Join(m.Include, ","))
+	}
+	if len(m.Exclude) > 0 {
+		parts = append(parts, "exclude "+strings.Join(m.Exclude, ","))
+	}
+	return strings.Join(parts, "; ")
+}
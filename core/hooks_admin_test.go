@@ -0,0 +1,109 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestHooksReload_KeepsActiveConfigOnErrors(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "hooks.json")
+	write := func(body string) {
+		t.Helper()
+		if err := os.WriteFile(configPath, []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`{"hooks": {"pre-write": [{"pattern": "write_file", "hooks": [{"type": "command", "command": "true", "enabled": true}]}]}}`)
+
+	hm := NewHookManager()
+	if err := hm.LoadConfig(configPath); err != nil {
+		t.Fatal(err)
+	}
+
+	// Invalid JSON, then a config that parses but does not validate
+	write(`{"hooks": `)
+	if _, err := hm.Reload(""); err == nil || !strings.Contains(err.Error(), "parse") {
+		t.Errorf("reload of broken JSON: err = %v", err)
+	}
+	write(`{"hooks": {"pre-wirte": [], "pre-delete": [{"pattern": "re:(", "hooks": [{"type": "command", "command": "", "enabled": true}]}]}}`)
+	_, err := hm.Reload("")
+	if err == nil {
+		t.Fatal("invalid config was accepted")
+	}
+	for _, want := range []string{`unknown event "pre-wirte"`, "invalid regex", "command is empty", "previous config kept"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("reload error missing %q:\n%v", want, err)
+		}
+	}
+	status := hm.Status()
+	if len(status.Events) != 1 || status.Events[0].Event != HookPreWrite || status.ConfigPath != configPath {
+		t.Errorf("active config changed after failed reloads: %+v", status)
+	}
+
+	// A valid edit replaces it
+	write(`{"hooks": {"pre-delete": [{"pattern": "*", "hooks": [{"type": "command", "command": "true", "enabled": true}, {"type": "command", "command": "false", "enabled": false}]}]}}`)
+	count, err := hm.Reload("")
+	if err != nil || count != 2 {
+		t.Fatalf("reload = %d, %v", count, err)
+	}
+	status = hm.Status()
+	if len(status.Events) != 1 || status.Events[0].Event != HookPreDelete {
+		t.Errorf("status after reload = %+v", status)
+	}
+	text := FormatHooksStatus(status)
+	if !strings.Contains(text, "Hooks enabled, config: "+configPath) || !strings.Contains(text, "[off]") {
+		t.Errorf("status text:\n%s", text)
+	}
+}
+
+func TestHookTest_ReportsEachHookWithoutOperating(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands use sh")
+	}
+	engine, dir := setupProgressEngine(t)
+	hm := engine.hookManager
+	hm.AddHook(HookPreWrite, "write_file", &Hook{Type: HookTypeCommand, Command: "tr", Args: []string{"a-z", "A-Z"}, Input: HookInputContent, Enabled: true})
+	hm.AddHook(HookPreWrite, "write_file", &Hook{Type: HookTypeCommand, Command: `grep -q '"dry_run":true' || exit 2`, Enabled: true})
+	hm.AddHook(HookPreWrite, "re:^write_file$", &Hook{Type: HookTypeCommand, Command: "true", Enabled: true})
+	hm.config.Hooks[HookPreWrite][1].Extensions = []string{"py"}
+	hm.AddHook(HookPreDelete, "*", &Hook{Type: HookTypeCommand, Command: `echo protected >&2; exit 2`, Enabled: true})
+	// Hooks stay disabled: hooks(action:"test") still runs them
+
+	path := filepath.Join(dir, "main.go")
+	tool, results, err := engine.TestHooks(context.Background(), HookPreWrite, "", path, "package main\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tool != "write_file" || len(results) != 3 {
+		t.Fatalf("tool %s, results %+v", tool, results)
+	}
+	if results[0].Outcome != "modify" || results[0].Content != "PACKAGE MAIN\n" {
+		t.Errorf("formatter result = %+v", results[0])
+	}
+	if results[1].Outcome != "allow" {
+		t.Errorf("context hook did not see dry_run: %+v", results[1])
+	}
+	if results[2].Outcome != "skipped" || !strings.Contains(results[2].Reason, "path excluded") {
+		t.Errorf("*.py hook ran for main.go: %+v", results[2])
+	}
+	if _, err := os.Stat(path); err == nil {
+		t.Error("hooks test wrote the file")
+	}
+
+	_, results, _ = engine.TestHooks(context.Background(), HookPreDelete, "", path, "")
+	if len(results) != 1 || results[0].Outcome != "deny" || !strings.Contains(results[0].Reason, "protected") {
+		t.Errorf("pre-delete results = %+v", results)
+	}
+	if text := FormatHookTestResults(HookPreDelete, "delete_file", results); !strings.Contains(text, "DENY") {
+		t.Errorf("formatted results:\n%s", text)
+	}
+
+	if _, _, err := engine.TestHooks(context.Background(), "pre-nothing", "", path, ""); err == nil {
+		t.Error("unknown event accepted")
+	}
+}
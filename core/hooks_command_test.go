@@ -0,0 +1,95 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeFormatter writes a formatter script: "upper <file>" prints a header
+// line ($FMT_PREFIX and the file name) then stdin upper-cased, "fail" exits 3
+// with a message on stderr, "hang" never finishes.
+func fakeFormatter(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake formatter is a shell script")
+	}
+	script := filepath.Join(t.TempDir(), "fmt.sh")
+	body := `#!/bin/sh
+case "$1" in
+  fail) cat >/dev/null; echo "syntax error on line 1" >&2; exit 3 ;;
+  hang) sleep 10 ;;
+esac
+printf '%s%s\n' "$FMT_PREFIX" "$(basename "$2")"
+tr a-z A-Z
+`
+	if err := os.WriteFile(script, []byte(body), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return script
+}
+
+func TestContentHook_FormatsWrites(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	script := fakeFormatter(t)
+	engine.hookManager.AddHook(HookPreWrite, "write_file", &Hook{
+		Type:    HookTypeCommand,
+		Command: script,
+		Args:    []string{"upper", "{file_path}"},
+		Input:   HookInputContent,
+		Env:     map[string]string{"FMT_PREFIX": "// "},
+		Enabled: true,
+	})
+	engine.hookManager.config.Hooks[HookPreWrite][0].Extensions = []string{"go"}
+	engine.hookManager.SetEnabled(true)
+	ctx := context.Background()
+
+	path := filepath.Join(dir, "main.go")
+	if err := engine.WriteFileContent(ctx, path, "package main\n"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "// main.go\nPACKAGE MAIN\n" {
+		t.Errorf("formatted content = %q", got)
+	}
+
+	// Files outside the matcher's extensions are written untouched
+	notes := filepath.Join(dir, "notes.txt")
+	engine.WriteFileContent(ctx, notes, "keep me\n")
+	if got, _ := os.ReadFile(notes); string(got) != "keep me\n" {
+		t.Errorf("unmatched file content = %q", got)
+	}
+}
+
+func TestContentHook_FailureAndTimeoutDeny(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	script := fakeFormatter(t)
+	hook := &Hook{Type: HookTypeCommand, Command: script, Args: []string{"fail"}, Input: HookInputContent, Enabled: true}
+	engine.hookManager.AddHook(HookPreWrite, "write_file", hook)
+	engine.hookManager.SetEnabled(true)
+	ctx := context.Background()
+
+	path := filepath.Join(dir, "main.go")
+	err := engine.WriteFileContent(ctx, path, "package main\n")
+	if err == nil || !strings.Contains(err.Error(), "syntax error on line 1") {
+		t.Fatalf("err = %v, want denial carrying the formatter's stderr", err)
+	}
+	if _, statErr := os.Stat(path); statErr == nil {
+		t.Error("denied write created the file")
+	}
+
+	// A hung formatter is killed at timeout_ms and the write is denied
+	hook.Args = []string{"hang"}
+	hook.TimeoutMs = 200
+	start := time.Now()
+	err = engine.WriteFileContent(ctx, path, "package main\n")
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("err = %v, want a timeout denial", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("hung formatter held the write for %v", elapsed)
+	}
+}
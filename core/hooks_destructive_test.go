@@ -0,0 +1,85 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// protectEnvFiles installs a pre-delete and pre-move hook that denies any
+// operation whose context names a *.env file (exit code 2 = deny)
+func protectEnvFiles(t *testing.T, engine *UltraFastEngine) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("hook command uses sh")
+	}
+	hook := &Hook{
+		Type:    HookTypeCommand,
+		Command: `grep -q '\.env"' && { echo "env files are protected" >&2; exit 2; }; exit 0`,
+		Enabled: true,
+	}
+	engine.hookManager.AddHook(HookPreDelete, "*", hook)
+	engine.hookManager.AddHook(HookPreMove, "*", hook)
+	engine.hookManager.SetEnabled(true)
+}
+
+func TestHooks_DenyDestructiveOperationsOnEnvFiles(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	protectEnvFiles(t, engine)
+	ctx := context.Background()
+
+	secret := filepath.Join(dir, "prod.env")
+	os.WriteFile(secret, []byte("TOKEN=x\n"), 0644)
+	stillThere := func(what string) {
+		t.Helper()
+		if _, err := os.Stat(secret); err != nil {
+			t.Fatalf("%s removed the file despite the denial", what)
+		}
+	}
+	assertKept := func(what string, err error) {
+		t.Helper()
+		if err == nil || !strings.Contains(err.Error(), "env files are protected") {
+			t.Errorf("%s: err = %v, want hook denial", what, err)
+		}
+		stillThere(what)
+	}
+
+	assertKept("delete_file", engine.DeleteFile(ctx, secret))
+	_, err := engine.SoftDeleteFile(ctx, secret)
+	assertKept("soft delete", err)
+	assertKept("move_file", engine.MoveFile(ctx, secret, filepath.Join(dir, "moved", "prod.env.bak")))
+	if _, err := os.Stat(filepath.Join(dir, "moved")); err == nil {
+		t.Error("denied move created the destination directory")
+	}
+	assertKept("rename", engine.RenameFile(ctx, secret, filepath.Join(dir, "prod.txt")))
+
+	mgr := NewBatchOperationManager(t.TempDir(), 10)
+	mgr.SetEngine(engine)
+	res := mgr.ExecuteBatch(BatchRequest{Operations: []FileOperation{{Type: "delete", Path: secret}}})
+	if res.Success {
+		t.Error("batch delete of a .env file succeeded")
+	}
+	stillThere("batch delete")
+
+	renamed, err := engine.BatchRenameFiles(ctx, BatchRenameRequest{Path: dir, Mode: "add_suffix", Suffix: ".old"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if renamed.ErrorCount != 1 || !strings.Contains(strings.Join(renamed.Errors, "\n"), "env files are protected") {
+		t.Errorf("batch rename result = %+v", renamed)
+	}
+	stillThere("batch rename")
+
+	// Other files are unaffected
+	plain := filepath.Join(dir, "notes.txt")
+	os.WriteFile(plain, []byte("x"), 0644)
+	if err := engine.RenameFile(ctx, plain, filepath.Join(dir, "notes.md")); err != nil {
+		t.Errorf("rename of a plain file: %v", err)
+	}
+	if err := engine.DeleteFile(ctx, filepath.Join(dir, "notes.md")); err != nil {
+		t.Errorf("delete of a plain file: %v", err)
+	}
+}
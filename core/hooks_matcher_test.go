@@ -0,0 +1,87 @@
+package core
+
+import (
+	"context"
+	"runtime"
+	"testing"
+)
+
+func TestHookMatcher_PathFilters(t *testing.T) {
+	cases := []struct {
+		name    string
+		matcher HookMatcher
+		path    string
+		want    bool
+	}{
+		{"no filters match everything", HookMatcher{}, "/p/README.md", true},
+		{"no filters match an empty path", HookMatcher{}, "", true},
+		{"extension with dot", HookMatcher{Extensions: []string{".go"}}, "/p/main.go", true},
+		{"extension without dot", HookMatcher{Extensions: []string{"go"}}, "/p/main.go", true},
+		{"extension is case-insensitive", HookMatcher{Extensions: []string{"md"}}, "/p/README.MD", true},
+		{"other extension", HookMatcher{Extensions: []string{"go"}}, "/p/README.md", false},
+		{"no extension", HookMatcher{Extensions: []string{"go"}}, "/p/Makefile", false},
+		{"extension filter and empty path", HookMatcher{Extensions: []string{"go"}}, "", false},
+		{"include name glob", HookMatcher{Include: []string{"*_test.go"}}, "/p/a_test.go", true},
+		{"include misses", HookMatcher{Include: []string{"*_test.go"}}, "/p/a.go", false},
+		{"include directory at any depth", HookMatcher{Include: []string{"cmd/**"}}, "/p/cmd/tool/main.go", true},
+		{"include trailing segments", HookMatcher{Include: []string{"cmd/*.go"}}, "/p/cmd/main.go", true},
+		{"exclude directory", HookMatcher{Extensions: []string{"go"}, Exclude: []string{"vendor/**"}}, "/p/vendor/x/y.go", false},
+		{"exclude leaves others", HookMatcher{Extensions: []string{"go"}, Exclude: []string{"vendor/**"}}, "/p/internal/y.go", true},
+		{"exclude only, empty path", HookMatcher{Exclude: []string{"*.md"}}, "", true},
+		{"windows separators", HookMatcher{Include: []string{"cmd/**"}, Extensions: []string{"go"}}, `C:\proj\cmd\main.go`, true},
+		{"windows exclude", HookMatcher{Extensions: []string{"go"}, Exclude: []string{"vendor/**"}}, `C:\proj\vendor\lib.go`, false},
+		{"windows pattern separators", HookMatcher{Include: []string{`cmd\*.go`}}, `C:\proj\cmd\main.go`, true},
+		{"windows extension", HookMatcher{Extensions: []string{"md"}}, `D:\docs\guide.md`, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.matcher.matchesPath(tc.path); got != tc.want {
+				t.Errorf("matchesPath(%q) = %v, want %v", tc.path, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExecuteHooks_SkipsMatchersFilteredByPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook command uses sh")
+	}
+	hm := NewHookManager()
+	hm.SetEnabled(true)
+	hm.configMutex.Lock()
+	hm.config.Hooks[HookPreWrite] = []*HookMatcher{{
+		Pattern:    "write_file",
+		Extensions: []string{"go"},
+		Exclude:    []string{"vendor/**"},
+		Hooks:      []*Hook{{Type: HookTypeCommand, Command: "exit 2", Enabled: true}},
+	}}
+	hm.configMutex.Unlock()
+
+	deny := func(path string) bool {
+		_, err := hm.ExecuteHooks(context.Background(), HookPreWrite, &HookContext{ToolName: "write_file", FilePath: path})
+		return err != nil
+	}
+	if !deny("/p/main.go") {
+		t.Error("hook did not run for a .go file")
+	}
+	if deny("/p/README.md") {
+		t.Error("Go hook ran for Markdown")
+	}
+	if deny("/p/vendor/lib/x.go") {
+		t.Error("hook ran for an excluded path")
+	}
+}
+
+func TestValidateHookConfig_PathFilters(t *testing.T) {
+	problems := ValidateHookConfig(&HookConfig{Hooks: map[HookEvent][]*HookMatcher{
+		HookPreWrite: {{
+			Pattern:    "*",
+			Include:    []string{"[bad"},
+			Extensions: []string{"."},
+			Hooks:      []*Hook{{Type: HookTypeCommand, Command: "true"}},
+		}},
+	}})
+	if len(problems) != 2 {
+		t.Errorf("problems = %q, want the bad include and the empty extension", problems)
+	}
+}
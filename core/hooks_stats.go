@@ -0,0 +1,107 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// HookExecStats is one hook's activity since startup, keyed by its name
+// (description, else command). hooks(action:"test") dry runs are not counted.
+type HookExecStats struct {
+	Name     string        `json:"name"`
+	Runs     int64         `json:"runs"`
+	Denied   int64         `json:"denied"`
+	Modified int64         `json:"modified"`
+	Duration time.Duration `json:"duration"` // cumulative
+}
+
+// recordRun counts one hook execution
+func (hm *HookManager) recordRun(result *HookResult, modified bool) {
+	hm.statsMu.Lock()
+	defer hm.statsMu.Unlock()
+	if hm.execStats == nil {
+		hm.execStats = make(map[string]*HookExecStats)
+	}
+	st := hm.execStats[result.Name]
+	if st == nil {
+		st = &HookExecStats{Name: result.Name}
+		hm.execStats[result.Name] = st
+	}
+	st.Runs++
+	st.Duration += result.Duration
+	if result.Decision == HookDeny {
+		st.Denied++
+	}
+	if modified {
+		st.Modified++
+	}
+}
+
+// ExecStats returns per-hook execution counters, busiest first
+func (hm *HookManager) ExecStats() []HookExecStats {
+	hm.statsMu.Lock()
+	defer hm.statsMu.Unlock()
+	out := make([]HookExecStats, 0, len(hm.execStats))
+	for _, st := range hm.execStats {
+		out = append(out, *st)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Duration != out[j].Duration {
+			return out[i].Duration > out[j].Duration
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// formatHookExecStats renders the performance_stats hook section: a total
+// line and one line per hook ("" when no hook has run)
+func formatHookExecStats(stats []HookExecStats) string {
+	if len(stats) == 0 {
+		return ""
+	}
+	var runs int64
+	var total time.Duration
+	var lines strings.Builder
+	for _, st := range stats {
+		runs += st.Runs
+		total += st.Duration
+		lines.WriteString(fmt.Sprintf("\n  %s: %d runs, %v", st.Name, st.Runs, st.Duration.Round(time.Millisecond)))
+		if st.Modified > 0 {
+			lines.WriteString(fmt.Sprintf(", %d modified", st.Modified))
+		}
+		if st.Denied > 0 {
+			lines.WriteString(fmt.Sprintf(", %d denied", st.Denied))
+		}
+	}
+	return fmt.Sprintf("\nHooks: %d runs, %v total%s", runs, total.Round(time.Millisecond), lines.String())
+}
+
+// noteHook records a hook outcome on the current audit entry so the tool
+// response can mention it. Safe without an audit entry (no-op).
+func noteHook(ctx context.Context, note string) {
+	if entry, ok := ctx.Value(AuditEntryKey{}).(*AuditEntry); ok {
+		entry.Hooks = append(entry.Hooks, note)
+	}
+}
+
+// HookNotes returns the hook outcomes recorded for the current tool call
+func HookNotes(ctx context.Context) []string {
+	if entry, ok := ctx.Value(AuditEntryKey{}).(*AuditEntry); ok {
+		return entry.Hooks
+	}
+	return nil
+}
+
+// modifiedNote describes a hook's rewrite: "gofmt modified content (+3/-1
+// lines)"; without the content it replaced (pre-edit) the counts are left out
+func modifiedNote(name, before, after string) string {
+	if before == "" {
+		return name + " modified content"
+	}
+	added, removed := countChanges(splitLines(before), splitLines(after))
+	return fmt.Sprintf("%s modified content (+%d/-%d lines)", name, added, removed)
+}
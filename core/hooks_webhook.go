@@ -0,0 +1,158 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// defaultWebhookTimeout applies to webhook hooks that set no timeout
+const defaultWebhookTimeout = 10 * time.Second
+
+// webhookRetryDelay is the pause before the single retry after a network error
+const webhookRetryDelay = 250 * time.Millisecond
+
+// webhookSignatureHeader carries the HMAC-SHA256 of the body when the hook
+// has a secret, GitHub style: "sha256=<hex>"
+const webhookSignatureHeader = "X-Hook-Signature-256"
+
+// webhookClient sends every webhook; each request carries its own deadline
+var webhookClient = &http.Client{}
+
+// webhookPayload is the body of a webhook request: the HookContext plus a
+// one-line summary, which chat webhooks (Slack, Mattermost) display
+type webhookPayload struct {
+	HookContext
+	Text             string `json:"text"`
+	ContentTruncated bool   `json:"content_truncated,omitempty"`
+}
+
+// newWebhookPayload copies hookCtx, dropping the content fields or capping
+// each at maxKB kilobytes
+func newWebhookPayload(hookCtx *HookContext, maxKB int) webhookPayload {
+	p := webhookPayload{HookContext: *hookCtx}
+	limit := maxKB * 1024
+	for _, field := range []*string{&p.Content, &p.OldContent, &p.NewContent} {
+		if len(*field) > limit {
+			cut := limit
+			for cut > 0 && !utf8.RuneStart((*field)[cut]) {
+				cut--
+			}
+			*field = (*field)[:cut]
+			p.ContentTruncated = limit > 0
+		}
+	}
+	p.Text = fmt.Sprintf("%s: %s %s", p.Event, p.ToolName, p.FilePath)
+	if p.DestPath != "" && p.DestPath != p.FilePath {
+		p.Text += " -> " + p.DestPath
+	}
+	return p
+}
+
+// executeWebhook sends the hook context to the hook's URL, retrying once
+// after a network error. Only a Blocking hook on a pre-* event can deny;
+// every other failure is logged and the operation continues.
+func (hm *HookManager) executeWebhook(ctx context.Context, hook *Hook, hookCtx *HookContext) *HookResult {
+	startTime := time.Now()
+	body, err := json.Marshal(newWebhookPayload(hookCtx, hook.MaxContentKB))
+	if err != nil {
+		return &HookResult{Name: hook.label(), Decision: HookContinue, Reason: fmt.Sprintf("Failed to marshal hook context: %v", err), ExitCode: -1}
+	}
+
+	status, respBody, err := hm.sendWebhook(ctx, hook, body)
+	if err != nil && ctx.Err() == nil && isWebhookNetworkError(err) {
+		select {
+		case <-time.After(webhookRetryDelay):
+			status, respBody, err = hm.sendWebhook(ctx, hook, body)
+		case <-ctx.Done():
+		}
+	}
+
+	result := &HookResult{Name: hook.label(), Decision: HookAllow, Stdout: respBody, Duration: time.Since(startTime)}
+	target := redactWebhookURL(hook.URL)
+	switch {
+	case err != nil:
+		result.ExitCode = -1
+		result.Reason = fmt.Sprintf("webhook %s failed: %v", target, err)
+	case status < 200 || status > 299:
+		result.ExitCode = status
+		result.Reason = fmt.Sprintf("webhook %s returned HTTP %d", target, status)
+		if msg := strings.TrimSpace(respBody); msg != "" {
+			result.Reason += ": " + truncateText(msg, 200)
+		}
+	default:
+		return result
+	}
+
+	if hook.Blocking && strings.HasPrefix(string(hookCtx.Event), "pre-") {
+		result.Decision = HookDeny
+		return result
+	}
+	slog.Warn("Webhook hook failed", "event", hookCtx.Event, "path", hookCtx.FilePath, "reason", result.Reason)
+	result.Decision = HookContinue
+	return result
+}
+
+// sendWebhook makes one request and returns the status and (capped) body
+func (hm *HookManager) sendWebhook(ctx context.Context, hook *Hook, body []byte) (int, string, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, hook.timeout())
+	defer cancel()
+
+	method := hook.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequestWithContext(reqCtx, strings.ToUpper(method), hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "mcp-filesystem-ultra-hooks")
+	if secret := os.ExpandEnv(hook.Secret); secret != "" {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		req.Header.Set(webhookSignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return resp.StatusCode, string(respBody), nil
+}
+
+// isWebhookNetworkError reports whether err is worth a retry: the request
+// went out but got no response (connection refused or reset, DNS, timeout),
+// as opposed to a URL that does not parse
+func isWebhookNetworkError(err error) bool {
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) && urlErr.Op != "parse"
+}
+
+// redactWebhookURL keeps the scheme and host of a webhook URL; the path and
+// query often carry the token (Slack, Discord)
+func redactWebhookURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "webhook"
+	}
+	if u.Path == "" || u.Path == "/" {
+		return u.Scheme + "://" + u.Host
+	}
+	return u.Scheme + "://" + u.Host + "/…"
+}
@@ -0,0 +1,173 @@
+package core
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// webhookRecorder is a test endpoint that keeps every request body and
+// signature and answers with status
+type webhookRecorder struct {
+	mu         sync.Mutex
+	bodies     [][]byte
+	signatures []string
+	status     int
+}
+
+func (rec *webhookRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	rec.mu.Lock()
+	rec.bodies = append(rec.bodies, body)
+	rec.signatures = append(rec.signatures, r.Header.Get(webhookSignatureHeader))
+	status := rec.status
+	rec.mu.Unlock()
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	io.WriteString(w, "frozen for release\n")
+}
+
+func (rec *webhookRecorder) last(t *testing.T) (webhookPayload, string) {
+	t.Helper()
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if len(rec.bodies) == 0 {
+		t.Fatal("webhook was not called")
+	}
+	var p webhookPayload
+	if err := json.Unmarshal(rec.bodies[len(rec.bodies)-1], &p); err != nil {
+		t.Fatal(err)
+	}
+	return p, rec.signatures[len(rec.signatures)-1]
+}
+
+func TestWebhookHook_PostWriteSendsSignedContext(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	rec := &webhookRecorder{}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	t.Setenv("TEST_WEBHOOK_SECRET", "s3cret")
+	hook := &Hook{Type: HookTypeWebhook, URL: srv.URL + "/services/T0/B0/token", Secret: "${TEST_WEBHOOK_SECRET}", Enabled: true}
+	engine.hookManager.AddHook(HookPostWrite, "write_file", hook)
+	engine.hookManager.SetEnabled(true)
+	ctx := context.Background()
+
+	path := filepath.Join(dir, "notes.txt")
+	if err := engine.WriteFileContent(ctx, path, "hello\n"); err != nil {
+		t.Fatal(err)
+	}
+	p, sig := rec.last(t)
+	if p.Event != HookPostWrite || p.FilePath != path || p.Content != "" || !strings.Contains(p.Text, "notes.txt") {
+		t.Errorf("payload = %+v", p)
+	}
+	rec.mu.Lock()
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(rec.bodies[0])
+	rec.mu.Unlock()
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); sig != want {
+		t.Errorf("signature = %q, want %q", sig, want)
+	}
+
+	// With max_content_kb the content is sent, capped
+	hook.MaxContentKB = 1
+	if err := engine.WriteFileContent(ctx, path, strings.Repeat("x", 3000)); err != nil {
+		t.Fatal(err)
+	}
+	if p, _ = rec.last(t); len(p.Content) != 1024 || !p.ContentTruncated {
+		t.Errorf("capped content: %d bytes, truncated=%v", len(p.Content), p.ContentTruncated)
+	}
+
+	// Status never shows the secret or the token in the URL
+	text := FormatHooksStatus(engine.HooksStatus())
+	data, _ := json.Marshal(engine.HooksStatus())
+	for _, out := range []string{text, string(data)} {
+		if strings.Contains(out, "s3cret") || strings.Contains(out, "TEST_WEBHOOK_SECRET") || strings.Contains(out, "token") {
+			t.Errorf("status leaks the webhook secret or URL:\n%s", out)
+		}
+	}
+}
+
+func TestWebhookHook_FailuresOnlyDenyBlockingPreEvents(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	rec := &webhookRecorder{status: http.StatusForbidden}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	hook := &Hook{Type: HookTypeWebhook, URL: srv.URL, Enabled: true}
+	engine.hookManager.AddHook(HookPreWrite, "write_file", hook)
+	engine.hookManager.AddHook(HookPostWrite, "write_file", &Hook{Type: HookTypeWebhook, URL: srv.URL, Blocking: true, Enabled: true})
+	engine.hookManager.SetEnabled(true)
+	ctx := context.Background()
+	path := filepath.Join(dir, "a.txt")
+
+	// Non-blocking pre-write and blocking post-write: logged, not fatal
+	if err := engine.WriteFileContent(ctx, path, "one\n"); err != nil {
+		t.Fatalf("failed webhooks failed the write: %v", err)
+	}
+
+	hook.Blocking = true
+	err := engine.WriteFileContent(ctx, path, "two\n")
+	if err == nil || !strings.Contains(err.Error(), "HTTP 403: frozen for release") {
+		t.Fatalf("err = %v, want a denial carrying the response", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "one\n" {
+		t.Errorf("denied write changed the file: %q", got)
+	}
+
+	// An unreachable blocking webhook denies too
+	srv.Close()
+	if err := engine.WriteFileContent(ctx, path, "three\n"); err == nil || !strings.Contains(err.Error(), "failed") {
+		t.Errorf("unreachable blocking webhook: err = %v", err)
+	}
+}
+
+func TestWebhookHook_RetriesOnceAfterNetworkError(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			// Drop the connection without a response
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	hm := NewHookManager()
+	hm.SetEnabled(true)
+	hm.AddHook(HookPreEdit, "*", &Hook{Type: HookTypeWebhook, URL: srv.URL, Blocking: true, Enabled: true})
+	if _, err := hm.ExecuteHooks(context.Background(), HookPreEdit, &HookContext{ToolName: "edit_file", FilePath: "/p/a.go"}); err != nil {
+		t.Fatalf("retry did not recover: %v", err)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("webhook called %d times, want 2", n)
+	}
+}
+
+func TestValidateHookConfig_Webhooks(t *testing.T) {
+	problems := ValidateHookConfig(&HookConfig{Hooks: map[HookEvent][]*HookMatcher{
+		HookPostEdit: {{Pattern: "*", Hooks: []*Hook{
+			{Type: HookTypeWebhook, URL: "https://hooks.example.com/x"},
+			{Type: HookTypeWebhook, URL: "ftp://example.com"},
+			{Type: HookTypeWebhook, URL: "https://example.com", Input: HookInputContent},
+		}}},
+	}})
+	if len(problems) != 2 {
+		t.Errorf("problems = %q, want the ftp URL and the content webhook", problems)
+	}
+}
@@ -2,6 +2,8 @@
 
 import (
 	"fmt"
+	"path"
+	"path/filepath"
 	"strings"
 )
 
@@ -22,6 +24,117 @@ type RiskThresholds struct {
 	HighPercentage    float64
 	MediumOccurrences int
 	HighOccurrences   int
+
+	// CriticalFiles extiende builtinCriticalFiles con patrones propios
+	// (--critical-files). Mismo formato que la lista integrada.
+	CriticalFiles []string
+}
+
+// builtinCriticalFiles lista los archivos de proyecto cuyo impacto no se mide
+// por porcentaje: un cambio de una línea en go.mod o en una migración SQL
+// puede romper el build o la base de datos. Cualquier edit/write/delete que
+// los toque sube al menos un nivel de riesgo.
+//
+// Formato de patrón:
+//   - sin "/": glob contra el nombre base ("go.mod", "Dockerfile.*")
+//   - "dir/**": cualquier ruta que contenga el directorio "dir"
+//   - "dir/*.ext": glob contra los últimos segmentos de la ruta
+var builtinCriticalFiles = []string{
+	"go.mod", "go.sum",
+	"package.json", "package-lock.json", "yarn.lock", "pnpm-lock.yaml",
+	"Cargo.toml", "Cargo.lock",
+	"Dockerfile", "Dockerfile.*",
+	".git/**",
+	"migrations/*.sql",
+}
+
+// MatchCriticalFile returns a short label for filePath if it matches the
+// built-in critical file list or one of the extra patterns, or "" otherwise.
+// The label is the base name for name patterns ("go.mod") and the pattern
+// itself for directory patterns (".git/**").
+func MatchCriticalFile(filePath string, extra []string) string {
+	if filePath == "" {
+		return ""
+	}
+	slashed := filepath.ToSlash(filepath.Clean(filePath))
+	segments := strings.Split(slashed, "/")
+
+	patterns := builtinCriticalFiles
+	if len(extra) > 0 {
+		patterns = append(append([]string{}, builtinCriticalFiles...), extra...)
+	}
+
+	for _, pattern := range patterns {
+		if matched := matchPathPattern(segments, pattern); matched != "" {
+			return matched
+		}
+	}
+	return ""
+}
+
+// matchPathPattern aplica un patrón de ruta a los segmentos de un path
+// (separados por "/"). Sintaxis: "name.ext" o "*.ext" contra el nombre base,
+// "dir/**" si el directorio aparece en cualquier nivel, y "a/*.sql" contra
+// los últimos segmentos. Devuelve la parte que coincidió, o "".
+func matchPathPattern(segments []string, pattern string) string {
+	pattern = strings.TrimSpace(filepath.ToSlash(pattern))
+	if pattern == "" || len(segments) == 0 {
+		return ""
+	}
+	base := segments[len(segments)-1]
+
+	// "dir/**": the directory appears anywhere in the path
+	if dir, ok := strings.CutSuffix(pattern, "/**"); ok {
+		for _, seg := range segments[:len(segments)-1] {
+			if seg == dir {
+				return pattern
+			}
+		}
+		return ""
+	}
+
+	// Name-only pattern: glob against the base name
+	if !strings.Contains(pattern, "/") {
+		if ok, _ := path.Match(pattern, base); ok {
+			return base
+		}
+		return ""
+	}
+
+	// Multi-segment pattern: glob against the trailing segments
+	n := strings.Count(pattern, "/") + 1
+	if n > len(segments) {
+		return ""
+	}
+	tail := strings.Join(segments[len(segments)-n:], "/")
+	if ok, _ := path.Match(pattern, tail); ok {
+		return tail
+	}
+	return ""
+}
+
+// bumpRiskLevel sube el nivel de riesgo un escalón (critical es el tope)
+func bumpRiskLevel(level string) string {
+	switch level {
+	case "low", "":
+		return "medium"
+	case "medium":
+		return "high"
+	default:
+		return "critical"
+	}
+}
+
+// applyCriticalFileRisk eleva el riesgo de impact si filePath es un archivo
+// crítico del proyecto, dejando constancia en RiskFactors.
+func applyCriticalFileRisk(impact *ChangeImpact, filePath string, extra []string) {
+	name := MatchCriticalFile(filePath, extra)
+	if name == "" {
+		return
+	}
+	impact.RiskLevel = bumpRiskLevel(impact.RiskLevel)
+	impact.IsRisky = true
+	impact.RiskFactors = append(impact.RiskFactors, "critical file: "+name)
 }
 
 // DefaultRiskThresholds retorna los umbrales por defecto
@@ -34,8 +147,10 @@ func DefaultRiskThresholds() RiskThresholds {
 	}
 }
 
-// CalculateChangeImpact analiza el impacto de una operación de edición
-func CalculateChangeImpact(content, oldText, newText string, thresholds RiskThresholds) *ChangeImpact {
+// CalculateChangeImpact analiza el impacto de una operación de edición.
+// filePath es opcional: si se indica y es un archivo crítico (go.mod,
+// lockfiles, Dockerfile, .git/**, migraciones...) el riesgo sube un nivel.
+func CalculateChangeImpact(content, oldText, newText string, thresholds RiskThresholds, filePath ...string) *ChangeImpact {
 	// Normalize line endings so CRLF files match LF search text (Bug #23)
 	content = normalizeLineEndings(content)
 	oldText = normalizeLineEndings(oldText)
@@ -127,6 +242,11 @@ func CalculateChangeImpact(content, oldText, newText string, thresholds RiskThre
 		impact.RiskFactors = append(impact.RiskFactors, "⚠️ Short pattern with many matches - verify carefully")
 	}
 
+	// Archivos críticos del proyecto: al menos un nivel más de riesgo
+	if len(filePath) > 0 {
+		applyCriticalFileRisk(impact, filePath[0], thresholds.CriticalFiles)
+	}
+
 	return impact
 }
 
@@ -150,7 +270,7 @@ func CalculateBatchImpact(operations []BatchImpactInfo, thresholds RiskThreshold
 	highRiskCount := 0
 
 	for _, op := range operations {
-		impact := CalculateChangeImpact(op.Content, op.OldText, op.NewText, thresholds)
+		impact := CalculateChangeImpact(op.Content, op.OldText, op.NewText, thresholds, op.FilePath)
 
 		batchImpact.TotalOccurrences += impact.Occurrences
 		totalChangePercent += impact.ChangePercentage
@@ -0,0 +1,276 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// LargeViewHeadLines and LargeViewTailLines are how many lines a
+	// large-file view shows from each end when the caller asks for no count
+	LargeViewHeadLines = 300
+	LargeViewTailLines = 50
+
+	// largeViewMaxBytes caps each end of a large-file view, so a file of a
+	// few very long lines cannot pull megabytes into the response
+	largeViewMaxBytes = 64 * 1024
+)
+
+// OverBinaryThreshold reports whether a file or payload of size bytes is over
+// --binary-threshold. Reads over it are summarized from a streamed head and
+// tail, and writes go through the streaming writer. A threshold of 0 turns
+// the distinction off.
+func (e *UltraFastEngine) OverBinaryThreshold(size int64) bool {
+	return e.config.BinaryThreshold > 0 && size > e.config.BinaryThreshold
+}
+
+// LargeFileView is a summary of a file over --binary-threshold: its first
+// and last lines, read without holding the whole file in memory
+type LargeFileView struct {
+	Path        string
+	Size        int64
+	Threshold   int64
+	TotalLines  int
+	Head        string // lines 1..HeadLines
+	HeadLines   int
+	HeadCut     bool // the last head line was cut at largeViewMaxBytes
+	Tail        string
+	TailStart   int // first line of Tail
+	TailLines   int
+	TailCut     bool   // the first tail line is only its end
+	ContentHash string // FNV-1a of the whole file, same token as read_file
+}
+
+// ReadLargeFileView reads up to headLines lines from the start of path and
+// tailLines from its end. The total line count and the content hash come from
+// the cached line index, so the file is streamed at most once per change and
+// only the two ends are ever held in memory.
+func (e *UltraFastEngine) ReadLargeFileView(ctx context.Context, path string, headLines, tailLines int) (*LargeFileView, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, &ContextError{Op: "read_file", Details: "operation cancelled before start"}
+	}
+	path = NormalizePath(path)
+	if err := e.acquireOperation(ctx, "read"); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	defer e.releaseOperation("read", start)
+
+	if !e.IsPathAllowed(path) {
+		return nil, e.AccessDeniedError("read", path)
+	}
+	requested := path
+	resolved, err := e.ResolveAndAuthorize("read", path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			e.rememberMissing(requested, "read", err)
+		}
+		return nil, err
+	}
+	path = resolved
+
+	workingDir, _ := os.Getwd()
+	hookCtx := &HookContext{
+		Event:      HookPreRead,
+		ToolName:   "read_file",
+		FilePath:   path,
+		Operation:  "read_large",
+		Timestamp:  time.Now(),
+		WorkingDir: workingDir,
+	}
+	if _, err := e.hookManager.ExecuteHooks(ctx, HookPreRead, hookCtx); err != nil {
+		return nil, fmt.Errorf("pre-read hook denied operation: %w", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("path is a directory, not a file: %s", path)
+	}
+	idx, err := e.lineIndexFor(ctx, path, info)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	v := &LargeFileView{
+		Path:        path,
+		Size:        info.Size(),
+		Threshold:   e.config.BinaryThreshold,
+		TotalLines:  idx.lines,
+		ContentHash: fmt.Sprintf("%08x", idx.hash),
+	}
+	if headLines > 0 {
+		window, err := readWindow(file, 0, largeViewMaxBytes)
+		if err != nil {
+			return nil, err
+		}
+		lines, cut := headWindowLines(window, int64(len(window)) >= v.Size)
+		if len(lines) > headLines {
+			lines, cut = lines[:headLines], false
+		}
+		v.Head, v.HeadLines, v.HeadCut = strings.Join(lines, "\n"), len(lines), cut
+	}
+	if tailLines > 0 {
+		from := v.Size - largeViewMaxBytes
+		if from < 0 {
+			from = 0
+		}
+		lines, cut, err := tailWindowLines(file, from, v.Size)
+		if err != nil {
+			return nil, err
+		}
+		if len(lines) > tailLines {
+			lines, cut = lines[len(lines)-tailLines:], false
+		}
+		// Never repeat lines the head already shows
+		tailStart := v.TotalLines - len(lines) + 1
+		if overlap := v.HeadLines - tailStart + 1; overlap > 0 {
+			if overlap > len(lines) {
+				overlap = len(lines)
+			}
+			lines, cut, tailStart = lines[overlap:], false, tailStart+overlap
+		}
+		if len(lines) > 0 {
+			v.Tail, v.TailStart, v.TailLines, v.TailCut = strings.Join(lines, "\n"), tailStart, len(lines), cut
+		}
+	}
+
+	hookCtx.Event = HookPostRead
+	hookCtx.Metadata = map[string]interface{}{"bytes": len(v.Head) + len(v.Tail), "size": v.Size}
+	_, _ = e.hookManager.ExecuteHooks(ctx, HookPostRead, hookCtx)
+	return v, nil
+}
+
+// readWindow reads at most n bytes of file from offset
+func readWindow(file *os.File, offset, n int64) ([]byte, error) {
+	buf := make([]byte, n)
+	got, err := file.ReadAt(buf, offset)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+	return buf[:got], nil
+}
+
+// splitWindowLines splits a window into lines the way bufio.Scanner does,
+// dropping a final newline and the "\r" of CRLF endings
+func splitWindowLines(window []byte) []string {
+	s := strings.TrimSuffix(string(window), "\n")
+	if s == "" && len(window) == 0 {
+		return nil
+	}
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimSuffix(l, "\r")
+	}
+	return lines
+}
+
+// headWindowLines returns the complete lines of a window read from the start
+// of a file. A window that ends mid-line loses that partial line, unless it
+// is the only one; then it is returned with cut set.
+func headWindowLines(window []byte, atEOF bool) (lines []string, cut bool) {
+	if atEOF {
+		return splitWindowLines(window), false
+	}
+	end := strings.LastIndexByte(string(window), '\n')
+	if end < 0 {
+		return []string{string(window)}, true
+	}
+	return splitWindowLines(window[:end+1]), false
+}
+
+// tailWindowLines returns the complete lines between from and the end of a
+// file of the given size. A leading partial line is dropped unless it is the
+// only one; then its end is returned with cut set.
+func tailWindowLines(file *os.File, from, size int64) (lines []string, cut bool, err error) {
+	readFrom := from
+	if from > 0 {
+		readFrom-- // one byte more to see whether from starts a line
+	}
+	window, err := readWindow(file, readFrom, size-readFrom)
+	if err != nil {
+		return nil, false, err
+	}
+	if from == 0 {
+		return splitWindowLines(window), false, nil
+	}
+	if window[0] == '\n' {
+		return splitWindowLines(window[1:]), false, nil
+	}
+	body := strings.TrimSuffix(string(window[1:]), "\n")
+	start := strings.IndexByte(body, '\n')
+	if start < 0 {
+		return splitWindowLines(window[1:]), true, nil
+	}
+	return splitWindowLines(window[1+start+1:]), false, nil
+}
+
+// FormatLargeFileView renders a view with a notice that the file was
+// summarized, and a footer in the shape ReadFileRange uses
+func FormatLargeFileView(v *LargeFileView) string {
+	var sb strings.Builder
+	base := filepath.Base(v.Path)
+	fmt.Fprintf(&sb, "[Large file: %s is %s (%d lines), over the %s --binary-threshold, so only its start and end are shown. "+
+		"Read any part with start_line/end_line, or find content with search_files]\n\n",
+		base, formatSize(v.Size), v.TotalLines, formatSize(v.Threshold))
+
+	sb.WriteString(v.Head)
+	if v.HeadCut {
+		sb.WriteString(" …[line cut]")
+	}
+	if v.TailLines > 0 {
+		if v.HeadLines > 0 {
+			if omitted := v.TailStart - v.HeadLines - 1; omitted > 0 {
+				fmt.Fprintf(&sb, "\n\n... [%d lines omitted] ...\n\n", omitted)
+			} else {
+				sb.WriteString("\n")
+			}
+		}
+		if v.TailCut {
+			sb.WriteString("[line cut]… ")
+		}
+		sb.WriteString(v.Tail)
+	}
+
+	var shown []string
+	if v.HeadLines > 0 {
+		shown = append(shown, fmt.Sprintf("1-%d", v.HeadLines))
+	}
+	if v.TailLines > 0 {
+		shown = append(shown, fmt.Sprintf("%d-%d", v.TailStart, v.TailStart+v.TailLines-1))
+	}
+	if len(shown) == 0 {
+		shown = append(shown, "none")
+	}
+	fmt.Fprintf(&sb, "\n\n[Lines %s of %d total lines in %s", strings.Join(shown, " and "), v.TotalLines, base)
+
+	// Suggest the first unshown lines, a head view's worth at a time
+	gapStart, gapEnd := v.HeadLines+1, v.TotalLines
+	if v.TailLines > 0 {
+		gapEnd = v.TailStart - 1
+	}
+	if gapStart <= gapEnd {
+		if v.HeadLines == 0 && gapEnd-LargeViewHeadLines+1 > gapStart {
+			gapStart = gapEnd - LargeViewHeadLines + 1
+		} else if gapEnd > gapStart+LargeViewHeadLines-1 {
+			gapEnd = gapStart + LargeViewHeadLines - 1
+		}
+		fmt.Fprintf(&sb, " — use start_line/end_line to read more, e.g. start_line=%d end_line=%d", gapStart, gapEnd)
+	}
+	sb.WriteString("]")
+	return sb.String()
+}
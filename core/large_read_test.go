@@ -0,0 +1,184 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// numberedLines returns "line 1<eol>line 2<eol>..." padded past size bytes
+func numberedLines(size int, eol string) string {
+	var sb strings.Builder
+	for i := 1; sb.Len() < size; i++ {
+		fmt.Fprintf(&sb, "line %d%s", i, eol)
+	}
+	return sb.String()
+}
+
+func fnvHex(data string) string {
+	h := fnv.New32a()
+	h.Write([]byte(data))
+	return fmt.Sprintf("%08x", h.Sum32())
+}
+
+func TestIntelligentRead_BinaryThresholdBoundary(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	const threshold = 4096
+	engine.config.BinaryThreshold = threshold
+	ctx := context.Background()
+	base := numberedLines(threshold+10, "\n")
+
+	for _, size := range []int{threshold - 1, threshold, threshold + 1} {
+		path := filepath.Join(dir, fmt.Sprintf("f%d.txt", size))
+		content := base[:size]
+		os.WriteFile(path, []byte(content), 0644)
+
+		got, err := engine.IntelligentRead(ctx, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		over := size > threshold
+		if engine.OverBinaryThreshold(int64(size)) != over {
+			t.Fatalf("size %d: OverBinaryThreshold = %v", size, !over)
+		}
+		if !over && got != content {
+			t.Fatalf("size %d: at or under the threshold the file is read whole", size)
+		}
+		if over && (!strings.HasPrefix(got, "[Large file: f4097.txt is 4.0 KB") || !strings.Contains(got, "\nline 1\nline 2\n") ||
+			!strings.Contains(got, "total lines in f4097.txt")) {
+			t.Fatalf("size %d: want the summarized view, got %q", size, tail(got))
+		}
+	}
+
+	engine.config.BinaryThreshold = 0
+	if engine.OverBinaryThreshold(1 << 40) {
+		t.Fatal("a zero threshold turns the large-file paths off")
+	}
+}
+
+func TestReadLargeFileView_HeadTailAndHash(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	engine.config.BinaryThreshold = 1024
+	ctx := context.Background()
+
+	for _, eol := range []string{"\n", "\r\n"} {
+		path := filepath.Join(dir, "big.log")
+		content := numberedLines(3*largeViewMaxBytes, eol)
+		os.WriteFile(path, []byte(content), 0644)
+		total := strings.Count(content, "\n")
+
+		v, err := engine.ReadLargeFileView(ctx, path, 3, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantTail := fmt.Sprintf("line %d\nline %d", total-1, total)
+		if v.Head != "line 1\nline 2\nline 3" || v.Tail != wantTail || v.TailStart != total-1 || v.TotalLines != total {
+			t.Fatalf("eol %q: view = head %q tail %q start %d total %d", eol, v.Head, v.Tail, v.TailStart, v.TotalLines)
+		}
+		if v.ContentHash != fnvHex(content) {
+			t.Fatalf("eol %q: hash %s, want the whole-file FNV %s", eol, v.ContentHash, fnvHex(content))
+		}
+		out := FormatLargeFileView(v)
+		if !strings.Contains(out, fmt.Sprintf("[%d lines omitted]", total-5)) ||
+			!strings.HasSuffix(out, fmt.Sprintf("[Lines 1-3 and %d-%d of %d total lines in big.log — use start_line/end_line to read more, e.g. start_line=4 end_line=303]", total-1, total, total)) {
+			t.Fatalf("eol %q: formatted %q", eol, tail(out))
+		}
+	}
+}
+
+func TestReadLargeFileView_ShortAndLongLines(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	engine.config.BinaryThreshold = 16
+	ctx := context.Background()
+
+	// Fewer lines than head+tail: every line once, nothing omitted
+	short := filepath.Join(dir, "short.txt")
+	os.WriteFile(short, []byte("a\nb\nc\nd\n"), 0644)
+	v, err := engine.ReadLargeFileView(ctx, short, 3, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Head != "a\nb\nc" || v.Tail != "d" || v.TailStart != 4 {
+		t.Fatalf("short view = %+v", v)
+	}
+	if out := FormatLargeFileView(v); !strings.Contains(out, "a\nb\nc\nd") || strings.Contains(out, "omitted") || strings.Contains(out, "e.g.") {
+		t.Fatalf("short formatted %q", out)
+	}
+
+	// One line longer than both windows: each end shows a cut piece of it
+	long := filepath.Join(dir, "minified.js")
+	os.WriteFile(long, []byte("x"+strings.Repeat("y", 3*largeViewMaxBytes)+"z"), 0644)
+	v, err = engine.ReadLargeFileView(ctx, long, LargeViewHeadLines, LargeViewTailLines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.TotalLines != 1 || !v.HeadCut || len(v.Head) != largeViewMaxBytes || v.Head[0] != 'x' || v.TailLines != 0 {
+		t.Fatalf("long head view: lines %d cut %v len %d tail %d", v.TotalLines, v.HeadCut, len(v.Head), v.TailLines)
+	}
+	v, err = engine.ReadLargeFileView(ctx, long, 0, LargeViewTailLines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !v.TailCut || v.TailStart != 1 || !strings.HasSuffix(v.Tail, "yz") || len(v.Tail) != largeViewMaxBytes {
+		t.Fatalf("long tail view: cut %v start %d len %d", v.TailCut, v.TailStart, len(v.Tail))
+	}
+}
+
+func TestWriteFileAuto_StreamsOverThreshold(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	const threshold = 2048
+	engine.config.BinaryThreshold = threshold
+	content := numberedLines(threshold+10, "\n")
+
+	for _, size := range []int{threshold, threshold + 1} {
+		entry := &AuditEntry{}
+		ctx := context.WithValue(context.Background(), AuditEntryKey{}, entry)
+		path := filepath.Join(dir, fmt.Sprintf("out%d.txt", size))
+		if err := engine.WriteFileAuto(ctx, path, content[:size]); err != nil {
+			t.Fatal(err)
+		}
+		if data, _ := os.ReadFile(path); string(data) != content[:size] {
+			t.Fatalf("size %d: content differs", size)
+		}
+		if streamed := entry.SubOp == "streaming_write"; streamed != (size > threshold) {
+			t.Fatalf("size %d: sub-op %q", size, entry.SubOp)
+		}
+	}
+
+	// A CRLF file keeps its endings, also when a "\r\n" straddles two chunks
+	path := filepath.Join(dir, "crlf.txt")
+	os.WriteFile(path, []byte("old\r\nfile\r\n"), 0644)
+	chunk := DefaultChunkingConfig().MaxChunkSize
+	body := strings.Repeat("a", chunk-1) + "\r\n" + numberedLines(threshold, "\n")
+	if err := engine.WriteFileAuto(context.Background(), path, body); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n")
+	if data, _ := os.ReadFile(path); string(data) != want {
+		t.Fatalf("CRLF not preserved: %d bytes, want %d", len(data), len(want))
+	}
+}
+
+func TestWriteFileAuto_StreamedWriteIsSecretScanned(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	engine.config.BinaryThreshold = 2048
+	engine.hookManager.SetSecretGuard(NewSecretGuard(nil))
+
+	// The key sits past the first chunk, so only a scan of the whole stream finds it
+	chunk := DefaultChunkingConfig().MaxChunkSize
+	body := numberedLines(chunk+100, "\n") + "AWS_KEY=" + fakeAWSKey + "\n"
+	entry := &AuditEntry{}
+	ctx := context.WithValue(context.Background(), AuditEntryKey{}, entry)
+	path := filepath.Join(dir, "dump.env")
+	err := engine.WriteFileAuto(ctx, path, body)
+	if err == nil || !strings.Contains(err.Error(), "aws-access-key-id") || entry.SubOp != "streaming_write" {
+		t.Fatalf("streamed secret written: %v (sub-op %q)", err, entry.SubOp)
+	}
+	if _, err := os.Stat(path); err == nil {
+		t.Error("denied streaming write left a file")
+	}
+}
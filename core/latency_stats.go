@@ -0,0 +1,229 @@
+package core
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Latency histograms use log-scale buckets, latencySubBuckets per power of
+// two (≈19% relative error), from 1µs up to ~18 minutes; anything outside
+// lands in the first or last bucket. Max is tracked exactly.
+const (
+	latencySubBuckets = 4
+	latencyMinLog2    = 10 // 1024ns
+	latencyMaxLog2    = 40 // ~18m
+	latencyBuckets    = (latencyMaxLog2-latencyMinLog2)*latencySubBuckets + 1
+)
+
+// Latency kinds: engine operations (the opType passed to releaseOperation)
+// and MCP tool handlers (the tool name, end to end)
+const (
+	LatencyKindOp   = "op"
+	LatencyKindTool = "tool"
+)
+
+// latencyHistogram is a fixed-bucket duration histogram
+type latencyHistogram struct {
+	counts [latencyBuckets]int64
+	count  int64
+	max    time.Duration
+	errors int64 // tool calls that returned an error (tools only)
+}
+
+func latencyBucket(d time.Duration) int {
+	if d <= 1<<latencyMinLog2 {
+		return 0
+	}
+	i := int((math.Log2(float64(d)) - latencyMinLog2) * latencySubBuckets)
+	return min(max(i, 0), latencyBuckets-1)
+}
+
+// latencyBucketUpper is the largest duration counted in bucket i
+func latencyBucketUpper(i int) time.Duration {
+	return time.Duration(math.Exp2(latencyMinLog2 + float64(i+1)/latencySubBuckets))
+}
+
+func (h *latencyHistogram) record(d time.Duration) {
+	h.counts[latencyBucket(d)]++
+	h.count++
+	if d > h.max {
+		h.max = d
+	}
+}
+
+// quantile returns the upper bound of the bucket holding quantile q, capped
+// at the recorded maximum
+func (h *latencyHistogram) quantile(q float64) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+	rank := int64(math.Ceil(q * float64(h.count)))
+	var seen int64
+	for i, c := range h.counts {
+		seen += c
+		if seen >= rank {
+			if i == latencyBuckets-1 {
+				return h.max // overflow bucket has no upper bound
+			}
+			return min(latencyBucketUpper(i), h.max)
+		}
+	}
+	return h.max
+}
+
+// LatencyStats is one operation type's or tool's latency distribution since
+// the tracking window started
+type LatencyStats struct {
+	Kind   string        `json:"kind"` // LatencyKindOp or LatencyKindTool
+	Name   string        `json:"name"`
+	Count  int64         `json:"count"`
+	P50    time.Duration `json:"p50"`
+	P95    time.Duration `json:"p95"`
+	P99    time.Duration `json:"p99"`
+	Max    time.Duration `json:"max"`
+	Errors int64         `json:"errors,omitempty"` // tool calls that returned an error
+}
+
+// latencyTracker keeps a histogram per kind and name plus one over all
+// engine operations (the compact stats line)
+type latencyTracker struct {
+	mu    sync.Mutex
+	hists map[[2]string]*latencyHistogram
+	all   latencyHistogram
+}
+
+// histLocked returns the histogram for kind and name, creating it (caller
+// holds mu)
+func (lt *latencyTracker) histLocked(kind, name string) *latencyHistogram {
+	if lt.hists == nil {
+		lt.hists = make(map[[2]string]*latencyHistogram)
+	}
+	key := [2]string{kind, name}
+	h := lt.hists[key]
+	if h == nil {
+		h = &latencyHistogram{}
+		lt.hists[key] = h
+	}
+	return h
+}
+
+func (lt *latencyTracker) record(kind, name string, d time.Duration) {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	lt.histLocked(kind, name).record(d)
+	if kind == LatencyKindOp {
+		lt.all.record(d)
+	}
+}
+
+func (lt *latencyTracker) recordError(tool string) {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	lt.histLocked(LatencyKindTool, tool).errors++
+}
+
+// p50 is the median over all engine operations
+func (lt *latencyTracker) p50() time.Duration {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	return lt.all.quantile(0.50)
+}
+
+func (lt *latencyTracker) reset() {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	lt.hists = nil
+	lt.all = latencyHistogram{}
+}
+
+// stats lists the distributions, operations before tools, busiest first
+func (lt *latencyTracker) stats() []LatencyStats {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	out := make([]LatencyStats, 0, len(lt.hists))
+	for key, h := range lt.hists {
+		out = append(out, LatencyStats{
+			Kind:   key[0],
+			Name:   key[1],
+			Count:  h.count,
+			P50:    h.quantile(0.50),
+			P95:    h.quantile(0.95),
+			P99:    h.quantile(0.99),
+			Max:    h.max,
+			Errors: h.errors,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Kind != out[j].Kind {
+			return out[i].Kind == LatencyKindOp
+		}
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// RecordToolLatency records the end-to-end duration of an MCP tool call
+func (e *UltraFastEngine) RecordToolLatency(tool string, d time.Duration) {
+	e.latency.record(LatencyKindTool, tool, d)
+}
+
+// RecordToolError counts an MCP tool call that returned an error
+func (e *UltraFastEngine) RecordToolError(tool string) {
+	e.latency.recordError(tool)
+}
+
+// ObserveSlowOperation logs a tool call that took at least
+// Config.SlowOpThreshold as a single structured line and counts it. It
+// reports whether the call was slow.
+func (e *UltraFastEngine) ObserveSlowOperation(entry AuditEntry, d time.Duration) bool {
+	threshold := e.config.SlowOpThreshold
+	if threshold <= 0 || d < threshold {
+		return false
+	}
+	e.metrics.mu.Lock()
+	e.metrics.SlowOperations++
+	e.metrics.mu.Unlock()
+	slog.Warn("Slow operation", "tool", entry.Tool, "path", entry.Path, "duration", d.Round(time.Millisecond),
+		"bytes_in", entry.BytesIn, "bytes_out", entry.BytesOut, "status", entry.Status)
+	return true
+}
+
+// LatencyStats returns per-operation and per-tool latency percentiles
+func (e *UltraFastEngine) LatencyStats() []LatencyStats {
+	return e.latency.stats()
+}
+
+// formatLatency renders a duration at a precision suited to its magnitude
+func formatLatency(d time.Duration) string {
+	switch {
+	case d < time.Millisecond:
+		return fmt.Sprintf("%dµs", d.Microseconds())
+	case d < time.Second:
+		return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+	default:
+		return fmt.Sprintf("%.2fs", d.Seconds())
+	}
+}
+
+// formatLatencyTable renders the performance_stats latency section, one row
+// per operation type and tool ("" before anything was recorded)
+func formatLatencyTable(stats []LatencyStats) string {
+	if len(stats) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("\nLatency:\n  %-22s %7s %9s %9s %9s %9s", "", "count", "p50", "p95", "p99", "max"))
+	for _, s := range stats {
+		b.WriteString(fmt.Sprintf("\n  %-22s %7d %9s %9s %9s %9s", s.Kind+":"+s.Name, s.Count,
+			formatLatency(s.P50), formatLatency(s.P95), formatLatency(s.P99), formatLatency(s.Max)))
+	}
+	return b.String()
+}
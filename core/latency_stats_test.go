@@ -0,0 +1,109 @@
+package core
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLatencyHistogram_Percentiles(t *testing.T) {
+	var h latencyHistogram
+	for i := 1; i <= 100; i++ {
+		h.record(time.Duration(i) * time.Millisecond)
+	}
+	// Buckets are ~19% wide; the reported value is the bucket's upper bound
+	within := func(got, want time.Duration) bool {
+		return got >= want && float64(got) <= float64(want)*1.2
+	}
+	if p := h.quantile(0.50); !within(p, 50*time.Millisecond) {
+		t.Errorf("p50 = %v", p)
+	}
+	if p := h.quantile(0.95); !within(p, 95*time.Millisecond) {
+		t.Errorf("p95 = %v", p)
+	}
+	if p := h.quantile(0.99); p > h.max || !within(p, 99*time.Millisecond) {
+		t.Errorf("p99 = %v (max %v)", p, h.max)
+	}
+	if h.max != 100*time.Millisecond {
+		t.Errorf("max = %v", h.max)
+	}
+
+	// Out-of-range durations are clamped, not dropped
+	var edge latencyHistogram
+	edge.record(0)
+	edge.record(time.Hour)
+	if edge.count != 2 || edge.quantile(1) != time.Hour || edge.quantile(0.5) > time.Microsecond*2 {
+		t.Errorf("edge histogram: count=%d p100=%v p50=%v", edge.count, edge.quantile(1), edge.quantile(0.5))
+	}
+}
+
+func TestLatencyStats_PerOperationAndTool(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	ctx := context.Background()
+	path := filepath.Join(dir, "a.txt")
+	if err := engine.WriteFileContent(ctx, path, "hello"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		engine.ReadFileContent(ctx, path)
+	}
+	engine.RecordToolLatency("read_file", 4*time.Millisecond)
+
+	stats := engine.LatencyStats()
+	byKey := map[string]LatencyStats{}
+	for _, s := range stats {
+		byKey[s.Kind+":"+s.Name] = s
+	}
+	if byKey["op:read"].Count != 3 || byKey["op:write"].Count != 1 {
+		t.Errorf("op counts = %+v", stats)
+	}
+	if tool := byKey["tool:read_file"]; tool.Count != 1 || tool.Max != 4*time.Millisecond {
+		t.Errorf("tool stats = %+v", tool)
+	}
+	if stats[len(stats)-1].Kind != LatencyKindTool {
+		t.Error("tools should be listed after engine operations")
+	}
+
+	table := formatLatencyTable(stats)
+	for _, want := range []string{"p50", "p99", "op:read", "tool:read_file"} {
+		if !strings.Contains(table, want) {
+			t.Errorf("latency table missing %q:\n%s", want, table)
+		}
+	}
+	if !strings.Contains(engine.GetPerformanceStats(), "op:read") {
+		t.Error("performance stats lack the latency table")
+	}
+
+	engine.ResetTelemetry()
+	if len(engine.LatencyStats()) != 0 {
+		t.Error("ResetTelemetry kept latency histograms")
+	}
+}
+
+func TestObserveSlowOperation_ThresholdAndCounter(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	entry := AuditEntry{Tool: "list_directory", Path: dir, BytesOut: 123}
+	if engine.ObserveSlowOperation(entry, time.Hour) {
+		t.Error("slow operation reported without a threshold")
+	}
+
+	engine.config.SlowOpThreshold = 2 * time.Second
+	if engine.ObserveSlowOperation(entry, time.Second) {
+		t.Error("call under the threshold reported as slow")
+	}
+	if !engine.ObserveSlowOperation(entry, 3*time.Second) || !engine.ObserveSlowOperation(entry, 2*time.Second) {
+		t.Error("call at or over the threshold not reported")
+	}
+	if engine.metrics.SlowOperations != 2 {
+		t.Errorf("SlowOperations = %d, want 2", engine.metrics.SlowOperations)
+	}
+	if stats := engine.GetPerformanceStats(); !strings.Contains(stats, "Slow Operations: 2 (over 2s)") {
+		t.Errorf("performance stats lack the slow-operation line:\n%s", stats)
+	}
+	engine.config.CompactMode = true
+	if stats := engine.GetPerformanceStats(); !strings.Contains(stats, "slow:2") {
+		t.Errorf("compact stats = %q", stats)
+	}
+}
@@ -0,0 +1,153 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// lineIndexStride is how many lines apart the checkpoints of a line index are
+const lineIndexStride = 1000
+
+// lineIndex is a sparse line-offset index of one large file, valid while
+// its mtime and size are unchanged. A ranged read seeks to the checkpoint at
+// or before its start line and scans at most lineIndexStride-1 lines to reach
+// it, and takes the total from the index instead of counting to the end.
+type lineIndex struct {
+	modTime time.Time
+	size    int64
+	lines   int     // total lines, counted as bufio.Scanner does
+	offsets []int64 // offsets[k] is where line k*lineIndexStride+1 starts
+	hash    uint32  // FNV-1a of the whole file, the read_file OCC token
+}
+
+// lineIndexKey is the metadata cache key of path's line index
+func lineIndexKey(path string) string {
+	return path + "::lines"
+}
+
+// checkpoint returns the offset and line number of the nearest checkpoint at
+// or before line
+func (idx *lineIndex) checkpoint(line int) (offset int64, at int) {
+	k := (line - 1) / lineIndexStride
+	if k >= len(idx.offsets) {
+		k = len(idx.offsets) - 1
+	}
+	return idx.offsets[k], k*lineIndexStride + 1
+}
+
+// lineIndexFor returns path's line index from the cache, building it when
+// there is none or the file changed since it was built
+func (e *UltraFastEngine) lineIndexFor(ctx context.Context, path string, info os.FileInfo) (*lineIndex, error) {
+	key := lineIndexKey(path)
+	if v, ok := e.cache.GetMetadata(key); ok {
+		if idx, ok := v.(*lineIndex); ok && idx.modTime.Equal(info.ModTime()) && idx.size == info.Size() {
+			return idx, nil
+		}
+	}
+	idx, err := e.buildLineIndex(ctx, path, info)
+	if err != nil {
+		return nil, err
+	}
+	e.cache.SetMetadata(key, idx)
+	return idx, nil
+}
+
+// buildLineIndex streams path once through a pooled buffer, recording where
+// every lineIndexStride-th line starts and hashing the bytes on the way
+func (e *UltraFastEngine) buildLineIndex(ctx context.Context, path string, info os.FileInfo) (*lineIndex, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	bufPtr := e.bufferPool.Get().(*[]byte)
+	defer e.bufferPool.Put(bufPtr)
+	buf := *bufPtr
+
+	idx := &lineIndex{modTime: info.ModTime(), size: info.Size(), offsets: []int64{0}}
+	sum := fnv.New32a()
+	var pos int64
+	newlines := 0
+	var last byte
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, &ContextError{Op: "read_range", Details: "cancelled while indexing lines"}
+		}
+		n, readErr := file.Read(buf)
+		chunk := buf[:n]
+		sum.Write(chunk)
+		for i := 0; ; {
+			j := bytes.IndexByte(chunk[i:], '\n')
+			if j < 0 {
+				break
+			}
+			i += j + 1
+			if newlines++; newlines%lineIndexStride == 0 {
+				idx.offsets = append(idx.offsets, pos+int64(i))
+			}
+		}
+		if n > 0 {
+			last = chunk[n-1]
+		}
+		pos += int64(n)
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return nil, fmt.Errorf("error reading file: %w", readErr)
+		}
+	}
+	idx.lines = newlines
+	idx.hash = sum.Sum32()
+	if pos > 0 && last != '\n' {
+		idx.lines++ // a last line without a newline still counts
+	}
+	return idx, nil
+}
+
+// readIndexedRange reads lines startLine..endLine of a large file through
+// its line index and returns them with the total line count
+func (e *UltraFastEngine) readIndexedRange(ctx context.Context, path string, info os.FileInfo, startLine, endLine int) (string, int, error) {
+	idx, err := e.lineIndexFor(ctx, path, info)
+	if err != nil {
+		return "", 0, err
+	}
+	if startLine > idx.lines {
+		return "", idx.lines, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+	offset, lineNum := idx.checkpoint(startLine)
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return "", 0, fmt.Errorf("failed to seek: %w", err)
+	}
+
+	var result strings.Builder
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024) // 1MB for very long lines
+	for lineNum--; lineNum < endLine && scanner.Scan(); {
+		if lineNum++; lineNum < startLine {
+			continue
+		}
+		if result.Len() > 0 {
+			result.WriteString("\n")
+		}
+		result.WriteString(scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return "", 0, fmt.Errorf("error reading file: %w", err)
+	}
+	return result.String(), idx.lines, nil
+}
@@ -0,0 +1,100 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeLargeLines writes numbered lines until the file passes
+// LargeFileThreshold, so ReadFileRange takes the indexed path
+func writeLargeLines(t *testing.T, path, eol string, trailing bool) []byte {
+	t.Helper()
+	var sb strings.Builder
+	for i := 1; sb.Len() <= LargeFileThreshold; i++ {
+		if i > 1 {
+			sb.WriteString(eol)
+		}
+		fmt.Fprintf(&sb, "line %07d %s", i, strings.Repeat("x", i%13))
+	}
+	if trailing {
+		sb.WriteString(eol)
+	}
+	if err := os.WriteFile(path, []byte(sb.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return []byte(sb.String())
+}
+
+func TestReadFileRange_LineIndexMatchesScan(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	ctx := context.Background()
+	for _, tc := range []struct {
+		name     string
+		eol      string
+		trailing bool
+	}{{"lf", "\n", true}, {"crlf", "\r\n", true}, {"no_trailing_newline", "\n", false}} {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(dir, tc.name+".log")
+			content := writeLargeLines(t, path, tc.eol, tc.trailing)
+			total := strings.Count(string(content), "\n")
+			if !tc.trailing {
+				total++
+			}
+			for _, r := range [][2]int{{1, 3}, {999, 1002}, {1000, 1000}, {1001, 1001}, {123456, 123500},
+				{total - 2, total + 5}, {total, total}, {total + 1, total + 10}} {
+				got, err := engine.ReadFileRange(ctx, path, r[0], r[1])
+				if err != nil {
+					t.Fatal(err)
+				}
+				want, _ := extractLineRangeFromBytes(content, path, r[0], r[1])
+				if got != want {
+					t.Fatalf("lines %d-%d:\n got %q\nwant %q", r[0], r[1], tail(got), tail(want))
+				}
+			}
+		})
+	}
+}
+
+func TestReadFileRange_LineIndexRebuiltWhenStale(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	ctx := context.Background()
+	path := filepath.Join(dir, "app.log")
+	content := writeLargeLines(t, path, "\n", true)
+	lines := strings.Count(string(content), "\n")
+
+	if _, err := engine.ReadFileRange(ctx, path, 10, 12); err != nil {
+		t.Fatal(err)
+	}
+	v, ok := engine.cache.GetMetadata(lineIndexKey(path))
+	if !ok || v.(*lineIndex).lines != lines || len(v.(*lineIndex).offsets) != lines/lineIndexStride+1 {
+		t.Fatalf("index not cached or wrong: %v %+v", ok, v)
+	}
+
+	// Append behind the engine's back: the index is stale by mtime and size
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("appended\n")
+	f.Close()
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(path, later, later)
+
+	got, err := engine.ReadFileRange(ctx, path, lines+1, lines+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(got, "appended\n") || !strings.Contains(got, fmt.Sprintf("of %d total lines", lines+1)) {
+		t.Fatalf("after append: %q", got)
+	}
+}
+
+// tail keeps failure output readable
+func tail(s string) string {
+	if len(s) > 200 {
+		return "..." + s[len(s)-200:]
+	}
+	return s
+}
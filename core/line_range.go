@@ -129,6 +129,9 @@ func (e *UltraFastEngine) DeleteLineRange(ctx context.Context, path string, star
 	if !e.IsPathAllowed(path) {
 		return "", nil, e.AccessDeniedError("delete_range", path)
 	}
+	if err := e.CheckWritable("delete_range", path); err != nil {
+		return "", nil, err
+	}
 	if err := e.validateEditableFile(path); err != nil {
 		return "", nil, fmt.Errorf("file validation failed: %w", err)
 	}
@@ -185,6 +188,8 @@ func (e *UltraFastEngine) DeleteLineRange(ctx context.Context, path string, star
 	}
 	// New point 1: post-edit content_hash for re-read-free chaining.
 	result.NewHash = contentHashFNV(remaining)
+	e.recordOperation(ctx, OperationRecord{Operation: "delete_range", Path: path, Replacements: 1,
+		BytesWritten: int64(len(remaining)), BackupID: backupID})
 	return removed, result, nil
 }
 
@@ -207,6 +212,9 @@ func (e *UltraFastEngine) ReplaceLineRange(ctx context.Context, path string, sta
 	if !e.IsPathAllowed(path) {
 		return nil, e.AccessDeniedError("replace_range", path)
 	}
+	if err := e.CheckWritable("replace_range", path); err != nil {
+		return nil, err
+	}
 	if err := e.validateEditableFile(path); err != nil {
 		return nil, fmt.Errorf("file validation failed: %w", err)
 	}
@@ -265,5 +273,7 @@ func (e *UltraFastEngine) ReplaceLineRange(ctx context.Context, path string, sta
 	}
 	// New point 1: post-edit content_hash for re-read-free chaining.
 	result.NewHash = contentHashFNV(remaining)
+	e.recordOperation(ctx, OperationRecord{Operation: "replace_range", Path: path, Replacements: 1,
+		BytesWritten: int64(len(remaining)), BackupID: backupID})
 	return result, nil
 }
@@ -0,0 +1,289 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// list_directory views (sort, filter, offset/limit, dirs_only/files_only)
+// share one cache entry per directory: the raw entries are cached under
+// dirListingEntriesKey, validated against the directory mtime like the
+// rendered listings, and each view is applied per call.
+
+// ListOptions selects a view of a directory listing. The zero value is the
+// plain listing (ListDirectoryContent / ListDirectoryJSON).
+type ListOptions struct {
+	Sort      string // "name" (default), "size" or "mtime"
+	Order     string // "asc" or "desc" (default: asc for name, desc for size and mtime)
+	Filter    string // glob on the entry name ("*.go")
+	Offset    int
+	Limit     int // entries shown (0 or more than MaxListItems = MaxListItems)
+	DirsOnly  bool
+	FilesOnly bool
+	Git       bool // annotate entries with their git status (M, A, ??, ...)
+}
+
+// IsDefault reports whether o asks for the plain listing
+func (o ListOptions) IsDefault() bool {
+	return o == ListOptions{}
+}
+
+// ListView describes the window of a DirectoryListing built with ListOptions
+type ListView struct {
+	Sort       string `json:"sort"`
+	Order      string `json:"order"`
+	Filter     string `json:"filter,omitempty"`
+	DirsOnly   bool   `json:"dirs_only,omitempty"`
+	FilesOnly  bool   `json:"files_only,omitempty"`
+	Git        bool   `json:"git,omitempty"` // entries carry git status; false outside a repository
+	Matched    int    `json:"matched"`       // entries passing filter and type, before offset/limit
+	Offset     int    `json:"offset"`
+	Limit      int    `json:"limit"`
+	NextOffset int    `json:"next_offset,omitempty"` // offset of the next page; 0 = last page
+}
+
+// listEntry is the cached raw form of one directory entry
+type listEntry struct {
+	Name  string `json:"n"`
+	Dir   bool   `json:"d,omitempty"`
+	Size  int64  `json:"s,omitempty"`
+	Mtime int64  `json:"m,omitempty"` // unix nanoseconds
+}
+
+// dirListingEntriesKey is the cache key of a directory's raw entries
+func dirListingEntriesKey(dir string) string {
+	return dir + "::entries"
+}
+
+// normalize fills the defaults and validates o
+func (o *ListOptions) normalize(maxItems int) error {
+	switch o.Sort {
+	case "":
+		o.Sort = "name"
+	case "name", "size", "mtime":
+	default:
+		return fmt.Errorf("invalid sort %q: use name, size or mtime", o.Sort)
+	}
+	switch o.Order {
+	case "":
+		o.Order = "asc"
+		if o.Sort != "name" {
+			o.Order = "desc"
+		}
+	case "asc", "desc":
+	default:
+		return fmt.Errorf("invalid order %q: use asc or desc", o.Order)
+	}
+	if o.Filter != "" {
+		if _, err := filepath.Match(o.Filter, ""); err != nil {
+			return fmt.Errorf("invalid filter %q: %w", o.Filter, err)
+		}
+	}
+	if o.DirsOnly && o.FilesOnly {
+		return fmt.Errorf("dirs_only and files_only are mutually exclusive")
+	}
+	if o.Offset < 0 {
+		return fmt.Errorf("offset must be >= 0, got %d", o.Offset)
+	}
+	if o.Limit <= 0 || (maxItems > 0 && o.Limit > maxItems) {
+		o.Limit = maxItems
+	}
+	return nil
+}
+
+// ListDirectoryView lists path through opts: entries are filtered (glob on
+// the name, dirs_only/files_only), sorted, then windowed by offset/limit.
+// The response states the directory total, the matching count and the window.
+func (e *UltraFastEngine) ListDirectoryView(ctx context.Context, path string, opts ListOptions) (*DirectoryListing, error) {
+	path = NormalizePath(path)
+	if err := opts.normalize(e.config.MaxListItems); err != nil {
+		return nil, err
+	}
+	if err := e.acquireOperation(ctx, "list"); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	defer e.releaseOperation("list", start)
+
+	if !e.IsPathAllowed(path) {
+		return nil, fmt.Errorf("access denied: path '%s' is not in allowed paths%s", path, e.AllowedDirsSuffix())
+	}
+	entries, err := e.dirEntries(path)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := make([]listEntry, 0, len(entries))
+	for _, entry := range entries {
+		if (opts.DirsOnly && !entry.Dir) || (opts.FilesOnly && entry.Dir) {
+			continue
+		}
+		if opts.Filter != "" {
+			if ok, _ := filepath.Match(opts.Filter, entry.Name); !ok {
+				continue
+			}
+		}
+		matched = append(matched, entry)
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		a, b := matched[i], matched[j]
+		if opts.Order == "desc" {
+			a, b = b, a
+		}
+		switch opts.Sort {
+		case "size":
+			if a.Size != b.Size {
+				return a.Size < b.Size
+			}
+		case "mtime":
+			if a.Mtime != b.Mtime {
+				return a.Mtime < b.Mtime
+			}
+		}
+		return a.Name < b.Name
+	})
+
+	view := &ListView{Sort: opts.Sort, Order: opts.Order, Filter: opts.Filter, DirsOnly: opts.DirsOnly,
+		FilesOnly: opts.FilesOnly, Matched: len(matched), Offset: opts.Offset, Limit: opts.Limit}
+	window := matched[min(opts.Offset, len(matched)):]
+	if opts.Limit > 0 && len(window) > opts.Limit {
+		window = window[:opts.Limit]
+		view.NextOffset = opts.Offset + opts.Limit
+	}
+
+	out := &DirectoryListing{Path: path, Total: len(entries), Truncated: view.NextOffset > 0,
+		Entries: make([]DirectoryEntry, 0, len(window)), View: view}
+	for _, entry := range window {
+		de := DirectoryEntry{Name: entry.Name, Type: "file", Size: entry.Size}
+		if entry.Dir {
+			de.Type = "dir"
+		}
+		if entry.Mtime != 0 {
+			de.Modified = time.Unix(0, entry.Mtime).UTC().Format(time.RFC3339)
+		}
+		out.Entries = append(out.Entries, de)
+	}
+	if opts.Git {
+		if ix, err := LoadGitStatus(ctx, path); err == nil {
+			view.Git = true
+			for i := range out.Entries {
+				de := &out.Entries[i]
+				de.Git = ix.Status(filepath.Join(path, de.Name), de.Type == "dir")
+			}
+		}
+	}
+	return out, nil
+}
+
+// dirEntries returns the raw entries of dir, cached until its mtime changes
+func (e *UltraFastEngine) dirEntries(dir string) ([]listEntry, error) {
+	dirInfo, statErr := os.Stat(dir)
+	cacheKey := dirListingEntriesKey(dir)
+	if cached, cachedMtime, hit := e.cache.GetDirectory(cacheKey); hit {
+		var entries []listEntry
+		if statErr == nil && !dirInfo.ModTime().After(cachedMtime) && json.Unmarshal([]byte(cached), &entries) == nil {
+			return entries, nil
+		}
+		e.cache.InvalidateDirectory(cacheKey)
+	}
+
+	if statErr != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", statErr)
+	}
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+	entries := make([]listEntry, 0, len(dirEntries))
+	for _, entry := range dirEntries {
+		le := listEntry{Name: entry.Name(), Dir: entry.IsDir()}
+		if info, err := entry.Info(); err == nil {
+			if !le.Dir {
+				le.Size = info.Size()
+			}
+			le.Mtime = info.ModTime().UnixNano()
+		}
+		entries = append(entries, le)
+	}
+	if data, err := json.Marshal(entries); err == nil {
+		e.cache.SetDirectory(cacheKey, string(data), dirInfo.ModTime())
+	}
+	return entries, nil
+}
+
+// FormatListView renders a ListDirectoryView result in the list_directory
+// text styles, ending with the window and, when there is more, the offset of
+// the next page
+func FormatListView(l *DirectoryListing, compact bool) string {
+	v := l.View
+	var window string
+	switch {
+	case len(l.Entries) == 0:
+		window = fmt.Sprintf("0 of %d", v.Matched)
+	default:
+		window = fmt.Sprintf("%d-%d of %d", v.Offset+1, v.Offset+len(l.Entries), v.Matched)
+	}
+	var scope []string
+	if v.Filter != "" {
+		scope = append(scope, fmt.Sprintf("matching %q", v.Filter))
+	}
+	if v.DirsOnly {
+		scope = append(scope, "dirs only")
+	}
+	if v.FilesOnly {
+		scope = append(scope, "files only")
+	}
+	if len(scope) > 0 || v.Matched != l.Total {
+		window += fmt.Sprintf(" (%s; %d entries in total)", strings.Join(scope, ", "), l.Total)
+	}
+
+	var sb strings.Builder
+	if compact {
+		sb.WriteString(fmt.Sprintf("%s |", l.Path))
+		for _, entry := range l.Entries {
+			sb.WriteString(" ")
+			switch {
+			case entry.Type == "dir":
+				sb.WriteString(entry.Name + "/")
+			case entry.Size > 512:
+				sb.WriteString(fmt.Sprintf("%s(%s)", entry.Name, formatSize(entry.Size)))
+			default:
+				sb.WriteString(entry.Name)
+			}
+			if entry.Git != "" {
+				sb.WriteString("[" + entry.Git + "]")
+			}
+		}
+		sb.WriteString(fmt.Sprintf(" | %s, %s %s", window, v.Sort, v.Order))
+		if v.NextOffset > 0 {
+			sb.WriteString(fmt.Sprintf(" | next offset:%d", v.NextOffset))
+		}
+		return sb.String()
+	}
+
+	for _, entry := range l.Entries {
+		if entry.Type == "dir" {
+			sb.WriteString(fmt.Sprintf("DIR  %s/", entry.Name))
+		} else {
+			sb.WriteString(fmt.Sprintf("FILE %s %s", entry.Name, formatSize(entry.Size)))
+		}
+		if v.Sort == "mtime" && entry.Modified != "" {
+			sb.WriteString(" " + entry.Modified)
+		}
+		if entry.Git != "" {
+			sb.WriteString(" [" + entry.Git + "]")
+		}
+		sb.WriteString(fmt.Sprintf(" | %s\n", l.Path))
+	}
+	sb.WriteString(fmt.Sprintf("--- | entries %s, sorted by %s %s | %s", window, v.Sort, v.Order, l.Path))
+	if v.NextOffset > 0 {
+		sb.WriteString(fmt.Sprintf("\nMore entries: list_directory(path:%q, offset:%d) with the same sort/filter", l.Path, v.NextOffset))
+	}
+	return sb.String()
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestListDirectoryView_SortFilterAndPaging(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	ctx := context.Background()
+	now := time.Now()
+	for i, name := range []string{"a.go", "b.txt", "c.go", "d.go"} {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, []byte(strings.Repeat("x", (i+1)*10)), 0644)
+		os.Chtimes(path, now, now.Add(time.Duration(i)*time.Minute))
+	}
+	os.Mkdir(filepath.Join(dir, "sub"), 0755)
+
+	names := func(l *DirectoryListing) string {
+		var out []string
+		for _, entry := range l.Entries {
+			out = append(out, entry.Name)
+		}
+		return strings.Join(out, ",")
+	}
+
+	l, err := engine.ListDirectoryView(ctx, dir, ListOptions{Sort: "mtime", Filter: "*.go", Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names(l) != "d.go,c.go" || l.Total != 5 || l.View.Matched != 3 || l.View.NextOffset != 2 || !l.Truncated {
+		t.Errorf("page 1 = %s %+v", names(l), l.View)
+	}
+	text := FormatListView(l, false)
+	if !strings.Contains(text, `entries 1-2 of 3 (matching "*.go"; 5 entries in total), sorted by mtime desc`) || !strings.Contains(text, "offset:2") {
+		t.Errorf("text:\n%s", text)
+	}
+
+	l, _ = engine.ListDirectoryView(ctx, dir, ListOptions{Sort: "mtime", Filter: "*.go", Limit: 2, Offset: 2})
+	if names(l) != "a.go" || l.View.NextOffset != 0 {
+		t.Errorf("page 2 = %s %+v", names(l), l.View)
+	}
+
+	l, _ = engine.ListDirectoryView(ctx, dir, ListOptions{Sort: "size", Order: "asc", FilesOnly: true})
+	if names(l) != "a.go,b.txt,c.go,d.go" {
+		t.Errorf("size asc = %s", names(l))
+	}
+	l, _ = engine.ListDirectoryView(ctx, dir, ListOptions{DirsOnly: true})
+	if names(l) != "sub" {
+		t.Errorf("dirs only = %s", names(l))
+	}
+
+	// The cached raw entries are dropped when the directory changes
+	if err := engine.WriteFileContent(ctx, filepath.Join(dir, "e.go"), "new"); err != nil {
+		t.Fatal(err)
+	}
+	l, _ = engine.ListDirectoryView(ctx, dir, ListOptions{Filter: "*.go"})
+	if names(l) != "a.go,c.go,d.go,e.go" {
+		t.Errorf("after write = %s", names(l))
+	}
+
+	for _, bad := range []ListOptions{{Sort: "color"}, {Order: "up"}, {Filter: "["}, {DirsOnly: true, FilesOnly: true}} {
+		if _, err := engine.ListDirectoryView(ctx, dir, bad); err == nil {
+			t.Errorf("%+v accepted", bad)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// metricsNamespace prefixes every exported metric name
+const metricsNamespace = "mcp_fs_"
+
+// startMetricsServer serves /metrics in the Prometheus text exposition format
+// (also accepted by OpenMetrics scrapers) on addr. Only used with
+// Config.MetricsAddr; the stdio server never opens a port otherwise.
+func (e *UltraFastEngine) startMetricsServer(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Write([]byte(e.MetricsText()))
+	})
+	e.metricsServer = &http.Server{Addr: ln.Addr().String(), Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if err := e.metricsServer.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Warn("Metrics endpoint stopped", "error", err)
+		}
+	}()
+	slog.Info("Metrics endpoint enabled", "addr", e.metricsServer.Addr)
+	return nil
+}
+
+// closeMetricsServer shuts the /metrics listener down, letting in-flight
+// scrapes finish for up to two seconds
+func (e *UltraFastEngine) closeMetricsServer() {
+	if e.metricsServer == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	e.metricsServer.Shutdown(ctx)
+}
+
+// promWriter builds a text exposition: one HELP/TYPE header per metric
+// followed by its samples
+type promWriter struct {
+	b strings.Builder
+}
+
+func (w *promWriter) header(name, kind, help string) {
+	fmt.Fprintf(&w.b, "# HELP %s%s %s\n# TYPE %s%s %s\n", metricsNamespace, name, help, metricsNamespace, name, kind)
+}
+
+// sample writes one value; labels alternate name, value
+func (w *promWriter) sample(name string, value float64, labels ...string) {
+	w.b.WriteString(metricsNamespace + name)
+	if len(labels) > 0 {
+		w.b.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				w.b.WriteByte(',')
+			}
+			fmt.Fprintf(&w.b, `%s="%s"`, labels[i], labelEscaper.Replace(labels[i+1]))
+		}
+		w.b.WriteByte('}')
+	}
+	w.b.WriteByte(' ')
+	w.b.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+	w.b.WriteByte('\n')
+}
+
+// labelEscaper applies the only escapes the exposition format defines
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// MetricsText renders the engine metrics for /metrics: operation counters and
+// rates, latency quantiles and tool errors, cache, backups and the auto-sync
+// queue.
+func (e *UltraFastEngine) MetricsText() string {
+	var w promWriter
+
+	e.metrics.mu.RLock()
+	m := struct {
+		total, reads, writes, lists, searches, edits, targeted, rewrites, slow int64
+		rate, rate1m, hitRate                                                  float64
+		since                                                                  time.Time
+	}{
+		e.metrics.OperationsTotal, e.metrics.ReadOperations, e.metrics.WriteOperations, e.metrics.ListOperations,
+		e.metrics.SearchOperations, e.metrics.EditOperations, e.metrics.TargetedEdits, e.metrics.FullFileRewrites, e.metrics.SlowOperations,
+		e.metrics.OperationsPerSecond, e.metrics.OperationsRate1m, e.metrics.CacheHitRate,
+		e.metrics.TrackingSince,
+	}
+	e.metrics.mu.RUnlock()
+
+	w.header("operations_total", "counter", "Engine operations since the tracking window started.")
+	w.sample("operations_total", float64(m.total))
+	w.header("operations_by_type_total", "counter", "Engine operations by type.")
+	for _, op := range []struct {
+		name  string
+		count int64
+	}{{"read", m.reads}, {"write", m.writes}, {"list", m.lists}, {"search", m.searches}, {"edit", m.edits}} {
+		w.sample("operations_by_type_total", float64(op.count), "type", op.name)
+	}
+	w.header("edits_total", "counter", "Edit operations by size class (targeted: small old_text, rewrite: large old_text).")
+	w.sample("edits_total", float64(m.targeted), "class", "targeted")
+	w.sample("edits_total", float64(m.rewrites), "class", "rewrite")
+	w.header("slow_operations_total", "counter", "Tool calls that took at least --slow-op-threshold.")
+	w.sample("slow_operations_total", float64(m.slow))
+	w.header("operations_per_second", "gauge", "Operations per second over the last 5-second interval.")
+	w.sample("operations_per_second", m.rate)
+	w.header("operations_rate_1m", "gauge", "Exponentially weighted operations per second, 1-minute time constant.")
+	w.sample("operations_rate_1m", m.rate1m)
+	w.header("tracking_since_seconds", "gauge", "Unix time the counters started (engine start, persisted window or server_info reset_telemetry).")
+	w.sample("tracking_since_seconds", float64(m.since.Unix()))
+
+	stats := e.latency.stats()
+	w.header("latency_seconds", "gauge", "Latency quantiles per engine operation (kind=op) and MCP tool (kind=tool).")
+	for _, s := range stats {
+		for _, q := range []struct {
+			label string
+			d     time.Duration
+		}{{"0.5", s.P50}, {"0.95", s.P95}, {"0.99", s.P99}, {"1", s.Max}} {
+			w.sample("latency_seconds", q.d.Seconds(), "kind", s.Kind, "name", s.Name, "quantile", q.label)
+		}
+	}
+	w.header("tool_calls_total", "counter", "MCP tool calls by tool.")
+	for _, s := range stats {
+		if s.Kind == LatencyKindTool {
+			w.sample("tool_calls_total", float64(s.Count), "tool", s.Name)
+		}
+	}
+	w.header("tool_errors_total", "counter", "MCP tool calls that returned an error, by tool.")
+	for _, s := range stats {
+		if s.Kind == LatencyKindTool {
+			w.sample("tool_errors_total", float64(s.Errors), "tool", s.Name)
+		}
+	}
+
+	if e.cache != nil {
+		w.header("cache_hit_ratio", "gauge", "Overall cache hit ratio (0-1).")
+		w.sample("cache_hit_ratio", m.hitRate)
+		w.header("cache_memory_bytes", "gauge", "Bytes held by the cache.")
+		w.sample("cache_memory_bytes", float64(e.cache.GetMemoryUsage()))
+		types := e.cache.TypeStats()
+		sort.Slice(types, func(i, j int) bool { return types[i].Type < types[j].Type })
+		w.header("cache_requests_total", "counter", "Cache lookups by cache type and result.")
+		for _, t := range types {
+			w.sample("cache_requests_total", float64(t.Hits), "type", t.Type, "result", "hit")
+			w.sample("cache_requests_total", float64(t.Misses), "type", t.Type, "result", "miss")
+		}
+	}
+
+	if e.backupManager != nil {
+		count, bytes := e.backupManager.Totals()
+		w.header("backups", "gauge", "Backups currently kept.")
+		w.sample("backups", float64(count))
+		w.header("backup_bytes", "gauge", "Combined size of the kept backups.")
+		w.sample("backup_bytes", float64(bytes))
+	}
+
+	if as := e.autoSyncManager; as != nil {
+		w.header("autosync_pending", "gauge", "Files queued for the next auto-sync batch.")
+		w.sample("autosync_pending", float64(as.queue.depth()))
+		w.header("autosync_files_total", "counter", "Auto-sync copies by result.")
+		w.sample("autosync_files_total", float64(as.queue.copied.Load()), "result", "synced")
+		w.sample("autosync_files_total", float64(as.queue.failed.Load()), "result", "failed")
+	}
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	w.header("go_heap_alloc_bytes", "gauge", "Go heap bytes allocated.")
+	w.sample("go_heap_alloc_bytes", float64(mem.HeapAlloc))
+	w.header("go_goroutines", "gauge", "Goroutines.")
+	w.sample("go_goroutines", float64(runtime.NumGoroutine()))
+	return w.b.String()
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mcp/filesystem-ultra/cache"
+)
+
+func TestMetricsEndpoint_ServesAndShutsDown(t *testing.T) {
+	dir := t.TempDir()
+	c, err := cache.NewIntelligentCache(1024 * 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	engine, err := NewUltraFastEngine(&Config{Cache: c, AllowedPaths: []string{dir}, ParallelOps: 2,
+		BackupDir: t.TempDir(), MetricsAddr: "127.0.0.1:0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	engine.WriteFileContent(ctx, filepath.Join(dir, "a.txt"), `he said "hi"`)
+	engine.ReadFileContent(ctx, filepath.Join(dir, "a.txt"))
+	engine.RecordToolLatency("read_file", 0)
+	engine.RecordToolError("read_file")
+
+	url := "http://" + engine.metricsServer.Addr + "/metrics"
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	text := string(body)
+	for _, want := range []string{
+		"# TYPE mcp_fs_operations_total counter",
+		`mcp_fs_operations_by_type_total{type="read"} 1`,
+		`mcp_fs_tool_errors_total{tool="read_file"} 1`,
+		`mcp_fs_latency_seconds{kind="op",name="write",quantile="0.99"}`,
+		"mcp_fs_cache_hit_ratio ",
+		"mcp_fs_backups ",
+		"mcp_fs_autosync_pending 0",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("/metrics missing %q", want)
+		}
+	}
+
+	engine.Close()
+	if _, err := http.Get(url); err == nil {
+		t.Error("metrics endpoint still serving after Close")
+	}
+}
+
+func TestMetricsEndpoint_OffByDefault(t *testing.T) {
+	engine, _ := setupProgressEngine(t)
+	if engine.metricsServer != nil {
+		t.Error("metrics server started without MetricsAddr")
+	}
+}
+
+func TestPromWriter_EscapesLabels(t *testing.T) {
+	var w promWriter
+	w.sample("x", 1.5, "path", "C:\\a \"b\"\nc")
+	if got, want := w.b.String(), `mcp_fs_x{path="C:\\a \"b\"\nc"} 1.5`+"\n"; got != want {
+		t.Errorf("sample = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,179 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// metricsStateFile holds the persisted counters under the backup dir
+const metricsStateFile = "metrics-state.json"
+
+// metricsPersistInterval is how often the counters are saved between
+// startup and Close
+const metricsPersistInterval = time.Minute
+
+// persistedMetrics is the on-disk form of the cumulative PerformanceMetrics
+// counters and edit telemetry. Rates, latency percentiles, cache hit rate
+// and memory usage describe the running process and are not persisted.
+type persistedMetrics struct {
+	TrackingSince time.Time `json:"tracking_since"`
+	SavedAt       time.Time `json:"saved_at"`
+
+	OperationsTotal  int64 `json:"operations_total"`
+	ReadOperations   int64 `json:"read_operations"`
+	WriteOperations  int64 `json:"write_operations"`
+	ListOperations   int64 `json:"list_operations"`
+	SearchOperations int64 `json:"search_operations"`
+
+	EditOperations      int64   `json:"edit_operations"`
+	TargetedEdits       int64   `json:"targeted_edits"`
+	FullFileRewrites    int64   `json:"full_file_rewrites"`
+	LastEditOperation   string  `json:"last_edit_operation,omitempty"`
+	LastEditBytesSent   int64   `json:"last_edit_bytes_sent"`
+	AverageBytesPerEdit float64 `json:"average_bytes_per_edit"`
+}
+
+// metricsPersistence saves the counters to path every
+// metricsPersistInterval until stopped
+type metricsPersistence struct {
+	path string
+	stop chan struct{}
+	done chan struct{}
+}
+
+// enableMetricsPersistence restores the counters from dir and starts the
+// periodic save. A missing or unreadable file starts a fresh tracking window.
+func (e *UltraFastEngine) enableMetricsPersistence(dir string) {
+	p := &metricsPersistence{
+		path: filepath.Join(dir, metricsStateFile),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	if err := e.loadMetrics(p.path); err != nil && !os.IsNotExist(err) {
+		slog.Warn("Persisted metrics unusable, starting a new tracking window", "path", p.path, "error", err)
+	}
+	e.metricsPersist = p
+
+	go func() {
+		defer close(p.done)
+		ticker := time.NewTicker(metricsPersistInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.stop:
+				return
+			case <-ticker.C:
+				if err := e.saveMetrics(); err != nil {
+					slog.Debug("Failed to persist metrics", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// closeMetricsPersistence stops the periodic save and writes a final copy
+func (e *UltraFastEngine) closeMetricsPersistence() {
+	p := e.metricsPersist
+	if p == nil {
+		return
+	}
+	select {
+	case <-p.stop:
+		return // already closed
+	default:
+		close(p.stop)
+	}
+	<-p.done
+	if err := e.saveMetrics(); err != nil {
+		slog.Warn("Failed to persist metrics", "error", err)
+	}
+}
+
+func (e *UltraFastEngine) loadMetrics(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var saved persistedMetrics
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	m := e.metrics
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !saved.TrackingSince.IsZero() {
+		m.TrackingSince = saved.TrackingSince
+	}
+	m.OperationsTotal = saved.OperationsTotal
+	m.opsAtLastUpdate = m.OperationsTotal
+	m.ReadOperations = saved.ReadOperations
+	m.WriteOperations = saved.WriteOperations
+	m.ListOperations = saved.ListOperations
+	m.SearchOperations = saved.SearchOperations
+	m.EditOperations = saved.EditOperations
+	m.TargetedEdits = saved.TargetedEdits
+	m.FullFileRewrites = saved.FullFileRewrites
+	m.LastEditOperation = saved.LastEditOperation
+	m.LastEditBytesSent = saved.LastEditBytesSent
+	m.AverageBytesPerEdit = saved.AverageBytesPerEdit
+	return nil
+}
+
+// saveMetrics writes the counters atomically (no-op without persistence)
+func (e *UltraFastEngine) saveMetrics() error {
+	if e.metricsPersist == nil {
+		return nil
+	}
+	m := e.metrics
+	m.mu.RLock()
+	state := persistedMetrics{
+		TrackingSince:       m.TrackingSince,
+		SavedAt:             time.Now(),
+		OperationsTotal:     m.OperationsTotal,
+		ReadOperations:      m.ReadOperations,
+		WriteOperations:     m.WriteOperations,
+		ListOperations:      m.ListOperations,
+		SearchOperations:    m.SearchOperations,
+		EditOperations:      m.EditOperations,
+		TargetedEdits:       m.TargetedEdits,
+		FullFileRewrites:    m.FullFileRewrites,
+		LastEditOperation:   m.LastEditOperation,
+		LastEditBytesSent:   m.LastEditBytesSent,
+		AverageBytesPerEdit: m.AverageBytesPerEdit,
+	}
+	m.mu.RUnlock()
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := e.metricsPersist.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, e.metricsPersist.path)
+}
+
+// ResetTelemetry zeroes the operation counters, edit telemetry and latency
+// histograms and starts a new tracking window, saving it right away when
+// metrics are persisted. It returns the start of the window that was
+// discarded.
+func (e *UltraFastEngine) ResetTelemetry() (time.Time, error) {
+	m := e.metrics
+	m.mu.Lock()
+	previous := m.TrackingSince
+	m.TrackingSince = time.Now()
+	m.OperationsTotal, m.opsAtLastUpdate = 0, 0
+	m.ReadOperations, m.WriteOperations, m.ListOperations, m.SearchOperations = 0, 0, 0, 0
+	m.SlowOperations = 0
+	m.EditOperations, m.TargetedEdits, m.FullFileRewrites = 0, 0, 0
+	m.LastEditOperation, m.LastEditBytesSent, m.AverageBytesPerEdit = "", 0, 0
+	m.mu.Unlock()
+	e.latency.reset()
+	return previous, e.saveMetrics()
+}
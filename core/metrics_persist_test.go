@@ -0,0 +1,106 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mcp/filesystem-ultra/cache"
+)
+
+func newPersistMetricsEngine(t *testing.T, dir, backupDir string) *UltraFastEngine {
+	t.Helper()
+	c, err := cache.NewIntelligentCache(1024 * 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	engine, err := NewUltraFastEngine(&Config{Cache: c, AllowedPaths: []string{dir}, ParallelOps: 2, BackupDir: backupDir, PersistMetrics: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return engine
+}
+
+func TestMetricsPersistence_RestoredAfterRestartAndReset(t *testing.T) {
+	dir, backupDir := t.TempDir(), t.TempDir()
+	engine := newPersistMetricsEngine(t, dir, backupDir)
+	started := engine.metrics.TrackingSince
+	if err := engine.WriteFileContent(context.Background(), filepath.Join(dir, "a.txt"), "hello"); err != nil {
+		t.Fatal(err)
+	}
+	engine.LogEditTelemetry(10, 12, filepath.Join(dir, "a.txt"))
+	engine.LogEditTelemetry(10, 12, filepath.Join(dir, "a.txt"))
+	writes := engine.metrics.WriteOperations
+	engine.Close()
+	if _, err := os.Stat(filepath.Join(backupDir, metricsStateFile)); err != nil {
+		t.Fatalf("metrics not saved on Close: %v", err)
+	}
+
+	restarted := newPersistMetricsEngine(t, dir, backupDir)
+	defer restarted.Close()
+	m := restarted.metrics
+	if m.EditOperations != 2 || m.WriteOperations != writes || !m.TrackingSince.Equal(started) {
+		t.Fatalf("restored edits=%d writes=%d since=%v, want 2, %d, %v", m.EditOperations, m.WriteOperations, m.TrackingSince, writes, started)
+	}
+	if summary := restarted.GetEditTelemetrySummary(); summary["tracking_since"] != started.Format(time.RFC3339) {
+		t.Errorf("summary tracking_since = %v", summary["tracking_since"])
+	}
+
+	previous, err := restarted.ResetTelemetry()
+	if err != nil || !previous.Equal(started) {
+		t.Fatalf("ResetTelemetry = %v, %v", previous, err)
+	}
+	if m.EditOperations != 0 || m.OperationsTotal != 0 || !m.TrackingSince.After(started) {
+		t.Errorf("after reset edits=%d ops=%d since=%v", m.EditOperations, m.OperationsTotal, m.TrackingSince)
+	}
+	// The reset is saved right away, not at the next tick
+	reloaded := &UltraFastEngine{metrics: &PerformanceMetrics{}}
+	if err := reloaded.loadMetrics(filepath.Join(backupDir, metricsStateFile)); err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.metrics.EditOperations != 0 || !reloaded.metrics.TrackingSince.Equal(m.TrackingSince) {
+		t.Errorf("saved state after reset: edits=%d since=%v", reloaded.metrics.EditOperations, reloaded.metrics.TrackingSince)
+	}
+}
+
+func TestMetricsPersistence_CorruptFileStartsFreshWindow(t *testing.T) {
+	dir, backupDir := t.TempDir(), t.TempDir()
+	os.WriteFile(filepath.Join(backupDir, metricsStateFile), []byte("{not json"), 0600)
+	before := time.Now()
+	engine := newPersistMetricsEngine(t, dir, backupDir)
+	defer engine.Close()
+	if engine.metrics.EditOperations != 0 || engine.metrics.TrackingSince.Before(before) {
+		t.Errorf("corrupt state was used: edits=%d since=%v", engine.metrics.EditOperations, engine.metrics.TrackingSince)
+	}
+}
+
+func TestUpdateMetrics_RatesUseLastInterval(t *testing.T) {
+	engine, _ := setupProgressEngine(t)
+	m := engine.metrics
+	t0 := time.Now()
+	m.LastUpdateTime = t0
+	m.OperationsTotal, m.opsAtLastUpdate = 1000, 1000 // lifetime total before the window
+
+	// Tick 1: 50 operations in 5 seconds
+	m.OperationsTotal += 50
+	engine.updateMetricsAt(t0.Add(5 * time.Second))
+	if m.OperationsPerSecond != 10 {
+		t.Errorf("ops/s after tick 1 = %v, want 10", m.OperationsPerSecond)
+	}
+	first := m.OperationsRate1m
+	if first <= 0 || first >= 10 {
+		t.Errorf("1m rate after tick 1 = %v, want between 0 and 10", first)
+	}
+
+	// Tick 2: idle for 5 seconds
+	engine.updateMetricsAt(t0.Add(10 * time.Second))
+	if m.OperationsPerSecond != 0 {
+		t.Errorf("ops/s after an idle tick = %v, want 0", m.OperationsPerSecond)
+	}
+	if m.OperationsRate1m <= 0 || m.OperationsRate1m >= first {
+		t.Errorf("1m rate after an idle tick = %v, want decaying from %v", m.OperationsRate1m, first)
+	}
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"syscall"
+)
+
+// renamePath is os.Rename; tests swap it to simulate a cross-device move
+var renamePath = os.Rename
+
+// errorNotSameDevice is Windows' ERROR_NOT_SAME_DEVICE, returned by
+// MoveFileEx when a rename crosses volumes (C: to D:)
+const errorNotSameDevice = syscall.Errno(17)
+
+// MoveResult is the move_file response
+type MoveResult struct {
+	Source string `json:"source"`
+	Dest   string `json:"dest"`
+	Dir    bool   `json:"dir"`
+	// CrossDevice is set when the rename crossed devices and the move was
+	// done as copy + verify + delete; Copy then describes the copy
+	CrossDevice bool        `json:"cross_device,omitempty"`
+	Copy        *CopyResult `json:"copy,omitempty"`
+}
+
+// isCrossDeviceError reports whether a rename failed because source and
+// destination are on different devices or volumes
+func isCrossDeviceError(err error) bool {
+	if errors.Is(err, syscall.EXDEV) {
+		return true
+	}
+	return runtime.GOOS == "windows" && errors.Is(err, errorNotSameDevice)
+}
+
+// moveAcrossDevices moves src to dst when a rename cannot: the file or tree
+// is copied (modes and mtimes kept), every copied file is hash-verified
+// against its source, and only then is the source removed. If the copy or
+// the verification fails, the partial destination is removed and the source
+// is left untouched. Trees holding symlinks are refused, as the copy would
+// drop them.
+func (e *UltraFastEngine) moveAcrossDevices(ctx context.Context, src, dst string, info os.FileInfo) (*CopyResult, error) {
+	plan := planFileCopy(src, dst, info)
+	if info.IsDir() {
+		var err error
+		if plan, err = planTreeCopy(ctx, src, dst, CopyOptions{}); err != nil {
+			return nil, fmt.Errorf("cross-device move: %w", err)
+		}
+		if plan.symlinks > 0 {
+			return nil, fmt.Errorf("cross-device move: %s holds %d symlinks, which cannot be copied; source left in place", src, plan.symlinks)
+		}
+	}
+
+	result, err := e.copyTree(ctx, plan, CopyOptions{})
+	if err == nil {
+		err = e.verifyCopy(plan, true, result)
+	}
+	if err != nil {
+		_ = os.RemoveAll(dst)
+		return nil, fmt.Errorf("cross-device move failed, source left in place: %w", err)
+	}
+	result.Source, result.Dest, result.Dir = src, dst, info.IsDir()
+
+	if err := os.RemoveAll(src); err != nil {
+		return result, fmt.Errorf("cross-device move: copied and verified %s, but removing the source failed: %w", dst, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,99 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// simulateCrossDevice makes every rename fail with EXDEV for the test
+func simulateCrossDevice(t *testing.T) {
+	renamePath = func(oldpath, newpath string) error {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: syscall.EXDEV}
+	}
+	t.Cleanup(func() { renamePath = os.Rename })
+}
+
+func TestMovePath_CrossDeviceFallback(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	simulateCrossDevice(t)
+	src := filepath.Join(dir, "c", "project")
+	os.MkdirAll(filepath.Join(src, "src"), 0755)
+	os.WriteFile(filepath.Join(src, "src", "main.go"), []byte("package main\n"), 0644)
+	os.WriteFile(filepath.Join(src, "build.sh"), []byte("#!/bin/sh\n"), 0750)
+	old := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	os.Chtimes(filepath.Join(src, "build.sh"), old, old)
+
+	dst := filepath.Join(dir, "d", "project")
+	os.MkdirAll(filepath.Dir(dst), 0755)
+	result, err := engine.MovePath(context.Background(), src, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.CrossDevice || result.Copy == nil || result.Copy.Files != 2 || result.Copy.VerifyMode != "full" || result.Copy.Verified != 2 {
+		t.Fatalf("result = %+v (copy %+v)", result, result.Copy)
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Errorf("source still present: %v", err)
+	}
+	info, err := os.Stat(filepath.Join(dst, "build.sh"))
+	if err != nil || info.Mode().Perm() != 0750 || !info.ModTime().Equal(old) {
+		t.Errorf("build.sh = %v %v, %v", info.Mode(), info.ModTime(), err)
+	}
+
+	file := filepath.Join(dir, "notes.txt")
+	os.WriteFile(file, []byte("notes"), 0644)
+	result, err = engine.MovePath(context.Background(), file, filepath.Join(dir, "d", "notes.txt"))
+	if err != nil || !result.CrossDevice || result.Copy.Files != 1 {
+		t.Fatalf("file move = %+v, %v", result, err)
+	}
+}
+
+func TestMovePath_CrossDeviceKeepsSourceOnFailure(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	simulateCrossDevice(t)
+	src := filepath.Join(dir, "project")
+	os.MkdirAll(src, 0755)
+	os.WriteFile(filepath.Join(src, "a.txt"), []byte("a"), 0644)
+	os.Symlink(filepath.Join(src, "a.txt"), filepath.Join(src, "link"))
+
+	dst := filepath.Join(dir, "moved")
+	_, err := engine.MovePath(context.Background(), src, dst)
+	if err == nil || !strings.Contains(err.Error(), "symlinks") {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(src, "a.txt")); err != nil {
+		t.Errorf("source touched: %v", err)
+	}
+	if _, err := os.Stat(dst); !os.IsNotExist(err) {
+		t.Errorf("partial destination left: %v", err)
+	}
+
+	// A copy that cannot complete is rolled back
+	os.Remove(filepath.Join(src, "link"))
+	os.WriteFile(filepath.Join(src, "b.txt"), []byte("b"), 0644)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := engine.moveAcrossDevices(ctx, src, dst, mustStat(t, src)); err == nil {
+		t.Fatal("canceled move succeeded")
+	}
+	if _, err := os.Stat(filepath.Join(src, "b.txt")); err != nil {
+		t.Errorf("source removed after failed copy: %v", err)
+	}
+	if _, err := os.Stat(dst); !os.IsNotExist(err) {
+		t.Errorf("partial destination left: %v", err)
+	}
+}
+
+func mustStat(t *testing.T, path string) os.FileInfo {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info
+}
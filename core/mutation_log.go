@@ -0,0 +1,416 @@
+package core
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Mutation log: an append-only, hash-chained JSONL record of every mutating
+// operation (whatever its risk level), for compliance. Each entry carries the
+// hash of the previous one, so editing or deleting a line breaks the chain
+// from that point on. Writes happen on a background goroutine and never block
+// or fail the operation being logged.
+const (
+	mutationLogFile           = "mutations.jsonl"
+	DefaultMutationLogMaxSize = 10 * 1024 * 1024
+	mutationLogQueueSize      = 1024
+)
+
+// MutationLogEntry is one line of the mutation log
+type MutationLogEntry struct {
+	Seq          int64     `json:"seq"`
+	Timestamp    time.Time `json:"ts"`
+	Tool         string    `json:"tool"`
+	Operation    string    `json:"op"`
+	Path         string    `json:"path"`
+	Dest         string    `json:"dest,omitempty"`
+	Bytes        int64     `json:"bytes,omitempty"`
+	Replacements int       `json:"replacements,omitempty"`
+	BackupID     string    `json:"backup_id,omitempty"`
+	RiskLevel    string    `json:"risk,omitempty"`
+	Forced       bool      `json:"forced,omitempty"`
+	DurationMs   int64     `json:"duration_ms"` // since the tool call started
+	PrevHash     string    `json:"prev_hash"`
+	Hash         string    `json:"hash"`
+}
+
+// computeHash hashes the entry (without its own hash) chained to PrevHash
+func (m MutationLogEntry) computeHash() string {
+	m.Hash = ""
+	data, _ := json.Marshal(m)
+	sum := sha256.Sum256(append([]byte(m.PrevHash+"\n"), data...))
+	return hex.EncodeToString(sum[:])
+}
+
+// mutationLogItem is a queued entry, or a flush marker when ack is set
+type mutationLogItem struct {
+	entry MutationLogEntry
+	ack   chan struct{}
+}
+
+// mutationLog appends entries to dir/mutations.jsonl, rotating it to
+// mutations-<time>.jsonl once it exceeds maxSize. Rotated files are kept.
+type mutationLog struct {
+	dir     string
+	maxSize int64
+	queue   chan mutationLogItem
+	done    chan struct{}
+
+	closeMu sync.RWMutex // guards closed against sends on the closed queue
+	closed  bool
+
+	mu       sync.Mutex // file, size, lastHash, seq
+	file     *os.File
+	size     int64
+	lastHash string
+	seq      int64
+
+	dropped atomic.Int64 // queue full
+	failed  atomic.Int64 // write errors
+}
+
+func newMutationLog(dir string, maxSize int64) (*mutationLog, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultMutationLogMaxSize
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	l := &mutationLog{
+		dir:     dir,
+		maxSize: maxSize,
+		queue:   make(chan mutationLogItem, mutationLogQueueSize),
+		done:    make(chan struct{}),
+	}
+	// Continue the chain from the newest entry on disk
+	files := l.files()
+	for i := len(files) - 1; i >= 0; i-- {
+		if last, ok := lastMutationEntry(files[i]); ok {
+			l.lastHash, l.seq = last.Hash, last.Seq
+			break
+		}
+	}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	go l.run()
+	return l, nil
+}
+
+func (l *mutationLog) open() error {
+	f, err := os.OpenFile(filepath.Join(l.dir, mutationLogFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	l.file, l.size = f, info.Size()
+	return nil
+}
+
+// add queues an entry; when the queue is full it is dropped and counted
+func (l *mutationLog) add(entry MutationLogEntry) {
+	l.closeMu.RLock()
+	defer l.closeMu.RUnlock()
+	if l.closed {
+		l.dropped.Add(1)
+		return
+	}
+	select {
+	case l.queue <- mutationLogItem{entry: entry}:
+	default:
+		l.dropped.Add(1)
+	}
+}
+
+func (l *mutationLog) run() {
+	defer close(l.done)
+	for item := range l.queue {
+		if item.ack != nil {
+			close(item.ack)
+			continue
+		}
+		if err := l.write(item.entry); err != nil {
+			l.failed.Add(1)
+			slog.Debug("Mutation log write failed", "error", err)
+		}
+	}
+}
+
+func (l *mutationLog) write(entry MutationLogEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		if err := l.open(); err != nil {
+			return err
+		}
+	}
+	entry.Seq = l.seq + 1
+	entry.PrevHash = l.lastHash
+	entry.Hash = entry.computeHash()
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if l.size > 0 && l.size+int64(len(data)) > l.maxSize {
+		l.rotate()
+	}
+	if l.file == nil {
+		return fmt.Errorf("mutation log not open")
+	}
+	n, err := l.file.Write(data)
+	l.size += int64(n)
+	if err != nil {
+		return err
+	}
+	l.seq, l.lastHash = entry.Seq, entry.Hash
+	return nil
+}
+
+// rotate renames the current file aside and opens a fresh one (caller holds
+// mu). On failure the current file keeps growing.
+func (l *mutationLog) rotate() {
+	l.file.Close()
+	l.file = nil
+	current := filepath.Join(l.dir, mutationLogFile)
+	rotated := filepath.Join(l.dir, "mutations-"+time.Now().Format("20060102T150405.000000000")+".jsonl")
+	if err := os.Rename(current, rotated); err != nil {
+		slog.Warn("Mutation log rotation failed", "error", err)
+	}
+	if err := l.open(); err != nil {
+		slog.Warn("Mutation log reopen failed", "error", err)
+	}
+}
+
+// flush waits until every entry queued before the call has been written
+func (l *mutationLog) flush() {
+	l.closeMu.RLock()
+	if l.closed {
+		l.closeMu.RUnlock()
+		return
+	}
+	ack := make(chan struct{})
+	l.queue <- mutationLogItem{ack: ack}
+	l.closeMu.RUnlock()
+	<-ack
+}
+
+// close writes the queued entries and closes the file
+func (l *mutationLog) close() {
+	l.closeMu.Lock()
+	if l.closed {
+		l.closeMu.Unlock()
+		return
+	}
+	l.closed = true
+	close(l.queue)
+	l.closeMu.Unlock()
+	<-l.done
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file != nil {
+		l.file.Close()
+		l.file = nil
+	}
+}
+
+// files lists the log files oldest first (rotated files, then the current one)
+func (l *mutationLog) files() []string {
+	rotated, _ := filepath.Glob(filepath.Join(l.dir, "mutations-*.jsonl"))
+	sort.Strings(rotated)
+	current := filepath.Join(l.dir, mutationLogFile)
+	if _, err := os.Stat(current); err == nil {
+		rotated = append(rotated, current)
+	}
+	return rotated
+}
+
+// eachMutationEntry calls fn for every parseable line of path, in order
+func eachMutationEntry(path string, fn func(MutationLogEntry)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry MutationLogEntry
+		if json.Unmarshal(scanner.Bytes(), &entry) == nil {
+			fn(entry)
+		}
+	}
+	return scanner.Err()
+}
+
+func lastMutationEntry(path string) (last MutationLogEntry, ok bool) {
+	eachMutationEntry(path, func(e MutationLogEntry) { last, ok = e, true })
+	return last, ok
+}
+
+// MutationLogQuery filters ReadMutationLog
+type MutationLogQuery struct {
+	Limit      int       // newest entries to return (<= 0 = all)
+	FilterPath string    // only entries whose path or dest is this path or below it
+	Since      time.Time // only entries at or after this time (zero = no filter)
+}
+
+// MutationLogResult is what server_info audit_log returns
+type MutationLogResult struct {
+	Entries []MutationLogEntry `json:"entries"` // newest first
+	Scanned int64              `json:"scanned"`
+	// ChainValid is false when an entry's hash does not match its content or
+	// the previous entry; BrokenAtSeq is the first such entry.
+	ChainValid  bool   `json:"chain_valid"`
+	BrokenAtSeq int64  `json:"broken_at_seq,omitempty"`
+	Files       int    `json:"files"`
+	Dropped     int64  `json:"dropped,omitempty"`
+	Failed      int64  `json:"failed,omitempty"`
+	Dir         string `json:"dir"`
+}
+
+// read scans every log file oldest first, verifying the hash chain, and
+// returns the newest entries matching q
+func (l *mutationLog) read(q MutationLogQuery) *MutationLogResult {
+	l.flush()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	res := &MutationLogResult{ChainValid: true, Dir: l.dir, Dropped: l.dropped.Load(), Failed: l.failed.Load()}
+	filter := ""
+	if q.FilterPath != "" {
+		filter = filepath.Clean(NormalizePath(q.FilterPath))
+	}
+	var matched []MutationLogEntry
+	prev := ""
+	files := l.files()
+	res.Files = len(files)
+	for _, path := range files {
+		eachMutationEntry(path, func(e MutationLogEntry) {
+			res.Scanned++
+			if res.ChainValid && (e.PrevHash != prev || e.computeHash() != e.Hash) {
+				res.ChainValid, res.BrokenAtSeq = false, e.Seq
+			}
+			prev = e.Hash
+			if !q.Since.IsZero() && e.Timestamp.Before(q.Since) {
+				return
+			}
+			if filter != "" && !pathWithin(e.Path, filter) && (e.Dest == "" || !pathWithin(e.Dest, filter)) {
+				return
+			}
+			matched = append(matched, e)
+		})
+	}
+
+	if q.Limit > 0 && len(matched) > q.Limit {
+		matched = matched[len(matched)-q.Limit:]
+	}
+	res.Entries = make([]MutationLogEntry, len(matched))
+	for i, e := range matched {
+		res.Entries[len(matched)-1-i] = e
+	}
+	return res
+}
+
+// pathWithin reports whether path is root or below it
+func pathWithin(path, root string) bool {
+	path = filepath.Clean(path)
+	return path == root || strings.HasPrefix(path, strings.TrimSuffix(root, string(filepath.Separator))+string(filepath.Separator))
+}
+
+// logMutation appends a recorded operation to the mutation log (no-op when
+// it is not configured)
+func (e *UltraFastEngine) logMutation(rec OperationRecord, started time.Time) {
+	if e.mutationLog == nil {
+		return
+	}
+	entry := MutationLogEntry{
+		Timestamp:    rec.Timestamp,
+		Tool:         rec.Tool,
+		Operation:    rec.Operation,
+		Path:         filepath.Clean(NormalizePath(rec.Path)),
+		Bytes:        rec.BytesWritten,
+		Replacements: rec.Replacements,
+		BackupID:     rec.BackupID,
+		RiskLevel:    rec.RiskLevel,
+		Forced:       rec.Forced,
+	}
+	if rec.Dest != "" {
+		entry.Dest = filepath.Clean(NormalizePath(rec.Dest))
+	}
+	if !started.IsZero() {
+		entry.DurationMs = rec.Timestamp.Sub(started).Milliseconds()
+	}
+	e.mutationLog.add(entry)
+}
+
+// ReadMutationLog returns the newest mutation log entries matching q and the
+// result of verifying the hash chain. It fails when the log is not enabled.
+func (e *UltraFastEngine) ReadMutationLog(q MutationLogQuery) (*MutationLogResult, error) {
+	if e.mutationLog == nil {
+		return nil, fmt.Errorf("mutation audit log is not enabled (start the server with --mutation-log-dir)")
+	}
+	return e.mutationLog.read(q), nil
+}
+
+// FormatMutationLog renders server_info audit_log output (compact or verbose)
+func FormatMutationLog(res *MutationLogResult, compact bool) string {
+	chain := "chain ok"
+	if !res.ChainValid {
+		chain = fmt.Sprintf("CHAIN BROKEN at seq %d", res.BrokenAtSeq)
+	}
+	var b strings.Builder
+	if compact {
+		b.WriteString(fmt.Sprintf("%d entries (%d scanned, %s)", len(res.Entries), res.Scanned, chain))
+	} else {
+		b.WriteString(fmt.Sprintf("Mutation audit log: %s (%d files)\n", res.Dir, res.Files))
+		b.WriteString(fmt.Sprintf("Entries: %d shown of %d scanned, %s", len(res.Entries), res.Scanned, chain))
+		if res.Dropped > 0 || res.Failed > 0 {
+			b.WriteString(fmt.Sprintf("\nWARNING: %d entries dropped (queue full), %d write failures since startup", res.Dropped, res.Failed))
+		}
+		b.WriteString("\n")
+	}
+	for _, e := range res.Entries {
+		b.WriteString(fmt.Sprintf("\n#%d %s %s %s %s", e.Seq, e.Timestamp.Format(time.RFC3339), e.Tool, e.Operation, e.Path))
+		if e.Dest != "" {
+			b.WriteString(" -> " + e.Dest)
+		}
+		if compact {
+			continue
+		}
+		var details []string
+		if e.Bytes > 0 {
+			details = append(details, FormatSize(e.Bytes))
+		}
+		if e.Replacements > 0 {
+			details = append(details, fmt.Sprintf("%d replacements", e.Replacements))
+		}
+		if e.RiskLevel != "" {
+			details = append(details, "risk "+e.RiskLevel)
+		}
+		if e.Forced {
+			details = append(details, "forced")
+		}
+		if e.BackupID != "" {
+			details = append(details, "backup "+e.BackupID)
+		}
+		details = append(details, fmt.Sprintf("%dms", e.DurationMs))
+		b.WriteString(" (" + strings.Join(details, ", ") + ")")
+	}
+	return b.String()
+}
@@ -0,0 +1,124 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mcp/filesystem-ultra/cache"
+)
+
+func newMutationLogEngine(t *testing.T, dir, logDir string, maxSize int64) *UltraFastEngine {
+	t.Helper()
+	c, err := cache.NewIntelligentCache(1024 * 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	engine, err := NewUltraFastEngine(&Config{Cache: c, AllowedPaths: []string{dir}, ParallelOps: 2,
+		BackupDir: t.TempDir(), MutationLogDir: logDir, MutationLogMaxSize: maxSize})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return engine
+}
+
+func TestMutationLog_RecordsChainsAndFilters(t *testing.T) {
+	dir, logDir := t.TempDir(), t.TempDir()
+	engine := newMutationLogEngine(t, dir, logDir, 0)
+	ctx := context.Background()
+	os.MkdirAll(filepath.Join(dir, "src"), 0755)
+	engine.WriteFileContent(ctx, filepath.Join(dir, "src", "a.go"), "package a")
+	engine.WriteFileContent(ctx, filepath.Join(dir, "notes.txt"), "hello")
+	engine.MoveFile(ctx, filepath.Join(dir, "notes.txt"), filepath.Join(dir, "src", "notes.txt"))
+	engine.DeleteFile(ctx, filepath.Join(dir, "src", "a.go"))
+
+	res, err := engine.ReadMutationLog(MutationLogQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Entries) != 4 || !res.ChainValid {
+		t.Fatalf("entries=%d chain_valid=%v", len(res.Entries), res.ChainValid)
+	}
+	newest := res.Entries[0]
+	if newest.Operation != "delete" || newest.Seq != 4 || newest.PrevHash != res.Entries[1].Hash {
+		t.Errorf("newest entry = %+v", newest)
+	}
+
+	// The move's destination is under src/, so it matches the filter
+	filtered, _ := engine.ReadMutationLog(MutationLogQuery{FilterPath: filepath.Join(dir, "src")})
+	if len(filtered.Entries) != 3 {
+		t.Errorf("filter_path matched %d entries, want 3", len(filtered.Entries))
+	}
+	if limited, _ := engine.ReadMutationLog(MutationLogQuery{Limit: 1}); len(limited.Entries) != 1 || limited.Entries[0].Seq != 4 {
+		t.Errorf("limit 1 = %+v", limited.Entries)
+	}
+
+	// A restart continues the chain
+	engine.Close()
+	engine = newMutationLogEngine(t, dir, logDir, 0)
+	defer engine.Close()
+	engine.WriteFileContent(ctx, filepath.Join(dir, "b.txt"), "b")
+	res, _ = engine.ReadMutationLog(MutationLogQuery{Limit: 1})
+	if res.Entries[0].Seq != 5 || !res.ChainValid {
+		t.Errorf("after restart: seq=%d chain_valid=%v", res.Entries[0].Seq, res.ChainValid)
+	}
+
+	// Editing a line breaks the chain at that entry
+	path := filepath.Join(logDir, mutationLogFile)
+	data, _ := os.ReadFile(path)
+	os.WriteFile(path, []byte(strings.Replace(string(data), `"op":"move"`, `"op":"copy"`, 1)), 0600)
+	res, _ = engine.ReadMutationLog(MutationLogQuery{})
+	if res.ChainValid || res.BrokenAtSeq != 3 {
+		t.Errorf("tampered log: chain_valid=%v broken_at=%d", res.ChainValid, res.BrokenAtSeq)
+	}
+	if !strings.Contains(FormatMutationLog(res, false), "CHAIN BROKEN at seq 3") {
+		t.Error("formatted log does not flag the broken chain")
+	}
+}
+
+func TestMutationLog_RecordsBatchOperations(t *testing.T) {
+	dir, logDir := t.TempDir(), t.TempDir()
+	engine := newMutationLogEngine(t, dir, logDir, 0)
+	defer engine.Close()
+	m := NewBatchOperationManager(t.TempDir(), 10)
+	m.SetEngine(engine)
+	result := m.ExecuteBatch(BatchRequest{Operations: []FileOperation{
+		{Type: "write", Path: filepath.Join(dir, "a.txt"), Content: "abc"},
+		{Type: "copy", Source: filepath.Join(dir, "a.txt"), Destination: filepath.Join(dir, "b.txt")},
+	}})
+	if !result.Success {
+		t.Fatalf("batch failed: %v", result.Errors)
+	}
+	res, _ := engine.ReadMutationLog(MutationLogQuery{})
+	if len(res.Entries) != 2 || res.Entries[1].Operation != "batch_write" || res.Entries[1].Bytes != 3 ||
+		res.Entries[0].Tool != "batch_operations" || res.Entries[0].Dest != filepath.Join(dir, "b.txt") {
+		t.Errorf("batch entries = %+v", res.Entries)
+	}
+}
+
+func TestMutationLog_RotatesBySize(t *testing.T) {
+	dir, logDir := t.TempDir(), t.TempDir()
+	engine := newMutationLogEngine(t, dir, logDir, 600) // a couple of entries per file
+	defer engine.Close()
+	ctx := context.Background()
+	for i := 0; i < 6; i++ {
+		engine.WriteFileContent(ctx, filepath.Join(dir, "f.txt"), strings.Repeat("x", i+1))
+	}
+	res, err := engine.ReadMutationLog(MutationLogQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Files < 2 || len(res.Entries) != 6 || !res.ChainValid {
+		t.Errorf("files=%d entries=%d chain_valid=%v", res.Files, len(res.Entries), res.ChainValid)
+	}
+}
+
+func TestMutationLog_DisabledByDefault(t *testing.T) {
+	engine, _ := setupProgressEngine(t)
+	if _, err := engine.ReadMutationLog(MutationLogQuery{}); err == nil || !strings.Contains(err.Error(), "--mutation-log-dir") {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mcp/filesystem-ultra/cache"
+)
+
+func setupNegativeCacheEngine(t *testing.T, ttl time.Duration) (*UltraFastEngine, string) {
+	t.Helper()
+	dir := t.TempDir()
+	c, err := cache.NewIntelligentCache(1024 * 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine, err := NewUltraFastEngine(&Config{Cache: c, AllowedPaths: []string{dir}, ParallelOps: 2, NegativeCacheTTL: ttl})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { engine.Close(); c.Close() })
+	return engine, dir
+}
+
+func TestNegativeCache_RepeatedProbesAndInvalidation(t *testing.T) {
+	engine, dir := setupNegativeCacheEngine(t, time.Minute)
+	ctx := context.Background()
+	path := filepath.Join(dir, "config.yaml")
+
+	_, first := engine.ReadFileContent(ctx, path)
+	if first == nil {
+		t.Fatal("reading a missing file succeeded")
+	}
+	_, second := engine.ReadFileContent(ctx, path)
+	if second == nil || second.Error() != first.Error() {
+		t.Fatalf("repeated probe = %v, want the cached %v", second, first)
+	}
+	if _, err := engine.GetFileInfo(ctx, path); err == nil {
+		t.Fatal("stat of a missing file succeeded")
+	}
+	engine.GetFileInfo(ctx, path)
+	if hits := engine.cache.GetStats().NegativeHits; hits != 2 {
+		t.Errorf("negative hits = %d, want 2", hits)
+	}
+	if report := FormatCacheReport(engine.GetCacheReport(0), false); !strings.Contains(report, "2 repeated lookups answered") {
+		t.Errorf("cache report missing negative hits:\n%s", report)
+	}
+
+	// A write through the engine makes the path readable at once
+	if err := engine.WriteFileContent(ctx, path, "a: 1\n"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := engine.ReadFileContent(ctx, path); err != nil || got != "a: 1\n" {
+		t.Fatalf("read after write = %q, %v", got, err)
+	}
+	if _, err := engine.GetFileInfo(ctx, path); err != nil {
+		t.Fatalf("stat after write: %v", err)
+	}
+
+	// So does a move onto it, including for paths under a moved directory
+	moved := filepath.Join(dir, "pkg", "main.go")
+	engine.ReadFileContent(ctx, moved)
+	src := filepath.Join(dir, "staging")
+	os.MkdirAll(src, 0755)
+	os.WriteFile(filepath.Join(src, "main.go"), []byte("package main\n"), 0644)
+	if err := engine.MoveFile(ctx, src, filepath.Join(dir, "pkg")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := engine.ReadFileContent(ctx, moved); err != nil {
+		t.Errorf("read under moved directory: %v", err)
+	}
+
+	// And a copy onto it
+	copied := filepath.Join(dir, "copy.yaml")
+	engine.GetFileInfo(ctx, copied)
+	if err := engine.CopyFile(ctx, path, copied); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := engine.GetFileInfo(ctx, copied); err != nil {
+		t.Errorf("stat after copy: %v", err)
+	}
+
+	// And creating a directory
+	sub := filepath.Join(dir, "docs")
+	engine.GetFileInfo(ctx, sub)
+	if err := engine.CreateDirectory(ctx, sub); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := engine.GetFileInfo(ctx, sub); err != nil {
+		t.Errorf("stat after create_directory: %v", err)
+	}
+}
+
+func TestNegativeCache_ExpiresAndCanBeDisabled(t *testing.T) {
+	engine, dir := setupNegativeCacheEngine(t, 30*time.Millisecond)
+	ctx := context.Background()
+	path := filepath.Join(dir, "late.txt")
+
+	engine.ReadFileContent(ctx, path)
+	// Created behind the engine's back: visible once the entry expires
+	os.WriteFile(path, []byte("here"), 0644)
+	time.Sleep(60 * time.Millisecond)
+	if got, err := engine.ReadFileContent(ctx, path); err != nil || got != "here" {
+		t.Errorf("read after TTL = %q, %v", got, err)
+	}
+
+	off, offDir := setupNegativeCacheEngine(t, 0)
+	missing := filepath.Join(offDir, "nope.txt")
+	off.ReadFileContent(ctx, missing)
+	off.ReadFileContent(ctx, missing)
+	if hits := off.cache.GetStats().NegativeHits; hits != 0 {
+		t.Errorf("negative hits with caching off = %d", hits)
+	}
+}
@@ -0,0 +1,174 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultOperationHistorySize is how many tool calls server_info history
+// keeps when Config.OperationHistorySize is 0
+const DefaultOperationHistorySize = 200
+
+// HistoryEntry is one MCP tool call as seen by server_info history. Unlike
+// OperationRecord (mutations only) it covers reads too.
+type HistoryEntry struct {
+	Timestamp  time.Time `json:"ts"`
+	Tool       string    `json:"tool"`
+	Path       string    `json:"path,omitempty"`
+	Status     string    `json:"status"` // ok, warn or error
+	Error      string    `json:"error,omitempty"`
+	Mutating   bool      `json:"mutating"` // the call changed files or server state
+	BytesIn    int64     `json:"bytes_in,omitempty"`
+	BytesOut   int64     `json:"bytes_out,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+}
+
+// operationHistory is a bounded ring buffer of recent tool calls
+type operationHistory struct {
+	mu      sync.Mutex
+	entries []HistoryEntry
+	next    int
+	full    bool
+}
+
+func newOperationHistory(size int) *operationHistory {
+	if size <= 0 {
+		size = DefaultOperationHistorySize
+	}
+	return &operationHistory{entries: make([]HistoryEntry, size)}
+}
+
+func (h *operationHistory) add(entry HistoryEntry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries[h.next] = entry
+	h.next = (h.next + 1) % len(h.entries)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// HistoryQuery filters GetOperationHistory
+type HistoryQuery struct {
+	Limit      int    // newest entries to return (<= 0 = the whole buffer)
+	FilterTool string // exact tool name
+	FilterPath string // this path or below it
+}
+
+// recent returns matching entries newest first
+func (h *operationHistory) recent(q HistoryQuery) []HistoryEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	filter := ""
+	if q.FilterPath != "" {
+		filter = filepath.Clean(NormalizePath(q.FilterPath))
+	}
+	n := h.next
+	if h.full {
+		n = len(h.entries)
+	}
+	out := make([]HistoryEntry, 0, min(n, max(q.Limit, 0)))
+	for i := 1; i <= n; i++ {
+		entry := h.entries[(h.next-i+len(h.entries))%len(h.entries)]
+		if q.FilterTool != "" && entry.Tool != q.FilterTool {
+			continue
+		}
+		if filter != "" && (entry.Path == "" || !pathWithin(NormalizePath(entry.Path), filter)) {
+			continue
+		}
+		out = append(out, entry)
+		if q.Limit > 0 && len(out) >= q.Limit {
+			break
+		}
+	}
+	return out
+}
+
+// MarkMutating flags the current tool call as one that changed files or
+// server state, for server_info history. Safe without an audit entry.
+func MarkMutating(ctx context.Context) {
+	if entry, ok := ctx.Value(AuditEntryKey{}).(*AuditEntry); ok {
+		entry.Mutating = true
+	}
+}
+
+// RecordHistory adds a completed tool call to the operation history
+func (e *UltraFastEngine) RecordHistory(entry AuditEntry) {
+	e.opHistory.add(HistoryEntry{
+		Timestamp:  entry.Timestamp,
+		Tool:       entry.Tool,
+		Path:       entry.Path,
+		Status:     entry.Status,
+		Error:      entry.Error,
+		Mutating:   entry.Mutating,
+		BytesIn:    entry.BytesIn,
+		BytesOut:   entry.BytesOut,
+		DurationMs: entry.DurationMs,
+	})
+}
+
+// GetOperationHistory returns the most recent tool calls, newest first
+func (e *UltraFastEngine) GetOperationHistory(q HistoryQuery) []HistoryEntry {
+	return e.opHistory.recent(q)
+}
+
+// FormatOperationHistory renders server_info history output. Compact mode
+// emits one line per entry; W marks mutating calls, R the rest.
+func FormatOperationHistory(entries []HistoryEntry, compact bool) string {
+	if len(entries) == 0 {
+		return "No operations recorded"
+	}
+	var b strings.Builder
+	if !compact {
+		var writes, errors int
+		for _, e := range entries {
+			if e.Mutating {
+				writes++
+			}
+			if e.Status == "error" {
+				errors++
+			}
+		}
+		b.WriteString(fmt.Sprintf("Operation history: %d calls (%d mutating, %d read-only, %d errors), newest first\n",
+			len(entries), writes, len(entries)-writes, errors))
+	}
+	for i, e := range entries {
+		if i > 0 || !compact {
+			b.WriteString("\n")
+		}
+		kind := "R"
+		if e.Mutating {
+			kind = "W"
+		}
+		if compact {
+			b.WriteString(fmt.Sprintf("%s %s %s %s %s %dms", e.Timestamp.Format("15:04:05"), kind, e.Tool, orDash(e.Path), e.Status, e.DurationMs))
+			continue
+		}
+		b.WriteString(fmt.Sprintf("%s [%s] %s %s — %s, %dms", e.Timestamp.Format(time.RFC3339), kind, e.Tool, orDash(e.Path), e.Status, e.DurationMs))
+		if e.BytesIn > 0 || e.BytesOut > 0 {
+			b.WriteString(fmt.Sprintf(", in %s / out %s", FormatSize(e.BytesIn), FormatSize(e.BytesOut)))
+		}
+		if e.Error != "" {
+			b.WriteString("\n    error: " + firstLine(e.Error))
+		}
+	}
+	return b.String()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i] + " …"
+	}
+	return s
+}
@@ -0,0 +1,87 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mcp/filesystem-ultra/cache"
+)
+
+func TestOperationHistory_RingWrapsNewestFirst(t *testing.T) {
+	h := newOperationHistory(3)
+	for i := 1; i <= 5; i++ {
+		h.add(HistoryEntry{Tool: fmt.Sprintf("t%d", i)})
+	}
+	got := h.recent(HistoryQuery{})
+	if len(got) != 3 || got[0].Tool != "t5" || got[1].Tool != "t4" || got[2].Tool != "t3" {
+		t.Fatalf("recent = %+v, want t5 t4 t3", got)
+	}
+	if got := h.recent(HistoryQuery{Limit: 1}); len(got) != 1 || got[0].Tool != "t5" {
+		t.Fatalf("limit 1 = %+v", got)
+	}
+}
+
+func TestOperationHistory_Filters(t *testing.T) {
+	dir := t.TempDir()
+	h := newOperationHistory(10)
+	h.add(HistoryEntry{Tool: "read_file", Path: filepath.Join(dir, "src", "a.go")})
+	h.add(HistoryEntry{Tool: "edit_file", Path: filepath.Join(dir, "src", "a.go"), Mutating: true})
+	h.add(HistoryEntry{Tool: "edit_file", Path: filepath.Join(dir, "srcx", "b.go"), Mutating: true})
+	h.add(HistoryEntry{Tool: "performance_stats"})
+
+	if got := h.recent(HistoryQuery{FilterTool: "edit_file"}); len(got) != 2 {
+		t.Fatalf("filter_tool edit_file = %d entries, want 2", len(got))
+	}
+	got := h.recent(HistoryQuery{FilterPath: filepath.Join(dir, "src")})
+	if len(got) != 2 || got[0].Tool != "edit_file" || got[1].Tool != "read_file" {
+		t.Fatalf("filter_path src = %+v, want the two a.go calls", got)
+	}
+	if got := h.recent(HistoryQuery{FilterTool: "edit_file", FilterPath: filepath.Join(dir, "src", "a.go")}); len(got) != 1 {
+		t.Fatalf("combined filters = %d entries, want 1", len(got))
+	}
+}
+
+func TestOperationHistory_RecordsMutatingCalls(t *testing.T) {
+	dir := t.TempDir()
+	c, err := cache.NewIntelligentCache(1024 * 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	engine, err := NewUltraFastEngine(&Config{Cache: c, AllowedPaths: []string{dir}, ParallelOps: 2, BackupDir: t.TempDir(), OperationHistorySize: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer engine.Close()
+
+	path := filepath.Join(dir, "a.txt")
+	write := &AuditEntry{Timestamp: time.Now(), Tool: "write_file", Path: path, Status: "ok"}
+	if err := engine.WriteFileContent(context.WithValue(context.Background(), AuditEntryKey{}, write), path, "hello"); err != nil {
+		t.Fatal(err)
+	}
+	engine.RecordHistory(*write)
+
+	read := &AuditEntry{Timestamp: time.Now(), Tool: "read_file", Path: path, Status: "ok"}
+	if _, err := engine.ReadFileContent(context.WithValue(context.Background(), AuditEntryKey{}, read), path); err != nil {
+		t.Fatal(err)
+	}
+	engine.RecordHistory(*read)
+
+	got := engine.GetOperationHistory(HistoryQuery{})
+	if len(got) != 2 || got[0].Tool != "read_file" || got[0].Mutating || !got[1].Mutating {
+		t.Fatalf("history = %+v, want read (R) then write (W)", got)
+	}
+
+	compact := FormatOperationHistory(got, true)
+	lines := strings.Split(compact, "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], " R read_file ") || !strings.Contains(lines[1], " W write_file ") {
+		t.Fatalf("compact output:\n%s", compact)
+	}
+	if verbose := FormatOperationHistory(got, false); !strings.Contains(verbose, "1 mutating, 1 read-only") {
+		t.Fatalf("verbose output:\n%s", verbose)
+	}
+}
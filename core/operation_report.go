@@ -0,0 +1,281 @@
+package core
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OperationRecord is one mutating operation as seen by server_info report.
+// It answers "what did the server change", not "how fast" (see
+// GetPerformanceStats for that).
+type OperationRecord struct {
+	Timestamp    time.Time `json:"ts"`
+	Tool         string    `json:"tool"`
+	Operation    string    `json:"op"` // engine-level operation: edit, multi_edit, write, delete, move, ...
+	Path         string    `json:"path"`
+	Dest         string    `json:"dest,omitempty"` // move/copy/rename destination
+	RiskLevel    string    `json:"risk,omitempty"`
+	Replacements int       `json:"replacements,omitempty"`
+	BytesWritten int64     `json:"bytes_written,omitempty"`
+	BackupID     string    `json:"backup_id,omitempty"`
+	Forced       bool      `json:"forced,omitempty"`
+}
+
+// operationReport is a bounded ring buffer of recent mutating operations,
+// optionally mirrored to a JSONL file so the report survives restarts.
+type operationReport struct {
+	mu      sync.Mutex
+	records []OperationRecord
+	next    int  // index of the slot the next record goes into
+	full    bool // buffer has wrapped at least once
+
+	persistPath string
+	file        *os.File
+}
+
+const operationReportFile = "operation-report.jsonl"
+
+func newOperationReport(size int, persistDir string) *operationReport {
+	if size <= 0 {
+		size = OperationReportSize
+	}
+	r := &operationReport{records: make([]OperationRecord, size)}
+	if persistDir == "" {
+		return r
+	}
+
+	r.persistPath = filepath.Join(persistDir, operationReportFile)
+	r.load()
+	f, err := os.OpenFile(r.persistPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		r.persistPath = ""
+		return r
+	}
+	r.file = f
+	return r
+}
+
+// load replays the tail of the persisted JSONL into the ring buffer. When the
+// file holds more than twice the buffer size it is compacted to the tail so it
+// cannot grow without bound.
+func (r *operationReport) load() {
+	f, err := os.Open(r.persistPath)
+	if err != nil {
+		return
+	}
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	f.Close()
+
+	if len(lines) > 2*len(r.records) {
+		lines = lines[len(lines)-len(r.records):]
+		tmp := r.persistPath + ".tmp"
+		if err := os.WriteFile(tmp, []byte(strings.Join(lines, "\n")+"\n"), 0600); err == nil {
+			os.Rename(tmp, r.persistPath)
+		}
+	}
+	for _, line := range lines {
+		var rec OperationRecord
+		if json.Unmarshal([]byte(line), &rec) == nil {
+			r.push(rec)
+		}
+	}
+}
+
+func (r *operationReport) push(rec OperationRecord) {
+	r.records[r.next] = rec
+	r.next = (r.next + 1) % len(r.records)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+func (r *operationReport) add(rec OperationRecord) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.push(rec)
+	if r.file != nil {
+		if data, err := json.Marshal(rec); err == nil {
+			r.file.Write(append(data, '\n'))
+		}
+	}
+}
+
+// recent returns records newest first, filtered by since (zero = no filter)
+// and capped at limit (<= 0 = everything in the buffer).
+func (r *operationReport) recent(limit int, since time.Time) []OperationRecord {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := r.next
+	if r.full {
+		n = len(r.records)
+	}
+	out := make([]OperationRecord, 0, n)
+	for i := 1; i <= n; i++ {
+		rec := r.records[(r.next-i+len(r.records))%len(r.records)]
+		if !since.IsZero() && rec.Timestamp.Before(since) {
+			continue
+		}
+		out = append(out, rec)
+		if limit > 0 && len(out) >= limit {
+			break
+		}
+	}
+	return out
+}
+
+func (r *operationReport) close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file != nil {
+		r.file.Close()
+		r.file = nil
+	}
+}
+
+// recordOperation appends a mutating operation to the report and the
+// mutation audit log. The tool name comes from the audit entry in ctx (the
+// MCP tool that was called — e.g. batch_operations for a batch edit) and
+// falls back to the engine operation.
+func (e *UltraFastEngine) recordOperation(ctx context.Context, rec OperationRecord) {
+	if e.opReport == nil {
+		return
+	}
+	if rec.Timestamp.IsZero() {
+		rec.Timestamp = time.Now()
+	}
+	var started time.Time
+	if entry, ok := ctx.Value(AuditEntryKey{}).(*AuditEntry); ok {
+		started = entry.Timestamp
+		entry.Mutating = true
+		if rec.Tool == "" {
+			rec.Tool = entry.Tool
+		}
+	}
+	if rec.Tool == "" {
+		rec.Tool = rec.Operation
+	}
+	e.opReport.add(rec)
+	e.logMutation(rec, started)
+}
+
+// OperationReport is the summary returned by GetOperationReport.
+type OperationReport struct {
+	Records      []OperationRecord `json:"records"`
+	Total        int               `json:"total"`
+	ByTool       map[string]int    `json:"by_tool"`
+	ByRisk       map[string]int    `json:"by_risk"`
+	Replacements int               `json:"replacements"`
+	BytesWritten int64             `json:"bytes_written"`
+	Forced       int               `json:"forced"`
+	Files        int               `json:"files"`
+	Persisted    bool              `json:"persisted"`
+}
+
+// GetOperationReport summarizes recent mutating operations, newest first.
+func (e *UltraFastEngine) GetOperationReport(limit int, since time.Time) *OperationReport {
+	report := &OperationReport{
+		ByTool: make(map[string]int),
+		ByRisk: make(map[string]int),
+	}
+	if e.opReport == nil {
+		return report
+	}
+	report.Persisted = e.opReport.persistPath != ""
+	report.Records = e.opReport.recent(limit, since)
+	report.Total = len(report.Records)
+
+	files := make(map[string]bool)
+	for _, rec := range report.Records {
+		report.ByTool[rec.Tool]++
+		risk := rec.RiskLevel
+		if risk == "" {
+			risk = "low"
+		}
+		report.ByRisk[risk]++
+		report.Replacements += rec.Replacements
+		report.BytesWritten += rec.BytesWritten
+		if rec.Forced {
+			report.Forced++
+		}
+		files[rec.Path] = true
+	}
+	report.Files = len(files)
+	return report
+}
+
+// FormatOperationReport renders the report as text (compact or verbose).
+func FormatOperationReport(report *OperationReport, compact bool) string {
+	if report.Total == 0 {
+		return "No mutating operations recorded"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%d ops on %d files, %d replacements, %s written",
+		report.Total, report.Files, report.Replacements, formatSize(report.BytesWritten)))
+	if report.Forced > 0 {
+		sb.WriteString(fmt.Sprintf(", %d forced", report.Forced))
+	}
+	sb.WriteString("\n")
+
+	riskOrder := []string{"critical", "high", "medium", "low"}
+	var risks []string
+	for _, level := range riskOrder {
+		if n := report.ByRisk[level]; n > 0 {
+			risks = append(risks, fmt.Sprintf("%s:%d", level, n))
+		}
+	}
+	tools := make([]string, 0, len(report.ByTool))
+	for tool, n := range report.ByTool {
+		tools = append(tools, fmt.Sprintf("%s:%d", tool, n))
+	}
+	sort.Strings(tools)
+	sb.WriteString(fmt.Sprintf("risk %s | tools %s\n", strings.Join(risks, " "), strings.Join(tools, " ")))
+
+	for _, rec := range report.Records {
+		if compact {
+			sb.WriteString(fmt.Sprintf("%s %s %s", rec.Timestamp.Format("15:04:05"), rec.Tool, rec.Path))
+		} else {
+			sb.WriteString(fmt.Sprintf("%s  %-16s %-10s %s", rec.Timestamp.Format("2006-01-02 15:04:05"), rec.Tool, rec.Operation, rec.Path))
+		}
+		if rec.Dest != "" {
+			sb.WriteString(" → " + rec.Dest)
+		}
+		var details []string
+		if rec.RiskLevel != "" && rec.RiskLevel != "low" {
+			details = append(details, "risk="+rec.RiskLevel)
+		}
+		if rec.Replacements > 0 {
+			details = append(details, fmt.Sprintf("repl=%d", rec.Replacements))
+		}
+		if rec.BytesWritten > 0 {
+			details = append(details, formatSize(rec.BytesWritten))
+		}
+		if rec.BackupID != "" {
+			details = append(details, "backup:"+rec.BackupID)
+		}
+		if rec.Forced {
+			details = append(details, "forced")
+		}
+		if len(details) > 0 {
+			sb.WriteString(" [" + strings.Join(details, ", ") + "]")
+		}
+		sb.WriteString("\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,96 @@
+package core
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mcp/filesystem-ultra/cache"
+)
+
+func TestOperationReport_RingBufferWrapsNewestFirst(t *testing.T) {
+	r := newOperationReport(3, "")
+	base := time.Now()
+	for i := 0; i < 5; i++ {
+		r.add(OperationRecord{Timestamp: base.Add(time.Duration(i) * time.Second), Tool: "edit_file", Path: string(rune('a' + i))})
+	}
+
+	got := r.recent(0, time.Time{})
+	if len(got) != 3 {
+		t.Fatalf("len = %d, want 3 (buffer capacity)", len(got))
+	}
+	if got[0].Path != "e" || got[2].Path != "c" {
+		t.Errorf("order = %s,%s,%s, want e,d,c", got[0].Path, got[1].Path, got[2].Path)
+	}
+	if got := r.recent(1, time.Time{}); len(got) != 1 || got[0].Path != "e" {
+		t.Errorf("limit=1 returned %+v", got)
+	}
+	if got := r.recent(0, base.Add(3*time.Second)); len(got) != 2 {
+		t.Errorf("since filter returned %d records, want 2", len(got))
+	}
+}
+
+func TestOperationReport_PersistedAcrossRestart(t *testing.T) {
+	dir := t.TempDir()
+	r := newOperationReport(10, dir)
+	r.add(OperationRecord{Timestamp: time.Now(), Tool: "write_file", Operation: "write", Path: "/x/a.txt", BytesWritten: 42})
+	r.close()
+
+	reloaded := newOperationReport(10, dir)
+	defer reloaded.close()
+	got := reloaded.recent(0, time.Time{})
+	if len(got) != 1 || got[0].Path != "/x/a.txt" || got[0].BytesWritten != 42 {
+		t.Fatalf("reloaded records = %+v", got)
+	}
+	if reloaded.persistPath != filepath.Join(dir, operationReportFile) {
+		t.Errorf("persistPath = %q", reloaded.persistPath)
+	}
+}
+
+func TestGetOperationReport_RecordsEngineMutations(t *testing.T) {
+	dir := t.TempDir()
+	c, err := cache.NewIntelligentCache(1024 * 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	engine, err := NewUltraFastEngine(&Config{Cache: c, AllowedPaths: []string{dir}, ParallelOps: 2, BackupDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer engine.Close()
+
+	ctx := context.WithValue(context.Background(), AuditEntryKey{}, &AuditEntry{Tool: "edit_file"})
+	path := createTestFile(t, dir, "a.txt", "alpha beta alpha\n")
+	if _, err := engine.EditFile(ctx, path, "alpha", "gamma", true, false, false); err != nil {
+		t.Fatalf("EditFile: %v", err)
+	}
+	// Dry runs are not mutations
+	if _, err := engine.EditFile(ctx, path, "gamma", "delta", false, true, false); err != nil {
+		t.Fatalf("EditFile dry_run: %v", err)
+	}
+	if err := engine.WriteFileContent(context.Background(), filepath.Join(dir, "b.txt"), "hello"); err != nil {
+		t.Fatalf("WriteFileContent: %v", err)
+	}
+
+	report := engine.GetOperationReport(0, time.Time{})
+	if report.Total != 2 {
+		t.Fatalf("Total = %d, want 2: %+v", report.Total, report.Records)
+	}
+	edit := report.Records[1]
+	if edit.Tool != "edit_file" || edit.Replacements != 2 || !edit.Forced || edit.BackupID == "" {
+		t.Errorf("edit record = %+v", edit)
+	}
+	if report.Records[0].Tool != "write" || report.Records[0].BytesWritten != 5 {
+		t.Errorf("write record = %+v", report.Records[0])
+	}
+
+	text := FormatOperationReport(report, false)
+	for _, want := range []string{"2 ops on 2 files", "repl=2", "forced", "backup:"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("report text missing %q:\n%s", want, text)
+		}
+	}
+}
@@ -0,0 +1,368 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Organize conflict policies: what happens when a file of the
+// same name already exists in the destination
+const (
+	OrganizeConflictRename    = "rename"    // move as name-1.ext, name-2.ext, ... (default)
+	OrganizeConflictOverwrite = "overwrite" // back up the existing file, then replace it
+	OrganizeConflictSkip      = "skip"      // leave the source in place
+)
+
+// OrganizeRule sends the files matching it to Destination. MatchGlob is
+// matched against the file name, case-insensitively; OlderThan is a
+// ParseWithin window on the modification time. When both are set a file
+// must match both.
+type OrganizeRule struct {
+	MatchGlob   string `json:"match_glob,omitempty"`
+	OlderThan   string `json:"older_than,omitempty"`
+	Destination string `json:"destination"` // relative to the organized directory, or absolute
+}
+
+// OrganizeRequest is the batch_operations organize_json payload
+type OrganizeRequest struct {
+	Path       string         `json:"path"`
+	Rules      []OrganizeRule `json:"rules"`
+	DryRun     *bool          `json:"dry_run,omitempty"` // nil = true: only list the planned moves
+	OnConflict string         `json:"on_conflict,omitempty"`
+}
+
+// OrganizeMove is one planned (or executed) move
+type OrganizeMove struct {
+	Rule      int    `json:"rule"` // 1-based index into the rules
+	Source    string `json:"source"`
+	Dest      string `json:"dest"`
+	Renamed   bool   `json:"renamed,omitempty"`   // Dest carries a -N suffix
+	Overwrite bool   `json:"overwrite,omitempty"` // replaces an existing file
+	Skipped   bool   `json:"skipped,omitempty"`   // name taken and on_conflict is skip
+	Error     string `json:"error,omitempty"`
+}
+
+// OrganizeRuleCount is the per-rule tally of an OrganizeResult
+type OrganizeRuleCount struct {
+	Rule        int    `json:"rule"`
+	MatchGlob   string `json:"match_glob,omitempty"`
+	OlderThan   string `json:"older_than,omitempty"`
+	Destination string `json:"destination"`
+	Files       int    `json:"files"`
+}
+
+// OrganizeResult is the batch_operations organize_json response
+type OrganizeResult struct {
+	Path       string              `json:"path"`
+	DryRun     bool                `json:"dry_run"`
+	OnConflict string              `json:"on_conflict"`
+	Rules      []OrganizeRuleCount `json:"rules"`
+	Moves      []OrganizeMove      `json:"moves"`
+	Unmatched  int                 `json:"unmatched"` // files no rule matched
+	Moved      int                 `json:"moved"`
+	Skipped    int                 `json:"skipped,omitempty"`
+	Failed     int                 `json:"failed,omitempty"`
+	BackupID   string              `json:"backup_id,omitempty"` // files replaced by overwrite moves
+	DurationMs int64               `json:"duration_ms"`
+}
+
+// organizeRule is an OrganizeRule resolved for planning
+type organizeRule struct {
+	glob      string
+	olderThan time.Duration
+	dest      string // absolute
+}
+
+// OrganizeDirectory moves the files directly inside path by ordered rules:
+// each file goes to the destination of the first rule it matches. With
+// dryRun nothing is touched and every planned move is listed. Execution goes
+// through MoveFile (access control, hooks, protected paths); a name already
+// taken in the destination is handled by onConflict, and overwrite moves
+// back up the replaced files in one batch backup first.
+func (e *UltraFastEngine) OrganizeDirectory(ctx context.Context, path string, rules []OrganizeRule, dryRun bool, onConflict string) (*OrganizeResult, error) {
+	start := time.Now()
+	path = NormalizePath(path)
+	if !e.IsPathAllowed(path) {
+		return nil, e.AccessDeniedError("organize", path)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, &PathError{Op: "organize", Path: path, Err: err}
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("not a directory: %s", path)
+	}
+	root, err := filepath.Abs(path)
+	if err != nil {
+		root = path
+	}
+	switch onConflict {
+	case "":
+		onConflict = OrganizeConflictRename
+	case OrganizeConflictRename, OrganizeConflictOverwrite, OrganizeConflictSkip:
+	default:
+		return nil, fmt.Errorf("invalid on_conflict %q: use rename, overwrite or skip", onConflict)
+	}
+	resolved, err := e.resolveOrganizeRules(root, rules)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &OrganizeResult{Path: root, DryRun: dryRun, OnConflict: onConflict, Moves: []OrganizeMove{}}
+	for i, r := range rules {
+		res.Rules = append(res.Rules, OrganizeRuleCount{Rule: i + 1, MatchGlob: r.MatchGlob, OlderThan: r.OlderThan, Destination: resolved[i].dest})
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, &PathError{Op: "organize", Path: root, Err: err}
+	}
+	taken := make(map[string]bool) // destinations claimed by earlier moves
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		fi, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		rule := -1
+		for i, r := range resolved {
+			if r.matches(entry.Name(), fi.ModTime(), start) {
+				rule = i
+				break
+			}
+		}
+		if rule < 0 {
+			res.Unmatched++
+			continue
+		}
+		res.Rules[rule].Files++
+		move := OrganizeMove{Rule: rule + 1, Source: filepath.Join(root, entry.Name()), Dest: filepath.Join(resolved[rule].dest, entry.Name())}
+		if _, err := os.Lstat(move.Dest); err == nil || taken[move.Dest] {
+			switch onConflict {
+			case OrganizeConflictSkip:
+				move.Skipped = true
+			case OrganizeConflictOverwrite:
+				move.Overwrite = !taken[move.Dest]
+				if taken[move.Dest] {
+					move.Dest, move.Renamed = freeName(move.Dest, taken), true
+				}
+			default:
+				move.Dest, move.Renamed = freeName(move.Dest, taken), true
+			}
+		}
+		if !move.Skipped {
+			taken[move.Dest] = true
+		}
+		res.Moves = append(res.Moves, move)
+	}
+	if dryRun {
+		for _, m := range res.Moves {
+			if m.Skipped {
+				res.Skipped++
+			}
+		}
+		res.DurationMs = time.Since(start).Milliseconds()
+		return res, nil
+	}
+
+	// Back up every file an overwrite move replaces, before any move
+	var replaced []string
+	for _, m := range res.Moves {
+		if m.Overwrite {
+			replaced = append(replaced, m.Dest)
+		}
+	}
+	if len(replaced) > 0 && e.backupManager != nil {
+		backupID, err := e.backupManager.CreateBatchBackup(replaced, "organize",
+			fmt.Sprintf("organize %s: %d files overwritten", root, len(replaced)))
+		if err != nil {
+			return nil, fmt.Errorf("backup failed (no files moved): %w", err)
+		}
+		res.BackupID = backupID
+	}
+
+	for i := range res.Moves {
+		m := &res.Moves[i]
+		if err := ctx.Err(); err != nil {
+			m.Error = err.Error()
+			res.Failed++
+			continue
+		}
+		if m.Skipped {
+			res.Skipped++
+			continue
+		}
+		if err := e.organizeMove(ctx, m); err != nil {
+			m.Error = err.Error()
+			res.Failed++
+			continue
+		}
+		res.Moved++
+	}
+	res.DurationMs = time.Since(start).Milliseconds()
+	return res, nil
+}
+
+// organizeMove executes one move, creating the destination directory and
+// removing the file an overwrite move replaces (already backed up)
+func (e *UltraFastEngine) organizeMove(ctx context.Context, m *OrganizeMove) error {
+	dir := filepath.Dir(m.Dest)
+	if err := e.CheckWritable("organize", dir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	if m.Overwrite {
+		if err := e.CheckWritable("organize", m.Dest); err != nil {
+			return err
+		}
+		if err := os.Remove(m.Dest); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to replace %s: %w", m.Dest, err)
+		}
+	}
+	return e.MoveFile(ctx, m.Source, m.Dest)
+}
+
+// resolveOrganizeRules validates rules and makes their destinations absolute
+func (e *UltraFastEngine) resolveOrganizeRules(root string, rules []OrganizeRule) ([]organizeRule, error) {
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("no rules: pass at least one {match_glob|older_than, destination}")
+	}
+	out := make([]organizeRule, len(rules))
+	for i, r := range rules {
+		n := i + 1
+		if r.MatchGlob == "" && r.OlderThan == "" {
+			return nil, fmt.Errorf("rule %d: set match_glob, older_than or both", n)
+		}
+		if r.MatchGlob != "" {
+			if _, err := filepath.Match(r.MatchGlob, ""); err != nil {
+				return nil, fmt.Errorf("rule %d: invalid match_glob %q: %w", n, r.MatchGlob, err)
+			}
+			out[i].glob = strings.ToLower(r.MatchGlob)
+		}
+		if r.OlderThan != "" {
+			d, err := ParseWithin(r.OlderThan)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: %w", n, err)
+			}
+			out[i].olderThan = d
+		}
+		if strings.TrimSpace(r.Destination) == "" {
+			return nil, fmt.Errorf("rule %d: destination is required", n)
+		}
+		dest := NormalizePath(r.Destination)
+		if !filepath.IsAbs(dest) {
+			dest = filepath.Join(root, dest)
+		}
+		dest = filepath.Clean(dest)
+		if dest == root {
+			return nil, fmt.Errorf("rule %d: destination is the organized directory itself", n)
+		}
+		if !e.IsPathAllowed(dest) {
+			return nil, fmt.Errorf("rule %d: %w", n, e.AccessDeniedError("organize", dest))
+		}
+		out[i].dest = dest
+	}
+	return out, nil
+}
+
+// matches reports whether a file named name, last modified at mtime, matches r
+func (r organizeRule) matches(name string, mtime, now time.Time) bool {
+	if r.glob != "" {
+		if ok, _ := filepath.Match(r.glob, strings.ToLower(name)); !ok {
+			return false
+		}
+	}
+	return r.olderThan == 0 || now.Sub(mtime) > r.olderThan
+}
+
+// freeName returns dest with the first -N suffix (before the extension)
+// that neither exists nor is claimed by an earlier move
+func freeName(dest string, taken map[string]bool) string {
+	ext := filepath.Ext(dest)
+	base := strings.TrimSuffix(dest, ext)
+	for n := 1; ; n++ {
+		candidate := fmt.Sprintf("%s-%d%s", base, n, ext)
+		if _, err := os.Lstat(candidate); os.IsNotExist(err) && !taken[candidate] {
+			return candidate
+		}
+	}
+}
+
+// FormatOrganizeResult renders an OrganizeResult as text
+func FormatOrganizeResult(r *OrganizeResult, compact bool) string {
+	rel := func(p string) string {
+		if rp, err := filepath.Rel(r.Path, p); err == nil && !strings.HasPrefix(rp, "..") {
+			return filepath.ToSlash(rp)
+		}
+		return p
+	}
+	status := fmt.Sprintf("APPLIED — %d moved", r.Moved)
+	if r.Skipped > 0 {
+		status += fmt.Sprintf(", %d skipped", r.Skipped)
+	}
+	if r.Failed > 0 {
+		status += fmt.Sprintf(", %d failed", r.Failed)
+	}
+	if r.DryRun {
+		status = fmt.Sprintf("PREVIEW — %d moves planned, no files moved (dry_run:false to apply)", len(r.Moves)-r.Skipped)
+	}
+
+	if compact {
+		parts := []string{fmt.Sprintf("%s: %s", r.Path, status)}
+		for _, c := range r.Rules {
+			parts = append(parts, fmt.Sprintf("rule %d→%s: %d", c.Rule, rel(c.Destination), c.Files))
+		}
+		parts = append(parts, fmt.Sprintf("unmatched: %d", r.Unmatched))
+		if r.BackupID != "" {
+			parts = append(parts, "UNDO:"+r.BackupID)
+		}
+		return strings.Join(parts, " | ")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Organize: %s\nStatus: %s\n\n", r.Path, status))
+	for _, c := range r.Rules {
+		var match []string
+		if c.MatchGlob != "" {
+			match = append(match, c.MatchGlob)
+		}
+		if c.OlderThan != "" {
+			match = append(match, "older than "+c.OlderThan)
+		}
+		sb.WriteString(fmt.Sprintf("Rule %d (%s) → %s/: %d files\n", c.Rule, strings.Join(match, ", "), rel(c.Destination), c.Files))
+	}
+	sb.WriteString(fmt.Sprintf("Unmatched: %d files\n", r.Unmatched))
+	if r.BackupID != "" {
+		sb.WriteString(fmt.Sprintf("✓ Backup/UNDO of overwritten files: %s\n", r.BackupID))
+	}
+
+	moves := append([]OrganizeMove(nil), r.Moves...)
+	sort.SliceStable(moves, func(i, j int) bool { return moves[i].Rule < moves[j].Rule })
+	if len(moves) > 0 {
+		sb.WriteString("\nMoves:\n")
+	}
+	for _, m := range moves {
+		line := fmt.Sprintf("  %s → %s", rel(m.Source), rel(m.Dest))
+		switch {
+		case m.Error != "":
+			line += "  FAILED: " + m.Error
+		case m.Skipped:
+			line += "  (skipped: name taken)"
+		case m.Overwrite:
+			line += "  (overwrites existing file)"
+		case m.Renamed:
+			line += "  (renamed: name taken)"
+		}
+		sb.WriteString(line + "\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,121 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func setupDownloads(t *testing.T) (*UltraFastEngine, string) {
+	t.Helper()
+	engine, dir := setupProgressEngine(t)
+	old := time.Now().Add(-40 * 24 * time.Hour)
+	for name, content := range map[string]string{
+		"shot.png":       "new shot",
+		"LOGO.PNG":       "logo",
+		"report.csv":     "a,b",
+		"invoice.pdf":    "old pdf",
+		"notes.txt":      "keep",
+		"images/old.png": "existing",
+	} {
+		full := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(full), 0755)
+		os.WriteFile(full, []byte(content), 0644)
+	}
+	os.WriteFile(filepath.Join(dir, "images", "shot.png"), []byte("older shot"), 0644)
+	os.Chtimes(filepath.Join(dir, "invoice.pdf"), old, old)
+	return engine, dir
+}
+
+var downloadRules = []OrganizeRule{
+	{MatchGlob: "*.png", Destination: "images"},
+	{MatchGlob: "*.csv", Destination: "data"},
+	{OlderThan: "30d", Destination: "archive"},
+}
+
+func TestOrganizeDirectory_DryRunThenApply(t *testing.T) {
+	engine, dir := setupDownloads(t)
+
+	plan, err := engine.OrganizeDirectory(context.Background(), dir, downloadRules, true, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Moves) != 4 || plan.Unmatched != 1 || plan.Rules[0].Files != 2 || plan.Rules[1].Files != 1 || plan.Rules[2].Files != 1 {
+		t.Fatalf("plan = %+v", plan)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "shot.png")); err != nil {
+		t.Fatalf("dry run moved a file: %v", err)
+	}
+	text := FormatOrganizeResult(plan, false)
+	if !strings.Contains(text, "shot.png → images/shot-1.png  (renamed: name taken)") || !strings.Contains(text, "PREVIEW") {
+		t.Errorf("text:\n%s", text)
+	}
+
+	res, err := engine.OrganizeDirectory(context.Background(), dir, downloadRules, false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Moved != 4 || res.Failed != 0 || res.BackupID != "" {
+		t.Fatalf("res = %+v", res)
+	}
+	for rel, want := range map[string]string{
+		"images/shot-1.png":   "new shot",
+		"images/shot.png":     "older shot",
+		"images/LOGO.PNG":     "logo",
+		"data/report.csv":     "a,b",
+		"archive/invoice.pdf": "old pdf",
+		"notes.txt":           "keep",
+	} {
+		if got, err := os.ReadFile(filepath.Join(dir, rel)); err != nil || string(got) != want {
+			t.Errorf("%s = %q, %v", rel, got, err)
+		}
+	}
+}
+
+func TestOrganizeDirectory_OverwriteBacksUp(t *testing.T) {
+	engine, dir := setupDownloads(t)
+	res, err := engine.OrganizeDirectory(context.Background(), dir, downloadRules[:1], false, OrganizeConflictOverwrite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Moved != 2 || res.BackupID == "" {
+		t.Fatalf("res = %+v", res)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, "images", "shot.png")); string(got) != "new shot" {
+		t.Errorf("images/shot.png = %q", got)
+	}
+	info, err := engine.backupManager.GetBackupInfo(res.BackupID)
+	if err != nil || len(info.Files) != 1 || info.Files[0].OriginalPath != filepath.Join(dir, "images", "shot.png") {
+		t.Errorf("backup = %+v, %v", info, err)
+	}
+
+	res, err = engine.OrganizeDirectory(context.Background(), dir, []OrganizeRule{{MatchGlob: "*.txt", Destination: "images"}}, false, OrganizeConflictSkip)
+	if err != nil || res.Moved != 1 {
+		t.Fatalf("txt move = %+v, %v", res, err)
+	}
+}
+
+func TestOrganizeDirectory_RejectsBadRules(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	for _, tc := range []struct {
+		rules []OrganizeRule
+		want  string
+	}{
+		{nil, "no rules"},
+		{[]OrganizeRule{{Destination: "x"}}, "set match_glob, older_than or both"},
+		{[]OrganizeRule{{MatchGlob: "[", Destination: "x"}}, "invalid match_glob"},
+		{[]OrganizeRule{{OlderThan: "soon", Destination: "x"}}, "invalid within"},
+		{[]OrganizeRule{{MatchGlob: "*", Destination: "."}}, "organized directory itself"},
+		{[]OrganizeRule{{MatchGlob: "*", Destination: t.TempDir()}}, "access denied"},
+	} {
+		if _, err := engine.OrganizeDirectory(context.Background(), dir, tc.rules, true, ""); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("rules %+v: err = %v, want %q", tc.rules, err, tc.want)
+		}
+	}
+	if _, err := engine.OrganizeDirectory(context.Background(), dir, downloadRules, true, "merge"); err == nil {
+		t.Error("invalid on_conflict accepted")
+	}
+}
@@ -54,10 +54,15 @@ type ParamDef struct {
 		"encoding":   {ParamString, false},
 	},
 	"write_file": {
-		"path":           {ParamString, true},
-		"content":        {ParamString, false},
-		"content_base64": {ParamString, false},
-		"encoding":       {ParamString, false},
+		"path":            {ParamString, true},
+		"content":         {ParamString, false},
+		"content_base64":  {ParamString, false},
+		"encoding":        {ParamString, false},
+		"session":         {ParamString, false}, // begin | append | commit | abort
+		"session_id":      {ParamString, false},
+		"chunk":           {ParamString, false},
+		"index":           {ParamNumber, false},
+		"expected_sha256": {ParamString, false},
 	},
 	"edit_file": {
 		"path":                {ParamString, true},
@@ -81,11 +86,64 @@ type ParamDef struct {
 		"whole_word":          {ParamBoolean, false},
 		"expected_hash":       {ParamString, false},  // B3: stale-edit protection
 		"tolerant_whitespace": {ParamBoolean, false}, // treat tabs↔4sp, CRLF↔LF as equivalent
+		"format":              {ParamString, false},  // "text" | "json" (default: --json-responses)
 	},
 	"list_directory": {
 		"path":          {ParamString, true},
 		"output_format": {ParamString, false}, // "compact" (default) | "json" | "tree"
 		"max_depth":     {ParamNumber, false}, // recursion depth for "tree"
+		"sort":          {ParamString, false}, // "name" | "size" | "mtime"
+		"order":         {ParamString, false}, // "asc" | "desc"
+		"filter":        {ParamString, false}, // glob on the entry name
+		"offset":        {ParamNumber, false},
+		"limit":         {ParamNumber, false},
+		"dirs_only":     {ParamBoolean, false},
+		"files_only":    {ParamBoolean, false},
+		"include_git":   {ParamBoolean, false}, // annotate entries with git status
+		"format":        {ParamString, false},  // "json" = output_format "json" (default: --json-responses)
+	},
+	"analyze_directory": {
+		"action":         {ParamString, false}, // tree | size | disk_usage | duplicates | compare | watch | recent
+		"path":           {ParamString, false}, // required except for compare
+		"exclude":        {ParamArray, false},
+		"max_depth":      {ParamNumber, false}, // tree
+		"include_sizes":  {ParamBoolean, false},
+		"include_hidden": {ParamBoolean, false},
+		"include_git":    {ParamBoolean, false},
+		"depth":          {ParamNumber, false}, // size
+		"top_n":          {ParamNumber, false},
+		"min_size":       {ParamNumber, false}, // duplicates
+		"path_a":         {ParamString, false}, // compare
+		"path_b":         {ParamString, false},
+		"check":          {ParamString, false}, // "name" | "size" | "mtime" | "hash"
+		"mode":           {ParamString, false}, // compare of files: "hash" | "diff" | "stats"
+		"cursor":         {ParamString, false}, // watch
+		"within":         {ParamString, false}, // recent: "30m" | "1h" | "24h" | "7d"
+		"limit":          {ParamNumber, false},
+		"format":         {ParamString, false}, // "text" | "json" (default: --json-responses)
+	},
+	"archive": {
+		"action":            {ParamString, false}, // create | extract | list | read | gzip | gunzip
+		"archive_path":      {ParamString, false}, // required except for gzip / gunzip
+		"archive_format":    {ParamString, false}, // "zip" | "tar.gz"
+		"sources":           {ParamArray, false},
+		"glob":              {ParamString, false},
+		"base_dir":          {ParamString, false},
+		"exclude":           {ParamArray, false},
+		"compression_level": {ParamNumber, false},
+		"dest_dir":          {ParamString, false},
+		"include_glob":      {ParamString, false},
+		"dry_run":           {ParamBoolean, false},
+		"entry_path":        {ParamString, false},
+		"max_bytes":         {ParamNumber, false},
+		"as_base64":         {ParamBoolean, false},
+		"path":              {ParamString, false},
+		"dest":              {ParamString, false},
+		"delete_source":     {ParamBoolean, false},
+		"overwrite":         {ParamBoolean, false},
+		"force":             {ParamBoolean, false},
+		"follow_symlinks":   {ParamBoolean, false},
+		"format":            {ParamString, false}, // "text" | "json" (default: --json-responses)
 	},
 	"search_files": {
 		"path":            {ParamString, true},
@@ -102,6 +160,7 @@ type ParamDef struct {
 		"output_format":   {ParamString, false},  // "text" or "json"
 		"output":          {ParamString, false},  // alias for output_format
 		"max_results":     {ParamNumber, false},  // cap filenames returned (v4.5.26, fix #3)
+		"format":          {ParamString, false},  // "json": filename matches with mime/is_binary
 	},
 
 	// ---- EDIT+ (1) ----
@@ -113,6 +172,7 @@ type ParamDef struct {
 		"dry_run":             {ParamBoolean, false}, // preview without writing (was read by handler but undeclared)
 		"expected_hash":       {ParamString, false},  // B3: stale-edit protection (parity with edit_file)
 		"diff_format":         {ParamString, false},  // ""/auto|full|summary|stat|none (parity with edit_file)
+		"format":              {ParamString, false},  // "text" | "json" (default: --json-responses)
 	},
 
 	// ---- FILES (4) ----
@@ -121,8 +181,11 @@ type ParamDef struct {
 		"dest_path":   {ParamString, true},
 	},
 	"copy_file": {
-		"source_path": {ParamString, true},
-		"dest_path":   {ParamString, true},
+		"source_path":           {ParamString, true},
+		"dest_path":             {ParamString, true},
+		"skip_default_excludes": {ParamBoolean, false},
+		"verify":                {ParamBoolean, false},
+		"format":                {ParamString, false},
 	},
 	"delete_file": {
 		"path":      {ParamString, true},
@@ -138,6 +201,8 @@ type ParamDef struct {
 		"request_json":  {ParamString, false},
 		"pipeline_json": {ParamString, false},
 		"rename_json":   {ParamString, false},
+		"organize_json": {ParamString, false},
+		"format":        {ParamString, false}, // "text" | "json" (default: --json-responses)
 	},
 
 	// ---- BACKUP (1) ----
@@ -152,6 +217,7 @@ type ParamDef struct {
 		"older_than_days":  {ParamNumber, false},
 		"dry_run":          {ParamBoolean, false},
 		"preview":          {ParamBoolean, false},
+		"format":           {ParamString, false}, // list: "text" | "json" (default: --json-responses)
 	},
 
 	// ---- ANALYSIS (1) ----
@@ -165,32 +231,71 @@ type ParamDef struct {
 
 	// ---- WSL (1) ----
 	"wsl": {
-		"action":         {ParamString, false},
-		"wsl_path":       {ParamString, false},
-		"windows_path":   {ParamString, false},
-		"direction":      {ParamString, false},
-		"create_dirs":    {ParamBoolean, false},
-		"filter_pattern": {ParamString, false},
-		"dry_run":        {ParamBoolean, false},
-		"enabled":        {ParamBoolean, false},
-		"sync_on_write":  {ParamBoolean, false},
-		"sync_on_edit":   {ParamBoolean, false},
-		"silent":         {ParamBoolean, false},
+		"action":               {ParamString, false},
+		"wsl_path":             {ParamString, false},
+		"windows_path":         {ParamString, false},
+		"direction":            {ParamString, false},
+		"create_dirs":          {ParamBoolean, false},
+		"filter_pattern":       {ParamString, false},
+		"exclude":              {ParamArray, false},
+		"conflict_strategy":    {ParamString, false},
+		"dry_run":              {ParamBoolean, false},
+		"max_files_per_second": {ParamNumber, false},
+		"max_concurrency":      {ParamNumber, false},
+		"sync_id":              {ParamString, false},
+		"output":               {ParamString, false},
+		"enabled":              {ParamBoolean, false},
+		"sync_on_write":        {ParamBoolean, false},
+		"sync_on_edit":         {ParamBoolean, false},
+		"sync_on_delete":       {ParamBoolean, false},
+		"delete_mode":          {ParamString, false},
+		"silent":               {ParamBoolean, false},
+		"operation":            {ParamString, false},
+		"wsl_root":             {ParamString, false},
+		"windows_root":         {ParamString, false},
+		"path":                 {ParamString, false}, // convert_path
+		"target":               {ParamString, false}, // convert_path
 	},
 
 	// ---- UTIL (1) ----
+	"allowed_paths": {
+		"action":  {ParamString, false},  // list | add | remove | rules
+		"path":    {ParamString, false},  // required for add, remove
+		"persist": {ParamBoolean, false}, // add
+		"output":  {ParamString, false},  // list, rules: "text" | "json"
+	},
+
 	"server_info": {
-		"action":     {ParamString, false},
-		"topic":      {ParamString, false},
-		"sub_action": {ParamString, false},
-		"content":    {ParamString, false},
-		"path":       {ParamString, false},
+		"action":        {ParamString, false},
+		"topic":         {ParamString, false},
+		"sub_action":    {ParamString, false}, // artifact: capture | capture_chunk | diff | write | apply | info | list | delete
+		"name":          {ParamString, false}, // artifact
+		"content":       {ParamString, false},
+		"chunk":         {ParamString, false},  // artifact capture_chunk
+		"index":         {ParamNumber, false},  // artifact capture_chunk
+		"total":         {ParamNumber, false},  // artifact capture_chunk
+		"final":         {ParamBoolean, false}, // artifact capture_chunk
+		"context_lines": {ParamNumber, false},  // artifact diff
+		"expected_hash": {ParamString, false},  // artifact write, apply: content_hash from diff
+		"mode":          {ParamString, false},  // artifact apply: overwrite | patch | append
+		"path":          {ParamString, false},
+		"format":        {ParamString, false}, // stats, config, tools, report, history, audit_log: "text" | "json" (default: --json-responses)
+		"limit":         {ParamNumber, false}, // report, history, audit_log
+		"since":         {ParamString, false}, // report, audit_log
+		"filter_tool":   {ParamString, false}, // history
+		"filter_path":   {ParamString, false}, // history, audit_log
 	},
 
 	// ---- INFO (1) ----
 	"get_file_info": {
-		"path":  {ParamString, true},
-		"paths": {ParamString, false}, // batch: JSON array of paths
+		"path":          {ParamString, true},
+		"paths":         {ParamString, false},  // batch: JSON array of paths
+		"hash":          {ParamBoolean, false}, // alias of include_hash
+		"include_hash":  {ParamBoolean, false},
+		"include_stats": {ParamBoolean, false},
+		"include_git":   {ParamBoolean, false},
+		"patterns":      {ParamString, false}, // JSON array; counts per pattern, implies include_stats
+		"format":        {ParamString, false}, // "text" | "json" (default: --json-responses)
 	},
 
 	// ---- VERSION CONTROL (1) ----
@@ -207,6 +312,7 @@ type ParamDef struct {
 		"name":      {ParamString, false},  // branch: list when empty
 		"checkout":  {ParamBoolean, false}, // branch: true → git switch -c
 		"force":     {ParamBoolean, false}, // branch delete: true → -D
+		"format":    {ParamString, false},  // changed: "text" | "json" (default: --json-responses)
 	},
 
 	// ---- ALIASES ----
@@ -0,0 +1,146 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// patchHunkHeader matches "@@ -12,5 +12,7 @@" (counts optional)
+var patchHunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// patchHunk is one hunk of a unified diff: the lines it expects (context and
+// removals) and the lines that replace them (context and additions)
+type patchHunk struct {
+	oldStart int // 1-based
+	old      []string
+	new      []string
+}
+
+// parseUnifiedDiff reads the hunks of a single-file unified diff. File
+// headers (---, +++, diff, index) are skipped; a patch touching several files
+// is refused.
+func parseUnifiedDiff(patch string) ([]patchHunk, error) {
+	var hunks []patchHunk
+	var cur *patchHunk
+	files := 0
+	for i, line := range strings.Split(normalizeLineEndings(patch), "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			if files++; files > 1 {
+				return nil, fmt.Errorf("patch touches more than one file; apply one file at a time")
+			}
+			cur = nil
+		case strings.HasPrefix(line, "@@"):
+			m := patchHunkHeader.FindStringSubmatch(line)
+			if m == nil {
+				return nil, fmt.Errorf("line %d: bad hunk header %q", i+1, line)
+			}
+			start, _ := strconv.Atoi(m[1])
+			if m[2] == "0" {
+				start++ // "-5,0" inserts after line 5
+			}
+			hunks = append(hunks, patchHunk{oldStart: start})
+			cur = &hunks[len(hunks)-1]
+		case cur == nil:
+			// Header or preamble before the first hunk
+		case strings.HasPrefix(line, " "):
+			cur.old = append(cur.old, line[1:])
+			cur.new = append(cur.new, line[1:])
+		case strings.HasPrefix(line, "-"):
+			cur.old = append(cur.old, line[1:])
+		case strings.HasPrefix(line, "+"):
+			cur.new = append(cur.new, line[1:])
+		case line == "":
+			// Blank context lines often lose their leading space in transit
+			cur.old = append(cur.old, "")
+			cur.new = append(cur.new, "")
+		case strings.HasPrefix(line, `\`):
+			// "\ No newline at end of file"
+		default:
+			return nil, fmt.Errorf("line %d: unexpected %q inside a hunk", i+1, line)
+		}
+	}
+	if len(hunks) == 0 {
+		return nil, fmt.Errorf("no hunks found: expected a unified diff with @@ headers")
+	}
+	// A trailing blank from the final newline is not context
+	last := &hunks[len(hunks)-1]
+	for len(last.old) > 0 && len(last.new) > 0 && last.old[len(last.old)-1] == "" && last.new[len(last.new)-1] == "" {
+		last.old, last.new = last.old[:len(last.old)-1], last.new[:len(last.new)-1]
+	}
+	return hunks, nil
+}
+
+// ApplyUnifiedDiff applies a single-file unified diff to content. Each hunk
+// must match exactly; it is looked for at the line its header gives, then at
+// the nearest offset, as patch(1) does. Line endings are matched as LF and
+// CRLF content keeps CRLF.
+func ApplyUnifiedDiff(content, patch string) (string, error) {
+	hunks, err := parseUnifiedDiff(patch)
+	if err != nil {
+		return "", err
+	}
+	crlf := strings.Contains(content, "\r\n")
+	text := normalizeLineEndings(content)
+	trailingNewline := text == "" || strings.HasSuffix(text, "\n")
+	lines := splitLines(text)
+
+	out := make([]string, 0, len(lines))
+	next := 0 // first line of lines not yet copied to out
+	for n, h := range hunks {
+		at := findHunk(lines, h.old, h.oldStart-1, next)
+		if at < 0 {
+			return "", fmt.Errorf("hunk %d (@@ -%d) does not match the file: its context or removed lines are not there", n+1, h.oldStart)
+		}
+		out = append(out, lines[next:at]...)
+		out = append(out, h.new...)
+		next = at + len(h.old)
+	}
+	out = append(out, lines[next:]...)
+
+	result := strings.Join(out, "\n")
+	if trailingNewline && len(out) > 0 {
+		result += "\n"
+	}
+	if crlf {
+		result = strings.ReplaceAll(result, "\n", "\r\n")
+	}
+	return result, nil
+}
+
+// findHunk returns where old occurs in lines at or after from, trying want
+// first and then ever larger offsets around it; -1 when it is nowhere
+func findHunk(lines, old []string, want, from int) int {
+	matches := func(at int) bool {
+		if at < from || at+len(old) > len(lines) {
+			return false
+		}
+		for i, l := range old {
+			if lines[at+i] != l {
+				return false
+			}
+		}
+		return true
+	}
+	if len(old) == 0 {
+		// Pure insertion: trust the header
+		if want < from {
+			want = from
+		}
+		if want > len(lines) {
+			want = len(lines)
+		}
+		return want
+	}
+	for off := 0; off <= len(lines); off++ {
+		if matches(want - off) {
+			return want - off
+		}
+		if off > 0 && matches(want+off) {
+			return want + off
+		}
+	}
+	return -1
+}
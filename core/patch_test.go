@@ -0,0 +1,91 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyUnifiedDiff(t *testing.T) {
+	content := "a\nb\nc\nd\ne\nf\n"
+	tests := []struct {
+		name, content, patch, want, err string
+	}{
+		{
+			name:    "replace",
+			content: content,
+			patch:   "--- a/x\n+++ b/x\n@@ -2,3 +2,3 @@\n b\n-c\n+C\n d\n",
+			want:    "a\nb\nC\nd\ne\nf\n",
+		},
+		{
+			name:    "offset",
+			content: "new\nnew\n" + content,
+			patch:   "@@ -5,2 +5,2 @@\n e\n-f\n+F\n",
+			want:    "new\nnew\na\nb\nc\nd\ne\nF\n",
+		},
+		{
+			name:    "insert only",
+			content: content,
+			patch:   "@@ -3,0 +4,1 @@\n+inserted\n",
+			want:    "a\nb\nc\ninserted\nd\ne\nf\n",
+		},
+		{
+			name:    "two hunks",
+			content: content,
+			patch:   "@@ -1,1 +1,1 @@\n-a\n+A\n@@ -6,1 +6,2 @@\n f\n+g\n",
+			want:    "A\nb\nc\nd\ne\nf\ng\n",
+		},
+		{
+			name:    "crlf kept",
+			content: "a\r\nb\r\n",
+			patch:   "@@ -1,2 +1,2 @@\n a\n-b\n+B\n",
+			want:    "a\r\nB\r\n",
+		},
+		{
+			name:    "mismatch",
+			content: content,
+			patch:   "@@ -2,2 +2,2 @@\n b\n-x\n+y\n",
+			err:     "hunk 1",
+		},
+		{
+			name:    "two files",
+			content: content,
+			patch:   "+++ b/x\n@@ -1 +1 @@\n-a\n+A\n+++ b/y\n@@ -1 +1 @@\n-a\n+A\n",
+			err:     "more than one file",
+		},
+		{
+			name:    "not a diff",
+			content: content,
+			patch:   "just text\n",
+			err:     "no hunks",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyUnifiedDiff(tt.content, tt.patch)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("err = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyUnifiedDiff_RoundTrip(t *testing.T) {
+	old := "package main\n\nfunc main() {\n\tprintln(\"hi\")\n}\n"
+	updated := "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hi\")\n}\n"
+	got, err := ApplyUnifiedDiff(old, UnifiedDiff(old, updated, "main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != updated {
+		t.Errorf("got %q, want %q", got, updated)
+	}
+}
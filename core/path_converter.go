@@ -11,27 +11,24 @@
 // Example: /home/user/file.txt -> C:\Users\user\file.txt
 // Example: /tmp/test.txt -> C:\Users\user\AppData\Local\Temp\test.txt
 // Example: /mnt/c/Projects/file.go -> C:\Projects\file.go (already Windows)
+// Example: \\wsl$\Ubuntu\home\user\x -> \\wsl.localhost\Ubuntu\home\user\x
 func WSLToWindows(wslPath string) (string, error) {
 	if wslPath == "" {
 		return "", fmt.Errorf("empty path provided")
 	}
 
+	// \\wsl$\<distro>\... is already the Windows view of the file
+	if distro, inner, ok := ParseWSLUNCPath(wslPath); ok {
+		return wslUNCPath(distro, inner), nil
+	}
+
+	// If it's a /mnt/c/ style path (or a mapped drive mount), convert it
+	if driveLetter, remainder, ok := splitWSLDrivePath(wslPath); ok {
+		return driveLetter + ":\\" + strings.ReplaceAll(remainder, "/", "\\"), nil
+	}
+
 	// If it's already a Windows path, return as-is
 	if IsWindowsPath(wslPath) {
-		// If it's a /mnt/c/ style path, convert it
-		if strings.HasPrefix(wslPath, "/mnt/") && len(wslPath) > 6 {
-			parts := strings.SplitN(wslPath[5:], "/", 2)
-			if len(parts) >= 1 && len(parts[0]) == 1 {
-				driveLetter := strings.ToUpper(parts[0])
-				remainder := ""
-				if len(parts) > 1 {
-					remainder = parts[1]
-					// Convert forward slashes to backslashes
-					remainder = filepath.FromSlash(remainder)
-				}
-				return driveLetter + ":\\" + remainder, nil
-			}
-		}
 		// Already in C:\ format
 		return wslPath, nil
 	}
@@ -99,27 +96,32 @@ func WSLToWindows(wslPath string) (string, error) {
 // Example: C:\Users\user\file.txt -> /home/user/file.txt
 // Example: C:\Projects\test.go -> /mnt/c/Projects/test.go
 // Example: /home/user/file.txt -> /home/user/file.txt (already WSL)
+// Example: \\wsl$\Ubuntu\home\user\file.txt -> /home/user/file.txt
 func WindowsToWSL(winPath string) (string, error) {
 	if winPath == "" {
 		return "", fmt.Errorf("empty path provided")
 	}
 
+	// \\wsl.localhost\<distro>\home\... -> /home/...
+	if _, inner, ok := ParseWSLUNCPath(winPath); ok {
+		return inner, nil
+	}
+
 	// If it's already a WSL path, return as-is
 	if IsWSLPath(winPath) {
 		return winPath, nil
 	}
 
 	// Handle /mnt/ style paths (already in WSL format but for Windows drives)
-	if strings.HasPrefix(winPath, "/mnt/") {
+	if _, _, ok := splitWSLDrivePath(winPath); ok || strings.HasPrefix(winPath, "/mnt/") {
 		return winPath, nil
 	}
 
 	// Handle Windows absolute paths: C:\... or C:/...
 	if len(winPath) >= 3 && winPath[1] == ':' && (winPath[2] == '\\' || winPath[2] == '/') {
-		driveLetter := strings.ToLower(string(winPath[0]))
-		remainder := winPath[3:]
-		// Convert backslashes to forward slashes
-		remainder = filepath.ToSlash(remainder)
+		// Convert backslashes to forward slashes (filepath.ToSlash is a
+		// no-op on Linux, where this conversion runs)
+		remainder := strings.ReplaceAll(winPath[3:], "\\", "/")
 
 		// Check if this is a Users directory path
 		if strings.HasPrefix(strings.ToLower(remainder), "users/") {
@@ -152,8 +154,13 @@ func WindowsToWSL(winPath string) (string, error) {
 			}
 		}
 
-		// For non-Users paths, use /mnt/ style
-		return "/mnt/" + driveLetter + "/" + remainder, nil
+		// For non-Users paths, use the drive's mount point; an unmapped
+		// drive is an error rather than a bogus /mnt/<x> path
+		mount, err := driveMountChecked(winPath[0])
+		if err != nil {
+			return "", err
+		}
+		return mount + "/" + remainder, nil
 	}
 
 	// Handle UNC paths: \\server\share -> /mnt/server/share
@@ -181,6 +188,66 @@ func ConvertPath(path string) (converted string, wasWSL bool, err error) {
 	return "", false, fmt.Errorf("could not determine path type: %s", path)
 }
 
+// PathConversion is the result of ConvertPathTo
+type PathConversion struct {
+	Input     string `json:"input"`
+	Target    string `json:"target"`     // "windows" or "wsl"
+	Converted string `json:"converted"`  // the path in the target's format
+	LocalPath string `json:"local_path"` // the form this server opens (NormalizePath)
+	Allowed   bool   `json:"allowed"`    // LocalPath is inside the allowed paths
+	Exists    bool   `json:"exists"`     // only checked when Allowed
+	IsDir     bool   `json:"is_dir,omitempty"`
+}
+
+// ConvertPathTo converts path to target ("windows", "wsl", or "auto" for the
+// opposite of its current format) with the same rules as NormalizePath,
+// WSLToWindows and WindowsToWSL, including UNC paths and drive mappings.
+// allowed decides whether the converted file may be stat'ed; a nil allowed
+// permits everything.
+func ConvertPathTo(path, target string, allowed func(string) bool) (*PathConversion, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil, fmt.Errorf("empty path provided")
+	}
+	if target == "" || target == "auto" {
+		// Anything written the Linux way, /mnt/c/... included, goes to
+		// Windows; drive letters and UNC paths go to WSL
+		_, _, isUNC := ParseWSLUNCPath(path)
+		switch {
+		case strings.HasPrefix(path, "/") && !isUNC:
+			target = "windows"
+		case IsWindowsPath(path):
+			target = "wsl"
+		default:
+			return nil, fmt.Errorf("could not determine path type: %s (pass target: windows or wsl)", path)
+		}
+	}
+
+	var converted string
+	var err error
+	switch target {
+	case "windows":
+		converted, err = WSLToWindows(path)
+	case "wsl":
+		converted, err = WindowsToWSL(path)
+	default:
+		return nil, fmt.Errorf("invalid target: %s (must be windows, wsl, or auto)", target)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PathConversion{Input: path, Target: target, Converted: converted, LocalPath: NormalizePath(converted)}
+	result.Allowed = allowed == nil || allowed(result.LocalPath)
+	if result.Allowed {
+		if info, err := os.Stat(result.LocalPath); err == nil {
+			result.Exists = true
+			result.IsDir = info.IsDir()
+		}
+	}
+	return result, nil
+}
+
 // NormalizePathAdvanced is an enhanced version that returns both normalized path and metadata
 func NormalizePathAdvanced(path string) (normalized string, isWSL bool, isWindows bool, err error) {
 	if path == "" {
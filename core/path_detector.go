@@ -4,6 +4,7 @@
 	"fmt"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -127,19 +128,62 @@ func IsWSLPath(path string) bool {
 	}
 
 	// WSL paths typically start with / (absolute Unix paths)
-	// but not /mnt/<drive> which are Windows paths accessed from WSL
-	if strings.HasPrefix(path, "/mnt/") && len(path) > 6 {
-		parts := strings.SplitN(path[5:], "/", 2)
-		if len(parts) >= 1 && len(parts[0]) == 1 {
-			// This is a Windows path accessed from WSL (/mnt/c/...)
-			return false
-		}
+	// but not /mnt/<drive> (or a mapped drive mount) which are Windows
+	// paths accessed from WSL
+	if _, _, ok := splitWSLDrivePath(path); ok {
+		return false
+	}
+
+	// //wsl.localhost/<distro>/... is the Windows view of a WSL path
+	if _, _, ok := ParseWSLUNCPath(path); ok {
+		return false
 	}
 
 	// True WSL paths: /home/..., /tmp/..., /usr/..., etc.
 	return strings.HasPrefix(path, "/") && !strings.HasPrefix(path, "/mnt/")
 }
 
+// wslUNCPrefixes are the Windows UNC roots of WSL distributions, lower case
+var wslUNCPrefixes = []string{`\\wsl.localhost\`, `\\wsl$\`}
+
+// ParseWSLUNCPath splits a \\wsl.localhost\<distro>\... or \\wsl$\<distro>\...
+// path (either slash direction, any case) into the distro name and the
+// cleaned path inside the distro: \\wsl$\Ubuntu-22.04\home\u ->
+// ("Ubuntu-22.04", "/home/u").
+func ParseWSLUNCPath(p string) (distro, inner string, ok bool) {
+	s := strings.ReplaceAll(p, "/", `\`)
+	lower := strings.ToLower(s)
+	for _, prefix := range wslUNCPrefixes {
+		if !strings.HasPrefix(lower, prefix) {
+			continue
+		}
+		distro, rest, _ := strings.Cut(s[len(prefix):], `\`)
+		if distro == "" {
+			return "", "", false
+		}
+		return distro, path.Clean("/" + strings.ReplaceAll(rest, `\`, "/")), true
+	}
+	return "", "", false
+}
+
+// wslUNCPath returns the canonical \\wsl.localhost form of a path inside distro
+func wslUNCPath(distro, inner string) string {
+	return `\\wsl.localhost\` + distro + strings.ReplaceAll(path.Clean("/"+inner), "/", `\`)
+}
+
+// canonicalWSLForm maps the UNC and in-distro spellings of a WSL file to one
+// form for comparisons: the in-distro path inside WSL, \\wsl.localhost on
+// Windows. Other paths are returned unchanged.
+func canonicalWSLForm(p string) string {
+	if _, _, ok := ParseWSLUNCPath(p); ok {
+		return NormalizePath(p)
+	}
+	if os.PathSeparator == '\\' && strings.HasPrefix(p, "/") && !IsWindowsPath(p) {
+		return NormalizePath(p)
+	}
+	return p
+}
+
 // IsWindowsPath checks if the given path is a Windows-style path
 func IsWindowsPath(path string) bool {
 	if len(path) < 3 {
@@ -151,17 +195,17 @@ func IsWindowsPath(path string) bool {
 		return true
 	}
 
-	// Windows UNC paths: \\server\share
+	// Windows UNC paths: \\server\share, and //wsl.localhost/<distro>/...
 	if strings.HasPrefix(path, "\\\\") {
 		return true
 	}
+	if _, _, ok := ParseWSLUNCPath(path); ok {
+		return true
+	}
 
-	// WSL-style Windows mount: /mnt/c/...
-	if strings.HasPrefix(path, "/mnt/") && len(path) > 6 {
-		parts := strings.SplitN(path[5:], "/", 2)
-		if len(parts) >= 1 && len(parts[0]) == 1 {
-			return true
-		}
+	// WSL-style Windows mount: /mnt/c/... or a --path-mappings mount
+	if _, _, ok := splitWSLDrivePath(path); ok {
+		return true
 	}
 
 	return false
@@ -212,14 +256,14 @@ func GetWindowsHome() (wslStyle string, windowsStyle string) {
 		winUser = "user" // fallback
 	}
 
-	wslStyle = "/mnt/c/Users/" + winUser
+	wslStyle = driveMount('c') + "/Users/" + winUser
 	windowsStyle = "C:\\Users\\" + winUser
 
 	// Verify the WSL-style path exists
 	if _, err := os.Stat(wslStyle); err != nil {
 		// Try other common drive letters
 		for _, drive := range []string{"d", "e"} {
-			altPath := "/mnt/" + drive + "/Users/" + winUser
+			altPath := driveMount(drive[0]) + "/Users/" + winUser
 			if _, err := os.Stat(altPath); err == nil {
 				driveLetter := strings.ToUpper(drive)
 				wslStyle = altPath
@@ -0,0 +1,117 @@
+package core
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// splitGlobBase splits a path glob into the longest leading directory
+// without wildcards and the slash-separated pattern below it:
+// "/repo/gen/**/*.pb.go" -> ("/repo/gen", "**/*.pb.go").
+func splitGlobBase(pattern string) (string, string) {
+	parts := strings.Split(filepath.ToSlash(filepath.Clean(pattern)), "/")
+	for i, part := range parts {
+		if !isGlobPattern(part) {
+			continue
+		}
+		if i == 0 {
+			return ".", strings.Join(parts, "/")
+		}
+		base := strings.Join(parts[:i], "/")
+		if base == "" {
+			base = "/"
+		}
+		return filepath.FromSlash(base), strings.Join(parts[i:], "/")
+	}
+	return filepath.Clean(pattern), ""
+}
+
+// matchGlobSegments matches path segments against pattern segments using
+// path.Match per segment; a "**" segment matches zero or more segments.
+func matchGlobSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchGlobSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// expandPathGlob returns the regular files matching pattern, in lexical
+// order. Symlinked directories are not followed. allowed (nil = no
+// restriction) is checked on the base directory and on every match, so the
+// expansion never leaves the allowed paths. More than maxMatches matches is
+// an error rather than a silently truncated list.
+func expandPathGlob(pattern string, maxMatches int, allowed func(string) bool) ([]string, error) {
+	base, rest := splitGlobBase(NormalizePath(pattern))
+	if rest == "" {
+		return nil, fmt.Errorf("not a glob pattern: %s", pattern)
+	}
+	if allowed != nil && !allowed(base) {
+		return nil, fmt.Errorf("access denied: glob base '%s' is not in allowed paths", base)
+	}
+	if info, err := os.Stat(base); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("glob base directory does not exist: %s", base)
+	}
+
+	patternSegs := strings.Split(rest, "/")
+	maxDepth := -1 // unlimited with "**"
+	if !strings.Contains(rest, "**") {
+		maxDepth = len(patternSegs)
+	}
+
+	var matches []string
+	err := filepath.WalkDir(base, func(p string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil || p == base {
+			return nil
+		}
+		rel, relErr := filepath.Rel(base, p)
+		if relErr != nil {
+			return nil
+		}
+		segs := strings.Split(filepath.ToSlash(rel), "/")
+		if d.IsDir() {
+			if maxDepth >= 0 && len(segs) >= maxDepth {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !matchGlobSegments(patternSegs, segs) {
+			return nil
+		}
+		if allowed != nil && !allowed(p) {
+			return nil
+		}
+		matches = append(matches, p)
+		if maxMatches > 0 && len(matches) > maxMatches {
+			return errWalkStop
+		}
+		return nil
+	})
+	if err != nil && err != errWalkStop {
+		return nil, err
+	}
+	if maxMatches > 0 && len(matches) > maxMatches {
+		return nil, fmt.Errorf("glob '%s' matches more than max_matches=%d files; narrow the pattern or raise max_matches", pattern, maxMatches)
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("glob '%s' matched no files", pattern)
+	}
+	return matches, nil
+}
@@ -0,0 +1,111 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMatchGlobSegments(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"*.tmp", "a.tmp", true},
+		{"*.tmp", "sub/a.tmp", false},
+		{"**/*.tmp", "a.tmp", true},
+		{"**/*.tmp", "x/y/a.tmp", true},
+		{"gen/**", "gen/a/b.go", true},
+		{"sub/*.go", "sub/a.go", true},
+		{"sub/*.go", "other/a.go", false},
+	}
+	for _, tt := range tests {
+		if got := matchGlobSegments(strings.Split(tt.pattern, "/"), strings.Split(tt.name, "/")); got != tt.want {
+			t.Errorf("matchGlobSegments(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+	if base, rest := splitGlobBase("/repo/gen/**/*.pb.go"); base != filepath.FromSlash("/repo/gen") || rest != "**/*.pb.go" {
+		t.Errorf("splitGlobBase = %q, %q", base, rest)
+	}
+}
+
+func createGlobTree(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(p), 0755)
+		if err := os.WriteFile(p, []byte("x\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestBatchGlob_ValidateOnlyEchoesAndDeleteExpands(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	createGlobTree(t, dir, "gen/a.tmp", "gen/sub/b.tmp", "gen/keep.go", "c.tmp")
+	mgr := NewBatchOperationManager(t.TempDir(), 10)
+	mgr.SetEngine(engine)
+
+	req := BatchRequest{
+		ValidateOnly: true,
+		Operations:   []FileOperation{{Type: "delete", Path: filepath.Join(dir, "gen", "**", "*.tmp")}},
+	}
+	res := mgr.ExecuteBatch(req)
+	if !res.Success || res.TotalOps != 2 || len(res.GlobExpansions) != 1 {
+		t.Fatalf("validate_only = %+v", res)
+	}
+	want := []string{filepath.Join(dir, "gen", "a.tmp"), filepath.Join(dir, "gen", "sub", "b.tmp")}
+	if got := res.GlobExpansions[0].Matches; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("matches = %v, want %v", got, want)
+	}
+	if _, err := os.Stat(want[0]); err != nil {
+		t.Fatal("validate_only deleted files")
+	}
+
+	req.ValidateOnly = false
+	if res = mgr.ExecuteBatch(req); !res.Success || res.CompletedOps != 2 {
+		t.Fatalf("delete via glob = %+v", res)
+	}
+	for _, f := range want {
+		if _, err := os.Stat(f); !os.IsNotExist(err) {
+			t.Errorf("%s not deleted", f)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "c.tmp")); err != nil {
+		t.Error("file outside the glob base was deleted")
+	}
+}
+
+func TestBatchGlob_GuardsAndCopyToDirectory(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	createGlobTree(t, dir, "logs/1.log", "logs/2.log", "logs/3.log")
+	os.MkdirAll(filepath.Join(dir, "archive"), 0755)
+	mgr := NewBatchOperationManager(t.TempDir(), 10)
+	mgr.SetEngine(engine)
+
+	res := mgr.ExecuteBatch(BatchRequest{
+		MaxMatches: 2,
+		Operations: []FileOperation{{Type: "delete", Path: filepath.Join(dir, "logs", "*.log")}},
+	})
+	if res.Success || !strings.Contains(strings.Join(res.Errors, " "), "max_matches=2") {
+		t.Errorf("max_matches guard: %+v", res)
+	}
+
+	outside := t.TempDir()
+	createGlobTree(t, outside, "x.log")
+	res = mgr.ExecuteBatch(BatchRequest{Operations: []FileOperation{{Type: "delete", Path: filepath.Join(outside, "*.log")}}})
+	if res.Success || !strings.Contains(strings.Join(res.Errors, " "), "access denied") {
+		t.Errorf("glob outside allowed paths: %+v", res)
+	}
+
+	res = mgr.ExecuteBatch(BatchRequest{Operations: []FileOperation{
+		{Type: "copy", Source: filepath.Join(dir, "logs", "*.log"), Destination: filepath.Join(dir, "archive")},
+	}})
+	if !res.Success || res.CompletedOps != 3 {
+		t.Fatalf("copy glob = %+v", res)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "archive", "2.log")); err != nil {
+		t.Errorf("copy glob did not keep base names: %v", err)
+	}
+}
@@ -0,0 +1,195 @@
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Drive/mount mappings used by NormalizePath, WSLToWindows and WindowsToWSL.
+// A drive letter maps to an explicit --path-mappings entry ("Z:=/mnt/share")
+// or else to <automount root><letter>, the root coming from /etc/wsl.conf
+// ([automount] root, default /mnt/).
+var (
+	pathMappingsMu sync.RWMutex
+	pathMappings   map[string]string // upper-case drive letter -> WSL mount point
+
+	automountOnce sync.Once
+	automountRoot = "/mnt/"
+
+	wslConfPath = "/etc/wsl.conf"
+
+	// mountedDrives caches drive mount points seen to exist inside WSL
+	mountedDrives sync.Map
+)
+
+// ParsePathMappings parses a --path-mappings value: comma-separated
+// "<letter>:=<absolute WSL path>" pairs such as "Z:=/mnt/share,Y:=/media/y".
+func ParsePathMappings(spec string) (map[string]string, error) {
+	mappings := make(map[string]string)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		drive, mount, ok := strings.Cut(entry, "=")
+		drive = strings.TrimSuffix(strings.TrimSpace(drive), ":")
+		mount = strings.TrimSpace(mount)
+		if !ok || len(drive) != 1 || !isDriveLetter(drive[0]) {
+			return nil, fmt.Errorf("invalid path mapping %q: want <letter>:=<path>, e.g. Z:=/mnt/share", entry)
+		}
+		if !strings.HasPrefix(mount, "/") {
+			return nil, fmt.Errorf("invalid path mapping %q: mount point must be an absolute WSL path", entry)
+		}
+		mappings[strings.ToUpper(drive)] = path.Clean(mount)
+	}
+	return mappings, nil
+}
+
+// SetPathMappings installs explicit drive mappings (nil clears them)
+func SetPathMappings(mappings map[string]string) {
+	pathMappingsMu.Lock()
+	defer pathMappingsMu.Unlock()
+	pathMappings = make(map[string]string, len(mappings))
+	for drive, mount := range mappings {
+		pathMappings[strings.ToUpper(drive)] = path.Clean(mount)
+	}
+}
+
+// PathMappings returns a copy of the explicit drive mappings
+func PathMappings() map[string]string {
+	pathMappingsMu.RLock()
+	defer pathMappingsMu.RUnlock()
+	out := make(map[string]string, len(pathMappings))
+	for drive, mount := range pathMappings {
+		out[drive] = mount
+	}
+	return out
+}
+
+// wslAutomountRoot returns the automount root ("/mnt/" unless /etc/wsl.conf
+// says otherwise), always with a trailing slash. The file is parsed once.
+func wslAutomountRoot() string {
+	automountOnce.Do(func() {
+		if root := parseAutomountRoot(wslConfPath); root != "" {
+			automountRoot = root
+		}
+	})
+	return automountRoot
+}
+
+// parseAutomountRoot reads the root key of the [automount] section of a
+// wsl.conf file, or "" when it is absent
+func parseAutomountRoot(file string) string {
+	f, err := os.Open(file)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	section := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.ToLower(strings.TrimSpace(line[1 : len(line)-1]))
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || section != "automount" || strings.ToLower(strings.TrimSpace(key)) != "root" {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		if !strings.HasPrefix(value, "/") {
+			return ""
+		}
+		return strings.TrimSuffix(path.Clean(value), "/") + "/"
+	}
+	return ""
+}
+
+// driveMount returns the WSL mount point of a drive letter
+func driveMount(letter byte) string {
+	drive := strings.ToUpper(string(letter))
+	pathMappingsMu.RLock()
+	mount, ok := pathMappings[drive]
+	pathMappingsMu.RUnlock()
+	if ok {
+		return mount
+	}
+	return wslAutomountRoot() + strings.ToLower(drive)
+}
+
+// driveMountChecked is driveMount for conversions that must not invent a
+// path: inside WSL the mount point has to exist, so an unmapped network
+// drive is an error instead of a bogus /mnt/z path.
+func driveMountChecked(letter byte) (string, error) {
+	mount := driveMount(letter)
+	if isWSL, _ := DetectEnvironment(); !isWSL {
+		return mount, nil
+	}
+	if _, ok := mountedDrives.Load(mount); ok {
+		return mount, nil
+	}
+	if info, err := os.Stat(mount); err != nil || !info.IsDir() {
+		drive := strings.ToUpper(string(letter))
+		return "", fmt.Errorf("drive %s: is not mounted in WSL (no %s); map it with --path-mappings \"%s:=/path/to/mount\"", drive, mount, drive)
+	}
+	mountedDrives.Store(mount, true)
+	return mount, nil
+}
+
+// splitWSLDrivePath splits a WSL path under a drive mount point into the
+// upper-case drive letter and the slash-separated remainder:
+// /mnt/c/Users/x -> ("C", "Users/x"). Explicit mappings win, longest first.
+func splitWSLDrivePath(p string) (drive, rest string, ok bool) {
+	if !strings.HasPrefix(p, "/") {
+		return "", "", false
+	}
+	p = path.Clean(p)
+
+	pathMappingsMu.RLock()
+	mounts := make([]string, 0, len(pathMappings))
+	byMount := make(map[string]string, len(pathMappings))
+	for d, mount := range pathMappings {
+		mounts = append(mounts, mount)
+		byMount[mount] = d
+	}
+	pathMappingsMu.RUnlock()
+	sort.Slice(mounts, func(i, j int) bool { return len(mounts[i]) > len(mounts[j]) })
+	for _, mount := range mounts {
+		if p == mount {
+			return byMount[mount], "", true
+		}
+		if strings.HasPrefix(p, mount+"/") {
+			return byMount[mount], p[len(mount)+1:], true
+		}
+	}
+
+	root := wslAutomountRoot()
+	if !strings.HasPrefix(p+"/", root) {
+		return "", "", false
+	}
+	letter, rest, _ := strings.Cut(strings.TrimPrefix(p+"/", root), "/")
+	if len(letter) != 1 || !isDriveLetter(letter[0]) {
+		return "", "", false
+	}
+	return strings.ToUpper(letter), strings.TrimSuffix(rest, "/"), true
+}
+
+func isDriveLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// logUnmappedDrive reports a drive NormalizePath could not convert
+func logUnmappedDrive(p string, err error) {
+	slog.Warn("Path not converted", "path", p, "error", err)
+}
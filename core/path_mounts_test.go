@@ -0,0 +1,164 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// withAutomountRoot overrides the wsl.conf automount root for one test
+func withAutomountRoot(t *testing.T, root string) {
+	t.Helper()
+	wslAutomountRoot() // settle the once before overriding
+	prev := automountRoot
+	automountRoot = root
+	t.Cleanup(func() { automountRoot = prev })
+}
+
+// withPathMappings installs explicit drive mappings for one test
+func withPathMappings(t *testing.T, mappings map[string]string) {
+	t.Helper()
+	prev := PathMappings()
+	SetPathMappings(mappings)
+	t.Cleanup(func() { SetPathMappings(prev) })
+}
+
+// fakeWSL makes DetectEnvironment report WSL for one test
+func fakeWSL(t *testing.T) {
+	t.Helper()
+	envCacheMu.Lock()
+	prevWSL, prevUser, prevAt := envCacheIsWSL, envCacheWinUser, envCacheCachedAt
+	envCacheIsWSL, envCacheWinUser, envCacheCachedAt = true, "tester", time.Now()
+	envCacheMu.Unlock()
+	t.Cleanup(func() {
+		envCacheMu.Lock()
+		envCacheIsWSL, envCacheWinUser, envCacheCachedAt = prevWSL, prevUser, prevAt
+		envCacheMu.Unlock()
+		mountedDrives.Clear()
+	})
+}
+
+func TestParsePathMappings(t *testing.T) {
+	got, err := ParsePathMappings(" Z:=/mnt/share/ , y=/media/y")
+	if err != nil || got["Z"] != "/mnt/share" || got["Y"] != "/media/y" || len(got) != 2 {
+		t.Errorf("ParsePathMappings = %v, %v", got, err)
+	}
+	for _, bad := range []string{"Z:/mnt/share", "ZZ:=/mnt/share", "1:=/x", "Z:=relative/path"} {
+		if _, err := ParsePathMappings(bad); err == nil {
+			t.Errorf("ParsePathMappings(%q) accepted", bad)
+		}
+	}
+}
+
+func TestParseAutomountRoot(t *testing.T) {
+	dir := t.TempDir()
+	cases := map[string]string{
+		"[automount]\nenabled = true\nroot = /\n":                        "/",
+		"# comment\n[network]\nroot=/x\n[automount]\nroot = \"/win/\"\n": "/win/",
+		"[Automount]\nRoot=/drives\n":                                    "/drives/",
+		"[boot]\nsystemd=true\n":                                         "",
+	}
+	i := 0
+	for content, want := range cases {
+		i++
+		file := filepath.Join(dir, "wsl.conf"+string(rune('0'+i)))
+		os.WriteFile(file, []byte(content), 0644)
+		if got := parseAutomountRoot(file); got != want {
+			t.Errorf("parseAutomountRoot(%q) = %q, want %q", content, got, want)
+		}
+	}
+	if got := parseAutomountRoot(filepath.Join(dir, "missing")); got != "" {
+		t.Errorf("missing file: %q", got)
+	}
+}
+
+func TestPathConversion_MappedDrivesAndAutomountRoot(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("WSL-side conversion")
+	}
+	withPathMappings(t, map[string]string{"Z": "/mnt/share"})
+
+	if got, err := WSLToWindows("/mnt/share/docs/a.txt"); err != nil || got != `Z:\docs\a.txt` {
+		t.Errorf("WSLToWindows(mapped) = %q, %v", got, err)
+	}
+	if got := NormalizePath(`Z:\docs\a.txt`); got != "/mnt/share/docs/a.txt" {
+		t.Errorf("NormalizePath(Z:) = %q", got)
+	}
+	if got, err := WindowsToWSL(`Z:\docs\a.txt`); err != nil || got != "/mnt/share/docs/a.txt" {
+		t.Errorf("WindowsToWSL(Z:) = %q, %v", got, err)
+	}
+	if IsWSLPath("/mnt/share/docs") || !IsWindowsPath("/mnt/share/docs") {
+		t.Error("mapped mount not classified as a Windows drive path")
+	}
+
+	// wsl.conf root = / mounts drives at /c, /d, ...
+	withAutomountRoot(t, "/")
+	if got := NormalizePath(`D:\work\x.go`); got != "/d/work/x.go" {
+		t.Errorf("NormalizePath with root / = %q", got)
+	}
+	if got, err := WSLToWindows("/d/work/x.go"); err != nil || got != `D:\work\x.go` {
+		t.Errorf("WSLToWindows with root / = %q, %v", got, err)
+	}
+	if !IsWSLPath("/home/u") {
+		t.Error("/home/u taken for a drive path")
+	}
+}
+
+func TestPathConversion_UnmappedDriveFailsInWSL(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("WSL-side conversion")
+	}
+	root := t.TempDir()
+	os.Mkdir(filepath.Join(root, "c"), 0755)
+	withAutomountRoot(t, root+"/")
+	fakeWSL(t)
+
+	if got := NormalizePath(`C:\proj\main.go`); got != root+"/c/proj/main.go" {
+		t.Errorf("mounted drive: %q", got)
+	}
+	// Q: is not mounted: no bogus <root>/q path
+	if got := NormalizePath(`Q:\data\x`); got != `Q:\data\x` {
+		t.Errorf("unmapped drive converted to %q", got)
+	}
+	if _, err := WindowsToWSL(`Q:\data\x`); err == nil || !strings.Contains(err.Error(), "--path-mappings") {
+		t.Errorf("WindowsToWSL(Q:) err = %v", err)
+	}
+}
+
+func TestConvertPathTo(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("WSL-side conversion")
+	}
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "c", "proj"), 0755)
+	os.WriteFile(filepath.Join(root, "c", "proj", "main.go"), []byte("package main\n"), 0644)
+	withAutomountRoot(t, root+"/")
+	fakeWSL(t)
+
+	conv, err := ConvertPathTo(`C:\proj\main.go`, "auto", nil)
+	if err != nil || conv.Target != "wsl" || conv.Converted != root+"/c/proj/main.go" || !conv.Exists || conv.IsDir {
+		t.Fatalf("Windows -> WSL = %+v, %v", conv, err)
+	}
+	conv, err = ConvertPathTo(root+"/c/proj", "auto", nil)
+	if err != nil || conv.Target != "windows" || conv.Converted != `C:\proj` || conv.LocalPath != root+"/c/proj" || !conv.IsDir {
+		t.Errorf("WSL -> Windows = %+v, %v", conv, err)
+	}
+	conv, err = ConvertPathTo(`\\wsl$\Ubuntu\home\u\x.txt`, "", nil)
+	if err != nil || conv.Target != "wsl" || conv.Converted != "/home/u/x.txt" || conv.Exists {
+		t.Errorf("UNC -> WSL = %+v, %v", conv, err)
+	}
+
+	// Outside the allowed paths existence is not revealed
+	conv, _ = ConvertPathTo(`C:\proj\main.go`, "wsl", func(string) bool { return false })
+	if conv.Allowed || conv.Exists {
+		t.Errorf("disallowed path was stat'ed: %+v", conv)
+	}
+	for _, bad := range [][2]string{{"", "auto"}, {"relative/x", "auto"}, {`C:\x`, "mac"}} {
+		if _, err := ConvertPathTo(bad[0], bad[1], nil); err == nil {
+			t.Errorf("ConvertPathTo(%q, %q) accepted", bad[0], bad[1])
+		}
+	}
+}
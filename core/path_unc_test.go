@@ -0,0 +1,107 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestParseWSLUNCPath(t *testing.T) {
+	cases := []struct {
+		in, distro, inner string
+		ok                bool
+	}{
+		{`\\wsl.localhost\Ubuntu\home\user\project\main.go`, "Ubuntu", "/home/user/project/main.go", true},
+		{`\\wsl$\Ubuntu\home\user`, "Ubuntu", "/home/user", true},
+		{`\\wsl.localhost\Ubuntu-22.04\home\user\a.txt`, "Ubuntu-22.04", "/home/user/a.txt", true},
+		{`\\wsl$\Ubuntu-24.04\tmp\x\..\y`, "Ubuntu-24.04", "/tmp/y", true},
+		{`\\WSL.LOCALHOST\Debian\etc\hosts`, "Debian", "/etc/hosts", true},
+		{`//wsl.localhost/openSUSE-Leap-15.6/home/u/`, "openSUSE-Leap-15.6", "/home/u", true},
+		{`\\wsl$\Ubuntu`, "Ubuntu", "/", true},
+		{`\\wsl$\`, "", "", false},
+		{`\\server\share\file`, "", "", false},
+		{`C:\Users\u`, "", "", false},
+		{`/home/user`, "", "", false},
+	}
+	for _, tc := range cases {
+		distro, inner, ok := ParseWSLUNCPath(tc.in)
+		if distro != tc.distro || inner != tc.inner || ok != tc.ok {
+			t.Errorf("ParseWSLUNCPath(%q) = (%q, %q, %v), want (%q, %q, %v)", tc.in, distro, inner, ok, tc.distro, tc.inner, tc.ok)
+		}
+	}
+}
+
+func TestNormalizePath_WSLUNC(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		for in, want := range map[string]string{
+			`\\wsl$\Ubuntu-22.04\home\u\a.go`:          `\\wsl.localhost\Ubuntu-22.04\home\u\a.go`,
+			`//wsl.localhost/Ubuntu-22.04/home/u/a.go`: `\\wsl.localhost\Ubuntu-22.04\home\u\a.go`,
+		} {
+			if got := NormalizePath(in); got != want {
+				t.Errorf("NormalizePath(%q) = %q, want %q", in, got, want)
+			}
+		}
+		return
+	}
+
+	t.Setenv("WSL_DISTRO_NAME", "Ubuntu-22.04")
+	for in, want := range map[string]string{
+		`\\wsl.localhost\Ubuntu-22.04\home\u\a.go`: "/home/u/a.go",
+		`\\wsl$\ubuntu-22.04\home\u\a.go`:          "/home/u/a.go",
+		`\\wsl$\Debian\home\u\a.go`:                `\\wsl$\Debian\home\u\a.go`, // another distro
+	} {
+		if got := NormalizePath(in); got != want {
+			t.Errorf("NormalizePath(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	t.Setenv("WSL_DISTRO_NAME", "")
+	if got := NormalizePath(`\\wsl$\Debian\srv\x`); got != "/srv/x" {
+		t.Errorf("outside WSL: got %q", got)
+	}
+}
+
+func TestWSLConversions_UNC(t *testing.T) {
+	if got, err := WindowsToWSL(`\\wsl.localhost\Ubuntu-22.04\home\u\main.go`); err != nil || got != "/home/u/main.go" {
+		t.Errorf("WindowsToWSL = %q, %v", got, err)
+	}
+	if got, err := WindowsToWSL(`\\wsl$\Ubuntu\tmp\x`); err != nil || got != "/tmp/x" {
+		t.Errorf("WindowsToWSL(wsl$) = %q, %v", got, err)
+	}
+	if got, err := WSLToWindows(`\\wsl$\Ubuntu-22.04\home\u`); err != nil || got != `\\wsl.localhost\Ubuntu-22.04\home\u` {
+		t.Errorf("WSLToWindows = %q, %v", got, err)
+	}
+	if IsWSLPath(`//wsl.localhost/Ubuntu/home/u`) || !IsWindowsPath(`//wsl.localhost/Ubuntu/home/u`) {
+		t.Error("forward-slash UNC path classified as a WSL path")
+	}
+}
+
+func TestIsPathAllowed_UNCAndInDistroFormsMatch(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("in-distro paths are only reachable from inside WSL")
+	}
+	t.Setenv("WSL_DISTRO_NAME", "Ubuntu-22.04")
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644)
+	unc := func(p string) string { return wslUNCPath("Ubuntu-22.04", p) }
+
+	engine := &UltraFastEngine{config: &Config{AllowedPaths: []string{dir}}}
+	for _, p := range []string{filepath.Join(dir, "main.go"), unc(filepath.Join(dir, "main.go")), `\\wsl$\Ubuntu-22.04` + filepath.FromSlash(dir)} {
+		if !engine.IsPathAllowed(p) {
+			t.Errorf("IsPathAllowed(%q) = false", p)
+		}
+	}
+	if engine.IsPathAllowed(unc("/etc/passwd")) {
+		t.Error("UNC path outside the allowed dir was allowed")
+	}
+
+	// An allowed path given in UNC form covers the in-distro form
+	engine = &UltraFastEngine{config: &Config{AllowedPaths: []string{unc(dir)}}}
+	if !engine.IsPathAllowed(filepath.Join(dir, "main.go")) {
+		t.Error("in-distro path not allowed under a UNC allowed path")
+	}
+	if !engine.IsAllowedPathRoot(`\\wsl$\Ubuntu-22.04` + dir) {
+		t.Error("UNC form of the allowed root not recognized as the root")
+	}
+}
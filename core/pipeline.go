@@ -2,7 +2,9 @@
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"math"
 	"os"
 	"path/filepath"
@@ -29,8 +31,10 @@ func toInt(v interface{}) int {
 
 // PipelineExecutor executes multi-step file transformation pipelines
 type PipelineExecutor struct {
-	engine     *UltraFastEngine
-	OnProgress func(stepIndex, totalSteps int, result StepResult) // Optional per-step progress callback
+	engine        *UltraFastEngine
+	OnProgress    func(stepIndex, totalSteps int, result StepResult) // Optional per-step progress callback
+	OnStatus      func(status PipelineStatus)                        // Optional status callback: step start/end and every ProgressEvery files
+	ProgressEvery int                                                // Files between OnStatus calls within a step (default DefaultPipelineProgressEvery)
 }
 
 // NewPipelineExecutor creates a new pipeline executor
@@ -40,8 +44,18 @@ func NewPipelineExecutor(engine *UltraFastEngine) *PipelineExecutor {
 	}
 }
 
-// Execute executes a complete pipeline
+// Execute executes a complete pipeline. The run is tracked for
+// GetPipelineStatus; cancelling ctx stops the current step at the next file
+// and rolls back like a stop_on_error failure.
 func (pe *PipelineExecutor) Execute(ctx context.Context, request PipelineRequest) (*PipelineResult, error) {
+	run := pe.engine.pipelineRuns.start(pe, request)
+	result, err := pe.execute(context.WithValue(ctx, pipelineRunKey{}, run), request)
+	run.finish(result, err, ctx.Err() != nil)
+	pe.engine.pipelineRuns.finish(run)
+	return result, err
+}
+
+func (pe *PipelineExecutor) execute(ctx context.Context, request PipelineRequest) (*PipelineResult, error) {
 	startTime := time.Now()
 
 	// Step 1: Validate request
@@ -57,8 +71,24 @@ func (pe *PipelineExecutor) Execute(ctx context.Context, request PipelineRequest
 		}, err
 	}
 
+	// Overall deadline: steps running when it passes fail with a timeout and
+	// the run stops (and rolls back) like a cancellation
+	if request.MaxDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(request.MaxDuration)*time.Second)
+		defer cancel()
+	}
+
 	// Step 2: Initialize context
 	pipelineCtx := NewPipelineContext()
+	pipelineCtx.SetPipelineInfo(request.Name, request.Variables)
+	if request.Verbose {
+		budget := int64(MaxPipelineDiffSnippet * MaxPipelineFiles)
+		if pe.engine.config != nil && pe.engine.config.MaxResponseSize > 0 {
+			budget = pe.engine.config.MaxResponseSize / 2 // leave room for the rest of the report
+		}
+		pipelineCtx.EnableDiffs(budget)
+	}
 
 	// Track pipeline execution in audit sub_op
 	AppendSubOp(ctx, fmt.Sprintf("pipeline:%d_steps", len(request.Steps)))
@@ -115,6 +145,7 @@ func (pe *PipelineExecutor) Execute(ctx context.Context, request PipelineRequest
 				}, err
 			}
 			pipelineCtx.SetBackupID(backupID)
+			markInFlightBackup(ctx, backupID)
 		}
 	}
 
@@ -152,10 +183,11 @@ func (pe *PipelineExecutor) Execute(ctx context.Context, request PipelineRequest
 
 		if err != nil || !stepResult.Success {
 			allSuccess = false
-			if request.StopOnError {
+			// A cancelled pipeline always stops; it rolls back like stop_on_error
+			if request.StopOnError || ctx.Err() != nil {
 				// Rollback if we have a backup
 				if backupID != "" && !request.DryRun {
-					if rollbackErr := pe.rollback(ctx, backupID); rollbackErr == nil {
+					if rollbackErr := pe.rollback(context.WithoutCancel(ctx), backupID, pipelineCtx); rollbackErr == nil {
 						return &PipelineResult{
 							Name:              request.Name,
 							Success:           false,
@@ -234,6 +266,22 @@ func (pe *PipelineExecutor) executeStep(ctx context.Context, step PipelineStep,
 		Success: false,
 	}
 
+	if run, ok := ctx.Value(pipelineRunKey{}).(*pipelineRun); ok {
+		run.beginStep(step)
+		ctx = withStepProgress(ctx, run, step.ID)
+		defer func() { run.endStep(result) }()
+	}
+	if ctx.Err() != nil {
+		var err error = &ContextError{Op: "pipeline", Details: fmt.Sprintf("cancelled before step %s", step.ID)}
+		if timeoutErr := stepTimeoutError(ctx, ctx, step); timeoutErr != nil {
+			result.TimedOut = true
+			err = timeoutErr
+		}
+		result.Error = err.Error()
+		result.Duration = time.Since(startTime)
+		return result, err
+	}
+
 	// Evaluate condition (skip if false)
 	if step.Condition != nil {
 		shouldRun, reason := EvaluateCondition(step.Condition, pipelineCtx, pe.engine)
@@ -247,12 +295,31 @@ func (pe *PipelineExecutor) executeStep(ctx context.Context, step PipelineStep,
 		}
 	}
 
-	// Resolve template variables in params
-	step.Params = ResolveTemplates(step.Params, pipelineCtx)
+	// Substitute ${var} placeholders, then {{step.field}} templates
+	params, varErr := ResolveVariables(step.Params, pipelineCtx)
+	if varErr != nil {
+		result.Error = varErr.Error()
+		result.Duration = time.Since(startTime)
+		return result, &PipelineStepError{
+			StepID:  step.ID,
+			Action:  step.Action,
+			Message: "variable substitution failed",
+			Err:     varErr,
+		}
+	}
+	step.Params = ResolveTemplates(params, pipelineCtx)
 
 	// Track each step action in audit sub_op chain
 	AppendSubOp(ctx, step.Action)
 
+	// Per-step timeout; per-file loops and engine calls observe ctx
+	pipelineDeadlineCtx := ctx
+	if step.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(step.Timeout)*time.Second)
+		defer cancel()
+	}
+
 	var err error
 
 	switch step.Action {
@@ -280,6 +347,14 @@ func (pe *PipelineExecutor) executeStep(ctx context.Context, step PipelineStep,
 		err = pe.executeDiff(ctx, step, pipelineCtx, &result)
 	case "merge":
 		err = pe.executeMerge(ctx, step, pipelineCtx, &result)
+	case "write":
+		err = pe.executeWrite(ctx, step, pipelineCtx, &result, dryRun)
+	case "create_dir":
+		err = pe.executeCreateDir(ctx, step, pipelineCtx, &result, dryRun)
+	case "filter":
+		err = pe.executeFilter(ctx, step, pipelineCtx, &result)
+	case "batch_rename":
+		err = pe.executeBatchRename(ctx, step, pipelineCtx, &result, dryRun)
 	default:
 		err = &PipelineStepError{
 			StepID:    step.ID,
@@ -292,6 +367,10 @@ func (pe *PipelineExecutor) executeStep(ctx context.Context, step PipelineStep,
 	result.Duration = time.Since(startTime)
 
 	if err != nil {
+		if timeoutErr := stepTimeoutError(ctx, pipelineDeadlineCtx, step); timeoutErr != nil {
+			result.TimedOut = true
+			err = timeoutErr
+		}
 		result.Success = false
 		result.Error = err.Error()
 		return result, err
@@ -301,6 +380,25 @@ func (pe *PipelineExecutor) executeStep(ctx context.Context, step PipelineStep,
 	return result, nil
 }
 
+// stepTimeoutError reports a failure as a timeout when stepCtx hit its
+// deadline: the step's own timeout_seconds, or max_duration_seconds when the
+// pipeline context (pipelineCtx) expired first. Returns nil otherwise.
+func stepTimeoutError(stepCtx, pipelineCtx context.Context, step PipelineStep) error {
+	if !errors.Is(stepCtx.Err(), context.DeadlineExceeded) {
+		return nil
+	}
+	message := fmt.Sprintf("timeout: step exceeded timeout_seconds=%d", step.Timeout)
+	if errors.Is(pipelineCtx.Err(), context.DeadlineExceeded) {
+		message = "timeout: pipeline exceeded max_duration_seconds"
+	}
+	return &PipelineStepError{
+		StepID:  step.ID,
+		Action:  step.Action,
+		Message: message,
+		Err:     context.DeadlineExceeded,
+	}
+}
+
 // executeSearch performs a smart search operation
 func (pe *PipelineExecutor) executeSearch(ctx context.Context, step PipelineStep, pipelineCtx *PipelineContext, result *StepResult) error {
 	// Extract parameters
@@ -332,7 +430,7 @@ func (pe *PipelineExecutor) executeSearch(ctx context.Context, step PipelineStep
 	}
 
 	// Perform search
-	matches, err := pe.performSmartSearchInternal(ctx, path, pattern, includeContent, fileTypes)
+	matches, stats, err := pe.performSmartSearchInternal(ctx, path, pattern, includeContent, fileTypes)
 	if err != nil {
 		return &PipelineStepError{
 			StepID:  step.ID,
@@ -348,6 +446,10 @@ func (pe *PipelineExecutor) executeSearch(ctx context.Context, step PipelineStep
 	for _, match := range matches {
 		result.FilesMatched = append(result.FilesMatched, match.FilePath)
 	}
+	if len(stats.skipped) > 0 {
+		result.FilesSkipped = stats.skipped
+	}
+	result.ResultsLimited = stats.limited
 
 	// Store matches in internal data for potential content access
 	result.internalData = matches
@@ -401,11 +503,14 @@ type lineRange struct{
 		ranges = append(ranges, lineRange{startLine, endLine})
 	}
 
-	for _, filePath := range files {
+	for i, filePath := range files {
+		if err := stepProgress(ctx, i, len(files)); err != nil {
+			return err
+		}
 		normalizedPath := NormalizePath(filePath)
 
 		// Check access
-		if len(pe.engine.config.AllowedPaths) > 0 {
+		if len(pe.engine.GetAllowedPaths()) > 0 {
 			if !pe.engine.IsPathAllowed(normalizedPath) {
 				return pe.engine.AccessDeniedError("read_ranges", normalizedPath)
 			}
@@ -504,22 +609,40 @@ func (pe *PipelineExecutor) executeEdit(ctx context.Context, step PipelineStep,
 	}
 
 	// Execute edits (or count for dry-run)
-	for _, filePath := range files {
+	for i, filePath := range files {
+		if err := stepProgress(ctx, i, len(files)); err != nil {
+			return err
+		}
 		normalizedPath := NormalizePath(filePath)
 
 		if dryRun {
-			// Just count occurrences
+			// Simulate with the same matcher EditFile uses, so counts and
+			// diffs match what the real run would write
 			content, err := os.ReadFile(normalizedPath)
 			if err != nil {
 				continue // Skip unreadable files in dry-run
 			}
-			count := strings.Count(string(content), oldText)
+			count := 0
+			if sim, simErr := pe.engine.performIntelligentEdit(string(content), oldText, newText, false); simErr == nil {
+				count = sim.ReplacementCount
+				if count > 0 {
+					pe.recordDiff(pipelineCtx, result, filePath, string(content), sim.ModifiedContent)
+				}
+			}
 			result.Counts[filePath] = count
 			totalEdits += count
 		} else {
+			var before []byte
+			if pipelineCtx.verboseDiffs {
+				before, _ = os.ReadFile(normalizedPath)
+			}
 			// Perform actual edit
 			// Force=true because pipeline already assessed risk at batch level
-			editResult, err := pe.engine.EditFile(ctx, normalizedPath, oldText, newText, true, dryRun, false)
+			var editResult *EditResult
+			err := pe.retryOp(ctx, step, result, func() (err error) {
+				editResult, err = pe.engine.EditFile(ctx, normalizedPath, oldText, newText, true, dryRun, false)
+				return err
+			})
 			if err != nil {
 				return &PipelineStepError{
 					StepID:  step.ID,
@@ -530,6 +653,10 @@ func (pe *PipelineExecutor) executeEdit(ctx context.Context, step PipelineStep,
 			}
 			result.Counts[filePath] = editResult.ReplacementCount
 			totalEdits += editResult.ReplacementCount
+			if before != nil {
+				after, _ := os.ReadFile(normalizedPath)
+				pe.recordDiff(pipelineCtx, result, filePath, string(before), string(after))
+			}
 		}
 	}
 
@@ -615,24 +742,29 @@ func (pe *PipelineExecutor) executeMultiEdit(ctx context.Context, step PipelineS
 	}
 
 	// Execute multi-edits
-	for _, filePath := range files {
+	for i, filePath := range files {
+		if err := stepProgress(ctx, i, len(files)); err != nil {
+			return err
+		}
 		normalizedPath := NormalizePath(filePath)
 
 		if dryRun {
-			// Count potential changes
-			content, err := os.ReadFile(normalizedPath)
+			// MultiEdit's own dry run applies the edits in memory exactly as
+			// the real run would (ambiguity guard, atomic failure)
+			editResult, err := pe.engine.MultiEdit(ctx, normalizedPath, edits, force, true, false, "")
 			if err != nil {
-				continue
+				continue // the real run would fail here; nothing to preview
 			}
-			count := 0
-			for _, edit := range edits {
-				count += strings.Count(string(content), edit.OldText)
-			}
-			result.Counts[filePath] = count
-			totalEdits += count
+			result.Counts[filePath] = editResult.TotalEdits
+			totalEdits += editResult.TotalEdits
+			pe.recordDiff(pipelineCtx, result, filePath, editResult.OriginalContent, editResult.FinalContent)
 		} else {
 			// Perform actual multi-edit
-			editResult, err := pe.engine.MultiEdit(ctx, normalizedPath, edits, force, dryRun, false, "")
+			var editResult *MultiEditResult
+			err := pe.retryOp(ctx, step, result, func() (err error) {
+				editResult, err = pe.engine.MultiEdit(ctx, normalizedPath, edits, force, dryRun, false, "")
+				return err
+			})
 			if err != nil {
 				return &PipelineStepError{
 					StepID:  step.ID,
@@ -643,6 +775,9 @@ func (pe *PipelineExecutor) executeMultiEdit(ctx context.Context, step PipelineS
 			}
 			result.Counts[filePath] = editResult.TotalEdits
 			totalEdits += editResult.TotalEdits
+			if editResult.FinalContent != "" {
+				pe.recordDiff(pipelineCtx, result, filePath, editResult.OriginalContent, editResult.FinalContent)
+			}
 		}
 	}
 
@@ -662,7 +797,10 @@ func (pe *PipelineExecutor) executeCountOccurrences(ctx context.Context, step Pi
 	result.FilesMatched = files
 	result.Counts = make(map[string]int)
 
-	for _, filePath := range files {
+	for i, filePath := range files {
+		if err := stepProgress(ctx, i, len(files)); err != nil {
+			return err
+		}
 		normalizedPath := NormalizePath(filePath)
 
 		content, err := os.ReadFile(normalizedPath)
@@ -756,7 +894,10 @@ func (pe *PipelineExecutor) executeRegexTransform(ctx context.Context, step Pipe
 
 	// Execute transformations
 	transformer := NewRegexTransformer(pe.engine)
-	for _, filePath := range files {
+	for i, filePath := range files {
+		if err := stepProgress(ctx, i, len(files)); err != nil {
+			return err
+		}
 		normalizedPath := NormalizePath(filePath)
 
 		// Read original content so pre/post edit hooks can see full content for regex_transform
@@ -794,11 +935,14 @@ func (pe *PipelineExecutor) executeRegexTransform(ctx context.Context, step Pipe
 			}
 		}
 
+		// Always transform in memory: the pipeline writes the result itself
+		// below (with retries). Letting the transformer write as well left
+		// TransformedContent empty and the write-back blanked the file.
 		config := RegexTransformConfig{
 			FilePath: normalizedPath,
 			Patterns: patterns,
 			Mode:     ModeSequential,
-			DryRun:   dryRun,
+			DryRun:   true,
 		}
 
 		transformResult, err := transformer.Transform(ctx, config)
@@ -815,7 +959,9 @@ func (pe *PipelineExecutor) executeRegexTransform(ctx context.Context, step Pipe
 		}
 
 		if !dryRun {
-			if err := pe.engine.WriteFileContent(ctx, normalizedPath, transformResult.TransformedContent); err != nil {
+			if err := pe.retryOp(ctx, step, result, func() error {
+				return pe.engine.WriteFileContent(ctx, normalizedPath, transformResult.TransformedContent)
+			}); err != nil {
 				return &PipelineStepError{
 					StepID:  step.ID,
 					Action:  "regex_transform",
@@ -827,6 +973,9 @@ func (pe *PipelineExecutor) executeRegexTransform(ctx context.Context, step Pipe
 
 		result.Counts[filePath] = transformResult.TotalReplacements
 		totalEdits += transformResult.TotalReplacements
+		if transformResult.TotalReplacements > 0 {
+			pe.recordDiff(pipelineCtx, result, filePath, originalContent, transformResult.TransformedContent)
+		}
 
 		// Post-edit hook with resulting content
 		if !dryRun && pe.engine != nil && pe.engine.hookManager != nil && pe.engine.hookManager.IsEnabled() {
@@ -849,6 +998,35 @@ func (pe *PipelineExecutor) executeRegexTransform(ctx context.Context, step Pipe
 	return nil
 }
 
+// recordDiff stores a unified diff snippet for filePath when the pipeline is
+// verbose. Snippets are cut at MaxPipelineDiffSnippet and dropped once the
+// pipeline's diff budget is spent; either sets DiffsTruncated.
+func (pe *PipelineExecutor) recordDiff(pipelineCtx *PipelineContext, result *StepResult, filePath, oldContent, newContent string) {
+	if !pipelineCtx.verboseDiffs {
+		return
+	}
+	diff := UnifiedDiff(oldContent, newContent, filePath)
+	if diff == "" {
+		return
+	}
+	if len(diff) > MaxPipelineDiffSnippet {
+		cut := strings.LastIndex(diff[:MaxPipelineDiffSnippet], "\n")
+		if cut <= 0 {
+			cut = MaxPipelineDiffSnippet
+		}
+		diff = diff[:cut] + "\n... (diff truncated)\n"
+		result.DiffsTruncated = true
+	}
+	if !pipelineCtx.takeDiffBudget(len(diff)) {
+		result.DiffsTruncated = true
+		return
+	}
+	if result.Diffs == nil {
+		result.Diffs = make(map[string]string)
+	}
+	result.Diffs[filePath] = diff
+}
+
 // executeCopy copies files to a destination
 func (pe *PipelineExecutor) executeCopy(ctx context.Context, step PipelineStep, pipelineCtx *PipelineContext, result *StepResult, dryRun bool) error {
 	// Get input files
@@ -867,9 +1045,18 @@ func (pe *PipelineExecutor) executeCopy(ctx context.Context, step PipelineStep,
 		}
 	}
 
+	if createDirs, _ := step.Params["create_dirs"].(bool); createDirs && len(files) > 0 {
+		if err := pe.ensureDir(ctx, step, destination, result, dryRun); err != nil {
+			return err
+		}
+	}
+
 	result.FilesMatched = make([]string, 0, len(files))
 
-	for _, filePath := range files {
+	for i, filePath := range files {
+		if err := stepProgress(ctx, i, len(files)); err != nil {
+			return err
+		}
 		normalizedSrc := NormalizePath(filePath)
 
 		// Calculate destination path
@@ -878,10 +1065,15 @@ func (pe *PipelineExecutor) executeCopy(ctx context.Context, step PipelineStep,
 
 		if dryRun {
 			// Just validate paths
+			if err := pe.engine.CheckWritable("copy", normalizedDest); err != nil {
+				return err
+			}
 			result.FilesMatched = append(result.FilesMatched, normalizedDest)
 		} else {
 			// Perform copy
-			if err := pe.engine.CopyFile(ctx, normalizedSrc, normalizedDest); err != nil {
+			if err := pe.retryOp(ctx, step, result, func() error {
+				return pe.engine.CopyFile(ctx, normalizedSrc, normalizedDest)
+			}); err != nil {
 				return &PipelineStepError{
 					StepID:  step.ID,
 					Action:  "copy",
@@ -914,9 +1106,18 @@ func (pe *PipelineExecutor) executeRename(ctx context.Context, step PipelineStep
 		}
 	}
 
+	if createDirs, _ := step.Params["create_dirs"].(bool); createDirs && len(files) > 0 {
+		if err := pe.ensureDir(ctx, step, destination, result, dryRun); err != nil {
+			return err
+		}
+	}
+
 	result.FilesMatched = make([]string, 0, len(files))
 
-	for _, filePath := range files {
+	for i, filePath := range files {
+		if err := stepProgress(ctx, i, len(files)); err != nil {
+			return err
+		}
 		normalizedSrc := NormalizePath(filePath)
 
 		// Calculate destination path
@@ -924,10 +1125,18 @@ func (pe *PipelineExecutor) executeRename(ctx context.Context, step PipelineStep
 		normalizedDest := filepath.Join(destination, fileName)
 
 		if dryRun {
+			if err := pe.engine.CheckRemovable("rename", normalizedSrc); err != nil {
+				return err
+			}
+			if err := pe.engine.CheckWritable("rename", normalizedDest); err != nil {
+				return err
+			}
 			result.FilesMatched = append(result.FilesMatched, normalizedDest)
 		} else {
 			// Perform rename
-			if err := pe.engine.RenameFile(ctx, normalizedSrc, normalizedDest); err != nil {
+			if err := pe.retryOp(ctx, step, result, func() error {
+				return pe.engine.RenameFile(ctx, normalizedSrc, normalizedDest)
+			}); err != nil {
 				return &PipelineStepError{
 					StepID:  step.ID,
 					Action:  "rename",
@@ -942,6 +1151,63 @@ func (pe *PipelineExecutor) executeRename(ctx context.Context, step PipelineStep
 	return nil
 }
 
+// executeBatchRename renames input files in place using the batch rename
+// modes (find_replace, add_prefix, number_files, ...). The whole plan is
+// checked first: any collision fails the step before a single rename.
+func (pe *PipelineExecutor) executeBatchRename(ctx context.Context, step PipelineStep, pipelineCtx *PipelineContext, result *StepResult, dryRun bool) error {
+	files, err := step.getInputFiles(pipelineCtx)
+	if err != nil {
+		return err
+	}
+	req, err := batchRenameParams(step.Params)
+	if err != nil {
+		return &PipelineStepError{StepID: step.ID, Action: "batch_rename", Message: err.Error()}
+	}
+
+	operations, conflicts, err := pe.engine.planBatchRenameList(files, req)
+	if err != nil {
+		return &PipelineStepError{StepID: step.ID, Action: "batch_rename", Param: "mode", Message: "invalid rename plan", Err: err}
+	}
+	if len(conflicts) > 0 {
+		return &PipelineStepError{
+			StepID:     step.ID,
+			Action:     "batch_rename",
+			Message:    fmt.Sprintf("%d naming conflict(s), nothing renamed: %s", len(conflicts), strings.Join(conflicts, "; ")),
+			Suggestion: "run with dry_run=true to review the plan, or narrow the input with a filter step",
+		}
+	}
+
+	result.Renames = make(map[string]string)
+	result.FilesMatched = make([]string, 0, len(operations))
+	for i, op := range operations {
+		if err := stepProgress(ctx, i, len(operations)); err != nil {
+			return err
+		}
+		if op.Skipped {
+			// Unchanged name (or mode not applicable): keep the file in the output
+			result.FilesMatched = append(result.FilesMatched, op.OldPath)
+			continue
+		}
+		if !dryRun {
+			if err := pe.retryOp(ctx, step, result, func() error {
+				return pe.engine.RenameFile(ctx, op.OldPath, op.NewPath)
+			}); err != nil {
+				return &PipelineStepError{
+					StepID:  step.ID,
+					Action:  "batch_rename",
+					Message: fmt.Sprintf("rename failed for %s", op.OldPath),
+					Err:     err,
+				}
+			}
+			pipelineCtx.recordRename(op.OldPath, op.NewPath)
+		}
+		result.Renames[op.OldPath] = op.NewPath
+		result.FilesMatched = append(result.FilesMatched, op.NewPath)
+	}
+
+	return nil
+}
+
 // executeDelete soft-deletes files
 func (pe *PipelineExecutor) executeDelete(ctx context.Context, step PipelineStep, pipelineCtx *PipelineContext, result *StepResult, dryRun bool) error {
 	// Get input files
@@ -952,12 +1218,18 @@ func (pe *PipelineExecutor) executeDelete(ctx context.Context, step PipelineStep
 
 	result.FilesMatched = files
 
-	for _, filePath := range files {
+	for i, filePath := range files {
+		if err := stepProgress(ctx, i, len(files)); err != nil {
+			return err
+		}
 		normalizedPath := NormalizePath(filePath)
 
 		if !dryRun {
 			// Perform soft delete
-			if _, err := pe.engine.SoftDeleteFile(ctx, normalizedPath); err != nil {
+			if err := pe.retryOp(ctx, step, result, func() error {
+				_, err := pe.engine.SoftDeleteFile(ctx, normalizedPath)
+				return err
+			}); err != nil {
 				return &PipelineStepError{
 					StepID:  step.ID,
 					Action:  "delete",
@@ -971,6 +1243,197 @@ func (pe *PipelineExecutor) executeDelete(ctx context.Context, step PipelineStep
 	return nil
 }
 
+// executeWrite creates or overwrites a single file with the given content
+func (pe *PipelineExecutor) executeWrite(ctx context.Context, step PipelineStep, pipelineCtx *PipelineContext, result *StepResult, dryRun bool) error {
+	path, _ := step.Params["path"].(string)
+	content, _ := step.Params["content"].(string)
+	normalizedPath := NormalizePath(path)
+
+	if dryRun {
+		// Report the would-write size; only check access, never touch disk
+		if len(pe.engine.GetAllowedPaths()) > 0 && !pe.engine.IsPathAllowed(normalizedPath) {
+			return pe.engine.AccessDeniedError("write", normalizedPath)
+		}
+		if err := pe.engine.CheckWritable("write", normalizedPath); err != nil {
+			return err
+		}
+	} else if err := pe.retryOp(ctx, step, result, func() error {
+		return pe.engine.WriteFileContent(ctx, normalizedPath, content)
+	}); err != nil {
+		return &PipelineStepError{
+			StepID:  step.ID,
+			Action:  "write",
+			Param:   "path",
+			Message: fmt.Sprintf("write failed for %s", path),
+			Err:     err,
+		}
+	}
+
+	result.FilesMatched = []string{normalizedPath}
+	result.BytesWritten = int64(len(content))
+	return nil
+}
+
+// executeFilter narrows the upstream file list. Predicates combine with AND:
+// include_glob (any), exclude_glob (none), path_contains (any), min_count
+// (upstream Counts), then max_files keeps the first N in upstream order.
+func (pe *PipelineExecutor) executeFilter(ctx context.Context, step PipelineStep, pipelineCtx *PipelineContext, result *StepResult) error {
+	files, err := step.getInputFiles(pipelineCtx)
+	if err != nil {
+		return err
+	}
+
+	includes := stringListParam(step.Params["include_glob"])
+	excludes := stringListParam(step.Params["exclude_glob"])
+	contains := stringListParam(step.Params["path_contains"])
+
+	var upstreamCounts map[string]int
+	minCount := 0
+	if v, ok := step.Params["min_count"]; ok {
+		minCount = toInt(v)
+		if ref, exists := pipelineCtx.GetStepResult(step.InputFrom); exists && !ref.Skipped {
+			if len(ref.Counts) == 0 && len(ref.FilesMatched) > 0 {
+				return &PipelineStepError{
+					StepID:     step.ID,
+					Action:     "filter",
+					Param:      "min_count",
+					Message:    fmt.Sprintf("step '%s' has no counts to filter on", step.InputFrom),
+					Suggestion: "point input_from at a count_occurrences, edit or multi_edit step",
+				}
+			}
+			upstreamCounts = ref.Counts
+		}
+	}
+
+	matchesAny := func(segments []string, patterns []string) bool {
+		for _, p := range patterns {
+			if matchPathPattern(segments, p) != "" {
+				return true
+			}
+		}
+		return false
+	}
+
+	kept := make([]string, 0, len(files))
+	for _, f := range files {
+		segments := strings.Split(filepath.ToSlash(filepath.Clean(f)), "/")
+		if len(includes) > 0 && !matchesAny(segments, includes) {
+			continue
+		}
+		if matchesAny(segments, excludes) {
+			continue
+		}
+		if len(contains) > 0 {
+			found := false
+			for _, c := range contains {
+				if strings.Contains(filepath.ToSlash(f), filepath.ToSlash(c)) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				continue
+			}
+		}
+		if upstreamCounts != nil && upstreamCounts[f] < minCount {
+			continue
+		}
+		kept = append(kept, f)
+	}
+
+	if v, ok := step.Params["max_files"]; ok {
+		if maxFiles := toInt(v); maxFiles >= 0 && len(kept) > maxFiles {
+			kept = kept[:maxFiles]
+		}
+	}
+
+	result.FilesMatched = kept
+	// Carry upstream counts for the kept files so conditions and templates
+	// downstream see the narrowed totals
+	if ref, exists := pipelineCtx.GetStepResult(step.InputFrom); exists && len(ref.Counts) > 0 {
+		result.Counts = make(map[string]int, len(kept))
+		for _, f := range kept {
+			result.Counts[f] = ref.Counts[f]
+		}
+	}
+	return nil
+}
+
+// stringListParam accepts a string or an array of strings
+func stringListParam(v interface{}) []string {
+	switch val := v.(type) {
+	case string:
+		if val == "" {
+			return nil
+		}
+		return []string{val}
+	case []string:
+		return val
+	case []interface{}:
+		out := make([]string, 0, len(val))
+		for _, item := range val {
+			if str, ok := item.(string); ok && str != "" {
+				out = append(out, str)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// executeCreateDir creates a directory (with parents). An existing directory
+// is not an error, so the step can be used to ensure a path exists.
+func (pe *PipelineExecutor) executeCreateDir(ctx context.Context, step PipelineStep, pipelineCtx *PipelineContext, result *StepResult, dryRun bool) error {
+	path, _ := step.Params["path"].(string)
+	normalizedPath := NormalizePath(path)
+	if err := pe.ensureDir(ctx, step, normalizedPath, result, dryRun); err != nil {
+		return err
+	}
+	result.FilesMatched = []string{normalizedPath}
+	return nil
+}
+
+// ensureDir makes sure dir exists, creating it through engine.CreateDirectory
+// (access control + pre/post-create hooks). Created directories — or, in
+// dry_run, the ones that would be created — are appended to DirsCreated.
+func (pe *PipelineExecutor) ensureDir(ctx context.Context, step PipelineStep, dir string, result *StepResult, dryRun bool) error {
+	dir = NormalizePath(dir)
+	if len(pe.engine.GetAllowedPaths()) > 0 && !pe.engine.IsPathAllowed(dir) {
+		return pe.engine.AccessDeniedError(step.Action, dir)
+	}
+
+	info, err := os.Stat(dir)
+	if err == nil {
+		if !info.IsDir() {
+			return &PipelineStepError{
+				StepID:  step.ID,
+				Action:  step.Action,
+				Message: fmt.Sprintf("%s exists and is not a directory", dir),
+			}
+		}
+		return nil
+	}
+
+	if dryRun {
+		if err := pe.engine.CheckWritable(step.Action, dir); err != nil {
+			return err
+		}
+	} else {
+		if err := pe.retryOp(ctx, step, result, func() error {
+			return pe.engine.CreateDirectory(ctx, dir)
+		}); err != nil {
+			return &PipelineStepError{
+				StepID:  step.ID,
+				Action:  step.Action,
+				Message: fmt.Sprintf("could not create directory %s", dir),
+				Err:     err,
+			}
+		}
+	}
+	result.DirsCreated = append(result.DirsCreated, dir)
+	return nil
+}
+
 // hasDestructiveSteps checks if any steps modify/delete files
 func (pe *PipelineExecutor) hasDestructiveSteps(steps []PipelineStep) bool {
 	destructiveActions := map[string]bool{
@@ -979,6 +1442,8 @@ func (pe *PipelineExecutor) hasDestructiveSteps(steps []PipelineStep) bool {
 		"regex_transform": true,
 		"delete":          true,
 		"rename":          true,
+		"batch_rename":    true,
+		"write":           true, // only existing targets are backed up
 	}
 
 	for _, step := range steps {
@@ -995,6 +1460,10 @@ func (pe *PipelineExecutor) estimateAffectedFiles(ctx context.Context, request P
 
 	// Execute search steps to discover files
 	for _, step := range request.Steps {
+		// Substitute ${var} placeholders so backups cover the real paths
+		if params, err := ResolveVariables(step.Params, pipelineCtx); err == nil {
+			step.Params = params
+		}
 		if step.Action == "search" {
 			// Execute search in read-only mode
 			result := StepResult{StepID: step.ID, Action: "search"}
@@ -1006,7 +1475,14 @@ func (pe *PipelineExecutor) estimateAffectedFiles(ctx context.Context, request P
 			}
 			// Store result for later steps
 			pipelineCtx.SetStepResult(step.ID, &result)
-		} else if step.Action == "edit" || step.Action == "multi_edit" || step.Action == "regex_transform" || step.Action == "delete" {
+		} else if step.Action == "write" {
+			// Overwriting an existing file is destructive; creating one is not
+			if path, _ := step.Params["path"].(string); path != "" {
+				if info, err := os.Stat(NormalizePath(path)); err == nil && !info.IsDir() {
+					affectedMap[NormalizePath(path)] = true
+				}
+			}
+		} else if step.Action == "edit" || step.Action == "multi_edit" || step.Action == "regex_transform" || step.Action == "delete" || step.Action == "batch_rename" {
 			// Get files from input_from or params
 			files, err := step.getInputFiles(pipelineCtx)
 			if err != nil {
@@ -1065,16 +1541,23 @@ func (pe *PipelineExecutor) assessPipelineRisk(filesAffected []string, totalEdit
 	return "LOW"
 }
 
-// rollback restores files from backup
+// rollback restores files from backup, first undoing batch_rename renames
+// (newest first) so restored content lands on the original names.
 //
 // Note on hooks: We now attempt to fire post-edit / post-write hooks on the
 // restored files so that user hooks (formatting, logging, etc.) have a chance
 // to react to the rollback. This is best-effort.
-func (pe *PipelineExecutor) rollback(ctx context.Context, backupID string) error {
+func (pe *PipelineExecutor) rollback(ctx context.Context, backupID string, pipelineCtx *PipelineContext) error {
 	if backupID == "" {
 		return fmt.Errorf("no backup ID provided")
 	}
 
+	for _, r := range pipelineCtx.takeRenames() {
+		if err := pe.engine.RenameFile(ctx, r[1], r[0]); err != nil {
+			slog.Warn("pipeline rollback: could not undo rename", "from", r[1], "to", r[0], "error", err)
+		}
+	}
+
 	restoredFiles, _, err := pe.engine.backupManager.RestoreBackup(backupID, "", false)
 	if err != nil {
 		return err
@@ -1103,19 +1586,33 @@ func (pe *PipelineExecutor) rollback(ctx context.Context, backupID string) error
 	return nil
 }
 
-// performSmartSearchInternal performs search and returns structured matches
-func (pe *PipelineExecutor) performSmartSearchInternal(ctx context.Context, path string, pattern string, includeContent bool, fileTypes []string) ([]PipelineSearchMatch, error) {
+// pipelineSearchStats counts what a pipeline search left out
+type pipelineSearchStats struct {
+	skipped map[string]int // reason -> files (binary, too_large, unreadable) or dirs (excluded_dir)
+	limited bool           // stopped at MaxSearchResults
+}
+
+// performSmartSearchInternal performs search and returns structured matches.
+// It follows the same rules as search_files: searchSkipDirs are pruned,
+// binary files (isTextFile) and files over searchMaxFileSize are skipped,
+// and the walk stops at the engine's MaxSearchResults.
+func (pe *PipelineExecutor) performSmartSearchInternal(ctx context.Context, path string, pattern string, includeContent bool, fileTypes []string) ([]PipelineSearchMatch, *pipelineSearchStats, error) {
 	// Normalize path
 	normalizedPath := NormalizePath(path)
 
 	// Check access
-	if len(pe.engine.config.AllowedPaths) > 0 {
+	if len(pe.engine.GetAllowedPaths()) > 0 {
 		if !pe.engine.IsPathAllowed(normalizedPath) {
-			return nil, pe.engine.AccessDeniedError("search", normalizedPath)
+			return nil, nil, pe.engine.AccessDeniedError("search", normalizedPath)
 		}
 	}
 
 	matches := []PipelineSearchMatch{}
+	stats := &pipelineSearchStats{skipped: make(map[string]int)}
+	maxResults := pe.engine.config.MaxSearchResults
+	if maxResults <= 0 {
+		maxResults = MaxSearchResults
+	}
 
 	// Check if pattern is a glob (contains *, ?, [) - glob patterns should use literal matching
 	// because users expect "Reports.*" to mean "Reports." followed by anything, not regex
@@ -1131,13 +1628,20 @@ func (pe *PipelineExecutor) performSmartSearchInternal(ctx context.Context, path
 	}
 
 	// Walk directory
-	err := filepath.Walk(normalizedPath, func(filePath string, info os.FileInfo, walkErr error) error {
+	err := filepath.WalkDir(normalizedPath, func(filePath string, d os.DirEntry, walkErr error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
 		if walkErr != nil {
 			return nil // Skip errors
 		}
 
-		// Skip directories
-		if info.IsDir() {
+		// Prune VCS internals, dependency caches and build output
+		if d.IsDir() {
+			if filePath != normalizedPath && searchSkipDirs[d.Name()] {
+				stats.skipped["excluded_dir"]++
+				return filepath.SkipDir
+			}
 			return nil
 		}
 
@@ -1155,39 +1659,56 @@ func (pe *PipelineExecutor) performSmartSearchInternal(ctx context.Context, path
 			}
 		}
 
+		if info, infoErr := d.Info(); infoErr != nil {
+			stats.skipped["unreadable"]++
+			return nil
+		} else if info.Size() > searchMaxFileSize {
+			stats.skipped["too_large"]++
+			return nil
+		}
+		if !pe.engine.isTextFile(filePath) {
+			stats.skipped["binary"]++
+			return nil
+		}
+
 		// Read file for content search
 		content, readErr := os.ReadFile(filePath)
 		if readErr != nil {
-			return nil // Skip unreadable files
+			stats.skipped["unreadable"]++
+			return nil
 		}
 
 		// Check if pattern matches
-		contentMatched := false
+		count := 0
 		if regexPattern != nil {
-			contentMatched = regexPattern.Match(content)
+			count = len(regexPattern.FindAllIndex(content, -1))
 		} else {
-			contentMatched = strings.Contains(string(content), pattern)
+			count = strings.Count(string(content), pattern)
 		}
 
-		if contentMatched {
+		if count > 0 {
 			match := PipelineSearchMatch{
 				FilePath: filePath,
-				Count:    strings.Count(string(content), pattern),
+				Count:    count,
 			}
 			if includeContent {
 				match.Content = string(content)
 			}
 			matches = append(matches, match)
+			if len(matches) >= maxResults {
+				stats.limited = true
+				return errWalkStop
+			}
 		}
 
 		return nil
 	})
 
-	if err != nil {
-		return nil, fmt.Errorf("search walk failed: %w", err)
+	if err != nil && err != errWalkStop {
+		return nil, nil, fmt.Errorf("search walk failed: %w", err)
 	}
 
-	return matches, nil
+	return matches, stats, nil
 }
 
 // executeParallelPath handles the parallel execution branch from Execute()
@@ -1239,8 +1760,8 @@ func (pe *PipelineExecutor) executeParallelPath(ctx context.Context, request Pip
 		TotalDuration:    time.Since(startTime),
 	}
 
-	if err != nil && request.StopOnError && backupID != "" && !request.DryRun {
-		if rollbackErr := pe.rollback(ctx, backupID); rollbackErr == nil {
+	if err != nil && (request.StopOnError || ctx.Err() != nil) && backupID != "" && !request.DryRun {
+		if rollbackErr := pe.rollback(context.WithoutCancel(ctx), backupID, pipelineCtx); rollbackErr == nil {
 			pResult.RollbackPerformed = true
 		}
 	}
@@ -1281,7 +1802,7 @@ func (pe *PipelineExecutor) ExecuteParallel(ctx context.Context, request Pipelin
 
 			if stepErr != nil || !stepResult.Success {
 				allSuccess = false
-				if request.StopOnError && !stepResult.Skipped {
+				if (request.StopOnError || ctx.Err() != nil) && !stepResult.Skipped {
 					return nil, results[:idx+1], stepErr
 				}
 			}
@@ -1330,8 +1851,8 @@ type indexedResult struct {
 				}
 			}
 
-			// Check stop_on_error after level completes
-			if !allSuccess && request.StopOnError {
+			// Check stop_on_error (or cancellation) after level completes
+			if !allSuccess && (request.StopOnError || ctx.Err() != nil) {
 				return nil, results[:], fmt.Errorf("pipeline failed during parallel level")
 			}
 		}
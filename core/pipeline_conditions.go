@@ -1,9 +1,11 @@
 package core
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 )
 
 // StepCondition defines when a step should execute
@@ -12,6 +14,52 @@ type StepCondition struct {
 	StepRef string `json:"step_ref,omitempty"` // Reference to a prior step ID
 	Value   string `json:"value,omitempty"`    // Comparison value (for count_gt, count_lt, count_eq)
 	Path    string `json:"path,omitempty"`     // File path (for file_exists, file_not_exists)
+	Expr    string `json:"expr,omitempty"`     // Expression over prior step results (for expr)
+
+	parsed *ConditionExpr // Internal: parsed Expr, set by ValidateCondition
+}
+
+// UnmarshalJSON accepts the condition either as an object or as a bare
+// expression string: "condition": "steps.count.total > 0". An object with
+// expr and no type is treated as an expression too.
+func (sc *StepCondition) UnmarshalJSON(data []byte) error {
+	var expr string
+	if err := json.Unmarshal(data, &expr); err == nil {
+		*sc = StepCondition{Type: CondExpr, Expr: expr}
+		return nil
+	}
+
+	type stepConditionAlias StepCondition
+	var raw stepConditionAlias
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*sc = StepCondition(raw)
+	if sc.Type == "" && sc.Expr != "" {
+		sc.Type = CondExpr
+	}
+	return nil
+}
+
+// References returns the IDs of the steps this condition reads
+func (sc *StepCondition) References() []string {
+	if sc == nil {
+		return nil
+	}
+	if sc.Type == CondExpr {
+		if sc.parsed == nil {
+			parsed, err := ParseConditionExpr(sc.Expr)
+			if err != nil {
+				return nil
+			}
+			sc.parsed = parsed
+		}
+		return sc.parsed.Refs()
+	}
+	if sc.StepRef != "" {
+		return []string{sc.StepRef}
+	}
+	return nil
 }
 
 // Supported condition types
@@ -25,6 +73,7 @@ type StepCondition struct {
 	CondFileNotExists = "file_not_exists"
 	CondStepSucceeded = "step_succeeded"
 	CondStepFailed    = "step_failed"
+	CondExpr          = "expr"
 )
 
 var validConditionTypes = map[string]bool{
@@ -37,6 +86,7 @@ type StepCondition struct {
 	CondFileNotExists: true,
 	CondStepSucceeded: true,
 	CondStepFailed:    true,
+	CondExpr:          true,
 }
 
 // ValidateCondition validates a step condition's fields
@@ -65,7 +115,7 @@ func ValidateCondition(cond *StepCondition, stepID string, priorStepIDs map[stri
 				Message: fmt.Sprintf("condition type '%s' requires step_ref", cond.Type),
 			}
 		}
-		if _, exists := priorStepIDs[cond.StepRef]; !exists {
+		if !isPriorStep(cond.StepRef, stepID, priorStepIDs) {
 			return &PipelineStepError{
 				StepID:     stepID,
 				Action:     "condition",
@@ -76,6 +126,41 @@ func ValidateCondition(cond *StepCondition, stepID string, priorStepIDs map[stri
 		}
 	}
 
+	// Expressions must parse and may only read steps defined before this one
+	if cond.Type == CondExpr {
+		if strings.TrimSpace(cond.Expr) == "" {
+			return &PipelineStepError{
+				StepID:  stepID,
+				Action:  "condition",
+				Param:   "expr",
+				Message: "condition type 'expr' requires expr",
+			}
+		}
+		parsed, err := ParseConditionExpr(cond.Expr)
+		if err != nil {
+			return &PipelineStepError{
+				StepID:     stepID,
+				Action:     "condition",
+				Param:      "expr",
+				Message:    fmt.Sprintf("invalid condition expression '%s'", cond.Expr),
+				Err:        err,
+				Suggestion: "example: steps.count.total > 0 && len(steps.find.files) < 20",
+			}
+		}
+		for _, ref := range parsed.Refs() {
+			if !isPriorStep(ref, stepID, priorStepIDs) {
+				return &PipelineStepError{
+					StepID:     stepID,
+					Action:     "condition",
+					Param:      "expr",
+					Message:    fmt.Sprintf("condition references step '%s' which is not defined before this step", ref),
+					Suggestion: "conditions may only read results of earlier steps",
+				}
+			}
+		}
+		cond.parsed = parsed
+	}
+
 	// Conditions that require value
 	switch cond.Type {
 	case CondCountGT, CondCountLT, CondCountEQ:
@@ -113,6 +198,19 @@ func ValidateCondition(cond *StepCondition, stepID string, priorStepIDs map[stri
 	return nil
 }
 
+// isPriorStep reports whether ref names a step defined before stepID.
+// priorStepIDs holds the steps validated so far, including stepID itself.
+func isPriorStep(ref, stepID string, priorStepIDs map[string]int) bool {
+	refIdx, exists := priorStepIDs[ref]
+	if !exists {
+		return false
+	}
+	if selfIdx, ok := priorStepIDs[stepID]; ok && refIdx >= selfIdx {
+		return false
+	}
+	return true
+}
+
 // EvaluateCondition evaluates whether a step should run based on its condition
 // Returns (shouldRun, reason)
 func EvaluateCondition(cond *StepCondition, pCtx *PipelineContext, engine *UltraFastEngine) (bool, string) {
@@ -153,7 +251,7 @@ func EvaluateCondition(cond *StepCondition, pCtx *PipelineContext, engine *Ultra
 	case CondFileExists:
 		path := NormalizePath(cond.Path)
 		// Security: respect --allowed-paths
-		if engine != nil && len(engine.config.AllowedPaths) > 0 {
+		if engine != nil && len(engine.GetAllowedPaths()) > 0 {
 			if !engine.IsPathAllowed(path) {
 				return false, fmt.Sprintf("access denied checking existence of '%s'", cond.Path)
 			}
@@ -166,7 +264,7 @@ func EvaluateCondition(cond *StepCondition, pCtx *PipelineContext, engine *Ultra
 	case CondFileNotExists:
 		path := NormalizePath(cond.Path)
 		// Security: respect --allowed-paths
-		if engine != nil && len(engine.config.AllowedPaths) > 0 {
+		if engine != nil && len(engine.GetAllowedPaths()) > 0 {
 			if !engine.IsPathAllowed(path) {
 				return false, fmt.Sprintf("access denied checking existence of '%s'", cond.Path)
 			}
@@ -195,6 +293,23 @@ func EvaluateCondition(cond *StepCondition, pCtx *PipelineContext, engine *Ultra
 			return true, ""
 		}
 		return false, fmt.Sprintf("step '%s' succeeded", cond.StepRef)
+
+	case CondExpr:
+		parsed := cond.parsed
+		if parsed == nil {
+			var err error
+			if parsed, err = ParseConditionExpr(cond.Expr); err != nil {
+				return false, fmt.Sprintf("invalid condition expression: %v", err)
+			}
+		}
+		ok, err := parsed.Eval(pCtx)
+		if err != nil {
+			return false, fmt.Sprintf("condition '%s' could not be evaluated: %v", cond.Expr, err)
+		}
+		if ok {
+			return true, ""
+		}
+		return false, fmt.Sprintf("condition '%s' is false", cond.Expr)
 	}
 
 	return false, fmt.Sprintf("unknown condition type '%s'", cond.Type)
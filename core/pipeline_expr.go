@@ -0,0 +1,469 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Condition expressions
+//
+// A step condition may be a small boolean expression over prior step results
+// instead of one of the fixed condition types:
+//
+//	"condition": "steps.count.total > 0"
+//	"condition": "len(steps.find.files) < 20 && steps.find.risk != 'HIGH'"
+//
+// Grammar (lowest to highest precedence):
+//
+//	expr    := and ('||' and)*
+//	and     := unary ('&&' unary)*
+//	unary   := '!' unary | compare
+//	compare := operand (('=='|'!='|'>'|'>='|'<'|'<=') operand)?
+//	operand := number | 'string' | "string" | true | false
+//	         | steps.<id>.<field> | len(operand) | '(' expr ')'
+//
+// Fields: total (alias count), files, files_count, edits, success, skipped,
+// risk, error. A skipped step reads as empty (total 0, no files).
+
+// exprStepFields lists the fields readable through steps.<id>.<field>
+var exprStepFields = map[string]bool{
+	"total":       true,
+	"count":       true,
+	"files":       true,
+	"files_count": true,
+	"edits":       true,
+	"success":     true,
+	"skipped":     true,
+	"risk":        true,
+	"error":       true,
+}
+
+// exprNode is one node of a parsed condition expression
+type exprNode interface {
+	eval(pCtx *PipelineContext) (interface{}, error)
+}
+
+type exprLiteral struct{ value interface{} }
+
+type exprStepRef struct{ stepID, field string }
+
+type exprLen struct{ arg exprNode }
+
+type exprNot struct{ arg exprNode }
+
+type exprBinary struct {
+	op          string
+	left, right exprNode
+}
+
+// ConditionExpr is a parsed condition expression and the steps it reads
+type ConditionExpr struct {
+	root exprNode
+	refs []string
+}
+
+// ParseConditionExpr parses a condition expression. Refs lists referenced step
+// IDs in order of first appearance.
+func ParseConditionExpr(src string) (*ConditionExpr, error) {
+	tokens, err := tokenizeExpr(src)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty expression")
+	}
+	p := &exprParser{tokens: tokens, seen: make(map[string]bool)}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected '%s' at position %d", p.tokens[p.pos].text, p.tokens[p.pos].pos)
+	}
+	return &ConditionExpr{root: root, refs: p.refs}, nil
+}
+
+// Refs returns the step IDs the expression reads
+func (ce *ConditionExpr) Refs() []string {
+	return ce.refs
+}
+
+// Eval evaluates the expression against prior step results. Non-boolean
+// results use truthiness: non-zero numbers, non-empty strings and lists.
+func (ce *ConditionExpr) Eval(pCtx *PipelineContext) (bool, error) {
+	v, err := ce.root.eval(pCtx)
+	if err != nil {
+		return false, err
+	}
+	return exprTruthy(v), nil
+}
+
+// --- tokenizer ---
+
+type exprToken struct {
+	kind string // num, str, ident, op
+	text string
+	pos  int
+}
+
+func tokenizeExpr(src string) ([]exprToken, error) {
+	var tokens []exprToken
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c >= '0' && c <= '9':
+			start := i
+			for i < len(src) && src[i] >= '0' && src[i] <= '9' {
+				i++
+			}
+			tokens = append(tokens, exprToken{"num", src[start:i], start})
+		case c == '\'' || c == '"':
+			start := i
+			end := strings.IndexByte(src[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string at position %d", start)
+			}
+			tokens = append(tokens, exprToken{"str", src[i+1 : i+1+end], start})
+			i += end + 2
+		case isExprIdentChar(c) || c == '.':
+			start := i
+			for i < len(src) && (isExprIdentChar(src[i]) || src[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, exprToken{"ident", src[start:i], start})
+		default:
+			if i+1 < len(src) {
+				two := src[i : i+2]
+				switch two {
+				case "==", "!=", ">=", "<=", "&&", "||":
+					tokens = append(tokens, exprToken{"op", two, i})
+					i += 2
+					continue
+				}
+			}
+			switch c {
+			case '>', '<', '!', '(', ')':
+				tokens = append(tokens, exprToken{"op", string(c), i})
+				i++
+			default:
+				return nil, fmt.Errorf("unexpected character '%c' at position %d", c, i)
+			}
+		}
+	}
+	return tokens, nil
+}
+
+func isExprIdentChar(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_' || c == '-'
+}
+
+// --- parser ---
+
+type exprParser struct {
+	tokens []exprToken
+	pos    int
+	refs   []string
+	seen   map[string]bool
+}
+
+func (p *exprParser) peekOp(ops ...string) string {
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != "op" {
+		return ""
+	}
+	for _, op := range ops {
+		if p.tokens[p.pos].text == op {
+			return op
+		}
+	}
+	return ""
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekOp("||") != "" {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &exprBinary{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekOp("&&") != "" {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &exprBinary{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if p.peekOp("!") != "" {
+		p.pos++
+		arg, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &exprNot{arg: arg}, nil
+	}
+	return p.parseCompare()
+}
+
+func (p *exprParser) parseCompare() (exprNode, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if op := p.peekOp("==", "!=", ">=", "<=", ">", "<"); op != "" {
+		p.pos++
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return &exprBinary{op: op, left: left, right: right}, nil
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseOperand() (exprNode, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	tok := p.tokens[p.pos]
+	p.pos++
+
+	switch tok.kind {
+	case "num":
+		n, err := strconv.Atoi(tok.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number '%s'", tok.text)
+		}
+		return &exprLiteral{value: n}, nil
+	case "str":
+		return &exprLiteral{value: tok.text}, nil
+	case "op":
+		if tok.text == "(" {
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if p.peekOp(")") == "" {
+				return nil, fmt.Errorf("missing ')' for '(' at position %d", tok.pos)
+			}
+			p.pos++
+			return inner, nil
+		}
+		return nil, fmt.Errorf("unexpected '%s' at position %d", tok.text, tok.pos)
+	}
+
+	// ident: true/false, len(...), or steps.<id>.<field>
+	switch tok.text {
+	case "true":
+		return &exprLiteral{value: true}, nil
+	case "false":
+		return &exprLiteral{value: false}, nil
+	case "len":
+		if p.peekOp("(") == "" {
+			return nil, fmt.Errorf("len must be called as len(...)")
+		}
+		p.pos++
+		arg, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if p.peekOp(")") == "" {
+			return nil, fmt.Errorf("missing ')' after len argument")
+		}
+		p.pos++
+		return &exprLen{arg: arg}, nil
+	}
+
+	parts := strings.Split(tok.text, ".")
+	if len(parts) != 3 || parts[0] != "steps" || parts[1] == "" {
+		return nil, fmt.Errorf("unknown identifier '%s' (expected steps.<id>.<field>)", tok.text)
+	}
+	if !exprStepFields[parts[2]] {
+		return nil, fmt.Errorf("unknown field '%s' in '%s' (supported: total, files, files_count, edits, success, skipped, risk, error)", parts[2], tok.text)
+	}
+	if !p.seen[parts[1]] {
+		p.seen[parts[1]] = true
+		p.refs = append(p.refs, parts[1])
+	}
+	return &exprStepRef{stepID: parts[1], field: parts[2]}, nil
+}
+
+// --- evaluation ---
+
+func (n *exprLiteral) eval(*PipelineContext) (interface{}, error) {
+	return n.value, nil
+}
+
+func (n *exprStepRef) eval(pCtx *PipelineContext) (interface{}, error) {
+	ref, exists := pCtx.GetStepResult(n.stepID)
+	if !exists {
+		return nil, fmt.Errorf("referenced step '%s' not found", n.stepID)
+	}
+	switch n.field {
+	case "total", "count":
+		total := 0
+		for _, c := range ref.Counts {
+			total += c
+		}
+		if len(ref.Counts) == 0 {
+			total = len(ref.FilesMatched)
+		}
+		return total, nil
+	case "files":
+		return ref.FilesMatched, nil
+	case "files_count":
+		return len(ref.FilesMatched), nil
+	case "edits":
+		return ref.EditsApplied, nil
+	case "success":
+		return ref.Success, nil
+	case "skipped":
+		return ref.Skipped, nil
+	case "risk":
+		return ref.RiskLevel, nil
+	case "error":
+		return ref.Error, nil
+	}
+	return nil, fmt.Errorf("unknown field '%s'", n.field)
+}
+
+func (n *exprLen) eval(pCtx *PipelineContext) (interface{}, error) {
+	v, err := n.arg.eval(pCtx)
+	if err != nil {
+		return nil, err
+	}
+	switch val := v.(type) {
+	case []string:
+		return len(val), nil
+	case string:
+		return len(val), nil
+	}
+	return nil, fmt.Errorf("len() needs a list or string, got %T", v)
+}
+
+func (n *exprNot) eval(pCtx *PipelineContext) (interface{}, error) {
+	v, err := n.arg.eval(pCtx)
+	if err != nil {
+		return nil, err
+	}
+	return !exprTruthy(v), nil
+}
+
+func (n *exprBinary) eval(pCtx *PipelineContext) (interface{}, error) {
+	left, err := n.left.eval(pCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Short-circuit logical operators
+	switch n.op {
+	case "&&":
+		if !exprTruthy(left) {
+			return false, nil
+		}
+		right, err := n.right.eval(pCtx)
+		if err != nil {
+			return nil, err
+		}
+		return exprTruthy(right), nil
+	case "||":
+		if exprTruthy(left) {
+			return true, nil
+		}
+		right, err := n.right.eval(pCtx)
+		if err != nil {
+			return nil, err
+		}
+		return exprTruthy(right), nil
+	}
+
+	right, err := n.right.eval(pCtx)
+	if err != nil {
+		return nil, err
+	}
+	return exprCompare(n.op, left, right)
+}
+
+func exprCompare(op string, left, right interface{}) (bool, error) {
+	switch l := left.(type) {
+	case int:
+		if r, ok := right.(int); ok {
+			switch op {
+			case "==":
+				return l == r, nil
+			case "!=":
+				return l != r, nil
+			case ">":
+				return l > r, nil
+			case ">=":
+				return l >= r, nil
+			case "<":
+				return l < r, nil
+			case "<=":
+				return l <= r, nil
+			}
+		}
+	case string:
+		if r, ok := right.(string); ok {
+			switch op {
+			case "==":
+				return l == r, nil
+			case "!=":
+				return l != r, nil
+			case ">":
+				return l > r, nil
+			case ">=":
+				return l >= r, nil
+			case "<":
+				return l < r, nil
+			case "<=":
+				return l <= r, nil
+			}
+		}
+	case bool:
+		if r, ok := right.(bool); ok {
+			switch op {
+			case "==":
+				return l == r, nil
+			case "!=":
+				return l != r, nil
+			}
+		}
+	}
+	return false, fmt.Errorf("cannot compare %T %s %T", left, op, right)
+}
+
+func exprTruthy(v interface{}) bool {
+	switch val := v.(type) {
+	case bool:
+		return val
+	case int:
+		return val != 0
+	case string:
+		return val != ""
+	case []string:
+		return len(val) > 0
+	}
+	return false
+}
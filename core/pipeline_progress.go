@@ -0,0 +1,263 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultPipelineProgressEvery is how many files a step processes between
+// progress callbacks when PipelineExecutor.ProgressEvery is not set.
+const DefaultPipelineProgressEvery = 10
+
+// maxRecentPipelineRuns bounds the finished runs kept for pipeline(action:"status")
+const maxRecentPipelineRuns = 20
+
+// Pipeline run states
+const (
+	PipelineRunning   = "running"
+	PipelineCompleted = "completed"
+	PipelineFailed    = "failed"
+	PipelineCancelled = "cancelled"
+)
+
+// PipelineStatus is a snapshot of a running or recently finished pipeline
+type PipelineStatus struct {
+	ID             string        `json:"id"`
+	Name           string        `json:"name"`
+	Status         string        `json:"status"`
+	DryRun         bool          `json:"dry_run,omitempty"`
+	StepID         string        `json:"step_id,omitempty"` // current (or last) step
+	Action         string        `json:"action,omitempty"`
+	StepIndex      int           `json:"step_index"` // 0-based index of StepID in the request
+	TotalSteps     int           `json:"total_steps"`
+	CompletedSteps int           `json:"completed_steps"`
+	FilesProcessed int           `json:"files_processed"` // within the current step
+	FilesTotal     int           `json:"files_total"`
+	StartedAt      time.Time     `json:"started_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+	Elapsed        time.Duration `json:"elapsed"`
+	Error          string        `json:"error,omitempty"`
+}
+
+// pipelineRun tracks one Execute call. Updates come from executeStep and the
+// per-file loops (possibly from parallel steps), so all fields are guarded.
+type pipelineRun struct {
+	mu        sync.Mutex
+	status    PipelineStatus
+	stepIndex map[string]int
+	executor  *PipelineExecutor
+}
+
+// pipelineRunRegistry keeps running pipelines and the most recent finished ones
+type pipelineRunRegistry struct {
+	mu     sync.Mutex
+	nextID atomic.Int64
+	runs   map[string]*pipelineRun
+	order  []string // finished run IDs, oldest first
+}
+
+func newPipelineRunRegistry() *pipelineRunRegistry {
+	return &pipelineRunRegistry{runs: make(map[string]*pipelineRun)}
+}
+
+// start registers a new run. A nil registry (engine built without
+// NewUltraFastEngine) still returns a usable, untracked run.
+func (r *pipelineRunRegistry) start(pe *PipelineExecutor, request PipelineRequest) *pipelineRun {
+	id := "pl_0"
+	if r != nil {
+		id = fmt.Sprintf("pl_%d", r.nextID.Add(1))
+	}
+	now := time.Now()
+	run := &pipelineRun{
+		status: PipelineStatus{
+			ID:         id,
+			Name:       request.Name,
+			Status:     PipelineRunning,
+			DryRun:     request.DryRun,
+			TotalSteps: len(request.Steps),
+			StartedAt:  now,
+			UpdatedAt:  now,
+		},
+		stepIndex: make(map[string]int, len(request.Steps)),
+		executor:  pe,
+	}
+	for i, step := range request.Steps {
+		run.stepIndex[step.ID] = i
+	}
+
+	if r != nil {
+		r.mu.Lock()
+		r.runs[run.status.ID] = run
+		r.mu.Unlock()
+	}
+	return run
+}
+
+// finish moves run to the bounded list of finished runs
+func (r *pipelineRunRegistry) finish(run *pipelineRun) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.order = append(r.order, run.status.ID)
+	for len(r.order) > maxRecentPipelineRuns {
+		delete(r.runs, r.order[0])
+		r.order = r.order[1:]
+	}
+}
+
+// list returns snapshots, running pipelines first, then newest first
+func (r *pipelineRunRegistry) list() []PipelineStatus {
+	r.mu.Lock()
+	runs := make([]*pipelineRun, 0, len(r.runs))
+	for _, run := range r.runs {
+		runs = append(runs, run)
+	}
+	r.mu.Unlock()
+
+	out := make([]PipelineStatus, 0, len(runs))
+	for _, run := range runs {
+		out = append(out, run.snapshot())
+	}
+	sort.Slice(out, func(i, j int) bool {
+		ri, rj := out[i].Status == PipelineRunning, out[j].Status == PipelineRunning
+		if ri != rj {
+			return ri
+		}
+		return out[i].StartedAt.After(out[j].StartedAt)
+	})
+	return out
+}
+
+func (run *pipelineRun) snapshot() PipelineStatus {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	s := run.status
+	if s.Status == PipelineRunning {
+		s.Elapsed = time.Since(s.StartedAt)
+	}
+	return s
+}
+
+// beginStep marks step as current and notifies
+func (run *pipelineRun) beginStep(step PipelineStep) {
+	run.mu.Lock()
+	run.status.StepID = step.ID
+	run.status.Action = step.Action
+	run.status.StepIndex = run.stepIndex[step.ID]
+	run.status.FilesProcessed = 0
+	run.status.FilesTotal = 0
+	run.status.UpdatedAt = time.Now()
+	run.mu.Unlock()
+	run.notify()
+}
+
+// endStep records a finished step and notifies
+func (run *pipelineRun) endStep(result StepResult) {
+	run.mu.Lock()
+	if result.Success {
+		run.status.CompletedSteps++
+	}
+	if run.status.StepID == result.StepID && run.status.FilesTotal > 0 {
+		run.status.FilesProcessed = run.status.FilesTotal
+	}
+	run.status.UpdatedAt = time.Now()
+	run.mu.Unlock()
+	run.notify()
+}
+
+// fileProgress records done/total files for stepID; callbacks fire every
+// ProgressEvery files (endStep reports the final count)
+func (run *pipelineRun) fileProgress(stepID string, done, total int) {
+	run.mu.Lock()
+	run.status.StepID = stepID
+	run.status.StepIndex = run.stepIndex[stepID]
+	run.status.FilesProcessed = done
+	run.status.FilesTotal = total
+	run.status.UpdatedAt = time.Now()
+	run.mu.Unlock()
+
+	every := run.executor.ProgressEvery
+	if every <= 0 {
+		every = DefaultPipelineProgressEvery
+	}
+	if done > 0 && done%every == 0 {
+		run.notify()
+	}
+}
+
+func (run *pipelineRun) finish(result *PipelineResult, err error, cancelled bool) {
+	run.mu.Lock()
+	run.status.Elapsed = time.Since(run.status.StartedAt)
+	run.status.UpdatedAt = time.Now()
+	switch {
+	case cancelled:
+		run.status.Status = PipelineCancelled
+	case err != nil || result == nil || !result.Success:
+		run.status.Status = PipelineFailed
+	default:
+		run.status.Status = PipelineCompleted
+	}
+	if err != nil {
+		run.status.Error = err.Error()
+	}
+	run.mu.Unlock()
+	run.notify()
+}
+
+func (run *pipelineRun) notify() {
+	if cb := run.executor.OnStatus; cb != nil {
+		cb(run.snapshot())
+	}
+}
+
+// pipelineRunKey carries the *pipelineRun from Execute to executeStep
+type pipelineRunKey struct{}
+
+// pipelineStepKey carries the run and current step ID to per-file loops
+type pipelineStepKey struct{}
+
+type pipelineStepProgress struct {
+	run    *pipelineRun
+	stepID string
+}
+
+func withStepProgress(ctx context.Context, run *pipelineRun, stepID string) context.Context {
+	return context.WithValue(ctx, pipelineStepKey{}, &pipelineStepProgress{run: run, stepID: stepID})
+}
+
+// stepProgress is called by per-file loops before processing file done+1 of
+// total. It reports progress and returns a ContextError as soon as the client
+// cancels, so the step stops before touching the next file.
+func stepProgress(ctx context.Context, done, total int) error {
+	if err := ctx.Err(); err != nil {
+		return &ContextError{Op: "pipeline", Details: fmt.Sprintf("cancelled after %d/%d files: %v", done, total, err)}
+	}
+	if p, ok := ctx.Value(pipelineStepKey{}).(*pipelineStepProgress); ok {
+		p.run.fileProgress(p.stepID, done, total)
+	}
+	return nil
+}
+
+// GetPipelineStatus returns running pipelines and recently finished ones
+// (running first, then newest first). id filters to a single run.
+func (e *UltraFastEngine) GetPipelineStatus(id string) []PipelineStatus {
+	if e.pipelineRuns == nil {
+		return nil
+	}
+	all := e.pipelineRuns.list()
+	if id == "" {
+		return all
+	}
+	for _, s := range all {
+		if s.ID == id {
+			return []PipelineStatus{s}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/mcp/filesystem-ultra/cache"
+)
+
+func setupProgressEngine(t *testing.T) (*UltraFastEngine, string) {
+	t.Helper()
+	dir := t.TempDir()
+	c, err := cache.NewIntelligentCache(1024 * 1024)
+	if err != nil {
+		t.Fatalf("cache: %v", err)
+	}
+	engine, err := NewUltraFastEngine(&Config{
+		Cache:        c,
+		AllowedPaths: []string{dir},
+		ParallelOps:  2,
+		BackupDir:    t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("engine: %v", err)
+	}
+	t.Cleanup(func() { engine.Close(); c.Close() })
+	return engine, dir
+}
+
+func createProgressFiles(t *testing.T, dir string, n int) []string {
+	t.Helper()
+	files := make([]string, n)
+	for i := range files {
+		files[i] = filepath.Join(dir, fmt.Sprintf("f%02d.txt", i))
+		if err := os.WriteFile(files[i], []byte("old value\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return files
+}
+
+func TestPipelineProgress_ReportsStepsAndFiles(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	files := createProgressFiles(t, dir, 5)
+
+	var mu sync.Mutex
+	var updates []PipelineStatus
+	pe := NewPipelineExecutor(engine)
+	pe.ProgressEvery = 2
+	pe.OnStatus = func(s PipelineStatus) {
+		mu.Lock()
+		updates = append(updates, s)
+		mu.Unlock()
+	}
+
+	result, err := pe.Execute(context.Background(), PipelineRequest{
+		Name: "progress",
+		Steps: []PipelineStep{
+			{ID: "count", Action: "count_occurrences", Params: map[string]interface{}{"pattern": "old", "files": toInterfaceSlice(files)}},
+			{ID: "fix", Action: "edit", InputFrom: "count", Params: map[string]interface{}{"old_text": "old", "new_text": "new"}},
+		},
+	})
+	if err != nil || !result.Success {
+		t.Fatalf("pipeline failed: %v", err)
+	}
+
+	sawMidStep := false
+	for _, u := range updates {
+		if u.StepID == "fix" && u.FilesTotal == 5 && u.FilesProcessed > 0 && u.FilesProcessed < 5 {
+			sawMidStep = true
+		}
+	}
+	if !sawMidStep {
+		t.Errorf("no mid-step file progress for fix: %+v", updates)
+	}
+	last := updates[len(updates)-1]
+	if last.Status != PipelineCompleted || last.CompletedSteps != 2 || last.FilesProcessed != 5 || last.Elapsed <= 0 {
+		t.Errorf("final status = %+v", last)
+	}
+
+	statuses := engine.GetPipelineStatus(last.ID)
+	if len(statuses) != 1 || statuses[0].Status != PipelineCompleted || statuses[0].Name != "progress" {
+		t.Errorf("GetPipelineStatus(%s) = %+v", last.ID, statuses)
+	}
+	if got := engine.GetPipelineStatus("pl_missing"); len(got) != 0 {
+		t.Errorf("unknown id returned %+v", got)
+	}
+}
+
+func TestPipelineProgress_CancelStopsStepAndRollsBack(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	files := createProgressFiles(t, dir, 6)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pe := NewPipelineExecutor(engine)
+	pe.ProgressEvery = 1
+	pe.OnStatus = func(s PipelineStatus) {
+		if s.StepID == "fix" && s.FilesProcessed == 2 {
+			cancel()
+		}
+	}
+
+	result, err := pe.Execute(ctx, PipelineRequest{
+		Name:         "cancel",
+		CreateBackup: true,
+		StopOnError:  false, // cancellation stops and rolls back regardless
+		Steps: []PipelineStep{
+			{ID: "fix", Action: "edit", Params: map[string]interface{}{"old_text": "old", "new_text": "new", "files": toInterfaceSlice(files)}},
+			{ID: "after", Action: "count_occurrences", Params: map[string]interface{}{"pattern": "new", "files": toInterfaceSlice(files)}},
+		},
+	})
+	if err == nil || result == nil {
+		t.Fatalf("expected cancellation error, got %v", err)
+	}
+	if !result.RollbackPerformed || len(result.Results) != 1 {
+		t.Errorf("rollback=%v results=%d, want rollback and no further steps", result.RollbackPerformed, len(result.Results))
+	}
+	if result.Results[0].EditsApplied >= len(files) {
+		t.Errorf("step did not stop promptly: %d edits", result.Results[0].EditsApplied)
+	}
+	for _, f := range files {
+		if got, _ := os.ReadFile(f); string(got) != "old value\n" {
+			t.Errorf("%s not restored: %q", filepath.Base(f), got)
+		}
+	}
+
+	statuses := engine.GetPipelineStatus("")
+	if len(statuses) == 0 || statuses[0].Status != PipelineCancelled {
+		t.Errorf("status = %+v, want cancelled", statuses)
+	}
+}
+
+func toInterfaceSlice(files []string) []interface{} {
+	out := make([]interface{}, len(files))
+	for i, f := range files {
+		out[i] = f
+	}
+	return out
+}
@@ -0,0 +1,144 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// StepRetry configures retries of transient failures within a step
+type StepRetry struct {
+	Attempts int    `json:"attempts"`           // Total attempts per operation, including the first (max 10)
+	DelayMs  int    `json:"delay_ms,omitempty"` // Delay before the first retry (default 100)
+	Backoff  string `json:"backoff,omitempty"`  // fixed (default), linear or exponential
+}
+
+// Retry limits
+const (
+	MaxStepRetryAttempts = 10
+	MaxStepRetryDelayMs  = 60000
+	defaultRetryDelayMs  = 100
+)
+
+// Validate checks the retry policy
+func (r *StepRetry) Validate() error {
+	if r == nil {
+		return nil
+	}
+	if r.Attempts < 1 || r.Attempts > MaxStepRetryAttempts {
+		return &ValidationError{
+			Field:   "retry.attempts",
+			Message: fmt.Sprintf("retry attempts must be between 1 and %d (got %d)", MaxStepRetryAttempts, r.Attempts),
+		}
+	}
+	if r.DelayMs < 0 || r.DelayMs > MaxStepRetryDelayMs {
+		return &ValidationError{
+			Field:   "retry.delay_ms",
+			Message: fmt.Sprintf("retry delay_ms must be between 0 and %d (got %d)", MaxStepRetryDelayMs, r.DelayMs),
+		}
+	}
+	switch r.Backoff {
+	case "", "fixed", "linear", "exponential":
+	default:
+		return &ValidationError{
+			Field:   "retry.backoff",
+			Message: fmt.Sprintf("unsupported backoff '%s' (use fixed, linear or exponential)", r.Backoff),
+		}
+	}
+	return nil
+}
+
+// delay returns the wait before retry number n (1-based)
+func (r *StepRetry) delay(n int) time.Duration {
+	base := r.DelayMs
+	if base == 0 {
+		base = defaultRetryDelayMs
+	}
+	switch r.Backoff {
+	case "linear":
+		base *= n
+	case "exponential":
+		base <<= n - 1
+	}
+	if base > MaxStepRetryDelayMs {
+		base = MaxStepRetryDelayMs
+	}
+	return time.Duration(base) * time.Millisecond
+}
+
+// windowsTransientErrnos are ERROR_SHARING_VIOLATION and ERROR_LOCK_VIOLATION:
+// an editor, indexer or antivirus briefly holds the file open.
+var windowsTransientErrnos = []syscall.Errno{32, 33}
+
+// transientErrorMessages catch the same conditions when the engine returned a
+// formatted error without wrapping the errno.
+var transientErrorMessages = []string{
+	"being used by another process",
+	"sharing violation",
+	"lock violation",
+	"locked a portion of the file",
+	"resource busy",
+	"text file busy",
+}
+
+// IsTransientError reports whether err is a short-lived lock or busy condition
+// worth retrying. Access denied, missing files, invalid patterns and other
+// logic errors are not transient.
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var locked *FileLockedError
+	if errors.As(err, &locked) {
+		return true
+	}
+	var errno syscall.Errno
+	if errors.As(err, &errno) {
+		if errno == syscall.EBUSY || errno == syscall.ETXTBSY {
+			return true
+		}
+		if runtime.GOOS == "windows" {
+			for _, e := range windowsTransientErrnos {
+				if errno == e {
+					return true
+				}
+			}
+		}
+	}
+	if errors.Is(err, fs.ErrPermission) || errors.Is(err, fs.ErrNotExist) {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, m := range transientErrorMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// retryOp runs one file operation of a step, retrying transient failures per
+// the step's retry policy. Retrying per operation (not per step) keeps files
+// already processed by the step from being processed twice.
+func (pe *PipelineExecutor) retryOp(ctx context.Context, step PipelineStep, result *StepResult, op func() error) error {
+	err := op()
+	if step.Retry == nil {
+		return err
+	}
+	for n := 1; n < step.Retry.Attempts && IsTransientError(err); n++ {
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(step.Retry.delay(n)):
+		}
+		result.Retries++
+		AppendSubOp(ctx, step.Action+":retry")
+		err = op()
+	}
+	return err
+}
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestIsTransientError(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"EBUSY", &os.PathError{Op: "open", Path: "f", Err: syscall.EBUSY}, true},
+		{"wrapped EBUSY", fmt.Errorf("write failed: %w", syscall.EBUSY), true},
+		{"windows sharing message", errors.New("open f: The process cannot access the file because it is being used by another process."), true},
+		{"permission", &os.PathError{Op: "open", Path: "f", Err: os.ErrPermission}, false},
+		{"not exist", fmt.Errorf("read: %w", os.ErrNotExist), false},
+		{"access denied", errors.New("access denied: path '/x' is not in allowed paths"), false},
+		{"invalid pattern", errors.New("invalid regex pattern: missing closing )"), false},
+	}
+	for _, tc := range cases {
+		if got := IsTransientError(tc.err); got != tc.want {
+			t.Errorf("%s: IsTransientError = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestRetryOp_RetriesTransientOnly(t *testing.T) {
+	pe := &PipelineExecutor{}
+	ctx := context.Background()
+	step := PipelineStep{ID: "s", Action: "edit", Retry: &StepRetry{Attempts: 3, DelayMs: 1, Backoff: "exponential"}}
+
+	// Two sharing violations, then success
+	calls := 0
+	result := &StepResult{}
+	err := pe.retryOp(ctx, step, result, func() error {
+		calls++
+		if calls < 3 {
+			return syscall.EBUSY
+		}
+		return nil
+	})
+	if err != nil || calls != 3 || result.Retries != 2 {
+		t.Fatalf("err=%v calls=%d retries=%d, want nil/3/2", err, calls, result.Retries)
+	}
+
+	// Attempts are capped
+	calls = 0
+	result = &StepResult{}
+	err = pe.retryOp(ctx, step, result, func() error { calls++; return syscall.EBUSY })
+	if !errors.Is(err, syscall.EBUSY) || calls != 3 || result.Retries != 2 {
+		t.Fatalf("err=%v calls=%d retries=%d, want EBUSY/3/2", err, calls, result.Retries)
+	}
+
+	// Non-transient errors are returned immediately
+	calls = 0
+	result = &StepResult{}
+	err = pe.retryOp(ctx, step, result, func() error { calls++; return os.ErrPermission })
+	if calls != 1 || result.Retries != 0 || err == nil {
+		t.Fatalf("permission error retried: calls=%d retries=%d", calls, result.Retries)
+	}
+
+	// No policy: single attempt
+	calls = 0
+	_ = pe.retryOp(ctx, PipelineStep{ID: "s"}, &StepResult{}, func() error { calls++; return syscall.EBUSY })
+	if calls != 1 {
+		t.Fatalf("retried without a policy: calls=%d", calls)
+	}
+}
+
+func TestStepRetry_ValidateAndDelay(t *testing.T) {
+	for _, bad := range []*StepRetry{
+		{Attempts: 0},
+		{Attempts: MaxStepRetryAttempts + 1},
+		{Attempts: 2, DelayMs: -1},
+		{Attempts: 2, Backoff: "random"},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("%+v: expected validation error", bad)
+		}
+	}
+
+	r := &StepRetry{Attempts: 4, DelayMs: 50, Backoff: "exponential"}
+	if err := r.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if got := []time.Duration{r.delay(1), r.delay(2), r.delay(3)}; got[0] != 50*time.Millisecond || got[1] != 100*time.Millisecond || got[2] != 200*time.Millisecond {
+		t.Errorf("exponential delays = %v", got)
+	}
+	linear := &StepRetry{Attempts: 3, Backoff: "linear"}
+	if got := linear.delay(3); got != 300*time.Millisecond {
+		t.Errorf("linear default delay(3) = %v, want 300ms", got)
+	}
+}
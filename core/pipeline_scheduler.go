@@ -62,9 +62,9 @@ func (s *PipelineScheduler) BuildExecutionPlan(steps []PipelineStep) ([][]int, e
 			}
 		}
 
-		// condition.step_ref dependency
-		if step.Condition != nil && step.Condition.StepRef != "" {
-			if depIdx, ok := idToIdx[step.Condition.StepRef]; ok {
+		// condition dependencies (step_ref or expression references)
+		for _, ref := range step.Condition.References() {
+			if depIdx, ok := idToIdx[ref]; ok {
 				// Avoid duplicate dependency
 				alreadyDep := false
 				for _, d := range deps[i] {
@@ -192,8 +192,11 @@ func (s *PipelineScheduler) GetDependencies(steps []PipelineStep) map[string][]s
 				seen[ref] = true
 			}
 		}
-		if step.Condition != nil && step.Condition.StepRef != "" && !seen[step.Condition.StepRef] {
-			deps = append(deps, step.Condition.StepRef)
+		for _, ref := range step.Condition.References() {
+			if !seen[ref] {
+				deps = append(deps, ref)
+				seen[ref] = true
+			}
 		}
 		result[step.ID] = deps
 	}
@@ -0,0 +1,70 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPipelineSearch_HonorsEngineLimits(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+
+	write := func(rel string, data []byte) {
+		t.Helper()
+		p := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a.txt", []byte("needle\n"))
+	write(".git/objects/x.txt", []byte("needle\n"))
+	write("node_modules/pkg/index.js", []byte("needle\n"))
+	write("blob.bin", []byte("needle\x00\x01\x02"))
+	big := filepath.Join(dir, "big.txt")
+	write("big.txt", []byte("needle\n"))
+	if err := os.Truncate(big, searchMaxFileSize+1); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewPipelineExecutor(engine).Execute(context.Background(), PipelineRequest{
+		Name:  "search",
+		Steps: []PipelineStep{{ID: "find", Action: "search", Params: map[string]interface{}{"path": dir, "pattern": "needle"}}},
+	})
+	if err != nil || !result.Success {
+		t.Fatalf("pipeline failed: %v", err)
+	}
+	step := result.Results[0]
+	if len(step.FilesMatched) != 1 || filepath.Base(step.FilesMatched[0]) != "a.txt" {
+		t.Errorf("files = %v, want only a.txt", step.FilesMatched)
+	}
+	want := map[string]int{"excluded_dir": 2, "binary": 1, "too_large": 1}
+	for reason, n := range want {
+		if step.FilesSkipped[reason] != n {
+			t.Errorf("files_skipped = %v, want %v", step.FilesSkipped, want)
+			break
+		}
+	}
+	if step.ResultsLimited {
+		t.Error("results_limited set without hitting the cap")
+	}
+
+	// The engine's MaxSearchResults caps pipeline search too
+	for _, name := range []string{"b.txt", "c.txt", "d.txt"} {
+		write(name, []byte("needle\n"))
+	}
+	engine.config.MaxSearchResults = 2
+	result, err = NewPipelineExecutor(engine).Execute(context.Background(), PipelineRequest{
+		Name:  "capped",
+		Steps: []PipelineStep{{ID: "find", Action: "search", Params: map[string]interface{}{"path": dir, "pattern": "needle"}}},
+	})
+	if err != nil || !result.Success {
+		t.Fatalf("pipeline failed: %v", err)
+	}
+	if step := result.Results[0]; len(step.FilesMatched) != 2 || !step.ResultsLimited {
+		t.Errorf("files = %v limited = %v, want 2 files and results_limited", step.FilesMatched, step.ResultsLimited)
+	}
+}
@@ -3,7 +3,9 @@
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // templatePattern matches {{step_id.field}} references
@@ -109,3 +111,236 @@ func resolveField(ref *StepResult, field string, fallback string) string {
 		return fallback
 	}
 }
+
+// Variable substitution
+//
+// String params may contain ${var} placeholders, resolved in executeStep
+// before dispatch (and before {{step.field}} templates):
+//
+//	${name}                   value from the request's "variables" map
+//	${date} ${time}           pipeline start, 2006-01-02 / 15-04-05
+//	${timestamp}              pipeline start, 20060102-150405
+//	${pipeline.name}          the pipeline name
+//	${steps.<id>.<field>}     a prior step field (count, files_count, files, risk, edits)
+//	${steps.<id>.files[N]}    the Nth file matched by a prior step
+//
+// "$${" produces a literal "${". Unknown variables are validation errors.
+
+// variablePattern matches $${ (escape) or ${...}
+var variablePattern = regexp.MustCompile(`\$\$\{|\$\{([^{}]*)\}`)
+
+// stepVariablePattern matches steps.<id>.<field> with an optional [N] index
+var stepVariablePattern = regexp.MustCompile(`^steps\.([a-zA-Z0-9_-]+)\.([a-z_]+)(?:\[(\d+)\])?$`)
+
+// userVariablePattern is the allowed shape of a name in "variables"
+var userVariablePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+
+var builtinVariables = map[string]bool{
+	"date":          true,
+	"time":          true,
+	"timestamp":     true,
+	"pipeline.name": true,
+}
+
+var stepVariableFields = map[string]bool{
+	FieldCount:      true,
+	FieldFilesCount: true,
+	FieldFiles:      true,
+	FieldRisk:       true,
+	FieldEdits:      true,
+}
+
+// ValidateVariables checks the request's variables map and every ${...}
+// placeholder in a step's params. priorStepIDs holds the steps validated so
+// far, including step itself.
+func ValidateVariables(step *PipelineStep, variables map[string]string, priorStepIDs map[string]int) error {
+	var firstErr error
+	walkParamStrings(step.Params, "", func(param, s string) {
+		if firstErr != nil || !strings.Contains(s, "${") {
+			return
+		}
+		for _, m := range variablePattern.FindAllStringSubmatch(s, -1) {
+			if m[0] == "$${" {
+				continue
+			}
+			if msg := checkVariable(m[1], step.ID, variables, priorStepIDs); msg != "" {
+				firstErr = &PipelineStepError{
+					StepID:     step.ID,
+					Action:     step.Action,
+					Param:      param,
+					Message:    msg,
+					Suggestion: "define it in the pipeline's \"variables\" map or use a built-in: ${date}, ${time}, ${timestamp}, ${pipeline.name}, ${steps.<id>.files[0]}",
+				}
+				return
+			}
+		}
+	})
+	return firstErr
+}
+
+// checkVariable returns why name cannot be resolved, or "" when it can
+func checkVariable(name, stepID string, variables map[string]string, priorStepIDs map[string]int) string {
+	if _, ok := variables[name]; ok {
+		return ""
+	}
+	if builtinVariables[name] {
+		return ""
+	}
+	if m := stepVariablePattern.FindStringSubmatch(name); m != nil {
+		if !isPriorStep(m[1], stepID, priorStepIDs) {
+			return fmt.Sprintf("variable '${%s}' references step '%s' which is not defined before this step", name, m[1])
+		}
+		if !stepVariableFields[m[2]] {
+			return fmt.Sprintf("variable '${%s}' uses unknown step field '%s'", name, m[2])
+		}
+		if m[3] != "" && m[2] != FieldFiles {
+			return fmt.Sprintf("variable '${%s}': only files can be indexed", name)
+		}
+		return ""
+	}
+	return fmt.Sprintf("unknown variable '${%s}'", name)
+}
+
+// validateVariableNames rejects names that could never be referenced or that
+// shadow built-ins
+func validateVariableNames(variables map[string]string) error {
+	for name := range variables {
+		if !userVariablePattern.MatchString(name) {
+			return &ValidationError{
+				Field:   "variables",
+				Message: fmt.Sprintf("invalid variable name '%s' (letters, digits, - and _; must not start with a digit)", name),
+			}
+		}
+		if builtinVariables[name] {
+			return &ValidationError{
+				Field:   "variables",
+				Message: fmt.Sprintf("variable '%s' shadows a built-in", name),
+			}
+		}
+	}
+	return nil
+}
+
+// ResolveVariables returns a copy of params with ${var} placeholders
+// substituted. The error names the param that could not be resolved.
+func ResolveVariables(params map[string]interface{}, pCtx *PipelineContext) (map[string]interface{}, error) {
+	if params == nil {
+		return nil, nil
+	}
+	var firstErr error
+	resolved := make(map[string]interface{}, len(params))
+	for k, v := range params {
+		resolved[k] = resolveVariablesValue(v, k, pCtx, &firstErr)
+	}
+	return resolved, firstErr
+}
+
+func resolveVariablesValue(v interface{}, param string, pCtx *PipelineContext, firstErr *error) interface{} {
+	switch val := v.(type) {
+	case string:
+		s, err := resolveVariablesString(val, pCtx)
+		if err != nil && *firstErr == nil {
+			*firstErr = fmt.Errorf("param '%s': %w", param, err)
+		}
+		return s
+	case []interface{}:
+		result := make([]interface{}, len(val))
+		for i, item := range val {
+			result[i] = resolveVariablesValue(item, fmt.Sprintf("%s[%d]", param, i), pCtx, firstErr)
+		}
+		return result
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			result[k] = resolveVariablesValue(item, param+"."+k, pCtx, firstErr)
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+func resolveVariablesString(s string, pCtx *PipelineContext) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+	var resolveErr error
+	out := variablePattern.ReplaceAllStringFunc(s, func(match string) string {
+		if match == "$${" {
+			return "${"
+		}
+		name := match[2 : len(match)-1]
+		value, err := pCtx.lookupVariable(name)
+		if err != nil {
+			if resolveErr == nil {
+				resolveErr = err
+			}
+			return match
+		}
+		return value
+	})
+	return out, resolveErr
+}
+
+// lookupVariable resolves one ${name} against the pipeline context
+func (pc *PipelineContext) lookupVariable(name string) (string, error) {
+	pc.mu.RLock()
+	value, ok := pc.variables[name]
+	pipelineName, started := pc.pipelineName, pc.startedAt
+	pc.mu.RUnlock()
+	if ok {
+		return value, nil
+	}
+	if started.IsZero() {
+		started = time.Now()
+	}
+
+	switch name {
+	case "date":
+		return started.Format("2006-01-02"), nil
+	case "time":
+		return started.Format("15-04-05"), nil
+	case "timestamp":
+		return started.Format("20060102-150405"), nil
+	case "pipeline.name":
+		return pipelineName, nil
+	}
+
+	m := stepVariablePattern.FindStringSubmatch(name)
+	if m == nil {
+		return "", fmt.Errorf("unknown variable '${%s}'", name)
+	}
+	ref, exists := pc.GetStepResult(m[1])
+	if !exists {
+		return "", fmt.Errorf("variable '${%s}': step '%s' has not run", name, m[1])
+	}
+	if m[3] != "" {
+		idx, _ := strconv.Atoi(m[3])
+		if idx >= len(ref.FilesMatched) {
+			return "", fmt.Errorf("variable '${%s}': step '%s' matched %d files", name, m[1], len(ref.FilesMatched))
+		}
+		return ref.FilesMatched[idx], nil
+	}
+	return resolveField(ref, m[2], "${"+name+"}"), nil
+}
+
+// walkParamStrings calls fn for every string in params with its param path
+// (e.g. "edits[0].new_text")
+func walkParamStrings(v interface{}, path string, fn func(param, s string)) {
+	switch val := v.(type) {
+	case string:
+		fn(path, val)
+	case []interface{}:
+		for i, item := range val {
+			walkParamStrings(item, fmt.Sprintf("%s[%d]", path, i), fn)
+		}
+	case map[string]interface{}:
+		for k, item := range val {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			walkParamStrings(item, p, fn)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package core
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPipelineTimeout_StepTimeoutFailsAndRollsBack(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	files := createProgressFiles(t, dir, 3)
+
+	pe := NewPipelineExecutor(engine)
+	pe.ProgressEvery = 1
+	pe.OnStatus = func(s PipelineStatus) {
+		// Make the first edited file outlast the 1s step timeout
+		if s.StepID == "fix" && s.FilesProcessed == 1 {
+			time.Sleep(1100 * time.Millisecond)
+		}
+	}
+
+	result, err := pe.Execute(context.Background(), PipelineRequest{
+		Name:         "slow",
+		StopOnError:  true,
+		CreateBackup: true,
+		Steps: []PipelineStep{
+			{ID: "fix", Action: "edit", Timeout: 1, Params: map[string]interface{}{"old_text": "old", "new_text": "new", "files": toInterfaceSlice(files)}},
+		},
+	})
+	if err == nil || result == nil {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+	step := result.Results[0]
+	if !step.TimedOut || !strings.Contains(step.Error, "timeout_seconds=1") {
+		t.Errorf("step = timed_out:%v error:%q, want timeout", step.TimedOut, step.Error)
+	}
+	if step.Duration < time.Second {
+		t.Errorf("duration = %v, want actual elapsed time (>= 1s)", step.Duration)
+	}
+	if !result.RollbackPerformed {
+		t.Error("timed-out step with stop_on_error should roll back")
+	}
+	for _, f := range files {
+		if got, _ := os.ReadFile(f); string(got) != "old value\n" {
+			t.Errorf("%s not restored: %q", f, got)
+		}
+	}
+}
+
+func TestPipelineTimeout_ExpiredDeadlineIsTimeout(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	files := createProgressFiles(t, dir, 1)
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	result, err := NewPipelineExecutor(engine).Execute(ctx, PipelineRequest{
+		Name:  "late",
+		Steps: []PipelineStep{{ID: "count", Action: "count_occurrences", Params: map[string]interface{}{"pattern": "old", "files": toInterfaceSlice(files)}}},
+	})
+	if err == nil || result == nil {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+	if step := result.Results[0]; !step.TimedOut || !strings.Contains(step.Error, "max_duration_seconds") {
+		t.Errorf("step = %+v, want pipeline timeout", step)
+	}
+
+	// Plain failures are not reported as timeouts
+	if stepTimeoutError(context.Background(), context.Background(), PipelineStep{ID: "x"}) != nil {
+		t.Error("non-expired context reported as timeout")
+	}
+}
+
+func TestPipelineTimeout_Validation(t *testing.T) {
+	steps := []PipelineStep{{ID: "c", Action: "count_occurrences", Params: map[string]interface{}{"pattern": "x", "files": []interface{}{"a"}}}}
+
+	req := PipelineRequest{Name: "v", MaxDuration: -1, Steps: steps}
+	if err := req.Validate(); err == nil || !strings.Contains(err.Error(), "max_duration_seconds") {
+		t.Errorf("negative max_duration_seconds: %v", err)
+	}
+	steps[0].Timeout = MaxPipelineDurationSeconds + 1
+	req = PipelineRequest{Name: "v", Steps: steps}
+	if err := req.Validate(); err == nil || !strings.Contains(err.Error(), "timeout_seconds") {
+		t.Errorf("oversized timeout_seconds: %v", err)
+	}
+}
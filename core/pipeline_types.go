@@ -11,26 +11,31 @@
 
 // PipelineRequest represents a multi-step file transformation pipeline
 type PipelineRequest struct {
-	Name         string         `json:"name"`               // Required: pipeline name
-	StopOnError  bool           `json:"stop_on_error"`      // Default: true - stop on first error
-	DryRun       bool           `json:"dry_run"`            // Default: false - preview changes without applying
-	CreateBackup bool           `json:"create_backup"`      // Default: true if destructive steps present
-	Force        bool           `json:"force"`              // Bypass risk warnings
-	Verbose      bool           `json:"verbose"`            // Return intermediate data (contents, per-file counts)
-	Parallel     bool           `json:"parallel,omitempty"` // Enable parallel execution via DAG scheduling
-	Steps        []PipelineStep `json:"steps"`              // Pipeline steps to execute
-	validated    bool           // Internal: validation cache
+	Name         string            `json:"name"`                           // Required: pipeline name
+	StopOnError  bool              `json:"stop_on_error"`                  // Default: true - stop on first error
+	DryRun       bool              `json:"dry_run"`                        // Default: false - preview changes without applying
+	CreateBackup bool              `json:"create_backup"`                  // Default: true if destructive steps present
+	Force        bool              `json:"force"`                          // Bypass risk warnings
+	Verbose      bool              `json:"verbose"`                        // Return intermediate data (contents, per-file counts)
+	Parallel     bool              `json:"parallel,omitempty"`             // Enable parallel execution via DAG scheduling
+	Variables    map[string]string `json:"variables,omitempty"`            // Values for ${name} placeholders in step params
+	MaxDuration  int               `json:"max_duration_seconds,omitempty"` // Deadline for the whole pipeline (0 = none)
+	Output       string            `json:"output,omitempty"`               // Tool response format: "text" (default) or "json"
+	Steps        []PipelineStep    `json:"steps"`                          // Pipeline steps to execute
+	validated    bool              // Internal: validation cache
 }
 
 // PipelineStep represents a single operation in the pipeline.
 // Accepts both "action" and "type" field names (Claude Desktop sometimes uses "type").
 type PipelineStep struct {
-	ID           string                 `json:"id"`                       // Unique identifier (alphanumeric + - _)
-	Action       string                 `json:"action"`                   // Action type: search, edit, etc.
-	InputFrom    string                 `json:"input_from,omitempty"`     // ID of previous step to get input from
-	InputFromAll []string               `json:"input_from_all,omitempty"` // IDs of multiple steps (for aggregate/merge)
-	Params       map[string]interface{} `json:"params"`                   // Action-specific parameters
-	Condition    *StepCondition         `json:"condition,omitempty"`      // Optional condition for conditional execution
+	ID           string                 `json:"id"`                        // Unique identifier (alphanumeric + - _)
+	Action       string                 `json:"action"`                    // Action type: search, edit, etc.
+	InputFrom    string                 `json:"input_from,omitempty"`      // ID of previous step to get input from
+	InputFromAll []string               `json:"input_from_all,omitempty"`  // IDs of multiple steps (for aggregate/merge)
+	Params       map[string]interface{} `json:"params"`                    // Action-specific parameters
+	Condition    *StepCondition         `json:"condition,omitempty"`       // Optional condition for conditional execution
+	Retry        *StepRetry             `json:"retry,omitempty"`           // Optional retry policy for transient failures
+	Timeout      int                    `json:"timeout_seconds,omitempty"` // Fail the step with a timeout after this many seconds (0 = none)
 }
 
 // UnmarshalJSON implements custom JSON unmarshaling to accept "type" as alias for "action"
@@ -76,6 +81,15 @@ type StepResult struct {
 	Duration          time.Duration     `json:"duration"`                     // Step execution time
 	RiskLevel         string            `json:"risk_level,omitempty"`         // LOW/MEDIUM/HIGH/CRITICAL
 	AggregatedContent string            `json:"aggregated_content,omitempty"` // Combined content from aggregate/merge
+	BytesWritten      int64             `json:"bytes_written,omitempty"`      // Bytes written (or that would be, in dry_run) by write
+	DirsCreated       []string          `json:"dirs_created,omitempty"`       // Directories created (or that would be, in dry_run)
+	Retries           int               `json:"retries,omitempty"`            // Transient failures retried
+	TimedOut          bool              `json:"timed_out,omitempty"`          // Failed because timeout_seconds or max_duration_seconds elapsed
+	Diffs             map[string]string `json:"diffs,omitempty"`              // Verbose: path -> unified diff snippet (edit, regex_transform)
+	DiffsTruncated    bool              `json:"diffs_truncated,omitempty"`    // Some diffs omitted or cut to stay within the response budget
+	Renames           map[string]string `json:"renames,omitempty"`            // batch_rename: old path -> new path (planned, in dry_run)
+	FilesSkipped      map[string]int    `json:"files_skipped,omitempty"`      // search: reason (binary, too_large, unreadable, excluded_dir) -> count
+	ResultsLimited    bool              `json:"results_limited,omitempty"`    // search stopped at the max results limit
 	internalData      interface{}       `json:"-"`                            // Internal data not serialized
 }
 
@@ -102,6 +116,12 @@ type PipelineContext struct {
 	affectedFiles map[string]bool
 	regexCache    map[string]*regexp.Regexp
 	backupID      string
+	pipelineName  string
+	variables     map[string]string
+	startedAt     time.Time
+	verboseDiffs  bool        // collect per-file diffs for edit/regex_transform
+	diffBudget    int64       // bytes of diff left before snippets are dropped
+	renames       [][2]string // batch_rename journal (old, new), undone on rollback
 	mu            sync.RWMutex
 }
 
@@ -129,6 +149,56 @@ func (pc *PipelineContext) SetStepResult(stepID string, result *StepResult) {
 	pc.stepResults[stepID] = result
 }
 
+// SetPipelineInfo stores the pipeline name and variables used by ${var}
+// substitution; built-in time variables are fixed at this moment
+func (pc *PipelineContext) SetPipelineInfo(name string, variables map[string]string) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	pc.pipelineName = name
+	pc.variables = variables
+	pc.startedAt = time.Now()
+}
+
+// EnableDiffs turns on per-file diff snippets for verbose pipelines, bounded
+// by budget bytes across all steps
+func (pc *PipelineContext) EnableDiffs(budget int64) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	pc.verboseDiffs = true
+	pc.diffBudget = budget
+}
+
+// takeDiffBudget reserves n bytes of diff output; false when diffs are off
+// or the budget is spent
+func (pc *PipelineContext) takeDiffBudget(n int) bool {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	if !pc.verboseDiffs || int64(n) > pc.diffBudget {
+		return false
+	}
+	pc.diffBudget -= int64(n)
+	return true
+}
+
+// recordRename journals a completed rename so rollback can undo it
+func (pc *PipelineContext) recordRename(oldPath, newPath string) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	pc.renames = append(pc.renames, [2]string{oldPath, newPath})
+}
+
+// takeRenames returns the rename journal newest first and clears it
+func (pc *PipelineContext) takeRenames() [][2]string {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	out := make([][2]string, len(pc.renames))
+	for i, r := range pc.renames {
+		out[len(pc.renames)-1-i] = r
+	}
+	pc.renames = nil
+	return out
+}
+
 // AddAffectedFiles adds files to the affected set (thread-safe)
 func (pc *PipelineContext) AddAffectedFiles(files []string) {
 	pc.mu.Lock()
@@ -177,6 +247,10 @@ func (pc *PipelineContext) GetBackupID() string {
 	"aggregate":         true,
 	"diff":              true,
 	"merge":             true,
+	"write":             true,
+	"create_dir":        true,
+	"filter":            true,
+	"batch_rename":      true,
 }
 
 // Validate validates the entire pipeline request
@@ -216,6 +290,24 @@ func (pr *PipelineRequest) Validate() error {
 		}
 	}
 
+	if pr.MaxDuration < 0 || pr.MaxDuration > MaxPipelineDurationSeconds {
+		return &ValidationError{
+			Field:   "max_duration_seconds",
+			Message: fmt.Sprintf("must be between 0 and %d, got %d", MaxPipelineDurationSeconds, pr.MaxDuration),
+		}
+	}
+
+	if pr.Output != "" && pr.Output != "text" && pr.Output != "json" {
+		return &ValidationError{
+			Field:   "output",
+			Message: fmt.Sprintf("unsupported output '%s' (use \"text\" or \"json\")", pr.Output),
+		}
+	}
+
+	if err := validateVariableNames(pr.Variables); err != nil {
+		return err
+	}
+
 	// Validate unique step IDs and build dependency graph
 	stepIDs := make(map[string]int) // ID -> index
 	for i, step := range pr.Steps {
@@ -274,6 +366,11 @@ func (pr *PipelineRequest) Validate() error {
 				return err
 			}
 		}
+
+		// Validate ${var} placeholders
+		if err := ValidateVariables(&pr.Steps[i], pr.Variables, stepIDs); err != nil {
+			return err
+		}
 	}
 
 	pr.validated = true
@@ -311,6 +408,18 @@ func (ps *PipelineStep) Validate() error {
 		return err
 	}
 
+	// Validate retry policy
+	if err := ps.Retry.Validate(); err != nil {
+		return err
+	}
+
+	if ps.Timeout < 0 || ps.Timeout > MaxPipelineDurationSeconds {
+		return &ValidationError{
+			Field:   "timeout_seconds",
+			Message: fmt.Sprintf("must be between 0 and %d, got %d", MaxPipelineDurationSeconds, ps.Timeout),
+		}
+	}
+
 	return nil
 }
 
@@ -506,6 +615,76 @@ func (ps *PipelineStep) validateActionParams() error {
 				Message: "merge action requires 'input_from_all' or 'input_from'",
 			}
 		}
+
+	case "filter":
+		// Requires: input_from OR files/path, and at least one predicate
+		if !hasInputFiles() {
+			return &ValidationError{
+				Field:   "params.files",
+				Message: "filter action requires 'input_from', 'files', or 'path' parameter",
+			}
+		}
+		hasPredicate := false
+		for _, key := range []string{"include_glob", "exclude_glob", "path_contains", "max_files", "min_count"} {
+			if _, ok := ps.Params[key]; ok {
+				hasPredicate = true
+			}
+		}
+		if !hasPredicate {
+			return &ValidationError{
+				Field:   "params",
+				Message: "filter action requires at least one of include_glob, exclude_glob, path_contains, max_files, min_count",
+			}
+		}
+		if _, ok := ps.Params["min_count"]; ok && ps.InputFrom == "" {
+			return &ValidationError{
+				Field:   "params.min_count",
+				Message: "min_count needs input_from pointing at a step with counts (e.g. count_occurrences)",
+			}
+		}
+
+	case "batch_rename":
+		// Requires: input_from OR files, and a valid BatchRenameRequest mode
+		if !hasInputFiles() {
+			return &ValidationError{
+				Field:   "params.files",
+				Message: "batch_rename action requires 'input_from', 'files', or 'path' parameter",
+			}
+		}
+		req, err := batchRenameParams(ps.Params)
+		if err == nil {
+			err = validateBatchRenameMode(&req)
+		}
+		if err != nil {
+			return &ValidationError{
+				Field:   "params.mode",
+				Message: fmt.Sprintf("batch_rename: %v", err),
+			}
+		}
+
+	case "create_dir":
+		// Requires: path
+		if p, _ := ps.Params["path"].(string); strings.TrimSpace(p) == "" {
+			return &ValidationError{
+				Field:   "params.path",
+				Message: "create_dir action requires 'path' parameter",
+			}
+		}
+
+	case "write":
+		// Requires: path and content (content may be empty)
+		if p, _ := ps.Params["path"].(string); strings.TrimSpace(p) == "" {
+			return &ValidationError{
+				Field:   "params.path",
+				Message: "write action requires 'path' parameter",
+			}
+		}
+		if _, ok := ps.Params["content"].(string); !ok {
+			return &ValidationError{
+				Field:   "params.content",
+				Message: "write action requires 'content' parameter (string)",
+			}
+		}
 	}
 
 	return nil
@@ -522,6 +701,10 @@ func (ps *PipelineStep) getInputFiles(ctx *PipelineContext) ([]string, error) {
 		if !prevResult.Success {
 			return nil, fmt.Errorf("referenced step '%s' failed: %s", ps.InputFrom, prevResult.Error)
 		}
+		// A step skipped by its condition contributes no files
+		if prevResult.Skipped {
+			return []string{}, nil
+		}
 		if len(prevResult.FilesMatched) == 0 {
 			return nil, fmt.Errorf("referenced step '%s' matched no files", ps.InputFrom)
 		}
@@ -560,3 +743,17 @@ func (ps *PipelineStep) getInputFiles(ctx *PipelineContext) ([]string, error) {
 
 	return nil, fmt.Errorf("no input files specified (missing input_from or files)")
 }
+
+// batchRenameParams decodes batch_rename step params into the same
+// BatchRenameRequest that batch_operations(rename_json) uses
+func batchRenameParams(params map[string]interface{}) (BatchRenameRequest, error) {
+	var req BatchRenameRequest
+	data, err := json.Marshal(params)
+	if err != nil {
+		return req, err
+	}
+	if err := json.Unmarshal(data, &req); err != nil {
+		return req, fmt.Errorf("invalid params: %w", err)
+	}
+	return req, nil
+}
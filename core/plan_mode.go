@@ -94,6 +94,8 @@ func (e *UltraFastEngine) AnalyzeWriteChange(ctx context.Context, path, content
 
 	// Assess risk
 	analysis.RiskLevel = e.assessWriteRisk(analysis, path, content, existingContent)
+	e.flagCriticalProjectFile(analysis)
+	e.applyProtectedRisk(analysis, false)
 
 	// Add risk factors
 	if analysis.FileExists {
@@ -179,6 +181,8 @@ func (e *UltraFastEngine) AnalyzeEditChange(ctx context.Context, path, oldText,
 
 	// Assess risk
 	analysis.RiskLevel = e.assessEditRisk(analysis, occurrences, oldText, newText)
+	e.flagCriticalProjectFile(analysis)
+	e.applyProtectedRisk(analysis, false)
 
 	// Add metadata
 	analysis.Metadata["occurrences"] = occurrences
@@ -260,6 +264,8 @@ func (e *UltraFastEngine) AnalyzeDeleteChange(ctx context.Context, path string)
 		analysis.RiskLevel = "critical"
 		analysis.RiskFactors = append(analysis.RiskFactors, "Critical or configuration file")
 	}
+	e.flagCriticalProjectFile(analysis)
+	e.applyProtectedRisk(analysis, true)
 
 	// Suggestions
 	analysis.Suggestions = append(analysis.Suggestions, "Consider using soft_delete_file for safer deletion")
@@ -434,6 +440,18 @@ func (e *UltraFastEngine) assessDeleteRisk(path string, size int64) string {
 	return "medium"
 }
 
+// flagCriticalProjectFile bumps the analysis one risk level when the target is
+// a critical project file (go.mod, lockfiles, Dockerfile, .git/**, SQL
+// migrations or --critical-files), mirroring CalculateChangeImpact.
+func (e *UltraFastEngine) flagCriticalProjectFile(analysis *ChangeAnalysis) {
+	name := MatchCriticalFile(analysis.FilePath, e.riskThresholds.CriticalFiles)
+	if name == "" {
+		return
+	}
+	analysis.RiskLevel = bumpRiskLevel(analysis.RiskLevel)
+	analysis.RiskFactors = append(analysis.RiskFactors, "critical file: "+name)
+}
+
 func (e *UltraFastEngine) isCriticalFile(path string) bool {
 	criticalPatterns := []string{
 		".env", "config", "credentials", "password", "secret",
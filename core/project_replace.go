@@ -25,6 +25,9 @@ type ProjectReplaceResult struct {
 	// refused to write anything. The result is a pure preview: counts describe
 	// what WOULD change, and the disk is guaranteed untouched.
 	Blocked bool `json:"blocked,omitempty"`
+	// ProtectedSkipped counts matching files left alone because they are
+	// write-protected (--protected-paths)
+	ProtectedSkipped int `json:"protected_skipped,omitempty"`
 }
 
 // ProjectReplaceFileResult contains results for a single file
@@ -108,6 +111,7 @@ func (e *UltraFastEngine) ProjectReplace(ctx context.Context, path, find, replac
 
 	// Discover files
 	var matchedFiles []string
+	protectedSkipped := 0
 	err := filepath.Walk(path, func(filePath string, info os.FileInfo, walkErr error) error {
 		if walkErr != nil {
 			return nil // Skip errors
@@ -172,6 +176,11 @@ func (e *UltraFastEngine) ProjectReplace(ctx context.Context, path, find, replac
 			}
 		}
 
+		if e.ProtectedRule(filePath) != "" {
+			protectedSkipped++
+			return nil
+		}
+
 		matchedFiles = append(matchedFiles, filePath)
 		return nil
 	})
@@ -181,9 +190,10 @@ func (e *UltraFastEngine) ProjectReplace(ctx context.Context, path, find, replac
 
 	if len(matchedFiles) == 0 {
 		return &ProjectReplaceResult{
-			FilesChanged:  0,
-			TotalReplaced: 0,
-			DryRun:        preview,
+			FilesChanged:     0,
+			TotalReplaced:    0,
+			DryRun:           preview,
+			ProtectedSkipped: protectedSkipped,
 		}, nil
 	}
 
@@ -214,9 +224,10 @@ func (e *UltraFastEngine) ProjectReplace(ctx context.Context, path, find, replac
 	}
 
 	result := &ProjectReplaceResult{
-		FilesChanged: len(filesWithMatches),
-		DryRun:       preview,
-		RiskLevel:    riskLevel,
+		FilesChanged:     len(filesWithMatches),
+		DryRun:           preview,
+		RiskLevel:        riskLevel,
+		ProtectedSkipped: protectedSkipped,
 	}
 
 	if preview {
@@ -384,6 +395,15 @@ type fileResult struct {
 		result.RiskWarning = fmt.Sprintf("⚠️ %s risk applied (force=true): %d files, %d replacements were written.", riskLevel, result.FilesChanged, result.TotalReplaced)
 	}
 
+	e.recordOperation(ctx, OperationRecord{
+		Operation:    "project_replace",
+		Path:         path,
+		RiskLevel:    strings.ToLower(riskLevel),
+		Replacements: result.TotalReplaced,
+		BackupID:     backupID,
+		Forced:       force,
+	})
+
 	return result, nil
 }
 
@@ -0,0 +1,221 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Write protection (--protected-paths) keeps paths inside AllowedPaths
+// readable but immutable: every mutating operation checks CheckWritable or
+// CheckRemovable next to IsPathAllowed.
+//
+// Rule syntax:
+//   - absolute path or glob ("/project/vendor", "/project/*/generated"): the
+//     matching path and everything below it
+//   - anything else uses the CriticalFiles syntax (see matchPathPattern):
+//     "*.lock" against the base name, "vendor/**" for the directory and its
+//     contents at any depth, "db/*.sql" against the trailing segments
+
+// ProtectedPathRule is one --protected-paths rule as shown by allowed_paths rules
+type ProtectedPathRule struct {
+	Rule     string `json:"rule"`
+	Absolute bool   `json:"absolute"` // anchored path or glob; otherwise matched by name/segments
+}
+
+// protectedRuleKey cleans an absolute rule for comparison
+func protectedRuleKey(rule string) string {
+	rule = filepath.Clean(NormalizePath(rule))
+	if os.PathSeparator == '\\' {
+		return strings.ToLower(rule)
+	}
+	return rule
+}
+
+// isAbsoluteRule reports whether rule is anchored to a location
+func isAbsoluteRule(rule string) bool {
+	return filepath.IsAbs(NormalizePath(rule)) || strings.HasPrefix(rule, "/")
+}
+
+// matchProtectedRule reports whether rule protects path (absolute, cleaned)
+func matchProtectedRule(path, rule string) bool {
+	if isAbsoluteRule(rule) {
+		key := protectedRuleKey(rule)
+		p := path
+		if os.PathSeparator == '\\' {
+			p = strings.ToLower(p)
+		}
+		for {
+			if ok, _ := filepath.Match(key, p); ok {
+				return true
+			}
+			parent := filepath.Dir(p)
+			if parent == p {
+				return false
+			}
+			p = parent
+		}
+	}
+	segments := strings.Split(filepath.ToSlash(path), "/")
+	if matchPathPattern(segments, rule) != "" {
+		return true
+	}
+	// "dir/**" also covers the directory itself
+	dir, ok := strings.CutSuffix(strings.TrimSpace(filepath.ToSlash(rule)), "/**")
+	return ok && segments[len(segments)-1] == dir
+}
+
+// protectedAbs resolves path the way the rules are matched
+func protectedAbs(path string) string {
+	abs, err := filepath.Abs(canonicalWSLForm(NormalizePath(path)))
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	return abs
+}
+
+// ProtectedRule returns the --protected-paths rule that makes path
+// read-only, or "" when it is writable
+func (e *UltraFastEngine) ProtectedRule(path string) string {
+	if len(e.config.ProtectedPaths) == 0 || path == "" {
+		return ""
+	}
+	abs := protectedAbs(path)
+	for _, rule := range e.config.ProtectedPaths {
+		if matchProtectedRule(abs, rule) {
+			return rule
+		}
+	}
+	return ""
+}
+
+// protectionReason explains why path cannot be written (or, with removal,
+// deleted or moved away), or returns "" when nothing protects it. Removing a
+// directory is also refused when it contains a path protected by an absolute
+// rule. Trash directories are always protected: only the backup tool manages
+// them.
+func (e *UltraFastEngine) protectionReason(path string, removal bool) string {
+	if dir := e.trashDirHolding(path); dir != "" {
+		return fmt.Sprintf("path is inside the trash %s; use backup list_trash, restore_trash or purge_trash", dir)
+	}
+	if rule := e.ProtectedRule(path); rule != "" {
+		return fmt.Sprintf("path is write-protected (rule %q)", rule)
+	}
+	if !removal || len(e.config.ProtectedPaths) == 0 {
+		return ""
+	}
+	abs := protectedRuleKey(protectedAbs(path))
+	for _, rule := range e.config.ProtectedPaths {
+		if isAbsoluteRule(rule) && pathWithin(protectedRuleKey(rule), abs) {
+			return fmt.Sprintf("path contains write-protected path (rule %q)", rule)
+		}
+	}
+	return ""
+}
+
+// CheckWritable fails when path is write-protected. Call it wherever a
+// mutating operation checks IsPathAllowed for the path it writes.
+func (e *UltraFastEngine) CheckWritable(op, path string) error {
+	if reason := e.protectionReason(path, false); reason != "" {
+		return &PathError{Op: op, Path: path, Err: errors.New(reason)}
+	}
+	return nil
+}
+
+// CheckRemovable is CheckWritable for the source of deletes, moves and renames
+func (e *UltraFastEngine) CheckRemovable(op, path string) error {
+	if reason := e.protectionReason(path, true); reason != "" {
+		return &PathError{Op: op, Path: path, Err: errors.New(reason)}
+	}
+	return nil
+}
+
+// ProtectedPathRules lists the write-protection rules
+func (e *UltraFastEngine) ProtectedPathRules() []ProtectedPathRule {
+	rules := make([]ProtectedPathRule, 0, len(e.config.ProtectedPaths))
+	for _, rule := range e.config.ProtectedPaths {
+		rules = append(rules, ProtectedPathRule{Rule: rule, Absolute: isAbsoluteRule(rule)})
+	}
+	return rules
+}
+
+// applyProtectedRisk notes write protection in a plan-mode analysis: the
+// operation would be refused, whatever its risk level
+func (e *UltraFastEngine) applyProtectedRisk(analysis *ChangeAnalysis, removal bool) {
+	if reason := e.protectionReason(analysis.FilePath, removal); reason != "" {
+		analysis.RiskLevel = "critical"
+		analysis.RiskFactors = append(analysis.RiskFactors, reason+": the operation will be refused")
+	}
+}
+
+// PathRuleCheck tells whether one path may be read and written
+type PathRuleCheck struct {
+	Path     string `json:"path"`
+	Allowed  bool   `json:"allowed"`
+	Writable bool   `json:"writable"`
+	Rule     string `json:"rule,omitempty"` // protecting rule when not writable
+}
+
+// PathRulesListing is the allowed_paths rules result
+type PathRulesListing struct {
+	AllowedPaths []string            `json:"allowed_paths"` // empty = access control off
+	Protected    []ProtectedPathRule `json:"protected"`
+	Check        *PathRuleCheck      `json:"check,omitempty"`
+}
+
+// PathRules lists the access and write-protection rules and, when path is
+// set, how they apply to it
+func (e *UltraFastEngine) PathRules(path string) PathRulesListing {
+	l := PathRulesListing{AllowedPaths: e.GetAllowedPaths(), Protected: e.ProtectedPathRules()}
+	if path != "" {
+		path = NormalizePath(path)
+		l.Check = &PathRuleCheck{Path: path, Allowed: e.IsPathAllowed(path), Rule: e.ProtectedRule(path)}
+		l.Check.Writable = l.Check.Allowed && l.Check.Rule == ""
+	}
+	return l
+}
+
+// FormatPathRules renders allowed_paths rules output
+func FormatPathRules(l PathRulesListing, compact bool) string {
+	var b strings.Builder
+	if compact {
+		b.WriteString(fmt.Sprintf("allowed:%d protected:%d", len(l.AllowedPaths), len(l.Protected)))
+		for _, r := range l.Protected {
+			b.WriteString("\nP " + r.Rule)
+		}
+	} else {
+		if len(l.AllowedPaths) == 0 {
+			b.WriteString("Allowed paths: none (access control off, every path is allowed)")
+		} else {
+			b.WriteString(fmt.Sprintf("Allowed paths (%d):", len(l.AllowedPaths)))
+			for _, p := range l.AllowedPaths {
+				b.WriteString("\n  " + p)
+			}
+		}
+		if len(l.Protected) == 0 {
+			b.WriteString("\nWrite-protected: none (start the server with --protected-paths to add rules)")
+		} else {
+			b.WriteString(fmt.Sprintf("\nWrite-protected (%d):", len(l.Protected)))
+			for _, r := range l.Protected {
+				kind := "name"
+				if r.Absolute {
+					kind = "path"
+				}
+				b.WriteString(fmt.Sprintf("\n  %s  [%s]", r.Rule, kind))
+			}
+		}
+	}
+	if c := l.Check; c != nil {
+		switch {
+		case !c.Allowed:
+			b.WriteString(fmt.Sprintf("\n%s: access denied (outside the allowed paths)", c.Path))
+		case !c.Writable:
+			b.WriteString(fmt.Sprintf("\n%s: read-only (rule %q)", c.Path, c.Rule))
+		default:
+			b.WriteString(fmt.Sprintf("\n%s: writable", c.Path))
+		}
+	}
+	return b.String()
+}
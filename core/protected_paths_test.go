@@ -0,0 +1,105 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mcp/filesystem-ultra/cache"
+)
+
+func newProtectedEngine(t *testing.T, dir string, rules ...string) *UltraFastEngine {
+	t.Helper()
+	c, err := cache.NewIntelligentCache(1024 * 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine, err := NewUltraFastEngine(&Config{Cache: c, AllowedPaths: []string{dir}, ParallelOps: 2, BackupDir: t.TempDir(),
+		ProtectedPaths: rules})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { engine.Close(); c.Close() })
+	return engine
+}
+
+func TestProtectedPaths_RuleMatching(t *testing.T) {
+	dir := t.TempDir()
+	vendor := filepath.Join(dir, "vendor")
+	engine := newProtectedEngine(t, dir, vendor, "*.lock", "migrations/**")
+
+	for path, want := range map[string]string{
+		vendor:                                    vendor,
+		filepath.Join(vendor, "lib", "a.go"):      vendor,
+		filepath.Join(dir, "vendored.go"):         "",
+		filepath.Join(dir, "yarn.lock"):           "*.lock",
+		filepath.Join(dir, "migrations"):          "migrations/**",
+		filepath.Join(dir, "migrations", "1.sql"): "migrations/**",
+		filepath.Join(dir, "src", "main.go"):      "",
+	} {
+		if got := engine.ProtectedRule(path); got != want {
+			t.Errorf("ProtectedRule(%s) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestProtectedPaths_MutationsRefused(t *testing.T) {
+	dir := t.TempDir()
+	locked := filepath.Join(dir, "proj", "locked")
+	os.MkdirAll(locked, 0755)
+	target := filepath.Join(locked, "a.txt")
+	os.WriteFile(target, []byte("original"), 0644)
+	engine := newProtectedEngine(t, dir, locked)
+	ctx := context.Background()
+
+	for name, err := range map[string]error{
+		"write":  engine.WriteFileContent(ctx, target, "changed"),
+		"delete": engine.DeleteFile(ctx, target),
+		"move":   engine.MoveFile(ctx, target, filepath.Join(dir, "b.txt")),
+		"copy":   engine.CopyFile(ctx, filepath.Join(dir, "x"), filepath.Join(locked, "new.txt")),
+		"mkdir":  engine.CreateDirectory(ctx, filepath.Join(locked, "sub")),
+		// the parent contains a protected directory
+		"delete parent": engine.DeleteFile(ctx, filepath.Join(dir, "proj")),
+	} {
+		if err == nil || !strings.Contains(err.Error(), "write-protected") {
+			t.Errorf("%s: err = %v, want write-protected", name, err)
+		}
+	}
+	if _, err := engine.EditFile(ctx, target, "original", "changed", false, false, false); err == nil || !strings.Contains(err.Error(), `rule "`+locked+`"`) {
+		t.Errorf("edit: err = %v, want the matching rule", err)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "original" {
+		t.Fatalf("protected file changed: %q", data)
+	}
+	if _, err := engine.ReadFileContent(ctx, target); err != nil {
+		t.Errorf("protected file not readable: %v", err)
+	}
+	if err := engine.WriteFileContent(ctx, filepath.Join(dir, "free.txt"), "ok"); err != nil {
+		t.Errorf("unprotected write: %v", err)
+	}
+}
+
+func TestProtectedPaths_AnalyzeAndRules(t *testing.T) {
+	dir := t.TempDir()
+	lock := filepath.Join(dir, "go.sum")
+	os.WriteFile(lock, []byte("x\n"), 0644)
+	engine := newProtectedEngine(t, dir, "go.sum")
+
+	analysis, err := engine.AnalyzeWriteChange(context.Background(), lock, "y\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if analysis.RiskLevel != "critical" || !strings.Contains(strings.Join(analysis.RiskFactors, "\n"), "write-protected") {
+		t.Errorf("analysis = %s %v, want critical + write-protected", analysis.RiskLevel, analysis.RiskFactors)
+	}
+
+	rules := engine.PathRules(lock)
+	if len(rules.Protected) != 1 || rules.Check == nil || rules.Check.Writable || rules.Check.Rule != "go.sum" {
+		t.Fatalf("PathRules = %+v", rules)
+	}
+	if out := FormatPathRules(rules, false); !strings.Contains(out, `read-only (rule "go.sum")`) {
+		t.Errorf("text output:\n%s", out)
+	}
+}
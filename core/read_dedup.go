@@ -18,9 +18,10 @@
 var diskReadCount atomic.Int64
 
 // readFileBytesDeduped returns file content from cache or disk, deduplicating
-// concurrent loads for the same path via singleflight.
+// concurrent loads for the same path via singleflight. Callers have already
+// made the counted cache lookup, so the re-checks here use PeekFile.
 func (e *UltraFastEngine) readFileBytesDeduped(ctx context.Context, path string) ([]byte, error) {
-	if cached, hit := e.cache.GetFile(path); hit {
+	if cached, hit := e.cache.PeekFile(path); hit {
 		return cached, nil
 	}
 
@@ -30,7 +31,7 @@ type readResult struct {
 	}
 
 	v, err, _ := readFlight.Do(path, func() (interface{}, error) {
-		if cached, hit := e.cache.GetFile(path); hit {
+		if cached, hit := e.cache.PeekFile(path); hit {
 			return readResult{data: cached}, nil
 		}
 
@@ -44,6 +45,7 @@ type readResult struct {
 			return nil, &PathError{Op: "read", Path: path, Err: readErr}
 		}
 
+		e.cache.NoteDiskRead(len(data))
 		e.cache.SetFile(path, data)
 		e.cache.TrackAccess(path)
 		return readResult{data: data}, nil
@@ -65,16 +67,23 @@ func forgetReadFlight(path string) {
 	readFlight.Forget(path)
 }
 
-// invalidateFileReadCache removes cached bytes and forgets read dedup for path.
+// invalidateFileReadCache removes cached bytes, forgets read dedup for path
+// and reports the change to the OnPathChanged listener.
 // It is intentionally file-only for move/delete call sites that manage several
 // source/destination directory caches explicitly.
 func (e *UltraFastEngine) invalidateFileReadCache(path string) {
+	if e != nil {
+		e.watcher.noteSelfWrite(path)
+	}
 	if e == nil || e.cache == nil || path == "" {
 		forgetReadFlight(path)
+		e.notifyPathChanged(path)
 		return
 	}
 	e.cache.InvalidateFile(path)
+	e.cache.InvalidateMissing(path)
 	forgetReadFlight(path)
+	e.notifyPathChanged(path)
 }
 
 // invalidateMutatedPath removes every cache entry whose value can be stale
@@ -91,7 +100,26 @@ func (e *UltraFastEngine) invalidateMutatedPath(path string) {
 		return
 	}
 	e.cache.InvalidateMetadata(path)
-	e.cache.InvalidateDirectory(filepath.Dir(path))
+	e.cache.InvalidateMetadata(lineIndexKey(path))
+	e.invalidateDirListing(filepath.Dir(path))
+}
+
+// dirListingJSONKey is the cache key of a directory's JSON listing; the text
+// listing is cached under the directory path itself and the raw entries used
+// by list_directory views under dirListingEntriesKey.
+func dirListingJSONKey(dir string) string {
+	return dir + "::json"
+}
+
+// invalidateDirListing drops every cached rendering of dir's listing. Call it
+// for each directory whose entries a mutation adds, removes or renames.
+func (e *UltraFastEngine) invalidateDirListing(dir string) {
+	if e == nil || e.cache == nil || dir == "" {
+		return
+	}
+	e.cache.InvalidateDirectory(dir)
+	e.cache.InvalidateDirectory(dirListingJSONKey(dir))
+	e.cache.InvalidateDirectory(dirListingEntriesKey(dir))
 }
 
 // extractLineRangeFromBytes builds the same response shape as ReadFileRange's
@@ -0,0 +1,247 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// analyze_directory recent defaults
+const (
+	DefaultRecentWithin = time.Hour
+	DefaultRecentLimit  = 50
+)
+
+// RecentFile is one analyze_directory recent entry; Path is relative to the
+// searched directory
+type RecentFile struct {
+	Path     string    `json:"path"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+// RecentlyModified is the analyze_directory recent response
+type RecentlyModified struct {
+	Path   string       `json:"path"`
+	Within string       `json:"within"`
+	Since  time.Time    `json:"since"`
+	Files  []RecentFile `json:"files"` // newest first
+	// Matched counts every file modified in the window; Files stops at the limit
+	Matched    int   `json:"matched"`
+	Truncated  bool  `json:"truncated,omitempty"`
+	Scanned    int   `json:"scanned"`
+	Skipped    int   `json:"skipped,omitempty"` // unreadable entries
+	DurationMs int64 `json:"duration_ms"`
+}
+
+// ParseWithin parses a analyze_directory recent window: a Go duration ("90m",
+// "1h30m") or a number of days ("7d")
+func ParseWithin(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return DefaultRecentWithin, nil
+	}
+	var d time.Duration
+	var err error
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		var n float64
+		n, err = strconv.ParseFloat(days, 64)
+		d = time.Duration(n * float64(24*time.Hour))
+	} else {
+		d, err = time.ParseDuration(s)
+	}
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid within %q: use a duration like 30m, 1h, 24h or 7d", s)
+	}
+	return d, nil
+}
+
+// recentWalk collects one subtree; each worker owns one
+type recentWalk struct {
+	files   []RecentFile
+	scanned int
+	skipped int
+}
+
+// RecentlyModified lists the regular files under path modified within the
+// window, newest first, up to limit (0 or more than MaxSearchResults =
+// MaxSearchResults). Each immediate subdirectory is walked on the worker
+// pool; the default excludes (.git, node_modules, ...) and exclude globs are
+// skipped and symlinks are not followed.
+func (e *UltraFastEngine) RecentlyModified(ctx context.Context, path string, within time.Duration, limit int, exclude []string) (*RecentlyModified, error) {
+	path = NormalizePath(path)
+	if err := e.acquireOperation(ctx, "search"); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	defer e.releaseOperation("search", start)
+
+	if !e.IsPathAllowed(path) {
+		return nil, e.AccessDeniedError("recently_modified", path)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, &PathError{Op: "recently_modified", Path: path, Err: err}
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("not a directory: %s", path)
+	}
+	if within <= 0 {
+		within = DefaultRecentWithin
+	}
+	if max := e.config.MaxSearchResults; limit <= 0 || (max > 0 && limit > max) {
+		limit = max
+	}
+	root, err := filepath.Abs(path)
+	if err != nil {
+		root = path
+	}
+	since := start.Add(-within)
+	excludes := newSyncExcludes(exclude, nil)
+
+	// The root's own files are checked here; each subdirectory is a task
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, &PathError{Op: "recently_modified", Path: root, Err: err}
+	}
+	top := &recentWalk{}
+	var walks []*recentWalk
+	var wg sync.WaitGroup
+	for _, entry := range entries {
+		p := filepath.Join(root, entry.Name())
+		if excludes.excluded(root, p, entry.IsDir()) {
+			continue
+		}
+		if !entry.IsDir() {
+			top.add(root, p, entry, since)
+			continue
+		}
+		w := &recentWalk{}
+		walks = append(walks, w)
+		task := func() {
+			defer wg.Done()
+			w.walk(ctx, root, p, since, excludes)
+		}
+		wg.Add(1)
+		if e.workerPool == nil || e.workerPool.Submit(task) != nil {
+			task()
+		}
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	res := &RecentlyModified{Path: root, Within: within.String(), Since: since, Files: []RecentFile{}}
+	for _, w := range append(walks, top) {
+		res.Files = append(res.Files, w.files...)
+		res.Scanned += w.scanned
+		res.Skipped += w.skipped
+	}
+	sort.Slice(res.Files, func(i, j int) bool {
+		a, b := res.Files[i], res.Files[j]
+		if !a.Modified.Equal(b.Modified) {
+			return a.Modified.After(b.Modified)
+		}
+		return a.Path < b.Path
+	})
+	res.Matched = len(res.Files)
+	if limit > 0 && len(res.Files) > limit {
+		res.Files = res.Files[:limit]
+		res.Truncated = true
+	}
+	res.DurationMs = time.Since(start).Milliseconds()
+	return res, nil
+}
+
+// walk collects the subtree at dir
+func (w *recentWalk) walk(ctx context.Context, root, dir string, since time.Time, excludes *syncExcludes) {
+	filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			w.skipped++
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if excludes.excluded(root, p, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			w.add(root, p, d, since)
+		}
+		return nil
+	})
+}
+
+// add records p when it is a regular file modified after since
+func (w *recentWalk) add(root, p string, d fs.DirEntry, since time.Time) {
+	if !d.Type().IsRegular() {
+		return
+	}
+	info, err := d.Info()
+	if err != nil {
+		w.skipped++
+		return
+	}
+	w.scanned++
+	if info.ModTime().After(since) {
+		rel, _ := filepath.Rel(root, p)
+		w.files = append(w.files, RecentFile{Path: filepath.ToSlash(rel), Size: info.Size(), Modified: info.ModTime()})
+	}
+}
+
+// FormatRecentlyModified renders a RecentlyModified as text, with ages
+// relative to now
+func FormatRecentlyModified(r *RecentlyModified, compact bool, now time.Time) string {
+	if r.Matched == 0 {
+		return fmt.Sprintf("No files modified in the last %s under %s (%d files scanned)", r.Within, r.Path, r.Scanned)
+	}
+	age := func(t time.Time) string {
+		d := now.Sub(t)
+		switch {
+		case d < time.Minute:
+			return fmt.Sprintf("%ds ago", int(d.Seconds()))
+		case d < time.Hour:
+			return fmt.Sprintf("%dm ago", int(d.Minutes()))
+		case d < 48*time.Hour:
+			return fmt.Sprintf("%dh ago", int(d.Hours()))
+		}
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+	var sb strings.Builder
+	if compact {
+		sb.WriteString(fmt.Sprintf("%s | %d modified in %s |", r.Path, r.Matched, r.Within))
+		for _, f := range r.Files {
+			sb.WriteString(fmt.Sprintf(" %s(%s)", f.Path, age(f.Modified)))
+		}
+		if r.Truncated {
+			sb.WriteString(fmt.Sprintf(" | +%d more", r.Matched-len(r.Files)))
+		}
+		return sb.String()
+	}
+	sb.WriteString(fmt.Sprintf("%d files modified in the last %s under %s (%d scanned, %dms)\n", r.Matched, r.Within, r.Path, r.Scanned, r.DurationMs))
+	for _, f := range r.Files {
+		sb.WriteString(fmt.Sprintf("%-8s %9s  %s  %s\n", age(f.Modified), formatSize(f.Size), f.Modified.Local().Format("2006-01-02 15:04:05"), f.Path))
+	}
+	if r.Truncated {
+		sb.WriteString(fmt.Sprintf("… %d more (raise limit or narrow path)\n", r.Matched-len(r.Files)))
+	}
+	if r.Skipped > 0 {
+		sb.WriteString(fmt.Sprintf("⚠️  %d unreadable entries skipped\n", r.Skipped))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,67 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecentlyModified_NewestFirstWithinWindow(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	now := time.Now()
+	for rel, age := range map[string]time.Duration{
+		"README.md":               10 * time.Minute,
+		"src/main.go":             time.Minute,
+		"src/pkg/util.go":         30 * time.Minute,
+		"src/old.go":              3 * time.Hour,
+		"docs/guide.md":           20 * 24 * time.Hour,
+		"node_modules/dep/a.js":   time.Minute,
+		"build/out.log":           2 * time.Minute,
+		"build/generated/gen.txt": 5 * time.Minute,
+	} {
+		full := filepath.Join(dir, rel)
+		os.MkdirAll(filepath.Dir(full), 0755)
+		os.WriteFile(full, []byte(rel), 0644)
+		mtime := now.Add(-age)
+		os.Chtimes(full, mtime, mtime)
+	}
+
+	res, err := engine.RecentlyModified(context.Background(), dir, time.Hour, 0, []string{"*.log"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range res.Files {
+		got = append(got, f.Path)
+	}
+	if want := "src/main.go,build/generated/gen.txt,README.md,src/pkg/util.go"; strings.Join(got, ",") != want {
+		t.Errorf("files = %v, want %s", got, want)
+	}
+	if res.Matched != 4 || res.Scanned != 6 || res.Truncated {
+		t.Errorf("res = %+v", res)
+	}
+
+	res, _ = engine.RecentlyModified(context.Background(), dir, 24*time.Hour, 2, nil)
+	if res.Matched != 6 || len(res.Files) != 2 || !res.Truncated || res.Files[0].Path != "src/main.go" {
+		t.Errorf("limited = %+v", res)
+	}
+	if text := FormatRecentlyModified(res, false, now); !strings.Contains(text, "1m ago") || !strings.Contains(text, "… 4 more") {
+		t.Errorf("text:\n%s", text)
+	}
+}
+
+func TestParseWithin(t *testing.T) {
+	for in, want := range map[string]time.Duration{"": time.Hour, "30m": 30 * time.Minute, "24h": 24 * time.Hour, "7d": 7 * 24 * time.Hour, "1.5d": 36 * time.Hour} {
+		if got, err := ParseWithin(in); err != nil || got != want {
+			t.Errorf("ParseWithin(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"soon", "-1h", "0", "d"} {
+		if _, err := ParseWithin(in); err == nil {
+			t.Errorf("ParseWithin(%q) accepted", in)
+		}
+	}
+}
@@ -99,9 +99,9 @@ func (rt *RegexTransformer) Transform(ctx context.Context, config RegexTransform
 	// Create processing function based on mode
 	var processFunc ProcessorFunc
 	if config.Mode == ModeSequential {
-		processFunc = rt.createSequentialProcessor(config, result)
+		processFunc = rt.createSequentialProcessor(ctx, config, result)
 	} else {
-		processFunc = rt.createParallelProcessor(config, result)
+		processFunc = rt.createParallelProcessor(ctx, config, result)
 	}
 
 	// Use LargeFileProcessor to handle the actual file processing
@@ -175,12 +175,16 @@ func (rt *RegexTransformer) compilePatterns(config *RegexTransformConfig) error
 }
 
 // createSequentialProcessor creates a processor that applies patterns in sequence
-func (rt *RegexTransformer) createSequentialProcessor(config RegexTransformConfig, result *RegexTransformResult) ProcessorFunc {
+func (rt *RegexTransformer) createSequentialProcessor(ctx context.Context, config RegexTransformConfig, result *RegexTransformResult) ProcessorFunc {
 	return func(content string, metadata ProcessMetadata) (string, error) {
 		current := content
 
 		// Apply each pattern to the result of the previous one
 		for _, pattern := range config.Patterns {
+			// Stop between patterns once the caller's deadline or cancel hits
+			if err := ctx.Err(); err != nil {
+				return "", err
+			}
 			patternResult := PatternResult{
 				Pattern: pattern.Pattern,
 			}
@@ -217,13 +221,17 @@ func (rt *RegexTransformer) createSequentialProcessor(config RegexTransformConfi
 }
 
 // createParallelProcessor creates a processor that applies all patterns to the original content
-func (rt *RegexTransformer) createParallelProcessor(config RegexTransformConfig, result *RegexTransformResult) ProcessorFunc {
+func (rt *RegexTransformer) createParallelProcessor(ctx context.Context, config RegexTransformConfig, result *RegexTransformResult) ProcessorFunc {
 	return func(content string, metadata ProcessMetadata) (string, error) {
 		current := content
 
 		// Apply each pattern independently to the original content
 		// Then merge results (later patterns take precedence on conflicts)
 		for _, pattern := range config.Patterns {
+			// Stop between patterns once the caller's deadline or cancel hits
+			if err := ctx.Err(); err != nil {
+				return "", err
+			}
 			patternResult := PatternResult{
 				Pattern: pattern.Pattern,
 			}
@@ -0,0 +1,185 @@
+package core
+
+import (
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MCP resources expose the files under the allowed paths, listed up to
+// Config.ResourceDepth levels deep. Reads go through ReadFileContent, so
+// access control, hooks and the cache apply exactly as for read_file.
+//
+// A resource URI is "file://" plus the absolute, slash-separated path
+// ("file:///home/me/project/main.go", "file:///C:/project/main.go").
+
+// DefaultResourceDepth is the --resource-depth default: files in an allowed
+// directory and two levels below it
+const DefaultResourceDepth = 3
+
+// maxResourceFiles bounds the resource listing
+const maxResourceFiles = 1000
+
+// ResourceFile is one listed resource
+type ResourceFile struct {
+	URI      string `json:"uri"`
+	Path     string `json:"path"`
+	Size     int64  `json:"size"`
+	MIMEType string `json:"mime_type"`
+}
+
+// ResourceURI returns the resource URI of an absolute path
+func ResourceURI(path string) string {
+	p := filepath.ToSlash(filepath.Clean(path))
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p // Windows drive path
+	}
+	return "file://" + p
+}
+
+// ResourcePath returns the path a resource URI names. Percent-encoded URIs
+// are accepted.
+func ResourcePath(uri string) (string, error) {
+	rest, ok := strings.CutPrefix(uri, "file://")
+	if !ok || rest == "" {
+		return "", fmt.Errorf("unsupported resource URI %q: expected file:///<absolute path>", uri)
+	}
+	if unescaped, err := url.PathUnescape(rest); err == nil {
+		rest = unescaped
+	}
+	// "/C:/dir" → "C:/dir"
+	if len(rest) > 2 && rest[0] == '/' && rest[2] == ':' {
+		rest = rest[1:]
+	}
+	return NormalizePath(filepath.FromSlash(rest)), nil
+}
+
+// ResourceMIMEType guesses a file's MIME type from its extension, text/plain
+// when unknown
+func ResourceMIMEType(path string) string {
+	if t := mime.TypeByExtension(filepath.Ext(path)); t != "" {
+		return t
+	}
+	return "text/plain"
+}
+
+// resourceExcludes are the default sync excludes, ResourceExcludes and the
+// .gitignore files
+func (e *UltraFastEngine) resourceExcludes() *syncExcludes {
+	return newSyncExcludes(e.config.ResourceExcludes, e.resourceIgnores)
+}
+
+// ResourceFiles lists the files exposed as resources: regular files at most
+// ResourceDepth levels below an allowed path, skipping excluded ones, up to
+// maxResourceFiles. Empty when ResourceDepth is 0 or no allowed paths are
+// configured.
+func (e *UltraFastEngine) ResourceFiles() []ResourceFile {
+	depth := e.config.ResourceDepth
+	if depth <= 0 {
+		return nil
+	}
+	excludes := e.resourceExcludes()
+	seen := make(map[string]bool)
+	var files []ResourceFile
+	for _, root := range e.GetAllowedPaths() {
+		root = filepath.Clean(root)
+		filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if len(files) >= maxResourceFiles {
+				return fs.SkipAll
+			}
+			if err != nil || p == root {
+				return nil
+			}
+			rel, _ := filepath.Rel(root, p)
+			level := strings.Count(rel, string(filepath.Separator)) + 1
+			if excludes.excluded(root, p, d.IsDir()) {
+				if d.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() {
+				if level >= depth {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() || seen[p] {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			seen[p] = true
+			files = append(files, ResourceFile{URI: ResourceURI(p), Path: p, Size: info.Size(), MIMEType: ResourceMIMEType(p)})
+			return nil
+		})
+	}
+	return files
+}
+
+// ResourceFor reports whether path belongs in the resource listing (an
+// existing regular file within ResourceDepth of an allowed path and not
+// excluded) and returns its entry
+func (e *UltraFastEngine) ResourceFor(path string) (ResourceFile, bool) {
+	depth := e.config.ResourceDepth
+	if depth <= 0 || !e.IsPathAllowed(path) {
+		return ResourceFile{}, false
+	}
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return ResourceFile{}, false
+	}
+	excludes := e.resourceExcludes()
+	for _, root := range e.GetAllowedPaths() {
+		root = filepath.Clean(root)
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		parts := strings.Split(rel, string(filepath.Separator))
+		if len(parts) > depth {
+			continue
+		}
+		// The file and every directory between it and the root must be listed
+		excluded := excludes.excluded(root, path, false)
+		for i := 1; i < len(parts) && !excluded; i++ {
+			excluded = excludes.excluded(root, filepath.Join(root, filepath.Join(parts[:i]...)), true)
+		}
+		if !excluded {
+			return ResourceFile{URI: ResourceURI(path), Path: path, Size: info.Size(), MIMEType: ResourceMIMEType(path)}, true
+		}
+	}
+	return ResourceFile{}, false
+}
+
+// OnPathChanged registers fn to be called with the absolute path of every
+// file or directory the engine writes, edits, moves, copies or deletes (nil
+// removes it). fn runs on the mutating goroutine and must not block.
+func (e *UltraFastEngine) OnPathChanged(fn func(path string)) {
+	if fn == nil {
+		e.changeListener.Store(nil)
+		return
+	}
+	e.changeListener.Store(&fn)
+}
+
+// notifyPathChanged calls the OnPathChanged listener
+func (e *UltraFastEngine) notifyPathChanged(path string) {
+	if e == nil || path == "" {
+		return
+	}
+	fn := e.changeListener.Load()
+	if fn == nil {
+		return
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	(*fn)(path)
+}
@@ -0,0 +1,86 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestResources_ListingDepthAndExcludes(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	engine.config.ResourceDepth = 2
+	engine.config.ResourceExcludes = []string{"*.log"}
+	for _, rel := range []string{"a.go", "app.log", "sub/b.go", "sub/deep/c.go", "node_modules/x/i.js", "ignored/d.go"} {
+		p := filepath.Join(dir, filepath.FromSlash(rel))
+		os.MkdirAll(filepath.Dir(p), 0755)
+		os.WriteFile(p, []byte("x"), 0644)
+	}
+	os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("ignored/\n"), 0644)
+
+	var got []string
+	for _, f := range engine.ResourceFiles() {
+		rel, _ := filepath.Rel(dir, f.Path)
+		got = append(got, filepath.ToSlash(rel))
+	}
+	sort.Strings(got)
+	if want := []string{".gitignore", "a.go", "sub/b.go"}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("resources = %v, want %v", got, want)
+	}
+
+	if _, ok := engine.ResourceFor(filepath.Join(dir, "sub", "b.go")); !ok {
+		t.Error("sub/b.go not a resource")
+	}
+	for _, rel := range []string{"sub/deep/c.go", "app.log", "ignored/d.go", "missing.go"} {
+		if _, ok := engine.ResourceFor(filepath.Join(dir, filepath.FromSlash(rel))); ok {
+			t.Errorf("%s is a resource", rel)
+		}
+	}
+
+	engine.config.ResourceDepth = 0
+	if files := engine.ResourceFiles(); len(files) != 0 {
+		t.Errorf("resources with depth 0: %d", len(files))
+	}
+}
+
+func TestResources_URIRoundTripAndChangeListener(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	path := filepath.Join(dir, "my file.txt")
+
+	uri := ResourceURI(path)
+	if !strings.HasPrefix(uri, "file:///") {
+		t.Fatalf("uri = %q", uri)
+	}
+	for _, u := range []string{uri, strings.ReplaceAll(uri, " ", "%20")} {
+		if got, err := ResourcePath(u); err != nil || got != path {
+			t.Errorf("ResourcePath(%q) = %q, %v", u, got, err)
+		}
+	}
+	if runtime.GOOS == "windows" {
+		if got, _ := ResourcePath("file:///C:/p/a.go"); got != `C:\p\a.go` {
+			t.Errorf("windows path = %q", got)
+		}
+	}
+	if _, err := ResourcePath("https://example.com/a"); err == nil {
+		t.Error("non-file URI accepted")
+	}
+
+	var changed []string
+	engine.OnPathChanged(func(p string) { changed = append(changed, p) })
+	ctx := context.Background()
+	if err := engine.WriteFileContent(ctx, path, "one"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := engine.EditFile(ctx, path, "one", "two", false, false, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.DeleteFile(ctx, path); err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) < 3 || changed[0] != path {
+		t.Errorf("change notifications = %v", changed)
+	}
+}
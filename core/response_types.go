@@ -0,0 +1,789 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/mcp/filesystem-ultra/cache"
+)
+
+// Structured responses. With --json-responses (or format:"json" on a call)
+// these tools return one JSON object as the tool text instead of prose:
+//
+//	get_file_info                 FileInfoResponse (FileInfoBatchResponse for paths)
+//	list_directory                DirectoryListing (output_format:"json")
+//	analyze_directory tree        DirectoryTree
+//	analyze_directory size        DirectorySize
+//	analyze_directory disk_usage  DiskUsageResponse
+//	analyze_directory duplicates  DuplicateReport
+//	analyze_directory compare     DirComparison
+//	analyze_directory watch       DirChanges
+//	analyze_directory recent      RecentlyModified
+//	search_files                  FileSearchResponse (filename search)
+//	copy_file                     CopyResult
+//	server_info stats             ServerStatsResponse
+//	server_info config            ServerConfigResponse
+//	edit_file, multi_edit         the structuredContent payload (editFileOutputSchema)
+//	batch_operations              BatchResult, BatchRenameResult, PipelineResult or OrganizeResult (organize_json)
+//	backup list                   BackupListResponse
+//
+// The prose output of these tools is rendered from the same structs
+// (FormatFileInfo, FormatPerformanceStats, FormatBackupList), so the text and
+// JSON views cannot drift. Errors stay plain-text tool errors in both modes.
+
+// Response formats accepted by the per-call format parameter
+const (
+	ResponseFormatText = "text"
+	ResponseFormatJSON = "json"
+)
+
+// JSONResponse reports whether a call asking for format ("" = server default,
+// "text" or "json") gets a JSON response
+func (e *UltraFastEngine) JSONResponse(format string) bool {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case ResponseFormatJSON:
+		return true
+	case ResponseFormatText:
+		return false
+	}
+	return e.config.JSONResponses
+}
+
+// FileInfoResponse is get_file_info for one path
+type FileInfoResponse struct {
+	Path        string           `json:"path"`
+	AbsPath     string           `json:"abs_path,omitempty"`
+	Name        string           `json:"name,omitempty"`
+	Type        string           `json:"type,omitempty"` // "file", "dir" or "symlink"
+	Size        int64            `json:"size"`           // bytes; 0 for directories
+	Mode        string           `json:"mode,omitempty"`
+	Permissions string           `json:"permissions,omitempty"` // octal, e.g. "0644"
+	Owner       string           `json:"owner,omitempty"`       // Unix: user name, or uid when unknown
+	Group       string           `json:"group,omitempty"`       // Unix: group name, or gid when unknown
+	Attributes  []string         `json:"attributes,omitempty"`  // Windows: hidden, readonly, system
+	LinkTarget  string           `json:"link_target,omitempty"` // symlinks: where the link points, not followed
+	Modified    time.Time        `json:"modified,omitempty"`
+	Contents    *DirectoryCounts `json:"contents,omitempty"` // directories only
+	MIME        string           `json:"mime,omitempty"`     // files: sniffed content type, see DetectContentType
+	IsBinary    bool             `json:"is_binary,omitempty"`
+	Lines       *int             `json:"lines,omitempty"`       // text files, with include_stats (same as stats.lines)
+	Stats       *FileStats       `json:"stats,omitempty"`       // include_stats: the file, or totals over a directory
+	StatsFiles  []FileStats      `json:"stats_files,omitempty"` // include_stats on a directory: one row per text file
+	Exists      bool             `json:"exists"`
+	SHA256      string           `json:"sha256,omitempty"`     // files, with include_hash
+	GitStatus   string           `json:"git_status,omitempty"` // include_git inside a repository: clean, M, A, R, C, U, ?? or !!
+	Error       string           `json:"error,omitempty"`      // batch entries that failed, or a failed hash/line count
+}
+
+// FileInfoOptions selects the get_file_info fields that read file content
+type FileInfoOptions struct {
+	Hash     bool     // SHA-256 of regular files
+	Stats    bool     // wc-style FileStats of text files; directories aggregate them
+	Patterns []string // with Stats: matches counted per pattern
+	Git      bool     // git_status of paths inside a repository
+
+	gitMemo *gitStatusMemo // FilesInfo: one git status per repository
+}
+
+// gitStatus returns the git status index for path, nil outside a repository
+func (o FileInfoOptions) gitStatus(ctx context.Context, path string) *GitStatusIndex {
+	if o.gitMemo != nil {
+		return o.gitMemo.load(ctx, path)
+	}
+	ix, _ := LoadGitStatus(ctx, path)
+	return ix
+}
+
+// DirectoryCounts counts a directory's direct children
+type DirectoryCounts struct {
+	Files int `json:"files"`
+	Dirs  int `json:"dirs"`
+}
+
+// FileInfoBatchResponse is get_file_info with paths
+type FileInfoBatchResponse struct {
+	Files []FileInfoResponse `json:"files"`
+	Count int                `json:"count"` // paths asked for
+	Found int                `json:"found"` // paths that exist and are readable
+	// Omitted counts trailing entries dropped to stay under MaxResponseSize
+	Omitted int `json:"omitted,omitempty"`
+}
+
+// FileInfo returns metadata about a file or directory
+func (e *UltraFastEngine) FileInfo(ctx context.Context, path string) (FileInfoResponse, error) {
+	return e.FileInfoWith(ctx, path, FileInfoOptions{})
+}
+
+// FileInfoWith is FileInfo plus the content-reading fields selected by opts.
+// A symlink is reported as such, with its target, instead of being followed.
+func (e *UltraFastEngine) FileInfoWith(ctx context.Context, path string, opts FileInfoOptions) (FileInfoResponse, error) {
+	// Normalize path (handles WSL ↔ Windows conversion)
+	path = NormalizePath(path)
+	if err := e.acquireOperation(ctx, "fileinfo"); err != nil {
+		return FileInfoResponse{}, err
+	}
+	start := time.Now()
+	defer e.releaseOperation("fileinfo", start)
+
+	if !e.IsPathAllowed(path) {
+		return FileInfoResponse{}, fmt.Errorf("access denied: path '%s' is not in allowed paths%s", path, e.AllowedDirsSuffix())
+	}
+	if err, hit := e.cachedMissing(path, "stat"); hit {
+		return FileInfoResponse{}, err
+	}
+
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		err = fmt.Errorf("file or directory does not exist: %s", path)
+		e.rememberMissing(path, "stat", err)
+		return FileInfoResponse{}, err
+	}
+	if err != nil {
+		return FileInfoResponse{}, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	r := FileInfoResponse{
+		Path:        path,
+		Name:        info.Name(),
+		Exists:      true,
+		Type:        "file",
+		Mode:        info.Mode().String(),
+		Permissions: fmt.Sprintf("%04o", info.Mode().Perm()),
+		Attributes:  fileAttributes(info),
+		Modified:    info.ModTime(),
+	}
+	fileOwnership(info, &r)
+	if absPath, err := filepath.Abs(path); err == nil {
+		r.AbsPath = absPath
+	}
+	if opts.Git {
+		if ix := opts.gitStatus(ctx, path); ix != nil {
+			r.GitStatus = ix.FileStatus(path, info.IsDir())
+		}
+	}
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		r.Type = "symlink"
+		r.LinkTarget, _ = os.Readlink(path)
+	case info.IsDir():
+		r.Type = "dir"
+		if opts.Stats {
+			total, rows, err := e.dirStats(ctx, path, opts.Patterns)
+			if err != nil {
+				r.Error = fmt.Sprintf("stats: %v", err)
+			} else {
+				r.Stats, r.StatsFiles = &total, rows
+			}
+		}
+		// Count items only when the directory is readable
+		if entries, err := os.ReadDir(path); err == nil {
+			r.Contents = &DirectoryCounts{}
+			for _, entry := range entries {
+				if entry.IsDir() {
+					r.Contents.Dirs++
+				} else {
+					r.Contents.Files++
+				}
+			}
+		}
+	default:
+		r.Size = info.Size()
+		if ct, err := DetectFileContentType(path); err == nil {
+			r.MIME, r.IsBinary = ct.MIME, ct.Binary
+		}
+		if opts.Hash {
+			if sum, err := e.hashFileBuffered(path); err == nil {
+				r.SHA256 = sum
+			} else {
+				r.Error = fmt.Sprintf("hash: %v", err)
+			}
+		}
+		if opts.Stats {
+			if st, text, err := fileStats(path, opts.Patterns, e.statsPatterns(opts.Patterns)); err != nil {
+				r.Error = fmt.Sprintf("stats: %v", err)
+			} else if text {
+				r.Lines, r.Stats = &st.Lines, &st
+			}
+		}
+	}
+	return r, nil
+}
+
+// FormatFileInfo renders get_file_info output
+func FormatFileInfo(r FileInfoResponse, compact bool) string {
+	modified := r.Modified.Format("2006-01-02 15:04:05")
+	if compact {
+		if r.Type == "symlink" {
+			return fmt.Sprintf("symlink: %s -> %s | %s\n", r.Name, r.LinkTarget, modified)
+		}
+		line := fmt.Sprintf("%s: %s | %s | %s", r.Type, r.Name, formatSize(r.Size), modified)
+		if r.MIME != "" {
+			line += " | " + r.MIME
+		}
+		if r.Lines != nil {
+			line += fmt.Sprintf(" | %d lines", *r.Lines)
+		}
+		if r.Stats != nil && r.Type == "file" {
+			line += fmt.Sprintf(" | %d words | max %d", r.Stats.Words, r.Stats.MaxLineLength)
+			for _, p := range sortedPatternNames(r.Stats.Patterns) {
+				line += fmt.Sprintf(" | %s:%d", p, r.Stats.Patterns[p])
+			}
+		}
+		if r.SHA256 != "" {
+			line += " | sha256:" + r.SHA256
+		}
+		if r.GitStatus != "" {
+			line += " | git:" + r.GitStatus
+		}
+		if r.Stats != nil && r.Type == "dir" {
+			return line + "\n" + formatStatsTable(*r.Stats, r.StatsFiles, sortedPatternNames(r.Stats.Patterns))
+		}
+		return line + "\n"
+	}
+
+	var b strings.Builder
+	b.WriteString("📄 File Information\n")
+	b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	b.WriteString(fmt.Sprintf("📁 Name: %s\n", r.Name))
+	b.WriteString(fmt.Sprintf("📍 Full Path: %s\n", r.Path))
+	switch r.Type {
+	case "dir":
+		b.WriteString("📂 Type: Directory\n")
+		if r.Contents != nil {
+			b.WriteString(fmt.Sprintf("📊 Contents: %d files, %d directories\n", r.Contents.Files, r.Contents.Dirs))
+		}
+		if r.Stats != nil {
+			b.WriteString("📏 Stats:\n")
+			b.WriteString(formatStatsTable(*r.Stats, r.StatsFiles, sortedPatternNames(r.Stats.Patterns)))
+		}
+	case "symlink":
+		b.WriteString(fmt.Sprintf("↪️  Type: Symlink -> %s\n", r.LinkTarget))
+	default:
+		b.WriteString("📄 Type: File\n")
+		b.WriteString(fmt.Sprintf("💾 Size: %s (%d bytes)\n", formatSize(r.Size), r.Size))
+		if r.MIME != "" {
+			kind := "text"
+			if r.IsBinary {
+				kind = "binary"
+			}
+			b.WriteString(fmt.Sprintf("🧾 Content: %s (%s)\n", r.MIME, kind))
+		}
+		if st := r.Stats; st != nil {
+			b.WriteString(fmt.Sprintf("📏 Lines: %d (%d blank) | Words: %d | Longest line: %d\n", st.Lines, st.BlankLines, st.Words, st.MaxLineLength))
+			if len(st.Patterns) > 0 {
+				counts := make([]string, 0, len(st.Patterns))
+				for _, p := range sortedPatternNames(st.Patterns) {
+					counts = append(counts, fmt.Sprintf("%s: %d", p, st.Patterns[p]))
+				}
+				b.WriteString(fmt.Sprintf("🔎 Matches: %s\n", strings.Join(counts, ", ")))
+			}
+		} else if r.Lines != nil {
+			b.WriteString(fmt.Sprintf("📏 Lines: %d\n", *r.Lines))
+		}
+	}
+	if r.Permissions != "" {
+		b.WriteString(fmt.Sprintf("🔐 Permissions: %s (%s)\n", r.Mode, r.Permissions))
+	} else {
+		b.WriteString(fmt.Sprintf("🔐 Permissions: %s\n", r.Mode))
+	}
+	if r.Owner != "" {
+		b.WriteString(fmt.Sprintf("👤 Owner: %s:%s\n", r.Owner, r.Group))
+	}
+	if len(r.Attributes) > 0 {
+		b.WriteString(fmt.Sprintf("🏷️  Attributes: %s\n", strings.Join(r.Attributes, ", ")))
+	}
+	b.WriteString(fmt.Sprintf("🕐 Modified: %s\n", modified))
+	if r.SHA256 != "" {
+		b.WriteString(fmt.Sprintf("🔑 SHA-256: %s\n", r.SHA256))
+	}
+	if r.GitStatus != "" {
+		b.WriteString(fmt.Sprintf("🌿 Git: %s\n", r.GitStatus))
+	}
+	if r.AbsPath != "" && r.AbsPath != r.Path {
+		b.WriteString(fmt.Sprintf("🔗 Absolute Path: %s\n", r.AbsPath))
+	}
+	b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	return b.String()
+}
+
+// FoundFile is one search_files filename match
+type FoundFile struct {
+	Path string `json:"path"`
+	ContentType
+}
+
+// FileSearchResponse is search_files filename search with format:"json"
+type FileSearchResponse struct {
+	Pattern        string        `json:"pattern"`
+	Path           string        `json:"path"`
+	Files          []FoundFile   `json:"files"`
+	ContentMatches []SearchMatch `json:"content_matches,omitempty"`
+	Truncated      bool          `json:"truncated,omitempty"` // max_results reached
+	// StoppedAfterFiles and StopReason are set when a content search stopped
+	// early at MaxSearchResults or MaxResponseSize; the matches are partial
+	StoppedAfterFiles int    `json:"stopped_after_files,omitempty"`
+	StopReason        string `json:"stop_reason,omitempty"`
+}
+
+// DirectoryEntry is one list_directory entry
+type DirectoryEntry struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"` // "file" or "dir"
+	Size     int64  `json:"size,omitempty"`
+	Modified string `json:"modified,omitempty"` // RFC3339, UTC
+	Git      string `json:"git,omitempty"`      // include_git: M, A, R, C, U, ?? or !!; "" = clean
+}
+
+// DirectoryListing is list_directory with output_format:"json"
+type DirectoryListing struct {
+	Path      string           `json:"path"`
+	Total     int              `json:"total"`
+	Truncated bool             `json:"truncated,omitempty"` // more than MaxListItems entries (or past the view window)
+	Entries   []DirectoryEntry `json:"entries"`
+	View      *ListView        `json:"view,omitempty"` // sort/filter/offset/limit, see ListDirectoryView
+}
+
+// WatcherStatsResponse is the file watcher part of PerformanceStatsResponse
+type WatcherStatsResponse struct {
+	Active                bool  `json:"active"`
+	Directories           int   `json:"directories"`
+	Partial               bool  `json:"partial,omitempty"` // watch limit reached
+	ExternalModifications int64 `json:"external_modifications"`
+}
+
+// PerformanceStatsResponse is a snapshot of the engine counters
+type PerformanceStatsResponse struct {
+	TrackingSince       time.Time              `json:"tracking_since"`
+	OperationsTotal     int64                  `json:"operations_total"`
+	OperationsPerSecond float64                `json:"operations_per_second"`
+	OperationsRate1m    float64                `json:"operations_rate_1m"`
+	CacheHitRate        float64                `json:"cache_hit_rate"` // 0-1
+	Cache               []cache.CacheTypeStats `json:"cache"`
+	BytesServed         int64                  `json:"bytes_served"`    // from cache
+	BytesFromDisk       int64                  `json:"bytes_from_disk"` // file reads that missed the cache
+	MemoryUsage         int64                  `json:"memory_usage"`    // cached file content
+	ReadOperations      int64                  `json:"read_operations"`
+	WriteOperations     int64                  `json:"write_operations"`
+	ListOperations      int64                  `json:"list_operations"`
+	SearchOperations    int64                  `json:"search_operations"`
+	SlowOperations      int64                  `json:"slow_operations"`
+	SlowOpThreshold     time.Duration          `json:"slow_op_threshold,omitempty"` // 0 = slow operations not tracked
+	P50                 time.Duration          `json:"p50"`                         // over all engine operations
+	Latency             []LatencyStats         `json:"latency"`
+	Runtime             *RuntimeStats          `json:"runtime,omitempty"` // not sampled in compact mode
+	Watcher             WatcherStatsResponse   `json:"watcher"`
+	SecretScan          *SecretGuardStats      `json:"secret_scan,omitempty"`
+	Hooks               []HookExecStats        `json:"hooks,omitempty"`
+}
+
+// PerformanceStats snapshots the performance counters. The runtime section
+// stops the world briefly, so it is left out in compact mode.
+func (e *UltraFastEngine) PerformanceStats() PerformanceStatsResponse {
+	var s PerformanceStatsResponse
+	if !e.config.CompactMode {
+		rt := e.RuntimeStats() // sampled outside the metrics lock
+		s.Runtime = &rt
+	}
+	s.Watcher.Active, s.Watcher.Directories, s.Watcher.Partial, s.Watcher.ExternalModifications = e.WatcherStats()
+	if e.cache != nil {
+		s.Cache = e.cache.TypeStats()
+		for _, t := range s.Cache {
+			s.BytesServed += t.Served
+		}
+		s.BytesFromDisk = e.cache.GetStats().FileBytesRead
+	}
+	s.P50 = e.latency.p50()
+	s.Latency = e.latency.stats()
+	if guard := e.hookManager.SecretGuard(); guard != nil {
+		stats := guard.Stats()
+		s.SecretScan = &stats
+	}
+	s.Hooks = e.hookManager.ExecStats()
+	s.SlowOpThreshold = e.config.SlowOpThreshold
+
+	e.metrics.mu.RLock()
+	defer e.metrics.mu.RUnlock()
+	s.TrackingSince = e.metrics.TrackingSince
+	s.OperationsTotal = e.metrics.OperationsTotal
+	s.OperationsPerSecond = e.metrics.OperationsPerSecond
+	s.OperationsRate1m = e.metrics.OperationsRate1m
+	s.CacheHitRate = e.metrics.CacheHitRate
+	s.MemoryUsage = e.metrics.MemoryUsage
+	s.ReadOperations = e.metrics.ReadOperations
+	s.WriteOperations = e.metrics.WriteOperations
+	s.ListOperations = e.metrics.ListOperations
+	s.SearchOperations = e.metrics.SearchOperations
+	s.SlowOperations = e.metrics.SlowOperations
+	return s
+}
+
+// FormatPerformanceStats renders performance_stats output
+func FormatPerformanceStats(s PerformanceStatsResponse, compact bool) string {
+	if compact {
+		// Compact format: key metrics only
+		stats := fmt.Sprintf("ops/s:%.1f 1m:%.1f p50:%s hit:%.1f%% mem:%s ops:%d",
+			s.OperationsPerSecond,
+			s.OperationsRate1m,
+			formatLatency(s.P50),
+			s.CacheHitRate*100,
+			formatSize(s.MemoryUsage),
+			s.OperationsTotal)
+		for _, t := range s.Cache {
+			if t.Hits+t.Misses > 0 {
+				stats += fmt.Sprintf(" %s:%.0f%%", t.Type, t.HitRate()*100)
+			}
+		}
+		if s.BytesServed+s.BytesFromDisk > 0 {
+			stats += fmt.Sprintf(" cached:%s disk:%s", formatSize(s.BytesServed), formatSize(s.BytesFromDisk))
+		}
+		if s.SlowOperations > 0 {
+			stats += fmt.Sprintf(" slow:%d", s.SlowOperations)
+		}
+		if s.Watcher.Active {
+			stats += fmt.Sprintf(" ext:%d", s.Watcher.ExternalModifications)
+		}
+		if s.SecretScan != nil {
+			stats += fmt.Sprintf(" secrets_blocked:%d", s.SecretScan.Blocked)
+		}
+		if len(s.Hooks) > 0 {
+			var runs int64
+			for _, st := range s.Hooks {
+				runs += st.Runs
+			}
+			stats += fmt.Sprintf(" hooks:%d", runs)
+		}
+		return stats
+	}
+
+	watchLine := "File Watcher: off (stat checks on cache hits)"
+	if s.Watcher.Active {
+		watchLine = fmt.Sprintf("File Watcher: %d directories", s.Watcher.Directories)
+		if s.Watcher.Partial {
+			watchLine += " (partial: limit reached, rest use stat checks)"
+		}
+		watchLine += fmt.Sprintf("\nExternal Modifications: %d", s.Watcher.ExternalModifications)
+	}
+	slowLine := ""
+	if s.SlowOpThreshold > 0 {
+		slowLine = fmt.Sprintf("\nSlow Operations: %d (over %v)", s.SlowOperations, s.SlowOpThreshold)
+	}
+	hookLines := ""
+	if s.SecretScan != nil {
+		hookLines = "\nSecret Scan: " + s.SecretScan.String()
+	}
+	hookLines += formatHookExecStats(s.Hooks)
+	runtimeLines := ""
+	if s.Runtime != nil {
+		runtimeLines = formatRuntimeStats(*s.Runtime)
+	}
+
+	var cacheLines strings.Builder
+	for _, t := range s.Cache {
+		cacheLines.WriteString(fmt.Sprintf("\n  %-5s %.2f%% (%d hits, %d misses)", t.Type+":", t.HitRate()*100, t.Hits, t.Misses))
+		if t.Served > 0 {
+			cacheLines.WriteString(fmt.Sprintf(", %s served", formatSize(t.Served)))
+		}
+	}
+	cacheLines.WriteString(fmt.Sprintf("\nCache Bytes: %s served from cache, %s read from disk", formatSize(s.BytesServed), formatSize(s.BytesFromDisk)))
+
+	// Verbose format
+	return fmt.Sprintf(`Performance Statistics:
+Tracking Since: %s (%s)
+Operations Total: %d
+Operations/Second: %.2f (1m rate: %.2f)
+Cache Hit Rate: %.2f%%%s
+Memory Usage: %s
+Read Operations: %d
+Write Operations: %d
+List Operations: %d
+Search Operations: %d%s%s%s
+%s%s`,
+		s.TrackingSince.Format(time.RFC3339), FormatAge(s.TrackingSince),
+		s.OperationsTotal,
+		s.OperationsPerSecond,
+		s.OperationsRate1m,
+		s.CacheHitRate*100,
+		cacheLines.String(),
+		formatSize(s.MemoryUsage),
+		s.ReadOperations,
+		s.WriteOperations,
+		s.ListOperations,
+		s.SearchOperations,
+		slowLine,
+		formatLatencyTable(s.Latency),
+		runtimeLines,
+		watchLine, hookLines)
+}
+
+// BackupSystemInfo is the backup part of server_info stats
+type BackupSystemInfo struct {
+	Directory    string      `json:"directory"`
+	MaxCount     int         `json:"max_count"`
+	MaxAgeDays   int         `json:"max_age_days"`
+	TotalBackups int         `json:"total_backups"`
+	Latest       *BackupInfo `json:"latest,omitempty"`
+}
+
+// ServerStatsResponse is server_info(action:"stats")
+type ServerStatsResponse struct {
+	Build         string                   `json:"build"`
+	Performance   PerformanceStatsResponse `json:"performance"`
+	EditTelemetry map[string]interface{}   `json:"edit_telemetry"`
+	Backup        *BackupSystemInfo        `json:"backup,omitempty"`
+}
+
+// BackupListResponse is backup(action:"list")
+type BackupListResponse struct {
+	Count   int          `json:"count"`
+	Backups []BackupInfo `json:"backups"`
+}
+
+// FormatBackupList renders backup list output
+func FormatBackupList(l BackupListResponse) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Available Backups (%d)\n", l.Count))
+	b.WriteString("---\n\n")
+
+	for _, backup := range l.Backups {
+		b.WriteString(fmt.Sprintf("# %s\n", backup.BackupID))
+		b.WriteString(fmt.Sprintf("   Time: %s (%s)\n", backup.Timestamp.Format("2006-01-02 15:04:05"), FormatAge(backup.Timestamp)))
+		b.WriteString(fmt.Sprintf("   Operation: %s\n", backup.Operation))
+		b.WriteString(fmt.Sprintf("   Files: %d (%s)\n", len(backup.Files), FormatSize(backup.TotalSize)))
+		if backup.UserContext != "" {
+			b.WriteString(fmt.Sprintf("   Context: %s\n", backup.UserContext))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(l.Backups) == 0 {
+		b.WriteString("No backups found matching the criteria.\n")
+	} else {
+		b.WriteString("Use backup(action:\"restore\", backup_id:\"...\") to restore files\n")
+		b.WriteString("Use backup(action:\"info\", backup_id:\"...\") for detailed information\n")
+	}
+	return b.String()
+}
+
+// ServerConfigResponse is server_info config: the configuration the server
+// resolved at startup plus the state that changes at runtime (allowed paths,
+// hooks, autosync). Version and Build are filled in by the caller.
+type ServerConfigResponse struct {
+	Version  string `json:"version"`
+	Build    string `json:"build"`
+	Platform string `json:"platform"` // GOOS/GOARCH and Go version
+
+	CompactMode      bool  `json:"compact_mode"`
+	JSONResponses    bool  `json:"json_responses"`
+	MaxResponseSize  int64 `json:"max_response_size"` // 0 = no limit
+	MaxSearchResults int   `json:"max_search_results"`
+	MaxListItems     int   `json:"max_list_items"`
+	ParallelOps      int   `json:"parallel_ops"`
+
+	AllowedPaths        []string `json:"allowed_paths"`
+	ProtectedPaths      []string `json:"protected_paths,omitempty"`
+	AllowPathManagement bool     `json:"allow_path_management"`
+
+	Backup    ConfigBackup    `json:"backup"`
+	Risk      ConfigRisk      `json:"risk"`
+	Hooks     ConfigHooks     `json:"hooks"`
+	AutoSync  AutoSyncConfig  `json:"autosync"`
+	Cache     ConfigCache     `json:"cache"`
+	Resources ConfigResources `json:"resources"`
+	Tools     ConfigTools     `json:"tools"`
+	Logging   ConfigLogging   `json:"logging"`
+}
+
+// ConfigBackup is the backup section of ServerConfigResponse
+type ConfigBackup struct {
+	Directory  string `json:"directory,omitempty"`
+	MaxCount   int    `json:"max_count"`
+	MaxAgeDays int    `json:"max_age_days"`
+}
+
+// ConfigRisk is the risk section of ServerConfigResponse
+type ConfigRisk struct {
+	MediumPercent     float64  `json:"medium_percent"`
+	HighPercent       float64  `json:"high_percent"`
+	MediumOccurrences int      `json:"medium_occurrences"`
+	HighOccurrences   int      `json:"high_occurrences"`
+	CriticalFiles     []string `json:"critical_files,omitempty"` // on top of the built-in list
+	ConfirmTokens     bool     `json:"confirm_tokens"`
+}
+
+// ConfigHooks is the hooks section of ServerConfigResponse
+type ConfigHooks struct {
+	Enabled    bool   `json:"enabled"`
+	ConfigPath string `json:"config_path,omitempty"`
+	SecretScan bool   `json:"secret_scan"`
+}
+
+// ConfigCache is the cache section of ServerConfigResponse
+type ConfigCache struct {
+	Size        int64         `json:"size"`          // memory limit
+	Used        int64         `json:"used"`          // file content cached now
+	MaxFileSize int64         `json:"max_file_size"` // <= 0 = no limit
+	FileTTL     time.Duration `json:"file_ttl,omitempty"`
+	NegativeTTL time.Duration `json:"negative_ttl,omitempty"`
+	Watch       bool          `json:"watch"`
+	Persist     bool          `json:"persist"`
+}
+
+// ConfigResources is the MCP resources section of ServerConfigResponse
+type ConfigResources struct {
+	Depth    int      `json:"depth"` // 0 = no resources
+	Excludes []string `json:"excludes,omitempty"`
+}
+
+// ConfigTools is the tool selection section of ServerConfigResponse
+type ConfigTools struct {
+	Enable  []string `json:"enable,omitempty"`
+	Disable []string `json:"disable,omitempty"`
+}
+
+// ConfigLogging is the logging section of ServerConfigResponse
+type ConfigLogging struct {
+	LogDir         string `json:"log_dir,omitempty"`
+	MutationLogDir string `json:"mutation_log_dir,omitempty"`
+	MetricsAddr    string `json:"metrics_addr,omitempty"`
+}
+
+// ServerConfig snapshots the effective configuration
+func (e *UltraFastEngine) ServerConfig() ServerConfigResponse {
+	c := e.config
+	r := ServerConfigResponse{
+		Platform:            fmt.Sprintf("%s/%s, %s", runtime.GOOS, runtime.GOARCH, runtime.Version()),
+		CompactMode:         c.CompactMode,
+		JSONResponses:       c.JSONResponses,
+		MaxResponseSize:     c.MaxResponseSize,
+		MaxSearchResults:    c.MaxSearchResults,
+		MaxListItems:        c.MaxListItems,
+		ParallelOps:         cap(e.semaphore),
+		AllowedPaths:        append([]string{}, e.GetAllowedPaths()...),
+		ProtectedPaths:      c.ProtectedPaths,
+		AllowPathManagement: c.AllowPathManagement,
+		Risk: ConfigRisk{
+			MediumPercent:     e.riskThresholds.MediumPercentage,
+			HighPercent:       e.riskThresholds.HighPercentage,
+			MediumOccurrences: e.riskThresholds.MediumOccurrences,
+			HighOccurrences:   e.riskThresholds.HighOccurrences,
+			CriticalFiles:     e.riskThresholds.CriticalFiles,
+			ConfirmTokens:     e.confirmTokens != nil,
+		},
+		Hooks:     ConfigHooks{ConfigPath: c.HooksConfigPath, SecretScan: c.SecretScan},
+		AutoSync:  e.GetAutoSyncConfig(),
+		Resources: ConfigResources{Depth: c.ResourceDepth, Excludes: c.ResourceExcludes},
+		Tools:     ConfigTools{Enable: c.EnableTools, Disable: c.DisableTools},
+		Logging:   ConfigLogging{LogDir: c.LogDir, MutationLogDir: c.MutationLogDir, MetricsAddr: c.MetricsAddr},
+	}
+	if e.hookManager != nil {
+		r.Hooks.Enabled = e.hookManager.IsEnabled()
+	}
+	if bm := e.backupManager; bm != nil {
+		r.Backup.Directory = bm.GetBackupDir()
+		r.Backup.MaxCount, r.Backup.MaxAgeDays = bm.GetBackupLimits()
+	}
+	if e.cache != nil {
+		r.Cache = ConfigCache{
+			Size:        e.cache.MaxSize(),
+			Used:        e.cache.GetMemoryUsage(),
+			MaxFileSize: e.cache.MaxFileSize(),
+			FileTTL:     c.CacheFileTTL,
+			NegativeTTL: c.NegativeCacheTTL,
+			Persist:     c.PersistCache,
+		}
+		r.Cache.Watch, _, _, _ = e.WatcherStats()
+	}
+	return r
+}
+
+// FormatServerConfig renders server_info config output
+func FormatServerConfig(r ServerConfigResponse, compact bool) string {
+	onOff := func(b bool) string {
+		if b {
+			return "on"
+		}
+		return "off"
+	}
+	orDash := func(s string) string {
+		if s == "" {
+			return "—"
+		}
+		return s
+	}
+	list := func(items []string) string {
+		return orDash(strings.Join(items, ", "))
+	}
+	if compact {
+		return fmt.Sprintf("v%s %s | paths:%s | compact:%s json:%s | resp:%s search:%d list:%d | cache:%s hooks:%s autosync:%s",
+			r.Version, r.Platform, list(r.AllowedPaths), onOff(r.CompactMode), onOff(r.JSONResponses),
+			formatSize(r.MaxResponseSize), r.MaxSearchResults, r.MaxListItems,
+			formatSize(r.Cache.Size), onOff(r.Hooks.Enabled), onOff(r.AutoSync.Enabled))
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Server: v%s, build %s\n", r.Version, r.Build))
+	b.WriteString(fmt.Sprintf("Platform: %s\n", r.Platform))
+	maxResponse := "no size limit"
+	if r.MaxResponseSize > 0 {
+		maxResponse = "max " + formatSize(r.MaxResponseSize)
+	}
+	b.WriteString(fmt.Sprintf("Responses: compact %s, JSON %s, %s\n", onOff(r.CompactMode), onOff(r.JSONResponses), maxResponse))
+	b.WriteString(fmt.Sprintf("Limits: %d search results, %d list items, %d parallel operations\n", r.MaxSearchResults, r.MaxListItems, r.ParallelOps))
+
+	b.WriteString("\nAllowed paths:\n")
+	if len(r.AllowedPaths) == 0 {
+		b.WriteString("  (none — every path is allowed)\n")
+	}
+	for _, p := range r.AllowedPaths {
+		b.WriteString("  " + p + "\n")
+	}
+	b.WriteString(fmt.Sprintf("Protected paths: %s\n", list(r.ProtectedPaths)))
+	b.WriteString(fmt.Sprintf("Path management tools: %s\n", onOff(r.AllowPathManagement)))
+
+	b.WriteString(fmt.Sprintf("\nBackups: %s (max %d, %d days)\n", r.Backup.Directory, r.Backup.MaxCount, r.Backup.MaxAgeDays))
+	b.WriteString(fmt.Sprintf("Risk: medium %.0f%% / %d occurrences, high %.0f%% / %d occurrences, confirm tokens %s\n",
+		r.Risk.MediumPercent, r.Risk.MediumOccurrences, r.Risk.HighPercent, r.Risk.HighOccurrences, onOff(r.Risk.ConfirmTokens)))
+	if len(r.Risk.CriticalFiles) > 0 {
+		b.WriteString(fmt.Sprintf("Extra critical files: %s\n", list(r.Risk.CriticalFiles)))
+	}
+	hooks := onOff(r.Hooks.Enabled)
+	if r.Hooks.ConfigPath != "" {
+		hooks += " (" + r.Hooks.ConfigPath + ")"
+	}
+	b.WriteString(fmt.Sprintf("Hooks: %s, secret scan %s\n", hooks, onOff(r.Hooks.SecretScan)))
+	b.WriteString(fmt.Sprintf("Autosync: %s", onOff(r.AutoSync.Enabled)))
+	if r.AutoSync.Enabled {
+		b.WriteString(fmt.Sprintf(" (write %s, edit %s, delete %s, %d mappings)",
+			onOff(r.AutoSync.SyncOnWrite), onOff(r.AutoSync.SyncOnEdit), onOff(r.AutoSync.SyncOnDelete), len(r.AutoSync.RootMappings)))
+	}
+	b.WriteString("\n")
+
+	maxFile := "no limit"
+	if r.Cache.MaxFileSize > 0 {
+		maxFile = formatSize(r.Cache.MaxFileSize)
+	}
+	b.WriteString(fmt.Sprintf("\nCache: %s (%s used), files up to %s, watch %s, snapshot %s",
+		formatSize(r.Cache.Size), formatSize(r.Cache.Used), maxFile, onOff(r.Cache.Watch), onOff(r.Cache.Persist)))
+	if r.Cache.FileTTL > 0 {
+		b.WriteString(fmt.Sprintf(", TTL %s", r.Cache.FileTTL))
+	}
+	b.WriteString("\n")
+	resources := "off"
+	if r.Resources.Depth > 0 {
+		resources = fmt.Sprintf("%d levels", r.Resources.Depth)
+	}
+	b.WriteString(fmt.Sprintf("Resources: %s\n", resources))
+	if len(r.Tools.Enable)+len(r.Tools.Disable) > 0 {
+		b.WriteString(fmt.Sprintf("Tools: enable %s, disable %s (server_info tools for details)\n", list(r.Tools.Enable), list(r.Tools.Disable)))
+	}
+	if r.Logging.LogDir != "" || r.Logging.MutationLogDir != "" || r.Logging.MetricsAddr != "" {
+		b.WriteString(fmt.Sprintf("Logging: audit %s, mutation log %s, metrics %s\n",
+			orDash(r.Logging.LogDir), orDash(r.Logging.MutationLogDir), orDash(r.Logging.MetricsAddr)))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
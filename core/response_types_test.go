@@ -0,0 +1,119 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestResponseTypes_FileInfoViews(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	path := filepath.Join(dir, "a.txt")
+	os.WriteFile(path, []byte("hello"), 0644)
+	os.Mkdir(filepath.Join(dir, "sub"), 0755)
+
+	info, err := engine.FileInfo(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Type != "file" || info.Size != 5 || info.Contents != nil {
+		t.Errorf("file info = %+v", info)
+	}
+	text, _ := engine.GetFileInfo(context.Background(), path)
+	if text != FormatFileInfo(info, false) || !strings.Contains(text, "💾 Size: 5 B (5 bytes)") {
+		t.Errorf("prose is not rendered from FileInfo:\n%s", text)
+	}
+
+	dirInfo, err := engine.FileInfo(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dirInfo.Type != "dir" || dirInfo.Contents == nil || *dirInfo.Contents != (DirectoryCounts{Files: 1, Dirs: 1}) {
+		t.Errorf("dir info = %+v", dirInfo)
+	}
+	if got := FormatFileInfo(dirInfo, true); !strings.HasPrefix(got, "dir: "+filepath.Base(dir)+" | ") {
+		t.Errorf("compact = %q", got)
+	}
+}
+
+func TestFileInfo_DetailsAndSymlink(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	ctx := context.Background()
+	path := filepath.Join(dir, "code.go")
+	os.WriteFile(path, []byte("package x\n\nfunc f() {}"), 0640)
+	bin := filepath.Join(dir, "blob.bin")
+	os.WriteFile(bin, []byte{0x7f, 'E', 'L', 'F', 0, 1, '\n'}, 0644)
+
+	plain, err := engine.FileInfo(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain.Lines != nil || plain.SHA256 != "" {
+		t.Errorf("content fields filled without asking: %+v", plain)
+	}
+	if runtime.GOOS != "windows" && (plain.Permissions != "0640" || plain.Owner == "") {
+		t.Errorf("permissions/owner = %q %q", plain.Permissions, plain.Owner)
+	}
+
+	info, err := engine.FileInfoWith(ctx, path, FileInfoOptions{Hash: true, Stats: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Lines == nil || *info.Lines != 3 || len(info.SHA256) != 64 {
+		t.Errorf("with hash and stats = %+v", info)
+	}
+	if !strings.Contains(FormatFileInfo(info, false), "📏 Lines: 3") || !strings.Contains(FormatFileInfo(info, true), "| 3 lines |") {
+		t.Errorf("line count not rendered:\n%s", FormatFileInfo(info, false))
+	}
+	if b, _ := engine.FileInfoWith(ctx, bin, FileInfoOptions{Stats: true}); b.Lines != nil {
+		t.Errorf("binary file got a line count: %d", *b.Lines)
+	}
+
+	link := filepath.Join(dir, "link.go")
+	if err := os.Symlink(path, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	l, err := engine.FileInfoWith(ctx, link, FileInfoOptions{Stats: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Type != "symlink" || l.LinkTarget != path || l.Lines != nil {
+		t.Errorf("symlink info = %+v", l)
+	}
+	if got := FormatFileInfo(l, true); !strings.HasPrefix(got, "symlink: link.go -> "+path) {
+		t.Errorf("compact symlink = %q", got)
+	}
+}
+
+func TestResponseTypes_PerformanceStatsJSON(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	engine.ReadFileContent(context.Background(), filepath.Join(dir, "missing.txt"))
+
+	s := engine.PerformanceStats()
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back map[string]any
+	json.Unmarshal(data, &back)
+	for _, key := range []string{"tracking_since", "operations_total", "cache", "latency", "runtime", "watcher"} {
+		if _, ok := back[key]; !ok {
+			t.Errorf("performance stats JSON missing %q: %s", key, data)
+		}
+	}
+	if engine.GetPerformanceStats() == "" || !strings.Contains(FormatPerformanceStats(s, false), "Runtime:") {
+		t.Error("prose view does not render the snapshot")
+	}
+
+	if engine.JSONResponse("") || !engine.JSONResponse("json") {
+		t.Error("format without --json-responses")
+	}
+	engine.config.JSONResponses = true
+	if !engine.JSONResponse("") || engine.JSONResponse("text") {
+		t.Error("format with --json-responses")
+	}
+}
@@ -71,11 +71,28 @@ type ripgrepMatch struct {
 	} `json:"data"`
 }
 
-// RunRipgrepSearch executes ripgrep with --json output and returns SearchMatch results.
+// RunRipgrepSearch executes ripgrep with --json output and returns SearchMatch results,
+// up to MaxSearchResults matches and MaxResponseSize bytes of them.
 // Falls back to returning an error if ripgrep is not available or fails.
 // The caller is responsible for passing a validated path that passes IsPathAllowed.
 func (e *UltraFastEngine) RunRipgrepSearch(ctx context.Context, path, pattern string,
 	caseSensitive, wholeWord, includeContext bool, contextLines int) ([]SearchMatch, error) {
+	col := e.newSearchCollector()
+	if err := e.runRipgrepSearch(ctx, path, pattern, caseSensitive, wholeWord, includeContext, contextLines, col); err != nil {
+		return nil, err
+	}
+	matches, _ := col.result()
+	if matches == nil {
+		matches = []SearchMatch{}
+	}
+	return matches, nil
+}
+
+// runRipgrepSearch runs ripgrep and adds its matches to col file by file as
+// they are printed. Once col is full, ripgrep is stopped instead of left to
+// print matches that would be dropped.
+func (e *UltraFastEngine) runRipgrepSearch(ctx context.Context, path, pattern string,
+	caseSensitive, wholeWord, includeContext bool, contextLines int, col *searchCollector) error {
 
 	args := []string{
 		"--json",
@@ -116,58 +133,77 @@ func (e *UltraFastEngine) RunRipgrepSearch(ctx context.Context, path, pattern st
 		}
 	}
 
-	cmd := exec.CommandContext(ctx, rgBin, args...)
-	output, err := cmd.Output()
+	rgCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	cmd := exec.CommandContext(rgCtx, rgBin, args...)
+	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		// ripgrep exit code 1 means "no matches found" — a valid result,
-		// not a failure. Codes >= 2 are real errors.
-		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
-			return []SearchMatch{}, nil
-		}
-		return nil, fmt.Errorf("ripgrep failed: %w", err)
+		return fmt.Errorf("ripgrep failed: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("ripgrep failed: %w", err)
 	}
 
-	var matches []SearchMatch
-	scanner := bufio.NewScanner(strings.NewReader(string(output)))
-	for scanner.Scan() {
-		line := scanner.Text()
-		if line == "" {
+	// ripgrep prints begin, match... and end messages per file; a file's
+	// matches go to the collector together when its end message arrives
+	var fileMatches []SearchMatch
+	var fileSize int64
+	scanner := bufio.NewScanner(stdout)
+	for !col.full() && scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
 			continue
 		}
 
 		var rgMatch ripgrepMatch
-		if err := json.Unmarshal([]byte(line), &rgMatch); err != nil {
+		if err := json.Unmarshal(line, &rgMatch); err != nil {
 			// Skip malformed lines
 			slog.Debug("ripgrep: malformed JSON line", "error", err)
 			continue
 		}
 
-		// Only process match type
-		if rgMatch.Type != "match" {
-			continue
-		}
-
-		match := SearchMatch{
-			File:       rgMatch.Data.Path.Text,
-			LineNumber: rgMatch.Data.LineNumber,
-			Line:       rgMatch.Data.Lines.Text,
-			MatchStart: rgMatch.Data.Bytes.Start,
-			MatchEnd:   rgMatch.Data.Bytes.End,
-		}
-
-		// Add context lines if present (ripgrep provides them via separate "context" type lines)
-		// For now, we capture the main match line; context is available via -C flag
-		if includeContext && contextLines > 0 {
-			// Ripgrep outputs context as separate "context" type lines before/after match lines
-			// We collect these as context on the following match
+		switch rgMatch.Type {
+		case "match":
+			if col.fileFull(len(fileMatches), fileSize) {
+				continue
+			}
+			// Context lines come as separate "context" messages and are not
+			// attached to matches; only the match line is captured
+			match := SearchMatch{
+				File:       rgMatch.Data.Path.Text,
+				LineNumber: rgMatch.Data.LineNumber,
+				Line:       rgMatch.Data.Lines.Text,
+				MatchStart: rgMatch.Data.Bytes.Start,
+				MatchEnd:   rgMatch.Data.Bytes.End,
+			}
+			fileMatches = append(fileMatches, match)
+			fileSize += searchMatchSize(match)
+		case "end":
+			col.add(fileMatches)
+			fileMatches, fileSize = nil, 0
 		}
-
-		matches = append(matches, match)
 	}
+	scanErr := scanner.Err()
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("ripgrep output parsing error: %w", err)
+	// Stop ripgrep if its output was not read to the end, so Wait cannot
+	// block on a full pipe
+	if col.full() || scanErr != nil {
+		cancel()
 	}
-
-	return matches, nil
+	waitErr := cmd.Wait()
+	if col.full() {
+		return nil
+	}
+	if scanErr != nil {
+		return fmt.Errorf("ripgrep output parsing error: %w", scanErr)
+	}
+	if waitErr != nil {
+		// ripgrep exit code 1 means "no matches found" — a valid result,
+		// not a failure. Codes >= 2 are real errors.
+		if exitErr, ok := waitErr.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return nil
+		}
+		return fmt.Errorf("ripgrep failed: %w", waitErr)
+	}
+	return nil
 }
@@ -0,0 +1,95 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RuntimeStats describes the Go runtime, the process and the worker pool.
+// MemoryUsage in PerformanceMetrics only counts cached file content; this is
+// what explains a large RSS.
+type RuntimeStats struct {
+	HeapAlloc    uint64        `json:"heap_alloc"`  // bytes of live heap objects
+	HeapSys      uint64        `json:"heap_sys"`    // heap bytes obtained from the OS
+	Sys          uint64        `json:"sys"`         // total bytes obtained from the OS
+	NumGC        uint32        `json:"num_gc"`      // completed GC cycles
+	PauseTotal   time.Duration `json:"pause_total"` // cumulative stop-the-world GC pause
+	LastPause    time.Duration `json:"last_pause"`  // most recent GC pause
+	MaxPause     time.Duration `json:"max_pause"`   // longest of the last 256 GC pauses
+	Goroutines   int           `json:"goroutines"`
+	RSS          int64         `json:"rss"`          // resident set size, 0 where it cannot be read
+	PoolRunning  int           `json:"pool_running"` // worker pool goroutines busy
+	PoolWaiting  int           `json:"pool_waiting"` // tasks blocked waiting for a worker
+	PoolCapacity int           `json:"pool_capacity"`
+}
+
+// RuntimeStats samples the Go runtime, process RSS and worker pool. It
+// briefly stops the world (runtime.ReadMemStats), so it is only called on
+// demand.
+func (e *UltraFastEngine) RuntimeStats() RuntimeStats {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	s := RuntimeStats{
+		HeapAlloc:  mem.HeapAlloc,
+		HeapSys:    mem.HeapSys,
+		Sys:        mem.Sys,
+		NumGC:      mem.NumGC,
+		PauseTotal: time.Duration(mem.PauseTotalNs),
+		Goroutines: runtime.NumGoroutine(),
+		RSS:        processRSS(),
+	}
+	if mem.NumGC > 0 {
+		s.LastPause = time.Duration(mem.PauseNs[(mem.NumGC+255)%256])
+	}
+	for _, p := range mem.PauseNs {
+		s.MaxPause = max(s.MaxPause, time.Duration(p))
+	}
+	if e.workerPool != nil {
+		s.PoolRunning = e.workerPool.Running()
+		s.PoolWaiting = e.workerPool.Waiting()
+		s.PoolCapacity = e.workerPool.Cap()
+	}
+	return s
+}
+
+// processRSS reads the resident set size from /proc/self/statm. Other
+// platforms return 0.
+func processRSS() int64 {
+	data, err := os.ReadFile("/proc/self/statm")
+	if err != nil {
+		return 0
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < 2 {
+		return 0
+	}
+	pages, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return pages * int64(os.Getpagesize())
+}
+
+// formatRuntimeStats renders the performance_stats runtime section
+func formatRuntimeStats(s RuntimeStats) string {
+	rss := "n/a"
+	if s.RSS > 0 {
+		rss = formatSize(s.RSS)
+	}
+	return fmt.Sprintf(`
+Runtime:
+  Process RSS: %s (Go sys: %s)
+  Heap: %s in use, %s reserved
+  GC: %d cycles, %s total pause (last %s, max %s)
+  Goroutines: %d
+  Worker Pool: %d/%d running, %d waiting`,
+		rss, formatSize(int64(s.Sys)),
+		formatSize(int64(s.HeapAlloc)), formatSize(int64(s.HeapSys)),
+		s.NumGC, formatLatency(s.PauseTotal), formatLatency(s.LastPause), formatLatency(s.MaxPause),
+		s.Goroutines,
+		s.PoolRunning, s.PoolCapacity, s.PoolWaiting)
+}
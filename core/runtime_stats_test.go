@@ -0,0 +1,34 @@
+package core
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestPerformanceStats_RuntimeSection(t *testing.T) {
+	engine, _ := setupProgressEngine(t)
+	runtime.GC()
+
+	s := engine.RuntimeStats()
+	if s.HeapAlloc == 0 || s.Sys == 0 || s.NumGC == 0 || s.Goroutines == 0 {
+		t.Errorf("runtime stats = %+v", s)
+	}
+	if s.PoolCapacity != engine.config.ParallelOps {
+		t.Errorf("pool capacity = %d, want %d", s.PoolCapacity, engine.config.ParallelOps)
+	}
+	if runtime.GOOS == "linux" && s.RSS <= 0 {
+		t.Errorf("RSS = %d, want > 0 on linux", s.RSS)
+	}
+
+	stats := engine.GetPerformanceStats()
+	for _, want := range []string{"Runtime:", "Process RSS: ", "Heap: ", "GC: ", "Goroutines: ", "Worker Pool: 0/"} {
+		if !strings.Contains(stats, want) {
+			t.Errorf("performance stats missing %q:\n%s", want, stats)
+		}
+	}
+	engine.config.CompactMode = true
+	if compact := engine.GetPerformanceStats(); strings.Contains(compact, "Runtime") {
+		t.Errorf("compact stats include the runtime section: %q", compact)
+	}
+}
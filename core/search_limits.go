@@ -0,0 +1,170 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// searchCollector gathers content matches from parallel file scans. It stops
+// at MaxSearchResults matches or MaxResponseSize bytes of match text, so a
+// pattern that hits every line of a large tree cannot hold the whole tree in
+// memory. Walks check full() to stop queueing files, scanners to stop reading.
+type searchCollector struct {
+	mu         sync.Mutex
+	matches    []SearchMatch
+	size       int64
+	files      int // files searched before the limits were hit
+	reason     string
+	maxResults int
+	maxBytes   int64 // 0 = no byte limit
+	stopped    atomic.Bool
+}
+
+// searchLimit says whether and why a search stopped before the end of the tree
+type searchLimit struct {
+	Reason string // "" when every candidate file was searched
+	Files  int    // files searched before stopping
+}
+
+func (e *UltraFastEngine) newSearchCollector() *searchCollector {
+	c := &searchCollector{maxResults: e.config.MaxSearchResults, maxBytes: e.config.MaxResponseSize}
+	if c.maxResults <= 0 {
+		c.maxResults = MaxSearchResults
+	}
+	return c
+}
+
+// searchMatchSize approximates the memory and response bytes of one match
+func searchMatchSize(m SearchMatch) int64 {
+	n := len(m.File) + len(m.Line) + 32
+	for _, l := range m.Context {
+		n += len(l) + 4
+	}
+	return int64(n)
+}
+
+// full reports whether a limit was hit; safe to call without the lock
+func (c *searchCollector) full() bool {
+	return c.stopped.Load()
+}
+
+// fileFull reports whether one file's matches alone reach a limit, so a scan
+// can stop reading a file that matches on every line
+func (c *searchCollector) fileFull(count int, size int64) bool {
+	return count >= c.maxResults || (c.maxBytes > 0 && size >= c.maxBytes) || c.full()
+}
+
+// add records one searched file and its matches. Matches past a limit are
+// dropped, as are files that finish after the collector filled up.
+func (c *searchCollector) add(matches []SearchMatch) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.reason != "" {
+		return
+	}
+	c.files++
+	for _, m := range matches {
+		if len(c.matches) >= c.maxResults {
+			c.stop(fmt.Sprintf("reached the %d-result limit", c.maxResults))
+			return
+		}
+		c.matches = append(c.matches, m)
+		c.size += searchMatchSize(m)
+		if c.maxBytes > 0 && c.size >= c.maxBytes {
+			c.stop(fmt.Sprintf("reached the %s response size limit", formatSize(c.maxBytes)))
+			return
+		}
+	}
+	if len(c.matches) >= c.maxResults {
+		c.stop(fmt.Sprintf("reached the %d-result limit", c.maxResults))
+	}
+}
+
+func (c *searchCollector) stop(reason string) {
+	c.reason = reason
+	c.stopped.Store(true)
+}
+
+// result returns the collected matches and why collection stopped, if it did
+func (c *searchCollector) result() ([]SearchMatch, searchLimit) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.matches, searchLimit{Reason: c.reason, Files: c.files}
+}
+
+// scanFileMatches returns the lines of content matching re, with up to
+// contextLines trimmed lines around each when includeContext is set. It stops
+// once the file's own matches reach a collector limit.
+func scanFileMatches(file string, content []byte, re *regexp.Regexp, includeContext bool, contextLines int, c *searchCollector) []SearchMatch {
+	var matches []SearchMatch
+	var size int64
+
+	// When context is not needed, use bufio.Scanner for memory efficiency
+	// When context is needed, use strings.Split (need forward-looking capability)
+	if !includeContext {
+		scanner := bufio.NewScanner(bytes.NewReader(content))
+		lineNum := 0
+		for !c.fileFull(len(matches), size) && scanner.Scan() {
+			lineNum++
+			line := scanner.Text()
+			if !re.MatchString(line) {
+				continue
+			}
+			matchStart, matchEnd := calculateCharacterOffset(line, re)
+			m := SearchMatch{
+				File:       file,
+				LineNumber: lineNum,
+				Line:       line, // ✅ NO TrimSpace - mantener línea original
+				MatchStart: matchStart,
+				MatchEnd:   matchEnd,
+			}
+			matches = append(matches, m)
+			size += searchMatchSize(m)
+		}
+		return matches
+	}
+
+	lines := strings.Split(string(content), "\n")
+	for lineNum, line := range lines {
+		if c.fileFull(len(matches), size) {
+			break
+		}
+		if !re.MatchString(line) {
+			continue
+		}
+		matchStart, matchEnd := calculateCharacterOffset(line, re)
+		m := SearchMatch{
+			File:       file,
+			LineNumber: lineNum + 1,
+			Line:       line,
+			MatchStart: matchStart,
+			MatchEnd:   matchEnd,
+		}
+		start := max(0, lineNum-contextLines)
+		end := min(len(lines), lineNum+contextLines+1)
+		for i := start; i < end; i++ {
+			if i != lineNum {
+				m.Context = append(m.Context, strings.TrimSpace(lines[i]))
+			}
+		}
+		matches = append(matches, m)
+		size += searchMatchSize(m)
+	}
+	return matches
+}
+
+// searchLimitNotice tells the caller the results are partial
+func searchLimitNotice(l searchLimit, compact bool) string {
+	if l.Reason == "" {
+		return ""
+	}
+	if compact {
+		return fmt.Sprintf(" (stopped early after %d files: %s; partial)", l.Files, l.Reason)
+	}
+	return fmt.Sprintf("\n⚠️ Search stopped early after %d files: %s, so the results are partial. Narrow the path or pattern, or use count_only:true for totals.\n", l.Files, l.Reason)
+}
@@ -0,0 +1,102 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mcp/filesystem-ultra/mcp"
+)
+
+// writeMatchingTree writes n files of lines lines each across 20 packages;
+// every line matches "."
+func writeMatchingTree(t *testing.T, dir string, n, lines int) {
+	t.Helper()
+	body := strings.Repeat("every line matches the pattern\n", lines)
+	for i := 0; i < n; i++ {
+		sub := filepath.Join(dir, fmt.Sprintf("pkg%02d", i%20))
+		os.MkdirAll(sub, 0755)
+		if err := os.WriteFile(filepath.Join(sub, fmt.Sprintf("f%04d.txt", i)), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAdvancedTextSearch_StopsAtLimits(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	const files = 3000
+	writeMatchingTree(t, dir, files, 20)
+	engine.config.MaxSearchResults = 50
+	ctx := context.Background()
+
+	matches, limit, err := engine.performAdvancedTextSearch(ctx, dir, ".", true, false, true, 3, "text")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 50 || limit.Reason != "reached the 50-result limit" || limit.Files == 0 || limit.Files >= 100 {
+		t.Fatalf("got %d matches, limit %+v; want 50 matches after a few files", len(matches), limit)
+	}
+
+	// The byte budget applies to the match text, context lines included
+	engine.config.MaxSearchResults = 1_000_000
+	engine.config.MaxResponseSize = 16 * 1024
+	matches, limit, err = engine.performAdvancedTextSearch(ctx, dir, ".", true, false, true, 3, "text")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var size int64
+	for _, m := range matches {
+		size += searchMatchSize(m)
+	}
+	if !strings.HasSuffix(limit.Reason, "response size limit") || size < 16*1024 || size > 16*1024+1024 || limit.Files >= 100 {
+		t.Fatalf("byte limit: %d matches, %d bytes, limit %+v", len(matches), size, limit)
+	}
+
+	resp, err := engine.AdvancedTextSearch(ctx, mcp.CallToolRequest{Arguments: map[string]interface{}{"path": dir, "pattern": "pattern"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := resp.Content[0].Text; !strings.Contains(text, "⚠️ Search stopped early after ") || !strings.Contains(text, "reached the 16.0 KB response size limit") {
+		t.Fatalf("partial results not reported:\n%s", tail(text))
+	}
+
+	// A search that fits reports no limit
+	small := filepath.Join(dir, "pkg00")
+	engine.config.MaxResponseSize = 0
+	matches, limit, err = engine.performAdvancedTextSearch(ctx, small, "matches", true, false, false, 0, "text")
+	if err != nil || limit.Reason != "" || len(matches) != files/20*20 {
+		t.Fatalf("small tree: %d matches, limit %+v, err %v", len(matches), limit, err)
+	}
+}
+
+func TestSmartSearch_ContentStopsAtLimit(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	writeMatchingTree(t, dir, 2000, 10)
+	engine.config.MaxSearchResults = 25
+
+	out, err := engine.performSmartSearch(context.Background(), dir, "line", true, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resp FileSearchResponse
+	if err := json.Unmarshal([]byte(out), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.ContentMatches) != 25 || !resp.Truncated || resp.StopReason != "reached the 25-result limit" ||
+		resp.StoppedAfterFiles == 0 || resp.StoppedAfterFiles >= 100 {
+		t.Fatalf("got %d matches, truncated %v, stopped after %d files (%q)",
+			len(resp.ContentMatches), resp.Truncated, resp.StoppedAfterFiles, resp.StopReason)
+	}
+
+	text, err := engine.performSmartSearch(context.Background(), dir, "line", true, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text, "⚠️ Search stopped early after ") || !strings.Contains(text, "results are partial") {
+		t.Fatalf("partial results not reported:\n%s", tail(text))
+	}
+}
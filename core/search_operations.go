@@ -1,8 +1,6 @@
 package core
 
 import (
-	"bufio"
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -80,7 +78,8 @@ func (e *UltraFastEngine) SmartSearch(ctx context.Context, request mcp.CallToolR
 		}, nil
 	}
 
-	results, err := e.performSmartSearch(ctx, validPath, pattern, includeContent, fileTypes)
+	jsonOut := request.Arguments["output_format"] == "json"
+	results, err := e.performSmartSearch(ctx, validPath, pattern, includeContent, fileTypes, jsonOut)
 	if err != nil {
 		return &mcp.CallToolResponse{
 			Content: []mcp.TextContent{
@@ -163,7 +162,7 @@ func (e *UltraFastEngine) AdvancedTextSearch(ctx context.Context, request mcp.Ca
 		}, nil
 	}
 
-	matches, err := e.performAdvancedTextSearch(ctx, validPath, pattern, caseSensitive, wholeWord, includeContext, contextLines, outputFormat)
+	matches, limit, err := e.performAdvancedTextSearch(ctx, validPath, pattern, caseSensitive, wholeWord, includeContext, contextLines, outputFormat)
 	if err != nil {
 		return &mcp.CallToolResponse{
 			Content: []mcp.TextContent{
@@ -200,6 +199,7 @@ func (e *UltraFastEngine) AdvancedTextSearch(ctx context.Context, request mcp.Ca
 		// Doesn't honor CompactMode (the whole point of auto is to give
 		// Grep-like direct readability when there are few hits).
 		result.WriteString(formatSearchMatchesRipgrep(matches, maxToShow))
+		result.WriteString(searchLimitNotice(limit, false))
 	} else if e.config.CompactMode {
 		// Compact format: minimal output but with full paths
 		result.WriteString(fmt.Sprintf("%d matches", len(matches)))
@@ -234,6 +234,7 @@ func (e *UltraFastEngine) AdvancedTextSearch(ctx context.Context, request mcp.Ca
 		if len(matches) > maxToShow {
 			result.WriteString(fmt.Sprintf(" ... (%d more)", len(matches)-maxToShow))
 		}
+		result.WriteString(searchLimitNotice(limit, true))
 	} else {
 		// Verbose format
 		result.WriteString(fmt.Sprintf("🔍 Found %d matches for pattern '%s':\n\n", len(matches), pattern))
@@ -255,18 +256,22 @@ func (e *UltraFastEngine) AdvancedTextSearch(ctx context.Context, request mcp.Ca
 		if len(matches) > maxToShow {
 			result.WriteString(fmt.Sprintf("⚠️ Showing %d of %d matches. Use more specific pattern.\n", maxToShow, len(matches)))
 		}
+		result.WriteString(searchLimitNotice(limit, false))
 	}
 
 	// Execute post-search hook (best-effort)
 	hookCtx2.Event = HookPostSearch
 	hookCtx2.Metadata["match_count"] = len(matches)
+	if limit.Reason != "" {
+		hookCtx2.Metadata["stopped_after_files"] = limit.Files
+	}
 	_, _ = e.hookManager.ExecuteHooks(ctx, HookPostSearch, hookCtx2)
 
 	// JSON output format for AI parsing
 	if outputFormat == "json" {
 		return &mcp.CallToolResponse{
 			Content: []mcp.TextContent{
-				{Text: formatSearchMatchesJSON(matches, pattern, path)},
+				{Text: formatSearchMatchesJSON(matches, limit, pattern, path)},
 			},
 		}, nil
 	}
@@ -279,12 +284,16 @@ func (e *UltraFastEngine) AdvancedTextSearch(ctx context.Context, request mcp.Ca
 }
 
 // formatSearchMatchesJSON formats search matches as structured JSON for AI parsing
-func formatSearchMatchesJSON(matches []SearchMatch, pattern, path string) string {
+// A search that stopped early adds truncated, stopped_after_files and stop_reason.
+func formatSearchMatchesJSON(matches []SearchMatch, limit searchLimit, pattern, path string) string {
 	var buf strings.Builder
 	buf.WriteString("{\n")
 	buf.WriteString(fmt.Sprintf(`  "pattern": %s, `, jsonString(pattern)))
 	buf.WriteString(fmt.Sprintf(`  "path": %s, `, jsonString(path)))
 	buf.WriteString(fmt.Sprintf(`  "total_matches": %d, `, len(matches)))
+	if limit.Reason != "" {
+		buf.WriteString(fmt.Sprintf(`  "truncated": true, "stopped_after_files": %d, "stop_reason": %s, `, limit.Files, jsonString(limit.Reason)))
+	}
 	buf.WriteString(`  "matches": [`)
 
 	for i, m := range matches {
@@ -343,8 +352,9 @@ func formatSearchMatchesRipgrep(matches []SearchMatch, maxToShow int) string {
 	return b.String()
 }
 
-// performSmartSearch
-func (e *UltraFastEngine) performSmartSearch(ctx context.Context, path, pattern string, includeContent bool, fileTypes []string) (string, error) {
+// performSmartSearch walks path for filename (and, with includeContent,
+// content) matches. jsonOut renders a FileSearchResponse instead of prose.
+func (e *UltraFastEngine) performSmartSearch(ctx context.Context, path, pattern string, includeContent bool, fileTypes []string, jsonOut bool) (string, error) {
 	// Check context before starting
 	if err := ctx.Err(); err != nil {
 		return "", &ContextError{Op: "search", Details: "operation cancelled before start"}
@@ -352,7 +362,7 @@ func (e *UltraFastEngine) performSmartSearch(ctx context.Context, path, pattern
 
 	var resultsMu sync.Mutex
 	var results []string
-	var contentMatches []SearchMatch
+	col := e.newSearchCollector()
 	maxResults := e.config.MaxSearchResults
 
 	// Compile regex pattern (uses engine cache to avoid repeated compilation)
@@ -372,12 +382,13 @@ func (e *UltraFastEngine) performSmartSearch(ctx context.Context, path, pattern
 		}
 	}
 
-	// First pass: collect all files to search.
+	// Walk the tree, matching names and handing content candidates straight
+	// to the worker pool, so a full collector ends the walk.
 	// Perf (#1, v4.5.27): WalkDir instead of Walk — Walk lstats every entry,
 	// WalkDir reuses the DirEntry from ReadDir (one syscall per dir, not per
 	// file). On the 50k-entry trees behind the 5-45s searches in the proxy
 	// log this is the dominant cost.
-	var filesToSearch []string
+	var wg sync.WaitGroup
 	walkErr := filepath.WalkDir(path, func(currentPath string, d os.DirEntry, err error) error {
 		// Check context in walk callback
 		if ctxErr := ctx.Err(); ctxErr != nil {
@@ -399,6 +410,10 @@ func (e *UltraFastEngine) performSmartSearch(ctx context.Context, path, pattern
 		// Perf (#2, v4.5.27): early exit — once max_results filename matches
 		// are collected and no content search is pending, the rest of the
 		// tree cannot change the response. Walk used to continue to the end.
+		// A content search stops once its collector is full.
+		if includeContent && col.full() {
+			return errWalkStop
+		}
 		if !includeContent {
 			resultsMu.Lock()
 			full := len(results) >= maxResults
@@ -439,102 +454,64 @@ func (e *UltraFastEngine) performSmartSearch(ctx context.Context, path, pattern
 			resultsMu.Unlock()
 		}
 
-		// Add to content search list if applicable (stat only content candidates)
-		if includeContent && e.isTextFile(currentPath) {
-			if info, ierr := d.Info(); ierr == nil && info.Size() < 10*1024*1024 { // 10MB limit
-				filesToSearch = append(filesToSearch, currentPath)
+		// Search the content of text files on the worker pool. Glob patterns
+		// (e.g., "Reports.*") are for filename matching only: as a regex the *
+		// would be misread, so their content is not searched.
+		if includeContent && !isGlob && e.isTextFile(currentPath) {
+			if info, ierr := d.Info(); ierr == nil && info.Size() < searchMaxFileSize {
+				wg.Add(1)
+				task := func() {
+					defer wg.Done()
+					if ctx.Err() != nil || col.full() {
+						return
+					}
+					content, err := os.ReadFile(currentPath)
+					if err != nil {
+						return
+					}
+					col.add(scanFileMatches(currentPath, content, regexPattern, false, 0, col))
+				}
+				if e.workerPool == nil || e.workerPool.Submit(task) != nil {
+					task()
+				}
 			}
 		}
 
 		return nil
 	})
+	wg.Wait()
 	if walkErr != nil && walkErr != errWalkStop && !errors.Is(walkErr, context.Canceled) && !errors.Is(walkErr, context.DeadlineExceeded) {
 		return "", walkErr
 	}
+	contentMatches, limit := col.result()
 
-	// Second pass: parallel content search using worker pool
-	if includeContent && len(filesToSearch) > 0 {
-		var wg sync.WaitGroup
-
-		for _, filePath := range filesToSearch {
-			// Check context in main loop
-			if ctxErr := ctx.Err(); ctxErr != nil {
-				break // Exit if context is cancelled
-			}
-
-			// Check if we've reached max results
-			resultsMu.Lock()
-			if len(contentMatches) >= maxResults {
-				resultsMu.Unlock()
-				break
-			}
-			resultsMu.Unlock()
-
-			wg.Add(1)
-			currentFile := filePath
-
-			e.workerPool.Submit(func() {
-				defer wg.Done()
-
-				// Check context in worker
-				if ctxErr := ctx.Err(); ctxErr != nil {
-					return // Exit worker if context is cancelled
-				}
-
-				// Read file
-				content, err := os.ReadFile(currentFile)
-				if err != nil {
-					return
-				}
-
-				// Use bufio.Scanner for memory-efficient line processing
-				// Avoids allocating all lines at once (30-40% memory savings)
-				scanner := bufio.NewScanner(bytes.NewReader(content))
-				var localMatches []SearchMatch
-				lineNum := 0
-
-				for scanner.Scan() {
-					lineNum++
-					line := scanner.Text()
-					if !isGlob && regexPattern.MatchString(line) {
-						// Only do regex content search for non-glob patterns.
-						// Glob patterns (e.g., "Reports.*") are for filename matching only.
-						// Content search with glob patterns would misinterpret * as regex.
-						// Calculate character offset of pattern match
-						matchStart, matchEnd := calculateCharacterOffset(line, regexPattern)
-						match := SearchMatch{
-							File:       currentFile,
-							LineNumber: lineNum,
-							Line:       line, // ✅ NO TrimSpace - mantener línea original
-							MatchStart: matchStart,
-							MatchEnd:   matchEnd,
-						}
-						localMatches = append(localMatches, match)
-					}
-				}
+	totalResults := len(results) + len(contentMatches)
 
-				// Append local matches to global list
-				if len(localMatches) > 0 {
-					resultsMu.Lock()
-					for _, match := range localMatches {
-						if len(contentMatches) < maxResults {
-							contentMatches = append(contentMatches, match)
-						} else {
-							break
-						}
-					}
-					resultsMu.Unlock()
-				}
-			})
+	if jsonOut {
+		resp := FileSearchResponse{
+			Pattern:        pattern,
+			Path:           path,
+			Files:          make([]FoundFile, 0, len(results)),
+			ContentMatches: contentMatches,
+			Truncated:      totalResults >= maxResults || limit.Reason != "",
 		}
-
-		wg.Wait()
+		if limit.Reason != "" {
+			resp.StoppedAfterFiles, resp.StopReason = limit.Files, limit.Reason
+		}
+		for _, result := range results {
+			found := FoundFile{Path: strings.TrimPrefix(result, "📄 ")}
+			found.ContentType, _ = DetectFileContentType(found.Path)
+			resp.Files = append(resp.Files, found)
+		}
+		data, err := json.MarshalIndent(resp, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
 	}
 
 	var resultBuilder strings.Builder
 
-	totalResults := len(results) + len(contentMatches)
-
 	if len(results) > 0 {
 		if e.config.CompactMode {
 			resultBuilder.WriteString(fmt.Sprintf("%d filename matches", len(results)))
@@ -607,7 +584,9 @@ func (e *UltraFastEngine) performSmartSearch(ctx context.Context, path, pattern
 		return fmt.Sprintf("🔍 No matches found for pattern '%s' in %s", pattern, path), nil
 	}
 
-	if totalResults >= e.config.MaxSearchResults {
+	if limit.Reason != "" {
+		resultBuilder.WriteString(searchLimitNotice(limit, e.config.CompactMode))
+	} else if totalResults >= e.config.MaxSearchResults {
 		if e.config.CompactMode {
 			resultBuilder.WriteString(fmt.Sprintf(" (limited to %d)", e.config.MaxSearchResults))
 		} else {
@@ -618,19 +597,22 @@ func (e *UltraFastEngine) performSmartSearch(ctx context.Context, path, pattern
 	return resultBuilder.String(), nil
 }
 
-// performAdvancedTextSearch implements advanced text search with parallelization
-func (e *UltraFastEngine) performAdvancedTextSearch(ctx context.Context, path, pattern string, caseSensitive, wholeWord, includeContext bool, contextLines int, outputFormat string) ([]SearchMatch, error) {
-	var matchesMu sync.Mutex
-	var matches []SearchMatch
+// performAdvancedTextSearch implements advanced text search with parallelization.
+// Matches go into a searchCollector, and the walk stops queueing files once it
+// is full; the returned searchLimit says whether the results are partial.
+func (e *UltraFastEngine) performAdvancedTextSearch(ctx context.Context, path, pattern string, caseSensitive, wholeWord, includeContext bool, contextLines int, outputFormat string) ([]SearchMatch, searchLimit, error) {
+	col := e.newSearchCollector()
 
 	// Try ripgrep if available and output format is json
 	if e.ripgrepAvailable && outputFormat == "json" {
-		rgMatches, rgErr := e.RunRipgrepSearch(ctx, path, pattern, caseSensitive, wholeWord, includeContext, contextLines)
+		rgErr := e.runRipgrepSearch(ctx, path, pattern, caseSensitive, wholeWord, includeContext, contextLines, col)
 		if rgErr == nil {
-			return rgMatches, nil
+			matches, limit := col.result()
+			return matches, limit, nil
 		}
 		// Fall through to Go-native on error
 		slog.Debug("Ripgrep fallback", "reason", rgErr)
+		col = e.newSearchCollector()
 	}
 
 	// Prepare the pattern
@@ -644,15 +626,23 @@ func (e *UltraFastEngine) performAdvancedTextSearch(ctx context.Context, path, p
 
 	regexPattern, err := e.CompileRegex(searchPattern)
 	if err != nil {
-		return nil, fmt.Errorf("invalid regex pattern: %w", err)
+		return nil, searchLimit{}, fmt.Errorf("invalid regex pattern: %w", err)
 	}
 
-	// First pass: collect all files to search (WalkDir: no per-entry lstat, v4.5.27)
-	var filesToSearch []string
+	// Walk and search in one pass (WalkDir: no per-entry lstat, v4.5.27):
+	// each text file goes straight to the worker pool, so the walk can stop
+	// as soon as the collector is full instead of listing the whole tree first
+	var wg sync.WaitGroup
 	err = filepath.WalkDir(path, func(currentPath string, d os.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
 		if err != nil {
 			return nil
 		}
+		if col.full() {
+			return errWalkStop
+		}
 
 		// Prune common large/irrelevant directories
 		if d.IsDir() {
@@ -666,107 +656,43 @@ func (e *UltraFastEngine) performAdvancedTextSearch(ctx context.Context, path, p
 		if !e.isTextFile(currentPath) {
 			return nil
 		}
-		if info, ierr := d.Info(); ierr != nil || info.Size() > 10*1024*1024 { // 10MB limit
+		if info, ierr := d.Info(); ierr != nil || info.Size() > searchMaxFileSize {
 			return nil
 		}
 
-		filesToSearch = append(filesToSearch, currentPath)
-		return nil
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	// Second pass: parallel search using worker pool
-	var wg sync.WaitGroup
-
-	for _, filePath := range filesToSearch {
 		wg.Add(1)
-		currentFile := filePath
-
-		e.workerPool.Submit(func() {
+		task := func() {
 			defer wg.Done()
-
-			content, err := os.ReadFile(currentFile)
-			if err != nil {
+			if col.full() {
 				return
 			}
-
-			var localMatches []SearchMatch
-
-			// When context is not needed, use bufio.Scanner for memory efficiency
-			// When context is needed, use strings.Split (need forward-looking capability)
-			if !includeContext {
-				// Memory-efficient path: 30-40% memory savings with bufio.Scanner
-				scanner := bufio.NewScanner(bytes.NewReader(content))
-				lineNum := 0
-
-				for scanner.Scan() {
-					lineNum++
-					line := scanner.Text()
-
-					if regexPattern.MatchString(line) {
-						// Calculate character offset of pattern match
-						matchStart, matchEnd := calculateCharacterOffset(line, regexPattern)
-						match := SearchMatch{
-							File:       currentFile,
-							LineNumber: lineNum,
-							Line:       line, // ✅ NO TrimSpace - mantener línea original
-							MatchStart: matchStart,
-							MatchEnd:   matchEnd,
-						}
-						localMatches = append(localMatches, match)
-					}
-				}
-			} else {
-				// Context path: needs full file in memory for forward-looking
-				lines := strings.Split(string(content), "\n")
-
-				for lineNum, line := range lines {
-					if regexPattern.MatchString(line) {
-						// Calculate character offset of pattern match
-						matchStart, matchEnd := calculateCharacterOffset(line, regexPattern)
-						match := SearchMatch{
-							File:       currentFile,
-							LineNumber: lineNum + 1,
-							Line:       line, // ✅ NO TrimSpace - mantener línea original
-							MatchStart: matchStart,
-							MatchEnd:   matchEnd,
-						}
-
-						// Add context
-						var context []string
-						// Use built-in min/max (Go 1.21+) - no need for helper functions
-						start := max(0, lineNum-contextLines)
-						end := min(len(lines), lineNum+contextLines+1)
-
-						for i := start; i < end; i++ {
-							if i != lineNum {
-								context = append(context, strings.TrimSpace(lines[i]))
-							}
-						}
-						match.Context = context
-
-						localMatches = append(localMatches, match)
-					}
-				}
+			content, err := os.ReadFile(currentPath)
+			if err != nil {
+				return
 			}
+			col.add(scanFileMatches(currentPath, content, regexPattern, includeContext, contextLines, col))
+		}
+		if e.workerPool == nil || e.workerPool.Submit(task) != nil {
+			task()
+		}
+		return nil
+	})
+	wg.Wait()
 
-			// Append local matches to global list
-			if len(localMatches) > 0 {
-				matchesMu.Lock()
-				matches = append(matches, localMatches...)
-				matchesMu.Unlock()
-			}
-		})
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return nil, searchLimit{}, &ContextError{Op: "search", Details: "operation cancelled"}
+	}
+	if err != nil && err != errWalkStop {
+		return nil, searchLimit{}, err
 	}
 
-	wg.Wait()
-
-	return matches, nil
+	matches, limit := col.result()
+	return matches, limit, nil
 }
 
+// searchMaxFileSize is the largest file content searches read (10MB).
+const searchMaxFileSize = 10 * 1024 * 1024
+
 // searchSkipDirs are directories that should be skipped during search walks.
 // These are typically build artifacts, dependency caches, or VCS internals
 // that contain large numbers of files irrelevant to source-code searches.
@@ -783,6 +709,8 @@ func (e *UltraFastEngine) performAdvancedTextSearch(ctx context.Context, path, p
 	"__pycache__": true, ".venv": true, "venv": true, ".eggs": true,
 	// General build/cache dirs
 	"build": true, ".cache": true, ".tmp": true,
+	// Per-root soft-delete trash
+	RootTrashDirName: true,
 }
 
 // isGlobPattern returns true if the pattern contains glob wildcards (*, ?, [)
@@ -852,68 +780,16 @@ func isGlobPattern(pattern string) bool {
 	".lock": true, // Lock files (package-lock.json, yarn.lock, etc.)
 }
 
-// isTextFile determines if a file is likely a text file
+// isTextFile reports whether path holds UTF-8 text worth searching, by
+// DetectFileContentType. UTF-16/UTF-32 files (common for Windows .cs files
+// with non-ASCII characters) are skipped: matching lines would be garbled.
 func (e *UltraFastEngine) isTextFile(path string) bool {
-	// Check by extension first (O(1) map lookup - very fast)
-	ext := strings.ToLower(filepath.Ext(path))
-	if textExtensionsMap[ext] {
-		// Extension matched: still need to check for binary-in-text encodings (UTF-16, UTF-32)
-		// because content search would fail on garbled output
-		file, err := os.Open(path)
-		if err != nil {
-			return true // assume text if can't open (extension is authoritative)
-		}
-		defer file.Close()
-
-		// Read first 512 bytes to check for binary-in-text signatures
-		buffer := make([]byte, 512)
-		n, err := file.Read(buffer)
-		if err != nil && n == 0 {
-			return true
-		}
-
-		// Check for UTF-16 / UTF-32 BOM (common in Windows .cs files with non-ASCII chars)
-		// UTF-16 LE: FF FE, UTF-16 BE: FE FF, UTF-32 LE: FF FE 00 00, UTF-32 BE: 00 00 FE FF
-		if n >= 4 && ((buffer[0] == 0xFF && buffer[1] == 0xFE && buffer[2] == 0x00 && buffer[3] == 0x00) ||
-			(buffer[0] == 0x00 && buffer[1] == 0x00 && buffer[2] == 0xFE && buffer[3] == 0xFF)) {
-			return false // UTF-32 - skip from text search
-		}
-		if n >= 2 && ((buffer[0] == 0xFF && buffer[1] == 0xFE) || (buffer[0] == 0xFE && buffer[1] == 0xFF)) {
-			return false // UTF-16 - skip from text search
-		}
-
-		// Check for null bytes (common in binary files)
-		for i := 0; i < n; i++ {
-			if buffer[i] == 0 {
-				return false
-			}
-		}
-
-		return true
-	}
-
-	// If no extension or unknown extension, check content (slower)
-	file, err := os.Open(path)
+	ct, err := DetectFileContentType(path)
 	if err != nil {
-		return false
+		// Unreadable: the extension is all there is to go on
+		return textExtensionsMap[strings.ToLower(filepath.Ext(path))]
 	}
-	defer file.Close()
-
-	// Read first 512 bytes to check for binary content
-	buffer := make([]byte, 512)
-	n, err := file.Read(buffer)
-	if err != nil && n == 0 {
-		return false
-	}
-
-	// Check for null bytes (common in binary files)
-	for i := 0; i < n; i++ {
-		if buffer[i] == 0 {
-			return false
-		}
-	}
-
-	return true
+	return ct.Text()
 }
 
 // CountOccurrences counts occurrences of a pattern in a file and optionally returns line numbers
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSearchAndReplace_DirectoryParallelDeterministic(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	ctx := context.Background()
+
+	// Dependency directories are not pruned: every file below the root counts
+	dep := filepath.Join(dir, "node_modules", "dep.js")
+	os.MkdirAll(filepath.Dir(dep), 0755)
+	os.WriteFile(dep, []byte("oldName()\n"), 0644)
+	want := []string{fmt.Sprintf("📄 %s: 1 replacements", dep)}
+	for d := 0; d < 3; d++ {
+		sub := filepath.Join(dir, fmt.Sprintf("pkg%d", d), "inner")
+		os.MkdirAll(sub, 0755)
+		for f := 0; f < 15; f++ {
+			p := filepath.Join(sub, fmt.Sprintf("f%02d.go", f))
+			os.WriteFile(p, []byte(strings.Repeat("oldName()\n", f%3+1)), 0644)
+			want = append(want, fmt.Sprintf("📄 %s: %d replacements", p, f%3+1))
+		}
+	}
+	preview, err := engine.SearchAndReplace(ctx, dir, "oldName", "newName", true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := engine.SearchAndReplace(ctx, dir, "oldName", "newName", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := resp.Content[0].Text
+	if !strings.Contains(text, "Total replacements: 91\n") || !strings.HasSuffix(text, strings.Join(want, "\n")+"\n") {
+		t.Fatalf("report:\n%s", text)
+	}
+	if previewLines := strings.SplitN(preview.Content[0].Text, "\n\n", 2); len(previewLines) != 2 || previewLines[1] != strings.Join(want, "\n")+"\n" {
+		t.Fatalf("dry run lists files differently:\n%s", preview.Content[0].Text)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "pkg2", "inner", "f14.go")); string(data) != strings.Repeat("newName()\n", 3) {
+		t.Fatalf("content = %q", data)
+	}
+	if data, _ := os.ReadFile(dep); string(data) != "newName()\n" {
+		t.Fatalf("node_modules was skipped: %q", data)
+	}
+}
+
+func TestSearchAndReplace_DirectoryCancelled(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("x"), 0644)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var results []string
+	var total int
+	if err := engine.searchAndReplaceInDirectory(ctx, dir, "x", "y", true, false, &results, &total); err == nil {
+		t.Fatal("a cancelled replace should fail")
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "a.txt")); string(data) != "x" {
+		t.Fatalf("cancelled replace wrote %q", data)
+	}
+}
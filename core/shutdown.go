@@ -0,0 +1,172 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Graceful shutdown: on SIGINT/SIGTERM main cancels the root context, which
+// cancels the context of every tool call in flight, then calls Drain. Calls
+// react to the cancellation where they can (pipelines roll back from their
+// backup, batch_operations starts no further waves and an atomic batch rolls
+// back); Drain waits for them up to the grace period and logs what was
+// interrupted. Engine.Close then flushes the autosync queue, telemetry and
+// logs.
+
+// DefaultShutdownGrace is the --shutdown-grace default
+const DefaultShutdownGrace = 10 * time.Second
+
+// ErrShuttingDown is returned for work refused because the server is stopping
+var ErrShuttingDown = errors.New("server is shutting down")
+
+// InFlightCall is a tool call that had not returned when shutdown began
+type InFlightCall struct {
+	RequestID string        `json:"req_id"`
+	SessionID string        `json:"session_id,omitempty"`
+	Tool      string        `json:"tool"`
+	Path      string        `json:"path,omitempty"`
+	Started   time.Time     `json:"started"`
+	Running   time.Duration `json:"running_ns"`
+	BackupID  string        `json:"backup_id,omitempty"`
+}
+
+// ShutdownReport is the result of Drain
+type ShutdownReport struct {
+	Waited time.Duration `json:"waited_ns"`
+	// InFlight were running when shutdown began; Unfinished were still
+	// running when the grace period ran out
+	InFlight   []InFlightCall `json:"in_flight"`
+	Unfinished []InFlightCall `json:"unfinished"`
+}
+
+type inFlightKey struct{}
+
+// inFlightCall is the tracked state of one call; backupID is set from the
+// handler goroutine while Drain reads it
+type inFlightCall struct {
+	info     InFlightCall
+	mu       sync.Mutex
+	backupID string
+}
+
+// callTracker holds the tool calls in flight
+type callTracker struct {
+	mu       sync.Mutex
+	calls    map[*inFlightCall]struct{}
+	draining atomic.Bool
+}
+
+// TrackCall registers the tool call described by entry (tool, request and
+// session IDs, path) until the returned func is called. The returned context
+// lets the call's backup ID reach the shutdown report.
+func (e *UltraFastEngine) TrackCall(ctx context.Context, entry AuditEntry) (context.Context, func()) {
+	call := &inFlightCall{info: InFlightCall{RequestID: entry.RequestID, SessionID: entry.SessionID,
+		Tool: entry.Tool, Path: entry.Path, Started: entry.Timestamp}}
+	if call.info.Started.IsZero() {
+		call.info.Started = time.Now()
+	}
+	t := &e.calls
+	t.mu.Lock()
+	if t.calls == nil {
+		t.calls = make(map[*inFlightCall]struct{})
+	}
+	t.calls[call] = struct{}{}
+	t.mu.Unlock()
+	return context.WithValue(ctx, inFlightKey{}, call), func() {
+		t.mu.Lock()
+		delete(t.calls, call)
+		t.mu.Unlock()
+	}
+}
+
+// markInFlightBackup records the backup an in-flight call created
+func markInFlightBackup(ctx context.Context, backupID string) {
+	if call, ok := ctx.Value(inFlightKey{}).(*inFlightCall); ok && backupID != "" {
+		call.mu.Lock()
+		call.backupID = backupID
+		call.mu.Unlock()
+	}
+}
+
+// InFlightCalls returns the tool calls running now, oldest first
+func (e *UltraFastEngine) InFlightCalls() []InFlightCall {
+	now := time.Now()
+	e.calls.mu.Lock()
+	calls := make([]InFlightCall, 0, len(e.calls.calls))
+	for call := range e.calls.calls {
+		info := call.info
+		call.mu.Lock()
+		info.BackupID = call.backupID
+		call.mu.Unlock()
+		info.Running = now.Sub(info.Started)
+		calls = append(calls, info)
+	}
+	e.calls.mu.Unlock()
+	sort.Slice(calls, func(i, j int) bool { return calls[i].Started.Before(calls[j].Started) })
+	return calls
+}
+
+// ShuttingDown reports whether Drain has been called. Long-running work that
+// does not take a context (batch_operations) checks it between steps.
+func (e *UltraFastEngine) ShuttingDown() bool {
+	return e.calls.draining.Load()
+}
+
+// Drain marks the engine as shutting down and waits up to grace for the
+// in-flight tool calls to return (their contexts are expected to be cancelled
+// already). Every call still running when the grace period ends is logged and
+// written to the audit log as "interrupted", with the restore command when it
+// had created a backup.
+func (e *UltraFastEngine) Drain(grace time.Duration) ShutdownReport {
+	e.calls.draining.Store(true)
+	start := time.Now()
+	report := ShutdownReport{InFlight: e.InFlightCalls()}
+	if len(report.InFlight) > 0 {
+		slog.Info("Shutdown: waiting for in-flight tool calls", "calls", len(report.InFlight), "grace", grace)
+	}
+
+	deadline := time.NewTimer(grace)
+	defer deadline.Stop()
+	tick := time.NewTicker(20 * time.Millisecond)
+	defer tick.Stop()
+wait:
+	for len(e.InFlightCalls()) > 0 {
+		select {
+		case <-deadline.C:
+			break wait
+		case <-tick.C:
+		}
+	}
+	report.Waited = time.Since(start)
+	report.Unfinished = e.InFlightCalls()
+
+	for _, call := range report.Unfinished {
+		attrs := []any{"tool", call.Tool, "req_id", call.RequestID, "running", call.Running.Round(time.Millisecond)}
+		if call.Path != "" {
+			attrs = append(attrs, "path", call.Path)
+		}
+		if call.BackupID != "" {
+			attrs = append(attrs, "backup_id", call.BackupID,
+				"restore", fmt.Sprintf(`backup(action:"restore", backup_id:%q)`, call.BackupID))
+		}
+		slog.Warn("Shutdown interrupted tool call", attrs...)
+		e.Audit(AuditEntry{
+			Timestamp:  time.Now(),
+			Tool:       call.Tool,
+			RequestID:  call.RequestID,
+			SessionID:  call.SessionID,
+			Path:       call.Path,
+			Status:     "interrupted",
+			Error:      fmt.Sprintf("server shut down after %s grace period", grace),
+			DurationMs: call.Running.Milliseconds(),
+			BackupID:   call.BackupID,
+		})
+	}
+	return report
+}
@@ -0,0 +1,59 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShutdown_DrainWaitsAndReportsUnfinished(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+
+	_, finish := engine.TrackCall(context.Background(), AuditEntry{Tool: "read_file", RequestID: "r1"})
+	stuckCtx, untrack := engine.TrackCall(context.Background(), AuditEntry{Tool: "pipeline", RequestID: "r2", Path: dir})
+	defer untrack()
+	SetBackupID(stuckCtx, "bk-1", "")
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		finish()
+	}()
+
+	if engine.ShuttingDown() {
+		t.Fatal("shutting down before Drain")
+	}
+	report := engine.Drain(500 * time.Millisecond)
+	if !engine.ShuttingDown() {
+		t.Error("not shutting down after Drain")
+	}
+	if len(report.InFlight) != 2 || report.InFlight[0].Tool != "read_file" {
+		t.Errorf("in flight = %+v", report.InFlight)
+	}
+	if len(report.Unfinished) != 1 || report.Unfinished[0].RequestID != "r2" || report.Unfinished[0].BackupID != "bk-1" {
+		t.Errorf("unfinished = %+v", report.Unfinished)
+	}
+	if report.Waited < 500*time.Millisecond {
+		t.Errorf("waited %s, want the grace period", report.Waited)
+	}
+}
+
+func TestShutdown_BatchStartsNoWaves(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	mgr := NewBatchOperationManager(t.TempDir(), 10)
+	mgr.SetEngine(engine)
+	path := filepath.Join(dir, "a.txt")
+	os.WriteFile(path, []byte("before"), 0644)
+
+	engine.Drain(0)
+	res := mgr.ExecuteBatch(BatchRequest{Atomic: true, Operations: []FileOperation{
+		{Type: "write", Path: path, Content: "after"},
+	}})
+	if res.Success || res.CompletedOps != 0 || len(res.Errors) == 0 || !strings.Contains(res.Errors[0], ErrShuttingDown.Error()) {
+		t.Errorf("batch during shutdown = %+v", res)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "before" {
+		t.Errorf("file changed during shutdown: %q", got)
+	}
+}
@@ -64,12 +64,47 @@ func (e *UltraFastEngine) StreamingWriteFile(ctx context.Context, path, content
 	if !e.IsPathAllowed(path) {
 		return e.AccessDeniedError("streaming_write", path)
 	}
+	if err := e.CheckWritable("streaming_write", path); err != nil {
+		return err
+	}
 
-	// Quick path for small files - use MediumFileThreshold for cutoff
-	if len(content) <= MediumFileThreshold {
+	// Quick path for small files - use MediumFileThreshold for cutoff, unless
+	// --binary-threshold is lower and the content is over it
+	if len(content) <= MediumFileThreshold && !e.OverBinaryThreshold(int64(len(content))) {
 		return e.WriteFileContent(ctx, path, content)
 	}
 
+	// TOCTOU defense, as in WriteFileContent: write the re-authorized target
+	if resolved, err := e.ResolveAndAuthorize("streaming_write", path); err != nil {
+		return err
+	} else {
+		path = resolved
+	}
+
+	// Execute pre-write hooks. They see the content like a normal write, so
+	// the secret scan (which walks the string line by line without copying
+	// it) and content hooks apply above the threshold too.
+	workingDir, _ := os.Getwd()
+	hookCtx := &HookContext{
+		Event:      HookPreWrite,
+		ToolName:   "streaming_write_file",
+		FilePath:   path,
+		Operation:  "streaming_write",
+		Content:    content,
+		Timestamp:  time.Now(),
+		WorkingDir: workingDir,
+		Metadata:   map[string]interface{}{"size": len(content), "is_large": true},
+	}
+	if e.hookManager != nil {
+		hookResult, err := e.hookManager.ExecuteHooks(ctx, HookPreWrite, hookCtx)
+		if err != nil {
+			return fmt.Errorf("pre-write hook denied streaming write: %w", err)
+		}
+		if hookResult.ModifiedContent != "" {
+			content = hookResult.ModifiedContent
+		}
+	}
+
 	config := DefaultChunkingConfig()
 
 	// Calculate chunks
@@ -97,23 +132,6 @@ func (e *UltraFastEngine) StreamingWriteFile(ctx context.Context, path, content
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
 
-	// Execute pre-write hooks (without full content for large files to avoid memory blowup)
-	if e.hookManager != nil && e.hookManager.IsEnabled() {
-		workingDir, _ := os.Getwd()
-		hookCtx := &HookContext{
-			Event:      HookPreWrite,
-			ToolName:   "streaming_write_file",
-			FilePath:   path,
-			Operation:  "streaming_write",
-			Timestamp:  time.Now(),
-			WorkingDir: workingDir,
-			Metadata:   map[string]interface{}{"size": totalSize, "is_large": true},
-		}
-		if _, err := e.hookManager.ExecuteHooks(ctx, HookPreWrite, hookCtx); err != nil {
-			return fmt.Errorf("pre-write hook denied streaming write: %w", err)
-		}
-	}
-
 	// Create temp file for atomic operation with secure random suffix
 	tmpPath := path + ".streaming." + secureRandomSuffix()
 
@@ -123,6 +141,10 @@ func (e *UltraFastEngine) StreamingWriteFile(ctx context.Context, path, content
 		fileMode = existingInfo.Mode()
 	}
 
+	// EOL preservation as in WriteFileContent, judged from the start of the
+	// existing file so it is not read whole
+	eol := &eolConverter{eol: sniffEOL(path)}
+
 	// Open file for writing with O_SYNC for data integrity
 	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, fileMode)
 	if err != nil {
@@ -156,7 +178,7 @@ func (e *UltraFastEngine) StreamingWriteFile(ctx context.Context, path, content
 			end = totalSize
 		}
 
-		chunk := content[startPos:end]
+		chunk := eol.convert(content[startPos:end], end == totalSize)
 
 		// Write chunk through buffered writer
 		n, err := writer.WriteString(chunk)
@@ -179,11 +201,12 @@ func (e *UltraFastEngine) StreamingWriteFile(ctx context.Context, path, content
 
 	// Atomic rename
 	if err := os.Rename(tmpPath, path); err != nil {
-		return fmt.Errorf("failed to finalize file: %w", err)
+		return fmt.Errorf("failed to finalize file: %w", lockedError("write", path, err))
 	}
 
 	// Invalidate cache
 	e.invalidateMutatedPath(path)
+	e.recordOperation(ctx, OperationRecord{Operation: "streaming_write", Path: path, BytesWritten: int64(written)})
 
 	operation.Status = "completed"
 
@@ -199,24 +222,66 @@ func (e *UltraFastEngine) StreamingWriteFile(ctx context.Context, path, content
 		_ = e.autoSyncManager.AfterWrite(path)
 	}
 
-	// Post-write hook (best effort, no content for large files)
-	if e.hookManager != nil && e.hookManager.IsEnabled() {
-		workingDir, _ := os.Getwd()
-		hookCtx := &HookContext{
-			Event:      HookPostWrite,
-			ToolName:   "streaming_write_file",
-			FilePath:   path,
-			Operation:  "streaming_write",
-			Timestamp:  time.Now(),
-			WorkingDir: workingDir,
-			Metadata:   map[string]interface{}{"size": totalSize, "is_large": true},
-		}
+	// Post-write hook (best effort)
+	if e.hookManager != nil {
+		hookCtx.Event = HookPostWrite
+		hookCtx.Content = content
+		hookCtx.Metadata["size"] = totalSize
 		_, _ = e.hookManager.ExecuteHooks(ctx, HookPostWrite, hookCtx)
 	}
 
 	return nil
 }
 
+// WriteFileAuto is the write_file text path: content over --binary-threshold
+// goes through StreamingWriteFile, the rest through WriteFileContent
+func (e *UltraFastEngine) WriteFileAuto(ctx context.Context, path, content string) error {
+	if e.OverBinaryThreshold(int64(len(content))) {
+		AppendSubOp(ctx, "streaming_write")
+		return e.StreamingWriteFile(ctx, path, content)
+	}
+	return e.WriteFileContent(ctx, path, content)
+}
+
+// eolSniffBytes is how much of an existing file sniffEOL looks at
+const eolSniffBytes = 64 * 1024
+
+// sniffEOL returns the line-ending style of the start of the file at path,
+// "\n" when there is no such file or it has no line breaks yet
+func sniffEOL(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return "\n"
+	}
+	defer file.Close()
+	head, err := readWindow(file, 0, eolSniffBytes)
+	if err != nil {
+		return "\n"
+	}
+	return detectEOL(string(head))
+}
+
+// eolConverter rewrites the line endings of content written in chunks to
+// eol. A chunk ending in "\r" keeps it back until the next chunk shows
+// whether it starts a "\r\n".
+type eolConverter struct {
+	eol       string
+	pendingCR bool
+}
+
+func (c *eolConverter) convert(chunk string, final bool) string {
+	if c.eol == "\n" {
+		return chunk
+	}
+	if c.pendingCR {
+		chunk, c.pendingCR = "\r"+chunk, false
+	}
+	if !final && strings.HasSuffix(chunk, "\r") {
+		chunk, c.pendingCR = chunk[:len(chunk)-1], true
+	}
+	return restoreEOL(normalizeLineEndings(chunk), c.eol)
+}
+
 // ChunkedReadFile reads large files in chunks with optimized I/O
 // ULTRA-FAST: Uses pre-allocated buffer and efficient reading
 func (e *UltraFastEngine) ChunkedReadFile(ctx context.Context, path string, maxChunkSize int) (string, error) {
@@ -236,7 +301,7 @@ func (e *UltraFastEngine) ChunkedReadFile(ctx context.Context, path string, maxC
 	}
 
 	// Check if path is allowed (access control) - must check before any read path
-	if len(e.config.AllowedPaths) > 0 {
+	if len(e.GetAllowedPaths()) > 0 {
 		if !e.IsPathAllowed(path) {
 			return "", e.AccessDeniedError("chunked_read", path)
 		}
@@ -314,11 +379,14 @@ func (e *UltraFastEngine) SmartEditFile(ctx context.Context, path, oldText, newT
 	}
 
 	// Check if path is allowed (access control)
-	if len(e.config.AllowedPaths) > 0 {
+	if len(e.GetAllowedPaths()) > 0 {
 		if !e.IsPathAllowed(path) {
 			return nil, e.AccessDeniedError("smart_edit", path)
 		}
 	}
+	if err := e.CheckWritable("smart_edit", path); err != nil {
+		return nil, err
+	}
 
 	// Get file info first
 	info, err := os.Stat(path)
@@ -357,7 +425,7 @@ func (e *UltraFastEngine) streamingEditLargeFile(ctx context.Context, path, oldT
 	newText = normalizeLineEndings(newText)
 
 	// Risk assessment + backup for large file edits (Bug #16)
-	impact := CalculateChangeImpact(content, oldText, newText, e.riskThresholds)
+	impact := CalculateChangeImpact(content, oldText, newText, e.riskThresholds, path)
 
 	var backupID string
 	if e.backupManager != nil && impact.IsRisky {
@@ -435,9 +503,19 @@ func (e *UltraFastEngine) GetFileAnalysis(ctx context.Context, path string) (str
 		analysis.WriteString("Recommendation: Read in chunks, edit specific sections, or use search operations\n")
 	}
 
-	// File type detection
+	// File type detection: sniffed content first, the extension when the
+	// file cannot be read
 	ext := strings.ToLower(filepath.Ext(path))
-	if textExtensionsMap[ext] {
+	if ct, err := DetectFileContentType(path); err == nil {
+		switch {
+		case ct.Binary:
+			analysis.WriteString(fmt.Sprintf("Type: Binary file, %s (read-only recommended)\n", ct.MIME))
+		case !ct.Text():
+			analysis.WriteString(fmt.Sprintf("Type: Text file, %s (not UTF-8: search skips it)\n", ct.MIME))
+		default:
+			analysis.WriteString(fmt.Sprintf("Type: Text file, %s (editable)\n", ct.MIME))
+		}
+	} else if textExtensionsMap[ext] {
 		analysis.WriteString("Type: Text file (editable)\n")
 	} else if binaryExtensionsMap[ext] {
 		analysis.WriteString("Type: Binary file (read-only recommended)\n")
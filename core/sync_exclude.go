@@ -0,0 +1,208 @@
+package core
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultSyncExcludes are never copied by workspace sync or auto-sync:
+// version control metadata, dependency trees and tool caches. Build output
+// is left to .gitignore, since names like build/ or dist/ are ambiguous.
+var defaultSyncExcludes = []string{
+	".git/**", ".hg/**", ".svn/**",
+	"node_modules/**", "__pycache__/**", ".venv/**",
+	".pytest_cache/**", ".mypy_cache/**", ".next/**", ".gradle/**",
+	"*.pyc", ".DS_Store", "Thumbs.db",
+}
+
+// syncExcludes decides which paths a sync skips: the default excludes, extra
+// globs in never-cache syntax ("*.log", "dist/**", "tmp/*.json"; see
+// matchPathPattern) and the .gitignore files between root and the path.
+type syncExcludes struct {
+	patterns []string
+	ignores  *gitignoreCache
+}
+
+func newSyncExcludes(extra []string, ignores *gitignoreCache) *syncExcludes {
+	patterns := append([]string(nil), defaultSyncExcludes...)
+	for _, p := range extra {
+		if p = strings.TrimSpace(p); p != "" {
+			patterns = append(patterns, p)
+		}
+	}
+	return &syncExcludes{patterns: patterns, ignores: ignores}
+}
+
+// excluded reports whether p is skipped. Globs are matched against the path
+// relative to root (the whole path when root is ""); .gitignore files are
+// read from root down to p's directory.
+func (x *syncExcludes) excluded(root, p string, isDir bool) bool {
+	rel := filepath.ToSlash(filepath.Clean(p))
+	if root != "" {
+		r, err := filepath.Rel(root, p)
+		if err != nil || r == "." || strings.HasPrefix(r, "..") {
+			return false
+		}
+		rel = filepath.ToSlash(r)
+	}
+	segments := strings.Split(strings.TrimPrefix(rel, "/"), "/")
+
+	for _, pattern := range x.patterns {
+		if matchPathPattern(segments, pattern) != "" {
+			return true
+		}
+		// A directory matches "dir/**" itself, so a walk can prune it
+		if isDir && matchPathPattern(append(segments, ""), pattern) != "" {
+			return true
+		}
+	}
+
+	if root == "" || x.ignores == nil {
+		return false
+	}
+	ignored := false
+	dir := root
+	for i := range segments {
+		if gi := x.ignores.get(dir); gi != nil {
+			if match, negated := gi.match(segments[i:], isDir); match {
+				ignored = !negated
+			}
+		}
+		if i < len(segments)-1 {
+			dir = filepath.Join(dir, segments[i])
+		}
+	}
+	return ignored
+}
+
+// findRepoRoot returns the nearest directory above p holding .git, or ""
+func findRepoRoot(p string) string {
+	dir := filepath.Dir(filepath.Clean(p))
+	for i := 0; i < 64; i++ {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return ""
+}
+
+// gitignoreRule is one parsed .gitignore line
+type gitignoreRule struct {
+	segments []string // pattern split on "/"; "**" spans any number of segments
+	anchored bool     // contains a slash: matched from the .gitignore directory
+	dirOnly  bool     // trailing slash: matches directories only
+	negated  bool     // leading "!": re-includes a path
+}
+
+// gitignore holds the rules of one .gitignore file
+type gitignore struct {
+	rules   []gitignoreRule
+	modTime time.Time
+	size    int64
+}
+
+// parseGitignore parses .gitignore content. Supported: comments, "!"
+// negation, trailing "/" for directories, leading or inner "/" anchoring,
+// "**" and the usual * ? [] globs.
+func parseGitignore(content string) []gitignoreRule {
+	var rules []gitignoreRule
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var rule gitignoreRule
+		if strings.HasPrefix(line, "!") {
+			rule.negated = true
+			line = line[1:]
+		}
+		line = strings.TrimPrefix(line, `\`) // escaped leading # or !
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			rule.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		rule.segments = strings.Split(line, "/")
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// match applies the rules to a path relative to the .gitignore directory;
+// the last matching rule wins. A rule matching a parent directory covers
+// everything below it.
+func (g *gitignore) match(segments []string, isDir bool) (matched, negated bool) {
+	for _, rule := range g.rules {
+		if rule.matches(segments, isDir) {
+			matched, negated = true, rule.negated
+		}
+	}
+	return matched, negated
+}
+
+func (r gitignoreRule) matches(segments []string, isDir bool) bool {
+	for n := 1; n <= len(segments); n++ {
+		prefixIsDir := n < len(segments) || isDir
+		if r.dirOnly && !prefixIsDir {
+			continue
+		}
+		if r.anchored {
+			if matchGlobSegments(r.segments, segments[:n]) {
+				return true
+			}
+		} else if ok, _ := path.Match(r.segments[0], segments[n-1]); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// gitignoreCache keeps parsed .gitignore files by directory, re-reading a
+// file when its size or modification time changes
+type gitignoreCache struct {
+	mu    sync.Mutex
+	files map[string]*gitignore
+}
+
+func newGitignoreCache() *gitignoreCache {
+	return &gitignoreCache{files: make(map[string]*gitignore)}
+}
+
+// get returns the parsed .gitignore in dir, or nil when there is none
+func (c *gitignoreCache) get(dir string) *gitignore {
+	file := filepath.Join(dir, ".gitignore")
+	info, err := os.Stat(file)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil || !info.Mode().IsRegular() {
+		delete(c.files, dir)
+		return nil
+	}
+	if gi, ok := c.files[dir]; ok && gi.size == info.Size() && gi.modTime.Equal(info.ModTime()) {
+		return gi
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	gi := &gitignore{rules: parseGitignore(string(data)), modTime: info.ModTime(), size: info.Size()}
+	c.files[dir] = gi
+	return gi
+}
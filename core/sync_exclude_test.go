@@ -0,0 +1,104 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGitignore_Rules(t *testing.T) {
+	gi := &gitignore{rules: parseGitignore(`
+# build output
+/build/
+*.log
+!keep.log
+docs/**/*.tmp
+cache/
+\#notes
+`)}
+	cases := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"build", true, true},
+		{"build/out/app", false, true},
+		{"src/build", true, false}, // anchored to the .gitignore directory
+		{"build", false, false},    // directory-only rule
+		{"debug.log", false, true},
+		{"logs/deep/app.log", false, true},
+		{"keep.log", false, false},
+		{"docs/a/b/x.tmp", false, true},
+		{"docs/x.tmp", false, true},
+		{"x.tmp", false, false},
+		{"src/cache/data.bin", false, true},
+		{"#notes", false, true},
+		{"main.go", false, false},
+	}
+	for _, tc := range cases {
+		matched, negated := gi.match(strings.Split(tc.path, "/"), tc.isDir)
+		if got := matched && !negated; got != tc.want {
+			t.Errorf("%s (dir %v): ignored = %v, want %v", tc.path, tc.isDir, got, tc.want)
+		}
+	}
+}
+
+func TestSyncWorkspace_SkipsExcludedPaths(t *testing.T) {
+	engine, _ := setupProgressEngine(t)
+	wslDir, winDir := syncPair(t)
+	for _, f := range []string{
+		"main.go", "node_modules/pkg/index.js", ".git/HEAD", "build/app.bin",
+		"sub/trace.log", "sub/notes.md", "sub/.gitignore", "sub/gen/x.go",
+	} {
+		writeAt(t, filepath.Join(wslDir, f), f, time.Now())
+	}
+	os.WriteFile(filepath.Join(wslDir, ".gitignore"), []byte("/build/\n*.log\n"), 0644)
+	os.WriteFile(filepath.Join(wslDir, "sub", ".gitignore"), []byte("gen/\n"), 0644)
+
+	ws := &workspaceSync{engine: engine, direction: "wsl_to_windows", strategy: ConflictSkip,
+		excludes: newSyncExcludes([]string{"*.md"}, newGitignoreCache())}
+	ws.syncDirs(wslDir, winDir)
+
+	// .git, node_modules, build, trace.log, notes.md, gen
+	if len(ws.excluded) != 6 {
+		t.Errorf("excluded = %v", ws.excluded)
+	}
+	for _, f := range []string{"main.go", ".gitignore", "sub/.gitignore"} {
+		if _, err := os.Stat(filepath.Join(winDir, f)); err != nil {
+			t.Errorf("%s not synced: %v", f, err)
+		}
+	}
+	for _, f := range []string{"node_modules", ".git", "build", "sub/trace.log", "sub/notes.md", "sub/gen"} {
+		if _, err := os.Stat(filepath.Join(winDir, f)); err == nil {
+			t.Errorf("excluded %s was synced", f)
+		}
+	}
+}
+
+func TestAutoSync_ShouldSyncPathHonorsExcludes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("auto-sync only handles WSL (Unix) source paths")
+	}
+	repo := t.TempDir()
+	os.MkdirAll(filepath.Join(repo, ".git"), 0755)
+	os.WriteFile(filepath.Join(repo, ".gitignore"), []byte("dist/\n"), 0644)
+
+	cfg := DefaultAutoSyncConfig()
+	cfg.ExcludePatterns = []string{"*.tmp"}
+	m := &AutoSyncManager{config: cfg, enabled: true, isWSL: true, gitignores: newGitignoreCache()}
+
+	cases := map[string]bool{
+		"src/main.go":                true,
+		"node_modules/left-pad/x.js": false,
+		"dist/bundle.js":             false,
+		"scratch.tmp":                false,
+	}
+	for rel, want := range cases {
+		if got := m.ShouldSyncPath(filepath.Join(repo, rel)); got != want {
+			t.Errorf("ShouldSyncPath(%s) = %v, want %v", rel, got, want)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// pathVolume is volumeID; tests swap it to place trash roots on other volumes
+var pathVolume = volumeID
+
+// TrashMapping is where soft-deletes below one allowed root go
+type TrashMapping struct {
+	Root       string `json:"root"`
+	Trash      string `json:"trash"`
+	SameVolume bool   `json:"same_volume"` // false: deletes are copied across volumes, not renamed
+}
+
+// TrashStatus is the backup trash_status report: the configured trash
+// roots, the root each allowed path maps to, and what every trash root holds
+type TrashStatus struct {
+	TrashDir string           `json:"trash_dir,omitempty"` // --trash-dir
+	Default  string           `json:"default"`             // <backup-dir>/trash
+	Mappings []TrashMapping   `json:"mappings"`
+	Usage    []TrashRootUsage `json:"usage"`
+}
+
+// trashEnabled reports whether soft-deletes go to the discoverable trash
+// rather than the legacy walk-up location
+func (e *UltraFastEngine) trashEnabled() bool {
+	return e.backupManager != nil && (e.config.BackupDir != "" || e.config.TrashDir != "")
+}
+
+// rootTrashDirs is the .mcp-trash of every allowed root
+func (e *UltraFastEngine) rootTrashDirs() []string {
+	allowed := e.GetAllowedPaths()
+	dirs := make([]string, 0, len(allowed))
+	for _, root := range allowed {
+		dirs = append(dirs, filepath.Join(absOrSelf(NormalizePath(root)), RootTrashDirName))
+	}
+	return dirs
+}
+
+// trashDirs lists every directory that holds trash entries. Their
+// metadata.json decides where restore_trash puts a file back, so tools must
+// not write into them (see protectionReason).
+func (e *UltraFastEngine) trashDirs() []string {
+	dirs := e.rootTrashDirs()
+	if e.config.TrashDir != "" {
+		dirs = append(dirs, absOrSelf(NormalizePath(e.config.TrashDir)))
+	}
+	if bm := e.backupManager; bm != nil {
+		dirs = append(dirs, absOrSelf(bm.defaultTrashRoot()), absOrSelf(filepath.Join(bm.backupDir, legacyTrashSubdir)))
+	}
+	return dirs
+}
+
+// trashDirHolding returns the trash directory path is in, or ""
+func (e *UltraFastEngine) trashDirHolding(path string) string {
+	abs := protectedRuleKey(protectedAbs(path))
+	for _, dir := range e.trashDirs() {
+		if pathWithin(abs, protectedRuleKey(dir)) {
+			return dir
+		}
+	}
+	return ""
+}
+
+// authorizeTrashRestore checks where restore_trash may put an entry back.
+// The original path comes from the entry's metadata.json, so it is treated
+// like any other write target and the resolved path is returned.
+func (e *UltraFastEngine) authorizeTrashRestore(path string) (string, error) {
+	if !e.IsPathAllowed(path) {
+		return "", e.AccessDeniedError("restore_trash", path)
+	}
+	resolved, err := e.ResolveAndAuthorize("restore_trash", path)
+	if err != nil {
+		return "", err
+	}
+	if err := e.CheckWritable("restore_trash", resolved); err != nil {
+		return "", err
+	}
+	return resolved, nil
+}
+
+// trashLocation returns the allowed root holding path and where path goes
+// inside its trash entry: its path below that root. With several matching
+// roots the nearest wins; outside every root, root is "" and rel is the
+// absolute path without its volume.
+func (e *UltraFastEngine) trashLocation(path string) (root, rel string) {
+	abs := absOrSelf(path)
+	roots := append([]string{}, e.GetAllowedPaths()...)
+	if bases, ok := e.allowedBases(); ok {
+		roots = append(roots, bases...)
+	}
+	for _, candidate := range roots {
+		candidate = absOrSelf(NormalizePath(candidate))
+		r, err := filepath.Rel(candidate, abs)
+		if err != nil || r == "." || r == ".." || strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if rel == "" || len(r) < len(rel) {
+			root, rel = candidate, r
+		}
+	}
+	if rel == "" {
+		rel = strings.TrimLeft(abs[len(filepath.VolumeName(abs)):], `/\`)
+	}
+	return root, rel
+}
+
+// TrashStatus reports the active trash mapping: for every allowed root, the
+// trash root its soft-deletes go to and whether that move is a rename
+func (e *UltraFastEngine) TrashStatus() (*TrashStatus, error) {
+	if !e.trashEnabled() {
+		return nil, fmt.Errorf("trash is not configured: soft-deletes use the legacy filesdelete/ location; set --backup-dir or --trash-dir")
+	}
+	bm := e.backupManager
+	bm.mutex.RLock()
+	status := &TrashStatus{TrashDir: bm.trashDir, Default: bm.defaultTrashRoot(), Mappings: []TrashMapping{}}
+	for _, root := range e.GetAllowedPaths() {
+		root = absOrSelf(NormalizePath(root))
+		trash, same := bm.chooseTrashRoot(root, root)
+		status.Mappings = append(status.Mappings, TrashMapping{Root: root, Trash: trash, SameVolume: same})
+	}
+	bm.mutex.RUnlock()
+
+	usage, err := bm.TrashUsage()
+	if err != nil {
+		return nil, err
+	}
+	status.Usage = usage
+	return status, nil
+}
+
+// FormatTrashStatus renders a TrashStatus as text
+func FormatTrashStatus(s *TrashStatus) string {
+	var sb strings.Builder
+	sb.WriteString("Trash roots (first on the deleted file's volume wins):\n")
+	if s.TrashDir != "" {
+		fmt.Fprintf(&sb, "  --trash-dir: %s\n", s.TrashDir)
+	}
+	fmt.Fprintf(&sb, "  backup dir:  %s\n", s.Default)
+	fmt.Fprintf(&sb, "  per root:    <root>/%s\n", RootTrashDirName)
+	if len(s.Mappings) > 0 {
+		sb.WriteString("\nMapping:\n")
+		for _, m := range s.Mappings {
+			fmt.Fprintf(&sb, "  %s → %s", m.Root, m.Trash)
+			if !m.SameVolume {
+				sb.WriteString("  (WARNING: other volume, deletes are copied + removed)")
+			}
+			sb.WriteString("\n")
+		}
+	}
+	sb.WriteString("\nContents:\n")
+	for _, u := range s.Usage {
+		fmt.Fprintf(&sb, "  %s: %d entries, %s\n", u.Dir, u.Entries, formatSize(u.Bytes))
+	}
+	return sb.String()
+}
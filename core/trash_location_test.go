@@ -0,0 +1,166 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mcp/filesystem-ultra/cache"
+)
+
+// placeVolumes makes pathVolume report the volume of the first prefix a
+// path lies under, and "other" for the rest
+func placeVolumes(t *testing.T, volumes map[string]string) {
+	pathVolume = func(path string) string {
+		for prefix, vol := range volumes {
+			if pathInside(prefix, path) {
+				return vol
+			}
+		}
+		return "other"
+	}
+	t.Cleanup(func() { pathVolume = volumeID })
+}
+
+func TestTrashLocation_PerRootDefault(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	placeVolumes(t, map[string]string{dir: "A"})
+	target := filepath.Join(dir, "docs", "a.txt")
+	os.MkdirAll(filepath.Dir(target), 0755)
+	os.WriteFile(target, []byte("alpha\n"), 0644)
+
+	info, err := engine.SoftDeleteFile(context.Background(), target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := filepath.Join(absOrSelf(dir), RootTrashDirName, strings.TrimPrefix(info.SDID, "sd-"), "docs", "a.txt")
+	if info.DestPath != want || info.CrossVolume {
+		t.Fatalf("dest = %s (cross volume %v), want %s", info.DestPath, info.CrossVolume, want)
+	}
+
+	status, err := engine.TrashStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status.Mappings) != 1 || status.Mappings[0].Trash != filepath.Join(absOrSelf(dir), RootTrashDirName) || !status.Mappings[0].SameVolume {
+		t.Fatalf("mappings = %+v", status.Mappings)
+	}
+	found := false
+	for _, u := range status.Usage {
+		found = found || (strings.HasSuffix(u.Dir, RootTrashDirName) && u.Entries == 1 && u.Bytes == 6)
+	}
+	if !found {
+		t.Fatalf("usage = %+v", status.Usage)
+	}
+
+	if _, err := engine.SoftDeleteFile(context.Background(), filepath.Join(dir, RootTrashDirName)); err == nil {
+		t.Fatal("soft-deleting the trash itself should be refused")
+	}
+	entries, err := engine.backupManager.ListTrash(0, "", 0)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("list = %+v, %v", entries, err)
+	}
+	if _, err := engine.backupManager.RestoreTrash(info.SDID); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "alpha\n" {
+		t.Fatalf("restored content = %q", data)
+	}
+}
+
+func TestTrashLocation_TrashDirPreferred(t *testing.T) {
+	dir, trashDir := t.TempDir(), t.TempDir()
+	c, err := cache.NewIntelligentCache(1024 * 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine, err := NewUltraFastEngine(&Config{Cache: c, AllowedPaths: []string{dir}, ParallelOps: 2, BackupDir: t.TempDir(), TrashDir: trashDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { engine.Close(); c.Close() })
+	target := filepath.Join(dir, "b.txt")
+	os.WriteFile(target, []byte("beta\n"), 0644)
+
+	info, err := engine.SoftDeleteFile(context.Background(), target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pathInside(trashDir, info.DestPath) {
+		t.Fatalf("dest = %s, want it under --trash-dir %s", info.DestPath, trashDir)
+	}
+}
+
+func TestTrashLocation_CrossVolumeFallback(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	placeVolumes(t, map[string]string{dir: "A"})
+	simulateCrossDevice(t)
+	bm := engine.backupManager
+	target := filepath.Join(dir, "c.txt")
+	os.WriteFile(target, []byte("gamma\n"), 0644)
+
+	// Outside every allowed root only the backup trash, on another volume, is a candidate
+	info, err := bm.SoftDeleteFile(target, "c.txt", "", "delete_file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.CrossVolume || !pathInside(bm.defaultTrashRoot(), info.DestPath) {
+		t.Fatalf("info = %+v", info)
+	}
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Fatalf("source still present: %v", err)
+	}
+	if data, _ := os.ReadFile(info.DestPath); string(data) != "gamma\n" {
+		t.Fatalf("trashed content = %q", data)
+	}
+	if _, err := bm.RestoreTrash(info.SDID); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "gamma\n" {
+		t.Fatalf("restored content = %q", data)
+	}
+}
+
+func TestRestoreTrash_ForgedEntryStaysInAllowedPaths(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	ctx := context.Background()
+	entry := filepath.Join(dir, RootTrashDirName, "20260101-120000-0123456789abcdef")
+
+	// Tools cannot write a trash entry
+	if err := engine.WriteFileContent(ctx, filepath.Join(entry, "metadata.json"), "{}"); err == nil || !strings.Contains(err.Error(), "inside the trash") {
+		t.Fatalf("write into the trash: %v", err)
+	}
+	if err := engine.CheckRemovable("move", filepath.Join(dir, RootTrashDirName)); err == nil {
+		t.Fatal("the trash can be moved away")
+	}
+
+	// An entry planted behind the engine's back is not restored outside the roots
+	outside := filepath.Join(t.TempDir(), "planted.sh")
+	os.MkdirAll(entry, 0755)
+	os.WriteFile(filepath.Join(entry, "planted.sh"), []byte("echo pwned\n"), 0644)
+	os.WriteFile(filepath.Join(entry, "metadata.json"), []byte(`{"sd_id":"sd-20260101-120000-0123456789abcdef","original_path":`+
+		jsonQuote(outside)+`,"rel_path":"planted.sh","kind":"soft_delete"}`), 0600)
+	if _, err := engine.backupManager.RestoreTrash("sd-20260101-120000-0123456789abcdef"); err == nil || !strings.Contains(err.Error(), "access denied") {
+		t.Fatalf("forged restore: %v", err)
+	}
+	if _, err := os.Stat(outside); !os.IsNotExist(err) {
+		t.Fatalf("file placed outside the allowed paths: %v", err)
+	}
+
+	// Nor into a write-protected path inside them
+	engine.config.ProtectedPaths = []string{"*.sh"}
+	os.WriteFile(filepath.Join(entry, "metadata.json"), []byte(`{"sd_id":"sd-20260101-120000-0123456789abcdef","original_path":`+
+		jsonQuote(filepath.Join(dir, "run.sh"))+`,"rel_path":"planted.sh","kind":"soft_delete"}`), 0600)
+	if _, err := engine.backupManager.RestoreTrash("sd-20260101-120000-0123456789abcdef"); err == nil || !strings.Contains(err.Error(), "write-protected") {
+		t.Fatalf("restore over protection: %v", err)
+	}
+}
+
+// jsonQuote renders s as a JSON string literal
+func jsonQuote(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package core
+
+// volumeID is not implemented on this platform: every path reports the same
+// unknown volume and cross-volume moves are only detected when a rename fails
+func volumeID(path string) string {
+	return ""
+}
@@ -0,0 +1,25 @@
+//go:build linux || darwin || freebsd
+
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+	"syscall"
+)
+
+// volumeID identifies the filesystem holding path (its st_dev), or of its
+// nearest existing ancestor when path does not exist yet; "" when unknown
+func volumeID(path string) string {
+	var st syscall.Stat_t
+	for p := absOrSelf(path); ; {
+		if err := syscall.Stat(p, &st); err == nil {
+			return fmt.Sprint(st.Dev)
+		}
+		parent := filepath.Dir(p)
+		if parent == p {
+			return ""
+		}
+		p = parent
+	}
+}
@@ -0,0 +1,14 @@
+//go:build windows
+
+package core
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// volumeID identifies the volume holding path: its drive letter or UNC
+// share, case-folded; "" when path has none
+func volumeID(path string) string {
+	return strings.ToUpper(filepath.VolumeName(absOrSelf(path)))
+}
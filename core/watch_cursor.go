@@ -0,0 +1,251 @@
+package core
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// analyze_directory watch reports what changed under a directory between two calls
+// without a background watcher: each call snapshots the files (name, size,
+// mtime), the cursor is a hash of that snapshot, and the latest snapshot of
+// each watched path is kept in a bounded LRU to diff the next call against.
+
+// DefaultMaxWatchSnapshots bounds the paths analyze_directory watch keeps a snapshot
+// for when Config.MaxWatchSnapshots is 0
+const DefaultMaxWatchSnapshots = 64
+
+// watchMaxFiles bounds one snapshot; larger trees must be watched in parts
+const watchMaxFiles = 20000
+
+// fileStamp is what a snapshot records of one file
+type fileStamp struct {
+	size  int64
+	mtime int64 // unix nanoseconds
+}
+
+// dirSnapshot is the state of a watched directory at one call
+type dirSnapshot struct {
+	path   string
+	cursor string
+	files  map[string]fileStamp // slash-separated path relative to the root
+}
+
+// DirChanges is the analyze_directory watch response
+type DirChanges struct {
+	Path   string `json:"path"`
+	Cursor string `json:"cursor"` // pass back on the next call
+	Files  int    `json:"files"`  // files in the current snapshot
+	// Initial is set when there was nothing to diff against: first call, or
+	// Expired when the cursor passed in is no longer the stored snapshot
+	Initial  bool     `json:"initial,omitempty"`
+	Expired  bool     `json:"expired,omitempty"`
+	Added    []string `json:"added"`
+	Removed  []string `json:"removed"`
+	Modified []string `json:"modified"`
+	// Changed counts every change; the lists stop at MaxSearchResults each
+	Changed    int   `json:"changed"`
+	Truncated  bool  `json:"truncated,omitempty"`
+	DurationMs int64 `json:"duration_ms"`
+}
+
+// watchSnapshotStore keeps the latest snapshot of up to max paths, evicting
+// the least recently used
+type watchSnapshotStore struct {
+	mu      sync.Mutex
+	max     int
+	order   *list.List // *dirSnapshot, most recently used first
+	entries map[string]*list.Element
+}
+
+func newWatchSnapshotStore(max int) *watchSnapshotStore {
+	if max <= 0 {
+		max = DefaultMaxWatchSnapshots
+	}
+	return &watchSnapshotStore{max: max, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// swap stores snap as the latest snapshot of its path and returns the
+// previous one (nil if none)
+func (s *watchSnapshotStore) swap(snap *dirSnapshot) *dirSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.entries[snap.path]; ok {
+		prev := el.Value.(*dirSnapshot)
+		el.Value = snap
+		s.order.MoveToFront(el)
+		return prev
+	}
+	s.entries[snap.path] = s.order.PushFront(snap)
+	for s.order.Len() > s.max {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*dirSnapshot).path)
+	}
+	return nil
+}
+
+// WatchDirectory snapshots the files under path and, when cursor is the
+// snapshot stored by the previous call, reports the files added, removed
+// and modified (size or mtime) since then. Without a cursor, or with one
+// that was superseded or evicted, it returns a fresh cursor and no changes.
+// The default excludes (.git, node_modules, ...) are skipped.
+func (e *UltraFastEngine) WatchDirectory(ctx context.Context, path, cursor string) (*DirChanges, error) {
+	path = NormalizePath(path)
+	if err := e.acquireOperation(ctx, "list"); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	defer e.releaseOperation("list", start)
+
+	if !e.IsPathAllowed(path) {
+		return nil, e.AccessDeniedError("watch_directory", path)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, &PathError{Op: "watch_directory", Path: path, Err: err}
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("not a directory: %s", path)
+	}
+	root, err := filepath.Abs(path)
+	if err != nil {
+		root = path
+	}
+
+	snap, err := takeDirSnapshot(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	prev := e.watchSnapshots.swap(snap)
+
+	out := &DirChanges{Path: root, Cursor: snap.cursor, Files: len(snap.files),
+		Added: []string{}, Removed: []string{}, Modified: []string{}}
+	switch {
+	case cursor == "":
+		out.Initial = true
+	case prev == nil || prev.cursor != cursor:
+		out.Initial, out.Expired = true, true
+	default:
+		for rel, stamp := range snap.files {
+			old, ok := prev.files[rel]
+			switch {
+			case !ok:
+				out.Added = append(out.Added, rel)
+			case old != stamp:
+				out.Modified = append(out.Modified, rel)
+			}
+		}
+		for rel := range prev.files {
+			if _, ok := snap.files[rel]; !ok {
+				out.Removed = append(out.Removed, rel)
+			}
+		}
+		out.Changed = len(out.Added) + len(out.Removed) + len(out.Modified)
+		for _, l := range []*[]string{&out.Added, &out.Removed, &out.Modified} {
+			sort.Strings(*l)
+			if max := e.config.MaxSearchResults; max > 0 && len(*l) > max {
+				*l = (*l)[:max]
+				out.Truncated = true
+			}
+		}
+	}
+	out.DurationMs = time.Since(start).Milliseconds()
+	return out, nil
+}
+
+// takeDirSnapshot records the regular files under root and hashes the sorted
+// listing into the cursor
+func takeDirSnapshot(ctx context.Context, root string) (*dirSnapshot, error) {
+	snap := &dirSnapshot{path: root, files: make(map[string]fileStamp)}
+	excludes := newSyncExcludes(nil, nil)
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if excludes.excluded(root, p, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if len(snap.files) >= watchMaxFiles {
+			return fmt.Errorf("more than %d files under %s; watch a narrower directory", watchMaxFiles, root)
+		}
+		rel, _ := filepath.Rel(root, p)
+		snap.files[filepath.ToSlash(rel)] = fileStamp{info.Size(), info.ModTime().UnixNano()}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(snap.files))
+	for rel := range snap.files {
+		names = append(names, rel)
+	}
+	sort.Strings(names)
+	hash := sha256.New()
+	for _, rel := range names {
+		stamp := snap.files[rel]
+		fmt.Fprintf(hash, "%s\x00%d\x00%d\n", rel, stamp.size, stamp.mtime)
+	}
+	snap.cursor = hex.EncodeToString(hash.Sum(nil))[:16]
+	return snap, nil
+}
+
+// FormatDirChanges renders a DirChanges as text
+func FormatDirChanges(c *DirChanges, compact bool) string {
+	if c.Initial {
+		note := "snapshot taken"
+		if c.Expired {
+			note = "cursor expired (superseded or evicted), new snapshot taken"
+		}
+		return fmt.Sprintf("%s: %s, %d files | cursor:%s", c.Path, note, c.Files, c.Cursor)
+	}
+	if c.Changed == 0 {
+		return fmt.Sprintf("%s: no changes (%d files) | cursor:%s", c.Path, c.Files, c.Cursor)
+	}
+	if compact {
+		return fmt.Sprintf("%s: +%d -%d ~%d | cursor:%s | added: %s | removed: %s | modified: %s",
+			c.Path, len(c.Added), len(c.Removed), len(c.Modified), c.Cursor,
+			strings.Join(c.Added, ","), strings.Join(c.Removed, ","), strings.Join(c.Modified, ","))
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%d changes under %s (%d files now)\n", c.Changed, c.Path, c.Files))
+	for _, group := range []struct {
+		mark  string
+		paths []string
+	}{{"+", c.Added}, {"-", c.Removed}, {"~", c.Modified}} {
+		for _, p := range group.paths {
+			sb.WriteString(fmt.Sprintf("%s %s\n", group.mark, p))
+		}
+	}
+	if c.Truncated {
+		sb.WriteString("… lists capped at max search results\n")
+	}
+	sb.WriteString(fmt.Sprintf("cursor: %s", c.Cursor))
+	return sb.String()
+}
@@ -0,0 +1,80 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatchDirectory_ReportsChangesSinceCursor(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	write := func(rel, content string) {
+		full := filepath.Join(dir, rel)
+		os.MkdirAll(filepath.Dir(full), 0755)
+		os.WriteFile(full, []byte(content), 0644)
+	}
+	write("src/main.go", "package main")
+	write("dist/app.js", "v1")
+	write("dist/old.js", "old")
+	write("node_modules/dep/index.js", "x")
+
+	first, err := engine.WatchDirectory(context.Background(), dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !first.Initial || first.Expired || first.Files != 3 || first.Cursor == "" {
+		t.Fatalf("first = %+v", first)
+	}
+
+	same, _ := engine.WatchDirectory(context.Background(), dir, first.Cursor)
+	if same.Initial || same.Changed != 0 || same.Cursor != first.Cursor {
+		t.Errorf("unchanged = %+v", same)
+	}
+
+	write("dist/app.js", "v2 build")
+	later := time.Now().Add(time.Second)
+	os.Chtimes(filepath.Join(dir, "src/main.go"), later, later)
+	os.Remove(filepath.Join(dir, "dist/old.js"))
+	write("dist/chunk.js", "new")
+	write("node_modules/dep/other.js", "ignored")
+
+	changes, err := engine.WatchDirectory(context.Background(), dir, same.Cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changes.Initial || changes.Changed != 4 || changes.Cursor == first.Cursor {
+		t.Fatalf("changes = %+v", changes)
+	}
+	if strings.Join(changes.Added, ",") != "dist/chunk.js" || strings.Join(changes.Removed, ",") != "dist/old.js" ||
+		strings.Join(changes.Modified, ",") != "dist/app.js,src/main.go" {
+		t.Errorf("added %v removed %v modified %v", changes.Added, changes.Removed, changes.Modified)
+	}
+	if text := FormatDirChanges(changes, false); !strings.Contains(text, "+ dist/chunk.js") || !strings.Contains(text, "cursor: "+changes.Cursor) {
+		t.Errorf("text:\n%s", text)
+	}
+
+	// The superseded cursor no longer diffs
+	stale, _ := engine.WatchDirectory(context.Background(), dir, first.Cursor)
+	if !stale.Initial || !stale.Expired || stale.Cursor != changes.Cursor {
+		t.Errorf("stale = %+v", stale)
+	}
+}
+
+func TestWatchSnapshotStore_EvictsLeastRecentlyUsed(t *testing.T) {
+	store := newWatchSnapshotStore(2)
+	store.swap(&dirSnapshot{path: "/a", cursor: "a1"})
+	store.swap(&dirSnapshot{path: "/b", cursor: "b1"})
+	if prev := store.swap(&dirSnapshot{path: "/a", cursor: "a2"}); prev == nil || prev.cursor != "a1" {
+		t.Fatalf("prev /a = %+v", prev)
+	}
+	store.swap(&dirSnapshot{path: "/c", cursor: "c1"}) // evicts /b
+	if prev := store.swap(&dirSnapshot{path: "/b", cursor: "b2"}); prev != nil {
+		t.Errorf("/b survived eviction: %+v", prev)
+	}
+	if prev := store.swap(&dirSnapshot{path: "/c", cursor: "c2"}); prev == nil || prev.cursor != "c1" {
+		t.Errorf("prev /c = %+v", prev)
+	}
+}
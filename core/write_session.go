@@ -0,0 +1,334 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultWriteSessionTTL is how long a write session may sit idle before it
+// is abandoned and its temp file removed, when --write-session-ttl is not set
+const DefaultWriteSessionTTL = 15 * time.Minute
+
+// WriteSessionInfo describes a write session after begin or append
+type WriteSessionInfo struct {
+	SessionID string    `json:"session_id"`
+	Path      string    `json:"path"`
+	Chunks    int       `json:"chunks"` // received so far; the next index to send
+	Bytes     int64     `json:"bytes"`
+	ExpiresAt time.Time `json:"expires_at"` // unless another chunk arrives first
+}
+
+// WriteSessionResult is the result of committing a write session
+type WriteSessionResult struct {
+	SessionID string `json:"session_id"`
+	Path      string `json:"path"`
+	Chunks    int    `json:"chunks"`
+	Bytes     int64  `json:"bytes"`
+	SHA256    string `json:"sha256"`
+	Created   bool   `json:"created,omitempty"`
+	BackupID  string `json:"backup_id,omitempty"`
+}
+
+// writeSession streams the chunks of one file into a temp file beside it
+type writeSession struct {
+	id       string
+	path     string // resolved target
+	tmpPath  string
+	file     *os.File
+	sum      hash.Hash // SHA-256 of every chunk so far
+	chunks   int
+	bytes    int64
+	lastHash string // of the last chunk, so a retried append is a no-op
+	timer    *time.Timer
+	mu       sync.Mutex // one chunk or commit at a time
+	done     bool       // committed or discarded
+}
+
+// discard closes and removes the temp file (caller holds s.mu)
+func (s *writeSession) discard() {
+	if s.done {
+		return
+	}
+	s.done = true
+	s.timer.Stop()
+	s.file.Close()
+	os.Remove(s.tmpPath)
+}
+
+// writeSessionStore holds the open write sessions. Each one carries a timer
+// that discards it after ttl without a chunk.
+type writeSessionStore struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	sessions map[string]*writeSession
+}
+
+func newWriteSessionStore(ttl time.Duration) *writeSessionStore {
+	if ttl <= 0 {
+		ttl = DefaultWriteSessionTTL
+	}
+	return &writeSessionStore{ttl: ttl, sessions: make(map[string]*writeSession)}
+}
+
+// get returns session id, or an error naming why it is gone
+func (st *writeSessionStore) get(id string) (*writeSession, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	s, ok := st.sessions[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown write session %q: it was committed, aborted, or idle for more than %s", id, st.ttl)
+	}
+	return s, nil
+}
+
+func (st *writeSessionStore) remove(id string) {
+	st.mu.Lock()
+	delete(st.sessions, id)
+	st.mu.Unlock()
+}
+
+// expire discards a session that stayed idle for ttl
+func (st *writeSessionStore) expire(id string) {
+	st.mu.Lock()
+	s, ok := st.sessions[id]
+	delete(st.sessions, id)
+	st.mu.Unlock()
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.done {
+		slog.Info("Abandoned write session discarded", "session_id", id, "path", s.path, "chunks", s.chunks, "bytes", s.bytes)
+		s.discard()
+	}
+}
+
+// closeAll discards every open session (engine shutdown)
+func (st *writeSessionStore) closeAll() {
+	st.mu.Lock()
+	sessions := st.sessions
+	st.sessions = make(map[string]*writeSession)
+	st.mu.Unlock()
+	for _, s := range sessions {
+		s.mu.Lock()
+		s.discard()
+		s.mu.Unlock()
+	}
+}
+
+func (s *writeSession) info(ttl time.Duration) *WriteSessionInfo {
+	return &WriteSessionInfo{SessionID: s.id, Path: s.path, Chunks: s.chunks, Bytes: s.bytes, ExpiresAt: time.Now().Add(ttl)}
+}
+
+// BeginWriteSession opens a session that writes path from chunks sent one
+// call at a time, for content larger than a single message. The chunks go to
+// a temp file next to path; nothing at path changes until the commit.
+func (e *UltraFastEngine) BeginWriteSession(ctx context.Context, path string) (*WriteSessionInfo, error) {
+	path = NormalizePath(path)
+	if !e.IsPathAllowed(path) {
+		return nil, e.AccessDeniedError("write", path)
+	}
+	if err := e.CheckWritable("write", path); err != nil {
+		return nil, err
+	}
+	resolved, err := e.ResolveAndAuthorize("write", path)
+	if err != nil {
+		return nil, err
+	}
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(resolved); err == nil {
+		if info.IsDir() {
+			return nil, fmt.Errorf("%s is a directory", resolved)
+		}
+		mode = info.Mode()
+	}
+	if err := os.MkdirAll(filepath.Dir(resolved), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	suffix := secureRandomSuffix()
+	s := &writeSession{
+		id:      "ws_" + suffix[:16],
+		path:    resolved,
+		tmpPath: resolved + ".session." + suffix,
+		sum:     sha256.New(),
+	}
+	if s.file, err = os.OpenFile(s.tmpPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, mode); err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	st := e.writeSessions
+	s.timer = time.AfterFunc(st.ttl, func() { st.expire(s.id) })
+	st.mu.Lock()
+	st.sessions[s.id] = s
+	st.mu.Unlock()
+	return s.info(st.ttl), nil
+}
+
+// AppendWriteChunk adds chunk number index (0-based) to a session. Chunks
+// must arrive in order; resending the last chunk unchanged is accepted and
+// ignored, so a retried call does not duplicate data.
+func (e *UltraFastEngine) AppendWriteChunk(ctx context.Context, id, chunk string, index int) (*WriteSessionInfo, error) {
+	st := e.writeSessions
+	s, err := st.get(id)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		return nil, fmt.Errorf("write session %q is closed", id)
+	}
+	chunkHash := contentHashFNV(chunk)
+	switch {
+	case index == s.chunks-1 && chunkHash == s.lastHash:
+		// Retry of the chunk just written
+	case index != s.chunks:
+		return nil, fmt.Errorf("chunk %d out of order: write session %q has %d chunks, send index %d next", index, id, s.chunks, s.chunks)
+	default:
+		if _, err := s.file.WriteString(chunk); err != nil {
+			st.remove(id)
+			s.discard()
+			return nil, fmt.Errorf("writing chunk %d failed, session discarded: %w", index, err)
+		}
+		s.sum.Write([]byte(chunk))
+		s.chunks++
+		s.bytes += int64(len(chunk))
+		s.lastHash = chunkHash
+	}
+	s.timer.Reset(st.ttl)
+	return s.info(st.ttl), nil
+}
+
+// CommitWriteSession checks the assembled content against expectedSHA256
+// (hex, optional) and renames it into place atomically. The usual write
+// path follows: pre- and post-write hooks, a backup when a file is
+// overwritten, cache invalidation and auto-sync. The session ends either way;
+// on a hash mismatch or a denied hook the temp file is discarded.
+func (e *UltraFastEngine) CommitWriteSession(ctx context.Context, id, expectedSHA256 string) (*WriteSessionResult, error) {
+	st := e.writeSessions
+	s, err := st.get(id)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		return nil, fmt.Errorf("write session %q is closed", id)
+	}
+	st.remove(id)
+	defer s.discard()
+
+	if err := e.acquireOperation(ctx, "write"); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	defer e.releaseOperation("write", start)
+
+	res := &WriteSessionResult{SessionID: id, Path: s.path, Chunks: s.chunks, Bytes: s.bytes, SHA256: hex.EncodeToString(s.sum.Sum(nil))}
+	if expectedSHA256 != "" && !strings.EqualFold(strings.TrimSpace(expectedSHA256), res.SHA256) {
+		return nil, fmt.Errorf("sha256 mismatch: expected %s, received content hashes to %s (%d chunks, %d bytes); session discarded",
+			expectedSHA256, res.SHA256, s.chunks, s.bytes)
+	}
+	if err := s.file.Sync(); err != nil {
+		return nil, fmt.Errorf("failed to flush temp file: %w", err)
+	}
+	if err := s.file.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close temp file: %w", err)
+	}
+
+	// The target may have been swapped for a symlink since begin
+	path, err := e.ResolveAndAuthorize("write", s.path)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.CheckWritable("write", path); err != nil {
+		return nil, err
+	}
+
+	// The secret scan reads the assembled temp file in chunks. Configured
+	// hooks get the content like any write_file, and a content hook's
+	// rewrite replaces it.
+	workingDir, _ := os.Getwd()
+	hookCtx := &HookContext{
+		Event:       HookPreWrite,
+		ToolName:    "write_file",
+		FilePath:    path,
+		Operation:   "write_session",
+		Timestamp:   time.Now(),
+		WorkingDir:  workingDir,
+		Metadata:    map[string]interface{}{"size": s.bytes, "chunks": s.chunks, "sha256": res.SHA256},
+		ContentPath: s.tmpPath,
+	}
+	if e.hookManager.IsEnabled() {
+		data, err := os.ReadFile(s.tmpPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read temp file: %w", err)
+		}
+		hookCtx.Content = string(data)
+	}
+	hookResult, err := e.hookManager.ExecuteHooks(ctx, HookPreWrite, hookCtx)
+	if err != nil {
+		return nil, fmt.Errorf("pre-write hook denied operation: %w", err)
+	}
+	if hookResult.ModifiedContent != "" && hookResult.ModifiedContent != hookCtx.Content {
+		if err := os.WriteFile(s.tmpPath, []byte(hookResult.ModifiedContent), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write hook output: %w", err)
+		}
+		res.Bytes = int64(len(hookResult.ModifiedContent))
+	}
+
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		res.Created = true
+	} else if e.backupManager != nil {
+		e.backupChainMu.RLock()
+		previousBackupID := e.backupChain[path]
+		e.backupChainMu.RUnlock()
+		res.BackupID, err = e.backupManager.CreateBackupWithContextAndParent(path, "write_session",
+			fmt.Sprintf("Overwrite from write session %s (%d chunks)", id, s.chunks), previousBackupID)
+		if err != nil {
+			return nil, fmt.Errorf("could not create backup: %w", err)
+		}
+		e.backupChainMu.Lock()
+		e.backupChain[path] = res.BackupID
+		e.backupChainMu.Unlock()
+		SetBackupID(ctx, res.BackupID, previousBackupID)
+	}
+
+	if err := os.Rename(s.tmpPath, path); err != nil {
+		return nil, fmt.Errorf("failed to rename temp file: %w", lockedError("write", path, err))
+	}
+	e.invalidateMutatedPath(path)
+
+	hookCtx.Event = HookPostWrite
+	_, _ = e.hookManager.ExecuteHooks(ctx, HookPostWrite, hookCtx)
+	if e.autoSyncManager != nil {
+		_ = e.autoSyncManager.AfterWrite(path)
+	}
+	e.recordOperation(ctx, OperationRecord{Operation: "write", Path: path, BytesWritten: res.Bytes, BackupID: res.BackupID})
+	res.Path = path
+	return res, nil
+}
+
+// AbortWriteSession discards a session and its temp file
+func (e *UltraFastEngine) AbortWriteSession(id string) error {
+	s, err := e.writeSessions.get(id)
+	if err != nil {
+		return err
+	}
+	e.writeSessions.remove(id)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.discard()
+	return nil
+}
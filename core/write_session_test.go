@@ -0,0 +1,153 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sessionTemps lists the temp files write sessions left in dir
+func sessionTemps(t *testing.T, dir string) []string {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(dir, "*.session.*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return matches
+}
+
+func TestWriteSession_ChunksCommit(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	ctx := context.Background()
+	target := filepath.Join(dir, "dump.sql")
+	os.WriteFile(target, []byte("old\n"), 0640)
+
+	info, err := engine.BeginWriteSession(ctx, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunks := []string{"INSERT 1;\n", "INSERT 2;\n", "INSERT 3;\n"}
+	for i, c := range chunks {
+		if _, err := engine.AppendWriteChunk(ctx, info.SessionID, c, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A retried last chunk is ignored, a gap is refused
+	if got, err := engine.AppendWriteChunk(ctx, info.SessionID, chunks[2], 2); err != nil || got.Chunks != 3 {
+		t.Fatalf("retry = %+v, %v", got, err)
+	}
+	if _, err := engine.AppendWriteChunk(ctx, info.SessionID, "x", 5); err == nil || !strings.Contains(err.Error(), "send index 3 next") {
+		t.Fatalf("out of order err = %v", err)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "old\n" {
+		t.Fatalf("target changed before commit: %q", data)
+	}
+
+	whole := strings.Join(chunks, "")
+	sum := sha256.Sum256([]byte(whole))
+	res, err := engine.CommitWriteSession(ctx, info.SessionID, strings.ToUpper(hex.EncodeToString(sum[:])))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Chunks != 3 || res.Bytes != int64(len(whole)) || res.Created || res.BackupID == "" {
+		t.Fatalf("result = %+v", res)
+	}
+	data, _ := os.ReadFile(target)
+	if string(data) != whole {
+		t.Fatalf("content = %q", data)
+	}
+	if st, _ := os.Stat(target); st.Mode().Perm() != 0640 {
+		t.Fatalf("mode = %v, want 0640 kept", st.Mode().Perm())
+	}
+	if left := sessionTemps(t, dir); len(left) != 0 {
+		t.Fatalf("temp files left: %v", left)
+	}
+	if _, err := engine.AppendWriteChunk(ctx, info.SessionID, "late", 3); err == nil {
+		t.Fatal("a committed session should be gone")
+	}
+}
+
+func TestWriteSession_HashMismatchAndAbort(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	ctx := context.Background()
+	target := filepath.Join(dir, "new", "out.txt")
+
+	info, err := engine.BeginWriteSession(ctx, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.AppendWriteChunk(ctx, info.SessionID, "hello", 0)
+	if _, err := engine.CommitWriteSession(ctx, info.SessionID, strings.Repeat("0", 64)); err == nil || !strings.Contains(err.Error(), "sha256 mismatch") {
+		t.Fatalf("commit err = %v", err)
+	}
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Fatalf("target written despite mismatch: %v", err)
+	}
+
+	info, _ = engine.BeginWriteSession(ctx, target)
+	engine.AppendWriteChunk(ctx, info.SessionID, "hello", 0)
+	if err := engine.AbortWriteSession(info.SessionID); err != nil {
+		t.Fatal(err)
+	}
+	if left := sessionTemps(t, filepath.Dir(target)); len(left) != 0 {
+		t.Fatalf("temp files left: %v", left)
+	}
+}
+
+func TestWriteSession_IdleExpiry(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	engine.writeSessions = newWriteSessionStore(50 * time.Millisecond)
+	ctx := context.Background()
+
+	info, err := engine.BeginWriteSession(ctx, filepath.Join(dir, "idle.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.AppendWriteChunk(ctx, info.SessionID, "partial", 0)
+	deadline := time.Now().Add(2 * time.Second)
+	for len(sessionTemps(t, dir)) > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if left := sessionTemps(t, dir); len(left) != 0 {
+		t.Fatalf("abandoned session not cleaned up: %v", left)
+	}
+	if _, err := engine.CommitWriteSession(ctx, info.SessionID, ""); err == nil || !strings.Contains(err.Error(), "idle") {
+		t.Fatalf("commit after expiry err = %v", err)
+	}
+}
+
+func TestWriteSession_CommitRunsSecretScan(t *testing.T) {
+	engine, dir := setupProgressEngine(t)
+	engine.hookManager.SetSecretGuard(NewSecretGuard(nil))
+	ctx := context.Background()
+	target := filepath.Join(dir, "settings.env")
+	os.WriteFile(target, []byte("old\n"), 0644)
+
+	// The key is split across two chunks, so no single chunk holds it
+	info, err := engine.BeginWriteSession(ctx, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := "AWS_KEY=" + fakeAWSKey + "\n"
+	chunks := []string{strings.Repeat("# padding\n", 3) + line[:12], line[12:]}
+	for i, c := range chunks {
+		if _, err := engine.AppendWriteChunk(ctx, info.SessionID, c, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err = engine.CommitWriteSession(ctx, info.SessionID, "")
+	if err == nil || !strings.Contains(err.Error(), "aws-access-key-id on line 4 of settings.env") {
+		t.Fatalf("commit err = %v, want a secret-scan denial", err)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "old\n" {
+		t.Fatalf("denied commit changed the target: %q", data)
+	}
+	if temps := sessionTemps(t, dir); len(temps) != 0 {
+		t.Fatalf("temp files left: %v", temps)
+	}
+}
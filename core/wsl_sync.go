@@ -7,7 +7,9 @@
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -47,7 +49,7 @@ func (e *UltraFastEngine) WSLWindowsCopy(ctx context.Context, srcPath, dstPath s
 
 	// If source is a /mnt/ path, try to convert it to Windows path to ensure it's accessible
 	accessPath := srcPath
-	if strings.HasPrefix(srcPath, "/mnt/") {
+	if _, _, ok := splitWSLDrivePath(srcPath); ok {
 		// Try to convert /mnt/c/... to Windows path for checking existence
 		winPath, err := WSLToWindows(srcPath)
 		if err == nil && winPath != srcPath {
@@ -64,6 +66,9 @@ func (e *UltraFastEngine) WSLWindowsCopy(ctx context.Context, srcPath, dstPath s
 		return fmt.Errorf("failed to stat source: %w", err)
 	}
 
+	// Even a failed copy may have written part of the tree
+	MarkMutating(ctx)
+
 	// If source is a directory, copy recursively
 	if srcInfo.IsDir() {
 		return e.copyDirectoryRecursive(accessPath, dstPath, createDirs)
@@ -138,8 +143,35 @@ func (e *UltraFastEngine) copyDirectoryRecursive(srcDir, dstDir string, createDi
 	})
 }
 
-// SyncWorkspace syncs files between WSL and Windows
-func (e *UltraFastEngine) SyncWorkspace(ctx context.Context, direction string, filterPattern string, dryRun bool) (result map[string]interface{}, err error) {
+// SyncWorkspaceOptions configures SyncWorkspace
+type SyncWorkspaceOptions struct {
+	Direction        string   // wsl_to_windows, windows_to_wsl or bidirectional
+	FilterPattern    string   // optional base-name glob
+	Exclude          []string // extra excludes (never-cache glob syntax)
+	ConflictStrategy string   // skip (default), newest_wins, prefer_wsl, prefer_windows
+	DryRun           bool
+
+	// Throttling for large workspaces on the 9p file system: at most
+	// MaxFilesPerSecond copies per second (0 = unlimited) and
+	// MaxConcurrency files in flight (0 = 1).
+	MaxFilesPerSecond float64
+	MaxConcurrency    int
+
+	// OnStatus receives progress snapshots: at the start, every
+	// DefaultSyncProgressEvery files and at the end
+	OnStatus func(SyncStatus)
+}
+
+// SyncWorkspace syncs files between WSL and Windows. Paths matching the
+// default excludes, opts.Exclude or a .gitignore in the workspace are
+// skipped and counted, never copied. Each file's state at the last sync is
+// kept in a per-workspace sync-state file; a file changed on both sides
+// since then is a conflict, resolved by opts.ConflictStrategy. Files whose
+// size and mtimes still match that state are skipped without being read, so
+// re-running an interrupted sync only copies what is left. Per-file errors
+// are collected and the sync keeps going; cancelling ctx stops it between
+// files with everything copied so far recorded.
+func (e *UltraFastEngine) SyncWorkspace(ctx context.Context, opts SyncWorkspaceOptions) (result map[string]interface{}, err error) {
 	// Acquire semaphore
 	if err := e.acquireOperation(ctx, "sync_workspace"); err != nil {
 		return nil, err
@@ -148,165 +180,480 @@ func (e *UltraFastEngine) SyncWorkspace(ctx context.Context, direction string, f
 	start := time.Now()
 	defer e.releaseOperation("sync_workspace", start)
 
-	result = make(map[string]interface{})
-	syncedFiles := []string{}
-	errors := []string{}
-
-	// Determine source and destination based on direction
-	var srcDirs, dstDirs []string
-
+	direction := opts.Direction
 	switch direction {
-	case "wsl_to_windows":
-		// Common WSL directories
-		wslHome := GetWSLHome()
-		srcDirs = []string{
-			filepath.Join(wslHome, "claude"),
-			filepath.Join(wslHome, "projects"),
-			"/tmp/claude",
+	case "wsl_to_windows", "windows_to_wsl", "bidirectional":
+	default:
+		return nil, fmt.Errorf("invalid direction: %s (must be wsl_to_windows, windows_to_wsl, or bidirectional)", direction)
+	}
+	conflictStrategy := opts.ConflictStrategy
+	if conflictStrategy == "" {
+		conflictStrategy = ConflictSkip
+	}
+	if !validConflictStrategy(conflictStrategy) {
+		return nil, fmt.Errorf("invalid conflict_strategy: %s (must be skip, newest_wins, prefer_wsl, or prefer_windows)", conflictStrategy)
+	}
+	if opts.FilterPattern != "" {
+		if _, err := filepath.Match(opts.FilterPattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid filter pattern: %w", err)
 		}
+	}
+	if opts.MaxFilesPerSecond < 0 || opts.MaxConcurrency < 0 {
+		return nil, fmt.Errorf("max_files_per_second and max_concurrency must not be negative")
+	}
 
-		_, winHome := GetWindowsHome()
-		if winHome != "" {
-			dstDirs = []string{
-				filepath.Join(winHome, "claude"),
-				filepath.Join(winHome, "projects"),
-				filepath.Join(winHome, "AppData", "Local", "Temp", "claude"),
-			}
+	// Common workspace directories, paired by index
+	wslHome := GetWSLHome()
+	wslDirs := []string{
+		filepath.Join(wslHome, "claude"),
+		filepath.Join(wslHome, "projects"),
+		"/tmp/claude",
+	}
+	var winDirs []string
+	winHomeWSL, winHome := GetWindowsHome()
+	if winHomeWSL != "" {
+		winHome = winHomeWSL // operate on /mnt/c/... from inside WSL
+	}
+	if winHome != "" {
+		winDirs = []string{
+			filepath.Join(winHome, "claude"),
+			filepath.Join(winHome, "projects"),
+			filepath.Join(winHome, "AppData", "Local", "Temp", "claude"),
 		}
+	}
+	if len(winDirs) == 0 {
+		return nil, fmt.Errorf("could not determine source or destination directories")
+	}
 
-	case "windows_to_wsl":
-		wslHome := GetWSLHome()
-		dstDirs = []string{
-			filepath.Join(wslHome, "claude"),
-			filepath.Join(wslHome, "projects"),
-			"/tmp/claude",
-		}
+	ws := &workspaceSync{
+		engine:      e,
+		direction:   direction,
+		filter:      opts.FilterPattern,
+		excludes:    newSyncExcludes(opts.Exclude, newGitignoreCache()),
+		strategy:    conflictStrategy,
+		dryRun:      opts.DryRun,
+		limiter:     newSyncRateLimiter(opts.MaxFilesPerSecond),
+		concurrency: opts.MaxConcurrency,
+		run:         e.syncRuns.start(direction, opts.DryRun, opts.OnStatus),
+	}
+	if !opts.DryRun {
+		MarkMutating(ctx)
+	}
+	for i, wslDir := range wslDirs {
+		ws.collect(wslDir, winDirs[i])
+	}
+	ws.process(ctx)
+	e.syncRuns.finish(ws.run)
+
+	result = ws.result()
+	result["direction"] = direction
+	result["filter_pattern"] = opts.FilterPattern
+	result["conflict_strategy"] = conflictStrategy
+	result["dry_run"] = opts.DryRun
+	return result, nil
+}
+
+// workspaceSync accumulates the outcome of one SyncWorkspace call. Files
+// are collected from every directory pair first, so progress has a total,
+// then processed by up to concurrency workers.
+type workspaceSync struct {
+	engine      *UltraFastEngine
+	direction   string
+	filter      string
+	excludes    *syncExcludes
+	strategy    string
+	dryRun      bool
+	limiter     *syncRateLimiter
+	concurrency int
+	run         *syncRun
+
+	items     []workspaceSyncItem
+	states    []*workspaceSyncState
+	cancelled bool
+
+	mu        sync.Mutex // guards the results below
+	synced    []string
+	conflicts []string
+	resolved  []string
+	errors    []string
+	excluded  map[string]bool // relative paths skipped by exclusion
+	unchanged int
+	bytes     int64
+	plan      []SyncPlanEntry // dry run only
+}
 
-		_, winHome := GetWindowsHome()
-		if winHome != "" {
-			srcDirs = []string{
-				filepath.Join(winHome, "claude"),
-				filepath.Join(winHome, "projects"),
-				filepath.Join(winHome, "AppData", "Local", "Temp", "claude"),
+// Dry-run plan actions
+const (
+	SyncPlanCopyNew         = "copy_new"         // missing on the destination
+	SyncPlanOverwrite       = "overwrite"        // destination holds an older copy
+	SyncPlanSkipIdentical   = "skip_identical"   // same content on both sides
+	SyncPlanConflict        = "conflict"         // changed on both sides, left alone
+	SyncPlanKeepDestination = "keep_destination" // conflict resolved in favour of the destination
+)
+
+// SyncPlanEntry is what a real sync would do with one file, reported by a
+// dry run
+type SyncPlanEntry struct {
+	Root   string `json:"root"` // WSL workspace directory
+	Path   string `json:"path"` // relative to Root
+	Action string `json:"action"`
+	Size   int64  `json:"size"` // bytes that would be transferred; 0 when nothing is copied
+	From   string `json:"from,omitempty"`
+	To     string `json:"to,omitempty"`
+}
+
+// workspaceSyncItem is one relative path of a directory pair
+type workspaceSyncItem struct {
+	rel            string
+	wslDir, winDir string
+	state          *workspaceSyncState
+}
+
+func (w *workspaceSync) result() map[string]interface{} {
+	result := map[string]interface{}{
+		"synced_files":       orEmpty(w.synced),
+		"synced_count":       len(w.synced),
+		"conflicts":          orEmpty(w.conflicts),
+		"conflict_count":     len(w.conflicts),
+		"resolved_conflicts": orEmpty(w.resolved),
+		"excluded_count":     len(w.excluded),
+		"unchanged_count":    w.unchanged,
+		"bytes_copied":       w.bytes,
+		"files_total":        len(w.items),
+		"cancelled":          w.cancelled,
+		"sync_id":            w.run.snapshot().ID,
+		"errors":             orEmpty(w.errors),
+		"error_count":        len(w.errors),
+	}
+	if w.dryRun {
+		sort.Slice(w.plan, func(i, j int) bool {
+			if w.plan[i].Root != w.plan[j].Root {
+				return w.plan[i].Root < w.plan[j].Root
 			}
+			return w.plan[i].Path < w.plan[j].Path
+		})
+		var transfer int64
+		for _, p := range w.plan {
+			transfer += p.Size
 		}
+		result["plan"] = orEmptyPlan(w.plan)
+		result["bytes_to_transfer"] = transfer
+	}
+	return result
+}
 
-	case "bidirectional":
-		return nil, fmt.Errorf("bidirectional sync not yet implemented")
+func orEmpty(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
 
-	default:
-		return nil, fmt.Errorf("invalid direction: %s (must be wsl_to_windows, windows_to_wsl, or bidirectional)", direction)
+func orEmptyPlan(plan []SyncPlanEntry) []SyncPlanEntry {
+	if plan == nil {
+		return []SyncPlanEntry{}
 	}
+	return plan
+}
 
-	if len(srcDirs) == 0 || len(dstDirs) == 0 {
-		return nil, fmt.Errorf("could not determine source or destination directories")
+// addPlan records a dry-run action
+func (w *workspaceSync) addPlan(entry SyncPlanEntry) {
+	if !w.dryRun {
+		return
 	}
+	w.mu.Lock()
+	w.plan = append(w.plan, entry)
+	w.mu.Unlock()
+}
 
-	// Sync each directory pair
-	for i, srcDir := range srcDirs {
-		if i >= len(dstDirs) {
-			break
-		}
-		dstDir := dstDirs[i]
+func (w *workspaceSync) addError(msg string) {
+	w.mu.Lock()
+	w.errors = append(w.errors, msg)
+	w.mu.Unlock()
+}
+
+// syncDirs syncs one WSL/Windows directory pair
+func (w *workspaceSync) syncDirs(wslDir, winDir string) {
+	w.collect(wslDir, winDir)
+	w.process(context.Background())
+}
+
+// collect walks one directory pair and queues its files
+func (w *workspaceSync) collect(wslDir, winDir string) {
+	var roots []string
+	switch w.direction {
+	case "wsl_to_windows":
+		roots = []string{wslDir}
+	case "windows_to_wsl":
+		roots = []string{winDir}
+	default:
+		roots = []string{wslDir, winDir}
+	}
 
-		// Check if source exists
-		if _, err := os.Stat(srcDir); os.IsNotExist(err) {
+	seen := make(map[string]bool)
+	var rels []string
+	for _, root := range roots {
+		if _, err := os.Stat(root); err != nil {
 			continue
 		}
-
-		// Walk through source directory
-		err := filepath.WalkDir(srcDir, func(path string, d fs.DirEntry, err error) error {
+		walkErr := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
 			if err != nil {
-				errors = append(errors, fmt.Sprintf("error accessing %s: %v", path, err))
+				w.addError(fmt.Sprintf("error accessing %s: %v", path, err))
+				return nil
+			}
+			if path != root && w.excludes != nil && w.excludes.excluded(root, path, d.IsDir()) {
+				if rel, err := filepath.Rel(root, path); err == nil {
+					if w.excluded == nil {
+						w.excluded = make(map[string]bool)
+					}
+					w.excluded[filepath.ToSlash(rel)] = true
+				}
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
 				return nil
 			}
-
-			// Skip directories
 			if d.IsDir() {
 				return nil
 			}
-
-			// Apply filter pattern if specified
-			if filterPattern != "" {
-				matched, err := filepath.Match(filterPattern, filepath.Base(path))
-				if err != nil {
-					errors = append(errors, fmt.Sprintf("invalid filter pattern: %v", err))
-					return nil
-				}
-				if !matched {
+			if w.filter != "" {
+				if matched, _ := filepath.Match(w.filter, filepath.Base(path)); !matched {
 					return nil
 				}
 			}
-
-			// Calculate destination path
-			relPath, err := filepath.Rel(srcDir, path)
+			rel, err := filepath.Rel(root, path)
 			if err != nil {
-				errors = append(errors, fmt.Sprintf("failed to get relative path for %s: %v", path, err))
+				w.addError(fmt.Sprintf("failed to get relative path for %s: %v", path, err))
 				return nil
 			}
+			rel = filepath.ToSlash(rel)
+			if !seen[rel] {
+				seen[rel] = true
+				rels = append(rels, rel)
+			}
+			return nil
+		})
+		if walkErr != nil {
+			w.addError(fmt.Sprintf("error walking directory %s: %v", root, walkErr))
+		}
+	}
+	if len(rels) == 0 {
+		return
+	}
+	sort.Strings(rels)
 
-			dstPath := filepath.Join(dstDir, relPath)
+	state := loadSyncState(wslDir, winDir)
+	w.states = append(w.states, state)
+	for _, rel := range rels {
+		w.items = append(w.items, workspaceSyncItem{rel: rel, wslDir: wslDir, winDir: winDir, state: state})
+	}
+}
 
-			// If dry run, just record what would be synced
-			if dryRun {
-				syncedFiles = append(syncedFiles, fmt.Sprintf("%s -> %s", path, dstPath))
-				return nil
-			}
+// process syncs the queued files, then saves every directory pair's state
+func (w *workspaceSync) process(ctx context.Context) {
+	if w.run == nil {
+		w.run = (*syncRunRegistry)(nil).start(w.direction, w.dryRun, nil)
+	}
+	w.run.setTotal(len(w.items))
 
-			// Create destination directory
-			dstFileDir := filepath.Dir(dstPath)
-			if err := os.MkdirAll(dstFileDir, 0755); err != nil {
-				errors = append(errors, fmt.Sprintf("failed to create directory %s: %v", dstFileDir, err))
-				return nil
-			}
+	workers := w.concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for _, item := range w.items {
+		if ctx.Err() != nil {
+			w.cancelled = true
+			break
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(item workspaceSyncItem) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			w.syncFile(ctx, item)
+		}(item)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		w.cancelled = true
+	}
 
-			// Copy file using io.CopyBuffer with pooled buffer for memory efficiency
-			srcFile, err := os.Open(path)
-			if err != nil {
-				errors = append(errors, fmt.Sprintf("failed to open %s: %v", path, err))
-				return nil
+	if !w.dryRun {
+		for _, state := range w.states {
+			if err := state.save(); err != nil {
+				w.addError(fmt.Sprintf("failed to save sync state: %v", err))
 			}
-			defer srcFile.Close()
+		}
+	}
+	w.run.finish(w.cancelled)
+}
 
-			dstFile, err := os.OpenFile(dstPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
-			if err != nil {
-				errors = append(errors, fmt.Sprintf("failed to create %s: %v", dstPath, err))
-				return nil
-			}
+// syncFile syncs one relative path and reports it as done
+func (w *workspaceSync) syncFile(ctx context.Context, item workspaceSyncItem) {
+	w.run.update(func(s *SyncStatus) { s.CurrentPath = item.rel })
+	outcome := w.syncOne(ctx, item)
+	w.run.update(func(s *SyncStatus) {
+		s.FilesDone++
+		switch outcome.kind {
+		case syncOutcomeCopied:
+			s.FilesCopied++
+			s.BytesCopied += outcome.bytes
+		case syncOutcomeUnchanged:
+			s.Unchanged++
+		case syncOutcomeConflict:
+			s.Conflicts++
+		case syncOutcomeError:
+			s.Errors++
+		}
+	})
+}
 
-			// Use pooled buffer to reduce GC pressure
-			bufPtr := e.bufferPool.Get().(*[]byte)
-			defer e.bufferPool.Put(bufPtr)
+// syncOutcome is what happened to one file
+type syncOutcome struct {
+	kind  int
+	bytes int64
+}
 
-			if _, err := io.CopyBuffer(dstFile, srcFile, *bufPtr); err != nil {
-				dstFile.Close()
-				errors = append(errors, fmt.Sprintf("failed to copy %s: %v", path, err))
-				return nil
-			}
+const (
+	syncOutcomeNone = iota
+	syncOutcomeCopied
+	syncOutcomeUnchanged
+	syncOutcomeConflict
+	syncOutcomeError
+)
 
-			if err := dstFile.Close(); err != nil {
-				errors = append(errors, fmt.Sprintf("failed to close %s: %v", dstPath, err))
-				return nil
-			}
+// syncOne decides and performs the copy for one file
+func (w *workspaceSync) syncOne(ctx context.Context, item workspaceSyncItem) syncOutcome {
+	rel, state := item.rel, item.state
+	wsl := statSyncFile(filepath.Join(item.wslDir, filepath.FromSlash(rel)))
+	win := statSyncFile(filepath.Join(item.winDir, filepath.FromSlash(rel)))
+	entry := state.entry(rel)
 
-			syncedFiles = append(syncedFiles, dstPath)
-			return nil
-		})
+	decision, err := decideSync(w.direction, wsl, win, entry)
+	if err != nil {
+		w.addError(fmt.Sprintf("failed to compare %s: %v", rel, err))
+		return syncOutcome{kind: syncOutcomeError}
+	}
+	if decision == syncConflict {
+		resolution := resolveConflict(w.strategy, wsl, win)
+		if resolution == syncConflict {
+			w.mu.Lock()
+			w.conflicts = append(w.conflicts, describeConflict(rel, wsl, win))
+			w.mu.Unlock()
+			w.addPlan(SyncPlanEntry{Root: item.wslDir, Path: rel, Action: SyncPlanConflict})
+			return syncOutcome{kind: syncOutcomeConflict}
+		}
+		// A one-way sync never writes to its source: keeping the
+		// destination's copy means copying nothing
+		if (w.direction == "wsl_to_windows" && resolution == syncToWSL) ||
+			(w.direction == "windows_to_wsl" && resolution == syncToWindows) {
+			w.mu.Lock()
+			w.resolved = append(w.resolved, fmt.Sprintf("%s: kept destination (%s)", rel, w.strategy))
+			w.mu.Unlock()
+			w.addPlan(SyncPlanEntry{Root: item.wslDir, Path: rel, Action: SyncPlanKeepDestination})
+			return syncOutcome{}
+		}
+		side := "WSL"
+		if resolution == syncToWSL {
+			side = "Windows"
+		}
+		w.mu.Lock()
+		w.resolved = append(w.resolved, fmt.Sprintf("%s: kept %s copy (%s)", rel, side, w.strategy))
+		w.mu.Unlock()
+		decision = resolution
+	}
 
-		if err != nil {
-			errors = append(errors, fmt.Sprintf("error walking directory %s: %v", srcDir, err))
+	src, dst := wsl, win
+	switch decision {
+	case syncNothing:
+		if !wsl.exists() || !win.exists() {
+			return syncOutcome{}
+		}
+		w.mu.Lock()
+		w.unchanged++
+		w.mu.Unlock()
+		w.addPlan(SyncPlanEntry{Root: item.wslDir, Path: rel, Action: SyncPlanSkipIdentical})
+		// Both sides match: remember it (with current mtimes, so the next
+		// run takes the fast path) so later edits are attributable
+		if w.dryRun || entry.unchanged(wsl, win) {
+			return syncOutcome{kind: syncOutcomeUnchanged}
+		}
+		if err := state.record(rel, wsl, win); err != nil {
+			w.addError(fmt.Sprintf("failed to record %s: %v", rel, err))
 		}
+		return syncOutcome{kind: syncOutcomeUnchanged}
+	case syncToWSL:
+		src, dst = win, wsl
 	}
 
-	result["synced_files"] = syncedFiles
-	result["synced_count"] = len(syncedFiles)
-	result["errors"] = errors
-	result["error_count"] = len(errors)
-	result["direction"] = direction
-	result["filter_pattern"] = filterPattern
-	result["dry_run"] = dryRun
+	if w.dryRun {
+		action := SyncPlanCopyNew
+		if dst.exists() {
+			action = SyncPlanOverwrite
+		}
+		w.addPlan(SyncPlanEntry{Root: item.wslDir, Path: rel, Action: action, Size: src.info.Size(), From: src.path, To: dst.path})
+		w.mu.Lock()
+		w.synced = append(w.synced, fmt.Sprintf("%s -> %s", src.path, dst.path))
+		w.mu.Unlock()
+		return syncOutcome{}
+	}
+	if err := w.limiter.wait(ctx); err != nil {
+		return syncOutcome{} // cancelled; the file is left for the next run
+	}
+	if err := w.engine.copySyncFile(src.path, dst.path); err != nil {
+		w.addError(err.Error())
+		return syncOutcome{kind: syncOutcomeError}
+	}
+	w.mu.Lock()
+	w.synced = append(w.synced, dst.path)
+	w.bytes += src.info.Size()
+	w.mu.Unlock()
+
+	wsl, win = statSyncFile(wsl.path), statSyncFile(win.path)
+	if wsl.exists() && win.exists() {
+		if err := state.record(rel, wsl, win); err != nil {
+			w.addError(fmt.Sprintf("failed to record %s: %v", rel, err))
+		}
+	}
+	return syncOutcome{kind: syncOutcomeCopied, bytes: src.info.Size()}
+}
 
-	return result, nil
+// copySyncFile copies src over dst with a pooled buffer, creating parent
+// directories and carrying the modification time across
+func (e *UltraFastEngine) copySyncFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(dst), err)
+	}
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", src, err)
+	}
+	defer srcFile.Close()
+	info, err := srcFile.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", src, err)
+	}
+
+	dstFile, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", dst, err)
+	}
+
+	// Use pooled buffer to reduce GC pressure
+	bufPtr := e.bufferPool.Get().(*[]byte)
+	defer e.bufferPool.Put(bufPtr)
+
+	if _, err := io.CopyBuffer(dstFile, srcFile, *bufPtr); err != nil {
+		dstFile.Close()
+		return fmt.Errorf("failed to copy %s: %w", src, err)
+	}
+	if err := dstFile.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", dst, err)
+	}
+	_ = os.Chtimes(dst, info.ModTime(), info.ModTime())
+	return nil
 }
 
 // GetWSLWindowsStatus returns the current WSL/Windows integration status
@@ -341,6 +688,8 @@ func (e *UltraFastEngine) GetWSLWindowsStatus(ctx context.Context) (map[string]i
 	status["wsl_home"] = wslHome
 	status["windows_home_wsl_style"] = wslWinHome
 	status["windows_home_windows_style"] = winHome
+	status["automount_root"] = wslAutomountRoot()
+	status["path_mappings"] = PathMappings()
 
 	// Check if common directories exist
 	commonDirs := make(map[string]bool)
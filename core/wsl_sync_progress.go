@@ -0,0 +1,233 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultSyncProgressEvery is how many files a workspace sync processes
+// between status callbacks
+const DefaultSyncProgressEvery = 50
+
+// maxRecentSyncRuns bounds the finished syncs kept for sync_status
+const maxRecentSyncRuns = 10
+
+// Workspace sync run states
+const (
+	SyncRunning   = "running"
+	SyncCompleted = "completed"
+	SyncCancelled = "cancelled"
+)
+
+// SyncStatus is a snapshot of a running or recently finished workspace sync
+type SyncStatus struct {
+	ID          string        `json:"id"`
+	Direction   string        `json:"direction"`
+	Status      string        `json:"status"`
+	DryRun      bool          `json:"dry_run,omitempty"`
+	FilesDone   int           `json:"files_done"`
+	FilesTotal  int           `json:"files_total"`
+	FilesCopied int           `json:"files_copied"`
+	BytesCopied int64         `json:"bytes_copied"`
+	Unchanged   int           `json:"unchanged"`
+	Conflicts   int           `json:"conflicts"`
+	Errors      int           `json:"errors"`
+	CurrentPath string        `json:"current_path,omitempty"`
+	StartedAt   time.Time     `json:"started_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+	Elapsed     time.Duration `json:"elapsed"`
+}
+
+// syncRun tracks one SyncWorkspace call; workers update it concurrently
+type syncRun struct {
+	mu       sync.Mutex
+	status   SyncStatus
+	onStatus func(SyncStatus)
+}
+
+// syncRunRegistry keeps running syncs and the most recent finished ones
+type syncRunRegistry struct {
+	mu     sync.Mutex
+	nextID atomic.Int64
+	runs   map[string]*syncRun
+	order  []string // finished run IDs, oldest first
+}
+
+func newSyncRunRegistry() *syncRunRegistry {
+	return &syncRunRegistry{runs: make(map[string]*syncRun)}
+}
+
+// start registers a new run. A nil registry (engine built without
+// NewUltraFastEngine) still returns a usable, untracked run.
+func (r *syncRunRegistry) start(direction string, dryRun bool, onStatus func(SyncStatus)) *syncRun {
+	id := "sync_0"
+	if r != nil {
+		id = fmt.Sprintf("sync_%d", r.nextID.Add(1))
+	}
+	now := time.Now()
+	run := &syncRun{
+		status:   SyncStatus{ID: id, Direction: direction, Status: SyncRunning, DryRun: dryRun, StartedAt: now, UpdatedAt: now},
+		onStatus: onStatus,
+	}
+	if r != nil {
+		r.mu.Lock()
+		r.runs[id] = run
+		r.mu.Unlock()
+	}
+	return run
+}
+
+// finish moves run to the bounded list of finished runs
+func (r *syncRunRegistry) finish(run *syncRun) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.order = append(r.order, run.status.ID)
+	for len(r.order) > maxRecentSyncRuns {
+		delete(r.runs, r.order[0])
+		r.order = r.order[1:]
+	}
+}
+
+// list returns snapshots, running syncs first, then newest first
+func (r *syncRunRegistry) list() []SyncStatus {
+	r.mu.Lock()
+	runs := make([]*syncRun, 0, len(r.runs))
+	for _, run := range r.runs {
+		runs = append(runs, run)
+	}
+	r.mu.Unlock()
+
+	out := make([]SyncStatus, 0, len(runs))
+	for _, run := range runs {
+		out = append(out, run.snapshot())
+	}
+	sort.Slice(out, func(i, j int) bool {
+		ri, rj := out[i].Status == SyncRunning, out[j].Status == SyncRunning
+		if ri != rj {
+			return ri
+		}
+		return out[i].StartedAt.After(out[j].StartedAt)
+	})
+	return out
+}
+
+func (run *syncRun) snapshot() SyncStatus {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	s := run.status
+	if s.Status == SyncRunning {
+		s.Elapsed = time.Since(s.StartedAt)
+	}
+	return s
+}
+
+// setTotal records how many files the sync will look at and notifies
+func (run *syncRun) setTotal(total int) {
+	run.mu.Lock()
+	run.status.FilesTotal = total
+	run.status.UpdatedAt = time.Now()
+	run.mu.Unlock()
+	run.notify()
+}
+
+// update applies fn to the status; callbacks fire every
+// DefaultSyncProgressEvery files (finish reports the final counts)
+func (run *syncRun) update(fn func(*SyncStatus)) {
+	run.mu.Lock()
+	before := run.status.FilesDone
+	fn(&run.status)
+	run.status.UpdatedAt = time.Now()
+	done := run.status.FilesDone
+	run.mu.Unlock()
+	if done != before && done%DefaultSyncProgressEvery == 0 {
+		run.notify()
+	}
+}
+
+func (run *syncRun) finish(cancelled bool) {
+	run.mu.Lock()
+	run.status.Elapsed = time.Since(run.status.StartedAt)
+	run.status.UpdatedAt = time.Now()
+	run.status.CurrentPath = ""
+	run.status.Status = SyncCompleted
+	if cancelled {
+		run.status.Status = SyncCancelled
+	}
+	run.mu.Unlock()
+	run.notify()
+}
+
+func (run *syncRun) notify() {
+	if run.onStatus != nil {
+		run.onStatus(run.snapshot())
+	}
+}
+
+// GetSyncStatus returns running workspace syncs and recently finished ones
+// (running first, then newest first). id filters to a single run.
+func (e *UltraFastEngine) GetSyncStatus(id string) []SyncStatus {
+	if e.syncRuns == nil {
+		return nil
+	}
+	all := e.syncRuns.list()
+	if id == "" {
+		return all
+	}
+	for _, s := range all {
+		if s.ID == id {
+			return []SyncStatus{s}
+		}
+	}
+	return nil
+}
+
+// syncRateLimiter spaces file copies to at most perSecond per second across
+// all sync workers, so a large sync does not saturate the 9p file system
+type syncRateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// newSyncRateLimiter returns nil (no limit) for perSecond <= 0
+func newSyncRateLimiter(perSecond float64) *syncRateLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &syncRateLimiter{interval: time.Duration(float64(time.Second) / perSecond)}
+}
+
+// wait blocks until the next copy slot, or until ctx is done
+func (l *syncRateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return ctx.Err()
+	}
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	slot := l.next
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(slot)
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,143 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSyncWorkspace_ProgressConcurrencyAndRerun(t *testing.T) {
+	engine, _ := setupProgressEngine(t)
+	wslDir, winDir := syncPair(t)
+	for i := 0; i < 120; i++ {
+		writeAt(t, filepath.Join(wslDir, fmt.Sprintf("d%d", i%3), fmt.Sprintf("f%03d.txt", i)), "content", time.Now())
+	}
+
+	var mu sync.Mutex
+	var updates []SyncStatus
+	ws := &workspaceSync{
+		engine:      engine,
+		direction:   "wsl_to_windows",
+		strategy:    ConflictSkip,
+		concurrency: 4,
+		run: engine.syncRuns.start("wsl_to_windows", false, func(s SyncStatus) {
+			mu.Lock()
+			updates = append(updates, s)
+			mu.Unlock()
+		}),
+	}
+	ws.syncDirs(wslDir, winDir)
+	engine.syncRuns.finish(ws.run)
+	if len(ws.errors) > 0 || len(ws.synced) != 120 || ws.bytes != 120*int64(len("content")) {
+		t.Fatalf("synced %d files, %d bytes, errors %v", len(ws.synced), ws.bytes, ws.errors)
+	}
+
+	// Start, every 50 files, end
+	if len(updates) != 4 || updates[0].FilesTotal != 120 || updates[1].FilesDone != 50 {
+		t.Fatalf("progress updates = %+v", updates)
+	}
+	final := updates[len(updates)-1]
+	if final.Status != SyncCompleted || final.FilesDone != 120 || final.FilesCopied != 120 || final.BytesCopied != ws.bytes {
+		t.Errorf("final status = %+v", final)
+	}
+	if listed := engine.GetSyncStatus(final.ID); len(listed) != 1 || listed[0].FilesCopied != 120 {
+		t.Errorf("GetSyncStatus(%s) = %+v", final.ID, listed)
+	}
+
+	// A re-run copies nothing and skips every file on size+mtime
+	ws = runSync(t, engine, "wsl_to_windows", ConflictSkip, wslDir, winDir)
+	if len(ws.synced) != 0 || ws.unchanged != 120 {
+		t.Errorf("re-run: synced %d, unchanged %d", len(ws.synced), ws.unchanged)
+	}
+}
+
+func TestSyncWorkspace_CancelledSyncResumes(t *testing.T) {
+	engine, _ := setupProgressEngine(t)
+	wslDir, winDir := syncPair(t)
+	for i := 0; i < 80; i++ {
+		writeAt(t, filepath.Join(wslDir, fmt.Sprintf("f%03d.txt", i)), "x", time.Now())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ws := &workspaceSync{
+		engine:    engine,
+		direction: "wsl_to_windows",
+		strategy:  ConflictSkip,
+		run: engine.syncRuns.start("wsl_to_windows", false, func(s SyncStatus) {
+			if s.FilesDone >= 50 {
+				cancel()
+			}
+		}),
+	}
+	ws.collect(wslDir, winDir)
+	ws.process(ctx)
+	if !ws.cancelled || len(ws.synced) != 50 {
+		t.Fatalf("cancelled %v after %d files", ws.cancelled, len(ws.synced))
+	}
+	if s := ws.run.snapshot(); s.Status != SyncCancelled {
+		t.Errorf("status = %s", s.Status)
+	}
+
+	ws = runSync(t, engine, "wsl_to_windows", ConflictSkip, wslDir, winDir)
+	if len(ws.synced) != 30 || ws.unchanged != 50 {
+		t.Errorf("resumed sync: synced %d, unchanged %d", len(ws.synced), ws.unchanged)
+	}
+}
+
+func TestSyncState_JournalSurvivesInterruptedSync(t *testing.T) {
+	wslDir, winDir := syncPair(t)
+	writeAt(t, filepath.Join(wslDir, "a.txt"), "same", time.Now())
+	writeAt(t, filepath.Join(winDir, "a.txt"), "same", time.Now())
+
+	// Record without save, as if the process died mid-sync
+	state := loadSyncState(wslDir, winDir)
+	wsl, win := statSyncFile(filepath.Join(wslDir, "a.txt")), statSyncFile(filepath.Join(winDir, "a.txt"))
+	if err := state.record("a.txt", wsl, win); err != nil {
+		t.Fatal(err)
+	}
+	state.journal.Close()
+
+	reloaded := loadSyncState(wslDir, winDir)
+	if !reloaded.entry("a.txt").unchanged(wsl, win) {
+		t.Fatalf("journal not replayed: %+v", reloaded.Files)
+	}
+	if err := reloaded.save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(reloaded.journalPath()); !os.IsNotExist(err) {
+		t.Errorf("journal kept after save: %v", err)
+	}
+	if loadSyncState(wslDir, winDir).entry("a.txt") == nil {
+		t.Error("replayed entry not saved")
+	}
+}
+
+func TestSyncRateLimiter_SpacesCopies(t *testing.T) {
+	if newSyncRateLimiter(0) != nil {
+		t.Error("zero rate should mean no limiter")
+	}
+	limiter := newSyncRateLimiter(50)
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		if err := limiter.wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("6 copies at 50/s took %v, want >= 100ms", elapsed)
+	}
+
+	// A cancelled sync does not sit out the next slot
+	slow := newSyncRateLimiter(0.5)
+	slow.wait(context.Background())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := slow.wait(ctx); err == nil {
+		t.Error("wait ignored the cancelled context")
+	}
+}
@@ -0,0 +1,358 @@
+package core
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Conflict strategies for workspace sync, used when both sides of a file
+// changed since the last sync
+const (
+	ConflictSkip          = "skip"           // report the conflict, copy nothing (default)
+	ConflictNewestWins    = "newest_wins"    // the most recently modified side wins
+	ConflictPreferWSL     = "prefer_wsl"     // the WSL side wins
+	ConflictPreferWindows = "prefer_windows" // the Windows side wins
+)
+
+// validConflictStrategy reports whether s is a known conflict strategy
+func validConflictStrategy(s string) bool {
+	switch s {
+	case ConflictSkip, ConflictNewestWins, ConflictPreferWSL, ConflictPreferWindows:
+		return true
+	}
+	return false
+}
+
+// syncStateEntry records a file as it was right after it was last synced
+type syncStateEntry struct {
+	Hash         string    `json:"hash"`
+	Size         int64     `json:"size"`
+	WSLModTime   time.Time `json:"wsl_mtime"`
+	WinModTime   time.Time `json:"windows_mtime"`
+	LastSyncedAt time.Time `json:"synced_at"`
+}
+
+// unchanged reports whether both sides still have the size and mtimes
+// recorded at the last sync, so the file can be skipped without hashing
+func (e *syncStateEntry) unchanged(wsl, win *syncFile) bool {
+	return e != nil &&
+		wsl.info.Size() == e.Size && wsl.info.ModTime().Equal(e.WSLModTime) &&
+		win.info.Size() == e.Size && win.info.ModTime().Equal(e.WinModTime)
+}
+
+// workspaceSyncState is the per-workspace sync-state file. It lets a sync
+// tell "changed since the last sync" apart from "differs", so a file edited
+// on both sides is reported as a conflict instead of silently overwritten.
+//
+// Every file recorded during a sync is also appended to a journal next to
+// the state file, so a sync that dies halfway keeps what it copied: the next
+// load replays the journal and those files are skipped as unchanged.
+type workspaceSyncState struct {
+	WSLDir     string                     `json:"wsl_dir"`
+	WindowsDir string                     `json:"windows_dir"`
+	Files      map[string]*syncStateEntry `json:"files"` // keyed by slash-separated relative path
+
+	path    string
+	mu      sync.Mutex // sync workers record concurrently
+	journal *os.File
+	dirty   bool
+}
+
+// syncJournalRecord is one journal line
+type syncJournalRecord struct {
+	Rel   string          `json:"rel"`
+	Entry *syncStateEntry `json:"entry"`
+}
+
+// syncStatePath returns the state file for a WSL/Windows directory pair,
+// under ~/.config/mcp-filesystem-ultra/sync-state/
+func syncStatePath(wslDir, winDir string) string {
+	sum := sha256.Sum256([]byte(wslDir + "\x00" + winDir))
+	return filepath.Join(os.Getenv("HOME"), ".config", "mcp-filesystem-ultra", "sync-state",
+		hex.EncodeToString(sum[:8])+".json")
+}
+
+// loadSyncState reads the state for a directory pair. A missing or corrupt
+// file yields an empty state: every file that differs is then treated as
+// changed on both sides.
+func loadSyncState(wslDir, winDir string) *workspaceSyncState {
+	state := &workspaceSyncState{
+		WSLDir:     wslDir,
+		WindowsDir: winDir,
+		Files:      make(map[string]*syncStateEntry),
+		path:       syncStatePath(wslDir, winDir),
+	}
+	if data, err := os.ReadFile(state.path); err == nil {
+		var loaded workspaceSyncState
+		if json.Unmarshal(data, &loaded) == nil && loaded.Files != nil {
+			state.Files = loaded.Files
+		}
+	}
+
+	// Replay files recorded by an interrupted sync
+	if f, err := os.Open(state.journalPath()); err == nil {
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var rec syncJournalRecord
+			if json.Unmarshal(scanner.Bytes(), &rec) == nil && rec.Rel != "" && rec.Entry != nil {
+				state.Files[rec.Rel] = rec.Entry
+				state.dirty = true
+			}
+		}
+		f.Close()
+	}
+	return state
+}
+
+func (s *workspaceSyncState) journalPath() string {
+	return s.path + ".journal"
+}
+
+// entry returns the recorded state of rel, or nil
+func (s *workspaceSyncState) entry(rel string) *syncStateEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Files[rel]
+}
+
+// save writes the state file atomically and drops the journal it absorbed
+func (s *workspaceSyncState) save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.journal != nil {
+		s.journal.Close()
+		s.journal = nil
+	}
+	if !s.dirty {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return err
+	}
+	s.dirty = false
+	os.Remove(s.journalPath())
+	return nil
+}
+
+// record marks rel as in sync, with both sides holding the same content,
+// and appends it to the journal
+func (s *workspaceSyncState) record(rel string, wsl, win *syncFile) error {
+	hash, err := wsl.contentHash()
+	if err != nil {
+		return err
+	}
+	entry := &syncStateEntry{
+		Hash:         hash,
+		Size:         wsl.info.Size(),
+		WSLModTime:   wsl.info.ModTime(),
+		WinModTime:   win.info.ModTime(),
+		LastSyncedAt: time.Now(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Files[rel] = entry
+	s.dirty = true
+	if s.journal == nil {
+		if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+			return err
+		}
+		if s.journal, err = os.OpenFile(s.journalPath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err != nil {
+			return err
+		}
+	}
+	line, _ := json.Marshal(syncJournalRecord{Rel: rel, Entry: entry})
+	_, err = s.journal.Write(append(line, '\n'))
+	return err
+}
+
+// syncFile is one side of a workspace file; info is nil when it is missing
+type syncFile struct {
+	path string
+	info fs.FileInfo
+	hash string
+}
+
+// statSyncFile stats path, leaving info nil for missing or non-regular files
+func statSyncFile(path string) *syncFile {
+	f := &syncFile{path: path}
+	if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+		f.info = info
+	}
+	return f
+}
+
+func (f *syncFile) exists() bool { return f.info != nil }
+
+// contentHash returns the file's SHA-256, computed once
+func (f *syncFile) contentHash() (string, error) {
+	if f.hash == "" {
+		h, err := hashFile(f.path)
+		if err != nil {
+			return "", err
+		}
+		f.hash = h
+	}
+	return f.hash, nil
+}
+
+// sameContent compares two existing files by size, then by hash
+func sameContent(a, b *syncFile) (bool, error) {
+	if a.info.Size() != b.info.Size() {
+		return false, nil
+	}
+	ha, err := a.contentHash()
+	if err != nil {
+		return false, err
+	}
+	hb, err := b.contentHash()
+	if err != nil {
+		return false, err
+	}
+	return ha == hb, nil
+}
+
+// changedSince reports whether f differs from its state at the last sync.
+// Unchanged size and mtime are trusted; otherwise the hash decides, so a
+// touched but unmodified file does not count as changed.
+func (f *syncFile) changedSince(entry *syncStateEntry, syncedModTime time.Time) (bool, error) {
+	if entry == nil {
+		return true, nil
+	}
+	if f.info.Size() == entry.Size && f.info.ModTime().Equal(syncedModTime) {
+		return false, nil
+	}
+	hash, err := f.contentHash()
+	if err != nil {
+		return false, err
+	}
+	return hash != entry.Hash, nil
+}
+
+// syncDecision is what a workspace sync does with one file
+type syncDecision int
+
+const (
+	syncNothing syncDecision = iota // already in sync
+	syncToWindows
+	syncToWSL
+	syncConflict
+)
+
+// decideSync works out the copy direction for one file. direction is the
+// SyncWorkspace direction; a one-way sync only reports a conflict when the
+// destination was also edited since the last sync, and copies otherwise.
+func decideSync(direction string, wsl, win *syncFile, entry *syncStateEntry) (syncDecision, error) {
+	switch {
+	case !wsl.exists() && !win.exists():
+		return syncNothing, nil
+	case !win.exists():
+		if direction == "windows_to_wsl" {
+			return syncNothing, nil
+		}
+		return syncToWindows, nil
+	case !wsl.exists():
+		if direction == "wsl_to_windows" {
+			return syncNothing, nil
+		}
+		return syncToWSL, nil
+	}
+
+	// Untouched since the last sync: skip without hashing
+	if entry.unchanged(wsl, win) {
+		return syncNothing, nil
+	}
+
+	same, err := sameContent(wsl, win)
+	if err != nil || same {
+		return syncNothing, err
+	}
+
+	var wslChanged, winChanged bool
+	if wslChanged, err = wsl.changedSince(entry, entryTime(entry, true)); err != nil {
+		return syncNothing, err
+	}
+	if winChanged, err = win.changedSince(entry, entryTime(entry, false)); err != nil {
+		return syncNothing, err
+	}
+
+	switch direction {
+	case "wsl_to_windows":
+		if entry != nil && wslChanged && winChanged {
+			return syncConflict, nil
+		}
+		return syncToWindows, nil
+	case "windows_to_wsl":
+		if entry != nil && wslChanged && winChanged {
+			return syncConflict, nil
+		}
+		return syncToWSL, nil
+	}
+
+	// Bidirectional: copy the side that changed; without a state entry
+	// there is no way to tell which one did
+	switch {
+	case wslChanged && !winChanged:
+		return syncToWindows, nil
+	case winChanged && !wslChanged:
+		return syncToWSL, nil
+	}
+	return syncConflict, nil
+}
+
+// entryTime returns the recorded mtime of one side, zero without an entry
+func entryTime(entry *syncStateEntry, wslSide bool) time.Time {
+	if entry == nil {
+		return time.Time{}
+	}
+	if wslSide {
+		return entry.WSLModTime
+	}
+	return entry.WinModTime
+}
+
+// resolveConflict applies a conflict strategy, returning syncConflict when
+// the conflict is left for the user
+func resolveConflict(strategy string, wsl, win *syncFile) syncDecision {
+	switch strategy {
+	case ConflictPreferWSL:
+		return syncToWindows
+	case ConflictPreferWindows:
+		return syncToWSL
+	case ConflictNewestWins:
+		wslTime, winTime := wsl.info.ModTime(), win.info.ModTime()
+		if wslTime.After(winTime) {
+			return syncToWindows
+		}
+		if winTime.After(wslTime) {
+			return syncToWSL
+		}
+	}
+	return syncConflict
+}
+
+// describeConflict renders a conflict for the sync result
+func describeConflict(rel string, wsl, win *syncFile) string {
+	return fmt.Sprintf("%s: changed on both sides since the last sync (WSL %s, %d bytes; Windows %s, %d bytes)",
+		rel, wsl.info.ModTime().Format(time.RFC3339), wsl.info.Size(),
+		win.info.ModTime().Format(time.RFC3339), win.info.Size())
+}
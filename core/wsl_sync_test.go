@@ -0,0 +1,192 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// syncPair returns a WSL and a Windows workspace directory with the sync
+// state kept under a temporary HOME
+func syncPair(t *testing.T) (string, string) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	return t.TempDir(), t.TempDir()
+}
+
+func runSync(t *testing.T, engine *UltraFastEngine, direction, strategy, wslDir, winDir string) *workspaceSync {
+	t.Helper()
+	ws := &workspaceSync{engine: engine, direction: direction, strategy: strategy}
+	ws.syncDirs(wslDir, winDir)
+	if len(ws.errors) > 0 {
+		t.Fatalf("sync errors: %v", ws.errors)
+	}
+	return ws
+}
+
+func writeAt(t *testing.T, path, content string, mtime time.Time) {
+	t.Helper()
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(path, mtime, mtime)
+}
+
+func readString(path string) string {
+	data, _ := os.ReadFile(path)
+	return string(data)
+}
+
+func TestSyncWorkspace_BidirectionalDetectsConflicts(t *testing.T) {
+	engine, _ := setupProgressEngine(t)
+	wslDir, winDir := syncPair(t)
+	base := time.Now().Add(-time.Hour)
+
+	writeAt(t, filepath.Join(wslDir, "a.txt"), "a v1", base)
+	writeAt(t, filepath.Join(winDir, "sub", "b.txt"), "b v1", base)
+	writeAt(t, filepath.Join(wslDir, "c.txt"), "same", base)
+	writeAt(t, filepath.Join(winDir, "c.txt"), "same", base)
+
+	// First sync: one-sided files are copied, identical ones recorded
+	ws := runSync(t, engine, "bidirectional", ConflictSkip, wslDir, winDir)
+	if len(ws.synced) != 2 || len(ws.conflicts) != 0 {
+		t.Fatalf("first sync: synced %v, conflicts %v", ws.synced, ws.conflicts)
+	}
+	if readString(filepath.Join(winDir, "a.txt")) != "a v1" || readString(filepath.Join(wslDir, "sub", "b.txt")) != "b v1" {
+		t.Fatal("one-sided files were not copied")
+	}
+	if _, err := os.Stat(syncStatePath(wslDir, winDir)); err != nil {
+		t.Fatalf("sync state not saved: %v", err)
+	}
+
+	// Only the Windows side changed: copied back without a conflict
+	writeAt(t, filepath.Join(winDir, "a.txt"), "a v2 from windows", base.Add(time.Minute))
+	ws = runSync(t, engine, "bidirectional", ConflictSkip, wslDir, winDir)
+	if len(ws.conflicts) != 0 || readString(filepath.Join(wslDir, "a.txt")) != "a v2 from windows" {
+		t.Fatalf("one-sided change: synced %v, conflicts %v", ws.synced, ws.conflicts)
+	}
+
+	// Both sides changed: reported, neither copy overwritten
+	writeAt(t, filepath.Join(wslDir, "a.txt"), "a v3 wsl", base.Add(2*time.Minute))
+	writeAt(t, filepath.Join(winDir, "a.txt"), "a v3 windows!", base.Add(3*time.Minute))
+	ws = runSync(t, engine, "bidirectional", ConflictSkip, wslDir, winDir)
+	if len(ws.conflicts) != 1 || !strings.HasPrefix(ws.conflicts[0], "a.txt: changed on both sides") || len(ws.synced) != 0 {
+		t.Fatalf("conflict: synced %v, conflicts %v", ws.synced, ws.conflicts)
+	}
+	if readString(filepath.Join(wslDir, "a.txt")) != "a v3 wsl" || readString(filepath.Join(winDir, "a.txt")) != "a v3 windows!" {
+		t.Fatal("a conflicting file was overwritten")
+	}
+
+	// newest_wins takes the later Windows copy and clears the conflict
+	ws = runSync(t, engine, "bidirectional", ConflictNewestWins, wslDir, winDir)
+	if len(ws.resolved) != 1 || readString(filepath.Join(wslDir, "a.txt")) != "a v3 windows!" {
+		t.Fatalf("newest_wins: resolved %v, conflicts %v", ws.resolved, ws.conflicts)
+	}
+	if ws = runSync(t, engine, "bidirectional", ConflictSkip, wslDir, winDir); len(ws.synced)+len(ws.conflicts) != 0 {
+		t.Errorf("workspace not in sync after resolution: synced %v, conflicts %v", ws.synced, ws.conflicts)
+	}
+}
+
+func TestSyncWorkspace_PreferStrategies(t *testing.T) {
+	engine, _ := setupProgressEngine(t)
+	wslDir, winDir := syncPair(t)
+	now := time.Now()
+
+	// No sync state yet: differing copies are conflicts
+	writeAt(t, filepath.Join(wslDir, "x.txt"), "wsl", now)
+	writeAt(t, filepath.Join(winDir, "x.txt"), "windows", now.Add(-time.Hour))
+	if ws := runSync(t, engine, "bidirectional", ConflictSkip, wslDir, winDir); len(ws.conflicts) != 1 {
+		t.Fatalf("conflicts = %v", ws.conflicts)
+	}
+	runSync(t, engine, "bidirectional", ConflictPreferWindows, wslDir, winDir)
+	if readString(filepath.Join(wslDir, "x.txt")) != "windows" {
+		t.Error("prefer_windows did not keep the Windows copy")
+	}
+
+	writeAt(t, filepath.Join(wslDir, "x.txt"), "wsl edit", now)
+	writeAt(t, filepath.Join(winDir, "x.txt"), "windows edit", now.Add(time.Hour))
+	runSync(t, engine, "bidirectional", ConflictPreferWSL, wslDir, winDir)
+	if readString(filepath.Join(winDir, "x.txt")) != "wsl edit" {
+		t.Error("prefer_wsl did not keep the WSL copy")
+	}
+}
+
+func TestSyncWorkspace_OneWayConflictOnlyWithState(t *testing.T) {
+	engine, _ := setupProgressEngine(t)
+	wslDir, winDir := syncPair(t)
+	now := time.Now()
+
+	// Without sync state a one-way sync overwrites, as it always has
+	writeAt(t, filepath.Join(wslDir, "f.txt"), "v1", now)
+	writeAt(t, filepath.Join(winDir, "f.txt"), "old", now)
+	runSync(t, engine, "wsl_to_windows", ConflictSkip, wslDir, winDir)
+	if readString(filepath.Join(winDir, "f.txt")) != "v1" {
+		t.Fatal("first one-way sync did not copy")
+	}
+
+	// Destination edited too: a conflict, and prefer_windows keeps it
+	writeAt(t, filepath.Join(wslDir, "f.txt"), "v2", now.Add(time.Minute))
+	writeAt(t, filepath.Join(winDir, "f.txt"), "windows edit", now.Add(2*time.Minute))
+	if ws := runSync(t, engine, "wsl_to_windows", ConflictSkip, wslDir, winDir); len(ws.conflicts) != 1 {
+		t.Fatalf("conflicts = %v", ws.conflicts)
+	}
+	ws := runSync(t, engine, "wsl_to_windows", ConflictPreferWindows, wslDir, winDir)
+	if len(ws.resolved) != 1 || !strings.Contains(ws.resolved[0], "kept destination") {
+		t.Errorf("resolved = %v", ws.resolved)
+	}
+	if readString(filepath.Join(winDir, "f.txt")) != "windows edit" || readString(filepath.Join(wslDir, "f.txt")) != "v2" {
+		t.Error("one-way sync wrote to its source or lost the destination edit")
+	}
+}
+
+func TestSyncWorkspace_DryRunPlan(t *testing.T) {
+	engine, _ := setupProgressEngine(t)
+	wslDir, winDir := syncPair(t)
+	base := time.Now().Add(-time.Hour)
+	writeAt(t, filepath.Join(wslDir, "same.txt"), "same", base)
+	writeAt(t, filepath.Join(winDir, "same.txt"), "same", base)
+	runSync(t, engine, "bidirectional", ConflictSkip, wslDir, winDir)
+
+	writeAt(t, filepath.Join(wslDir, "new.txt"), "brand new", base)
+	writeAt(t, filepath.Join(wslDir, "both.txt"), "wsl side", base.Add(time.Minute))
+	writeAt(t, filepath.Join(winDir, "both.txt"), "windows side", base.Add(time.Minute))
+
+	ws := &workspaceSync{engine: engine, direction: "wsl_to_windows", strategy: ConflictSkip, dryRun: true}
+	ws.syncDirs(wslDir, winDir)
+	result := ws.result()
+	plan := result["plan"].([]SyncPlanEntry)
+	actions := map[string]string{}
+	for _, p := range plan {
+		actions[p.Path] = p.Action
+	}
+	// No state for both.txt: a one-way sync overwrites the destination
+	want := map[string]string{"new.txt": SyncPlanCopyNew, "both.txt": SyncPlanOverwrite, "same.txt": SyncPlanSkipIdentical}
+	if len(actions) != len(want) {
+		t.Fatalf("plan = %+v", plan)
+	}
+	for path, action := range want {
+		if actions[path] != action {
+			t.Errorf("%s: action %q, want %q", path, actions[path], action)
+		}
+	}
+	if transfer := result["bytes_to_transfer"].(int64); transfer != int64(len("brand new")+len("wsl side")) {
+		t.Errorf("bytes_to_transfer = %d", transfer)
+	}
+	if _, err := os.Stat(filepath.Join(winDir, "new.txt")); err == nil {
+		t.Error("dry run copied a file")
+	}
+
+	// Changed on both sides since the recorded sync: a conflict
+	writeAt(t, filepath.Join(wslDir, "same.txt"), "edited in wsl", base.Add(2*time.Minute))
+	writeAt(t, filepath.Join(winDir, "same.txt"), "edited in windows", base.Add(2*time.Minute))
+	ws = &workspaceSync{engine: engine, direction: "bidirectional", strategy: ConflictSkip, dryRun: true}
+	ws.syncDirs(wslDir, winDir)
+	for _, p := range ws.result()["plan"].([]SyncPlanEntry) {
+		if p.Path == "same.txt" && p.Action != SyncPlanConflict {
+			t.Errorf("same.txt planned as %s, want conflict", p.Action)
+		}
+	}
+}
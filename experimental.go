@@ -36,6 +36,25 @@
 var experimentalFeatures = map[string]string{
 	// Example (graduated — remove after one release):
 	// "git:implicit-pathspec": "4.5.31",
+
+	"pipeline":           "4.5.33",
+	"cache":              "4.5.33",
+	"hooks":              "4.5.33",
+	"allowed_paths":      "4.5.33",
+	"fetch_continuation": "4.5.33",
+	"analyze_directory":  "4.5.33",
+	"archive":            "4.5.33",
+
+	"batch_operations:organize_json":     "4.5.33",
+	"batch_operations:continue_on_error": "4.5.33",
+	"backup:rollback_batch":              "4.5.33",
+	"wsl:convert_path":                   "4.5.33",
+	"server_info:report":                 "4.5.33",
+	"server_info:history":                "4.5.33",
+	"server_info:audit_log":              "4.5.33",
+	"server_info:reset_telemetry":        "4.5.33",
+	"server_info:tools":                  "4.5.33",
+	"git:changed":                        "4.5.33",
 }
 
 // isExperimental reports whether featureKey is currently experimental and
@@ -34,7 +34,7 @@ func TestExperimental_EntriesWellFormed(t *testing.T) {
 // without ':') name a tool that is actually registered — a typo in the map
 // would silently disable enforcement for that feature.
 func TestExperimental_ToolEntriesAreRegistered(t *testing.T) {
-	reg := buildEditRegistry(t, t.TempDir(), false)
+	reg := newHelpTestRegistry(t, t.TempDir())
 	for key := range experimentalFeatures {
 		if strings.Contains(key, ":") {
 			continue // mode-level entries are informational
@@ -4,6 +4,7 @@
 	"fmt"
 	"log"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -111,6 +112,18 @@ func parseSize(sizeStr string) (int64, error) {
 	return size * multiplier, nil
 }
 
+// splitCommaList splits a comma-separated flag value, trimming whitespace and
+// dropping empty entries. Returns nil for an empty string.
+func splitCommaList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
 // formatBatchResult formats a BatchResult as human-readable text
 func formatBatchResult(result core.BatchResult) string {
 	var sb strings.Builder
@@ -125,6 +138,23 @@ func formatBatchResult(result core.BatchResult) string {
 			sb.WriteString("Validation failed\n")
 			sb.WriteString(fmt.Sprintf("Errors: %v\n", result.Errors))
 		}
+		if len(result.Results) > 0 {
+			sb.WriteString("\nOperation Checks:\n")
+			for _, opResult := range result.Results {
+				status := "OK"
+				if !opResult.Success {
+					status = "FAIL"
+				}
+				sb.WriteString(fmt.Sprintf("  [%s] [%d] %s: %s\n", status, opResult.Index, opResult.Type, opResult.Path))
+				for _, finding := range opResult.Findings {
+					sb.WriteString(fmt.Sprintf("      %s\n", finding))
+				}
+				if opResult.Error != "" {
+					sb.WriteString(fmt.Sprintf("      Error: %s\n", opResult.Error))
+				}
+			}
+		}
+		writeGlobExpansions(&sb, result.GlobExpansions, true)
 		return sb.String()
 	}
 
@@ -140,27 +170,35 @@ func formatBatchResult(result core.BatchResult) string {
 	sb.WriteString(fmt.Sprintf("  Total operations: %d\n", result.TotalOps))
 	sb.WriteString(fmt.Sprintf("  Completed: %d\n", result.CompletedOps))
 	sb.WriteString(fmt.Sprintf("  Failed: %d\n", result.FailedOps))
+	if result.SkippedOps > 0 {
+		sb.WriteString(fmt.Sprintf("  Skipped: %d (group already failed)\n", result.SkippedOps))
+	}
 	sb.WriteString(fmt.Sprintf("  Execution time: %s\n", result.ExecutionTime))
 
-	if result.BackupPath != "" {
-		sb.WriteString(fmt.Sprintf("  Backup created: %s\n", result.BackupPath))
+	if result.BackupID != "" {
+		sb.WriteString(fmt.Sprintf("  Backup: %s (undo: backup(action:\"restore\", backup_id:\"%s\"))\n", result.BackupID, result.BackupID))
 	}
 
 	if result.RollbackDone {
 		sb.WriteString("\nRollback performed - all changes reverted\n")
 	}
 
+	writeGlobExpansions(&sb, result.GlobExpansions, false)
+
 	// Individual operation results
 	sb.WriteString("\nOperation Details:\n")
 	for _, opResult := range result.Results {
 		status := "OK"
-		if !opResult.Success {
-			status = "FAIL"
-		} else if opResult.Skipped {
+		if opResult.Skipped {
 			status = "SKIP"
+		} else if !opResult.Success {
+			status = "FAIL"
 		}
 
 		sb.WriteString(fmt.Sprintf("  [%s] [%d] %s: %s", status, opResult.Index, opResult.Type, opResult.Path))
+		if opResult.Group != "" {
+			sb.WriteString(fmt.Sprintf(" {%s}", opResult.Group))
+		}
 
 		if opResult.BytesAffected > 0 {
 			sb.WriteString(fmt.Sprintf(" (%s)", formatSize(opResult.BytesAffected)))
@@ -180,9 +218,70 @@ func formatBatchResult(result core.BatchResult) string {
 		}
 	}
 
+	if len(result.FailedGroups) > 0 && result.BackupID != "" {
+		sb.WriteString(fmt.Sprintf("\nFailed groups: %s\n", strings.Join(result.FailedGroups, ", ")))
+		sb.WriteString(fmt.Sprintf("Revert only these groups: backup(action:\"rollback_batch\", backup_id:\"%s\")\n", result.BackupID))
+	}
+
 	return sb.String()
 }
 
+// formatBatchRollbackResult formats the outcome of backup(action:"rollback_batch")
+func formatBatchRollbackResult(result *core.BatchRollbackResult) string {
+	var sb strings.Builder
+	if result.DryRun {
+		sb.WriteString("Batch Rollback Preview (dry run)\n")
+	} else {
+		sb.WriteString("Batch Rollback\n")
+	}
+	sb.WriteString("---\n\n")
+	if len(result.Groups) == 0 {
+		sb.WriteString(fmt.Sprintf("Batch %s has no failed groups - nothing to roll back\n", result.BackupID))
+		return sb.String()
+	}
+	sb.WriteString(fmt.Sprintf("Backup: %s\n", result.BackupID))
+	sb.WriteString(fmt.Sprintf("Failed groups: %s\n", strings.Join(result.Groups, ", ")))
+	for _, section := range []struct {
+		title string
+		items []string
+	}{
+		{"Restored", result.Restored},
+		{"Removed", result.Removed},
+		{"Moved back", result.MovedBack},
+		{"Kept (also changed by a successful group)", result.Kept},
+		{"Errors", result.Errors},
+	} {
+		if len(section.items) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("\n%s:\n", section.title))
+		for _, item := range section.items {
+			sb.WriteString(fmt.Sprintf("  - %s\n", item))
+		}
+	}
+	if len(result.Restored)+len(result.Removed)+len(result.MovedBack) == 0 && len(result.Errors) == 0 {
+		sb.WriteString("\nNothing to revert\n")
+	}
+	return sb.String()
+}
+
+// writeGlobExpansions lists what each path/source glob expanded to. validate_only
+// shows every file so the batch can be reviewed before running it.
+func writeGlobExpansions(sb *strings.Builder, expansions []core.GlobExpansion, listFiles bool) {
+	if len(expansions) == 0 {
+		return
+	}
+	sb.WriteString("\nGlob expansions:\n")
+	for _, exp := range expansions {
+		sb.WriteString(fmt.Sprintf("  Op %d %s %s -> %d files\n", exp.Index, exp.Field, exp.Pattern, len(exp.Matches)))
+		if listFiles {
+			for _, match := range exp.Matches {
+				sb.WriteString(fmt.Sprintf("    - %s\n", match))
+			}
+		}
+	}
+}
+
 // formatPipelineResult formats pipeline execution results for display
 func formatPipelineResult(result *core.PipelineResult, compact bool) string {
 	if result == nil {
@@ -212,9 +311,23 @@ func formatPipelineResult(result *core.PipelineResult, compact bool) string {
 				errorInfo = " | rolled back"
 			}
 		}
-		return fmt.Sprintf("%s: %d/%d steps | %d files | %d edits%s%s",
+		skipInfo := ""
+		skipped := 0
+		for _, sr := range result.Results {
+			if sr.Skipped {
+				skipped++
+			}
+		}
+		if skipped > 0 {
+			skipInfo = fmt.Sprintf(" | %d skipped", skipped)
+		}
+		backupInfo := ""
+		if result.BackupID != "" {
+			backupInfo = " | backup:" + result.BackupID
+		}
+		return fmt.Sprintf("%s: %d/%d steps | %d files | %d edits%s%s%s%s",
 			status, result.CompletedSteps, result.TotalSteps,
-			len(result.FilesAffected), result.TotalEdits, riskInfo, errorInfo)
+			len(result.FilesAffected), result.TotalEdits, skipInfo, riskInfo, backupInfo, errorInfo)
 	}
 
 	// Verbose mode: detailed output
@@ -249,12 +362,19 @@ func formatPipelineResult(result *core.PipelineResult, compact bool) string {
 	for i, stepResult := range result.Results {
 		stepNum := i + 1
 		status := "OK"
-		if !stepResult.Success {
+		if stepResult.TimedOut {
+			status = "TIMEOUT"
+		} else if !stepResult.Success {
 			status = "FAIL"
+		} else if stepResult.Skipped {
+			status = "SKIPPED"
 		}
 
 		output.WriteString(fmt.Sprintf("%d. %s [%s] %s\n",
 			stepNum, stepResult.StepID, stepResult.Action, status))
+		if stepResult.SkipReason != "" {
+			output.WriteString(fmt.Sprintf("   Reason: %s\n", stepResult.SkipReason))
+		}
 		output.WriteString(fmt.Sprintf("   Duration: %v\n", stepResult.Duration))
 
 		if len(stepResult.FilesMatched) > 0 {
@@ -275,6 +395,59 @@ func formatPipelineResult(result *core.PipelineResult, compact bool) string {
 			output.WriteString(fmt.Sprintf("   Edits: %d replacements\n", stepResult.EditsApplied))
 		}
 
+		if stepResult.ResultsLimited {
+			output.WriteString(fmt.Sprintf("   Results limited to %d files (max search results)\n", len(stepResult.FilesMatched)))
+		}
+
+		if len(stepResult.FilesSkipped) > 0 {
+			reasons := make([]string, 0, len(stepResult.FilesSkipped))
+			for reason := range stepResult.FilesSkipped {
+				reasons = append(reasons, reason)
+			}
+			sort.Strings(reasons)
+			parts := make([]string, 0, len(reasons))
+			for _, reason := range reasons {
+				parts = append(parts, fmt.Sprintf("%s %d", reason, stepResult.FilesSkipped[reason]))
+			}
+			output.WriteString(fmt.Sprintf("   Skipped: %s\n", strings.Join(parts, ", ")))
+		}
+
+		if len(stepResult.DirsCreated) > 0 {
+			verb := "Created"
+			if result.DryRun {
+				verb = "Would create"
+			}
+			output.WriteString(fmt.Sprintf("   %s: %s\n", verb, strings.Join(stepResult.DirsCreated, ", ")))
+		}
+
+		if len(stepResult.Renames) > 0 {
+			verb := "Renamed"
+			if result.DryRun {
+				verb = "Would rename"
+			}
+			olds := make([]string, 0, len(stepResult.Renames))
+			for old := range stepResult.Renames {
+				olds = append(olds, old)
+			}
+			sort.Strings(olds)
+			output.WriteString(fmt.Sprintf("   %s: %d files\n", verb, len(olds)))
+			for j, old := range olds {
+				if j == 5 && !result.Verbose {
+					output.WriteString(fmt.Sprintf("     ... and %d more\n", len(olds)-5))
+					break
+				}
+				output.WriteString(fmt.Sprintf("     %s → %s\n", old, stepResult.Renames[old]))
+			}
+		}
+
+		if stepResult.Action == "write" && !stepResult.Skipped && stepResult.Success {
+			verb := "Wrote"
+			if result.DryRun {
+				verb = "Would write"
+			}
+			output.WriteString(fmt.Sprintf("   %s: %s\n", verb, formatSize(stepResult.BytesWritten)))
+		}
+
 		if len(stepResult.Counts) > 0 {
 			totalCount := 0
 			for _, count := range stepResult.Counts {
@@ -312,6 +485,23 @@ func formatPipelineResult(result *core.PipelineResult, compact bool) string {
 			}
 		}
 
+		// Verbose: per-file diffs from edit/regex_transform
+		if result.Verbose && len(stepResult.Diffs) > 0 {
+			paths := make([]string, 0, len(stepResult.Diffs))
+			for path := range stepResult.Diffs {
+				paths = append(paths, path)
+			}
+			sort.Strings(paths)
+			for _, path := range paths {
+				for _, line := range strings.Split(strings.TrimRight(stepResult.Diffs[path], "\n"), "\n") {
+					output.WriteString(fmt.Sprintf("   %s\n", line))
+				}
+			}
+		}
+		if stepResult.DiffsTruncated {
+			output.WriteString("   Diffs: truncated to fit the response size limit\n")
+		}
+
 		// Verbose: full file list when more than 5
 		if result.Verbose && len(stepResult.FilesMatched) > 5 {
 			output.WriteString("   All files:\n")
@@ -324,6 +514,10 @@ func formatPipelineResult(result *core.PipelineResult, compact bool) string {
 			output.WriteString(fmt.Sprintf("   Risk: %s\n", stepResult.RiskLevel))
 		}
 
+		if stepResult.Retries > 0 {
+			output.WriteString(fmt.Sprintf("   Retries: %d (transient failures)\n", stepResult.Retries))
+		}
+
 		if stepResult.Error != "" {
 			output.WriteString(fmt.Sprintf("   Error: %s\n", stepResult.Error))
 		}
@@ -386,6 +580,26 @@ func autoTruncateLargeFile(content, path string) string {
 	)
 }
 
+// largeViewLines maps read_file's max_lines and mode onto the head and tail
+// line counts of a large-file view, the way truncateContent splits them
+func largeViewLines(maxLines int, mode string) (head, tail int) {
+	if maxLines <= 0 {
+		if mode == "head" || mode == "tail" {
+			maxLines = 100 // truncateContent's default
+		} else {
+			return core.LargeViewHeadLines, core.LargeViewTailLines
+		}
+	}
+	switch mode {
+	case "head":
+		return maxLines, 0
+	case "tail":
+		return 0, maxLines
+	default:
+		return maxLines / 2, maxLines - maxLines/2
+	}
+}
+
 func truncateContent(content string, maxLines int, mode string) string {
 	lines := strings.Split(content, "\n")
 	totalLines := len(lines)
@@ -465,16 +679,3 @@ func setupLogging(config *Configuration) {
 		log.SetFlags(log.LstdFlags)
 	}
 }
-
-// runBenchmark runs performance benchmarks
-func runBenchmark(config *Configuration) {
-	log.Printf("Running performance benchmark...")
-
-	// This would run comprehensive benchmarks comparing:
-	// 1. This ultra-fast server vs standard MCP
-	// 2. Various cache sizes and parallel operation counts
-	// 3. Different file sizes and operation types
-
-	fmt.Printf("Benchmark results will be implemented in bench/ package\n")
-	fmt.Printf("Run: cd bench && go run benchmark.go\n")
-}
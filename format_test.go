@@ -4,6 +4,8 @@
 	"fmt"
 	"strings"
 	"testing"
+
+	"github.com/mcp/filesystem-ultra/core"
 )
 
 func TestAutoTruncateLargeFile_SmallFile(t *testing.T) {
@@ -77,3 +79,24 @@ func max(a, b int) int {
 	}
 	return b
 }
+
+func TestFormatSyncPlan_TransfersFirstAndCapped(t *testing.T) {
+	plan := []core.SyncPlanEntry{
+		{Root: "/w", Path: "a.txt", Action: core.SyncPlanSkipIdentical},
+		{Root: "/w", Path: "b.txt", Action: core.SyncPlanCopyNew, Size: 2048, From: "/w/b.txt", To: "/mnt/c/w/b.txt"},
+		{Root: "/w", Path: "c.txt", Action: core.SyncPlanConflict},
+		{Root: "/w", Path: "d.txt", Action: core.SyncPlanOverwrite, Size: 10, From: "/w/d.txt", To: "/mnt/c/w/d.txt"},
+	}
+	text := formatSyncPlan(plan, 2058, 3)
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	if !strings.HasPrefix(lines[0], "Plan: 1 to copy, 1 to overwrite") || !strings.Contains(lines[0], "1 conflicts") {
+		t.Errorf("summary = %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "copy new") || !strings.Contains(lines[1], "b.txt -> /mnt/c/w/b.txt") ||
+		!strings.Contains(lines[2], "overwrite") {
+		t.Errorf("files to transfer are not listed first:\n%s", text)
+	}
+	if len(lines) != 5 || lines[4] != "  ... and 1 more" {
+		t.Errorf("plan not capped at 3 lines:\n%s", text)
+	}
+}
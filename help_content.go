@@ -31,7 +31,7 @@ func getHelpContent(topic string, compactMode bool) string {
 ## AVAILABLE TOPICS
 Call server_info(action:"help", topic:"...") with:
 - "workflow" - The 4-step efficient workflow
-- "tools"    - Complete list of 20 tools
+- "tools"    - Complete list of 27 tools
 - "read"     - Reading files efficiently
 - "write"    - Writing and creating files
 - "edit"     - Editing files (most important!)
@@ -77,19 +77,23 @@ func getHelpContent(topic string, compactMode bool) string {
 `)
 
 	case "tools":
-		sb.WriteString(`# COMPLETE TOOL LIST (20 Tools)
+		sb.WriteString(`# COMPLETE TOOL LIST (27 Tools)
 
 Use help() for the live catalog generated from the registered MCP tools. This topic is the compact reference for clients that hide full schemas.
 
-## Core I/O (6)
+## Core I/O (7)
 
 read_file
 - Purpose: Read a full file, line range, head/tail, or base64 content
 - Key params: path, paths, start_line, end_line, max_lines, mode, encoding
 
+fetch_continuation
+- Purpose: Next chunk of a response cut at --max-response-size
+- Key params: continuation_token
+
 write_file
-- Purpose: Create or overwrite a file atomically
-- Key params: path, content, content_base64, encoding
+- Purpose: Create or overwrite a file atomically; stream content larger than one message through a write session
+- Key params: path, content, content_base64, encoding, session (begin|append|commit|abort), session_id, chunk, index, expected_sha256
 
 edit_file
 - Purpose: Modify existing files with backup, OCC, risk checks, and diffs
@@ -100,26 +104,26 @@ func getHelpContent(topic string, compactMode bool) string {
 - Key params: path, edits_json, diff_format, dry_run, expected_hash
 
 list_directory
-- Purpose: List directory contents
-- Key params: path, output_format (compact|json|tree), max_depth
+- Purpose: List directory contents; sort, filter and page through large directories
+- Key params: path, output_format (compact|json|tree), max_depth, sort (name|size|mtime), order, filter, offset, limit, dirs_only, files_only, include_git
 
 search_files
 - Purpose: Search by filename or content
-- Key params: path, pattern, file_types, include_content, include_context, case_sensitive, count_only
+- Key params: path, pattern, file_types, include_content, include_context, case_sensitive, count_only, format (json: mime/is_binary per match)
 
-## File Operations (5)
+## File Operations and Analysis (7)
 
 get_file_info
-- Purpose: Return metadata for one or several files/directories
-- Key params: path, paths
+- Purpose: Return metadata, MIME type and binary flag for one or several files/directories (missing/denied paths reported inline)
+- Key params: path, paths, include_hash, include_stats (lines/words/bytes/longest/blank; directories: per file + totals), patterns, include_git
 
 move_file
-- Purpose: Move or rename a file or directory
+- Purpose: Move or rename a file or directory (across drives: copy + verify + delete)
 - Key params: source_path, dest_path
 
 copy_file
-- Purpose: Copy a file or directory
-- Key params: source_path, dest_path
+- Purpose: Copy a file or directory tree (modes and mtimes kept, hash-checked)
+- Key params: source_path, dest_path, skip_default_excludes, verify
 
 delete_file
 - Purpose: Soft-delete by default, or hard-delete permanently
@@ -129,37 +133,61 @@ func getHelpContent(topic string, compactMode bool) string {
 - Purpose: Create a directory tree like mkdir -p
 - Key params: path
 
-## Batch, Recovery, and Platform (6)
+analyze_directory
+- Purpose: Read-only tree, du-style size, free disk space, duplicate files, directory comparison, change detection between calls, recently modified files
+- Key params: action (tree|size|disk_usage|duplicates|compare|watch|recent), path, path_a, path_b, check (compare of directories), mode (compare of files: hash|diff|stats), max_depth, include_git, exclude, cursor, within
+
+archive
+- Purpose: Pack files and directories into a zip or tar.gz archive, unpack one with zip-slip protection, list its entries or read one entry without extracting; gzip or gunzip a single file
+- Key params: action (create|extract|list|read|gzip|gunzip), archive_path, sources, glob, base_dir, exclude, compression_level, dest_dir (extract), include_glob, dry_run, entry_path (read), max_bytes, as_base64, path (gzip|gunzip), dest, delete_source, overwrite, force, follow_symlinks
+
+## Batch, Recovery, and Platform (10)
 
 batch_operations
-- Purpose: Run atomic multi-file ops, pipelines, or batch rename
-- Key params: request_json, pipeline_json, rename_json
+- Purpose: Run atomic multi-file ops, pipelines, batch rename, or rule-based folder organizing
+- Key params: request_json, pipeline_json, rename_json, organize_json
+
+pipeline
+- Purpose: Run a pipeline inline or from a reviewed JSON file, and check running/recent runs
+- Key params: action (run|load|status), request_json, path, dry_run, id
 
 project_replace
 - Purpose: Find/replace a token across a project tree
 - Key params: path, find, replace, file_types, preview
 
 backup
-- Purpose: List, inspect, compare, restore, clean up, or undo backups
-- Key params: action, backup_id, file_path, preview, filter_path
+- Purpose: List, inspect, compare, restore, clean up, or undo backups; roll back failed batch groups; manage the trash (trash_status shows which trash root each allowed root uses)
+- Key params: action, backup_id, file_path, preview, filter_path, sd_id, older_than_days, dry_run (purge_trash / empty_trash)
 
 analyze_operation
 - Purpose: Preview risk and impact before acting
 - Key params: path, operation
 
 wsl
-- Purpose: WSL/Windows sync, status, and autosync operations
-- Key params: action, wsl_path, windows_path, direction
+- Purpose: WSL/Windows sync, status, autosync, and path conversion
+- Key params: action, wsl_path, windows_path, direction, path, target
+
+allowed_paths
+- Purpose: List allowed paths and write-protection rules; add or remove allowed paths at runtime
+- Key params: action (list|add|remove|rules), path, persist
+
+cache
+- Purpose: Read-cache statistics, clearing and invalidation
+- Key params: action (stats|clear|invalidate_path|invalidate_prefix), path, top
+
+hooks
+- Purpose: Show, reload, or dry-run the configured hooks
+- Key params: action (status|reload|test), event, path, sample_content
 
 server_info
-- Purpose: Static help topics, performance stats, and artifact management
-- Key params: action, topic, sub_action, content, path
+- Purpose: Static help topics, performance stats, effective configuration, registered tools, operation report/history/audit log, telemetry reset, and named artifact slots
+- Key params: action, topic, limit, since, filter_tool, filter_path, sub_action (artifact: capture|capture_chunk|diff|write|apply|info|delete), name, content, chunk, index, total, final, path, context_lines, expected_hash, mode
 
 ## Version Control, JavaScript, and Discovery (3)
 
 git
-- Purpose: Safe Git status, diff, log, show, add, commit, restore, branch, and init actions
-- Key params: action, path, paths, output, rev, max_lines
+- Purpose: Safe Git status, diff, log, show, add, commit, restore, branch, init, and changed (working-tree changes) actions
+- Key params: action, path, paths, output, rev, max_lines, format
 
 minify_js
 - Purpose: Pure-Go JavaScript minification without Node
@@ -170,7 +198,7 @@ func getHelpContent(topic string, compactMode bool) string {
 - Key params: tool
 
 ## Disabled Names
-Aliases read_text_file, search, edit, write, create_file, directory_tree, View, Edit, Write, Replace, LS, GlobTool, GrepTool and the fs super-tool are NOT registered. A runtime-native tool with one of those names may target a different sandbox; it is not a filesystem-ultra alias.
+Aliases read_text_file, search, edit, write, create_file, View, Edit, Write, Replace, LS, GlobTool, GrepTool and the fs super-tool are NOT registered. A runtime-native tool with one of those names may target a different sandbox; it is not a filesystem-ultra alias.
 `)
 
 	case "read":
@@ -182,6 +210,7 @@ func getHelpContent(topic string, compactMode bool) string {
 | <1000 lines  | read_file(path)               |
 | >1000 lines  | read_file(path, start_line=N, end_line=M) |
 | Binary file  | read_file(path, encoding:"base64") |
+| Over --binary-threshold (1MB) | read_file(path) returns the first 300 + last 50 lines and the total; read the rest by range |
 
 ## Best Practice: Line Range
 # Read only lines 100-150 of a large file
@@ -208,6 +237,7 @@ func getHelpContent(topic string, compactMode bool) string {
 | Create file    | write_file(path, content)    |
 | Overwrite file | write_file(path, content)    |
 | Binary file    | write_file(path, content_base64="...") |
+| Huge content   | write_file(path, session="begin") → append → commit |
 
 ## Examples
 
@@ -217,6 +247,11 @@ func getHelpContent(topic string, compactMode bool) string {
 # Write binary from base64
 write_file("/path/to/image.png", content_base64="iVBOR...")
 
+# Content larger than one message: stream it in chunks
+write_file("/path/to/dump.sql", session="begin")                      → session_id ws_...
+write_file("/path/to/dump.sql", session="append", session_id="ws_...", chunk="...", index=0)
+write_file("/path/to/dump.sql", session="commit", session_id="ws_...", expected_sha256="...")
+
 ## IMPORTANT
 - write_file OVERWRITES the entire file
 - For small changes, use edit_file instead!
@@ -327,7 +362,9 @@ func getHelpContent(topic string, compactMode bool) string {
     {"type": "write", "path": "file1.txt", "content": "..."},
     {"type": "write", "path": "file2.txt", "content": "..."},
     {"type": "copy", "source": "file1.txt", "destination": "backup.txt"},
-    {"type": "edit", "path": "file3.txt", "old_text": "x", "new_text": "y"}
+    {"type": "edit", "path": "file3.txt", "old_text": "x", "new_text": "y"},
+    {"type": "append", "path": "log.txt", "content": "done\n"},
+    {"type": "replace_nth", "path": "file3.txt", "pattern": "TODO", "replacement": "DONE", "occurrence": -1}
   ],
   "atomic": true,
   "create_backup": true
@@ -342,6 +379,32 @@ func getHelpContent(topic string, compactMode bool) string {
   ]
 }')
 
+Pipelines can also run through pipeline(action="run", request_json=...) or be
+kept in the repo and run with pipeline(action="load", path="pipelines/refactor.json").
+
+Long pipelines report progress after each step and every 10 files within a
+step: as notifications/progress when the client sends a progressToken, and
+always via pipeline(action="status") (current step, files processed/total, elapsed).
+Cancelling the request stops the current step before its next file and
+rolls back from the backup like stop_on_error.
+
+## Conditional Steps
+Add "condition" to run a step only when prior results allow it; a false
+condition marks the step skipped (not failed) and input_from a skipped step
+yields no files:
+    {"id": "fix", "action": "edit", "input_from": "find",
+     "condition": "steps.count.total > 0 && len(steps.find.files) < 20", ...}
+Fields: total, files, files_count, edits, success, skipped, risk, error.
+Only earlier steps may be referenced.
+
+## Variables
+String params accept ${name} from a top-level "variables" map plus built-ins
+${date}, ${time}, ${timestamp}, ${pipeline.name}, ${steps.<id>.files[0]}:
+    "variables": {"old": "fetchUser"},
+    {"id": "cp", "action": "copy", "input_from": "find",
+     "params": {"destination": "backup/${pipeline.name}-${date}"}}
+Unknown variables fail validation. Use $${ for a literal ${.
+
 ## Example: Batch Rename
 batch_operations(rename_json='{
   "path": "/dir", "mode": "find_replace",
@@ -350,15 +413,51 @@ func getHelpContent(topic string, compactMode bool) string {
 
 ## Batch Operation Types
 - write, edit, copy, move, delete, create_directory
+- append (path, content — creates the file if missing)
+- replace_nth (path, pattern, replacement, occurrence: 1=first, -1=last)
+- globs: path (delete, edit, search_and_replace, replace_nth, append) and
+  source (copy/move into a destination directory) may be patterns such as
+  "/repo/gen/**/*.pb.go"; they expand inside the allowed paths, at most
+  max_matches files each (default 100). validate_only lists every match.
 
 ## Pipeline Actions
 - search, read_ranges, count_occurrences, edit, multi_edit
 - regex_transform, copy, rename, delete, aggregate, diff, merge
+- search follows search_files rules: skips .git/node_modules/build dirs,
+  binary files and files over 10MB, stops at max search results; the step
+  reports files_skipped counts and results_limited
+- write (params: path, content — creates or overwrites one file)
+- create_dir (param: path — ok if it already exists)
+- filter (input_from + include_glob, exclude_glob, path_contains, max_files,
+  min_count) narrows files before an edit, e.g. exclude_glob: ["*_test.go", "gen/**"]
+- copy/rename accept create_dirs: true to create the destination first
+- batch_rename (input_from + the rename_json fields: mode, find, replace,
+  prefix, suffix, pattern, extension, start_number, padding, case_sensitive)
+  renames in place; any collision fails the step before anything is renamed,
+  and rollback restores the original names
+- any step: "retry": {"attempts": 3, "delay_ms": 200, "backoff": "exponential"}
+  retries file-locked/busy failures only (never access denied or bad patterns)
+- any step: "timeout_seconds": 30 fails the step with a timeout (status
+  TIMEOUT) and follows stop_on_error/rollback; "max_duration_seconds" on the
+  pipeline bounds the whole run and always stops it
+- dry_run + verbose: edit/multi_edit/regex_transform attach a per-file unified
+  diff of what the real run would write (same matcher, counts and risk level;
+  diffs capped per file and in total)
 
 ## Options
 - atomic: true = All succeed or all rollback
-- create_backup: true = Backup before changes
-- validate_only: true = Dry run (no changes)
+- create_backup: true (default) = one backup of every file modified; the
+  backup_id is listed by backup(action:"list") and used for atomic rollback
+- continue_on_error: true = keep running independent operations after a
+  failure; operations sharing "group" are skipped once one of them fails.
+  backup(action="rollback_batch", backup_id) then reverts only the failed groups (not with atomic)
+- validate_only: true = Dry run (no changes) that replays the batch in
+  memory: checks sources, allowed paths and intra-batch conflicts (op 3
+  deletes what op 5 edits), reports expected replacement counts per edit and
+  destination overwrites, all listed per operation
+- Independent operations run concurrently (up to the server's parallel ops);
+  operations sharing a path (or a directory and something inside it) or a
+  group keep their order. Results are always listed in operation order.
 `)
 
 	case "errors":
@@ -551,7 +650,7 @@ func getHelpContent(topic string, compactMode bool) string {
 Available topics:
 - overview  - Quick start guide
 - workflow  - The 4-step efficient workflow
-- tools     - Complete list of 20 tools
+- tools     - Complete list of 27 tools
 - read      - Reading files efficiently
 - write     - Writing and creating files
 - edit      - Editing files (most important!)
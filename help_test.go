@@ -11,6 +11,7 @@
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
@@ -55,6 +56,10 @@ func newHelpTestRegistry(t *testing.T, allowedDir string) *toolRegistry {
 	registerPlatformTools(reg)
 	registerGitTools(reg)
 	registerMinifyTools(reg)
+	registerCacheTools(reg)
+	registerHookTools(reg)
+	registerPipelineTools(reg)
+	registerArchiveTools(reg)
 	registerHelpTool(reg)
 	return reg
 }
@@ -96,6 +101,8 @@ func TestHelp_NoArgs_ListsAllRegisteredTools(t *testing.T) {
 		"get_file_info", "move_file", "copy_file", "delete_file", "create_directory",
 		"search_files", "batch_operations", "backup", "analyze_operation",
 		"wsl", "server_info", "git", "minify_js", "project_replace", "help",
+		"pipeline", "cache", "hooks",
+		"allowed_paths", "fetch_continuation", "analyze_directory",
 	} {
 		if !strings.Contains(text, want) {
 			t.Errorf("help() missing %q", want)
@@ -103,6 +110,22 @@ func TestHelp_NoArgs_ListsAllRegisteredTools(t *testing.T) {
 	}
 }
 
+// The static "tools" topic is hand-written; keep it in step with what is
+// actually registered, count included.
+func TestHelpContent_ToolsTopicCoversRegisteredTools(t *testing.T) {
+	reg := newHelpTestRegistry(t, t.TempDir())
+	text := getHelpContent("tools", false)
+	tools := reg.server.ListTools()
+	for name := range tools {
+		if !strings.Contains(text, "\n"+name+"\n") {
+			t.Errorf("tools topic missing %q", name)
+		}
+	}
+	if want := fmt.Sprintf("(%d Tools)", len(tools)); !strings.Contains(text, want) {
+		t.Errorf("tools topic header does not say %s", want)
+	}
+}
+
 func TestHelp_NoArgs_DoesNotAdvertiseDisabledAliases(t *testing.T) {
 	reg := newHelpTestRegistry(t, t.TempDir())
 	text := resultText(t, callHelp(t, reg, nil))
@@ -112,7 +135,6 @@ func TestHelp_NoArgs_DoesNotAdvertiseDisabledAliases(t *testing.T) {
 		"view",
 		" GlobTool",
 		" GrepTool",
-		"directory_tree",
 		"read_text_file",
 	} {
 		// names may still appear inside description prose, so we look for a
@@ -0,0 +1,48 @@
+package main
+
+// Hook outcomes reach the caller: a formatter's rewrite and a denial are
+// named in write_file/edit_file verbose responses, and per-hook counters
+// show up in performance_stats.
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/mcp/filesystem-ultra/core"
+)
+
+func TestHookOutcomes_InResponsesAndStats(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks use tr and sh")
+	}
+	dir := t.TempDir()
+	reg := buildEditRegistry(t, dir, false)
+	hm := reg.engine.GetHookManager()
+	hm.AddHook(core.HookPreWrite, "write_file", &core.Hook{Name: "upper", Type: core.HookTypeCommand, Command: "tr", Args: []string{"a-z", "A-Z"}, Input: core.HookInputContent, Enabled: true})
+	hm.AddHook(core.HookPreEdit, "edit_file", &core.Hook{Name: "no-secrets", Type: core.HookTypeCommand, Command: `grep -q password && exit 2; exit 0`, Enabled: true})
+	hm.SetEnabled(true)
+
+	path := filepath.Join(dir, "notes.txt")
+	res := callWriteFile(t, reg, map[string]interface{}{"path": path, "content": "hello\nworld\n"})
+	if text := resultText(t, res); res.IsError || !strings.Contains(text, "hooks: upper modified content (+2/-2 lines)") {
+		t.Errorf("write_file response:\n%s", text)
+	}
+
+	res = callEdit(t, reg, map[string]interface{}{"path": path, "old_text": "WORLD", "new_text": "password"})
+	if text := resultText(t, res); !res.IsError || !strings.Contains(text, "hooks: blocked by hook 'no-secrets'") {
+		t.Errorf("edit_file response:\n%s", text)
+	}
+	res = callEdit(t, reg, map[string]interface{}{"path": path, "old_text": "WORLD", "new_text": "there"})
+	if text := resultText(t, res); res.IsError || strings.Contains(text, "hooks:") {
+		t.Errorf("allowed edit mentions hooks:\n%s", text)
+	}
+
+	perf := reg.engine.GetPerformanceStats()
+	for _, want := range []string{"Hooks: 3 runs", "upper: 1 runs", "1 modified", "no-secrets: 2 runs", "1 denied"} {
+		if !strings.Contains(perf, want) {
+			t.Errorf("performance_stats missing %q:\n%s", want, perf)
+		}
+	}
+}
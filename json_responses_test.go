@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mcp/filesystem-ultra/core"
+)
+
+func callJSONTool(t *testing.T, reg *toolRegistry, tool string, args map[string]any, out any) string {
+	t.Helper()
+	res, err := reg.handlers[tool](context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: tool, Arguments: args}})
+	if err != nil || res.IsError {
+		t.Fatalf("%s: err=%v result=%v", tool, err, res.Content)
+	}
+	text := textBlock(t, res)
+	if out != nil {
+		if err := json.Unmarshal([]byte(text), out); err != nil {
+			t.Fatalf("%s text is not JSON: %v\n%s", tool, err, text)
+		}
+	}
+	return text
+}
+
+func TestJSONResponses_PerCallFormat(t *testing.T) {
+	dir := t.TempDir()
+	reg := newHelpTestRegistry(t, dir)
+	path := filepath.Join(dir, "a.txt")
+	os.WriteFile(path, []byte("alpha beta\n"), 0644)
+
+	var info core.FileInfoResponse
+	callJSONTool(t, reg, "get_file_info", map[string]any{"path": path, "format": "json"}, &info)
+	if info.Type != "file" || info.Size != 11 || info.Name != "a.txt" {
+		t.Errorf("file info = %+v", info)
+	}
+	if text := callJSONTool(t, reg, "get_file_info", map[string]any{"path": path}, nil); !strings.Contains(text, "File Information") {
+		t.Errorf("default get_file_info is not prose:\n%s", text)
+	}
+
+	var listing core.DirectoryListing
+	callJSONTool(t, reg, "list_directory", map[string]any{"path": dir, "format": "json"}, &listing)
+	if listing.Total != 1 || listing.Entries[0].Name != "a.txt" {
+		t.Errorf("listing = %+v", listing)
+	}
+
+	var edit map[string]any
+	callJSONTool(t, reg, "edit_file", map[string]any{"path": path, "old_text": "beta", "new_text": "gamma", "format": "json"}, &edit)
+	if edit["content_hash"] == "" || edit["replacements"] != float64(1) {
+		t.Errorf("edit result = %v", edit)
+	}
+
+	var batch core.BatchResult
+	callJSONTool(t, reg, "batch_operations", map[string]any{"format": "json",
+		"request_json": `{"operations":[{"type":"write","path":"` + filepath.ToSlash(filepath.Join(dir, "b.txt")) + `","content":"x"}]}`}, &batch)
+	if !batch.Success || batch.CompletedOps != 1 {
+		t.Errorf("batch result = %+v", batch)
+	}
+
+	var backups core.BackupListResponse
+	callJSONTool(t, reg, "backup", map[string]any{"action": "list", "format": "json"}, &backups)
+	if backups.Count != len(backups.Backups) || backups.Count == 0 {
+		t.Errorf("backup list = %+v", backups)
+	}
+}
+
+func TestJSONResponses_ServerDefault(t *testing.T) {
+	dir := t.TempDir()
+	reg := newHelpTestRegistry(t, dir)
+	reg.engine.GetConfig().JSONResponses = true
+
+	var stats core.ServerStatsResponse
+	callJSONTool(t, reg, "server_info", map[string]any{"action": "stats"}, &stats)
+	if stats.Build == "" || stats.Performance.Runtime == nil || stats.EditTelemetry == nil {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	var batch core.FileInfoBatchResponse
+	callJSONTool(t, reg, "get_file_info", map[string]any{"paths": `["` + filepath.ToSlash(dir) + `","` + filepath.ToSlash(filepath.Join(dir, "missing")) + `"]`}, &batch)
+	if len(batch.Files) != 2 || batch.Files[0].Type != "dir" || batch.Files[1].Error == "" {
+		t.Errorf("batch info = %+v", batch.Files)
+	}
+
+	// format:"text" overrides the server default
+	if text := callJSONTool(t, reg, "server_info", map[string]any{"action": "stats", "format": "text"}, nil); !strings.Contains(text, "Performance Statistics:") {
+		t.Errorf("format text ignored:\n%s", text)
+	}
+}
+
+func TestServerInfo_ConfigAction(t *testing.T) {
+	dir := t.TempDir()
+	reg := newHelpTestRegistry(t, dir)
+
+	var cfg core.ServerConfigResponse
+	callJSONTool(t, reg, "server_info", map[string]any{"action": "config", "format": "json"}, &cfg)
+	if cfg.Version != serverVersion || cfg.Platform == "" || len(cfg.AllowedPaths) != 1 || cfg.AllowedPaths[0] != dir {
+		t.Errorf("config = %+v", cfg)
+	}
+	if cfg.Cache.Size != 4*1024*1024 || cfg.Risk.HighPercent == 0 || cfg.ParallelOps != 2 {
+		t.Errorf("cache/risk/limits = %+v %+v %d", cfg.Cache, cfg.Risk, cfg.ParallelOps)
+	}
+
+	text := callJSONTool(t, reg, "server_info", map[string]any{"action": "config"}, nil)
+	for _, want := range []string{"Server: v" + serverVersion, "Allowed paths:\n  " + dir, "Risk: medium", "Autosync: ", "Cache: 4.0 MB"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("config text missing %q:\n%s", want, text)
+		}
+	}
+}
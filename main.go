@@ -2,11 +2,16 @@
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
 	"runtime"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/mcp/filesystem-ultra/cache"
@@ -17,7 +22,7 @@
 type Configuration struct {
 	CacheSize        int64    // Cache size in bytes
 	ParallelOps      int      // Max concurrent operations
-	BinaryThreshold  int64    // File size threshold for binary protocol
+	BinaryThreshold  int64    // Over it, reads are summarized and writes streamed
 	VSCodeAPIEnabled bool     // Enable VSCode API integration when available
 	DebugMode        bool     // Enable debug logging
 	LogLevel         string   // Log level (info, debug, error)
@@ -78,29 +83,67 @@ func main() {
 	// Parse command line arguments
 	var (
 		cacheSize        = flag.String("cache-size", "100MB", "Memory cache limit (e.g., 50MB, 1GB)")
+		cacheStatCheck   = flag.Bool("cache-stat-check", true, "Stat files on cache hits and drop entries whose size or mtime changed on disk (disable for raw speed)")
+		cachePersist     = flag.Bool("cache-persist", false, "Save the cache to <backup-dir>/cache-snapshot.gob on shutdown and warm it from there on startup")
+		cachePersistMax  = flag.String("cache-persist-budget", "32MB", "Max file content bytes written to the cache snapshot (the index is always saved)")
+		cacheMaxFile     = flag.String("cache-max-file-size", "2MB", "Largest file cached in memory; bigger files are always read from disk")
+		cacheTTL         = flag.Duration("cache-ttl", 0, "Treat cached file entries older than this as misses (e.g. 30s; 0 = no per-entry TTL)")
+		negativeTTL      = flag.Duration("negative-cache-ttl", cache.DefaultNegativeTTL, "Remember 'not found' results for repeated lookups of a missing path this long (0 = off)")
+		neverCache       = flag.String("never-cache", "", "Comma-separated path patterns never cached (e.g. '*.log,logs/**')")
+		watch            = flag.Bool("watch", true, "Watch the allowed paths (fsnotify) and invalidate cached files changed by other programs")
+		maxWatchSnaps    = flag.Int("max-watch-snapshots", core.DefaultMaxWatchSnapshots, "Directories analyze_directory watch keeps a change-detection snapshot for (least recently used dropped first)")
+		maxArtifactSize  = flag.String("max-artifact-bytes", "64MB", "Total size of the server_info artifact slots (least recently used evicted first)")
+		persistArtifacts = flag.Bool("persist-artifacts", true, "Keep the server_info artifact slots in <backup-dir>/artifacts so they survive restarts")
+		artifactMaxAge   = flag.Duration("artifact-max-age", core.DefaultArtifactMaxAge, "Drop artifact slots captured longer ago than this")
 		parallelOps      = flag.Int("parallel-ops", config.ParallelOps, "Max concurrent operations")
-		binaryThreshold  = flag.String("binary-threshold", "1MB", "File size threshold for binary protocol")
+		binaryThreshold  = flag.String("binary-threshold", "1MB", "Files and content over this size are read as a head+tail view and written by the streaming writer (0 = off)")
 		vsCodeAPI        = flag.Bool("vscode-api", true, "Enable VSCode API integration when available")
 		debugMode        = flag.Bool("debug", false, "Enable debug mode")
 		logLevel         = flag.String("log-level", "info", "Log level (debug, info, warn, error)")
 		allowedPaths     = flag.String("allowed-paths", "", "Comma-separated list of allowed base paths for access control (alternative: pass paths as individual arguments)")
+		allowPathMgmt    = flag.Bool("allow-path-management", false, "Enable allowed_paths add and remove (change the allowed paths at runtime)")
+		allowedPathsFile = flag.String("allowed-paths-file", "", "JSON file keeping paths added with allowed_paths add across restarts (loaded at startup)")
+		protectedPaths   = flag.String("protected-paths", "", "Comma-separated write-protection rules: absolute paths/globs (e.g. '/project/vendor') or name patterns ('*.lock,migrations/**'); matching paths stay readable but cannot be changed")
+		resourceDepth    = flag.Int("resource-depth", core.DefaultResourceDepth, "Directory levels below each allowed path listed as MCP resources (file:// URIs, read through the cache and access control); 0 disables resources")
+		resourceExclude  = flag.String("resource-exclude", "", "Comma-separated globs left out of the resource listing, in addition to .git, node_modules, ... and .gitignore (e.g. 'dist/**,*.log')")
+		enableTools      = flag.String("enable-tools", "", "Comma-separated tool names or globs to register, all others are left out (e.g. 'read_file,edit_file,search_*'; default: all tools)")
+		disableTools     = flag.String("disable-tools", "", "Comma-separated tool names or globs not to register (e.g. 'delete_file,wsl*'); applied after --enable-tools")
 		compactMode      = flag.Bool("compact-mode", false, "Enable compact responses (minimal tokens for Claude Desktop)")
+		jsonResponses    = flag.Bool("json-responses", false, "Return JSON objects instead of prose from get_file_info, list_directory, server_info stats and config, edit_file, multi_edit, batch_operations and backup list (per call: format:\"text\"|\"json\")")
 		maxResponseSize  = flag.String("max-response-size", "10MB", "Maximum response size")
 		maxSearchResults = flag.Int("max-search-results", 1000, "Maximum search results to return")
 		maxListItems     = flag.Int("max-list-items", 500, "Maximum items in directory listings")
 		hooksEnabled     = flag.Bool("hooks-enabled", false, "Enable hooks system for pre/post operation validation and formatting")
 		hooksConfig      = flag.String("hooks-config", "", "Path to hooks configuration JSON file (e.g., hooks.json)")
+		secretScan       = flag.Bool("secret-scan", false, "Deny writes and edits whose content contains credentials (AWS keys, private keys, GitHub tokens, high-entropy secrets)")
+		secretScanAllow  = flag.String("secret-scan-allow", "", "Comma-separated path patterns the secret scan skips (e.g. 'testdata/**,*_test.go')")
+		pathMappings     = flag.String("path-mappings", "", "Comma-separated extra drive mappings for WSL path conversion (e.g. 'Z:=/mnt/share')")
 		version          = flag.Bool("version", false, "Show version information")
-		benchmark        = flag.Bool("bench", false, "Run performance benchmark")
+		benchmark        = flag.Bool("bench", false, "Run the benchmark on a generated temp workspace and exit")
+		benchFiles       = flag.Int("bench-files", 200, "Files in the --bench workspace")
+		benchSizes       = flag.String("bench-sizes", "1KB,16KB,256KB", "Comma-separated --bench file sizes, assigned round-robin")
+		benchIterations  = flag.Int("bench-iterations", 20, "Repeats of the workspace-wide --bench operations (list_directory, smart_search, search_and_replace)")
+		benchCompare     = flag.String("bench-compare-cache", "", "Second cache size --bench runs and compares with --cache-size (e.g. 16MB)")
+		benchJSON        = flag.String("bench-json", "", "Write the --bench results as JSON to this file (- = stdout)")
 
 		// Backup configuration
 		backupDir      = flag.String("backup-dir", "", "Directory for backup storage (default: temp/mcp-batch-backups)")
 		backupMaxAge   = flag.Int("backup-max-age", 7, "Max age of backups in days")
 		backupMaxCount = flag.Int("backup-max-count", 100, "Max number of backups to keep")
+		trashDir       = flag.String("trash-dir", "", "Preferred trash root for soft-deletes on its volume (default: <backup-dir>/trash, else <root>/.mcp-trash)")
+		trashMaxAge    = flag.Duration("trash-max-age", 0, "At startup, permanently delete soft-deleted files older than this, e.g. 720h (0 = keep)")
 
 		// Logging
 		logDir          = flag.String("log-dir", "", "Directory for audit logs and metrics snapshots (enables operation logging)")
 		normalizerRules = flag.String("normalizer-rules", "", "Path to external normalizer rules JSON file (extends built-in rules)")
+		persistOpReport = flag.Bool("persist-op-report", false, "Persist the server_info report history to <backup-dir>/operation-report.jsonl")
+		mutationLogDir  = flag.String("mutation-log-dir", "", "Directory for the append-only, hash-chained log of every mutating operation (enables server_info audit_log)")
+		mutationLogMax  = flag.Int("mutation-log-max-mb", 10, "Rotate the mutation log after this many MB")
+		opHistorySize   = flag.Int("op-history-size", core.DefaultOperationHistorySize, "Tool calls kept in memory for server_info history")
+		slowOpThreshold = flag.Duration("slow-op-threshold", 0, "Log tool calls that take at least this long (e.g. 2s) and count them in performance stats (0 = off)")
+		shutdownGrace   = flag.Duration("shutdown-grace", core.DefaultShutdownGrace, "On SIGINT/SIGTERM, wait this long for in-flight tool calls (cancelled, rolled back where a backup exists) before exiting")
+		metricsAddr     = flag.String("metrics-addr", "", "Serve Prometheus metrics at http://<addr>/metrics (e.g. :9090; off by default)")
+		persistMetrics  = flag.Bool("persist-metrics", false, "Persist operation counters and edit telemetry to <backup-dir>/metrics-state.json across restarts")
 
 		// Auto-OCC (new point 4): automatic optimistic-concurrency check on edits
 		// without an explicit expected_hash. off | warn (default) | block.
@@ -111,6 +154,10 @@ func main() {
 		riskThresholdHigh     = flag.Float64("risk-threshold-high", 75.0, "Percentage change threshold for high risk")
 		riskOccurrencesMedium = flag.Int("risk-occurrences-medium", 50, "Number of occurrences threshold for medium risk")
 		riskOccurrencesHigh   = flag.Int("risk-occurrences-high", 100, "Number of occurrences threshold for high risk")
+		confirmTokens         = flag.Bool("confirm-tokens", false, "Two-step confirmation: HIGH/CRITICAL edit_file/multi_edit calls return a one-time confirm_token instead of executing (force:true no longer bypasses); other tools are not risk-assessed")
+		confirmTokenTTL       = flag.Int("confirm-token-ttl", 10, "Minutes a confirmation token stays valid (with --confirm-tokens)")
+		writeSessionTTL       = flag.Duration("write-session-ttl", core.DefaultWriteSessionTTL, "How long a chunked write_file session may sit idle before it is discarded")
+		criticalFiles         = flag.String("critical-files", "", "Comma-separated extra critical file patterns (e.g. 'schema.prisma,deploy/**'); edits to them are bumped one risk level")
 	)
 	flag.Parse()
 
@@ -156,6 +203,15 @@ func main() {
 		config.MaxResponseSize = size
 	}
 
+	// Drive mappings must be in place before any path is converted
+	if *pathMappings != "" {
+		mappings, err := core.ParsePathMappings(*pathMappings)
+		if err != nil {
+			log.Fatalf("Invalid path mappings: %v", err)
+		}
+		core.SetPathMappings(mappings)
+	}
+
 	// Parse allowed paths - support both formats:
 	// 1. Single --allowed-paths flag with comma-separated values
 	// 2. Multiple individual path arguments after all flags
@@ -182,10 +238,40 @@ func main() {
 		formatSize(config.BinaryThreshold), config.VSCodeAPIEnabled, config.CompactMode)
 
 	if *benchmark {
-		runBenchmark(config)
+		opts := benchOptions{Files: *benchFiles, Iterations: *benchIterations, JSONPath: *benchJSON}
+		for _, sz := range splitCommaList(*benchSizes) {
+			n, err := parseSize(sz)
+			if err != nil {
+				log.Fatalf("Invalid --bench-sizes: %v", err)
+			}
+			opts.Sizes = append(opts.Sizes, n)
+		}
+		if *benchCompare != "" {
+			n, err := parseSize(*benchCompare)
+			if err != nil {
+				log.Fatalf("Invalid --bench-compare-cache: %v", err)
+			}
+			opts.CompareCache = n
+		}
+		if err := runBenchmark(os.Stdout, config, opts); err != nil {
+			log.Fatalf("Benchmark failed: %v", err)
+		}
 		return
 	}
 
+	cachePersistBudget, err := parseSize(*cachePersistMax)
+	if err != nil {
+		log.Fatalf("Invalid cache persist budget: %v", err)
+	}
+	cacheMaxFileSize, err := parseSize(*cacheMaxFile)
+	if err != nil {
+		log.Fatalf("Invalid cache max file size: %v", err)
+	}
+	maxArtifactBytes, err := parseSize(*maxArtifactSize)
+	if err != nil {
+		log.Fatalf("Invalid max artifact bytes: %v", err)
+	}
+
 	// Initialize components
 	ctx := context.Background()
 
@@ -195,6 +281,7 @@ func main() {
 		log.Fatalf("Failed to initialize cache: %v", err)
 	}
 	defer cacheSystem.Close()
+	cacheSystem.SetStatValidation(*cacheStatCheck)
 
 	// Initialize core engine
 	engine, err := core.NewUltraFastEngine(&core.Config{
@@ -205,26 +292,70 @@ func main() {
 		AllowedPaths:     config.AllowedPaths,
 		BinaryThreshold:  config.BinaryThreshold,
 		CompactMode:      config.CompactMode,
+		JSONResponses:    *jsonResponses,
 		MaxResponseSize:  config.MaxResponseSize,
 		MaxSearchResults: config.MaxSearchResults,
 		MaxListItems:     config.MaxListItems,
 		HooksEnabled:     *hooksEnabled,
 		HooksConfigPath:  *hooksConfig,
+		SecretScan:       *secretScan,
+		SecretScanAllow:  splitCommaList(*secretScanAllow),
+
+		// Runtime allowed-path management
+		AllowPathManagement: *allowPathMgmt,
+		AllowedPathsFile:    *allowedPathsFile,
+		ProtectedPaths:      splitCommaList(*protectedPaths),
+
+		// MCP resources
+		ResourceDepth:    *resourceDepth,
+		ResourceExcludes: splitCommaList(*resourceExclude),
+
+		// Tool selection
+		EnableTools:  splitCommaList(*enableTools),
+		DisableTools: splitCommaList(*disableTools),
 
 		// Backup configuration
 		BackupDir:      *backupDir,
 		BackupMaxAge:   *backupMaxAge,
 		BackupMaxCount: *backupMaxCount,
+		TrashMaxAge:    *trashMaxAge,
+		TrashDir:       *trashDir,
 
 		// Logging
 		LogDir:              *logDir,
 		NormalizerRulesPath: *normalizerRules,
 
+		// Operation report
+		PersistOperationReport: *persistOpReport,
+		PersistMetrics:         *persistMetrics,
+		MutationLogDir:         *mutationLogDir,
+		MutationLogMaxSize:     int64(*mutationLogMax) * 1024 * 1024,
+		MetricsAddr:            *metricsAddr,
+		SlowOpThreshold:        *slowOpThreshold,
+		OperationHistorySize:   *opHistorySize,
+
+		// Cache snapshot
+		PersistCache:       *cachePersist,
+		CachePersistBudget: cachePersistBudget,
+		CacheMaxFileSize:   cacheMaxFileSize,
+		CacheFileTTL:       *cacheTTL,
+		NeverCachePatterns: splitCommaList(*neverCache),
+		NegativeCacheTTL:   *negativeTTL,
+		Watch:              *watch,
+		MaxWatchSnapshots:  *maxWatchSnaps,
+		MaxArtifactBytes:   maxArtifactBytes,
+		PersistArtifacts:   *persistArtifacts,
+		ArtifactMaxAge:     *artifactMaxAge,
+
 		// Risk thresholds
 		RiskThresholdMedium:   *riskThresholdMedium,
 		RiskThresholdHigh:     *riskThresholdHigh,
 		RiskOccurrencesMedium: *riskOccurrencesMedium,
 		RiskOccurrencesHigh:   *riskOccurrencesHigh,
+		CriticalFiles:         splitCommaList(*criticalFiles),
+		ConfirmTokens:         *confirmTokens,
+		ConfirmTokenTTL:       time.Duration(*confirmTokenTTL) * time.Minute,
+		WriteSessionTTL:       *writeSessionTTL,
 	})
 	if err != nil {
 		log.Fatalf("Failed to initialize engine: %v", err)
@@ -236,6 +367,7 @@ func main() {
 		"filesystem-ultra",
 		serverVersion,
 		server.WithToolCapabilities(true), // listChanged=true enables tools/list_changed notifications
+		server.WithResourceCapabilities(true, true),
 		server.WithLogging(),
 		server.WithInstructions(serverInstructions),
 	)
@@ -244,9 +376,11 @@ func main() {
 	if err := registerTools(s, engine); err != nil {
 		log.Fatalf("Failed to register tools: %v", err)
 	}
+	registerResources(s, engine)
 
-	// Setup graceful shutdown
-	ctx, cancel := context.WithCancel(ctx)
+	// Setup graceful shutdown: SIGINT/SIGTERM cancel ctx, which cancels the
+	// tool calls in flight
+	ctx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
 	// Start performance monitoring
@@ -254,8 +388,31 @@ func main() {
 
 	log.Printf("Server ready - Waiting for connections...")
 
-	// Start the stdio server using new API
-	if err := server.ServeStdio(s); err != nil {
-		log.Fatalf("Server error: %v", err)
+	// Serve stdio until the client closes stdin or a signal arrives
+	served := make(chan error, 1)
+	go func() {
+		served <- server.NewStdioServer(s).Listen(ctx, os.Stdin, os.Stdout)
+	}()
+	var serveErr error
+	select {
+	case serveErr = <-served:
+	case <-ctx.Done():
+		log.Printf("Shutdown signal received - draining in-flight operations (up to %s)", *shutdownGrace)
+	}
+	cancel()
+
+	// Wait for the cancelled calls, then the deferred Close calls flush the
+	// autosync queue, telemetry, logs and the cache snapshot
+	report := engine.Drain(*shutdownGrace)
+	if len(report.InFlight) > 0 {
+		log.Printf("Shutdown: %d in-flight tool calls, %d finished within %s, %d interrupted",
+			len(report.InFlight), len(report.InFlight)-len(report.Unfinished),
+			report.Waited.Round(time.Millisecond), len(report.Unfinished))
+	}
+	if serveErr != nil && !errors.Is(serveErr, context.Canceled) {
+		// log.Fatalf skips the deferred Close calls
+		engine.Close()
+		cacheSystem.Close()
+		log.Fatalf("Server error: %v", serveErr)
 	}
 }
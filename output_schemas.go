@@ -13,7 +13,9 @@
   "type": "object",
   "properties": {
     "content": {"type": "string", "description": "File body (possibly truncated; truncation is annotated inline)"},
-    "content_hash": {"type": "string", "description": "FNV-1a 8-hex hash of the FULL file on disk. Pass as expected_hash on a subsequent edit_file/multi_edit to detect concurrent external changes (OCC). Absent on multi-file reads."}
+    "content_hash": {"type": "string", "description": "FNV-1a 8-hex hash of the FULL file on disk. Pass as expected_hash on a subsequent edit_file/multi_edit to detect concurrent external changes (OCC). Absent on multi-file reads."},
+    "continuation_token": {"type": "string", "description": "Present when the body exceeded the server's max response size: content holds the first chunk; pass the token to fetch_continuation for the rest"},
+    "total_size": {"type": "integer", "description": "Full body size in bytes, present with continuation_token"}
   },
   "required": ["content"]
 }`)
@@ -27,6 +29,8 @@
     "content_hash": {"type": "string", "description": "FNV-1a 8-hex hash computed from the reopened host file. Pass as expected_hash on a subsequent edit_file/multi_edit to chain operations without re-reading."},
     "backup_id": {"type": "string", "description": "Present when a safety backup was auto-created (adaptive guard). Full ID for backup(action:'restore') or backup(action:'undo_last')."},
     "feedback": {"type": "string", "description": "Non-blocking warning (truncation/inflation/rewrite heuristics or failed post-write read-back)"},
+    "session_id": {"type": "string", "description": "Chunked write session (session:begin|append|commit); before the commit bytes_written counts the bytes received and verified is false"},
+    "chunks": {"type": "integer", "description": "Chunks received by the write session"},
     "message": {"type": "string", "description": "Human-readable summary, identical to the text content block"}
   },
   "required": ["path", "bytes_written", "verified", "message"]
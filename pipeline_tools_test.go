@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunPipeline_ExecutesAndFormats(t *testing.T) {
+	dir := t.TempDir()
+	s, _ := newIncidentFixServer(t, dir)
+	target := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(target, []byte("old old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	requestJSON := `{"name": "swap", "steps": [
+		{"id": "count", "action": "count_occurrences", "params": {"pattern": "old", "files": ["` + filepath.ToSlash(target) + `"]}},
+		{"id": "fix", "action": "edit", "input_from": "count", "params": {"old_text": "old", "new_text": "new"}}
+	]}`
+	result := callServer(t, s, "pipeline", map[string]any{"action": "run", "request_json": requestJSON, "compact": false})
+	text := textFromResult(t, result)
+	if result.IsError {
+		t.Fatalf("pipeline run failed: %s", text)
+	}
+	for _, want := range []string{"Pipeline: swap", "1. count [count_occurrences] OK", "Counts: 2 total occurrences", "Total edits: 2"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("output missing %q:\n%s", want, text)
+		}
+	}
+	if got, _ := os.ReadFile(target); string(got) != "new new\n" {
+		t.Errorf("content = %q", got)
+	}
+
+	countOnly := `{"name": "count", "steps": [
+		{"id": "count", "action": "count_occurrences", "params": {"pattern": "new", "files": ["` + filepath.ToSlash(target) + `"]}}
+	]}`
+	compact := textFromResult(t, callServer(t, s, "pipeline", map[string]any{"action": "run", "request_json": countOnly, "compact": true}))
+	if !strings.HasPrefix(compact, "OK: 1/1 steps") || strings.Contains(compact, "\n") {
+		t.Errorf("compact output = %q", compact)
+	}
+
+	bad := callServer(t, s, "pipeline", map[string]any{"action": "run", "request_json": "{not json"})
+	if !bad.IsError || !strings.Contains(textFromResult(t, bad), "Invalid pipeline JSON") {
+		t.Errorf("malformed JSON should be a tool error: %s", textFromResult(t, bad))
+	}
+}
+
+func TestLoadPipeline_ReadsFromAllowedPaths(t *testing.T) {
+	dir := t.TempDir()
+	s, _ := newIncidentFixServer(t, dir)
+	target := filepath.Join(dir, "b.txt")
+	os.WriteFile(target, []byte("alpha\n"), 0644)
+
+	pipelinePath := filepath.Join(dir, "pipelines", "upper.json")
+	os.MkdirAll(filepath.Dir(pipelinePath), 0755)
+	os.WriteFile(pipelinePath, []byte(`{"name": "upper", "steps": [
+		{"id": "fix", "action": "edit", "params": {"old_text": "alpha", "new_text": "ALPHA", "files": ["`+filepath.ToSlash(target)+`"]}}
+	]}`), 0644)
+
+	// dry_run override leaves the file untouched
+	preview := callServer(t, s, "pipeline", map[string]any{"action": "load", "path": pipelinePath, "dry_run": true, "compact": false})
+	if text := textFromResult(t, preview); preview.IsError || !strings.Contains(text, "DRY RUN") {
+		t.Fatalf("dry run output: %s", text)
+	}
+	if got, _ := os.ReadFile(target); string(got) != "alpha\n" {
+		t.Fatalf("dry run modified the file: %q", got)
+	}
+
+	if result := callServer(t, s, "pipeline", map[string]any{"action": "load", "path": pipelinePath}); result.IsError {
+		t.Fatalf("pipeline load failed: %s", textFromResult(t, result))
+	}
+	if got, _ := os.ReadFile(target); string(got) != "ALPHA\n" {
+		t.Errorf("content = %q", got)
+	}
+
+	outside := filepath.Join(t.TempDir(), "evil.json")
+	os.WriteFile(outside, []byte(`{"name": "x", "steps": []}`), 0644)
+	if result := callServer(t, s, "pipeline", map[string]any{"action": "load", "path": outside}); !result.IsError {
+		t.Errorf("pipeline outside allowed paths was loaded: %s", textFromResult(t, result))
+	}
+}
+
+func TestPipelineStatus_ListsRecentRuns(t *testing.T) {
+	dir := t.TempDir()
+	s, _ := newIncidentFixServer(t, dir)
+
+	empty := textFromResult(t, callServer(t, s, "pipeline", map[string]any{"action": "status"}))
+	if !strings.Contains(empty, "No pipelines") {
+		t.Errorf("empty status = %q", empty)
+	}
+
+	target := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(target, []byte("old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	requestJSON := `{"name": "tally", "steps": [
+		{"id": "count", "action": "count_occurrences", "params": {"pattern": "old", "files": ["` + filepath.ToSlash(target) + `"]}}
+	]}`
+	callServer(t, s, "pipeline", map[string]any{"action": "run", "request_json": requestJSON})
+
+	text := textFromResult(t, callServer(t, s, "pipeline", map[string]any{"action": "status"}))
+	for _, want := range []string{"pl_1 tally COMPLETED: 1/1 steps", "step 1 count (count_occurrences) 1/1 files"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("status missing %q:\n%s", want, text)
+		}
+	}
+	missing := callServer(t, s, "pipeline", map[string]any{"action": "status", "id": "pl_99"})
+	if !missing.IsError {
+		t.Errorf("unknown id should be a tool error: %s", textFromResult(t, missing))
+	}
+}
+
+func TestRunPipeline_JSONOutput(t *testing.T) {
+	dir := t.TempDir()
+	s, _ := newIncidentFixServer(t, dir)
+	target := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(target, []byte("old old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	requestJSON := `{"name": "swap", "output": "json", "verbose": true, "steps": [
+		{"id": "fix", "action": "edit", "params": {"old_text": "old", "new_text": "new", "files": ["` + filepath.ToSlash(target) + `"]}}
+	]}`
+	result := callServer(t, s, "pipeline", map[string]any{"action": "run", "request_json": requestJSON})
+	text := textFromResult(t, result)
+	if result.IsError {
+		t.Fatalf("pipeline run failed: %s", text)
+	}
+	var decoded struct {
+		Name       string `json:"name"`
+		Success    bool   `json:"success"`
+		TotalEdits int    `json:"total_edits"`
+		Results    []struct {
+			StepID string            `json:"step_id"`
+			Counts map[string]int    `json:"counts"`
+			Diffs  map[string]string `json:"diffs"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal([]byte(text), &decoded); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, text)
+	}
+	if decoded.Name != "swap" || !decoded.Success || decoded.TotalEdits != 2 || len(decoded.Results) != 1 {
+		t.Errorf("decoded = %+v", decoded)
+	}
+	for path, diff := range decoded.Results[0].Diffs {
+		if !strings.Contains(diff, "+new new") {
+			t.Errorf("diff for %s = %q", path, diff)
+		}
+	}
+	if len(decoded.Results[0].Diffs) != 1 {
+		t.Errorf("expected one verbose diff, got %v", decoded.Results[0].Diffs)
+	}
+
+	bad := callServer(t, s, "pipeline", map[string]any{"action": "run", "request_json": `{"name": "x", "output": "yaml", "steps": [{"id": "a", "action": "search", "params": {"pattern": "x"}}]}`})
+	if !bad.IsError || !strings.Contains(textFromResult(t, bad), "unsupported output") {
+		t.Errorf("unknown output should fail validation: %s", textFromResult(t, bad))
+	}
+}
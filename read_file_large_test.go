@@ -0,0 +1,87 @@
+package main
+
+// read_file on files over --binary-threshold: summarized head+tail view,
+// streamed range reads, and the same content_hash a full read would give.
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mcp/filesystem-ultra/cache"
+	"github.com/mcp/filesystem-ultra/core"
+)
+
+func buildThresholdRegistry(t *testing.T, allowedDir string, threshold int64) *toolRegistry {
+	t.Helper()
+	cacheInstance, err := cache.NewIntelligentCache(4 * 1024 * 1024)
+	if err != nil {
+		t.Fatalf("cache: %v", err)
+	}
+	engine, err := core.NewUltraFastEngine(&core.Config{
+		Cache:           cacheInstance,
+		AllowedPaths:    []string{allowedDir},
+		ParallelOps:     2,
+		BinaryThreshold: threshold,
+	})
+	if err != nil {
+		t.Fatalf("engine: %v", err)
+	}
+	t.Cleanup(func() { engine.Close() })
+	reg := &toolRegistry{
+		server:         server.NewMCPServer("test", "0.0.0"),
+		engine:         engine,
+		handlers:       make(map[string]toolHandler),
+		regexTransform: core.NewRegexTransformer(engine),
+	}
+	registerCoreTools(reg)
+	return reg
+}
+
+func TestReadFileHandler_BinaryThresholdBoundary(t *testing.T) {
+	dir := t.TempDir()
+	const threshold = 8192
+	reg := buildThresholdRegistry(t, dir, threshold)
+
+	var b strings.Builder
+	for i := 1; b.Len() <= threshold; i++ {
+		fmt.Fprintf(&b, "row %d\n", i)
+	}
+	for _, size := range []int{threshold, threshold + 1} {
+		path := filepath.Join(dir, fmt.Sprintf("data%d.csv", size))
+		content := b.String()[:size]
+		os.WriteFile(path, []byte(content), 0644)
+		wantHash, _ := computeFileOCCHash(path)
+
+		result := callReadFile(t, reg, map[string]interface{}{"path": path})
+		if result.IsError {
+			t.Fatalf("size %d: %v", size, result.Content)
+		}
+		text := resultText(t, result)
+		summarized := strings.HasPrefix(text, "[Large file: ")
+		if summarized != (size > threshold) {
+			t.Fatalf("size %d: summarized = %v:\n%s", size, summarized, text[:200])
+		}
+		if summarized && (!strings.Contains(text, "\nrow 1\nrow 2\n") || !strings.Contains(text, "lines omitted")) {
+			t.Fatalf("size %d: view missing head or gap:\n%s", size, text)
+		}
+		if got := result.StructuredContent.(map[string]any)["content_hash"]; got != wantHash {
+			t.Fatalf("size %d: content_hash %v, want %s", size, got, wantHash)
+		}
+
+		// mode/max_lines choose the view's ends
+		result = callReadFile(t, reg, map[string]interface{}{"path": path, "mode": "tail", "max_lines": float64(2)})
+		if text := resultText(t, result); size > threshold && strings.Contains(text, "\nrow 1\n") {
+			t.Fatalf("size %d: tail view shows the head:\n%s", size, text)
+		}
+
+		// Range reads of a file over the threshold go through the line index
+		result = callReadFile(t, reg, map[string]interface{}{"path": path, "start_line": float64(3), "end_line": float64(4)})
+		if text := resultText(t, result); !strings.HasPrefix(text, "row 3\nrow 4") {
+			t.Fatalf("size %d: range read %q", size, text)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mcp/filesystem-ultra/core"
+)
+
+// registerResources exposes the files under the allowed paths as MCP
+// resources (see core/resources.go). The listing is built at startup and kept
+// current from the engine's change notifications; any other allowed file can
+// still be read through the file:///{+path} template. No-op when
+// --resource-depth is 0.
+func registerResources(s *server.MCPServer, engine *core.UltraFastEngine) {
+	if engine.GetConfig().ResourceDepth <= 0 {
+		return
+	}
+	read := func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return readResource(ctx, engine, request.Params.URI)
+	}
+
+	files := engine.ResourceFiles()
+	resources := make([]server.ServerResource, 0, len(files))
+	for _, f := range files {
+		resources = append(resources, server.ServerResource{Resource: fileResource(f), Handler: read})
+	}
+	s.SetResources(resources...)
+	s.AddResourceTemplate(mcp.NewResourceTemplate("file:///{+path}", "file",
+		mcp.WithTemplateDescription("Any file inside the allowed paths, by absolute path (file:///home/me/project/main.go, file:///C:/project/main.go)"),
+	), read)
+
+	engine.OnPathChanged(func(path string) {
+		resourceChanged(s, engine, read, path)
+	})
+}
+
+// fileResource is the listing entry of a file
+func fileResource(f core.ResourceFile) mcp.Resource {
+	return mcp.NewResource(f.URI, f.Path,
+		mcp.WithResourceTitle(filepath.Base(f.Path)),
+		mcp.WithMIMEType(f.MIMEType),
+		mcp.WithResourceSize(f.Size),
+	)
+}
+
+// readResource serves a resource through ReadFileContent (access control,
+// hooks, cache). Content that is not UTF-8 is returned as a base64 blob.
+func readResource(ctx context.Context, engine *core.UltraFastEngine, uri string) ([]mcp.ResourceContents, error) {
+	path, err := core.ResourcePath(uri)
+	if err != nil {
+		return nil, err
+	}
+	content, err := engine.ReadFileContent(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	mimeType := core.ResourceMIMEType(path)
+	if utf8.ValidString(content) {
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: uri, MIMEType: mimeType, Text: content}}, nil
+	}
+	if strings.HasPrefix(mimeType, "text/") {
+		mimeType = "application/octet-stream"
+	}
+	return []mcp.ResourceContents{mcp.BlobResourceContents{URI: uri, MIMEType: mimeType,
+		Blob: base64.StdEncoding.EncodeToString([]byte(content))}}, nil
+}
+
+// resourceChanged updates the listing after the engine changed path: a new
+// listed file is added, a changed one gets notifications/resources/updated,
+// and a path that is gone (or no longer listed) is removed together with
+// everything below it. Adding and removing send list_changed.
+func resourceChanged(s *server.MCPServer, engine *core.UltraFastEngine, read server.ResourceHandlerFunc, path string) {
+	uri := core.ResourceURI(path)
+	listed := s.ListResources()
+	if f, ok := engine.ResourceFor(path); ok {
+		if _, known := listed[uri]; !known {
+			s.AddResource(fileResource(f), read)
+		}
+		s.SendNotificationToAllClients(mcp.MethodNotificationResourceUpdated, map[string]any{"uri": uri})
+		return
+	}
+	var gone []string
+	for u := range listed {
+		if u == uri || strings.HasPrefix(u, uri+"/") {
+			gone = append(gone, u)
+		}
+	}
+	if len(gone) > 0 {
+		s.DeleteResources(gone...)
+	}
+}
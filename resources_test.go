@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mcp/filesystem-ultra/cache"
+	"github.com/mcp/filesystem-ultra/core"
+)
+
+func TestResources_ListReadAndUpdate(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("alpha\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "b.bin"), []byte{0xff, 0x00, 0xfe}, 0644)
+	c, err := cache.NewIntelligentCache(1024 * 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine, err := core.NewUltraFastEngine(&core.Config{Cache: c, AllowedPaths: []string{dir}, ParallelOps: 2,
+		BackupDir: t.TempDir(), ResourceDepth: core.DefaultResourceDepth})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { engine.Close() })
+	s := server.NewMCPServer("test", "0.0.0", server.WithResourceCapabilities(true, true))
+	registerResources(s, engine)
+
+	a := core.ResourceURI(filepath.Join(dir, "a.txt"))
+	listed := s.ListResources()
+	if len(listed) != 2 || listed[a] == nil || listed[a].Resource.MIMEType != "text/plain; charset=utf-8" {
+		t.Fatalf("listed resources = %v", listed)
+	}
+
+	ctx := context.Background()
+	contents, err := listed[a].Handler(ctx, mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: a}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text, ok := contents[0].(mcp.TextResourceContents); !ok || text.Text != "alpha\n" {
+		t.Errorf("a.txt contents = %#v", contents[0])
+	}
+	b := core.ResourceURI(filepath.Join(dir, "b.bin"))
+	if contents, err := readResource(ctx, engine, b); err != nil {
+		t.Fatal(err)
+	} else if blob, ok := contents[0].(mcp.BlobResourceContents); !ok || blob.Blob != "/wD+" {
+		t.Errorf("b.bin contents = %#v", contents[0])
+	}
+	if _, err := readResource(ctx, engine, core.ResourceURI(filepath.Join(t.TempDir(), "x"))); err == nil {
+		t.Error("read outside the allowed paths succeeded")
+	}
+
+	// writes add new files, deletes remove them
+	created := filepath.Join(dir, "sub", "c.go")
+	if err := engine.WriteFileContent(ctx, created, "package sub\n"); err != nil {
+		t.Fatal(err)
+	}
+	if s.ListResources()[core.ResourceURI(created)] == nil {
+		t.Error("new file not listed")
+	}
+	if err := engine.DeleteFile(ctx, filepath.Join(dir, "sub")); err != nil {
+		t.Fatal(err)
+	}
+	if s.ListResources()[core.ResourceURI(created)] != nil {
+		t.Error("deleted file still listed")
+	}
+}
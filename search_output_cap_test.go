@@ -82,3 +82,29 @@ func TestCapSearchOutput_ExactBoundary(t *testing.T) {
 		t.Error("101 bytes should trigger truncation at 100-byte cap")
 	}
 }
+
+// TestCapSearchOutput_Continuation: the cut-off remainder is available
+// through fetch_continuation.
+func TestCapSearchOutput_Continuation(t *testing.T) {
+	engine := newEngineWithCap(t, 1024)
+	big := strings.Repeat("match line\n", 300)
+	got := capSearchOutput(big, engine)
+	_, token, ok := strings.Cut(got, "fetch_continuation(token:\"")
+	if !ok {
+		t.Fatalf("no continuation token in marker:\n%s", got[len(got)-300:])
+	}
+	token, _, _ = strings.Cut(token, "\"")
+	first, _, _ := strings.Cut(got, "\n\n⚠️ truncated")
+	rest := first
+	for token != "" {
+		c, err := engine.FetchContinuation(token, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rest += c.Content
+		token = c.ContinuationToken
+	}
+	if rest != big {
+		t.Errorf("reassembled %d bytes, want %d", len(rest), len(big))
+	}
+}
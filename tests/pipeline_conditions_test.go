@@ -2,8 +2,10 @@
 
 import (
 	"context"
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/mcp/filesystem-ultra/core"
@@ -300,3 +302,151 @@ func TestCondition_PipelineIntegration(t *testing.T) {
 		t.Fatalf("file should not have changed: %s", string(content2))
 	}
 }
+
+func TestCondition_Expr(t *testing.T) {
+	pCtx := core.NewPipelineContext()
+	pCtx.SetStepResult("count", &core.StepResult{
+		Success:      true,
+		FilesMatched: []string{"a.go", "b.go"},
+		Counts:       map[string]int{"a.go": 3, "b.go": 0},
+	})
+	pCtx.SetStepResult("find", &core.StepResult{Success: true, FilesMatched: []string{"a.go"}, RiskLevel: "LOW"})
+	pCtx.SetStepResult("maybe", &core.StepResult{Success: true, Skipped: true})
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"steps.count.total > 0", true},
+		{"steps.count.count == 3", true},
+		{"len(steps.find.files) < 20", true},
+		{"steps.count.total > 0 && len(steps.find.files) >= 2", false},
+		{"steps.count.total > 5 || steps.find.risk == 'LOW'", true},
+		{"!(steps.find.success)", false},
+		{"steps.maybe.skipped && steps.maybe.total == 0", true},
+		{"steps.find.files", true},
+		{"len(steps.maybe.files) != 0", false},
+	}
+	for _, tc := range tests {
+		cond := &core.StepCondition{Type: "expr", Expr: tc.expr}
+		got, reason := core.EvaluateCondition(cond, pCtx, nil)
+		if got != tc.want {
+			t.Errorf("%q = %v (reason %q), want %v", tc.expr, got, reason, tc.want)
+		}
+	}
+
+	// Type mismatch is reported as a reason, not a panic
+	cond := &core.StepCondition{Type: "expr", Expr: "steps.find.risk > 1"}
+	if got, reason := core.EvaluateCondition(cond, pCtx, nil); got || reason == "" {
+		t.Errorf("mismatched comparison = %v (reason %q), want false with reason", got, reason)
+	}
+}
+
+func TestCondition_ExprUnmarshalString(t *testing.T) {
+	var step core.PipelineStep
+	data := `{"id": "fix", "action": "edit", "condition": "steps.count.total > 0", "params": {}}`
+	if err := json.Unmarshal([]byte(data), &step); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if step.Condition == nil || step.Condition.Type != "expr" || step.Condition.Expr != "steps.count.total > 0" {
+		t.Fatalf("condition = %+v, want expr", step.Condition)
+	}
+
+	var objStep core.PipelineStep
+	if err := json.Unmarshal([]byte(`{"id": "x", "action": "edit", "condition": {"expr": "steps.a.edits == 0"}}`), &objStep); err != nil {
+		t.Fatalf("unmarshal object: %v", err)
+	}
+	if objStep.Condition.Type != "expr" {
+		t.Errorf("object with expr only: type = %q, want expr", objStep.Condition.Type)
+	}
+}
+
+func TestCondition_ExprValidation(t *testing.T) {
+	// Steps validated so far, including the current one ("check" at index 1)
+	priorSteps := map[string]int{"find": 0, "check": 1}
+
+	if err := core.ValidateCondition(&core.StepCondition{Type: "expr", Expr: "steps.find.total > 0"}, "check", priorSteps); err != nil {
+		t.Fatalf("valid expression rejected: %v", err)
+	}
+
+	bad := map[string]string{
+		"later step":    "steps.later.total > 0",
+		"self":          "steps.check.success",
+		"syntax":        "steps.find.total >",
+		"unknown field": "steps.find.bogus == 1",
+		"bare ident":    "total > 0",
+		"empty":         "  ",
+	}
+	for name, expr := range bad {
+		t.Run(name, func(t *testing.T) {
+			if err := core.ValidateCondition(&core.StepCondition{Type: "expr", Expr: expr}, "check", priorSteps); err == nil {
+				t.Fatalf("expected validation error for %q", expr)
+			}
+		})
+	}
+
+	// A pipeline whose condition reads a later step is rejected up front
+	var request core.PipelineRequest
+	pipelineJSON := `{"name": "fwd", "steps": [
+		{"id": "fix", "action": "edit", "condition": "steps.count.total > 0",
+		 "params": {"old_text": "a", "new_text": "b", "files": ["a.txt"]}},
+		{"id": "count", "action": "count_occurrences", "params": {"pattern": "a", "files": ["a.txt"]}}
+	]}`
+	if err := json.Unmarshal([]byte(pipelineJSON), &request); err != nil {
+		t.Fatal(err)
+	}
+	if err := request.Validate(); err == nil || !strings.Contains(err.Error(), "not defined before") {
+		t.Fatalf("expected forward-reference error, got %v", err)
+	}
+}
+
+func TestCondition_ExprPipelineSkipsAndInputFromSkippedIsEmpty(t *testing.T) {
+	tmpDir := t.TempDir()
+	engine := createTestEngineWithPath(t, tmpDir)
+
+	testFile := filepath.Join(tmpDir, "expr.txt")
+	os.WriteFile(testFile, []byte("alpha beta"), 0644)
+
+	pipelineJSON := `{"name": "expr-skip", "stop_on_error": true, "steps": [
+		{"id": "count", "action": "count_occurrences", "params": {"pattern": "gamma", "files": ["` + filepath.ToSlash(testFile) + `"]}},
+		{"id": "fix", "action": "edit", "condition": "steps.count.total > 0",
+		 "params": {"old_text": "alpha", "new_text": "ALPHA", "files": ["` + filepath.ToSlash(testFile) + `"]}},
+		{"id": "after", "action": "edit", "input_from": "fix", "params": {"old_text": "beta", "new_text": "BETA"}}
+	]}`
+	var request core.PipelineRequest
+	if err := json.Unmarshal([]byte(pipelineJSON), &request); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := core.NewPipelineExecutor(engine).Execute(context.Background(), request)
+	if err != nil {
+		t.Fatalf("pipeline failed: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("expected success, got %+v", result.Results)
+	}
+	fix := result.Results[1]
+	if !fix.Skipped || !fix.Success || !strings.Contains(fix.SkipReason, "steps.count.total > 0") {
+		t.Fatalf("fix step = %+v, want skipped with reason", fix)
+	}
+	after := result.Results[2]
+	if after.Skipped || !after.Success || len(after.FilesMatched) != 0 || after.EditsApplied != 0 {
+		t.Fatalf("step reading a skipped step should run on no files, got %+v", after)
+	}
+	if content, _ := os.ReadFile(testFile); string(content) != "alpha beta" {
+		t.Fatalf("file changed: %q", content)
+	}
+}
+
+func TestCondition_ExprReferencesAreSchedulerDependencies(t *testing.T) {
+	steps := []core.PipelineStep{
+		{ID: "a", Action: "search", Params: map[string]interface{}{"pattern": "x"}},
+		{ID: "b", Action: "search", Params: map[string]interface{}{"pattern": "y"}},
+		{ID: "c", Action: "search", Params: map[string]interface{}{"pattern": "z"},
+			Condition: &core.StepCondition{Type: "expr", Expr: "steps.a.total > 0 && steps.b.total > 0"}},
+	}
+	deps := core.NewPipelineScheduler().GetDependencies(steps)
+	if got := deps["c"]; len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Fatalf("deps[c] = %v, want [a b]", got)
+	}
+}
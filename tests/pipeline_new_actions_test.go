@@ -266,3 +266,359 @@ func TestPipeline_ParallelExecution(t *testing.T) {
 		t.Fatalf("expected 2 merged files, got %d: %v", len(mergeResult.FilesMatched), mergeResult.FilesMatched)
 	}
 }
+
+func TestPipeline_WriteThenPostProcess(t *testing.T) {
+	tmpDir := t.TempDir()
+	engine := createTestEngineWithPath(t, tmpDir)
+	executor := core.NewPipelineExecutor(engine)
+
+	target := filepath.Join(tmpDir, "gen", "report.txt")
+	request := core.PipelineRequest{
+		Name:        "generate",
+		StopOnError: true,
+		Variables:   map[string]string{"title": "Weekly"},
+		Steps: []core.PipelineStep{
+			{ID: "gen", Action: "write", Params: map[string]interface{}{
+				"path":    target,
+				"content": "${title} report for ${pipeline.name}\nTODO\n",
+			}},
+			{ID: "fill", Action: "edit", InputFrom: "gen", Params: map[string]interface{}{
+				"old_text": "TODO",
+				"new_text": "done",
+			}},
+		},
+	}
+
+	// dry_run reports the size without creating the file
+	request.DryRun = true
+	result, err := executor.Execute(context.Background(), request)
+	if err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	if got := result.Results[0].BytesWritten; got != int64(len("Weekly report for generate\nTODO\n")) {
+		t.Errorf("dry run BytesWritten = %d", got)
+	}
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Fatalf("dry run created the file (err=%v)", err)
+	}
+
+	request.DryRun = false
+	result, err = executor.Execute(context.Background(), request)
+	if err != nil || !result.Success {
+		t.Fatalf("pipeline failed: %v %+v", err, result.Results)
+	}
+	content, _ := os.ReadFile(target)
+	if string(content) != "Weekly report for generate\ndone\n" {
+		t.Fatalf("content = %q", content)
+	}
+	if result.BackupID != "" {
+		t.Errorf("creating a new file should not need a backup, got %s", result.BackupID)
+	}
+}
+
+func TestPipeline_WriteOverExistingIsBackedUp(t *testing.T) {
+	tmpDir := t.TempDir()
+	engine := createTestEngineWithPath(t, tmpDir)
+
+	target := filepath.Join(tmpDir, "existing.txt")
+	os.WriteFile(target, []byte("original"), 0644)
+
+	result, err := core.NewPipelineExecutor(engine).Execute(context.Background(), core.PipelineRequest{
+		Name:         "overwrite",
+		StopOnError:  true,
+		CreateBackup: true,
+		Steps: []core.PipelineStep{
+			{ID: "w", Action: "write", Params: map[string]interface{}{"path": target, "content": "replaced"}},
+		},
+	})
+	if err != nil || !result.Success {
+		t.Fatalf("pipeline failed: %v", err)
+	}
+	if result.BackupID == "" {
+		t.Error("overwriting an existing file should create a backup")
+	}
+	if content, _ := os.ReadFile(target); string(content) != "replaced" {
+		t.Errorf("content = %q", content)
+	}
+
+	// Validation: path and content are required
+	bad := core.PipelineRequest{Name: "bad", Steps: []core.PipelineStep{
+		{ID: "w", Action: "write", Params: map[string]interface{}{"path": target}},
+	}}
+	if err := bad.Validate(); err == nil || !strings.Contains(err.Error(), "content") {
+		t.Errorf("expected missing content error, got %v", err)
+	}
+}
+
+func TestPipeline_CreateDirAndCopyWithCreateDirs(t *testing.T) {
+	tmpDir := t.TempDir()
+	engine := createTestEngineWithPath(t, tmpDir)
+	executor := core.NewPipelineExecutor(engine)
+
+	src := filepath.Join(tmpDir, "a.txt")
+	os.WriteFile(src, []byte("a"), 0644)
+	scaffold := filepath.Join(tmpDir, "scaffold", "src")
+	dest := filepath.Join(tmpDir, "out", "nested")
+
+	request := core.PipelineRequest{
+		Name:        "scaffold",
+		StopOnError: true,
+		Steps: []core.PipelineStep{
+			{ID: "mk", Action: "create_dir", Params: map[string]interface{}{"path": scaffold}},
+			{ID: "mk-again", Action: "create_dir", Params: map[string]interface{}{"path": tmpDir}},
+			{ID: "cp", Action: "copy", Params: map[string]interface{}{
+				"files": []interface{}{src}, "destination": dest, "create_dirs": true,
+			}},
+		},
+	}
+
+	// Dry run reports the directories without creating them
+	request.DryRun = true
+	result, err := executor.Execute(context.Background(), request)
+	if err != nil || !result.Success {
+		t.Fatalf("dry run failed: %v %+v", err, result.Results)
+	}
+	if got := result.Results[0].DirsCreated; len(got) != 1 || got[0] != scaffold {
+		t.Errorf("dry run create_dir DirsCreated = %v", got)
+	}
+	if got := result.Results[1].DirsCreated; len(got) != 0 {
+		t.Errorf("existing directory reported as created: %v", got)
+	}
+	if got := result.Results[2].DirsCreated; len(got) != 1 || got[0] != dest {
+		t.Errorf("dry run copy DirsCreated = %v", got)
+	}
+	for _, dir := range []string{scaffold, dest} {
+		if _, err := os.Stat(dir); !os.IsNotExist(err) {
+			t.Fatalf("dry run created %s", dir)
+		}
+	}
+
+	request.DryRun = false
+	result, err = executor.Execute(context.Background(), request)
+	if err != nil || !result.Success {
+		t.Fatalf("pipeline failed: %v %+v", err, result.Results)
+	}
+	if info, err := os.Stat(scaffold); err != nil || !info.IsDir() {
+		t.Errorf("create_dir did not create %s", scaffold)
+	}
+	if content, err := os.ReadFile(filepath.Join(dest, "a.txt")); err != nil || string(content) != "a" {
+		t.Errorf("copy into created directory failed: %v", err)
+	}
+
+	// A file in the way is an error, not silently ignored
+	blocker := filepath.Join(tmpDir, "blocker")
+	os.WriteFile(blocker, []byte("x"), 0644)
+	blocked, _ := executor.Execute(context.Background(), core.PipelineRequest{
+		Name: "blocked", StopOnError: true,
+		Steps: []core.PipelineStep{{ID: "mk", Action: "create_dir", Params: map[string]interface{}{"path": blocker}}},
+	})
+	if blocked.Success || !strings.Contains(blocked.Results[0].Error, "not a directory") {
+		t.Errorf("expected not-a-directory error, got %+v", blocked.Results)
+	}
+}
+
+func TestPipeline_FilterNarrowsFilesBeforeEdit(t *testing.T) {
+	tmpDir := t.TempDir()
+	engine := createTestEngineWithPath(t, tmpDir)
+
+	write := func(rel, content string) string {
+		p := filepath.Join(tmpDir, rel)
+		os.MkdirAll(filepath.Dir(p), 0755)
+		os.WriteFile(p, []byte(content), 0644)
+		return p
+	}
+	main := write("app/main.go", "oldName oldName oldName")
+	util := write("app/util.go", "oldName")
+	test := write("app/main_test.go", "oldName oldName")
+	gen := write("gen/api.go", "oldName oldName")
+
+	request := core.PipelineRequest{
+		Name:        "filter",
+		StopOnError: true,
+		Steps: []core.PipelineStep{
+			{ID: "count", Action: "count_occurrences", Params: map[string]interface{}{
+				"pattern": "oldName",
+				"files":   []interface{}{main, util, test, gen},
+			}},
+			{ID: "narrow", Action: "filter", InputFrom: "count", Params: map[string]interface{}{
+				"include_glob": "*.go",
+				"exclude_glob": []interface{}{"*_test.go", "gen/**"},
+				"min_count":    2,
+			}},
+			{ID: "fix", Action: "edit", InputFrom: "narrow", Params: map[string]interface{}{
+				"old_text": "oldName", "new_text": "newName",
+			}},
+		},
+	}
+
+	result, err := core.NewPipelineExecutor(engine).Execute(context.Background(), request)
+	if err != nil || !result.Success {
+		t.Fatalf("pipeline failed: %v %+v", err, result.Results)
+	}
+	narrow := result.Results[1]
+	if len(narrow.FilesMatched) != 1 || narrow.FilesMatched[0] != main {
+		t.Fatalf("filter kept %v, want only %s", narrow.FilesMatched, main)
+	}
+	if narrow.Counts[main] != 3 {
+		t.Errorf("filter should carry upstream counts, got %v", narrow.Counts)
+	}
+	for path, want := range map[string]string{main: "newName newName newName", util: "oldName", test: "oldName oldName", gen: "oldName oldName"} {
+		if got, _ := os.ReadFile(path); string(got) != want {
+			t.Errorf("%s = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestPipeline_FilterPathContainsAndMaxFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	engine := createTestEngineWithPath(t, tmpDir)
+
+	files := []interface{}{"/repo/src/a.go", "/repo/src/b.go", "/repo/docs/c.md", "/repo/src/d.go"}
+	result, err := core.NewPipelineExecutor(engine).Execute(context.Background(), core.PipelineRequest{
+		Name: "narrow", StopOnError: true,
+		Steps: []core.PipelineStep{
+			{ID: "f", Action: "filter", Params: map[string]interface{}{
+				"files": files, "path_contains": "/src/", "max_files": 2,
+			}},
+		},
+	})
+	if err != nil || !result.Success {
+		t.Fatalf("pipeline failed: %v", err)
+	}
+	if got := result.Results[0].FilesMatched; len(got) != 2 || got[0] != "/repo/src/a.go" || got[1] != "/repo/src/b.go" {
+		t.Errorf("filter = %v", got)
+	}
+
+	// No predicates is a validation error
+	bad := core.PipelineRequest{Name: "bad", Steps: []core.PipelineStep{
+		{ID: "f", Action: "filter", Params: map[string]interface{}{"files": files}},
+	}}
+	if err := bad.Validate(); err == nil || !strings.Contains(err.Error(), "at least one") {
+		t.Errorf("expected missing predicate error, got %v", err)
+	}
+}
+
+func TestPipeline_BatchRenameFromSearch(t *testing.T) {
+	tmpDir := t.TempDir()
+	engine := createTestEngineWithPath(t, tmpDir)
+
+	a := filepath.Join(tmpDir, "user_model.go")
+	b := filepath.Join(tmpDir, "user_view.go")
+	other := filepath.Join(tmpDir, "order.go")
+	os.WriteFile(a, []byte("type User struct{}"), 0644)
+	os.WriteFile(b, []byte("// User view"), 0644)
+	os.WriteFile(other, []byte("type Order struct{}"), 0644)
+
+	request := core.PipelineRequest{
+		Name:        "rename-user",
+		StopOnError: true,
+		Steps: []core.PipelineStep{
+			{ID: "count", Action: "count_occurrences", Params: map[string]interface{}{
+				"pattern": "User", "files": []interface{}{a, b, other},
+			}},
+			{ID: "narrow", Action: "filter", InputFrom: "count", Params: map[string]interface{}{"min_count": 1}},
+			{ID: "mv", Action: "batch_rename", InputFrom: "narrow", Params: map[string]interface{}{
+				"mode": "find_replace", "find": "user", "replace": "account",
+			}},
+			{ID: "fix", Action: "edit", InputFrom: "mv", Params: map[string]interface{}{"old_text": "User", "new_text": "Account"}},
+		},
+	}
+
+	// Dry run reports the mapping without touching anything
+	request.DryRun = true
+	result, err := core.NewPipelineExecutor(engine).Execute(context.Background(), request)
+	if err != nil || !result.Success {
+		t.Fatalf("dry run failed: %v %+v", err, result.Results)
+	}
+	if got := result.Results[2].Renames[a]; got != filepath.Join(tmpDir, "account_model.go") {
+		t.Errorf("planned rename for %s = %q", a, got)
+	}
+	if _, err := os.Stat(a); err != nil {
+		t.Fatalf("dry run renamed files: %v", err)
+	}
+
+	request.DryRun = false
+	result, err = core.NewPipelineExecutor(engine).Execute(context.Background(), request)
+	if err != nil || !result.Success {
+		t.Fatalf("pipeline failed: %v %+v", err, result.Results)
+	}
+	if len(result.Results[2].Renames) != 2 {
+		t.Errorf("renames = %v", result.Results[2].Renames)
+	}
+	if got, _ := os.ReadFile(filepath.Join(tmpDir, "account_model.go")); string(got) != "type Account struct{}" {
+		t.Errorf("renamed file content = %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "account_view.go")); err != nil {
+		t.Errorf("account_view.go missing: %v", err)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("unrelated file touched: %v", err)
+	}
+}
+
+func TestPipeline_BatchRenameCollisionRenamesNothing(t *testing.T) {
+	tmpDir := t.TempDir()
+	engine := createTestEngineWithPath(t, tmpDir)
+
+	a := filepath.Join(tmpDir, "Report.txt")
+	b := filepath.Join(tmpDir, "REPORT.TXT")
+	os.WriteFile(a, []byte("a"), 0644)
+	if err := os.WriteFile(b, []byte("b"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(tmpDir); len(entries) != 2 {
+		t.Skip("case-insensitive filesystem")
+	}
+
+	result, err := core.NewPipelineExecutor(engine).Execute(context.Background(), core.PipelineRequest{
+		Name: "lower", StopOnError: true,
+		Steps: []core.PipelineStep{
+			{ID: "mv", Action: "batch_rename", Params: map[string]interface{}{
+				"files": []interface{}{a, b}, "mode": "to_lowercase",
+			}},
+		},
+	})
+	if err == nil || result.Success || !strings.Contains(result.Results[0].Error, "naming conflict") {
+		t.Fatalf("expected collision failure, got %v %+v", err, result.Results)
+	}
+	for _, p := range []string{a, b} {
+		if _, err := os.Stat(p); err != nil {
+			t.Errorf("%s renamed despite collision: %v", p, err)
+		}
+	}
+
+	bad := core.PipelineRequest{Name: "bad", Steps: []core.PipelineStep{
+		{ID: "mv", Action: "batch_rename", Params: map[string]interface{}{"files": []interface{}{a}, "mode": "shuffle"}},
+	}}
+	if err := bad.Validate(); err == nil || !strings.Contains(err.Error(), "invalid mode") {
+		t.Errorf("expected invalid mode error, got %v", err)
+	}
+}
+
+func TestPipeline_BatchRenameRolledBack(t *testing.T) {
+	tmpDir := t.TempDir()
+	engine := createTestEngineWithPath(t, tmpDir)
+
+	a := filepath.Join(tmpDir, "a.txt")
+	os.WriteFile(a, []byte("alpha"), 0644)
+
+	result, err := core.NewPipelineExecutor(engine).Execute(context.Background(), core.PipelineRequest{
+		Name: "prefix", StopOnError: true, CreateBackup: true,
+		Steps: []core.PipelineStep{
+			{ID: "mv", Action: "batch_rename", Params: map[string]interface{}{
+				"files": []interface{}{a}, "mode": "add_prefix", "prefix": "old_",
+			}},
+			{ID: "fix", Action: "edit", InputFrom: "mv", Params: map[string]interface{}{"old_text": "alpha", "new_text": "beta"}},
+			{ID: "boom", Action: "edit", Params: map[string]interface{}{"files": []interface{}{filepath.Join(tmpDir, "missing.txt")}, "old_text": "x", "new_text": "y"}},
+		},
+	})
+	if err == nil || !result.RollbackPerformed {
+		t.Fatalf("expected rollback, got err=%v rollback=%v", err, result != nil && result.RollbackPerformed)
+	}
+	if got, _ := os.ReadFile(a); string(got) != "alpha" {
+		t.Errorf("original not restored: %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "old_a.txt")); !os.IsNotExist(err) {
+		t.Errorf("renamed file should be gone after rollback: %v", err)
+	}
+}
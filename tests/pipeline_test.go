@@ -631,6 +631,112 @@ func TestPipeline_Copy(t *testing.T) {
 	}
 }
 
+// TestPipeline_VerboseDiffs tests per-file diff snippets for edit and regex_transform
+func TestPipeline_VerboseDiffs(t *testing.T) {
+	testDir := t.TempDir()
+	engine := createTestEngineWithPath(t, testDir)
+	executor := core.NewPipelineExecutor(engine)
+	ctx := context.Background()
+
+	file := filepath.Join(testDir, "app.go")
+	os.WriteFile(file, []byte("package app\n\nvar version = \"v1\"\n"), 0644)
+
+	request := core.PipelineRequest{
+		Name:    "diffs",
+		Verbose: true,
+		Steps: []core.PipelineStep{
+			{ID: "bump", Action: "edit", Params: map[string]interface{}{"files": []interface{}{file}, "old_text": `"v1"`, "new_text": `"v2"`}},
+			{ID: "rename", Action: "regex_transform", Params: map[string]interface{}{
+				"files":    []interface{}{file},
+				"patterns": []interface{}{map[string]interface{}{"pattern": `var (\w+)`, "replacement": "const $1"}},
+			}},
+		},
+	}
+
+	result, err := executor.Execute(ctx, request)
+	if err != nil || !result.Success {
+		t.Fatalf("pipeline failed: %v %v", err, extractErrors(result))
+	}
+	editDiff := result.Results[0].Diffs[file]
+	if !strings.Contains(editDiff, `-var version = "v1"`) || !strings.Contains(editDiff, `+var version = "v2"`) {
+		t.Errorf("edit diff = %q", editDiff)
+	}
+	regexDiff := result.Results[1].Diffs[file]
+	if !strings.Contains(regexDiff, `+const version = "v2"`) {
+		t.Errorf("regex_transform diff = %q", regexDiff)
+	}
+	// regex_transform must write the transformed content, not blank the file
+	if got, _ := os.ReadFile(file); string(got) != "package app\n\nconst version = \"v2\"\n" {
+		t.Errorf("content after pipeline = %q", got)
+	}
+
+	// Non-verbose pipelines carry no diffs
+	os.WriteFile(file, []byte("var version = \"v1\"\n"), 0644)
+	request.Verbose = false
+	request.Steps = request.Steps[:1]
+	result, err = executor.Execute(ctx, request)
+	if err != nil || len(result.Results[0].Diffs) != 0 {
+		t.Errorf("non-verbose diffs = %v (err %v)", result.Results[0].Diffs, err)
+	}
+}
+
+// TestPipeline_DryRunDiffPreviews tests that a verbose dry run previews the
+// same edits, counts and risk the real run applies, without touching files
+func TestPipeline_DryRunDiffPreviews(t *testing.T) {
+	testDir := t.TempDir()
+	engine := createTestEngineWithPath(t, testDir)
+	executor := core.NewPipelineExecutor(engine)
+	ctx := context.Background()
+
+	file := filepath.Join(testDir, "cfg.go")
+	original := "host := \"localhost\"\nport := 8080\n"
+	os.WriteFile(file, []byte(original), 0644)
+
+	request := core.PipelineRequest{
+		Name:    "preview",
+		DryRun:  true,
+		Verbose: true,
+		Steps: []core.PipelineStep{
+			{ID: "edit", Action: "edit", Params: map[string]interface{}{"files": []interface{}{file}, "old_text": "8080", "new_text": "9090"}},
+			{ID: "multi", Action: "multi_edit", Params: map[string]interface{}{
+				"files": []interface{}{file},
+				"edits": []interface{}{map[string]interface{}{"old_text": `"localhost"`, "new_text": `"0.0.0.0"`}},
+			}},
+		},
+	}
+
+	preview, err := executor.Execute(ctx, request)
+	if err != nil || !preview.Success {
+		t.Fatalf("dry run failed: %v %v", err, extractErrors(preview))
+	}
+	if diff := preview.Results[0].Diffs[file]; !strings.Contains(diff, "+port := 9090") {
+		t.Errorf("edit preview diff = %q", diff)
+	}
+	if diff := preview.Results[1].Diffs[file]; !strings.Contains(diff, `-host := "localhost"`) || !strings.Contains(diff, `+host := "0.0.0.0"`) {
+		t.Errorf("multi_edit preview diff = %q", diff)
+	}
+	if got, _ := os.ReadFile(file); string(got) != original {
+		t.Fatalf("dry run modified the file: %q", got)
+	}
+
+	request.DryRun = false
+	applied, err := executor.Execute(ctx, request)
+	if err != nil || !applied.Success {
+		t.Fatalf("real run failed: %v %v", err, extractErrors(applied))
+	}
+	for i := range applied.Results {
+		p, a := preview.Results[i], applied.Results[i]
+		if p.Counts[file] != a.Counts[file] || p.RiskLevel != a.RiskLevel {
+			t.Errorf("step %s: preview counts=%d risk=%s, real counts=%d risk=%s",
+				a.StepID, p.Counts[file], p.RiskLevel, a.Counts[file], a.RiskLevel)
+		}
+	}
+	if preview.TotalEdits != applied.TotalEdits || preview.OverallRiskLevel != applied.OverallRiskLevel {
+		t.Errorf("preview total=%d risk=%s, real total=%d risk=%s",
+			preview.TotalEdits, preview.OverallRiskLevel, applied.TotalEdits, applied.OverallRiskLevel)
+	}
+}
+
 // Helper functions
 
 func extractErrors(result *core.PipelineResult) []string {
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mcp/filesystem-ultra/core"
+)
+
+func TestVariables_ResolveBuiltinsAndUserValues(t *testing.T) {
+	pCtx := core.NewPipelineContext()
+	pCtx.SetPipelineInfo("nightly", map[string]string{"pattern": "oldName", "dest": "/out"})
+	pCtx.SetStepResult("find", &core.StepResult{
+		Success:      true,
+		FilesMatched: []string{"a.go", "b.go"},
+		Counts:       map[string]int{"a.go": 2, "b.go": 1},
+	})
+
+	params := map[string]interface{}{
+		"destination": "${dest}/${pipeline.name}-${date}",
+		"first":       "${steps.find.files[1]}",
+		"edits": []interface{}{
+			map[string]interface{}{"old_text": "${pattern}", "new_text": "x${steps.find.count}"},
+		},
+		"literal": "$${pattern}",
+		"number":  3.0,
+	}
+	resolved, err := core.ResolveVariables(params, pCtx)
+	if err != nil {
+		t.Fatalf("ResolveVariables: %v", err)
+	}
+
+	today := time.Now().Format("2006-01-02")
+	if got := resolved["destination"]; got != "/out/nightly-"+today {
+		t.Errorf("destination = %v", got)
+	}
+	if got := resolved["first"]; got != "b.go" {
+		t.Errorf("first = %v, want b.go", got)
+	}
+	edit := resolved["edits"].([]interface{})[0].(map[string]interface{})
+	if edit["old_text"] != "oldName" || edit["new_text"] != "x3" {
+		t.Errorf("nested edit = %v", edit)
+	}
+	if got := resolved["literal"]; got != "${pattern}" {
+		t.Errorf("escaped placeholder = %v, want ${pattern}", got)
+	}
+	if got := resolved["number"]; got != 3.0 {
+		t.Errorf("non-string param changed: %v", got)
+	}
+
+	// Out-of-range index fails at run time and names the param
+	_, err = core.ResolveVariables(map[string]interface{}{"path": "${steps.find.files[5]}"}, pCtx)
+	if err == nil || !strings.Contains(err.Error(), "param 'path'") {
+		t.Errorf("expected out-of-range error naming the param, got %v", err)
+	}
+}
+
+func TestVariables_ValidationErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		pipeline  string
+		wantParts []string
+	}{
+		{
+			"unknown variable",
+			`{"name": "v", "steps": [{"id": "cp", "action": "copy", "params": {"files": ["a"], "destination": "/tmp/${nope}"}}]}`,
+			[]string{"'cp'", "destination", "unknown variable '${nope}'"},
+		},
+		{
+			"later step",
+			`{"name": "v", "steps": [
+				{"id": "cp", "action": "copy", "params": {"files": ["${steps.find.files[0]}"], "destination": "/tmp"}},
+				{"id": "find", "action": "search", "params": {"pattern": "x"}}]}`,
+			[]string{"'cp'", "files[0]", "not defined before"},
+		},
+		{
+			"index on non-files field",
+			`{"name": "v", "steps": [
+				{"id": "find", "action": "search", "params": {"pattern": "x"}},
+				{"id": "cp", "action": "copy", "params": {"files": ["a"], "destination": "${steps.find.risk[0]}"}}]}`,
+			[]string{"only files can be indexed"},
+		},
+		{
+			"shadowed built-in",
+			`{"name": "v", "variables": {"date": "x"}, "steps": [{"id": "s", "action": "search", "params": {"pattern": "x"}}]}`,
+			[]string{"shadows a built-in"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var request core.PipelineRequest
+			if err := json.Unmarshal([]byte(tc.pipeline), &request); err != nil {
+				t.Fatal(err)
+			}
+			err := request.Validate()
+			if err == nil {
+				t.Fatal("expected validation error")
+			}
+			for _, part := range tc.wantParts {
+				if !strings.Contains(err.Error(), part) {
+					t.Errorf("error %q missing %q", err.Error(), part)
+				}
+			}
+		})
+	}
+}
+
+func TestVariables_PipelineCopyToDatedDirectory(t *testing.T) {
+	tmpDir := t.TempDir()
+	engine := createTestEngineWithPath(t, tmpDir)
+
+	src := filepath.Join(tmpDir, "notes.txt")
+	os.WriteFile(src, []byte("keep me"), 0644)
+
+	request := core.PipelineRequest{
+		Name:        "snapshot",
+		StopOnError: true,
+		Variables:   map[string]string{"root": filepath.ToSlash(tmpDir)},
+		Steps: []core.PipelineStep{
+			{ID: "find", Action: "search", Params: map[string]interface{}{"path": tmpDir, "pattern": "keep"}},
+			{ID: "cp", Action: "copy", InputFrom: "find", Params: map[string]interface{}{
+				"destination": "${root}/${pipeline.name}-${date}",
+			}},
+		},
+	}
+
+	result, err := core.NewPipelineExecutor(engine).Execute(context.Background(), request)
+	if err != nil {
+		t.Fatalf("pipeline failed: %v (%+v)", err, result.Results)
+	}
+	want := filepath.Join(tmpDir, "snapshot-"+time.Now().Format("2006-01-02"), "notes.txt")
+	if content, err := os.ReadFile(want); err != nil || string(content) != "keep me" {
+		t.Fatalf("copy not found at %s: %v", want, err)
+	}
+}
@@ -59,8 +59,8 @@ func TestSoftDeleteUsesBackupDir(t *testing.T) {
 		t.Fatal("source file still exists after SoftDeleteFile")
 	}
 
-	// The file must be in <backup-dir>/filesdelete/<sd-id>/<basename>
-	expectedDir := filepath.Join(backupDir, "filesdelete", info.SDID)
+	// The file must be in <backup-dir>/trash/<sd-id without "sd-">/<path below the allowed root>
+	expectedDir := filepath.Join(backupDir, "trash", strings.TrimPrefix(info.SDID, "sd-"))
 	if _, err := os.Stat(expectedDir); err != nil {
 		t.Fatalf("expected trash subdir %s: %v", expectedDir, err)
 	}
@@ -97,13 +97,13 @@ func TestSoftDeleteUsesBackupDir(t *testing.T) {
 
 	// Trash directory must NOT contain the legacy "filesdelete/__REPOS/..." style path
 	// (which was the bug — file got mis-rooted to C:\temp\__REPOS\...)
-	trashRoot := filepath.Join(backupDir, "filesdelete")
+	trashRoot := filepath.Join(backupDir, "trash")
 	walkErr := filepath.Walk(trashRoot, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
-		// Any path under the trash should be <sd-id>/<basename> or <sd-id>/metadata.json
-		// Never __REPOS or arbitrary deep trees.
+		// Any path under the trash should be <entry>/<path below the allowed root>
+		// or <entry>/metadata.json. Never __REPOS.
 		if info.IsDir() && info.Name() == "__REPOS" {
 			t.Errorf("trash contains __REPOS/ — legacy walk-up path leaked: %s", path)
 		}
@@ -5,6 +5,7 @@
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -47,7 +48,7 @@ func TestBackupPurgeTrash(t *testing.T) {
 
 	// Backdate the metadata.json's timestamp field so the entry looks >7 days
 	// old. (PurgeTrash filters by the JSON timestamp, not the file's mtime.)
-	metaPath := filepath.Join(backupDir, "filesdelete", info.SDID, "metadata.json")
+	metaPath := filepath.Join(backupDir, "trash", strings.TrimPrefix(info.SDID, "sd-"), "metadata.json")
 	data, err := os.ReadFile(metaPath)
 	if err != nil {
 		t.Fatalf("read metadata: %v", err)
@@ -91,7 +92,7 @@ func TestBackupPurgeTrash(t *testing.T) {
 	if _, err := os.Stat(metaPath); !os.IsNotExist(err) {
 		t.Errorf("real-run did not delete the file: %v", err)
 	}
-	trashDir := filepath.Join(backupDir, "filesdelete", info.SDID)
+	trashDir := filepath.Join(backupDir, "trash", strings.TrimPrefix(info.SDID, "sd-"))
 	if _, err := os.Stat(trashDir); !os.IsNotExist(err) {
 		t.Errorf("real-run did not remove trash subdir: %v", err)
 	}
@@ -138,8 +139,80 @@ func TestBackupPurgeTrashRespectsCutoff(t *testing.T) {
 	if deleted != 0 {
 		t.Errorf("purged %d recent entries; want 0", deleted)
 	}
-	metaPath := filepath.Join(backupDir, "filesdelete", info.SDID, "metadata.json")
+	metaPath := filepath.Join(backupDir, "trash", strings.TrimPrefix(info.SDID, "sd-"), "metadata.json")
 	if _, err := os.Stat(metaPath); err != nil {
 		t.Errorf("recent entry was incorrectly purged: %v", err)
 	}
 }
+
+// TestTrashRetentionOnStartup verifies that --trash-max-age purges expired
+// entries when the engine starts, and that an entry whose file was already
+// restored frees nothing.
+func TestTrashRetentionOnStartup(t *testing.T) {
+	backupDir := t.TempDir()
+	allowedDir := t.TempDir()
+	newEngine := func(maxAge time.Duration) *core.UltraFastEngine {
+		cacheSystem, err := cache.NewIntelligentCache(10 * 1024 * 1024)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { cacheSystem.Close() })
+		engine, err := core.NewUltraFastEngine(&core.Config{
+			Cache:        cacheSystem,
+			ParallelOps:  2,
+			AllowedPaths: []string{allowedDir},
+			BackupDir:    backupDir,
+			TrashMaxAge:  maxAge,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { engine.Close() })
+		return engine
+	}
+
+	ctx := context.Background()
+	first := newEngine(0)
+	var infos []*core.SoftDeleteInfo
+	for _, name := range []string{"a.txt", "b.txt"} {
+		path := filepath.Join(allowedDir, name)
+		if err := os.WriteFile(path, []byte("content of "+name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		info, err := first.SoftDeleteFile(ctx, path)
+		if err != nil {
+			t.Fatalf("SoftDeleteFile failed: %v", err)
+		}
+		infos = append(infos, info)
+	}
+	// b was restored by hand, leaving its metadata behind
+	if err := os.Rename(infos[1].DestPath, infos[1].OriginalPath); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	res, err := first.GetBackupManager().PurgeTrashOlderThan(time.Millisecond, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Entries) != 2 || res.FreedBytes != infos[0].Size {
+		t.Errorf("dry run = %d entries, %d bytes; want 2 entries, %d bytes", len(res.Entries), res.FreedBytes, infos[0].Size)
+	}
+
+	newEngine(time.Millisecond)
+	trashRoot := filepath.Join(backupDir, "trash")
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		entries, _ := os.ReadDir(trashRoot)
+		if len(entries) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("startup retention left %d entries", len(entries))
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if _, err := os.Stat(infos[1].OriginalPath); err != nil {
+		t.Errorf("restored file touched by the purge: %v", err)
+	}
+}
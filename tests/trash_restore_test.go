@@ -2,6 +2,7 @@
 
 import (
 	"context"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
@@ -69,7 +70,7 @@ func TestBackupRestoreTrash(t *testing.T) {
 	}
 
 	// Trash dir for this SD-ID should be gone
-	trashDir := filepath.Join(backupDir, "filesdelete", info.SDID)
+	trashDir := filepath.Join(backupDir, "trash", strings.TrimPrefix(info.SDID, "sd-"))
 	if _, err := os.Stat(trashDir); !os.IsNotExist(err) {
 		t.Errorf("trash subdir %q still exists after restore: %v", trashDir, err)
 	}
@@ -176,3 +177,128 @@ func TestRestoreTrashRefusesIfOriginalPathExists(t *testing.T) {
 		t.Errorf("user's new file was modified: got %q", got)
 	}
 }
+
+// TestTrashPreservesStructure verifies that two files with the same name get
+// separate entries keyed by their path below the allowed root, that a
+// directory keeps its subtree through a delete/restore cycle, and that entries
+// of the old flat filesdelete/ layout can still be listed and restored.
+func TestTrashPreservesStructure(t *testing.T) {
+	backupDir := t.TempDir()
+	allowedDir := t.TempDir()
+	files := map[string]string{
+		"app/config.json":        `{"app":true}`,
+		"worker/config.json":     `{"worker":true}`,
+		"assets/img/logo.svg":    "<svg/>",
+		"assets/css/site.css":    "body{}",
+		"assets/css/vendor/a.js": "x",
+	}
+	for rel, content := range files {
+		p := filepath.Join(allowedDir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cacheSystem, err := cache.NewIntelligentCache(10 * 1024 * 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cacheSystem.Close()
+	engine, err := core.NewUltraFastEngine(&core.Config{
+		Cache:        cacheSystem,
+		ParallelOps:  2,
+		AllowedPaths: []string{allowedDir},
+		BackupDir:    backupDir,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer engine.Close()
+	ctx := context.Background()
+	bm := engine.GetBackupManager()
+
+	// Same basename, different directories: no collision
+	var ids []string
+	for _, rel := range []string{"app/config.json", "worker/config.json"} {
+		info, err := engine.SoftDeleteFile(ctx, filepath.Join(allowedDir, filepath.FromSlash(rel)))
+		if err != nil {
+			t.Fatalf("SoftDeleteFile(%s) failed: %v", rel, err)
+		}
+		if info.RelPath != filepath.FromSlash(rel) {
+			t.Errorf("RelPath = %q, want %q", info.RelPath, rel)
+		}
+		want := filepath.Join(backupDir, "trash", strings.TrimPrefix(info.SDID, "sd-"), filepath.FromSlash(rel))
+		if info.DestPath != want {
+			t.Errorf("DestPath = %q, want %q", info.DestPath, want)
+		}
+		ids = append(ids, info.SDID)
+	}
+	for _, id := range ids {
+		if _, err := bm.RestoreTrash(id); err != nil {
+			t.Fatalf("RestoreTrash(%s) failed: %v", id, err)
+		}
+	}
+	for _, rel := range []string{"app/config.json", "worker/config.json"} {
+		got, _ := os.ReadFile(filepath.Join(allowedDir, filepath.FromSlash(rel)))
+		if string(got) != files[rel] {
+			t.Errorf("%s restored as %q, want %q", rel, got, files[rel])
+		}
+	}
+
+	// A directory moves with its whole subtree
+	assets := filepath.Join(allowedDir, "assets")
+	info, err := engine.SoftDeleteFile(ctx, assets)
+	if err != nil {
+		t.Fatalf("SoftDeleteFile(dir) failed: %v", err)
+	}
+	if !info.IsDir || info.Files != 3 || info.Size != int64(len("<svg/>body{}x")) {
+		t.Errorf("dir entry = %+v", info)
+	}
+	if _, err := os.Stat(filepath.Join(info.DestPath, "css", "vendor", "a.js")); err != nil {
+		t.Errorf("subtree not kept in the trash: %v", err)
+	}
+	entries, err := bm.ListTrash(0, "assets", 0)
+	if err != nil || len(entries) != 1 || !entries[0].IsDir {
+		t.Fatalf("ListTrash = %+v, %v", entries, err)
+	}
+	if _, err := bm.RestoreTrash(info.SDID); err != nil {
+		t.Fatalf("RestoreTrash(dir) failed: %v", err)
+	}
+	for rel, content := range files {
+		if !strings.HasPrefix(rel, "assets/") {
+			continue
+		}
+		if got, _ := os.ReadFile(filepath.Join(allowedDir, filepath.FromSlash(rel))); string(got) != content {
+			t.Errorf("%s restored as %q", rel, got)
+		}
+	}
+
+	// An entry of the flat layout is still found
+	legacyDir := filepath.Join(backupDir, "filesdelete", "sd-20260101-120000-0123456789abcdef")
+	if err := os.MkdirAll(legacyDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	legacyOriginal := filepath.Join(allowedDir, "old.txt")
+	os.WriteFile(filepath.Join(legacyDir, "old.txt"), []byte("old"), 0644)
+	os.WriteFile(filepath.Join(legacyDir, "metadata.json"), []byte(`{"sd_id":"sd-20260101-120000-0123456789abcdef",`+
+		`"original_path":`+jsonQuote(legacyOriginal)+`,"dest_path":`+jsonQuote(filepath.Join(legacyDir, "old.txt"))+
+		`,"size":3,"timestamp":"2026-01-01T12:00:00Z","kind":"soft_delete"}`), 0600)
+	if entries, _ := bm.ListTrash(0, "old.txt", 0); len(entries) != 1 {
+		t.Fatalf("legacy entry not listed: %+v", entries)
+	}
+	if _, err := bm.RestoreTrash("sd-20260101-120000-0123456789abcdef"); err != nil {
+		t.Fatalf("RestoreTrash(legacy) failed: %v", err)
+	}
+	if got, _ := os.ReadFile(legacyOriginal); string(got) != "old" {
+		t.Errorf("legacy restore = %q", got)
+	}
+}
+
+// jsonQuote renders s as a JSON string literal
+func jsonQuote(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
@@ -10,7 +10,8 @@
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
-// registerAliases registers the 6 compatibility aliases
+// registerAliases registers the 5 compatibility aliases
+// (directory trees are analyze_directory(action:"tree") since 4.5.33)
 func registerAliases(reg *toolRegistry) {
 	s := reg.server
 
@@ -92,15 +93,6 @@ func registerAliases(reg *toolRegistry) {
 		mcp.WithString("content_base64", mcp.Description("Base64-encoded binary content")),
 		mcp.WithString("encoding", mcp.Description("Set to \"base64\" for base64 content")),
 	), reg.writeFileHandler)
-
-	s.AddTool(mcp.NewTool("directory_tree",
-		mcp.WithTitleAnnotation("Directory Tree (alias)"),
-		mcp.WithDescription("Alias for list_directory. Related: search_files, read_file, edit_file."),
-		mcp.WithReadOnlyHintAnnotation(true),
-		mcp.WithDestructiveHintAnnotation(false),
-		mcp.WithIdempotentHintAnnotation(true),
-		mcp.WithString("path", mcp.Required(), mcp.Description("Path to directory")),
-	), reg.listDirHandler)
 }
 
 // registerClaudeCodeAliases registers 7 aliases matching Claude Code tool names:
@@ -296,7 +288,7 @@ func registerSuperTool(reg *toolRegistry) {
 		mcp.WithNumber("older_than_days", mcp.Description("Cleanup: older than N days")),
 		mcp.WithBoolean("preview", mcp.Description("Preview restore")),
 		// wsl — uses "action" field internally, remap from wsl_action
-		mcp.WithString("wsl_action", mcp.Description("WSL: sync/status/autosync_config/autosync_status")),
+		mcp.WithString("wsl_action", mcp.Description("WSL: sync/sync_status/status/autosync_config/autosync_status")),
 		mcp.WithString("wsl_path", mcp.Description("WSL path")),
 		mcp.WithString("windows_path", mcp.Description("Windows path")),
 		mcp.WithString("direction", mcp.Description("Sync direction")),
@@ -309,7 +301,7 @@ func registerSuperTool(reg *toolRegistry) {
 		// server_info — uses "action" field internally, remap from server_action
 		mcp.WithString("server_action", mcp.Description("Info: help/stats/artifact")),
 		mcp.WithString("topic", mcp.Description("Help topic")),
-		mcp.WithString("sub_action", mcp.Description("Artifact: capture/write/info")),
+		mcp.WithString("sub_action", mcp.Description("Artifact: capture/write/info/delete")),
 	)
 
 	s.AddTool(fsTool, auditWrap(engine, "fs", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -442,6 +434,14 @@ func registerHelpTool(reg *toolRegistry) {
 		all := reg.server.ListTools()
 		st, ok := all[name]
 		if !ok {
+			if reg.filter != nil {
+				if reason, off := reg.filter.disabled[name]; off {
+					return usageError(
+						fmt.Sprintf("tool %q is disabled on this server (%s)", name, reason),
+						`server_info(action:"tools")  // registered and disabled tools`),
+						nil
+				}
+			}
 			return usageError(
 				fmt.Sprintf("no tool named %q registered on this server", name),
 				`help()  // for the catalog`),
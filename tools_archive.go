@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mcp/filesystem-ultra/core"
+)
+
+// registerArchiveTools registers the archive tool
+func registerArchiveTools(reg *toolRegistry) {
+	engine := reg.engine
+
+	// ============================================================================
+	// archive — Pack and unpack archives
+	// ============================================================================
+	archiveTool := mcp.NewTool("archive",
+		mcp.WithTitleAnnotation("Archive"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(false),
+		mcp.WithDescription("archive — Zip and tar.gz archives on the real host filesystem. Actions: create, extract, list, read, gzip, gunzip. "+
+			"create: pack sources (files and directories) and/or the files matching glob into archive_path, entries named relative to base_dir "+
+			"(default: the sources' parent directory). .git, node_modules and the other default excludes are skipped, symlinks too unless follow_symlinks:true; exclude adds globs. "+
+			"The archive is written to a temporary file and renamed into place, and must lie in the allowed paths. "+
+			"More than 2 GB of input is refused unless force:true. Reports file count, uncompressed and compressed sizes. "+
+			"extract: unpack archive_path into dest_dir (within the allowed paths). Every entry is checked first: an absolute or ../ entry refuses the whole archive. "+
+			"Directories are created as needed, file modes and times kept, symlink entries skipped with a warning (follow_symlinks:true recreates those pointing inside dest_dir); existing files are skipped unless overwrite:true. "+
+			"include_glob extracts a subset; dry_run:true lists each entry's action without writing. Reports extracted/overwritten/skipped counts. "+
+			"list: entries with size and modification time, nothing extracted. "+
+			"read: the content of the one entry named exactly entry_path, streamed from the archive without touching disk; "+
+			"a miss suggests the closest entry names. At most max_bytes (and never more than the response size limit) are returned, with a truncation note. "+
+			"Binary entries need as_base64:true. "+
+			"gzip / gunzip: compress or decompress the single file path to dest (default: path + \".gz\" / path without \".gz\"), streamed, "+
+			"keeping the modification time; an existing dest is refused unless overwrite:true, delete_source:true removes the input afterwards. "+
+			"Reports sizes before and after and the ratio. "+
+			"Related: copy_file, list_directory, get_file_info."),
+		mcp.WithString("action", mcp.Description("Action: create (default), extract, list, read, gzip, gunzip")),
+		mcp.WithString("archive_path", mcp.Description("The archive file (.zip, .tar.gz or .tgz); required except for gzip and gunzip")),
+		mcp.WithString("archive_format", mcp.Description("Archive format: zip or tar.gz. Default: from the archive_path extension")),
+		// create params
+		mcp.WithArray("sources", mcp.WithStringItems(), mcp.Description("For create: files and directories to pack")),
+		mcp.WithString("glob", mcp.Description("For create: path glob of files to pack, e.g. \"/project/out/**/*.pdf\"")),
+		mcp.WithString("base_dir", mcp.Description("For create: entry names are relative to this directory (default: the sources' parent, or the glob's base)")),
+		mcp.WithArray("exclude", mcp.WithStringItems(), mcp.Description("For create: extra globs to skip (\"*.log\", \"dist/**\"), on top of the defaults")),
+		mcp.WithNumber("compression_level", mcp.Description("For create and gzip: 0 (store) to 9 (smallest); default: deflate's default")),
+		// extract params
+		mcp.WithString("dest_dir", mcp.Description("For extract: directory to unpack into (created if missing)")),
+		mcp.WithString("include_glob", mcp.Description("For extract: only entries matching this glob, e.g. \"*.csv\" or \"docs/**\"")),
+		mcp.WithBoolean("dry_run", mcp.Description("For extract: list what would be extracted, write nothing (default: false)")),
+		// read params
+		mcp.WithString("entry_path", mcp.Description("For read: exact entry name as listed, e.g. \"docs/readme.md\"")),
+		mcp.WithNumber("max_bytes", mcp.Description("For read: return at most this many bytes of the entry (default: the response size limit)")),
+		mcp.WithBoolean("as_base64", mcp.Description("For read: return the entry base64-encoded, for binary content (default: false)")),
+		// gzip / gunzip params
+		mcp.WithString("path", mcp.Description("For gzip/gunzip: the file to compress or decompress")),
+		mcp.WithString("dest", mcp.Description("For gzip/gunzip: output file (default: path + \".gz\", or path without \".gz\"; .tgz becomes .tar)")),
+		mcp.WithBoolean("delete_source", mcp.Description("For gzip/gunzip: remove path once dest is written (default: false)")),
+		mcp.WithBoolean("overwrite", mcp.Description("create: replace an existing archive_path; extract: replace existing files; gzip/gunzip: replace dest (default: false)")),
+		mcp.WithBoolean("force", mcp.Description("Pack, unpack or gunzip more than 2 GB (default: false)")),
+		mcp.WithBoolean("follow_symlinks", mcp.Description("create: pack what symlinks point to; extract: recreate symlink entries that stay inside dest_dir (default: false, skip them)")),
+		formatParam(),
+	)
+	reg.addTool(archiveTool, auditWrap(engine, "archive", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, _ := request.Params.Arguments.(map[string]interface{})
+		action := request.GetString("action", "create")
+		archivePath := request.GetString("archive_path", "")
+		if archivePath == "" && action != "gzip" && action != "gunzip" {
+			return usageError("archive_path is required", `archive(action:"create", archive_path:"/project/out.zip", sources:["/project/out"])`), nil
+		}
+		format := request.GetString("archive_format", "")
+
+		switch action {
+		case "create", "":
+			sources, errResult := stringsFromArgs(args, "sources")
+			if errResult != nil {
+				return errResult, nil
+			}
+			exclude, errResult := excludeFromArgs(args)
+			if errResult != nil {
+				return errResult, nil
+			}
+			opts := core.ArchiveOptions{
+				Sources:          sources,
+				Glob:             request.GetString("glob", ""),
+				BaseDir:          request.GetString("base_dir", ""),
+				Exclude:          exclude,
+				CompressionLevel: request.GetInt("compression_level", -1),
+				Overwrite:        request.GetBool("overwrite", false),
+				Force:            request.GetBool("force", false),
+				FollowSymlinks:   request.GetBool("follow_symlinks", false),
+			}
+			if len(opts.Sources) == 0 && opts.Glob == "" {
+				return usageError("sources or glob is required", `archive(action:"create", archive_path:"/project/out.zip", sources:["/project/out"])`), nil
+			}
+			res, err := engine.CreateArchive(ctx, archivePath, format, opts)
+			if err != nil {
+				return mcp.NewToolResultError(formatToolError(err)), nil
+			}
+			core.MarkMutating(ctx)
+			if wantJSON(engine, request) {
+				return jsonResult(res), nil
+			}
+			return mcp.NewToolResultText(core.FormatArchiveResult(res, engine.IsCompactMode())), nil
+
+		case "extract":
+			destDir := request.GetString("dest_dir", "")
+			if destDir == "" {
+				return usageError("dest_dir is required for extract", `archive(action:"extract", archive_path:"/project/in.zip", dest_dir:"/project/in")`), nil
+			}
+			opts := core.ExtractOptions{
+				Include:        request.GetString("include_glob", ""),
+				Overwrite:      request.GetBool("overwrite", false),
+				DryRun:         request.GetBool("dry_run", false),
+				Force:          request.GetBool("force", false),
+				FollowSymlinks: request.GetBool("follow_symlinks", false),
+			}
+			res, err := engine.ExtractArchive(ctx, archivePath, destDir, format, opts)
+			if err != nil {
+				return mcp.NewToolResultError(formatToolError(err)), nil
+			}
+			if !opts.DryRun {
+				core.MarkMutating(ctx)
+			}
+			if wantJSON(engine, request) {
+				return jsonResult(res), nil
+			}
+			return mcp.NewToolResultText(core.FormatExtractResult(res, engine.IsCompactMode())), nil
+
+		case "list":
+			res, err := engine.ListArchive(ctx, archivePath, format)
+			if err != nil {
+				return mcp.NewToolResultError(formatToolError(err)), nil
+			}
+			if wantJSON(engine, request) {
+				return jsonResult(res), nil
+			}
+			return chunkedText(engine, "archive", core.FormatArchiveListing(res, engine.IsCompactMode()), responseLimit(engine)), nil
+
+		case "read":
+			entry := request.GetString("entry_path", "")
+			if entry == "" {
+				return usageError("entry_path is required for read", `archive(action:"read", archive_path:"/project/in.zip", entry_path:"docs/readme.md")`), nil
+			}
+			res, err := engine.ReadArchiveEntry(ctx, archivePath, entry, format,
+				int64(request.GetInt("max_bytes", 0)), request.GetBool("as_base64", false))
+			if err != nil {
+				return mcp.NewToolResultError(formatToolError(err)), nil
+			}
+			if wantJSON(engine, request) {
+				return jsonResult(res), nil
+			}
+			return mcp.NewToolResultText(core.FormatArchiveEntryContent(res, engine.IsCompactMode())), nil
+
+		case "gzip", "gunzip":
+			path := request.GetString("path", "")
+			if path == "" {
+				return usageError("path is required for "+action, `archive(action:"gzip", path:"/var/log/app.log", delete_source:true)`), nil
+			}
+			opts := core.GzipOptions{
+				Dest:         request.GetString("dest", ""),
+				Overwrite:    request.GetBool("overwrite", false),
+				DeleteSource: request.GetBool("delete_source", false),
+				Level:        request.GetInt("compression_level", -1),
+				Force:        request.GetBool("force", false),
+			}
+			var res *core.GzipResult
+			var err error
+			if action == "gzip" {
+				res, err = engine.GzipFile(ctx, path, opts)
+			} else {
+				res, err = engine.GunzipFile(ctx, path, opts)
+			}
+			if err != nil {
+				return mcp.NewToolResultError(formatToolError(err)), nil
+			}
+			core.MarkMutating(ctx)
+			if wantJSON(engine, request) {
+				return jsonResult(res), nil
+			}
+			return mcp.NewToolResultText(core.FormatGzipResult(res, engine.IsCompactMode())), nil
+
+		default:
+			return usageError(fmt.Sprintf("invalid action %q. Valid: create, extract, list, read, gzip, gunzip", action), `archive(action:"create", archive_path:"/project/out.zip", sources:["/project/out"])`), nil
+		}
+	}))
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mcp/filesystem-ultra/core"
+)
+
+// artifactResult handles server_info(action:"artifact"): named slots of
+// captured content, written to disk on request. sub_action picks capture,
+// capture_chunk, diff, write, apply, info (alias list) or delete; name "last" (the default) is the most
+// recently captured slot.
+func artifactResult(ctx context.Context, engine *core.UltraFastEngine, request mcp.CallToolRequest) *mcp.CallToolResult {
+	name := request.GetString("name", "")
+
+	switch subAction := request.GetString("sub_action", "info"); subAction {
+	case "capture":
+		content := request.GetString("content", "")
+		if content == "" {
+			return usageError("content is required for artifact capture", `server_info(action:"artifact", sub_action:"capture", name:"report", content:"...")`)
+		}
+		res, err := engine.CaptureArtifact(ctx, name, content)
+		if err != nil {
+			return mcp.NewToolResultError(formatToolError(err))
+		}
+		if wantJSON(engine, request) {
+			return jsonResult(res)
+		}
+		return mcp.NewToolResultText(core.FormatArtifactCapture(res))
+
+	case "capture_chunk":
+		args, _ := request.Params.Arguments.(map[string]interface{})
+		if _, ok := args["index"]; !ok {
+			return usageError("index is required for artifact capture_chunk", `server_info(action:"artifact", sub_action:"capture_chunk", name:"report", chunk:"...", index:0, total:3)`)
+		}
+		chunk := request.GetString("chunk", "")
+		if chunk == "" {
+			return usageError("chunk is required for artifact capture_chunk", `server_info(action:"artifact", sub_action:"capture_chunk", name:"report", chunk:"...", index:0, total:3)`)
+		}
+		res, err := engine.CaptureArtifactChunk(ctx, name, chunk, request.GetInt("index", 0), request.GetInt("total", 0), request.GetBool("final", false))
+		if err != nil {
+			return mcp.NewToolResultError(formatToolError(err))
+		}
+		if wantJSON(engine, request) {
+			return jsonResult(res)
+		}
+		return mcp.NewToolResultText(core.FormatArtifactCapture(res))
+
+	case "diff":
+		path := request.GetString("path", "")
+		if path == "" {
+			return usageError("path is required for artifact diff", `server_info(action:"artifact", sub_action:"diff", name:"report", path:"/project/report.md")`)
+		}
+		res, err := engine.DiffArtifact(ctx, name, path, request.GetInt("context_lines", 3))
+		if err != nil {
+			return mcp.NewToolResultError(formatToolError(err))
+		}
+		if wantJSON(engine, request) {
+			return jsonResult(res)
+		}
+		return chunkedText(engine, "artifact_diff", core.FormatArtifactDiff(res), responseLimit(engine))
+
+	case "write":
+		path := request.GetString("path", "")
+		if path == "" {
+			return usageError("path is required for artifact write", `server_info(action:"artifact", sub_action:"write", name:"report", path:"/project/report.md")`)
+		}
+		info, err := engine.WriteArtifact(ctx, name, path, request.GetString("expected_hash", ""))
+		if err != nil {
+			return mcp.NewToolResultError(formatToolError(err))
+		}
+		core.MarkMutating(ctx)
+		if wantJSON(engine, request) {
+			return jsonResult(info)
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Wrote artifact %q (%d bytes) to: %s", info.Name, info.Bytes, path))
+
+	case "apply":
+		path := request.GetString("path", "")
+		if path == "" {
+			return usageError("path is required for artifact apply", `server_info(action:"artifact", sub_action:"apply", name:"fix", path:"/project/main.go", mode:"patch")`)
+		}
+		res, err := engine.ApplyArtifact(ctx, name, path, request.GetString("mode", core.ArtifactOverwrite), request.GetString("expected_hash", ""))
+		if err != nil {
+			return mcp.NewToolResultError(formatToolError(err))
+		}
+		core.MarkMutating(ctx)
+		if wantJSON(engine, request) {
+			return jsonResult(res)
+		}
+		return mcp.NewToolResultText(core.FormatArtifactApply(res))
+
+	case "info", "list":
+		list := engine.ListArtifacts()
+		if wantJSON(engine, request) {
+			return jsonResult(list)
+		}
+		return mcp.NewToolResultText(core.FormatArtifactList(list, engine.IsCompactMode()))
+
+	case "delete":
+		if name == "" {
+			return usageError("name is required for artifact delete", `server_info(action:"artifact", sub_action:"delete", name:"report")`)
+		}
+		deleted, err := engine.DeleteArtifact(name)
+		if err != nil {
+			return mcp.NewToolResultError(formatToolError(err))
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Deleted artifact %q", deleted))
+
+	default:
+		return usageError(fmt.Sprintf("invalid sub_action %q. Valid: capture, capture_chunk, diff, write, apply, info, list, delete", subAction),
+			`server_info(action:"artifact", sub_action:"info")`)
+	}
+}
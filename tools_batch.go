@@ -3,9 +3,11 @@
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mcp/filesystem-ultra/core"
@@ -40,7 +42,8 @@ func multiEditStructured(path string, r *core.MultiEditResult) map[string]any {
 	return m
 }
 
-// registerBatchTools registers multi_edit, batch_operations, backup
+// registerBatchTools registers multi_edit, batch_operations, project_replace,
+// backup
 func registerBatchTools(reg *toolRegistry) {
 	engine := reg.engine
 
@@ -65,8 +68,10 @@ func registerBatchTools(reg *toolRegistry) {
 		mcp.WithBoolean("dry_run", mcp.Description("Preview changes without writing to disk. Default: false.")),
 		mcp.WithString("diff_format", mcp.Description("Controls how the aggregate diff of the whole batch is rendered (parity with edit_file): \"\"/\"auto\" (default): full diff when small, else summary with anchors; \"full\": complete unified diff; \"summary\": per-hunk ranges + anchor lines; \"stat\": just \"+added -removed\"; \"none\": no diff (previous behaviour).")),
 		mcp.WithString("expected_hash", mcp.Description("Optional. The content_hash returned by the last full read_file (range and batch reads don't return it). If the file's current hash doesn't match, the multi_edit is rejected so the model can re-read first. Same OCC token as edit_file (Improvement B3), atomic over the whole batch.")),
+		mcp.WithString("confirm_token", mcp.Description("Only when the server runs with --confirm-tokens: the one-time token returned by a blocked HIGH/CRITICAL multi_edit. Re-issue the identical batch with it to execute.")),
+		formatParam(),
 	)
-	reg.addTool(multiEditTool, auditWrap(engine, "multi_edit", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	reg.addTool(multiEditTool, auditWrap(engine, "multi_edit", jsonResponses(engine, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		path, err := request.RequireString("path")
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Invalid path: %v", err)), nil
@@ -160,6 +165,9 @@ func registerBatchTools(reg *toolRegistry) {
 			if eh, ok := args["expected_hash"].(string); ok {
 				expectedHash = eh
 			}
+			if ct, ok := args["confirm_token"].(string); ok {
+				ctx = core.WithConfirmToken(ctx, ct)
+			}
 		}
 
 		// Execute multi-edit
@@ -180,6 +188,10 @@ func registerBatchTools(reg *toolRegistry) {
 		}
 		result, err := engine.MultiEdit(ctx, path, edits, force, dryRun, tolerantWhitespace, expectedHash)
 		if err != nil {
+			var confirmErr *core.ConfirmationRequiredError
+			if errors.As(err, &confirmErr) {
+				return mcp.NewToolResultError(confirmErr.Error()), nil
+			}
 			// Bug #27: If result is non-nil, this is an atomic rollback — include backup_id and details
 			if result != nil && result.BackupID != "" {
 				errMsg := fmt.Sprintf("Multi-edit ROLLED BACK (file unchanged): %v\n", err)
@@ -384,10 +396,10 @@ func registerBatchTools(reg *toolRegistry) {
 		}
 
 		return mcp.NewToolResultStructured(attachMessage(attachParentBackup(multiEditStructured(path, result), engine, result.BackupID), sb.String()), sb.String()), nil
-	}))
+	})))
 
 	// ============================================================================
-	// 13. batch_operations — Batch operations (enhanced: + pipeline_json + rename_json)
+	// 13. batch_operations — Batch operations (enhanced: + pipeline_json + rename_json + organize_json)
 	// ============================================================================
 	batchOpsTool := mcp.NewTool("batch_operations",
 		mcp.WithTitleAnnotation("Batch Operations"),
@@ -397,16 +409,22 @@ func registerBatchTools(reg *toolRegistry) {
 		mcp.WithDescription("batch_operations — Execute atomic file operations (write, edit, search_and_replace, copy, move, delete, create_dir, extract) on the real host filesystem (the user's actual disk, e.g. C:\\, D:\\, /mnt/...). "+
 			"extract moves lines [start_line,end_line] from source to destination atomically (bytes written == bytes deleted). "+
 			"Use batch_operations for ALL batch/atomic operations on the host disk — never use the runtime's built-in tools for host paths. "+
-			"Supports pipelines, rename, dry_run, rollback on error. Params: request_json, pipeline_json, or rename_json. "+
+			"Supports pipelines, rename, folder organizing, dry_run, rollback on error. Params: request_json, pipeline_json, rename_json, or organize_json. "+
 			"Related: edit_file (single edit), multi_edit (multi-edit one file), search_files, backup."),
-		mcp.WithString("request_json", mcp.Description("JSON with operations array and options. Fields: operations (array), atomic (bool), create_backup (bool), validate_only (bool). Operation types: write, edit, search_and_replace, copy, move, delete, create_dir, extract. extract fields: source, destination, start_line, end_line, append (bool).")),
-		mcp.WithString("pipeline_json", mcp.Description("JSON-encoded pipeline definition with name, steps, and optional flags (dry_run, force, stop_on_error, create_backup, verbose, parallel)")),
+		mcp.WithString("request_json", mcp.Description("JSON with operations array and options. Fields: operations (array), atomic (bool), continue_on_error (bool: independent operations keep running after a failure, operations sharing a \"group\" stop together; undo the failed groups afterwards with rollback_batch(backup_id); exclusive with atomic), max_matches (int, default 100: files a path/source glob may expand to), create_backup (bool, default true: one backup of every file the batch modifies, returned as backup_id), validate_only (bool: replays the batch without writing and reports per operation expected replacement counts, overwrites and conflicts). Operation types: write, append, edit, search_and_replace, replace_nth, copy, move, delete, create_dir, extract. path (delete, edit, search_and_replace, replace_nth, append) and source (copy, move; destination must be a directory) accept globs like \"gen/**/*.tmp\", expanded inside the allowed paths — run with validate_only:true to review the files. Any operation may set group (string) for continue_on_error. append fields: path, content (file created if missing). replace_nth fields: path, pattern, replacement, occurrence (1=first, -1=last). extract fields: source, destination, start_line, end_line, append (bool). Example: {\"operations\":[{\"type\":\"append\",\"path\":\"CHANGELOG.md\",\"content\":\"- fix\\n\"},{\"type\":\"replace_nth\",\"path\":\"main.go\",\"pattern\":\"TODO\",\"replacement\":\"DONE\",\"occurrence\":-1}],\"atomic\":true}")),
+		mcp.WithString("pipeline_json", mcp.Description("JSON-encoded pipeline definition with name, steps, and optional flags (dry_run, force, stop_on_error, create_backup, verbose, parallel) and variables for ${name} placeholders")),
 		mcp.WithString("rename_json", mcp.Description("JSON with batch rename parameters. Fields: path, mode, find, replace, prefix, suffix, pattern, extension, start_number, padding, recursive, file_pattern, preview, case_sensitive")),
+		mcp.WithString("organize_json", mcp.Description(`JSON that triages a folder (Downloads, exports): each file directly inside path moves to the destination of the first rule it matches (match_glob on the name, older_than on the mtime, or both; subdirectories are left alone). `+
+			`Fields: path, rules (ordered; destinations relative to path or absolute), dry_run (default: true, lists every planned move), on_conflict (rename: default, name-1.ext; overwrite: backs up the replaced files first; skip). `+
+			`Example: {"path":"/home/me/Downloads","rules":[{"match_glob":"*.png","destination":"images"},{"older_than":"30d","destination":"archive"}],"dry_run":false}`)),
+		formatParam(),
 	)
 	reg.addTool(batchOpsTool, auditWrap(engine, "batch_operations", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		pipelineJSON := ""
 		renameJSON := ""
+		organizeJSON := ""
 		requestJSON := ""
+		asJSON := wantJSON(engine, request)
 
 		if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
 			if pj, ok := args["pipeline_json"].(string); ok {
@@ -415,6 +433,9 @@ func registerBatchTools(reg *toolRegistry) {
 			if rj, ok := args["rename_json"].(string); ok {
 				renameJSON = rj
 			}
+			if oj, ok := args["organize_json"].(string); ok {
+				organizeJSON = oj
+			}
 			if rq, ok := args["request_json"].(string); ok {
 				requestJSON = rq
 			}
@@ -426,20 +447,11 @@ func registerBatchTools(reg *toolRegistry) {
 			if err := json.Unmarshal([]byte(pipelineJSON), &pipelineReq); err != nil {
 				return mcp.NewToolResultError(fmt.Sprintf("Invalid pipeline JSON: %v", err)), nil
 			}
-
-			executor := core.NewPipelineExecutor(engine)
-			result, err := executor.Execute(ctx, pipelineReq)
-			if err != nil && result == nil {
-				return mcp.NewToolResultError(fmt.Sprintf("Pipeline execution failed: %v", err)), nil
-			}
-
-			responseText := formatPipelineResult(result, engine.IsCompactMode())
-
-			if !result.Success {
-				return mcp.NewToolResultError(responseText), nil
+			if asJSON && pipelineReq.Output == "" {
+				pipelineReq.Output = "json"
 			}
 
-			return mcp.NewToolResultText(responseText), nil
+			return executePipelineTool(ctx, engine, pipelineReq, engine.IsCompactMode(), progressToken(request)), nil
 		}
 
 		// If rename_json is provided, dispatch to batch rename
@@ -455,15 +467,46 @@ func registerBatchTools(reg *toolRegistry) {
 			}
 
 			resultText := core.FormatBatchRenameResult(result, engine.IsCompactMode())
+			if asJSON {
+				data, err := json.MarshalIndent(result, "", "  ")
+				if err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("Failed to encode response: %v", err)), nil
+				}
+				resultText = string(data)
+			}
 			if !result.Success && !result.Preview {
 				return mcp.NewToolResultError(resultText), nil
 			}
 			return mcp.NewToolResultText(resultText), nil
 		}
 
+		// If organize_json is provided, dispatch to the rule-based folder organizer
+		if organizeJSON != "" {
+			const example = `batch_operations(organize_json:'{"path":"/home/me/Downloads","rules":[{"match_glob":"*.png","destination":"images"}]}')`
+			var organizeReq core.OrganizeRequest
+			if err := json.Unmarshal([]byte(organizeJSON), &organizeReq); err != nil {
+				return usageError(fmt.Sprintf("invalid organize_json: %v", err), example), nil
+			}
+			if organizeReq.Path == "" {
+				return usageError("organize_json needs path", example), nil
+			}
+			dryRun := organizeReq.DryRun == nil || *organizeReq.DryRun
+			res, err := engine.OrganizeDirectory(ctx, organizeReq.Path, organizeReq.Rules, dryRun, organizeReq.OnConflict)
+			if err != nil {
+				return mcp.NewToolResultError(formatToolError(err)), nil
+			}
+			if !dryRun && res.Moved > 0 {
+				core.MarkMutating(ctx)
+			}
+			if asJSON {
+				return jsonResult(res), nil
+			}
+			return chunkedText(engine, "batch_operations", core.FormatOrganizeResult(res, engine.IsCompactMode()), responseLimit(engine)), nil
+		}
+
 		// Default: existing batch operations via request_json
 		if requestJSON == "" {
-			return mcp.NewToolResultError("One of request_json, pipeline_json, or rename_json is required"), nil
+			return mcp.NewToolResultError("One of request_json, pipeline_json, rename_json, or organize_json is required"), nil
 		}
 
 		var batchReq core.BatchRequest
@@ -479,8 +522,18 @@ func registerBatchTools(reg *toolRegistry) {
 		batchManager.SetBackupManager(engine.GetBackupManager())
 		batchManager.SetEngine(engine)
 		result := batchManager.ExecuteBatch(batchReq)
+		if result.CompletedOps > 0 && !result.ValidationOnly {
+			core.MarkMutating(ctx)
+		}
 
 		resultText := formatBatchResult(result)
+		if asJSON {
+			data, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to encode response: %v", err)), nil
+			}
+			resultText = string(data)
+		}
 
 		if !result.Success {
 			return mcp.NewToolResultError(resultText), nil
@@ -603,6 +656,9 @@ func registerBatchTools(reg *toolRegistry) {
 			if result.RiskWarning != "" {
 				msg += " | " + strings.TrimPrefix(result.RiskWarning, "⚠️ ")
 			}
+			if result.ProtectedSkipped > 0 {
+				msg += fmt.Sprintf(" | %d write-protected skipped", result.ProtectedSkipped)
+			}
 			return mcp.NewToolResultText(msg), nil
 		}
 
@@ -620,6 +676,9 @@ func registerBatchTools(reg *toolRegistry) {
 		}
 		sb.WriteString(fmt.Sprintf("Files changed: %d\n", result.FilesChanged))
 		sb.WriteString(fmt.Sprintf("Total replacements: %d\n", result.TotalReplaced))
+		if result.ProtectedSkipped > 0 {
+			sb.WriteString(fmt.Sprintf("Write-protected files skipped: %d\n", result.ProtectedSkipped))
+		}
 		if result.BackupID != "" {
 			sb.WriteString(fmt.Sprintf("✓ Backup/UNDO: %s\n", result.BackupID))
 		}
@@ -652,11 +711,14 @@ func registerBatchTools(reg *toolRegistry) {
 		mcp.WithReadOnlyHintAnnotation(false),
 		mcp.WithDestructiveHintAnnotation(false),
 		mcp.WithIdempotentHintAnnotation(false),
-		mcp.WithDescription("backup — Manage backups, restore, and undo. Actions: list, info, compare, cleanup, restore, undo_last, undo_chain, list_trash, restore_trash, purge_trash. "+
-			"Auto-created before every edit_file/multi_edit. Soft-deleted files (delete_file) are managed via list_trash/restore_trash/purge_trash when --backup-dir is set. "+
+		mcp.WithDescription("backup — Manage backups, restore, and undo. Actions: list, info, compare, cleanup, restore, undo_last, undo_chain, rollback_batch, list_trash, restore_trash, purge_trash (alias empty_trash), trash_status. "+
+			"Auto-created before every edit_file/multi_edit. Soft-deleted files (delete_file) are managed via list_trash/restore_trash/purge_trash when --backup-dir or --trash-dir is set; "+
+			"trash_status shows which trash root each allowed root uses (the first of --trash-dir, <backup-dir>/trash, <root>/.mcp-trash on the same volume). "+
+			"purge_trash previews by default, listing the entries and the space it would free, and --trash-max-age runs it at startup. "+
+			"rollback_batch reverts only the failed groups of a continue_on_error batch_operations call from its journal: modified files are restored, created files removed and moves undone; paths also touched by a successful group are kept, and a batch can be rolled back once. "+
 			"Related: edit_file, batch_operations, analyze_operation, delete_file."),
-		mcp.WithString("action", mcp.Description("Action: list (default), info, compare, cleanup, restore, undo_last, undo_chain, list_trash, restore_trash, purge_trash")),
-		mcp.WithString("backup_id", mcp.Description("Backup ID (required for info, compare, restore)")),
+		mcp.WithString("action", mcp.Description("Action: list (default), info, compare, cleanup, restore, undo_last, undo_chain, rollback_batch, list_trash, restore_trash, purge_trash (alias empty_trash), trash_status")),
+		mcp.WithString("backup_id", mcp.Description("Backup ID (required for info, compare, restore, rollback_batch)")),
 		mcp.WithString("sd_id", mcp.Description("Soft-delete ID (required for restore_trash)")),
 		mcp.WithString("file_path", mcp.Description("File path for compare or selective restore")),
 		mcp.WithNumber("limit", mcp.Description("Max backups to return for list (default: 20)")),
@@ -664,8 +726,9 @@ func registerBatchTools(reg *toolRegistry) {
 		mcp.WithString("filter_path", mcp.Description("Filter by file path (substring match)")),
 		mcp.WithNumber("newer_than_hours", mcp.Description("Only backups newer than N hours")),
 		mcp.WithNumber("older_than_days", mcp.Description("For cleanup/purge_trash: delete entries older than N days (default: 7)")),
-		mcp.WithBoolean("dry_run", mcp.Description("For cleanup/restore/purge_trash: preview without executing (default: true for cleanup/purge_trash, false for restore)")),
+		mcp.WithBoolean("dry_run", mcp.Description("For cleanup/restore/rollback_batch/purge_trash: preview without executing (default: true for cleanup/purge_trash, false for restore/rollback_batch)")),
 		mcp.WithBoolean("preview", mcp.Description("For restore: show diff without restoring (default: false)")),
+		formatParam(),
 	)
 	reg.addTool(backupTool, auditWrap(engine, "backup", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		if engine.GetBackupManager() == nil {
@@ -706,29 +769,11 @@ func registerBatchTools(reg *toolRegistry) {
 				return mcp.NewToolResultError(fmt.Sprintf("Failed to list backups: %v", err)), nil
 			}
 
-			var output strings.Builder
-			output.WriteString(fmt.Sprintf("Available Backups (%d)\n", len(backups)))
-			output.WriteString("---\n\n")
-
-			for _, backup := range backups {
-				output.WriteString(fmt.Sprintf("# %s\n", backup.BackupID))
-				output.WriteString(fmt.Sprintf("   Time: %s (%s)\n", backup.Timestamp.Format("2006-01-02 15:04:05"), core.FormatAge(backup.Timestamp)))
-				output.WriteString(fmt.Sprintf("   Operation: %s\n", backup.Operation))
-				output.WriteString(fmt.Sprintf("   Files: %d (%s)\n", len(backup.Files), core.FormatSize(backup.TotalSize)))
-				if backup.UserContext != "" {
-					output.WriteString(fmt.Sprintf("   Context: %s\n", backup.UserContext))
-				}
-				output.WriteString("\n")
+			listing := core.BackupListResponse{Count: len(backups), Backups: backups}
+			if wantJSON(engine, request) {
+				return jsonResult(listing), nil
 			}
-
-			if len(backups) == 0 {
-				output.WriteString("No backups found matching the criteria.\n")
-			} else {
-				output.WriteString("Use backup(action:\"restore\", backup_id:\"...\") to restore files\n")
-				output.WriteString("Use backup(action:\"info\", backup_id:\"...\") for detailed information\n")
-			}
-
-			return mcp.NewToolResultText(output.String()), nil
+			return mcp.NewToolResultText(core.FormatBackupList(listing)), nil
 
 		case "info":
 			backupID, err := request.RequireString("backup_id")
@@ -786,7 +831,7 @@ func registerBatchTools(reg *toolRegistry) {
 				return mcp.NewToolResultError(fmt.Sprintf("Comparison failed: %v", err)), nil
 			}
 
-			return mcp.NewToolResultText(diff), nil
+			return chunkedText(engine, "backup_compare", diff, responseLimit(engine)), nil
 
 		case "cleanup":
 			olderThanDays := 7
@@ -805,17 +850,30 @@ func registerBatchTools(reg *toolRegistry) {
 			if err != nil {
 				return mcp.NewToolResultError(fmt.Sprintf("Cleanup failed: %v", err)), nil
 			}
+			if !dryRun {
+				core.MarkMutating(ctx)
+			}
+
+			// Free space of the backup filesystem, when the platform reports it
+			diskFree := ""
+			if disk, err := core.DiskSpaceOf(engine.GetBackupManager().GetBackupDir()); err == nil {
+				verb := "has"
+				if !dryRun {
+					verb = "now has"
+				}
+				diskFree = fmt.Sprintf(" (disk %s %s free)", verb, core.FormatSize(disk.Free))
+			}
 
 			var output strings.Builder
 			if dryRun {
 				output.WriteString("Dry Run - Preview of cleanup\n\n")
 				output.WriteString(fmt.Sprintf("Would delete: %d backup(s)\n", deletedCount))
-				output.WriteString(fmt.Sprintf("Would free: %s\n\n", core.FormatSize(freedSpace)))
+				output.WriteString(fmt.Sprintf("Would free: %s%s\n\n", core.FormatSize(freedSpace), diskFree))
 				output.WriteString("Run with dry_run: false to actually delete backups\n")
 			} else {
 				output.WriteString("Cleanup completed\n\n")
 				output.WriteString(fmt.Sprintf("Deleted: %d backup(s)\n", deletedCount))
-				output.WriteString(fmt.Sprintf("Freed: %s\n", core.FormatSize(freedSpace)))
+				output.WriteString(fmt.Sprintf("Freed: %s%s\n", core.FormatSize(freedSpace), diskFree))
 			}
 
 			return mcp.NewToolResultText(output.String()), nil
@@ -851,7 +909,7 @@ func registerBatchTools(reg *toolRegistry) {
 					return mcp.NewToolResultError(fmt.Sprintf("Failed to compare: %v", err)), nil
 				}
 
-				return mcp.NewToolResultText(fmt.Sprintf("Preview - Changes to be restored:\n\n%s", diff)), nil
+				return chunkedText(engine, "backup_compare", "Preview - Changes to be restored:\n\n"+diff, responseLimit(engine)), nil
 			}
 
 			// Dry run for full restore preview
@@ -879,6 +937,7 @@ func registerBatchTools(reg *toolRegistry) {
 			if err != nil {
 				return mcp.NewToolResultError(fmt.Sprintf("Failed to restore: %v", err)), nil
 			}
+			core.MarkMutating(ctx)
 
 			var output strings.Builder
 			output.WriteString("Restore completed successfully\n\n")
@@ -921,6 +980,7 @@ func registerBatchTools(reg *toolRegistry) {
 				if currentBackupID != "" {
 					restoredFiles, prevID, hasMore, err := engine.GetBackupManager().RestorePreviousInChain(currentBackupID, targetFile)
 					if err == nil {
+						core.MarkMutating(ctx)
 						// Preview/dry-run: show what would happen without executing
 						if isDryRun || isPreview {
 							var output strings.Builder
@@ -994,6 +1054,7 @@ func registerBatchTools(reg *toolRegistry) {
 			if err != nil {
 				return mcp.NewToolResultError(fmt.Sprintf("Failed to restore: %v", err)), nil
 			}
+			core.MarkMutating(ctx)
 
 			var output strings.Builder
 			output.WriteString(fmt.Sprintf("UNDO completed — restored backup %s\n\n", lastBackup.BackupID))
@@ -1088,7 +1149,14 @@ func registerBatchTools(reg *toolRegistry) {
 				output.WriteString(fmt.Sprintf("# %s\n", entry.SDID))
 				output.WriteString(fmt.Sprintf("   Time: %s (%s)\n", entry.Timestamp.Format("2006-01-02 15:04:05"), core.FormatAge(entry.Timestamp)))
 				output.WriteString(fmt.Sprintf("   Original: %s\n", entry.OriginalPath))
-				output.WriteString(fmt.Sprintf("   Size: %s\n", core.FormatSize(entry.Size)))
+				if entry.IsDir {
+					output.WriteString(fmt.Sprintf("   Size: %s (directory, %d files)\n", core.FormatSize(entry.Size), entry.Files))
+				} else {
+					output.WriteString(fmt.Sprintf("   Size: %s\n", core.FormatSize(entry.Size)))
+				}
+				if entry.Source != "" {
+					output.WriteString(fmt.Sprintf("   Deleted by: %s\n", entry.Source))
+				}
 				if entry.Hash != "" {
 					output.WriteString(fmt.Sprintf("   Hash: %s\n", entry.Hash[:12]))
 				}
@@ -1097,7 +1165,7 @@ func registerBatchTools(reg *toolRegistry) {
 			if len(entries) == 0 {
 				output.WriteString("No trash entries found.\n")
 				if engine.GetBackupManager().GetBackupDir() == "" {
-					output.WriteString("(Trash is only populated when --backup-dir is configured.)\n")
+					output.WriteString("(Trash is only populated when --backup-dir or --trash-dir is configured.)\n")
 				}
 			} else {
 				output.WriteString("Use backup(action:\"restore_trash\", sd_id:\"...\") to restore\n")
@@ -1117,6 +1185,7 @@ func registerBatchTools(reg *toolRegistry) {
 			if err != nil {
 				return mcp.NewToolResultError(fmt.Sprintf("Failed to restore: %v", err)), nil
 			}
+			core.MarkMutating(ctx)
 
 			var output strings.Builder
 			output.WriteString("Trash restore completed successfully\n\n")
@@ -1124,7 +1193,7 @@ func registerBatchTools(reg *toolRegistry) {
 			output.WriteString(fmt.Sprintf("File: %s\n", restoredPath))
 			return mcp.NewToolResultText(output.String()), nil
 
-		case "purge_trash":
+		case "purge_trash", "empty_trash":
 			// Permanently delete trash entries older than olderThanDays.
 			olderThanDays := 7
 			dryRun := true
@@ -1137,26 +1206,73 @@ func registerBatchTools(reg *toolRegistry) {
 				}
 			}
 
-			deletedCount, freedSpace, err := engine.GetBackupManager().PurgeTrash(olderThanDays, dryRun)
+			res, err := engine.GetBackupManager().PurgeTrashOlderThan(time.Duration(olderThanDays)*24*time.Hour, dryRun)
 			if err != nil {
 				return mcp.NewToolResultError(fmt.Sprintf("Purge trash failed: %v", err)), nil
 			}
+			if !dryRun {
+				core.MarkMutating(ctx)
+			}
+			if wantJSON(engine, request) {
+				return jsonResult(res), nil
+			}
 
 			var output strings.Builder
 			if dryRun {
 				output.WriteString("Dry Run - Preview of trash purge\n\n")
-				output.WriteString(fmt.Sprintf("Would delete: %d trash entry/entries\n", deletedCount))
-				output.WriteString(fmt.Sprintf("Would free: %s\n\n", core.FormatSize(freedSpace)))
-				output.WriteString("Run with dry_run: false to actually purge trash\n")
+				output.WriteString(fmt.Sprintf("Would delete: %d trash entry/entries\n", len(res.Entries)))
+				output.WriteString(fmt.Sprintf("Would free: %s\n", core.FormatSize(res.FreedBytes)))
 			} else {
 				output.WriteString("Trash purge completed\n\n")
-				output.WriteString(fmt.Sprintf("Deleted: %d trash entry/entries\n", deletedCount))
-				output.WriteString(fmt.Sprintf("Freed: %s\n", core.FormatSize(freedSpace)))
+				output.WriteString(fmt.Sprintf("Deleted: %d trash entry/entries\n", len(res.Entries)))
+				output.WriteString(fmt.Sprintf("Freed: %s\n", core.FormatSize(res.FreedBytes)))
+			}
+			for i, info := range res.Entries {
+				if i == 20 {
+					output.WriteString(fmt.Sprintf("   ... and %d more\n", len(res.Entries)-i))
+					break
+				}
+				output.WriteString(fmt.Sprintf("   - %s (%s, deleted %s)\n", info.OriginalPath, core.FormatSize(info.Size), core.FormatAge(info.Timestamp)))
+			}
+			if dryRun {
+				output.WriteString("\nRun with dry_run: false to actually purge trash\n")
 			}
 			return mcp.NewToolResultText(output.String()), nil
 
+		case "trash_status":
+			status, err := engine.TrashStatus()
+			if err != nil {
+				return mcp.NewToolResultError(formatToolError(err)), nil
+			}
+			if wantJSON(engine, request) {
+				return jsonResult(status), nil
+			}
+			return mcp.NewToolResultText(core.FormatTrashStatus(status)), nil
+
+		case "rollback_batch":
+			backupID, err := request.RequireString("backup_id")
+			if err != nil || strings.TrimSpace(backupID) == "" {
+				return usageError("backup_id is required", `backup(action:"rollback_batch", backup_id:"20260101-120000-abcd1234")`), nil
+			}
+			batchManager := core.NewBatchOperationManager("", 10)
+			batchManager.SetBackupManager(engine.GetBackupManager())
+			batchManager.SetEngine(engine)
+			dryRun := request.GetBool("dry_run", false)
+			result, err := batchManager.RollbackBatch(strings.TrimSpace(backupID), dryRun)
+			if err != nil {
+				return mcp.NewToolResultError(formatToolError(err)), nil
+			}
+			if !dryRun {
+				core.MarkMutating(ctx)
+			}
+			resultText := formatBatchRollbackResult(result)
+			if len(result.Errors) > 0 {
+				return mcp.NewToolResultError(resultText), nil
+			}
+			return mcp.NewToolResultText(resultText), nil
+
 		default:
-			return mcp.NewToolResultError(fmt.Sprintf("Unknown action: %s. Valid: list, info, compare, cleanup, restore, undo_last, undo_chain, list_trash, restore_trash, purge_trash", action)), nil
+			return mcp.NewToolResultError(fmt.Sprintf("Unknown action: %s. Valid: list, info, compare, cleanup, restore, undo_last, undo_chain, rollback_batch, list_trash, restore_trash, purge_trash, empty_trash, trash_status", action)), nil
 		}
 	}))
 }
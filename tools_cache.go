@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mcp/filesystem-ultra/core"
+)
+
+// registerCacheTools registers the cache tool
+func registerCacheTools(reg *toolRegistry) {
+	engine := reg.engine
+
+	// ============================================================================
+	// cache — Read cache inspection and flushing (consolidated: stats + control)
+	// ============================================================================
+	cacheTool := mcp.NewTool("cache",
+		mcp.WithTitleAnnotation("Cache"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithDescription("cache — The server's read cache. Actions: stats, clear, invalidate_path, invalidate_prefix. "+
+			"stats shows entries and bytes per type (file, dir, meta), used vs capacity, hit/miss counts, evictions, stale entries dropped, and the largest entries (server_info stats only shows the hit rate). "+
+			"clear drops everything, invalidate_path one file or directory listing plus its parent listing, invalidate_prefix every entry under a path prefix. "+
+			"Files on disk are never touched; the next read goes to disk. Related: server_info."),
+		mcp.WithString("action", mcp.Description("Action: stats (default), clear, invalidate_path, invalidate_prefix")),
+		mcp.WithString("path", mcp.Description("Path (invalidate_path) or path prefix (invalidate_prefix)")),
+		mcp.WithNumber("top", mcp.Description("For stats: how many of the largest entries to list (default: 10, 0 = none)")),
+		mcp.WithString("output", mcp.Description("For stats: output format \"text\" (default) or \"json\"")),
+	)
+	reg.addTool(cacheTool, auditWrap(engine, "cache", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		path := request.GetString("path", "")
+
+		switch action := request.GetString("action", "stats"); action {
+		case "stats", "":
+			args, _ := request.Params.Arguments.(map[string]interface{})
+			top := parseIntArg(args, "top", 10)
+			output, _ := args["output"].(string)
+			if output != "" && output != "text" && output != "json" {
+				return usageError(fmt.Sprintf("invalid output %q. Valid: text, json", output), `cache(action:"stats", output:"json")`), nil
+			}
+
+			report := engine.GetCacheReport(top)
+			if output == "json" {
+				data, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("Error: %v", err)), nil
+				}
+				return mcp.NewToolResultText(string(data)), nil
+			}
+			return mcp.NewToolResultText(core.FormatCacheReport(report, engine.IsCompactMode())), nil
+
+		case "clear":
+			dropped := engine.ClearCache()
+			return mcp.NewToolResultText(fmt.Sprintf("Cache cleared: %d entries dropped", dropped)), nil
+
+		case "invalidate_path":
+			if path == "" {
+				return usageError("path is required for invalidate_path", `cache(action:"invalidate_path", path:"/project/main.go")`), nil
+			}
+			key := engine.InvalidateCachePath(path)
+			return mcp.NewToolResultText(fmt.Sprintf("Cache entries for %s invalidated", key)), nil
+
+		case "invalidate_prefix":
+			if path == "" {
+				return usageError("path is required for invalidate_prefix", `cache(action:"invalidate_prefix", path:"/project/src")`), nil
+			}
+			removed, prefix := engine.InvalidateCachePrefix(path)
+			return mcp.NewToolResultText(fmt.Sprintf("Invalidated %d cache entries under %s", removed, prefix)), nil
+
+		default:
+			return usageError(fmt.Sprintf("invalid action %q. Valid: stats, clear, invalidate_path, invalidate_prefix", action), `cache(action:"stats")`), nil
+		}
+	}))
+}
@@ -4,13 +4,16 @@
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"hash/fnv"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -75,6 +78,9 @@ type toolRegistry struct {
 	// addTool keeps the 17 existing call sites source-compatible.
 	toolExamples map[string][]string
 
+	// filter is the --enable-tools / --disable-tools selection (nil = all)
+	filter *toolFilter
+
 	// Named handlers needed by alias registration
 	readFileHandler    toolHandler
 	writeFileHandler   toolHandler
@@ -87,6 +93,9 @@ type toolRegistry struct {
 // The trailing examples... is optional and only consumed by help(tool:"<name>").
 // All 17 existing call sites pass no examples, so this stays source-compatible.
 func (r *toolRegistry) addTool(tool mcp.Tool, handler toolHandler, examples ...string) {
+	if !r.filter.admit(tool.Name) {
+		return
+	}
 	tool = applyExperimentalPolicy(tool)
 	r.server.AddTool(tool, handler)
 	r.handlers[tool.Name] = handler
@@ -100,11 +109,16 @@ func (r *toolRegistry) addTool(tool mcp.Tool, handler toolHandler, examples ...s
 
 // registerTools registers all 16 consolidated filesystem tools + aliases + super-tool + help
 func registerTools(s *server.MCPServer, engine *core.UltraFastEngine) error {
+	filter, err := newToolFilter(engine.GetConfig().EnableTools, engine.GetConfig().DisableTools)
+	if err != nil {
+		return err
+	}
 	reg := &toolRegistry{
 		server:         s,
 		engine:         engine,
 		handlers:       make(map[string]toolHandler),
 		regexTransform: core.NewRegexTransformer(engine),
+		filter:         filter,
 	}
 
 	registerCoreTools(reg)
@@ -114,13 +128,23 @@ func registerTools(s *server.MCPServer, engine *core.UltraFastEngine) error {
 	registerPlatformTools(reg)
 	registerGitTools(reg)
 	registerMinifyTools(reg)
+	registerCacheTools(reg)
+	registerHookTools(reg)
+	registerPipelineTools(reg)
+	registerArchiveTools(reg)
 	// Aliases disabled: duplicates add noise to discovery, hurt token budget.
 	// registerAliases(reg)
 	// registerClaudeCodeAliases(reg)
 	// registerSuperTool(reg)
 	registerHelpTool(reg)
 
-	log.Printf("Registered 20 tools (18 core + git + help + minify_js) for v%s — aliases disabled", serverVersion)
+	if len(filter.disabled) > 0 {
+		log.Printf("Tool selection: %d tools left out by --enable-tools/--disable-tools (see server_info tools)", len(filter.disabled))
+	}
+	if unmatched := filter.unmatched(); len(unmatched) > 0 {
+		log.Printf("Warning: tool patterns matching no tool: %s", strings.Join(unmatched, ", "))
+	}
+	log.Printf("Registered %d tools for v%s — aliases disabled", len(s.ListTools()), serverVersion)
 	return nil
 }
 
@@ -136,15 +160,34 @@ func contentHashBytes(raw []byte) string {
 // (they hash os.ReadFile(path)). It reads the whole file from disk so that
 // PARTIAL reads (range, head/tail, base64) can still surface a valid
 // concurrency token without forcing the caller to pull the entire file into
-// its context (point 3: content_hash on range reads). The disk read is local
-// and bounded; only the partial body is returned to the consumer, so the token
-// cost stays small. Returns ("", false) if the file cannot be read.
+// its context (point 3: content_hash on range reads). The file is streamed
+// through the hash, never held whole; only the partial body is returned to the
+// consumer, so the token cost stays small. Returns ("", false) if the file
+// cannot be read.
 func computeFileOCCHash(path string) (string, bool) {
-	raw, err := os.ReadFile(path)
+	f, err := os.Open(path)
 	if err != nil {
 		return "", false
 	}
-	return contentHashBytes(raw), true
+	defer f.Close()
+	h := fnv.New32a()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", false
+	}
+	return fmt.Sprintf("%08x", h.Sum32()), true
+}
+
+// readWholeOrView reads path for a batch read_file: whole, or as a large-file
+// view when it is over --binary-threshold
+func readWholeOrView(ctx context.Context, engine *core.UltraFastEngine, path string) (string, error) {
+	if info, err := os.Stat(path); err == nil && !info.IsDir() && engine.OverBinaryThreshold(info.Size()) {
+		view, err := engine.ReadLargeFileView(ctx, path, core.LargeViewHeadLines, core.LargeViewTailLines)
+		if err != nil {
+			return "", err
+		}
+		return core.FormatLargeFileView(view), nil
+	}
+	return engine.ReadFileContent(ctx, path)
 }
 
 // verifyOnDiskWrite independently reopens the final host file after the engine
@@ -161,11 +204,90 @@ func verifyOnDiskWrite(engine *core.UltraFastEngine, path string) (verifiedPath
 		}
 	}
 
-	raw, err := os.ReadFile(normPath)
+	// Streamed, so verifying a write over --binary-threshold does not read it whole
+	f, err := os.Open(normPath)
 	if err != nil {
 		return verifiedPath, 0, "", false
 	}
-	return verifiedPath, len(raw), contentHashBytes(raw), true
+	defer f.Close()
+	h := fnv.New32a()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return verifiedPath, 0, "", false
+	}
+	return verifiedPath, int(n), fmt.Sprintf("%08x", h.Sum32()), true
+}
+
+// handleWriteSession runs write_file(session: begin|append|commit|abort),
+// which writes path from chunks sent one call at a time
+func handleWriteSession(ctx context.Context, engine *core.UltraFastEngine, request mcp.CallToolRequest, path, action string) *mcp.CallToolResult {
+	id := strings.TrimSpace(request.GetString("session_id", ""))
+	if action != "begin" && id == "" {
+		return usageError("session_id is required for session:\""+action+"\"",
+			`write_file(path:"big.sql", session:"append", session_id:"ws_...", chunk:"...", index:0)`)
+	}
+	sessionStructured := func(info *core.WriteSessionInfo, msg string) *mcp.CallToolResult {
+		sc := writeStructured(info.Path, int(info.Bytes), "", false)
+		sc["session_id"], sc["chunks"] = info.SessionID, info.Chunks
+		return mcp.NewToolResultStructured(attachMessage(sc, msg), msg)
+	}
+
+	switch action {
+	case "begin":
+		info, err := engine.BeginWriteSession(ctx, path)
+		if err != nil {
+			return mcp.NewToolResultError(formatToolError(err))
+		}
+		msg := fmt.Sprintf("SESSION %s → %s | send chunks with session:\"append\", index 0, 1, ... then session:\"commit\" (idle timeout %s)",
+			info.SessionID, info.Path, time.Until(info.ExpiresAt).Round(time.Second))
+		return sessionStructured(info, msg)
+
+	case "append":
+		chunk, ok := request.GetArguments()["chunk"].(string)
+		if !ok {
+			return usageError("chunk is required for session:\"append\"",
+				`write_file(path:"big.sql", session:"append", session_id:"ws_...", chunk:"...", index:0)`)
+		}
+		info, err := engine.AppendWriteChunk(ctx, id, chunk, request.GetInt("index", -1))
+		if err != nil {
+			return mcp.NewToolResultError(formatToolError(err))
+		}
+		msg := fmt.Sprintf("SESSION %s | %d chunks, %s received | next index %d", info.SessionID, info.Chunks, core.FormatSize(info.Bytes), info.Chunks)
+		return sessionStructured(info, msg)
+
+	case "commit":
+		res, err := engine.CommitWriteSession(ctx, id, request.GetString("expected_sha256", ""))
+		if err != nil {
+			return mcp.NewToolResultError(formatToolError(err) + hookNotesLine(ctx, engine))
+		}
+		core.MarkMutating(ctx)
+		verifiedPath, bytesWritten, contentHash, verified := verifyOnDiskWrite(engine, res.Path)
+		if verified {
+			core.RecordWriteHash(res.Path, contentHash)
+		}
+		msg := fmt.Sprintf("WRITTEN %s %s | %dB | %d chunks | sha256 %s", diskPrefix(verifiedPath), verifiedPath, bytesWritten, res.Chunks, res.SHA256)
+		if res.BackupID != "" {
+			msg += " | backup " + res.BackupID
+		}
+		msg += hookNotesLine(ctx, engine)
+		sc := writeStructured(verifiedPath, bytesWritten, contentHash, verified)
+		sc["session_id"], sc["chunks"] = res.SessionID, res.Chunks
+		if res.BackupID != "" {
+			sc["backup_id"] = res.BackupID
+		}
+		if !verified {
+			msg += "\n⚠ " + unverifiedWriteWarning
+			sc["feedback"] = unverifiedWriteWarning
+		}
+		return mcp.NewToolResultStructured(attachMessage(sc, msg), msg)
+
+	case "abort":
+		if err := engine.AbortWriteSession(id); err != nil {
+			return mcp.NewToolResultError(formatToolError(err))
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("SESSION %s aborted, temp file removed", id))
+	}
+	return mcp.NewToolResultError(fmt.Sprintf("invalid session %q: use begin, append, commit or abort", action))
 }
 
 const unverifiedWriteWarning = "POST-WRITE VERIFICATION FAILED: the atomic write returned success, but the host file could not be reopened. Confirm with get_file_info/read_file before continuing; do not repeat the write blindly."
@@ -348,7 +470,7 @@ func registerCoreTools(reg *toolRegistry) {
 			var results strings.Builder
 			for i, p := range paths {
 				p = core.NormalizePath(p)
-				content, err := engine.ReadFileContent(ctx, p)
+				content, err := readWholeOrView(ctx, engine, p)
 				if i > 0 {
 					results.WriteString("\n")
 				}
@@ -365,8 +487,7 @@ func registerCoreTools(reg *toolRegistry) {
 			// FASE1: structuredContent conformance. No content_hash here —
 			// per-file hashes will come in a later phase (the readFileOutputSchema
 			// declares content_hash as optional precisely for this branch).
-			combined := results.String()
-			return mcp.NewToolResultStructured(map[string]any{"content": combined}, combined), nil
+			return chunkedStructured(engine, "read_file", map[string]any{}, results.String()), nil
 		}
 
 		path, err := request.RequireString("path")
@@ -414,12 +535,12 @@ func registerCoreTools(reg *toolRegistry) {
 			// Point 3: surface the whole-file OCC hash for base64 reads too.
 			if contentHash, ok := computeFileOCCHash(core.NormalizePath(path)); ok {
 				core.RecordReadHash(core.NormalizePath(path), contentHash) // new point 4
-				return mcp.NewToolResultStructured(map[string]any{"content": body, "content_hash": contentHash}, body), nil
+				return chunkedStructured(engine, "read_file", map[string]any{"content_hash": contentHash}, body), nil
 			}
 			// FASE1: structuredContent conformance fallback (file read OK but
 			// computeFileOCCHash failed — usually transient lock). No
 			// content_hash; the schema declares it optional.
-			return mcp.NewToolResultStructured(map[string]any{"content": body}, body), nil
+			return chunkedStructured(engine, "read_file", map[string]any{}, body), nil
 		}
 
 		// Range read mode: read specific line range
@@ -445,11 +566,27 @@ func registerCoreTools(reg *toolRegistry) {
 			// pulling the entire file into context.
 			if contentHash, ok := computeFileOCCHash(core.NormalizePath(path)); ok {
 				core.RecordReadHash(core.NormalizePath(path), contentHash) // new point 4
-				return mcp.NewToolResultStructured(map[string]any{"content": content, "content_hash": contentHash}, content), nil
+				return chunkedStructured(engine, "read_file", map[string]any{"content_hash": contentHash}, content), nil
 			}
 			// FASE1: structuredContent conformance fallback (range read OK but
 			// computeFileOCCHash failed). No content_hash; schema declares it optional.
-			return mcp.NewToolResultStructured(map[string]any{"content": content}, content), nil
+			return chunkedStructured(engine, "read_file", map[string]any{}, content), nil
+		}
+
+		// Over --binary-threshold: a head+tail view streamed from disk, with
+		// the whole-file hash from the line index
+		normPath := core.NormalizePath(path)
+		if info, statErr := os.Stat(normPath); statErr == nil && !info.IsDir() && engine.OverBinaryThreshold(info.Size()) {
+			head, tail := largeViewLines(maxLines, mode)
+			view, err := engine.ReadLargeFileView(ctx, path, head, tail)
+			if err != nil {
+				return mcp.NewToolResultError(formatToolError(err)), nil
+			}
+			core.RecordRead(normPath)
+			core.RecordReadHash(normPath, view.ContentHash)
+			core.SetFileLinesTotal(ctx, view.TotalLines)
+			core.SetLinesRead(ctx, view.HeadLines+view.TailLines)
+			return chunkedStructured(engine, "read_file", map[string]any{"content_hash": view.ContentHash}, core.FormatLargeFileView(view)), nil
 		}
 
 		// Default: read full file
@@ -501,14 +638,39 @@ func registerCoreTools(reg *toolRegistry) {
 		// Return the body as plain text (NO trailer) and the hash as a
 		// structured field. Fallback text for naive clients is the file
 		// body — they never see a `# content_hash:` line, so they can't
-		// mistake it for content.
-		return mcp.NewToolResultStructured(
-			map[string]any{"content": content, "content_hash": contentHash},
-			content,
-		), nil
+		// mistake it for content. A body over MaxResponseSize is sent in
+		// chunks (fetch_continuation).
+		return chunkedStructured(engine, "read_file", map[string]any{"content_hash": contentHash}, content), nil
 	})
 	reg.addTool(readFileTool, reg.readFileHandler)
 
+	// ============================================================================
+	// fetch_continuation — the rest of a response cut at --max-response-size
+	// ============================================================================
+	continuationTool := mcp.NewTool("fetch_continuation",
+		mcp.WithTitleAnnotation("Fetch Continuation"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithDescription("fetch_continuation — Next chunk of a response that was too large to send at once (read_file, list_directory, search_files, git diff, backup compare). "+
+			"Pass the continuation_token from the previous chunk; each chunk ends with the token for the following one until the response is complete. "+
+			"Tokens expire 5 minutes after their last use. Related: read_file, search_files."),
+		mcp.WithString("token", mcp.Required(), mcp.Description("continuation_token from the previous chunk")),
+		mcp.WithNumber("max_bytes", mcp.Description("Max chunk size in bytes (default: the server's max response size)")),
+	)
+	reg.addTool(continuationTool, auditWrap(engine, "fetch_continuation", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		token, err := request.RequireString("token")
+		if err != nil {
+			return usageError("token is required", `fetch_continuation(token:"cont_1a2b3c4d5e6f7a8b:1048576")`), nil
+		}
+		args, _ := request.Params.Arguments.(map[string]interface{})
+		chunk, err := engine.FetchContinuation(token, parseIntArg(args, "max_bytes", 0))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Error: %v", err)), nil
+		}
+		return mcp.NewToolResultText(chunk.Content + core.ContinuationFooter(chunk)), nil
+	}))
+
 	// ============================================================================
 	// 2. write_file — Write file (consolidated: mcp_write + write_file + create_file + write_base64 + streaming_write + intelligent_write)
 	// ============================================================================
@@ -519,6 +681,7 @@ func registerCoreTools(reg *toolRegistry) {
 			"Use write_file for ALL project files — never use the runtime's built-in write/create tools for host paths; those may target a different sandbox. "+
 			"Returns verified post-write host evidence, but still confirm each mutation independently with get_file_info/list_directory and read_file when content matters. "+
 			"Creates or overwrites. For binary use content_base64 with encoding:\"base64\". "+
+			"Content too large for one call: session:\"begin\", then session:\"append\" with chunk and index, then session:\"commit\"; chunks stream to a temp file that is renamed into place, with a backup when overwriting. "+
 			"WARNING: To modify/edit existing files use edit_file instead. Related: edit_file, multi_edit, copy_file, batch_operations."),
 		mcp.WithReadOnlyHintAnnotation(false),
 		mcp.WithDestructiveHintAnnotation(true),
@@ -527,6 +690,11 @@ func registerCoreTools(reg *toolRegistry) {
 		mcp.WithString("content", mcp.Description("Text content to write to the file")),
 		mcp.WithString("content_base64", mcp.Description("Base64-encoded binary content to write")),
 		mcp.WithString("encoding", mcp.Description("Set to \"base64\" when content is base64-encoded")),
+		mcp.WithString("session", mcp.Description("Chunked write for content larger than one message: begin (returns session_id), append (chunk + index 0, 1, ...), commit (optional expected_sha256), abort")),
+		mcp.WithString("session_id", mcp.Description("Write session from session:\"begin\" (append, commit, abort)")),
+		mcp.WithString("chunk", mcp.Description("Next piece of the content (session:\"append\")")),
+		mcp.WithNumber("index", mcp.Description("0-based position of chunk; resending the last chunk is ignored (session:\"append\")")),
+		mcp.WithString("expected_sha256", mcp.Description("Hex SHA-256 of the whole content; commit is refused and the session discarded on mismatch (session:\"commit\")")),
 	)
 	reg.writeFileHandler = auditWrap(engine, "write_file", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		path, err := request.RequireString("path")
@@ -544,6 +712,11 @@ func registerCoreTools(reg *toolRegistry) {
 		// The final response path is resolved after the write by
 		// verifyOnDiskWrite, which can report the canonical authorized target.
 
+		// Chunked write session: content larger than one message
+		if action := request.GetString("session", ""); action != "" {
+			return handleWriteSession(ctx, engine, request, path, action), nil
+		}
+
 		// Check for base64 content
 		contentBase64 := ""
 		encoding := ""
@@ -610,7 +783,7 @@ func registerCoreTools(reg *toolRegistry) {
 		}
 
 		// Feedback: check for truncation/inflation/full-rewrite patterns.
-		// Normalize path once so os.Stat, CreateBackup, and WriteFileContent
+		// Normalize path once so os.Stat, CreateBackup, and WriteFileAuto
 		// all see the same target (consistent on Windows/WSL).
 		normPath := core.NormalizePath(path)
 		var existingSize int64
@@ -650,9 +823,9 @@ func registerCoreTools(reg *toolRegistry) {
 			// Always use verbose format (FormatFeedback, not FormatFeedbackCompact)
 			// when the warn came from an adaptive downgrade so the backup ID
 			// and restore command remain literal and visible to the AI/operator.
-			err = engine.WriteFileContent(ctx, path, content)
+			err = engine.WriteFileAuto(ctx, path, content)
 			if err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("Error: %v", err)), nil
+				return mcp.NewToolResultError(fmt.Sprintf("Error: %v", err) + hookNotesLine(ctx, engine)), nil
 			}
 			// Reopen once after the write so hooks/EOL transformations are reflected
 			// in both the byte count and OCC token.
@@ -677,6 +850,7 @@ func registerCoreTools(reg *toolRegistry) {
 				return mcp.NewToolResultStructured(attachMessage(sc, msg), msg), nil
 			}
 			msg := core.FormatFeedback(signal, fmt.Sprintf("WRITTEN %s %s | %dB", diskPrefix(verifiedPath), verifiedPath, bytesWritten))
+			msg += hookNotesLine(ctx, engine)
 			if !verified {
 				msg += "\n⚠ " + unverifiedWriteWarning
 			}
@@ -695,15 +869,16 @@ func registerCoreTools(reg *toolRegistry) {
 			return mcp.NewToolResultStructured(attachMessage(sc, msg), msg), nil
 		}
 
-		err = engine.WriteFileContent(ctx, path, content)
+		err = engine.WriteFileAuto(ctx, path, content)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Error: %v", err)), nil
+			return mcp.NewToolResultError(fmt.Sprintf("Error: %v", err) + hookNotesLine(ctx, engine)), nil
 		}
 		verifiedPath, bytesWritten, writeContentHash, verified := verifyOnDiskWrite(engine, path)
 		if verified {
 			core.RecordWriteHash(normPath, writeContentHash)
 		}
 		msg := fmt.Sprintf("WRITTEN %s %s | %dB", diskPrefix(verifiedPath), verifiedPath, bytesWritten)
+		msg += hookNotesLine(ctx, engine)
 		if !verified {
 			msg += "\n⚠ " + unverifiedWriteWarning
 		}
@@ -755,9 +930,11 @@ func registerCoreTools(reg *toolRegistry) {
 		// Improvement B3 (see log analysis: 6 stale-edit cycles in 12 days).
 		mcp.WithString("expected_hash", mcp.Description("Optional. The content_hash from the last read_file (full, range, head/tail and base64 reads all return it). If the file's current hash doesn't match, the edit is rejected so the model can re-read first.")),
 		mcp.WithBoolean("tolerant_whitespace", mcp.Description("Treat tabs and 4-space runs as equivalent (1 tab = 4 spaces) and CRLF/LF as equivalent when matching old_text. Use when the file has mixed indentation (e.g., tabs in some lines, spaces in others). Original file bytes are preserved — only the matching is tolerant. Default: false.")),
+		mcp.WithString("confirm_token", mcp.Description("Only when the server runs with --confirm-tokens: the one-time token returned by a blocked HIGH/CRITICAL edit. Re-issue the identical edit with it to execute. Expires after a few minutes or if the file changes.")),
+		formatParam(),
 	)
 	regexTransform := reg.regexTransform
-	reg.editFileHandler = auditWrap(engine, "edit_file", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	reg.editFileHandler = auditWrap(engine, "edit_file", jsonResponses(engine, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		path, err := request.RequireString("path")
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Invalid path: %v", err)), nil
@@ -799,6 +976,9 @@ func registerCoreTools(reg *toolRegistry) {
 			if nt, ok := args["new_text"].(string); ok {
 				newText = nt
 			}
+			if ct, ok := args["confirm_token"].(string); ok {
+				ctx = core.WithConfirmToken(ctx, ct)
+			}
 		}
 
 		// ---- MODE: regex ----
@@ -1210,11 +1390,16 @@ func registerCoreTools(reg *toolRegistry) {
 
 		result, err := engine.EditFile(ctx, path, oldText, newText, force, dryRun, tolerantWhitespace)
 		if err != nil {
+			// --confirm-tokens: blocked HIGH/CRITICAL edit — not a failed match
+			var confirmErr *core.ConfirmationRequiredError
+			if errors.As(err, &confirmErr) {
+				return mcp.NewToolResultError(confirmErr.Error()), nil
+			}
 			// Record failed old_text for reinforcement detection
 			core.RecordFailedOldText(path, oldText)
 			editSignal := core.CheckEditOp(path, oldText, fileSize, expectedHash != "")
 			core.SetFeedback(ctx, editSignal)
-			errMsg := formatToolError(err)
+			errMsg := formatToolError(err) + hookNotesLine(ctx, engine)
 			errMsg = core.FormatFeedback(editSignal, errMsg)
 			return mcp.NewToolResultError(errMsg), nil
 		}
@@ -1318,6 +1503,7 @@ func registerCoreTools(reg *toolRegistry) {
 
 		// Verbose format: single line summary + optional sections
 		msg := fmt.Sprintf("M %s | %d replacement(s) | +%d -%d | %dL", path, result.ReplacementCount, result.LinesAdded, result.LinesRemoved, result.TotalLines)
+		msg += hookNotesLine(ctx, engine)
 		if result.BackupID != "" {
 			msg += fmt.Sprintf("\n✓ UNDO:%s", result.BackupID)
 			// Show parent chain if exists
@@ -1413,6 +1599,6 @@ func registerCoreTools(reg *toolRegistry) {
 			sc["external_change"] = autoOCCWarn
 		}
 		return mcp.NewToolResultStructured(attachMessage(sc, msg), msg), nil
-	})
+	}))
 	reg.addTool(editFileTool, reg.editFileHandler)
 }
@@ -7,6 +7,7 @@
 	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
 	"github.com/mcp/filesystem-ultra/core"
 )
 
@@ -149,6 +150,7 @@ func registerFileTools(reg *toolRegistry) {
 		mcp.WithIdempotentHintAnnotation(false),
 		mcp.WithDescription("move_file — Move or rename files on the real host filesystem (the user's actual disk, e.g. C:\\, D:\\, /mnt/...). "+
 			"Use move_file for ALL project file moves — never use the runtime's built-in move/rename tools for host paths. "+
+			"Moves across drives or filesystems fall back to copy + hash verification + delete; the source is kept if any step fails. "+
 			"Related: copy_file, delete_file, edit_file, batch_operations."),
 		mcp.WithString("source_path", mcp.Required(), mcp.Description("Current path of the file/directory")),
 		mcp.WithString("dest_path", mcp.Required(), mcp.Description("New path for the file/directory")),
@@ -164,14 +166,24 @@ func registerFileTools(reg *toolRegistry) {
 			return mcp.NewToolResultError(fmt.Sprintf("Invalid dest_path: %v", err)), nil
 		}
 
-		err = engine.MoveFile(ctx, sourcePath, destPath)
+		result, err := engine.MovePath(ctx, sourcePath, destPath)
 		if err != nil {
 			return mcp.NewToolResultError(formatToolError(err)), nil
 		}
 
+		var fallback string
+		if c := result.Copy; result.CrossDevice && c != nil {
+			fallback = fmt.Sprintf("cross-device: copied %d files (%s), hash-verified, source removed", c.Files, core.FormatSize(c.Bytes))
+		}
 		if engine.IsCompactMode() {
+			if fallback != "" {
+				return mcp.NewToolResultText(fmt.Sprintf("OK: moved to %s | %s", destPath, fallback)), nil
+			}
 			return mcp.NewToolResultText(fmt.Sprintf("OK: moved to %s", destPath)), nil
 		}
+		if fallback != "" {
+			return mcp.NewToolResultText(fmt.Sprintf("Successfully moved '%s' to '%s' (%s)", sourcePath, destPath, fallback)), nil
+		}
 		return mcp.NewToolResultText(fmt.Sprintf("Successfully moved '%s' to '%s'", sourcePath, destPath)), nil
 	}))
 
@@ -185,9 +197,14 @@ func registerFileTools(reg *toolRegistry) {
 		mcp.WithIdempotentHintAnnotation(true),
 		mcp.WithDescription("copy_file — Copy files on the real host filesystem (the user's actual disk, e.g. C:\\, D:\\, /mnt/...). "+
 			"Use copy_file for ALL project file copies — never use the runtime's built-in copy tools for host paths. "+
-			"Also copies directories recursively. Related: move_file, delete_file, edit_file, batch_operations, backup."),
+			"Also copies directories recursively, keeping modes and mtimes, and reports files/bytes copied; "+
+			"the copy is hash-checked on a sample of files (every file with verify:true). "+
+			"A destination inside the source directory is rejected. Related: move_file, delete_file, edit_file, batch_operations, backup."),
 		mcp.WithString("source_path", mcp.Required(), mcp.Description("Path of the file/directory to copy")),
 		mcp.WithString("dest_path", mcp.Required(), mcp.Description("Destination path for the copy")),
+		mcp.WithBoolean("skip_default_excludes", mcp.Description("Directories: leave out .git, node_modules, dist and the other default excludes (default: false)")),
+		mcp.WithBoolean("verify", mcp.Description("Hash-check every copied file instead of a sample (default: false)")),
+		formatParam(),
 	)
 	reg.addTool(copyFileTool, auditWrap(engine, "copy_file", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		sourcePath, err := request.RequireString("source_path")
@@ -200,11 +217,22 @@ func registerFileTools(reg *toolRegistry) {
 			return mcp.NewToolResultError(fmt.Sprintf("Invalid dest_path: %v", err)), nil
 		}
 
-		err = engine.CopyFile(ctx, sourcePath, destPath)
+		opts := core.CopyOptions{
+			SkipDefaultExcludes: request.GetBool("skip_default_excludes", false),
+			Verify:              request.GetBool("verify", false),
+			OnProgress:          copyProgressNotifier(ctx, progressToken(request)),
+		}
+		result, err := engine.CopyPath(ctx, sourcePath, destPath, opts)
 		if err != nil {
 			return mcp.NewToolResultError(formatToolError(err)), nil
 		}
 
+		if wantJSON(engine, request) {
+			return jsonResult(result), nil
+		}
+		if result.Dir {
+			return mcp.NewToolResultText(core.FormatCopyResult(result, engine.IsCompactMode())), nil
+		}
 		if engine.IsCompactMode() {
 			return mcp.NewToolResultText(fmt.Sprintf("OK: copied to %s", destPath)), nil
 		}
@@ -216,16 +244,42 @@ func registerFileTools(reg *toolRegistry) {
 	// ============================================================================
 	fileInfoTool := mcp.NewTool("get_file_info",
 		mcp.WithTitleAnnotation("File Info"),
-		mcp.WithDescription("get_file_info — File/directory metadata (size, permissions, dates) from the real host filesystem. "+
+		mcp.WithDescription("get_file_info — File/directory metadata from the real host filesystem: size, mode and octal permissions, owner and group (Unix), hidden/readonly/system attributes (Windows), modification time. "+
+			"Symlinks are reported as symlinks with their target, not followed. include_hash adds the SHA-256 and include_stats wc-style stats of text files (lines, words, bytes, longest line, blank lines, plus per-pattern counts with patterns; directories get a per-file table and totals); both read the file, the rest is a single stat. "+
 			"Use it after host mutations to verify existence and actual size independently; runtime-native info tools may inspect a different sandbox. "+
-			"Batch: pass paths (JSON array) to get info for multiple files in one call. Related: read_file, list_directory, search_files."),
+			"Batch: pass paths (JSON array) to check many files in one call — missing or denied paths are reported inline, not as a failed call. Related: read_file, list_directory, search_files."),
 		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithDestructiveHintAnnotation(false),
 		mcp.WithIdempotentHintAnnotation(true),
 		mcp.WithString("path", mcp.Description("Path to the file or directory. Required unless paths is provided.")),
 		mcp.WithString("paths", mcp.Description("JSON array of paths for batch file info, e.g. '[\"file1.txt\",\"dir/\"]'")),
+		mcp.WithBoolean("include_hash", mcp.Description("Also return the SHA-256 of each file (default: false; hash is accepted as an alias)")),
+		mcp.WithBoolean("include_stats", mcp.Description("Also return lines, words, bytes, longest line and blank lines of text files, streamed; on a directory, per file plus totals (default: false)")),
+		mcp.WithBoolean("include_git", mcp.Description("Also return git_status inside a git repository: clean, M, A, R, C, U, ?? (untracked) or !! (ignored). Read-only; absent outside a repository (default: false)")),
+		mcp.WithString("patterns", mcp.Description("JSON array of regex (or literal) patterns to count per line, e.g. '[\"TODO\",\"func \"]'. Implies include_stats")),
+		formatParam(),
 	)
 	reg.addTool(fileInfoTool, auditWrap(engine, "get_file_info", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		asJSON := wantJSON(engine, request)
+		opts := core.FileInfoOptions{
+			Hash:  request.GetBool("include_hash", request.GetBool("hash", false)),
+			Stats: request.GetBool("include_stats", false),
+			Git:   request.GetBool("include_git", false),
+		}
+		if patternsJSON := request.GetString("patterns", ""); patternsJSON != "" {
+			var patterns []string
+			if err := json.Unmarshal([]byte(patternsJSON), &patterns); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid patterns JSON: %v", err)), nil
+			}
+			seen := make(map[string]bool, len(patterns))
+			for _, p := range patterns {
+				if p != "" && !seen[p] {
+					seen[p] = true
+					opts.Patterns = append(opts.Patterns, p)
+				}
+			}
+			opts.Stats = true
+		}
 		// Batch mode: get info for multiple files in one call
 		if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
 			if pathsJSON, ok := args["paths"].(string); ok && pathsJSON != "" {
@@ -236,23 +290,11 @@ func registerFileTools(reg *toolRegistry) {
 				if len(paths) == 0 {
 					return mcp.NewToolResultError("paths array is empty"), nil
 				}
-				var results strings.Builder
-				for i, p := range paths {
-					p = core.NormalizePath(p)
-					if i > 0 {
-						results.WriteString("\n")
-					}
-					info, err := engine.GetFileInfo(ctx, p)
-					if err != nil {
-						results.WriteString(fmt.Sprintf("=== %s ===\nERROR: %v\n", p, err))
-					} else {
-						results.WriteString(info)
-						if !strings.HasSuffix(info, "\n") {
-							results.WriteString("\n")
-						}
-					}
+				batch := engine.FilesInfo(ctx, paths, opts)
+				if asJSON {
+					return mcp.NewToolResultText(core.FileInfoBatchJSON(batch, responseLimit(engine))), nil
 				}
-				return mcp.NewToolResultText(results.String()), nil
+				return mcp.NewToolResultText(core.FormatFileInfoBatch(batch, engine.IsCompactMode(), responseLimit(engine))), nil
 			}
 		}
 
@@ -261,17 +303,27 @@ func registerFileTools(reg *toolRegistry) {
 			return mcp.NewToolResultError(fmt.Sprintf("Invalid path: %v", err)), nil
 		}
 
-		info, err := engine.GetFileInfo(ctx, path)
+		info, err := engine.FileInfoWith(ctx, path, opts)
 		if err != nil {
 			return mcp.NewToolResultError(formatToolError(err)), nil
 		}
-		return mcp.NewToolResultText(info), nil
+		if asJSON {
+			return jsonResult(info), nil
+		}
+		text := core.FormatFileInfo(info, engine.IsCompactMode())
+		if info.Error != "" {
+			text += fmt.Sprintf("⚠️  %s\n", info.Error)
+		}
+		return mcp.NewToolResultText(text), nil
 	}))
 }
 
 // formatSoftDeleteLine formats a single line of a batch soft-delete result.
 // Used inside the `paths` JSON batch handler (one line per file).
 func formatSoftDeleteLine(path string, info *core.SoftDeleteInfo, compact bool) string {
+	if info.SDID != "" && info.CrossVolume {
+		return fmt.Sprintf("OK: %s soft-deleted (SD:%s, copied across volumes)", path, info.SDID)
+	}
 	if info.SDID != "" {
 		return fmt.Sprintf("OK: %s soft-deleted (SD:%s)", path, info.SDID)
 	}
@@ -283,8 +335,12 @@ func formatSoftDeleteLine(path string, info *core.SoftDeleteInfo, compact bool)
 // and a one-line restore command.
 func formatSoftDeleteCompact(path string, info *core.SoftDeleteInfo) string {
 	if info.SDID != "" {
-		return fmt.Sprintf("D %s | SD:%s | restore: backup(action:\"restore_trash\", sd_id:\"%s\")",
-			path, info.SDID, info.SDID)
+		warning := ""
+		if info.CrossVolume {
+			warning = " | WARNING: no trash on this volume, copied + deleted"
+		}
+		return fmt.Sprintf("D %s | SD:%s | restore: backup(action:\"restore_trash\", sd_id:\"%s\")%s",
+			path, info.SDID, info.SDID, warning)
 	}
 	return fmt.Sprintf("D %s | legacy | manual: move_file(%s → %s) | hint: set --backup-dir",
 		path, info.DestPath, path)
@@ -297,13 +353,17 @@ func formatSoftDeleteCompact(path string, info *core.SoftDeleteInfo) string {
 // it's a legacy entry, the response points to a manual move_file.
 func formatSoftDeleteVerbose(path string, info *core.SoftDeleteInfo) string {
 	if info.SDID != "" {
-		return fmt.Sprintf(
+		text := fmt.Sprintf(
 			"OK: %s soft-deleted\n"+
 				"   SD-ID: %s\n"+
 				"   Trash: %s\n"+
 				"   Restore: backup(action:\"restore_trash\", sd_id:\"%s\")\n"+
 				"   Or manually: move_file(%s → %s)\n",
 			path, info.SDID, info.DestPath, info.SDID, info.DestPath, path)
+		if info.CrossVolume {
+			text += "   WARNING: no trash root shares this volume, so it was copied + deleted rather than renamed (set --trash-dir on this volume; see backup(action:\"trash_status\"))\n"
+		}
+		return text
 	}
 	return fmt.Sprintf(
 		"OK: %s soft-deleted (legacy mode — no discoverable trash)\n"+
@@ -312,3 +372,20 @@ func formatSoftDeleteVerbose(path string, info *core.SoftDeleteInfo) string {
 			"   Hint: set --backup-dir to get a discoverable trash + restore_trash action\n",
 		path, info.DestPath, info.DestPath, path)
 }
+
+// copyProgressNotifier forwards copy_file progress as notifications/progress
+// when the client sent a progress token (progress counts files)
+func copyProgressNotifier(ctx context.Context, token mcp.ProgressToken) func(core.CopyProgress) {
+	mcpServer := server.ServerFromContext(ctx)
+	if token == nil || mcpServer == nil {
+		return nil
+	}
+	return func(p core.CopyProgress) {
+		_ = mcpServer.SendNotificationToClient(ctx, string(mcp.MethodNotificationProgress), map[string]any{
+			"progressToken": token,
+			"progress":      float64(p.FilesDone),
+			"total":         float64(p.FilesTotal),
+			"message":       fmt.Sprintf("copied %d/%d files (%s of %s)", p.FilesDone, p.FilesTotal, core.FormatSize(p.BytesDone), core.FormatSize(p.BytesTotal)),
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// toolFilter applies --enable-tools / --disable-tools at registration time:
+// toolRegistry.addTool asks admit before registering, so a left-out tool is
+// never advertised and costs no description tokens. help and
+// server_info (whose tools action reports the selection) are always
+// registered.
+type toolFilter struct {
+	enable   []string
+	disable  []string
+	disabled map[string]string // tool name → reason it was left out
+	matched  map[string]bool   // patterns that matched at least one tool
+}
+
+// DisabledTool is a tool left out by the tool selection flags
+type DisabledTool struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// ToolsConfig is the server_info tools response
+type ToolsConfig struct {
+	EnableTools       []string       `json:"enable_tools"`
+	DisableTools      []string       `json:"disable_tools"`
+	Registered        []string       `json:"registered"`
+	Disabled          []DisabledTool `json:"disabled"`
+	UnmatchedPatterns []string       `json:"unmatched_patterns,omitempty"`
+	RegisteredCount   int            `json:"registered_count"`
+}
+
+// newToolFilter validates the patterns (tool names or path.Match globs)
+func newToolFilter(enable, disable []string) (*toolFilter, error) {
+	for _, p := range append(append([]string{}, enable...), disable...) {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid tool pattern %q: %w", p, err)
+		}
+	}
+	return &toolFilter{
+		enable:   enable,
+		disable:  disable,
+		disabled: make(map[string]string),
+		matched:  make(map[string]bool),
+	}, nil
+}
+
+// match returns the first pattern matching name
+func (f *toolFilter) match(patterns []string, name string) (string, bool) {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			f.matched[p] = true
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// admit reports whether name is registered, recording why when it is not
+func (f *toolFilter) admit(name string) bool {
+	if f == nil || name == "server_info" {
+		return true
+	}
+	if len(f.enable) > 0 {
+		if _, ok := f.match(f.enable, name); !ok {
+			f.disabled[name] = "not matched by --enable-tools"
+			return false
+		}
+	}
+	if p, ok := f.match(f.disable, name); ok {
+		f.disabled[name] = fmt.Sprintf("--disable-tools %q", p)
+		return false
+	}
+	return true
+}
+
+// unmatched lists the patterns that matched no tool (usually typos)
+func (f *toolFilter) unmatched() []string {
+	var out []string
+	for _, p := range append(append([]string{}, f.enable...), f.disable...) {
+		if !f.matched[p] {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// toolsConfig snapshots the registered and left-out tools
+func (r *toolRegistry) toolsConfig() ToolsConfig {
+	cfg := ToolsConfig{EnableTools: []string{}, DisableTools: []string{}, Registered: []string{}, Disabled: []DisabledTool{}}
+	for name := range r.server.ListTools() {
+		cfg.Registered = append(cfg.Registered, name)
+	}
+	sort.Strings(cfg.Registered)
+	cfg.RegisteredCount = len(cfg.Registered)
+	if f := r.filter; f != nil {
+		cfg.EnableTools = append(cfg.EnableTools, f.enable...)
+		cfg.DisableTools = append(cfg.DisableTools, f.disable...)
+		for name, reason := range f.disabled {
+			cfg.Disabled = append(cfg.Disabled, DisabledTool{Name: name, Reason: reason})
+		}
+		sort.Slice(cfg.Disabled, func(i, j int) bool { return cfg.Disabled[i].Name < cfg.Disabled[j].Name })
+		cfg.UnmatchedPatterns = f.unmatched()
+	}
+	return cfg
+}
+
+// formatToolsConfig renders server_info tools as text
+func formatToolsConfig(cfg ToolsConfig, compact bool) string {
+	list := func(items []string, empty string) string {
+		if len(items) == 0 {
+			return empty
+		}
+		return strings.Join(items, ", ")
+	}
+	if compact {
+		return fmt.Sprintf("%d registered, %d disabled | enable: %s | disable: %s",
+			cfg.RegisteredCount, len(cfg.Disabled), list(cfg.EnableTools, "all"), list(cfg.DisableTools, "none"))
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Tools: %d registered, %d disabled\n", cfg.RegisteredCount, len(cfg.Disabled)))
+	sb.WriteString(fmt.Sprintf("--enable-tools: %s\n", list(cfg.EnableTools, "(all tools)")))
+	sb.WriteString(fmt.Sprintf("--disable-tools: %s\n", list(cfg.DisableTools, "(none)")))
+	if len(cfg.UnmatchedPatterns) > 0 {
+		sb.WriteString(fmt.Sprintf("⚠️  Patterns matching no tool: %s\n", strings.Join(cfg.UnmatchedPatterns, ", ")))
+	}
+	sb.WriteString(fmt.Sprintf("\nRegistered:\n  %s\n", strings.Join(cfg.Registered, ", ")))
+	if len(cfg.Disabled) > 0 {
+		sb.WriteString("\nDisabled (calls fail with \"tool not found\"):\n")
+		for _, d := range cfg.Disabled {
+			sb.WriteString(fmt.Sprintf("  %s — %s\n", d.Name, d.Reason))
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// toolsConfigResult handles server_info(action:"tools"). The snapshot is
+// taken per call, so it always reflects every registration.
+func toolsConfigResult(reg *toolRegistry, request mcp.CallToolRequest) *mcp.CallToolResult {
+	cfg := reg.toolsConfig()
+	if wantJSON(reg.engine, request) {
+		return jsonResult(cfg)
+	}
+	return mcp.NewToolResultText(formatToolsConfig(cfg, reg.engine.IsCompactMode()))
+}
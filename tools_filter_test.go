@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mcp/filesystem-ultra/cache"
+	"github.com/mcp/filesystem-ultra/core"
+)
+
+func newFilteredServer(t *testing.T, enable, disable []string) (*server.MCPServer, error) {
+	t.Helper()
+	c, err := cache.NewIntelligentCache(1024 * 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine, err := core.NewUltraFastEngine(&core.Config{Cache: c, AllowedPaths: []string{t.TempDir()}, ParallelOps: 2,
+		EnableTools: enable, DisableTools: disable})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { engine.Close() })
+	s := server.NewMCPServer("test", "0.0.0")
+	return s, registerTools(s, engine)
+}
+
+func TestToolFilter_EnableAndDisable(t *testing.T) {
+	s, err := newFilteredServer(t, []string{"read_file", "list_*", "allowed_*", "delete_file", "nope_*"}, []string{"delete_file", "allowed_*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tools := s.ListTools()
+	for _, name := range []string{"read_file", "list_directory", "help", "server_info"} {
+		if _, ok := tools[name]; !ok {
+			t.Errorf("%s not registered", name)
+		}
+	}
+	for _, name := range []string{"delete_file", "edit_file", "allowed_paths", "wsl"} {
+		if _, ok := tools[name]; ok {
+			t.Errorf("%s registered", name)
+		}
+	}
+
+	res := callServer(t, s, "server_info", map[string]any{"action": "tools", "format": "json"})
+	var cfg ToolsConfig
+	if err := json.Unmarshal([]byte(textFromResult(t, res)), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.RegisteredCount != len(tools) || strings.Join(cfg.UnmatchedPatterns, ",") != "nope_*" {
+		t.Errorf("config = %+v", cfg)
+	}
+	reasons := map[string]string{}
+	for _, d := range cfg.Disabled {
+		reasons[d.Name] = d.Reason
+	}
+	if reasons["delete_file"] != `--disable-tools "delete_file"` || reasons["edit_file"] != "not matched by --enable-tools" {
+		t.Errorf("disabled = %v", cfg.Disabled)
+	}
+
+	text := textFromResult(t, callServer(t, s, "server_info", map[string]any{"action": "tools"}))
+	if !strings.Contains(text, "delete_file — --disable-tools") || !strings.Contains(text, "Patterns matching no tool: nope_*") {
+		t.Errorf("text view:\n%s", text)
+	}
+	help := textFromResult(t, callServer(t, s, "help", map[string]any{"tool": "delete_file"}))
+	if !strings.Contains(help, "disabled on this server") {
+		t.Errorf("help for a disabled tool: %s", help)
+	}
+}
+
+func TestToolFilter_InvalidPattern(t *testing.T) {
+	if _, err := newFilteredServer(t, nil, []string{"wsl["}); err == nil {
+		t.Error("invalid pattern accepted")
+	}
+}
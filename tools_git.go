@@ -18,13 +18,14 @@ func registerGitTools(reg *toolRegistry) {
 
 	gitTool := mcp.NewTool("git",
 		mcp.WithTitleAnnotation("Git Version Control"),
-		mcp.WithDescription("git — Git operations: status, diff, log, show, add, commit, restore, branch, init. "+
+		mcp.WithDescription("git — Git operations: status, diff, log, show, add, commit, restore, branch, init, changed. "+
+			"changed lists the working-tree changes one path per line (M, A, D, R, C, U, ??), read-only; format:'json' returns them structured. "+
 			"Must be run from within a git repository. Related: analyze_operation, edit_file, help."),
 		mcp.WithReadOnlyHintAnnotation(false),
 		mcp.WithDestructiveHintAnnotation(true), // restore, branch delete
 		mcp.WithIdempotentHintAnnotation(false), // commit, restore, branch delete are not idempotent
 
-		mcp.WithString("action", mcp.Required(), mcp.Description("Action: status, diff, log, show, add, commit, restore, branch, init")),
+		mcp.WithString("action", mcp.Required(), mcp.Description("Action: status, diff, log, show, add, commit, restore, branch, init, changed")),
 		mcp.WithString("path", mcp.Description("Working directory or file path (default: auto-detect repo root). If a file path, used as implicit pathspec for diff/log/status.")),
 		mcp.WithArray("paths", mcp.WithStringItems(),
 			mcp.Description("Pathspec: native array of file/dir paths relative to repo root. Limits diff/log/status/add/restore to these paths. Equivalent to 'git <cmd> -- <paths>'.")),
@@ -37,6 +38,7 @@ func registerGitTools(reg *toolRegistry) {
 		mcp.WithString("name", mcp.Description("branch: branch name to create/delete/checkout")),
 		mcp.WithBoolean("checkout", mcp.Description("branch: with name=true, also switch to new branch (git switch -c). Default: false.")),
 		mcp.WithBoolean("force", mcp.Description("branch delete: true → -D (force). Other actions: ignored.")),
+		formatParam(),
 	)
 
 	reg.addTool(gitTool, auditWrap(engine, "git", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -48,7 +50,7 @@ func registerGitTools(reg *toolRegistry) {
 		if action == "" {
 			return usageError(
 				"missing 'action' parameter",
-				`git(action:"status")  // or: diff, log, show, add, commit, restore, branch, init`), nil
+				`git(action:"status")  // or: diff, log, show, add, commit, restore, branch, init, changed`), nil
 		}
 
 		// Normalize path
@@ -120,10 +122,19 @@ func registerGitTools(reg *toolRegistry) {
 			return gitRestore(ctx, engine, repoRoot, args)
 		case "branch":
 			return gitBranch(ctx, engine, repoRoot, args)
+		case "changed":
+			ix, err := core.LoadGitStatus(ctx, repoRoot)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("git status failed: %v", err)), nil
+			}
+			if wantJSON(engine, request) {
+				return jsonResult(ix.Changes()), nil
+			}
+			return mcp.NewToolResultText(core.FormatGitChanges(ix.Changes())), nil
 		default:
 			return usageError(
 				fmt.Sprintf("unknown action %q", action),
-				`git(action:"status")  // valid: status, diff, log, show, add, commit, restore, branch, init`), nil
+				`git(action:"status")  // valid: status, diff, log, show, add, commit, restore, branch, init, changed`), nil
 		}
 	}),
 		// Examples for help(tool:"git") — manually curated per docs/git-tool-spec.md §5
@@ -135,6 +146,7 @@ func registerGitTools(reg *toolRegistry) {
 		`git(action:"commit", message:"fix: short description")`,
 		`git(action:"restore", paths:["file.txt"], staged:true)`,
 		`git(action:"branch", name:"feature/new", checkout:true)`,
+		`git(action:"changed", format:"json")`,
 	)
 }
 
@@ -397,12 +409,13 @@ func gitDiff(ctx context.Context, engine *core.UltraFastEngine, repoRoot string,
 	// for LLM consumers. We apply max_lines unconditionally and prepend the banner if downgraded.
 	final := banner + truncateOutput(output, maxLines)
 
-	// Server compact mode still trims further if it's an extreme case (>10k chars).
-	if engine.IsCompactMode() && len(final) > 10000 {
-		final = final[:10000] + "\n... (truncated by compact mode)"
+	// Server compact mode still trims further if it's an extreme case (>10k
+	// chars); the rest stays available through fetch_continuation.
+	limit := responseLimit(engine)
+	if engine.IsCompactMode() && (limit <= 0 || limit > 10000) {
+		limit = 10000
 	}
-
-	return mcp.NewToolResultText(final), nil
+	return chunkedText(engine, "git_diff", final, limit), nil
 }
 
 // gitLog returns the commit log.
@@ -1,14 +1,18 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mcp/filesystem-ultra/core"
 )
 
 // usageError formats an error response with a usage example, per docs/git-tool-spec.md §4.
@@ -40,6 +44,17 @@ func formatToolError(err error) string {
 	return fmt.Sprintf("Error: %v", err) + filesystemMismatchSuffix(err)
 }
 
+// hookNotesLine renders the hook outcomes recorded during this call
+// ("\nhooks: gofmt modified content (+3/-1 lines)"), or "" when no hook
+// changed or blocked anything. Verbose responses only.
+func hookNotesLine(ctx context.Context, engine *core.UltraFastEngine) string {
+	notes := core.HookNotes(ctx)
+	if len(notes) == 0 || engine.IsCompactMode() {
+		return ""
+	}
+	return "\nhooks: " + strings.Join(notes, "; ")
+}
+
 // pathsFromArgs extracts the pathspec from args["paths"] as []string.
 //
 // Returns:
@@ -81,6 +96,28 @@ func pathsFromArgs(args map[string]interface{}) ([]string, *mcp.CallToolResult)
 	}
 }
 
+// excludeFromArgs extracts the optional "exclude" glob array
+func excludeFromArgs(args map[string]interface{}) ([]string, *mcp.CallToolResult) {
+	return stringsFromArgs(args, "exclude")
+}
+
+// stringsFromArgs extracts an optional native string array argument
+func stringsFromArgs(args map[string]interface{}, key string) ([]string, *mcp.CallToolResult) {
+	raw, ok := args[key].([]interface{})
+	if !ok {
+		return nil, nil
+	}
+	out := make([]string, 0, len(raw))
+	for i, item := range raw {
+		s, ok := item.(string)
+		if !ok {
+			return nil, mcp.NewToolResultError(fmt.Sprintf("'%s[%d]' must be a string, got %T", key, i, item))
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
 func implicitGitPathspec(path, repoRoot string) (string, bool) {
 	if path == "" || repoRoot == "" {
 		return "", false
@@ -96,6 +133,73 @@ func implicitGitPathspec(path, repoRoot string) (string, bool) {
 	return filepath.ToSlash(rel), true
 }
 
+// responseLimit is the size (MaxResponseSize) above which a tool response is
+// sent in chunks; 0 = unlimited
+func responseLimit(engine *core.UltraFastEngine) int {
+	if cfg := engine.GetConfig(); cfg != nil {
+		return int(cfg.MaxResponseSize)
+	}
+	return 0
+}
+
+// chunkedText returns text as a tool result. Beyond limit bytes only the first
+// chunk is sent, followed by a footer with the fetch_continuation token.
+func chunkedText(engine *core.UltraFastEngine, kind, text string, limit int) *mcp.CallToolResult {
+	c := engine.ChunkResponse(kind, text, limit)
+	return mcp.NewToolResultText(c.Content + core.ContinuationFooter(c))
+}
+
+// chunkedStructured is mcp.NewToolResultStructured for a response whose
+// "content" may exceed MaxResponseSize: the first chunk goes in sc["content"]
+// with continuation_token and total_size, the text gets the footer.
+func chunkedStructured(engine *core.UltraFastEngine, kind string, sc map[string]any, content string) *mcp.CallToolResult {
+	c := engine.ChunkResponse(kind, content, responseLimit(engine))
+	sc["content"] = c.Content
+	if c.ContinuationToken != "" {
+		sc["continuation_token"] = c.ContinuationToken
+		sc["total_size"] = c.TotalSize
+	}
+	return mcp.NewToolResultStructured(sc, c.Content+core.ContinuationFooter(c))
+}
+
+// formatParam is the per-call override of --json-responses
+func formatParam() mcp.ToolOption {
+	return mcp.WithString("format", mcp.Description("Response format: \"text\" (prose) or \"json\" (one JSON object, schema in core/response_types.go). Default: text, or json when the server runs with --json-responses"))
+}
+
+// wantJSON reports whether the call gets a JSON response (format argument,
+// else --json-responses)
+func wantJSON(engine *core.UltraFastEngine, request mcp.CallToolRequest) bool {
+	return engine.JSONResponse(request.GetString("format", ""))
+}
+
+// jsonResult returns v, indented, as the tool text
+func jsonResult(v any) *mcp.CallToolResult {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to encode response: %v", err))
+	}
+	return mcp.NewToolResultText(string(data))
+}
+
+// jsonResponses wraps a handler whose results carry structuredContent: when
+// the call wants JSON, successful results get that payload as their text
+// instead of the prose
+func jsonResponses(engine *core.UltraFastEngine, handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error)) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		res, err := handler(ctx, request)
+		if err != nil || res == nil || res.IsError || res.StructuredContent == nil || !wantJSON(engine, request) {
+			return res, err
+		}
+		data, marshalErr := json.MarshalIndent(res.StructuredContent, "", "  ")
+		if marshalErr != nil {
+			return res, nil
+		}
+		res.Content = []mcp.Content{mcp.NewTextContent(string(data))}
+		return res, nil
+	}
+}
+
 // truncateOutput truncates text to maxLines and appends a footer if truncated.
 // Footer per docs/git-tool-spec.md §3:
 //
@@ -170,3 +274,19 @@ func countChangedFiles(repoRoot, rev string, staged bool) (int, error) {
 	}
 	return len(strings.Split(strings.TrimRight(output, "\n"), "\n")), nil
 }
+
+// parseSinceArg parses a "since" filter: an RFC3339 timestamp or a Go duration
+// meaning "that long ago" ("30m", "2h"). Empty returns the zero time (no filter).
+func parseSinceArg(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid since %q: use an RFC3339 timestamp or a duration like \"30m\"", s)
+}
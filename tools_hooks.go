@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mcp/filesystem-ultra/core"
+)
+
+// registerHookTools registers the hooks tool
+func registerHookTools(reg *toolRegistry) {
+	engine := reg.engine
+
+	// ============================================================================
+	// hooks — Hook administration (consolidated: status + reload + test)
+	// ============================================================================
+	hooksTool := mcp.NewTool("hooks",
+		mcp.WithTitleAnnotation("Hooks"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(false),
+		mcp.WithDescription("hooks — Inspect, reload and try the pre/post operation hooks. Actions: status, reload, test. "+
+			"status shows whether hooks are enabled, the config file they came from, and every registered hook per event with its matcher pattern, path filters, command and timeout. "+
+			"reload re-reads the --hooks-config file; the new config replaces the active one only if it parses and validates (known events, valid regexes and path patterns, a command per hook), otherwise the problems are reported and the previous config stays active. "+
+			"test runs the hooks configured for an event against a path and sample content and reports what each would do: allow, deny (with the reason), modify (with the resulting content) or skipped. "+
+			"No file operation is performed; hooks receive metadata dry_run=true, but their commands do run. Works while hooks are disabled, to try a config before enabling it."),
+		mcp.WithString("action", mcp.Description("Action: status (default), reload, test")),
+		mcp.WithString("output", mcp.Description("For status: output format \"text\" (default) or \"json\"")),
+		mcp.WithString("event", mcp.Description("For test: hook event, e.g. pre-write, pre-delete, pre-move")),
+		mcp.WithString("path", mcp.Description("For test: file path the hooks see (the file need not exist)")),
+		mcp.WithString("sample_content", mcp.Description("For test: content for write/edit events (formatter hooks need it)")),
+		mcp.WithString("tool", mcp.Description("For test: tool name matched against hook patterns (default: the event's usual tool, e.g. write_file for pre-write)")),
+	)
+	reg.addTool(hooksTool, auditWrap(engine, "hooks", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		switch action := request.GetString("action", "status"); action {
+		case "status", "":
+			output := request.GetString("output", "text")
+			if output != "text" && output != "json" {
+				return usageError(fmt.Sprintf("invalid output %q. Valid: text, json", output), `hooks(action:"status", output:"json")`), nil
+			}
+			status := engine.HooksStatus()
+			if output == "json" {
+				data, err := json.MarshalIndent(status, "", "  ")
+				if err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("Error: %v", err)), nil
+				}
+				return mcp.NewToolResultText(string(data)), nil
+			}
+			return mcp.NewToolResultText(core.FormatHooksStatus(status)), nil
+
+		case "reload":
+			count, err := engine.ReloadHooks()
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Error: %v", err)), nil
+			}
+			status := engine.HooksStatus()
+			state := "disabled (start the server with --hooks-enabled)"
+			if status.Enabled {
+				state = "enabled"
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("Reloaded %s: %d hooks across %d events, hooks %s", status.ConfigPath, count, len(status.Events), state)), nil
+
+		case "test":
+			event, err := request.RequireString("event")
+			if err != nil {
+				return usageError("event is required", `hooks(action:"test", event:"pre-write", path:"/project/main.go", sample_content:"package main")`), nil
+			}
+			path, err := request.RequireString("path")
+			if err != nil {
+				return usageError("path is required", `hooks(action:"test", event:"pre-delete", path:"/project/.env")`), nil
+			}
+			tool, results, err := engine.TestHooks(ctx, core.HookEvent(event), request.GetString("tool", ""), path, request.GetString("sample_content", ""))
+			if err != nil {
+				return usageError(err.Error(), `hooks(action:"test", event:"pre-write", path:"/project/main.go", sample_content:"package main")`), nil
+			}
+			return mcp.NewToolResultText(core.FormatHookTestResults(core.HookEvent(event), tool, results)), nil
+
+		default:
+			return usageError(fmt.Sprintf("invalid action %q. Valid: status, reload, test", action), `hooks(action:"status")`), nil
+		}
+	}))
+}
@@ -133,6 +133,9 @@ func registerMinifyTools(reg *toolRegistry) {
 				target = resolved
 			}
 		}
+		if err := engine.CheckWritable("minify_js", target); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Error: %v", err)), nil
+		}
 
 		// Dry-run: return the preview without writing
 		if dryRun {
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mcp/filesystem-ultra/core"
+)
+
+// registerPipelineTools registers the pipeline tool. run and load share the
+// executor behind batch_operations(pipeline_json); the tool exists so
+// pipelines are discoverable as a first-class tool and can live in the repo.
+func registerPipelineTools(reg *toolRegistry) {
+	engine := reg.engine
+
+	// ============================================================================
+	// pipeline — Multi-step pipelines (consolidated: run + load + status)
+	// ============================================================================
+	pipelineTool := mcp.NewTool("pipeline",
+		mcp.WithTitleAnnotation("Pipeline"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithIdempotentHintAnnotation(false),
+		mcp.WithDescription("pipeline — Multi-step file transformation pipelines (search → count → edit → ...) on the real host filesystem. Actions: run, load, status. "+
+			"run executes request_json; load reads the same JSON from a file inside the allowed paths (keeps reviewed pipelines in the repo); "+
+			"status shows running and recently finished pipelines (current step, files processed/total, elapsed) for clients that do not display progress notifications. "+
+			"Steps pass files to each other via input_from; supports conditions, ${var} variables, dry_run, backups and rollback on error. "+
+			"Returns per-step status and counts, overall risk level and backup_id. Related: batch_operations, help(\"batch\"). "+
+			pipelineOutputSchemaDoc),
+		mcp.WithString("action", mcp.Description("Action: run (default), load, status")),
+		mcp.WithString("request_json", mcp.Description("For run: JSON pipeline: {name, steps:[{id, action, input_from, condition, params, timeout_seconds}], dry_run, force, stop_on_error, create_backup, verbose, parallel, variables, max_duration_seconds, output}")),
+		mcp.WithString("path", mcp.Description("For load: path to the pipeline JSON file")),
+		mcp.WithBoolean("dry_run", mcp.Description("For load: preview only, overriding the file's dry_run (default: use the file's value)")),
+		mcp.WithBoolean("compact", mcp.Description("For run/load: one-line summary instead of per-step detail (default: server compact mode)")),
+		mcp.WithString("id", mcp.Description("For status: pipeline run ID (pl_N) to show; default: all running and up to 20 recent runs")),
+	)
+	reg.addTool(pipelineTool, auditWrap(engine, "pipeline", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		switch action := request.GetString("action", "run"); action {
+		case "run", "":
+			requestJSON, err := request.RequireString("request_json")
+			if err != nil {
+				return usageError("request_json is required", `pipeline(action:"run", request_json:'{"name":"rename","steps":[{"id":"find","action":"search","params":{"pattern":"old"}}]}')`), nil
+			}
+			var pipelineReq core.PipelineRequest
+			if err := json.Unmarshal([]byte(requestJSON), &pipelineReq); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid pipeline JSON: %v", err)), nil
+			}
+			return executePipelineTool(ctx, engine, pipelineReq, pipelineCompact(request, engine), progressToken(request)), nil
+
+		case "load":
+			path, err := request.RequireString("path")
+			if err != nil {
+				return usageError("path is required", `pipeline(action:"load", path:"pipelines/rename-api.json")`), nil
+			}
+			content, err := engine.ReadFileContent(ctx, path)
+			if err != nil {
+				return mcp.NewToolResultError(formatToolError(err)), nil
+			}
+			var pipelineReq core.PipelineRequest
+			if err := json.Unmarshal([]byte(content), &pipelineReq); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid pipeline JSON in %s: %v", path, err)), nil
+			}
+			if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+				if dryRun, ok := args["dry_run"].(bool); ok {
+					pipelineReq.DryRun = dryRun
+				}
+			}
+			return executePipelineTool(ctx, engine, pipelineReq, pipelineCompact(request, engine), progressToken(request)), nil
+
+		case "status":
+			id := strings.TrimSpace(request.GetString("id", ""))
+			statuses := engine.GetPipelineStatus(id)
+			if len(statuses) == 0 {
+				if id != "" {
+					return mcp.NewToolResultError(fmt.Sprintf("Unknown pipeline run %q (only running and the 20 most recent runs are kept)", id)), nil
+				}
+				return mcp.NewToolResultText("No pipelines running or recently finished"), nil
+			}
+			lines := make([]string, 0, len(statuses))
+			for _, s := range statuses {
+				lines = append(lines, formatPipelineStatus(s))
+			}
+			return mcp.NewToolResultText(strings.Join(lines, "\n")), nil
+
+		default:
+			return mcp.NewToolResultError(fmt.Sprintf("Unknown action: %s. Valid: run, load, status", action)), nil
+		}
+	}))
+}
+
+// pipelineOutputSchemaDoc documents output:"json" in the tool descriptions
+const pipelineOutputSchemaDoc = `output:"json" returns the result as JSON instead of text: {name, success, total_steps, completed_steps, ` +
+	`results:[{step_id, action, success, skipped, skip_reason, files_matched, counts:{path:n}, content:{path:text}, aggregated_content, edits_applied, error, timed_out, ` +
+	`duration (ns), risk_level, retries, bytes_written, dirs_created, diffs:{path:unified diff}, diffs_truncated, files_skipped:{reason:n}, results_limited}], ` +
+	`backup_id, total_duration (ns), dry_run, verbose, overall_risk_level, files_affected, total_edits, rollback_performed}. ` +
+	`verbose:true adds per-file diffs for edit/regex_transform (bounded by the max response size).`
+
+// pipelineCompact resolves the optional per-call compact override
+func pipelineCompact(request mcp.CallToolRequest, engine *core.UltraFastEngine) bool {
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if compact, ok := args["compact"].(bool); ok {
+			return compact
+		}
+	}
+	return engine.IsCompactMode()
+}
+
+// progressToken returns the client's _meta.progressToken, or nil
+func progressToken(request mcp.CallToolRequest) mcp.ProgressToken {
+	if request.Params.Meta == nil {
+		return nil
+	}
+	return request.Params.Meta.ProgressToken
+}
+
+// executePipelineTool runs a pipeline and formats the result. Failed
+// pipelines are returned as tool errors carrying the same report. Status
+// updates are logged and, when the client sent a progress token, forwarded
+// as notifications/progress (progress counts steps, fractional within a step).
+func executePipelineTool(ctx context.Context, engine *core.UltraFastEngine, pipelineReq core.PipelineRequest, compact bool, token mcp.ProgressToken) *mcp.CallToolResult {
+	executor := core.NewPipelineExecutor(engine)
+	mcpServer := server.ServerFromContext(ctx)
+	executor.OnStatus = func(status core.PipelineStatus) {
+		slog.Debug("pipeline progress", "id", status.ID, "name", status.Name, "status", status.Status,
+			"step", status.StepID, "files", status.FilesProcessed, "files_total", status.FilesTotal,
+			"elapsed", status.Elapsed.Round(time.Millisecond))
+		if token == nil || mcpServer == nil {
+			return
+		}
+		progress := float64(status.StepIndex)
+		if status.Status != core.PipelineRunning {
+			progress = float64(status.TotalSteps)
+		} else if status.FilesTotal > 0 {
+			progress += float64(status.FilesProcessed) / float64(status.FilesTotal)
+		}
+		_ = mcpServer.SendNotificationToClient(ctx, string(mcp.MethodNotificationProgress), map[string]any{
+			"progressToken": token,
+			"progress":      progress,
+			"total":         float64(status.TotalSteps),
+			"message":       formatPipelineStatus(status),
+		})
+	}
+
+	result, err := executor.Execute(ctx, pipelineReq)
+	if err != nil && result == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Pipeline execution failed: %v", err))
+	}
+
+	if pipelineReq.Output == "json" {
+		data, marshalErr := json.Marshal(result)
+		if marshalErr != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to encode pipeline result: %v", marshalErr))
+		}
+		if !result.Success {
+			return mcp.NewToolResultError(string(data))
+		}
+		return mcp.NewToolResultText(string(data))
+	}
+
+	responseText := formatPipelineResult(result, compact)
+	if !result.Success {
+		return mcp.NewToolResultError(responseText)
+	}
+	return mcp.NewToolResultText(responseText)
+}
+
+// formatPipelineStatus renders one run as a single line
+func formatPipelineStatus(s core.PipelineStatus) string {
+	name := s.Name
+	if name == "" {
+		name = "(unnamed)"
+	}
+	line := fmt.Sprintf("%s %s %s: %d/%d steps", s.ID, name, strings.ToUpper(s.Status), s.CompletedSteps, s.TotalSteps)
+	if s.StepID != "" {
+		line += fmt.Sprintf(" | step %d %s (%s)", s.StepIndex+1, s.StepID, s.Action)
+		if s.FilesTotal > 0 {
+			line += fmt.Sprintf(" %d/%d files", s.FilesProcessed, s.FilesTotal)
+		}
+	}
+	line += " | " + s.Elapsed.Round(time.Millisecond).String()
+	if s.DryRun {
+		line += " | dry-run"
+	}
+	if s.Error != "" {
+		line += " | " + s.Error
+	}
+	return line
+}
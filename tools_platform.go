@@ -4,13 +4,18 @@
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
+	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
 	"github.com/mcp/filesystem-ultra/core"
 )
 
-// registerPlatformTools registers wsl, server_info
+// registerPlatformTools registers wsl, allowed_paths, server_info
 func registerPlatformTools(reg *toolRegistry) {
 	engine := reg.engine
 
@@ -22,21 +27,39 @@ func registerPlatformTools(reg *toolRegistry) {
 		mcp.WithReadOnlyHintAnnotation(false),
 		mcp.WithDestructiveHintAnnotation(false),
 		mcp.WithIdempotentHintAnnotation(true),
-		mcp.WithDescription("wsl — WSL/Windows file sync and path conversion. Actions: sync, status, autosync_config, autosync_status. "+
+		mcp.WithDescription("wsl — WSL/Windows file sync and path conversion. Actions: sync, sync_status, convert_path, status, autosync_config, autosync_status. "+
+			"convert_path converts path between WSL (/home/..., /mnt/c/...) and Windows (C:\\..., \\\\wsl.localhost\\<distro>\\...) format with the rules the server applies to every path (UNC, wsl.conf automount root, --path-mappings) and reports whether the file exists; "+
+			"use it before handing a path to a Windows-native or Linux-native tool. "+
 			"Related: read_file, edit_file, copy_file, search_files."),
-		mcp.WithString("action", mcp.Description("Action: sync (default), status, autosync_config, autosync_status")),
+		mcp.WithString("action", mcp.Description("Action: sync (default), sync_status, convert_path, status, autosync_config, autosync_status")),
 		// sync params
 		mcp.WithString("wsl_path", mcp.Description("Source WSL path for sync")),
 		mcp.WithString("windows_path", mcp.Description("Destination or source Windows path for sync")),
 		mcp.WithString("direction", mcp.Description("Sync direction: wsl_to_windows, windows_to_wsl, or bidirectional")),
 		mcp.WithBoolean("create_dirs", mcp.Description("Create destination directories (default: true)")),
 		mcp.WithString("filter_pattern", mcp.Description("Optional file filter pattern for workspace sync")),
+		mcp.WithArray("exclude", mcp.WithStringItems(),
+			mcp.Description("Workspace sync: extra globs to skip (\"*.log\", \"dist/**\"), on top of .gitignore and the defaults (.git, node_modules, __pycache__, .venv, ...)")),
+		mcp.WithString("conflict_strategy", mcp.Description("Workspace sync: what to do with files changed on both sides since the last sync: skip (default, report only), newest_wins, prefer_wsl, prefer_windows")),
 		mcp.WithBoolean("dry_run", mcp.Description("Preview changes without executing (default: false)")),
+		mcp.WithNumber("max_files_per_second", mcp.Description("Workspace sync: copy at most this many files per second (default: unlimited)")),
+		mcp.WithNumber("max_concurrency", mcp.Description("Workspace sync: files copied in parallel (default: 1)")),
+		mcp.WithString("output", mcp.Description("Workspace sync: text (default) or json; with dry_run the JSON includes the per-file plan (action, size) and bytes_to_transfer")),
+		// convert_path params
+		mcp.WithString("path", mcp.Description("For convert_path: path to convert")),
+		mcp.WithString("target", mcp.Description("For convert_path: target format windows, wsl, or auto (default: the other format)")),
+		// sync_status params
+		mcp.WithString("sync_id", mcp.Description("For sync_status: workspace sync run ID (sync_N); default: running and recent syncs")),
 		// autosync_config params
 		mcp.WithBoolean("enabled", mcp.Description("Enable/disable auto-sync (for autosync_config)")),
 		mcp.WithBoolean("sync_on_write", mcp.Description("Auto-sync on write operations (default: true)")),
 		mcp.WithBoolean("sync_on_edit", mcp.Description("Auto-sync on edit operations (default: true)")),
+		mcp.WithBoolean("sync_on_delete", mcp.Description("Remove the Windows copy when a file is deleted or moved in WSL (default: false)")),
+		mcp.WithString("delete_mode", mcp.Description("What sync_on_delete does with the Windows copy: remove (default) or trash")),
 		mcp.WithBoolean("silent", mcp.Description("Silent mode for auto-sync (default: false)")),
+		mcp.WithString("operation", mcp.Description("For autosync_config: add_mapping or remove_mapping, to mirror only chosen directory trees (enabled then applies to the mapping, default true)")),
+		mcp.WithString("wsl_root", mcp.Description("For add_mapping/remove_mapping: WSL directory to mirror, e.g. /home/user/claude-workspace")),
+		mcp.WithString("windows_root", mcp.Description("For add_mapping: Windows directory it mirrors to, e.g. C:\\Users\\user\\claude-workspace")),
 	)
 	reg.addTool(wslTool, auditWrap(engine, "wsl", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		action := "sync"
@@ -47,6 +70,51 @@ func registerPlatformTools(reg *toolRegistry) {
 		}
 
 		switch action {
+		case "sync_status":
+			id := strings.TrimSpace(request.GetString("sync_id", ""))
+			statuses := engine.GetSyncStatus(id)
+			if len(statuses) == 0 {
+				if id != "" {
+					return mcp.NewToolResultError(fmt.Sprintf("Unknown sync run %q (only running and the 10 most recent syncs are kept)", id)), nil
+				}
+				return mcp.NewToolResultText("No workspace syncs running or recently finished"), nil
+			}
+			lines := make([]string, 0, len(statuses))
+			for _, s := range statuses {
+				lines = append(lines, formatSyncStatus(s))
+			}
+			return mcp.NewToolResultText(strings.Join(lines, "\n")), nil
+
+		case "convert_path":
+			path, err := request.RequireString("path")
+			if err != nil {
+				return usageError("path is required", `wsl(action:"convert_path", path:"/home/user/project/main.go", target:"windows")`), nil
+			}
+			conv, err := core.ConvertPathTo(path, request.GetString("target", "auto"), engine.IsPathAllowed)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Conversion failed: %v", err)), nil
+			}
+
+			existence := "does not exist"
+			switch {
+			case !conv.Allowed:
+				existence = "existence not checked: outside allowed paths"
+			case conv.IsDir:
+				existence = "exists, directory"
+			case conv.Exists:
+				existence = "exists, file"
+			}
+			if engine.IsCompactMode() {
+				return mcp.NewToolResultText(fmt.Sprintf("%s (%s)", conv.Converted, existence)), nil
+			}
+			var output strings.Builder
+			output.WriteString(fmt.Sprintf("%s path: %s\n", map[string]string{"windows": "Windows", "wsl": "WSL"}[conv.Target], conv.Converted))
+			if conv.LocalPath != conv.Converted {
+				output.WriteString(fmt.Sprintf("Opened by this server as: %s\n", conv.LocalPath))
+			}
+			output.WriteString(fmt.Sprintf("Status: %s\n", existence))
+			return mcp.NewToolResultText(output.String()), nil
+
 		case "status":
 			status, err := engine.GetWSLWindowsStatus(ctx)
 			if err != nil {
@@ -78,6 +146,17 @@ func registerPlatformTools(reg *toolRegistry) {
 			if winHome, ok := status["windows_home_windows_style"].(string); ok && winHome != "" {
 				output.WriteString(fmt.Sprintf("  Windows Home (Windows style): %s\n", winHome))
 			}
+			output.WriteString(fmt.Sprintf("  Drive Mount Root: %s\n", status["automount_root"]))
+			if mappings, ok := status["path_mappings"].(map[string]string); ok {
+				drives := make([]string, 0, len(mappings))
+				for drive := range mappings {
+					drives = append(drives, drive)
+				}
+				sort.Strings(drives)
+				for _, drive := range drives {
+					output.WriteString(fmt.Sprintf("  Drive %s: -> %s\n", drive, mappings[drive]))
+				}
+			}
 
 			output.WriteString(fmt.Sprintf("\nSystem:\n"))
 			output.WriteString(fmt.Sprintf("  Path Separator: %s\n", status["path_separator"]))
@@ -100,6 +179,29 @@ func registerPlatformTools(reg *toolRegistry) {
 			return mcp.NewToolResultText(output.String()), nil
 
 		case "autosync_config":
+			if op := request.GetString("operation", ""); op != "" {
+				wslRoot := request.GetString("wsl_root", "")
+				switch op {
+				case "add_mapping":
+					mapping := core.AutoSyncRootMapping{
+						WSLRoot:     wslRoot,
+						WindowsRoot: request.GetString("windows_root", ""),
+						Enabled:     request.GetBool("enabled", true),
+					}
+					if err := engine.AddAutoSyncMapping(mapping); err != nil {
+						return mcp.NewToolResultError(fmt.Sprintf("Failed to add mapping: %v", err)), nil
+					}
+					return mcp.NewToolResultText(fmt.Sprintf("Auto-sync mapping added: %s -> %s (enabled: %v)\nOnly files under mapped roots are auto-synced.", mapping.WSLRoot, mapping.WindowsRoot, mapping.Enabled)), nil
+				case "remove_mapping":
+					if err := engine.RemoveAutoSyncMapping(wslRoot); err != nil {
+						return mcp.NewToolResultError(fmt.Sprintf("Failed to remove mapping: %v", err)), nil
+					}
+					return mcp.NewToolResultText(fmt.Sprintf("Auto-sync mapping removed: %s", wslRoot)), nil
+				default:
+					return mcp.NewToolResultError(fmt.Sprintf("Unknown operation: %s. Valid: add_mapping, remove_mapping", op)), nil
+				}
+			}
+
 			enabledVal := false
 			hasEnabled := false
 			if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
@@ -122,6 +224,15 @@ func registerPlatformTools(reg *toolRegistry) {
 				if syncOnEdit, ok := args["sync_on_edit"].(bool); ok {
 					asCfg.SyncOnEdit = syncOnEdit
 				}
+				if syncOnDelete, ok := args["sync_on_delete"].(bool); ok {
+					asCfg.SyncOnDelete = syncOnDelete
+				}
+				if mode, ok := args["delete_mode"].(string); ok && mode != "" {
+					if mode != core.AutoSyncDeleteRemove && mode != core.AutoSyncDeleteTrash {
+						return mcp.NewToolResultError(fmt.Sprintf("invalid delete_mode %q: use remove or trash", mode)), nil
+					}
+					asCfg.DeleteMode = mode
+				}
 				if silent, ok := args["silent"].(bool); ok {
 					asCfg.Silent = silent
 				}
@@ -179,6 +290,28 @@ func registerPlatformTools(reg *toolRegistry) {
 			output.WriteString(fmt.Sprintf("  Sync on Write: %v\n", asStatus["sync_on_write"]))
 			output.WriteString(fmt.Sprintf("  Sync on Edit: %v\n", asStatus["sync_on_edit"]))
 			output.WriteString(fmt.Sprintf("  Sync on Delete: %v\n", asStatus["sync_on_delete"]))
+			if asStatus["sync_on_delete"] == true {
+				output.WriteString(fmt.Sprintf("  Delete Mode: %v\n", asStatus["delete_mode"]))
+			}
+			output.WriteString(fmt.Sprintf("  Deletes Synced: %v (errors: %v)\n", asStatus["deletes_synced"], asStatus["delete_errors"]))
+
+			output.WriteString(fmt.Sprintf("\nQueue: %v pending, %v files synced, %v errors, %v repeated writes merged\n",
+				asStatus["pending_count"], asStatus["files_synced"], asStatus["sync_errors"], asStatus["deduped_writes"]))
+			if at, ok := asStatus["last_flush_at"].(time.Time); ok {
+				output.WriteString(fmt.Sprintf("  Last Flush: %s, %v files (%v failed) in %v\n",
+					core.FormatAge(at), asStatus["last_flush_files"], asStatus["last_flush_failed"], asStatus["last_flush_duration"].(time.Duration).Round(time.Millisecond)))
+			}
+
+			if mappings, ok := asStatus["root_mappings"].([]core.AutoSyncMappingStatus); ok && len(mappings) > 0 {
+				output.WriteString("\nMappings (only these trees are synced):\n")
+				for _, mapping := range mappings {
+					marker := "on"
+					if !mapping.Enabled {
+						marker = "off"
+					}
+					output.WriteString(fmt.Sprintf("  [%s] %s -> %s (%d files synced)\n", marker, mapping.WSLRoot, mapping.WindowsRoot, mapping.FilesSynced))
+				}
+			}
 
 			if configPath, ok := asStatus["config_path"].(string); ok && configPath != "" {
 				output.WriteString(fmt.Sprintf("\nConfig File: %s\n", configPath))
@@ -203,7 +336,11 @@ func registerPlatformTools(reg *toolRegistry) {
 			direction := ""
 			createDirs := true
 			filterPattern := ""
+			conflictStrategy := ""
+			var exclude []string
 			dryRun := false
+			maxFilesPerSecond := 0.0
+			maxConcurrency := 0
 
 			if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
 				if wp, ok := args["wsl_path"].(string); ok {
@@ -221,22 +358,71 @@ func registerPlatformTools(reg *toolRegistry) {
 				if fp, ok := args["filter_pattern"].(string); ok {
 					filterPattern = fp
 				}
+				if cs, ok := args["conflict_strategy"].(string); ok {
+					conflictStrategy = cs
+				}
+				if raw, ok := args["exclude"].([]interface{}); ok {
+					for i, item := range raw {
+						glob, ok := item.(string)
+						if !ok {
+							return mcp.NewToolResultError(fmt.Sprintf("'exclude[%d]' must be a string, got %T", i, item)), nil
+						}
+						exclude = append(exclude, glob)
+					}
+				}
 				if dr, ok := args["dry_run"].(bool); ok {
 					dryRun = dr
 				}
+				if rate, ok := args["max_files_per_second"].(float64); ok {
+					maxFilesPerSecond = rate
+				}
+				if workers, ok := args["max_concurrency"].(float64); ok {
+					maxConcurrency = int(workers)
+				}
 			}
 
 			// Workspace sync mode (when direction is specified)
 			if direction != "" {
-				syncResult, err := engine.SyncWorkspace(ctx, direction, filterPattern, dryRun)
+				outputFormat := request.GetString("output", "text")
+				if outputFormat != "text" && outputFormat != "json" {
+					return usageError(fmt.Sprintf("invalid output %q. Valid: text, json", outputFormat), `wsl(action:"sync", direction:"wsl_to_windows", dry_run:true, output:"json")`), nil
+				}
+				syncResult, err := engine.SyncWorkspace(ctx, core.SyncWorkspaceOptions{
+					Direction:         direction,
+					FilterPattern:     filterPattern,
+					Exclude:           exclude,
+					ConflictStrategy:  conflictStrategy,
+					DryRun:            dryRun,
+					MaxFilesPerSecond: maxFilesPerSecond,
+					MaxConcurrency:    maxConcurrency,
+					OnStatus:          syncProgressNotifier(ctx, progressToken(request)),
+				})
 				if err != nil {
 					return mcp.NewToolResultError(fmt.Sprintf("Sync failed: %v", err)), nil
 				}
+				if outputFormat == "json" {
+					data, err := json.Marshal(syncResult)
+					if err != nil {
+						return mcp.NewToolResultError(fmt.Sprintf("Failed to encode sync result: %v", err)), nil
+					}
+					return mcp.NewToolResultText(string(data)), nil
+				}
 
+				if engine.IsCompactMode() && dryRun {
+					return mcp.NewToolResultText("DRY RUN: " + syncPlanSummary(syncResult["plan"].([]core.SyncPlanEntry), syncResult["bytes_to_transfer"].(int64))), nil
+				}
 				if engine.IsCompactMode() {
-					syncCount := syncResult["synced_count"].(int)
-					errorCount := syncResult["error_count"].(int)
-					return mcp.NewToolResultText(fmt.Sprintf("OK: %d files synced, %d errors", syncCount, errorCount)), nil
+					summary := fmt.Sprintf("OK: %d files synced", syncResult["synced_count"].(int))
+					if conflictCount := syncResult["conflict_count"].(int); conflictCount > 0 {
+						summary += fmt.Sprintf(", %d conflicts", conflictCount)
+					}
+					if excludedCount := syncResult["excluded_count"].(int); excludedCount > 0 {
+						summary += fmt.Sprintf(", %d excluded", excludedCount)
+					}
+					if syncResult["cancelled"].(bool) {
+						summary += " (cancelled)"
+					}
+					return mcp.NewToolResultText(fmt.Sprintf("%s, %d errors", summary, syncResult["error_count"].(int))), nil
 				}
 
 				var output strings.Builder
@@ -249,13 +435,23 @@ func registerPlatformTools(reg *toolRegistry) {
 				if dryRun {
 					output.WriteString("Mode: DRY RUN (preview only)\n")
 				}
+				if excludedCount := syncResult["excluded_count"].(int); excludedCount > 0 {
+					output.WriteString(fmt.Sprintf("Excluded: %d paths (.gitignore, defaults, exclude)\n", excludedCount))
+				}
+				output.WriteString(fmt.Sprintf("Run: %s, %d files checked, %d unchanged, %s copied\n",
+					syncResult["sync_id"], syncResult["files_total"], syncResult["unchanged_count"], core.FormatSize(syncResult["bytes_copied"].(int64))))
+				if syncResult["cancelled"].(bool) {
+					output.WriteString("CANCELLED: files copied so far are recorded; re-run to continue\n")
+				}
 				output.WriteString("\n")
 
 				syncedFiles := syncResult["synced_files"].([]string)
 				syncCount := syncResult["synced_count"].(int)
 				errorCount := syncResult["error_count"].(int)
 
-				if syncCount > 0 {
+				if dryRun {
+					output.WriteString(formatSyncPlan(syncResult["plan"].([]core.SyncPlanEntry), syncResult["bytes_to_transfer"].(int64), engine.GetConfig().MaxListItems))
+				} else if syncCount > 0 {
 					output.WriteString(fmt.Sprintf("Files synced: %d\n", syncCount))
 					if syncCount <= 20 {
 						for _, file := range syncedFiles {
@@ -271,6 +467,20 @@ func registerPlatformTools(reg *toolRegistry) {
 					output.WriteString("No files to sync\n")
 				}
 
+				if conflicts := syncResult["conflicts"].([]string); len(conflicts) > 0 {
+					output.WriteString(fmt.Sprintf("\nConflicts (not synced): %d\n", len(conflicts)))
+					for _, c := range conflicts {
+						output.WriteString(fmt.Sprintf("  - %s\n", c))
+					}
+					output.WriteString("Resolve by hand or re-run with conflict_strategy: newest_wins, prefer_wsl or prefer_windows\n")
+				}
+				if resolved := syncResult["resolved_conflicts"].([]string); len(resolved) > 0 {
+					output.WriteString(fmt.Sprintf("\nConflicts resolved (%s): %d\n", syncResult["conflict_strategy"], len(resolved)))
+					for _, r := range resolved {
+						output.WriteString(fmt.Sprintf("  - %s\n", r))
+					}
+				}
+
 				if errorCount > 0 {
 					syncErrors := syncResult["errors"].([]string)
 					output.WriteString(fmt.Sprintf("\nErrors: %d\n", errorCount))
@@ -299,6 +509,10 @@ func registerPlatformTools(reg *toolRegistry) {
 					return mcp.NewToolResultError("access denied: one or both paths are outside allowed directories"+engine.AllowedDirsSuffix()), nil
 				}
 
+				if err := engine.CheckWritable("wsl_sync", windowsPath); err != nil {
+					return mcp.NewToolResultError(err.Error()), nil
+				}
+
 				err := engine.WSLWindowsCopy(ctx, wslPath, windowsPath, createDirs)
 				if err != nil {
 					return mcp.NewToolResultError(fmt.Sprintf("Copy failed: %v", err)), nil
@@ -325,6 +539,10 @@ func registerPlatformTools(reg *toolRegistry) {
 					return mcp.NewToolResultError("access denied: one or both paths are outside allowed directories"+engine.AllowedDirsSuffix()), nil
 				}
 
+				if err := engine.CheckWritable("wsl_sync", wslDest); err != nil {
+					return mcp.NewToolResultError(err.Error()), nil
+				}
+
 				err := engine.WSLWindowsCopy(ctx, windowsPath, wslDest, createDirs)
 				if err != nil {
 					return mcp.NewToolResultError(fmt.Sprintf("Copy failed: %v", err)), nil
@@ -340,22 +558,129 @@ func registerPlatformTools(reg *toolRegistry) {
 	}))
 
 	// ============================================================================
-	// 16. server_info — Server info (consolidated: stats + artifact + get_help)
+	// allowed_paths — inspect the access rules and change the sandbox without
+	// editing the client config and restarting
+	// ============================================================================
+	allowedPathsTool := mcp.NewTool("allowed_paths",
+		mcp.WithTitleAnnotation("Allowed Paths"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithDescription("allowed_paths — The directories this server may access and the rules in force. Actions: list, add, remove, rules. "+
+			"list shows each allowed path, where it came from (startup, persisted, runtime) and whether it exists. "+
+			"add grants access to an existing directory and remove revokes one (exactly as listed; files are not touched); both require --allow-path-management and are logged. "+
+			"With --allowed-paths-file an added path is kept across restarts (persist, default true); a removed startup path comes back on restart and the last allowed path cannot be removed. "+
+			"rules shows the allowed paths and write-protection rules (--protected-paths; matching paths can be read but every write, edit, delete, move or rename is refused); pass path to see whether it is readable and writable and which rule protects it. "+
+			"Related: analyze_operation, server_info."),
+		mcp.WithString("action", mcp.Description("Action: list (default), add, remove, rules")),
+		mcp.WithString("path", mcp.Description("For add: directory to allow. For remove: allowed path to remove. For rules: optional path to check")),
+		mcp.WithBoolean("persist", mcp.Description("For add: keep the path in --allowed-paths-file (default: true when the file is configured)")),
+		mcp.WithString("output", mcp.Description("For list, rules: output format \"text\" (default) or \"json\"")),
+	)
+	reg.addTool(allowedPathsTool, auditWrap(engine, "allowed_paths", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		switch action := request.GetString("action", "list"); action {
+		case "list", "":
+			output := request.GetString("output", "text")
+			if output != "text" && output != "json" {
+				return usageError(fmt.Sprintf("invalid output %q. Valid: text, json", output), `allowed_paths(action:"list", output:"json")`), nil
+			}
+			listing := engine.ListAllowedPaths()
+			if output == "json" {
+				data, err := json.MarshalIndent(listing, "", "  ")
+				if err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("Error: %v", err)), nil
+				}
+				return mcp.NewToolResultText(string(data)), nil
+			}
+			return mcp.NewToolResultText(core.FormatAllowedPaths(listing, engine.IsCompactMode())), nil
+
+		case "add":
+			path, err := request.RequireString("path")
+			if err != nil {
+				return usageError("path is required", `allowed_paths(action:"add", path:"/home/user/other-project")`), nil
+			}
+			persist := request.GetBool("persist", engine.GetConfig().AllowedPathsFile != "")
+			added, err := engine.AddAllowedPath(ctx, path, persist)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Error: %v", err)), nil
+			}
+			until := "until restart"
+			if persist {
+				until = "persisted"
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("Allowed: %s (%s)", added, until)), nil
+
+		case "remove":
+			path, err := request.RequireString("path")
+			if err != nil {
+				return usageError("path is required", `allowed_paths(action:"remove", path:"/home/user/other-project")`), nil
+			}
+			removed, err := engine.RemoveAllowedPath(ctx, path)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Error: %v", err)), nil
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("No longer allowed: %s", removed)), nil
+
+		case "rules":
+			output := request.GetString("output", "text")
+			if output != "text" && output != "json" {
+				return usageError(fmt.Sprintf("invalid output %q. Valid: text, json", output), `allowed_paths(action:"rules", path:"/project/vendor/lib.go")`), nil
+			}
+			rules := engine.PathRules(request.GetString("path", ""))
+			if output == "json" {
+				data, err := json.MarshalIndent(rules, "", "  ")
+				if err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("Error: %v", err)), nil
+				}
+				return mcp.NewToolResultText(string(data)), nil
+			}
+			return mcp.NewToolResultText(core.FormatPathRules(rules, engine.IsCompactMode())), nil
+
+		default:
+			return usageError(fmt.Sprintf("invalid action %q. Valid: list, add, remove, rules", action), `allowed_paths(action:"list")`), nil
+		}
+	}))
+
+	// ============================================================================
+	// 16. server_info — Server info (consolidated: stats + reports + artifact + get_help)
 	// ============================================================================
 	serverInfoTool := mcp.NewTool("server_info",
 		mcp.WithTitleAnnotation("Server Info"),
 		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithDestructiveHintAnnotation(false),
 		mcp.WithIdempotentHintAnnotation(true),
-		mcp.WithDescription("server_info — Server help, performance stats, and artifact capture. Actions: help, stats, artifact. "+
+		mcp.WithDescription("server_info — Server help, performance stats, effective configuration, operation reports, and artifact capture. "+
+			"Actions: help, stats, config, tools, report, history, audit_log, reset_telemetry, artifact. "+
+			"tools lists the registered tools and the ones left out by --enable-tools / --disable-tools with the reason; call it when a tool you expect fails with \"tool not found\". "+
+			"report summarizes recent MUTATING operations (tool, path, risk, replacements, bytes written, backup_id, forced); "+
+			"history lists the most recent tool calls, reads included (W = mutating, R = read-only; in memory, --op-history-size); "+
+			"audit_log reads the append-only, hash-chained log of every mutation across restarts (--mutation-log-dir), verifying the chain on every read; "+
+			"reset_telemetry zeroes the stats counters, latency percentiles and edit telemetry and starts a new tracking window (not backups or report). "+
+			"artifact keeps generated content in named slots (sub_action capture, write, info, delete; name \"last\" is the most recent capture) "+
+			"or assembles one too large for a single call from ordered chunks (sub_action capture_chunk; write waits for final:true); sub_action diff shows what writing it to path would change, and apply writes it as an edit with a backup (mode overwrite, patch for a unified diff, or append) "+
+			"under a total size cap (--max-artifact-bytes), least recently used evicted first. "+
 			"Related: edit_file, search_files, batch_operations, backup, analyze_operation."),
-		mcp.WithString("action", mcp.Description("Action: help (default), stats, artifact")),
+		mcp.WithString("action", mcp.Description("Action: help (default), stats, config (version, platform, allowed paths, limits, backup/risk/hooks/autosync/cache settings), tools, report, history, audit_log, reset_telemetry, artifact")),
 		// help params
 		mcp.WithString("topic", mcp.Description("Help topic: overview, workflow, tools, read, write, edit, search, batch, errors, examples, tips, all")),
+		// report / history / audit_log params
+		mcp.WithNumber("limit", mcp.Description("For report, history, audit_log: max entries (default: 50)")),
+		mcp.WithString("since", mcp.Description("For report, audit_log: only entries after this point: RFC3339 timestamp or a duration ago such as \"30m\", \"2h\"")),
+		mcp.WithString("filter_tool", mcp.Description("For history: only calls to this tool, e.g. \"edit_file\"")),
+		mcp.WithString("filter_path", mcp.Description("For history, audit_log: only entries on this file or directory, or below it")),
 		// artifact params
-		mcp.WithString("sub_action", mcp.Description("For artifact: capture, write, info")),
+		mcp.WithString("sub_action", mcp.Description("For artifact: capture, capture_chunk, diff, write, apply, info (default; alias list), delete")),
+		mcp.WithString("name", mcp.Description("For artifact: slot name (capture default: \"default\"; elsewhere \"last\", the most recently captured)")),
 		mcp.WithString("content", mcp.Description("Artifact content to capture")),
-		mcp.WithString("path", mcp.Description("Path for writing artifact")),
+		mcp.WithString("path", mcp.Description("Path for writing or diffing artifact")),
+		mcp.WithNumber("context_lines", mcp.Description("For artifact diff: unchanged lines around each change (default: 3)")),
+		mcp.WithString("expected_hash", mcp.Description("For artifact write, apply: the content_hash from artifact diff; the write is refused if the file changed since")),
+		mcp.WithString("mode", mcp.Description("For artifact apply: overwrite (default), patch (the artifact is a unified diff for path), append")),
+		mcp.WithString("chunk", mcp.Description("For artifact capture_chunk: the next part of the content")),
+		mcp.WithNumber("index", mcp.Description("For artifact capture_chunk: 0-based chunk number; 0 starts over, each later one must follow the previous")),
+		mcp.WithNumber("total", mcp.Description("For artifact capture_chunk: expected number of chunks, if known")),
+		mcp.WithBoolean("final", mcp.Description("For artifact capture_chunk: set on the last chunk; the artifact cannot be written before")),
+		formatParam(),
 	)
 	reg.addTool(serverInfoTool, auditWrap(engine, "server_info", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		action := "help"
@@ -371,6 +696,22 @@ func registerPlatformTools(reg *toolRegistry) {
 
 		switch action {
 		case "stats":
+			if wantJSON(engine, request) {
+				resp := core.ServerStatsResponse{
+					Build:         fmt.Sprintf("%s (%s)", buildCommit, buildDate),
+					Performance:   engine.PerformanceStats(),
+					EditTelemetry: engine.GetEditTelemetrySummary(),
+				}
+				if bm := engine.GetBackupManager(); bm != nil {
+					allBackups, _ := bm.ListBackups(9999, "all", "", 0)
+					maxCount, maxAge := bm.GetBackupLimits()
+					resp.Backup = &core.BackupSystemInfo{Directory: bm.GetBackupDir(), MaxCount: maxCount, MaxAgeDays: maxAge, TotalBackups: len(allBackups)}
+					if len(allBackups) > 0 {
+						resp.Backup.Latest = &allBackups[0]
+					}
+				}
+				return jsonResult(resp), nil
+			}
 			stats := engine.GetPerformanceStats()
 
 			// Also include edit telemetry
@@ -397,59 +738,33 @@ func registerPlatformTools(reg *toolRegistry) {
 			buildLine := fmt.Sprintf("build:%s (%s)\n", buildCommit, buildDate)
 			return mcp.NewToolResultText(buildLine + stats + telemetry + backupInfo), nil
 
-		case "artifact":
-			subAction := "info"
-			if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
-				if sa, ok := args["sub_action"].(string); ok && sa != "" {
-					subAction = sa
-				}
+		case "config":
+			cfg := engine.ServerConfig()
+			cfg.Version = serverVersion
+			cfg.Build = fmt.Sprintf("%s (%s)", buildCommit, buildDate)
+			if wantJSON(engine, request) {
+				return jsonResult(cfg), nil
 			}
+			return mcp.NewToolResultText(core.FormatServerConfig(cfg, engine.IsCompactMode())), nil
 
-			switch subAction {
-			case "capture":
-				content := ""
-				if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
-					if c, ok := args["content"].(string); ok {
-						content = c
-					}
-				}
-				if content == "" {
-					return mcp.NewToolResultError("content parameter is required for artifact capture"), nil
-				}
-
-				err := engine.CaptureLastArtifact(ctx, content)
-				if err != nil {
-					return mcp.NewToolResultError(fmt.Sprintf("Error: %v", err)), nil
-				}
-
-				lines := strings.Count(content, "\n") + 1
-				return mcp.NewToolResultText(fmt.Sprintf("Captured artifact: %d bytes, %d lines", len(content), lines)), nil
+		case "tools":
+			return toolsConfigResult(reg, request), nil
 
-			case "write":
-				artifactPath := ""
-				if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
-					if p, ok := args["path"].(string); ok {
-						artifactPath = p
-					}
-				}
-				if artifactPath == "" {
-					return mcp.NewToolResultError("path parameter is required for artifact write"), nil
-				}
+		case "report":
+			return operationReportResult(engine, request), nil
 
-				err := engine.WriteLastArtifact(ctx, artifactPath)
-				if err != nil {
-					return mcp.NewToolResultError(fmt.Sprintf("Error: %v", err)), nil
-				}
+		case "history":
+			return operationHistoryResult(engine, request), nil
 
-				return mcp.NewToolResultText(fmt.Sprintf("Wrote last artifact to: %s", artifactPath)), nil
+		case "audit_log":
+			return auditLogResult(engine, request), nil
 
-			case "info":
-				info := engine.GetLastArtifactInfo()
-				return mcp.NewToolResultText(info), nil
+		case "reset_telemetry":
+			core.MarkMutating(ctx)
+			return resetTelemetryResult(engine), nil
 
-			default:
-				return mcp.NewToolResultError(fmt.Sprintf("Unknown sub_action: %s. Valid: capture, write, info", subAction)), nil
-			}
+		case "artifact":
+			return artifactResult(ctx, engine, request), nil
 
 		case "help":
 			topic := "overview"
@@ -463,7 +778,100 @@ func registerPlatformTools(reg *toolRegistry) {
 			return mcp.NewToolResultText(help), nil
 
 		default:
-			return mcp.NewToolResultError(fmt.Sprintf("Unknown server_action: %s. Valid: help, stats, artifact (or use sub_action for artifact options)", action)), nil
+			return mcp.NewToolResultError(fmt.Sprintf("Unknown server_action: %s. Valid: help, stats, config, artifact (or use sub_action for artifact options)", action)), nil
 		}
 	}))
 }
+
+// syncProgressNotifier logs workspace sync progress and, when the client sent
+// a progress token, forwards it as notifications/progress (progress counts files)
+func syncProgressNotifier(ctx context.Context, token mcp.ProgressToken) func(core.SyncStatus) {
+	mcpServer := server.ServerFromContext(ctx)
+	return func(status core.SyncStatus) {
+		slog.Debug("sync progress", "id", status.ID, "status", status.Status, "files", status.FilesDone,
+			"files_total", status.FilesTotal, "bytes", status.BytesCopied, "path", status.CurrentPath)
+		if token == nil || mcpServer == nil {
+			return
+		}
+		_ = mcpServer.SendNotificationToClient(ctx, string(mcp.MethodNotificationProgress), map[string]any{
+			"progressToken": token,
+			"progress":      float64(status.FilesDone),
+			"total":         float64(status.FilesTotal),
+			"message":       formatSyncStatus(status),
+		})
+	}
+}
+
+// formatSyncStatus renders one workspace sync status line
+func formatSyncStatus(s core.SyncStatus) string {
+	line := fmt.Sprintf("%s %s %s: %d/%d files | %d copied (%s), %d unchanged",
+		s.ID, s.Direction, strings.ToUpper(s.Status), s.FilesDone, s.FilesTotal,
+		s.FilesCopied, core.FormatSize(s.BytesCopied), s.Unchanged)
+	if s.Conflicts > 0 {
+		line += fmt.Sprintf(", %d conflicts", s.Conflicts)
+	}
+	if s.Errors > 0 {
+		line += fmt.Sprintf(", %d errors", s.Errors)
+	}
+	if s.CurrentPath != "" {
+		line += " | " + s.CurrentPath
+	}
+	line += " | " + s.Elapsed.Round(time.Millisecond).String()
+	if s.DryRun {
+		line += " | dry-run"
+	}
+	return line
+}
+
+// syncPlanSummary counts a dry-run plan by action
+func syncPlanSummary(plan []core.SyncPlanEntry, transfer int64) string {
+	counts := make(map[string]int)
+	for _, p := range plan {
+		counts[p.Action]++
+	}
+	summary := fmt.Sprintf("%d to copy, %d to overwrite (%s to transfer), %d identical",
+		counts[core.SyncPlanCopyNew], counts[core.SyncPlanOverwrite], core.FormatSize(transfer), counts[core.SyncPlanSkipIdentical])
+	if n := counts[core.SyncPlanConflict]; n > 0 {
+		summary += fmt.Sprintf(", %d conflicts", n)
+	}
+	if n := counts[core.SyncPlanKeepDestination]; n > 0 {
+		summary += fmt.Sprintf(", %d kept on the destination", n)
+	}
+	return summary
+}
+
+// syncPlanLabels are the dry-run action labels, padded to one width
+var syncPlanLabels = map[string]string{
+	core.SyncPlanCopyNew:         "copy new  ",
+	core.SyncPlanOverwrite:       "overwrite ",
+	core.SyncPlanSkipIdentical:   "identical ",
+	core.SyncPlanConflict:        "CONFLICT  ",
+	core.SyncPlanKeepDestination: "keep dest ",
+}
+
+// formatSyncPlan lists a dry-run plan, files to transfer first, at most
+// maxItems lines
+func formatSyncPlan(plan []core.SyncPlanEntry, transfer int64, maxItems int) string {
+	var out strings.Builder
+	out.WriteString("Plan: " + syncPlanSummary(plan, transfer) + "\n")
+	ordered := make([]core.SyncPlanEntry, 0, len(plan))
+	for _, transferring := range []bool{true, false} {
+		for _, p := range plan {
+			if (p.Action == core.SyncPlanCopyNew || p.Action == core.SyncPlanOverwrite) == transferring {
+				ordered = append(ordered, p)
+			}
+		}
+	}
+	for i, p := range ordered {
+		if maxItems > 0 && i >= maxItems {
+			out.WriteString(fmt.Sprintf("  ... and %d more\n", len(ordered)-i))
+			break
+		}
+		line := fmt.Sprintf("  %s %s", syncPlanLabels[p.Action], filepath.Join(p.Root, p.Path))
+		if p.To != "" {
+			line += fmt.Sprintf(" -> %s (%s)", p.To, core.FormatSize(p.Size))
+		}
+		out.WriteString(line + "\n")
+	}
+	return out.String()
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mcp/filesystem-ultra/core"
+)
+
+// The server_info report actions: report, history, audit_log and
+// reset_telemetry. Each takes the server_info request and returns its result.
+
+// operationReportResult handles server_info(action:"report") — what the
+// server changed (not how fast: see action stats)
+func operationReportResult(engine *core.UltraFastEngine, request mcp.CallToolRequest) *mcp.CallToolResult {
+	args, _ := request.Params.Arguments.(map[string]interface{})
+	limit := parseIntArg(args, "limit", 50)
+	sinceArg, _ := args["since"].(string)
+	since, err := parseSinceArg(sinceArg)
+	if err != nil {
+		return usageError(err.Error(), `server_info(action:"report", since:"1h", limit:20)`)
+	}
+
+	report := engine.GetOperationReport(limit, since)
+	if wantJSON(engine, request) {
+		return jsonResult(report)
+	}
+	return mcp.NewToolResultText(core.FormatOperationReport(report, engine.IsCompactMode()))
+}
+
+// operationHistoryResult handles server_info(action:"history") — every recent
+// tool call, reads included
+func operationHistoryResult(engine *core.UltraFastEngine, request mcp.CallToolRequest) *mcp.CallToolResult {
+	args, _ := request.Params.Arguments.(map[string]interface{})
+	filterTool, _ := args["filter_tool"].(string)
+	filterPath, _ := args["filter_path"].(string)
+
+	entries := engine.GetOperationHistory(core.HistoryQuery{
+		Limit:      parseIntArg(args, "limit", 50),
+		FilterTool: filterTool,
+		FilterPath: filterPath,
+	})
+	if wantJSON(engine, request) {
+		return jsonResult(entries)
+	}
+	return mcp.NewToolResultText(core.FormatOperationHistory(entries, engine.IsCompactMode()))
+}
+
+// auditLogResult handles server_info(action:"audit_log") — the hash-chained
+// mutation log (--mutation-log-dir)
+func auditLogResult(engine *core.UltraFastEngine, request mcp.CallToolRequest) *mcp.CallToolResult {
+	args, _ := request.Params.Arguments.(map[string]interface{})
+	sinceArg, _ := args["since"].(string)
+	since, err := parseSinceArg(sinceArg)
+	if err != nil {
+		return usageError(err.Error(), `server_info(action:"audit_log", since:"24h", filter_path:"/repo/src")`)
+	}
+	filterPath, _ := args["filter_path"].(string)
+
+	res, err := engine.ReadMutationLog(core.MutationLogQuery{
+		Limit:      parseIntArg(args, "limit", 50),
+		FilterPath: filterPath,
+		Since:      since,
+	})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error())
+	}
+	if wantJSON(engine, request) {
+		return jsonResult(res)
+	}
+	return mcp.NewToolResultText(core.FormatMutationLog(res, engine.IsCompactMode()))
+}
+
+// resetTelemetryResult handles server_info(action:"reset_telemetry") — start a
+// new window for the stats counters and edit telemetry
+func resetTelemetryResult(engine *core.UltraFastEngine) *mcp.CallToolResult {
+	previous, err := engine.ResetTelemetry()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Telemetry reset in memory, but saving it failed: %v", err))
+	}
+	if engine.IsCompactMode() {
+		return mcp.NewToolResultText(fmt.Sprintf("OK: telemetry reset (was tracking since %s)", previous.Format(time.RFC3339)))
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Telemetry reset. Discarded counters tracked since %s (%s).\nNew tracking window started now.",
+		previous.Format(time.RFC3339), core.FormatAge(previous)))
+}
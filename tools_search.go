@@ -5,13 +5,16 @@
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mcp/filesystem-ultra/core"
 	localmcp "github.com/mcp/filesystem-ultra/mcp"
 )
 
-// registerSearchTools registers list_directory, search_files, analyze_operation
+// registerSearchTools registers list_directory, directory_tree, directory_size,
+// disk_usage, find_duplicate_files, compare_directories, watch_directory,
+// recently_modified, search_files, analyze_operation
 func registerSearchTools(reg *toolRegistry) {
 	engine := reg.engine
 
@@ -22,6 +25,7 @@ func registerSearchTools(reg *toolRegistry) {
 		mcp.WithTitleAnnotation("List Directory"),
 		mcp.WithDescription("list_directory — List directory contents on the real host filesystem; use it to verify a host creation/edit independently. "+
 			"Runtime-native directory tools may inspect a different sandbox. output_format: 'compact' (default), 'json' (structured entries with name/type/size/modified), 'tree' (recursive JSON tree, use max_depth). "+
+			"sort (name|size|mtime), order, filter (glob), dirs_only/files_only and offset/limit page through large directories. "+
 			"Related: search_files, read_file, edit_file, create_directory, batch_operations."),
 		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithDestructiveHintAnnotation(false),
@@ -29,6 +33,15 @@ func registerSearchTools(reg *toolRegistry) {
 		mcp.WithString("path", mcp.Required(), mcp.Description("Path to directory (WSL or Windows format)")),
 		mcp.WithString("output_format", mcp.Description("Output format: 'compact' (default, token-efficient one-liner), 'json' (structured entries: name, type, size, modified RFC3339), 'tree' (recursive JSON tree)")),
 		mcp.WithNumber("max_depth", mcp.Description("Recursion depth for output_format:'tree' (default: 2)")),
+		mcp.WithString("sort", mcp.Description("Sort entries by name (default), size or mtime")),
+		mcp.WithString("order", mcp.Description("asc or desc (default: asc for name, desc for size and mtime — largest/newest first)")),
+		mcp.WithString("filter", mcp.Description("Glob on the entry name, e.g. '*.go'")),
+		mcp.WithNumber("offset", mcp.Description("Skip this many entries (after filter and sort) to page through large directories")),
+		mcp.WithNumber("limit", mcp.Description("Entries shown (default and max: --max-list-items)")),
+		mcp.WithBoolean("dirs_only", mcp.Description("List only directories")),
+		mcp.WithBoolean("files_only", mcp.Description("List only files")),
+		mcp.WithBoolean("include_git", mcp.Description("Annotate entries with their git status (M, A, ?? ...; directories with changes below them show M). Read-only; ignored outside a repository (default: false)")),
+		formatParam(),
 	)
 	reg.listDirHandler = auditWrap(engine, "list_directory", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		path, err := request.RequireString("path")
@@ -47,6 +60,38 @@ func registerSearchTools(reg *toolRegistry) {
 			}
 		}
 
+		// format:"json" (or --json-responses) selects the JSON listing unless
+		// output_format asks for something else
+		if outputFormat == "" && wantJSON(engine, request) {
+			outputFormat = "json"
+		}
+
+		args, _ := request.Params.Arguments.(map[string]interface{})
+		view := core.ListOptions{
+			Sort:      request.GetString("sort", ""),
+			Order:     request.GetString("order", ""),
+			Filter:    request.GetString("filter", ""),
+			Offset:    parseIntArg(args, "offset", 0),
+			Limit:     parseIntArg(args, "limit", 0),
+			DirsOnly:  request.GetBool("dirs_only", false),
+			FilesOnly: request.GetBool("files_only", false),
+			Git:       request.GetBool("include_git", false),
+		}
+		if !view.IsDefault() {
+			if outputFormat == "tree" {
+				return usageError("sort, order, filter, offset, limit, dirs_only, files_only and include_git apply to the compact and json listings, not output_format:'tree' (analyze_directory tree takes include_git)",
+					`list_directory(path:"/project/src", sort:"mtime", filter:"*.go", limit:20)`), nil
+			}
+			listing, err := engine.ListDirectoryView(ctx, path, view)
+			if err != nil {
+				return mcp.NewToolResultError(formatToolError(err)), nil
+			}
+			if outputFormat == "json" {
+				return jsonResult(listing), nil
+			}
+			return chunkedText(engine, "list_directory", core.FormatListView(listing, engine.IsCompactMode()), responseLimit(engine)), nil
+		}
+
 		var listing string
 		switch outputFormat {
 		case "json":
@@ -59,10 +104,185 @@ func registerSearchTools(reg *toolRegistry) {
 		if err != nil {
 			return mcp.NewToolResultError(formatToolError(err)), nil
 		}
-		return mcp.NewToolResultText(listing), nil
+		return chunkedText(engine, "list_directory", listing, responseLimit(engine)), nil
 	})
 	reg.addTool(listDirTool, reg.listDirHandler)
 
+	// ============================================================================
+	// analyze_directory — Read-only directory analysis: tree, size, disk usage,
+	// duplicates, comparison, change detection and recent files
+	// ============================================================================
+	analyzeDirTool := mcp.NewTool("analyze_directory",
+		mcp.WithTitleAnnotation("Analyze Directory"),
+		mcp.WithDescription("analyze_directory — Read-only analysis of a directory. Actions: tree, size, disk_usage, duplicates, compare, watch, recent. "+
+			"tree: indented recursive view for orienting in a project; each level lists at most --max-list-items entries, include_sizes adds cumulative size and file count. "+
+			"size: disk usage like du (total, largest subdirectories and files), measured on every call. "+
+			"disk_usage: total, used and free bytes of the filesystem holding path, plus what the backup directory uses. "+
+			"duplicates: byte-identical files grouped by size then hash, largest waste first; reports only. "+
+			"compare: for two directories, files only in path_a, only in path_b, and files that differ (check: name, size, mtime, hash); "+
+			"for two files, identical or different (mode: hash, diff, stats). "+
+			"watch: the first call returns a cursor; call again with it for the files added, removed and modified since then. "+
+			"recent: files modified within a window (default 1h), newest first. "+
+			"All actions skip .git, node_modules and the other default excludes; unreadable entries are counted as skipped. "+
+			"Related: list_directory, search_files, get_file_info, delete_file."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(false),
+		mcp.WithString("action", mcp.Description("Action: tree (default), size, disk_usage, duplicates, compare, watch, recent")),
+		mcp.WithString("path", mcp.Description("Directory to analyze (WSL or Windows format); for disk_usage any existing path on the filesystem. Required except for compare")),
+		mcp.WithArray("exclude", mcp.WithStringItems(),
+			mcp.Description("For tree, duplicates, compare, recent: extra globs to skip (\"*.log\", \"dist/**\"), on top of the defaults")),
+		// tree params
+		mcp.WithNumber("max_depth", mcp.Description(fmt.Sprintf("For tree: levels listed below path (default: %d)", core.DefaultTreeDepth))),
+		mcp.WithBoolean("include_sizes", mcp.Description("For tree: annotate directories with cumulative size and file count, counting files below max_depth too (default: false)")),
+		mcp.WithBoolean("include_hidden", mcp.Description("For tree: list dot files and directories (default: false)")),
+		mcp.WithBoolean("include_git", mcp.Description("For tree: annotate entries with their git status (M, A, ?? ...). Read-only; ignored outside a repository (default: false)")),
+		// size params
+		mcp.WithNumber("depth", mcp.Description("For size: rank subdirectories up to this many levels below path (default: 1, immediate subdirectories)")),
+		mcp.WithNumber("top_n", mcp.Description(fmt.Sprintf("For size: largest directories and files listed (default: %d, max: %d)", core.DefaultSizeTopN, core.MaxSizeTopN))),
+		// duplicates params
+		mcp.WithNumber("min_size", mcp.Description(fmt.Sprintf("For duplicates: ignore files smaller than this many bytes (default: %d)", core.DefaultDuplicateMinSize))),
+		// compare params
+		mcp.WithString("path_a", mcp.Description("For compare: first directory or file")),
+		mcp.WithString("path_b", mcp.Description("For compare: second directory or file")),
+		mcp.WithString("check", mcp.Description("For compare of directories: what makes two files differ: name (presence only), size (default), mtime (size or modification time), hash (size or content)")),
+		mcp.WithString("mode", mcp.Description("For compare of files: hash (default; identical or not, plus both SHA-256), diff (unified diff), stats (lines added and removed). Binary files and files over 4 MB are compared by hash")),
+		// watch params
+		mcp.WithString("cursor", mcp.Description("For watch: cursor from the previous call on this path; omit to take the first snapshot")),
+		// recent params
+		mcp.WithString("within", mcp.Description("For recent: time window: 30m, 1h (default), 24h, 7d")),
+		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("For recent: files listed (default: %d, max: max search results)", core.DefaultRecentLimit))),
+		formatParam(),
+	)
+	reg.addTool(analyzeDirTool, auditWrap(engine, "analyze_directory", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		action := request.GetString("action", "tree")
+		args, _ := request.Params.Arguments.(map[string]interface{})
+
+		if action == "compare" {
+			pathA, errA := request.RequireString("path_a")
+			pathB, errB := request.RequireString("path_b")
+			if errA != nil || errB != nil {
+				return usageError("path_a and path_b are required", `analyze_directory(action:"compare", path_a:"/project", path_b:"/mnt/c/project", check:"hash")`), nil
+			}
+			if engine.ComparesFiles(pathA) {
+				res, err := engine.CompareFileContents(ctx, pathA, pathB, request.GetString("mode", ""))
+				if err != nil {
+					return mcp.NewToolResultError(formatToolError(err)), nil
+				}
+				if wantJSON(engine, request) {
+					return jsonResult(res), nil
+				}
+				return chunkedText(engine, "analyze_directory", core.FormatFileComparison(res, engine.IsCompactMode()), responseLimit(engine)), nil
+			}
+			exclude, errResult := excludeFromArgs(args)
+			if errResult != nil {
+				return errResult, nil
+			}
+			res, err := engine.CompareDirectories(ctx, pathA, pathB, request.GetString("check", ""), exclude)
+			if err != nil {
+				return mcp.NewToolResultError(formatToolError(err)), nil
+			}
+			if wantJSON(engine, request) {
+				return jsonResult(res), nil
+			}
+			return chunkedText(engine, "analyze_directory", core.FormatDirComparison(res, engine.IsCompactMode()), responseLimit(engine)), nil
+		}
+
+		path, err := request.RequireString("path")
+		if err != nil {
+			return usageError("path is required", fmt.Sprintf(`analyze_directory(action:%q, path:"/project")`, action)), nil
+		}
+
+		switch action {
+		case "tree", "":
+			exclude, errResult := excludeFromArgs(args)
+			if errResult != nil {
+				return errResult, nil
+			}
+			opts := core.TreeOptions{
+				MaxDepth:      parseIntArg(args, "max_depth", core.DefaultTreeDepth),
+				IncludeSizes:  request.GetBool("include_sizes", false),
+				IncludeHidden: request.GetBool("include_hidden", false),
+				Exclude:       exclude,
+				Git:           request.GetBool("include_git", false),
+			}
+			tree, err := engine.DirectoryTree(ctx, path, opts)
+			if err != nil {
+				return mcp.NewToolResultError(formatToolError(err)), nil
+			}
+			if wantJSON(engine, request) {
+				return mcp.NewToolResultText(core.DirectoryTreeJSON(tree, responseLimit(engine))), nil
+			}
+			return mcp.NewToolResultText(core.FormatDirectoryTree(tree, engine.IsCompactMode(), responseLimit(engine))), nil
+
+		case "size":
+			res, err := engine.DirectorySize(ctx, path, parseIntArg(args, "depth", 1), parseIntArg(args, "top_n", core.DefaultSizeTopN))
+			if err != nil {
+				return mcp.NewToolResultError(formatToolError(err)), nil
+			}
+			if wantJSON(engine, request) {
+				return jsonResult(res), nil
+			}
+			return mcp.NewToolResultText(core.FormatDirectorySize(res, engine.IsCompactMode())), nil
+
+		case "disk_usage":
+			res, err := engine.DiskUsage(ctx, path)
+			if err != nil {
+				return mcp.NewToolResultError(formatToolError(err)), nil
+			}
+			if wantJSON(engine, request) {
+				return jsonResult(res), nil
+			}
+			return mcp.NewToolResultText(core.FormatDiskUsage(res, engine.IsCompactMode())), nil
+
+		case "duplicates":
+			exclude, errResult := excludeFromArgs(args)
+			if errResult != nil {
+				return errResult, nil
+			}
+			minSize := int64(parseIntArg(args, "min_size", core.DefaultDuplicateMinSize))
+			report, err := engine.FindDuplicateFiles(ctx, path, minSize, exclude)
+			if err != nil {
+				return mcp.NewToolResultError(formatToolError(err)), nil
+			}
+			if wantJSON(engine, request) {
+				return jsonResult(report), nil
+			}
+			return chunkedText(engine, "analyze_directory", core.FormatDuplicateReport(report, engine.IsCompactMode()), responseLimit(engine)), nil
+
+		case "watch":
+			changes, err := engine.WatchDirectory(ctx, path, request.GetString("cursor", ""))
+			if err != nil {
+				return mcp.NewToolResultError(formatToolError(err)), nil
+			}
+			if wantJSON(engine, request) {
+				return jsonResult(changes), nil
+			}
+			return chunkedText(engine, "analyze_directory", core.FormatDirChanges(changes, engine.IsCompactMode()), responseLimit(engine)), nil
+
+		case "recent":
+			within, err := core.ParseWithin(request.GetString("within", ""))
+			if err != nil {
+				return usageError(err.Error(), `analyze_directory(action:"recent", path:"/project", within:"24h")`), nil
+			}
+			exclude, errResult := excludeFromArgs(args)
+			if errResult != nil {
+				return errResult, nil
+			}
+			res, err := engine.RecentlyModified(ctx, path, within, parseIntArg(args, "limit", core.DefaultRecentLimit), exclude)
+			if err != nil {
+				return mcp.NewToolResultError(formatToolError(err)), nil
+			}
+			if wantJSON(engine, request) {
+				return jsonResult(res), nil
+			}
+			return chunkedText(engine, "analyze_directory", core.FormatRecentlyModified(res, engine.IsCompactMode(), time.Now()), responseLimit(engine)), nil
+
+		default:
+			return usageError(fmt.Sprintf("invalid action %q. Valid: tree, size, disk_usage, duplicates, compare, watch, recent", action), `analyze_directory(action:"tree", path:"/project")`), nil
+		}
+	}))
+
 	// ============================================================================
 	// 5. search_files — Search files (consolidated: mcp_search + smart_search + advanced_text_search + count_occurrences)
 	// ============================================================================
@@ -88,6 +308,7 @@ func registerSearchTools(reg *toolRegistry) {
 		mcp.WithString("output_format", mcp.Description("Output format. 'text' = verbose with emojis (legacy default), 'json' = structured for AI parsing. If omitted: auto-detect — ripgrep-style 'path:line:content' when ≤5 matches, verbose when more. Pass 'text' explicitly to force the legacy verbose format regardless of match count.")),
 		mcp.WithString("output", mcp.Description("Alias for output_format. Accepts 'text' or 'json'. Legacy values 'content'|'files_with_matches'|'count' are NOT implemented and fall through to the default text branch.")),
 		mcp.WithNumber("max_results", mcp.Description("Maximum number of filenames to return (default: uses engine config; cap recommended for large trees)")),
+		formatParam(),
 	)
 	reg.searchFilesHandler = auditWrap(engine, "search_files", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		path, err := request.RequireString("path")
@@ -189,6 +410,9 @@ func registerSearchTools(reg *toolRegistry) {
 		// Bug #32: route ALL content searches through AdvancedTextSearch which properly
 		// handles case_sensitive:false. SmartSearch (the default path) ignores this flag.
 		if includeContent || wholeWord || includeContext {
+			if outputFormat == "" && wantJSON(engine, request) {
+				outputFormat = "json"
+			}
 			advArgs := map[string]interface{}{
 				"path": path, "pattern": pattern,
 				"case_sensitive": caseSensitive, "whole_word": wholeWord,
@@ -217,6 +441,10 @@ func registerSearchTools(reg *toolRegistry) {
 			"path": path, "pattern": pattern,
 			"include_content": includeContent, "file_types": fileTypes,
 		}}
+		// format:"json" lists the matches with their sniffed mime/is_binary
+		if wantJSON(engine, request) {
+			engineReq.Arguments["output_format"] = "json"
+		}
 		// Forward optional max_results (new param v4.5.26) — engine falls back to
 		// config default when absent, so this is purely advisory.
 		if rawArgs, ok := request.Params.Arguments.(map[string]interface{}); ok {
@@ -235,7 +463,7 @@ func registerSearchTools(reg *toolRegistry) {
 			// of search_files calls in 5–45 s on unwalkable trees like CRM/SmartAdmin
 			// because the model relied on defaults. The hint costs ~30 output tokens
 			// but prevents the next call from re-walking the whole tree.
-			if len(out) > 8000 && len(fileTypes) == 0 && !includeContent {
+			if len(out) > 8000 && len(fileTypes) == 0 && !includeContent && !wantJSON(engine, request) {
 				out += "\n\n💡 hint: this search returned many matches across many files. Next time, pass `file_types` (e.g. \".razor,.cs\") or `include` to skip unrelated trees and keep latency under 1s."
 			}
 			return mcp.NewToolResultText(out), nil
@@ -336,7 +564,8 @@ func registerSearchTools(reg *toolRegistry) {
 
 // capSearchOutput truncates a search_files response if it exceeds the configured
 // output cap. Appends a marker so the model knows the response was truncated
-// and how to recover (count_only:true or a narrower path).
+// and how to recover (fetch_continuation for the rest, count_only:true or a
+// narrower path).
 //
 // Improvement M1+M2: prevents accidental multi-MB responses that waste tokens
 // (a single 2.28MB search response was observed in the proxy log, costing
@@ -346,10 +575,11 @@ func capSearchOutput(text string, engine *core.UltraFastEngine) string {
 	if cfg := engine.GetConfig(); cfg != nil && cfg.MaxSearchOutputBytes > 0 {
 		maxBytes = cfg.MaxSearchOutputBytes
 	}
-	if len(text) <= maxBytes {
+	c := engine.ChunkResponse("search_files", text, maxBytes)
+	if c.ContinuationToken == "" {
 		return text
 	}
-	truncated := text[:maxBytes]
-	marker := fmt.Sprintf("\n\n⚠️ truncated: response exceeded %d KB. Use count_only:true or narrow the path/pattern.\n", maxBytes/1024)
-	return truncated + marker
+	marker := fmt.Sprintf("\n\n⚠️ truncated: response exceeded %d KB (%d of %d bytes shown). Next chunk: fetch_continuation(token:%q), or use count_only:true or narrow the path/pattern.\n",
+		maxBytes/1024, len(c.Content), c.TotalSize, c.ContinuationToken)
+	return c.Content + marker
 }